@@ -0,0 +1,227 @@
+// Package chaos injects faults into the packets of a DHCP transport, so that
+// the retransmission logic of the clients and the idempotency of the servers
+// can be tested under adverse network conditions. A PacketConn wraps the
+// net.PacketConn of a server, e.g. the one passed to
+// server4.NewServerWithConn, or of a test peer, and drops, duplicates,
+// reorders or delays the packets it receives and sends.
+//
+// The faults are drawn from a seeded random source, so that a failing test
+// can be replayed.
+package chaos
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Faults describes the faults injected in one direction of a PacketConn. The
+// zero value injects none.
+type Faults struct {
+	// Loss is the probability that a packet is dropped.
+	Loss float64
+	// Duplicate is the probability that a packet is delivered twice.
+	Duplicate float64
+	// Reorder is the probability that a packet is held back and delivered
+	// after the next one.
+	Reorder float64
+	// Delay is added to the delivery of every packet, plus a random duration
+	// up to Jitter.
+	Delay  time.Duration
+	Jitter time.Duration
+}
+
+type packet struct {
+	data []byte
+	addr net.Addr
+}
+
+// PacketConn is a net.PacketConn that injects In faults into the packets it
+// receives and Out faults into the packets it sends. The faults must not be
+// changed while the connection is in use.
+type PacketConn struct {
+	net.PacketConn
+	In  Faults
+	Out Faults
+
+	mu      sync.Mutex
+	rand    *rand.Rand
+	queue   []packet
+	heldIn  *packet
+	heldOut *packet
+	delayed []delayedPackets
+	sending bool
+	writes  sync.WaitGroup
+}
+
+type delayedPackets struct {
+	pkts []packet
+	at   time.Time
+}
+
+// NewPacketConn returns a PacketConn wrapping conn, whose faults are drawn
+// from a random source seeded with seed.
+func NewPacketConn(conn net.PacketConn, seed int64) *PacketConn {
+	return &PacketConn{
+		PacketConn: conn,
+		rand:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// chance reports whether an event of probability p happens.
+func (c *PacketConn) chance(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rand.Float64() < p
+}
+
+// delay returns the delay of a packet with the faults f.
+func (c *PacketConn) delay(f Faults) time.Duration {
+	d := f.Delay
+	if f.Jitter > 0 {
+		c.mu.Lock()
+		d += time.Duration(c.rand.Int63n(int64(f.Jitter)))
+		c.mu.Unlock()
+	}
+	return d
+}
+
+// ReadFrom reads a packet from the underlying connection, after the In faults
+// are applied. A packet held back for reordering is returned after the next
+// one, or by the next call if the read fails.
+func (c *PacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	buf := make([]byte, 65536)
+	for {
+		c.mu.Lock()
+		if len(c.queue) > 0 {
+			pkt := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			return copy(p, pkt.data), pkt.addr, nil
+		}
+		c.mu.Unlock()
+
+		n, addr, err := c.PacketConn.ReadFrom(buf)
+		if err != nil {
+			c.mu.Lock()
+			if c.heldIn != nil {
+				c.queue = append(c.queue, *c.heldIn)
+				c.heldIn = nil
+			}
+			c.mu.Unlock()
+			return 0, addr, err
+		}
+		pkt := packet{data: append([]byte(nil), buf[:n]...), addr: addr}
+		if c.chance(c.In.Loss) {
+			continue
+		}
+		var extra []packet
+		if c.chance(c.In.Duplicate) {
+			extra = append(extra, pkt)
+		}
+		c.mu.Lock()
+		if c.heldIn == nil && c.rand.Float64() < c.In.Reorder {
+			c.heldIn = &pkt
+			c.queue = append(c.queue, extra...)
+			c.mu.Unlock()
+			continue
+		}
+		if c.heldIn != nil {
+			extra = append(extra, *c.heldIn)
+			c.heldIn = nil
+		}
+		c.queue = append(c.queue, extra...)
+		c.mu.Unlock()
+
+		if d := c.delay(c.In); d > 0 {
+			time.Sleep(d)
+		}
+		return copy(p, pkt.data), pkt.addr, nil
+	}
+}
+
+// WriteTo sends a packet to addr on the underlying connection, after the Out
+// faults are applied. Dropped, held back and delayed packets are reported as
+// sent, as a lossy network would. Delayed packets are sent in the background,
+// in the order they were written, and errors sending them are lost. A packet held back for reordering is sent
+// after the next one, or on Close.
+func (c *PacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if c.chance(c.Out.Loss) {
+		return len(p), nil
+	}
+	pkts := []packet{{data: append([]byte(nil), p...), addr: addr}}
+	if c.chance(c.Out.Duplicate) {
+		pkts = append(pkts, pkts[0])
+	}
+	c.mu.Lock()
+	if c.heldOut == nil && c.rand.Float64() < c.Out.Reorder {
+		c.heldOut = &pkts[0]
+		pkts = pkts[1:]
+	} else if c.heldOut != nil {
+		pkts = append(pkts, *c.heldOut)
+		c.heldOut = nil
+	}
+	c.mu.Unlock()
+
+	if d := c.delay(c.Out); d > 0 {
+		c.mu.Lock()
+		c.delayed = append(c.delayed, delayedPackets{pkts: pkts, at: time.Now().Add(d)})
+		if !c.sending {
+			c.sending = true
+			c.writes.Add(1)
+			go c.sendDelayed()
+		}
+		c.mu.Unlock()
+		return len(p), nil
+	}
+	if err := c.send(pkts); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// sendDelayed sends the delayed packets in the order they were written, each
+// no earlier than its delivery time.
+func (c *PacketConn) sendDelayed() {
+	defer c.writes.Done()
+	for {
+		c.mu.Lock()
+		if len(c.delayed) == 0 {
+			c.sending = false
+			c.mu.Unlock()
+			return
+		}
+		next := c.delayed[0]
+		c.delayed = c.delayed[1:]
+		c.mu.Unlock()
+		time.Sleep(time.Until(next.at))
+		_ = c.send(next.pkts)
+	}
+}
+
+func (c *PacketConn) send(pkts []packet) error {
+	for _, pkt := range pkts {
+		if _, err := c.PacketConn.WriteTo(pkt.data, pkt.addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close sends the packet held back for reordering, if any, waits for the
+// delayed packets to be sent, and closes the underlying connection.
+func (c *PacketConn) Close() error {
+	c.mu.Lock()
+	held := c.heldOut
+	c.heldOut = nil
+	c.mu.Unlock()
+	if held != nil {
+		_ = c.send([]packet{*held})
+	}
+	c.writes.Wait()
+	return c.PacketConn.Close()
+}
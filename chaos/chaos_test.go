@@ -0,0 +1,147 @@
+package chaos
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func loopbackPair(t *testing.T) (*PacketConn, net.PacketConn) {
+	a, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	b, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	return NewPacketConn(a, 1), b
+}
+
+// readAll returns the packets received on conn until it is idle for a while.
+func readAll(t *testing.T, conn net.PacketConn) []string {
+	var got []string
+	buf := make([]byte, 1500)
+	for {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return got
+		}
+		got = append(got, string(buf[:n]))
+	}
+}
+
+func send(t *testing.T, conn net.PacketConn, to net.Addr, msgs ...string) {
+	for _, msg := range msgs {
+		_, err := conn.WriteTo([]byte(msg), to)
+		require.NoError(t, err)
+	}
+}
+
+func TestPacketConnNoFaults(t *testing.T) {
+	c, peer := loopbackPair(t)
+	defer c.Close()
+	defer peer.Close()
+
+	send(t, c, peer.LocalAddr(), "a", "b", "c")
+	require.Equal(t, []string{"a", "b", "c"}, readAll(t, peer))
+	send(t, peer, c.LocalAddr(), "a", "b", "c")
+	require.Equal(t, []string{"a", "b", "c"}, readAll(t, c))
+}
+
+func TestPacketConnOutFaults(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		faults Faults
+		want   []string
+	}{
+		{"loss", Faults{Loss: 1}, nil},
+		{"duplicate", Faults{Duplicate: 1}, []string{"a", "a", "b", "b"}},
+		{"reorder", Faults{Reorder: 1}, []string{"b", "a"}},
+		{"delay", Faults{Delay: 50 * time.Millisecond}, []string{"a", "b"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, peer := loopbackPair(t)
+			defer peer.Close()
+			c.Out = tc.faults
+
+			start := time.Now()
+			send(t, c, peer.LocalAddr(), "a", "b")
+			if tc.faults.Delay > 0 {
+				require.True(t, time.Since(start) < tc.faults.Delay, "delayed packets block the sender")
+			}
+			require.NoError(t, c.Close())
+			require.Equal(t, tc.want, readAll(t, peer))
+		})
+	}
+}
+
+func TestPacketConnReorderFlushedOnClose(t *testing.T) {
+	c, peer := loopbackPair(t)
+	defer peer.Close()
+	c.Out.Reorder = 1
+
+	send(t, c, peer.LocalAddr(), "a")
+	require.NoError(t, c.Close())
+	require.Equal(t, []string{"a"}, readAll(t, peer))
+}
+
+func TestPacketConnInFaults(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		faults Faults
+		want   []string
+	}{
+		{"loss", Faults{Loss: 1}, nil},
+		{"duplicate", Faults{Duplicate: 1}, []string{"a", "a", "b", "b"}},
+		{"reorder", Faults{Reorder: 1}, []string{"b", "a"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, peer := loopbackPair(t)
+			defer c.Close()
+			defer peer.Close()
+			c.In = tc.faults
+
+			send(t, peer, c.LocalAddr(), "a", "b")
+			require.Equal(t, tc.want, readAll(t, c))
+		})
+	}
+}
+
+func TestPacketConnInDelay(t *testing.T) {
+	c, peer := loopbackPair(t)
+	defer c.Close()
+	defer peer.Close()
+	c.In.Delay = 50 * time.Millisecond
+
+	send(t, peer, c.LocalAddr(), "a")
+	start := time.Now()
+	require.Equal(t, []string{"a"}, readAll(t, c)[:1])
+	require.True(t, time.Since(start) >= c.In.Delay)
+}
+
+func TestPacketConnHeldPacketNotLostOnTimeout(t *testing.T) {
+	c, peer := loopbackPair(t)
+	defer c.Close()
+	defer peer.Close()
+	c.In.Reorder = 1
+
+	// the only packet is held back, then returned by the read following the
+	// timeout
+	send(t, peer, c.LocalAddr(), "a")
+	require.Empty(t, readAll(t, c))
+	require.Equal(t, []string{"a"}, readAll(t, c))
+}
+
+func TestPacketConnSeeded(t *testing.T) {
+	faults := Faults{Loss: 0.5}
+	var runs [2][]string
+	for i := range runs {
+		c, peer := loopbackPair(t)
+		c.Out = faults
+		send(t, c, peer.LocalAddr(), "0", "1", "2", "3", "4", "5", "6", "7", "8", "9")
+		require.NoError(t, c.Close())
+		runs[i] = readAll(t, peer)
+		peer.Close()
+	}
+	require.Equal(t, runs[0], runs[1])
+}
@@ -0,0 +1,280 @@
+// Package dhcppcap reads the DHCPv4 and DHCPv6 messages of packet captures,
+// in the pcap and pcapng formats, and writes crafted messages to pcap files,
+// so that captures from the field can be replayed through the parsers in
+// regression tests, and what a test sends can be inspected with the usual
+// tools.
+//
+// A Reader decapsulates the UDP datagrams of Ethernet, Linux cooked, BSD
+// loopback and raw IP captures, and returns those sent from or to the DHCP
+// ports. The DHCP messages are left to the caller to parse, with the DHCPv4 and
+// DHCPv6 methods of Packet, so that the captures of malformed messages can be
+// tested as well.
+package dhcppcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+// Packet is a UDP datagram carrying a DHCP message.
+type Packet struct {
+	// Timestamp is the time at which the frame was captured.
+	Timestamp time.Time
+	// SrcMAC and DstMAC are the hardware addresses of the frame, when the
+	// link layer of the capture has them. DstMAC is always nil in Linux
+	// cooked captures, which only keep the source address.
+	SrcMAC, DstMAC net.HardwareAddr
+	// Src and Dst are the addresses of the datagram.
+	Src, Dst *net.UDPAddr
+	// Payload is the DHCP message.
+	Payload []byte
+	// Truncated is set if Payload lacks the end of the message, because the
+	// frame was cut at the snapshot length of the capture, or because the
+	// datagram was fragmented.
+	Truncated bool
+}
+
+// IsDHCPv4 returns true if the datagram is sent over IPv4 from or to the DHCPv4
+// ports.
+func (p *Packet) IsDHCPv4() bool {
+	if p.Src == nil || p.Dst == nil {
+		return false
+	}
+	return p.Src.IP.To4() != nil && (isPort(p.Src, dhcpv4.ServerPort, dhcpv4.ClientPort) || isPort(p.Dst, dhcpv4.ServerPort, dhcpv4.ClientPort))
+}
+
+// IsDHCPv6 returns true if the datagram is sent over IPv6 from or to the DHCPv6
+// ports.
+func (p *Packet) IsDHCPv6() bool {
+	if p.Src == nil || p.Dst == nil {
+		return false
+	}
+	return p.Src.IP.To4() == nil && (isPort(p.Src, dhcpv6.DefaultServerPort, dhcpv6.DefaultClientPort) || isPort(p.Dst, dhcpv6.DefaultServerPort, dhcpv6.DefaultClientPort))
+}
+
+func isPort(addr *net.UDPAddr, ports ...int) bool {
+	for _, port := range ports {
+		if addr.Port == port {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrTruncated is returned when parsing the payload of a truncated packet.
+var ErrTruncated = errors.New("truncated DHCP message")
+
+// DHCPv4 parses the payload as a DHCPv4 message.
+func (p *Packet) DHCPv4() (*dhcpv4.DHCPv4, error) {
+	if !p.IsDHCPv4() {
+		return nil, errors.New("not a DHCPv4 packet")
+	}
+	if p.Truncated {
+		return nil, ErrTruncated
+	}
+	return dhcpv4.FromBytes(p.Payload)
+}
+
+// DHCPv6 parses the payload as a DHCPv6 message.
+func (p *Packet) DHCPv6() (dhcpv6.DHCPv6, error) {
+	if !p.IsDHCPv6() {
+		return nil, errors.New("not a DHCPv6 packet")
+	}
+	if p.Truncated {
+		return nil, ErrTruncated
+	}
+	return dhcpv6.FromBytes(p.Payload)
+}
+
+// Link types of the captures, see
+// https://www.tcpdump.org/linktypes.html
+const (
+	linkTypeNull     = 0
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSLL = 113
+	linkTypeIPv4     = 228
+	linkTypeIPv6     = 229
+)
+
+// EtherTypes of the frames
+const (
+	etherTypeIPv4  = 0x0800
+	etherTypeIPv6  = 0x86dd
+	etherTypeVLAN  = 0x8100
+	etherTypeQinQ  = 0x88a8
+	etherTypeQinQ1 = 0x9100
+)
+
+const protocolUDP = 17
+
+// decodeFrame returns the DHCP datagram carried by a frame of the given link
+// type, or false if the frame is not one.
+func decodeFrame(linkType uint32, data []byte) (*Packet, bool) {
+	var (
+		p         Packet
+		etherType uint16
+	)
+	switch linkType {
+	case linkTypeEthernet:
+		if len(data) < 14 {
+			return nil, false
+		}
+		p.DstMAC = net.HardwareAddr(data[0:6])
+		p.SrcMAC = net.HardwareAddr(data[6:12])
+		etherType, data = binary.BigEndian.Uint16(data[12:14]), data[14:]
+		for etherType == etherTypeVLAN || etherType == etherTypeQinQ || etherType == etherTypeQinQ1 {
+			if len(data) < 4 {
+				return nil, false
+			}
+			etherType, data = binary.BigEndian.Uint16(data[2:4]), data[4:]
+		}
+	case linkTypeLinuxSLL:
+		if len(data) < 16 {
+			return nil, false
+		}
+		hwType, addrLen := binary.BigEndian.Uint16(data[2:4]), binary.BigEndian.Uint16(data[4:6])
+		if hwType == 1 && addrLen == 6 {
+			p.SrcMAC = net.HardwareAddr(data[6:12])
+		}
+		etherType, data = binary.BigEndian.Uint16(data[14:16]), data[16:]
+	case linkTypeNull:
+		// the address family is in the byte order of the capturing host
+		if len(data) < 4 {
+			return nil, false
+		}
+		family := binary.LittleEndian.Uint32(data[0:4])
+		if family > 0xffff {
+			family = binary.BigEndian.Uint32(data[0:4])
+		}
+		switch family {
+		case 2:
+			etherType = etherTypeIPv4
+		case 24, 28, 30:
+			// AF_INET6 of NetBSD and OpenBSD, FreeBSD, and Darwin
+			etherType = etherTypeIPv6
+		}
+		data = data[4:]
+	case linkTypeRaw:
+		if len(data) == 0 {
+			return nil, false
+		}
+		switch data[0] >> 4 {
+		case 4:
+			etherType = etherTypeIPv4
+		case 6:
+			etherType = etherTypeIPv6
+		}
+	case linkTypeIPv4:
+		etherType = etherTypeIPv4
+	case linkTypeIPv6:
+		etherType = etherTypeIPv6
+	}
+
+	var (
+		src, dst net.IP
+		ok       bool
+	)
+	switch etherType {
+	case etherTypeIPv4:
+		src, dst, data, ok = decodeIPv4(data, &p.Truncated)
+	case etherTypeIPv6:
+		src, dst, data, ok = decodeIPv6(data, &p.Truncated)
+	}
+	if !ok || len(data) < 8 {
+		return nil, false
+	}
+	p.Src = &net.UDPAddr{IP: src, Port: int(binary.BigEndian.Uint16(data[0:2]))}
+	p.Dst = &net.UDPAddr{IP: dst, Port: int(binary.BigEndian.Uint16(data[2:4]))}
+	if length := int(binary.BigEndian.Uint16(data[4:6])); length >= 8 && length <= len(data) {
+		data = data[:length]
+	} else if length > len(data) {
+		p.Truncated = true
+	}
+	p.Payload = data[8:]
+	if !p.IsDHCPv4() && !p.IsDHCPv6() {
+		return nil, false
+	}
+	return &p, true
+}
+
+// decodeIPv4 returns the addresses and the payload of an IPv4 packet carrying
+// UDP. Only the first fragment of a fragmented packet is returned, and marked
+// as truncated.
+func decodeIPv4(data []byte, truncated *bool) (net.IP, net.IP, []byte, bool) {
+	if len(data) < 20 || data[0]>>4 != 4 {
+		return nil, nil, nil, false
+	}
+	headerLen := int(data[0]&0x0f) * 4
+	if headerLen < 20 || len(data) < headerLen || data[9] != protocolUDP {
+		return nil, nil, nil, false
+	}
+	fragment := binary.BigEndian.Uint16(data[6:8])
+	if fragment&0x1fff != 0 {
+		return nil, nil, nil, false
+	}
+	if fragment&0x2000 != 0 {
+		*truncated = true
+	}
+	if total := int(binary.BigEndian.Uint16(data[2:4])); total >= headerLen && total <= len(data) {
+		data = data[:total]
+	}
+	src := net.IP(append([]byte(nil), data[12:16]...))
+	dst := net.IP(append([]byte(nil), data[16:20]...))
+	return src, dst, data[headerLen:], true
+}
+
+// Length of the IPv6 header, and extension headers that may precede the UDP
+// header
+const (
+	ipv6HopByHop     = 0
+	ipv6Routing      = 43
+	ipv6Fragment     = 44
+	ipv6DestOptions  = 60
+	ipv6HeaderLength = 40
+)
+
+// decodeIPv6 returns the addresses and the payload of an IPv6 packet carrying
+// UDP, after its extension headers. Only the first fragment of a fragmented
+// packet is returned, and marked as truncated.
+func decodeIPv6(data []byte, truncated *bool) (net.IP, net.IP, []byte, bool) {
+	if len(data) < ipv6HeaderLength || data[0]>>4 != 6 {
+		return nil, nil, nil, false
+	}
+	if length := int(binary.BigEndian.Uint16(data[4:6])); ipv6HeaderLength+length <= len(data) {
+		data = data[:ipv6HeaderLength+length]
+	}
+	src := net.IP(append([]byte(nil), data[8:24]...))
+	dst := net.IP(append([]byte(nil), data[24:40]...))
+	next, data := data[6], data[ipv6HeaderLength:]
+	for next != protocolUDP {
+		if len(data) < 8 {
+			return nil, nil, nil, false
+		}
+		switch next {
+		case ipv6HopByHop, ipv6Routing, ipv6DestOptions:
+			length := (int(data[1]) + 1) * 8
+			if len(data) < length {
+				return nil, nil, nil, false
+			}
+			next, data = data[0], data[length:]
+		case ipv6Fragment:
+			fragment := binary.BigEndian.Uint16(data[2:4])
+			if fragment&0xfff8 != 0 {
+				return nil, nil, nil, false
+			}
+			if fragment&1 != 0 {
+				*truncated = true
+			}
+			next, data = data[0], data[8:]
+		default:
+			return nil, nil, nil, false
+		}
+	}
+	return src, dst, data, true
+}
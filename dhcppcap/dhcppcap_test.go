@@ -0,0 +1,210 @@
+package dhcppcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	v4Client = &net.UDPAddr{IP: net.IPv4zero.To4(), Port: dhcpv4.ClientPort}
+	v4Server = &net.UDPAddr{IP: net.IPv4bcast.To4(), Port: dhcpv4.ServerPort}
+	v6Client = &net.UDPAddr{IP: net.ParseIP("fe80::1"), Port: dhcpv6.DefaultClientPort}
+	v6Server = &net.UDPAddr{IP: dhcpv6.AllDHCPRelayAgentsAndServers, Port: dhcpv6.DefaultServerPort}
+	hwaddr   = net.HardwareAddr{0, 0x11, 0x22, 0x33, 0x44, 0x55}
+)
+
+func testMessages(t *testing.T) (*dhcpv4.DHCPv4, dhcpv6.DHCPv6) {
+	discover, err := dhcpv4.NewDiscovery(hwaddr)
+	require.NoError(t, err)
+	solicit, err := dhcpv6.NewSolicitWithCID(dhcpv6.Duid{Type: dhcpv6.DUID_LL, HwType: iana.HwTypeEthernet, LinkLayerAddr: hwaddr})
+	require.NoError(t, err)
+	return discover, solicit
+}
+
+func TestWriteRead(t *testing.T) {
+	discover, solicit := testMessages(t)
+	ts := time.Date(2018, 7, 1, 12, 0, 0, 123456000, time.UTC)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteDHCPv4(ts, v4Client, v4Server, discover))
+	require.NoError(t, w.WritePacket(&Packet{
+		Timestamp: ts,
+		Src:       &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 53},
+		Dst:       &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 53},
+		Payload:   []byte("not DHCP"),
+	}))
+	require.NoError(t, w.WriteDHCPv6(ts.Add(time.Second), v6Client, v6Server, solicit))
+	require.Error(t, w.WritePacket(&Packet{Src: v4Client, Dst: v6Server}))
+
+	packets, err := ReadAll(&buf)
+	require.NoError(t, err)
+	require.Len(t, packets, 2)
+
+	p := packets[0]
+	require.Equal(t, ts, p.Timestamp)
+	require.Equal(t, net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, p.DstMAC)
+	require.Equal(t, v4Client, p.Src)
+	require.Equal(t, v4Server, p.Dst)
+	require.True(t, p.IsDHCPv4())
+	require.False(t, p.IsDHCPv6())
+	m4, err := p.DHCPv4()
+	require.NoError(t, err)
+	require.Equal(t, discover.ToBytes(), m4.ToBytes())
+	_, err = p.DHCPv6()
+	require.Error(t, err)
+
+	p = packets[1]
+	require.Equal(t, ts.Add(time.Second), p.Timestamp)
+	require.Equal(t, net.HardwareAddr{0x33, 0x33, 0, 1, 0, 2}, p.DstMAC)
+	require.Equal(t, v6Client.IP, p.Src.IP)
+	require.True(t, p.IsDHCPv6())
+	m6, err := p.DHCPv6()
+	require.NoError(t, err)
+	require.Equal(t, solicit.ToBytes(), m6.ToBytes())
+}
+
+func TestMakeFrameChecksums(t *testing.T) {
+	discover, solicit := testMessages(t)
+	frame, err := makeFrame(&Packet{Src: v4Client, Dst: v4Server, Payload: discover.ToBytes()})
+	require.NoError(t, err)
+	ip, udp := frame[14:34], frame[34:]
+	require.Equal(t, uint16(0), checksum(ip))
+	require.Equal(t, uint16(0), udpChecksum(ip[12:16], ip[16:20], udp)^0xffff)
+
+	frame, err = makeFrame(&Packet{Src: v6Client, Dst: v6Server, Payload: solicit.ToBytes()})
+	require.NoError(t, err)
+	ip, udp = frame[14:54], frame[54:]
+	require.Equal(t, uint16(0), udpChecksum(ip[8:24], ip[24:40], udp)^0xffff)
+}
+
+func TestDecodeFrame(t *testing.T) {
+	discover, _ := testMessages(t)
+	frame, err := makeFrame(&Packet{SrcMAC: hwaddr, Src: v4Client, Dst: v4Server, Payload: discover.ToBytes()})
+	require.NoError(t, err)
+	ip := frame[14:]
+
+	// 802.1Q tagged frame
+	tagged := append(append(append([]byte{}, frame[:12]...), 0x81, 0x00, 0x00, 0x2a), frame[12:]...)
+	p, ok := decodeFrame(linkTypeEthernet, tagged)
+	require.True(t, ok)
+	require.Equal(t, hwaddr, p.SrcMAC)
+	require.Equal(t, discover.ToBytes(), p.Payload)
+
+	// Linux cooked capture
+	sll := []byte{0, 4, 0, 1, 0, 6}
+	sll = append(sll, hwaddr...)
+	sll = append(sll, 0, 0, 0x08, 0x00)
+	p, ok = decodeFrame(linkTypeLinuxSLL, append(sll, ip...))
+	require.True(t, ok)
+	require.Equal(t, hwaddr, p.SrcMAC)
+	require.Nil(t, p.DstMAC)
+	require.Equal(t, discover.ToBytes(), p.Payload)
+
+	// BSD loopback, in both byte orders
+	for _, family := range [][]byte{{2, 0, 0, 0}, {0, 0, 0, 2}} {
+		p, ok = decodeFrame(linkTypeNull, append(family, ip...))
+		require.True(t, ok)
+		require.Equal(t, discover.ToBytes(), p.Payload)
+	}
+
+	// raw IP, cut at a snapshot length
+	p, ok = decodeFrame(linkTypeRaw, ip[:100])
+	require.True(t, ok)
+	require.True(t, p.Truncated)
+	_, err = p.DHCPv4()
+	require.Equal(t, ErrTruncated, err)
+
+	// not UDP
+	other := append([]byte{}, ip...)
+	other[9] = 6
+	_, ok = decodeFrame(linkTypeIPv4, other)
+	require.False(t, ok)
+
+	// not the first fragment
+	other = append([]byte{}, ip...)
+	other[7] = 1
+	_, ok = decodeFrame(linkTypeIPv4, other)
+	require.False(t, ok)
+}
+
+// pcapngBlock builds a little-endian pcapng block.
+func pcapngBlock(blockType uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	b := make([]byte, 8, 12+len(body))
+	binary.LittleEndian.PutUint32(b[0:4], blockType)
+	binary.LittleEndian.PutUint32(b[4:8], uint32(12+len(body)))
+	b = append(b, body...)
+	return append(b, b[4:8]...)
+}
+
+func TestReadPcapng(t *testing.T) {
+	_, solicit := testMessages(t)
+	frame, err := makeFrame(&Packet{Src: v6Client, Dst: v6Server, Payload: solicit.ToBytes()})
+	require.NoError(t, err)
+	ip := frame[14:]
+
+	var capture []byte
+	// section header, version 1.0, unknown section length
+	capture = append(capture, pcapngBlock(blockSectionHeader, []byte{
+		0x4d, 0x3c, 0x2b, 0x1a, 1, 0, 0, 0,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	})...)
+	// raw IP interface, with nanosecond timestamps
+	capture = append(capture, pcapngBlock(blockInterface, []byte{
+		linkTypeRaw, 0, 0, 0, 0, 0, 1, 0,
+		optionIfTSResolution, 0, 1, 0, 9, 0, 0, 0,
+		0, 0, 0, 0,
+	})...)
+	// a block that is skipped
+	capture = append(capture, pcapngBlock(0x0bad, []byte{1, 2, 3, 4})...)
+	ts := time.Date(2018, 7, 1, 12, 0, 0, 123456789, time.UTC)
+	epb := make([]byte, 20, 20+len(ip))
+	binary.LittleEndian.PutUint32(epb[4:8], uint32(uint64(ts.UnixNano())>>32))
+	binary.LittleEndian.PutUint32(epb[8:12], uint32(uint64(ts.UnixNano())))
+	binary.LittleEndian.PutUint32(epb[12:16], uint32(len(ip)))
+	binary.LittleEndian.PutUint32(epb[16:20], uint32(len(ip)))
+	capture = append(capture, pcapngBlock(blockEnhancedPacket, append(epb, ip...))...)
+	spb := make([]byte, 4, 4+len(ip))
+	binary.LittleEndian.PutUint32(spb[0:4], uint32(len(ip)))
+	capture = append(capture, pcapngBlock(blockSimplePacket, append(spb, ip...))...)
+
+	rd, err := NewReader(bytes.NewReader(capture))
+	require.NoError(t, err)
+	for _, want := range []time.Time{ts, {}} {
+		p, err := rd.Next()
+		require.NoError(t, err)
+		require.Equal(t, want, p.Timestamp)
+		require.False(t, p.Truncated)
+		m, err := p.DHCPv6()
+		require.NoError(t, err)
+		require.Equal(t, solicit.ToBytes(), m.ToBytes())
+	}
+	_, err = rd.Next()
+	require.Equal(t, io.EOF, err)
+
+	// cut in the middle of a block
+	rd, err = NewReader(bytes.NewReader(capture[:len(capture)-10]))
+	require.NoError(t, err)
+	_, err = rd.Next()
+	require.NoError(t, err)
+	_, err = rd.Next()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestNewReaderUnknownFormat(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("not a capture file")))
+	require.Equal(t, ErrUnknownFormat, err)
+}
@@ -0,0 +1,322 @@
+package dhcppcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"time"
+)
+
+// Magic numbers of the capture formats
+const (
+	pcapMagic      = 0xa1b2c3d4
+	pcapMagicNanos = 0xa1b23c4d
+	pcapngMagic    = 0x1a2b3c4d
+)
+
+// pcapng block types
+const (
+	blockSectionHeader   = 0x0a0d0d0a
+	blockInterface       = 1
+	blockSimplePacket    = 3
+	blockEnhancedPacket  = 6
+	optionEndOfOpt       = 0
+	optionIfTSResolution = 9
+)
+
+// maxFrameSize bounds the memory allocated for a frame, whatever the lengths
+// read from a corrupted capture.
+const maxFrameSize = 256 * 1024
+
+// ErrUnknownFormat is returned by NewReader if the capture is neither in the
+// pcap nor in the pcapng format.
+var ErrUnknownFormat = errors.New("unknown capture format")
+
+// frame is a captured frame, before decapsulation.
+type frame struct {
+	timestamp time.Time
+	linkType  uint32
+	data      []byte
+}
+
+// Reader reads the DHCP packets of a capture.
+type Reader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	next  func() (*frame, error)
+
+	// pcap
+	linkType uint32
+	nanos    bool
+
+	// pcapng
+	interfaces []pcapngInterface
+}
+
+// pcapngInterface is what a pcapng interface description block tells about
+// the packets captured on an interface.
+type pcapngInterface struct {
+	linkType uint32
+	snapLen  uint32
+	// the timestamps are in units of 10^-resolution seconds, or
+	// 2^-resolution seconds if pow2 is set
+	resolution uint8
+	pow2       bool
+}
+
+// NewReader returns a Reader of a capture in the pcap or in the pcapng format.
+func NewReader(r io.Reader) (*Reader, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, fmt.Errorf("cannot read capture header: %v", err)
+	}
+	rd := Reader{r: r}
+	switch {
+	case binary.BigEndian.Uint32(magic[:]) == blockSectionHeader:
+		var length [4]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			return nil, fmt.Errorf("cannot read section header: %v", unexpected(err))
+		}
+		if err := rd.readSectionHeader(length[:]); err != nil {
+			return nil, err
+		}
+		rd.next = rd.nextPcapng
+		return &rd, nil
+	case binary.LittleEndian.Uint32(magic[:]) == pcapMagic || binary.LittleEndian.Uint32(magic[:]) == pcapMagicNanos:
+		rd.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(magic[:]) == pcapMagic || binary.BigEndian.Uint32(magic[:]) == pcapMagicNanos:
+		rd.order = binary.BigEndian
+	default:
+		return nil, ErrUnknownFormat
+	}
+	rd.nanos = rd.order.Uint32(magic[:]) == pcapMagicNanos
+	// version, time zone, significant figures, snapshot length, link type
+	var hdr [20]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("cannot read capture header: %v", err)
+	}
+	rd.linkType = rd.order.Uint32(hdr[16:20]) & 0x0fffffff
+	rd.next = rd.nextPcap
+	return &rd, nil
+}
+
+// Next returns the next DHCPv4 or DHCPv6 packet of the capture, skipping the
+// other frames, or io.EOF at the end of the capture.
+func (rd *Reader) Next() (*Packet, error) {
+	for {
+		f, err := rd.next()
+		if err != nil {
+			return nil, err
+		}
+		if p, ok := decodeFrame(f.linkType, f.data); ok {
+			p.Timestamp = f.timestamp
+			return p, nil
+		}
+	}
+}
+
+// ReadAll returns the DHCPv4 and DHCPv6 packets of a capture.
+func ReadAll(r io.Reader) ([]*Packet, error) {
+	rd, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	var packets []*Packet
+	for {
+		p, err := rd.Next()
+		if err == io.EOF {
+			return packets, nil
+		}
+		if err != nil {
+			return packets, err
+		}
+		packets = append(packets, p)
+	}
+}
+
+func (rd *Reader) nextPcap() (*frame, error) {
+	var hdr [16]byte
+	if err := readFull(rd.r, hdr[:]); err != nil {
+		return nil, err
+	}
+	capLen := rd.order.Uint32(hdr[8:12])
+	if capLen > maxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes is too large", capLen)
+	}
+	f := frame{linkType: rd.linkType, data: make([]byte, capLen)}
+	if err := readFull(rd.r, f.data); err != nil {
+		return nil, unexpected(err)
+	}
+	sec, frac := int64(rd.order.Uint32(hdr[0:4])), int64(rd.order.Uint32(hdr[4:8]))
+	if !rd.nanos {
+		frac *= 1000
+	}
+	f.timestamp = time.Unix(sec, frac).UTC()
+	return &f, nil
+}
+
+// readSectionHeader reads a pcapng section header block, after its type and
+// its length, which are read before the byte order of the section is known.
+// The interfaces of the previous section are forgotten.
+func (rd *Reader) readSectionHeader(length []byte) error {
+	var magic [4]byte
+	if err := readFull(rd.r, magic[:]); err != nil {
+		return fmt.Errorf("cannot read section header: %v", unexpected(err))
+	}
+	switch {
+	case binary.LittleEndian.Uint32(magic[:]) == pcapngMagic:
+		rd.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(magic[:]) == pcapngMagic:
+		rd.order = binary.BigEndian
+	default:
+		return ErrUnknownFormat
+	}
+	n := rd.order.Uint32(length)
+	if n < 28 || n%4 != 0 || n > maxFrameSize {
+		return fmt.Errorf("invalid section header length %d", n)
+	}
+	// the rest of the block, which holds the version, the section length
+	// and options, is ignored
+	if _, err := io.CopyN(ioutil.Discard, rd.r, int64(n)-12); err != nil {
+		return fmt.Errorf("cannot read section header: %v", unexpected(err))
+	}
+	rd.interfaces = nil
+	return nil
+}
+
+func (rd *Reader) nextPcapng() (*frame, error) {
+	for {
+		var hdr [8]byte
+		if err := readFull(rd.r, hdr[:]); err != nil {
+			return nil, err
+		}
+		blockType := rd.order.Uint32(hdr[0:4])
+		if blockType == blockSectionHeader {
+			// a new section, possibly in another byte order
+			if err := rd.readSectionHeader(hdr[4:8]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		length := rd.order.Uint32(hdr[4:8])
+		if length < 12 || length%4 != 0 || length > maxFrameSize {
+			return nil, fmt.Errorf("invalid block length %d", length)
+		}
+		block := make([]byte, length-8)
+		if err := readFull(rd.r, block); err != nil {
+			return nil, unexpected(err)
+		}
+		body := block[:len(block)-4]
+		switch blockType {
+		case blockInterface:
+			if err := rd.readInterface(body); err != nil {
+				return nil, err
+			}
+		case blockEnhancedPacket:
+			return rd.enhancedPacket(body)
+		case blockSimplePacket:
+			return rd.simplePacket(body)
+		}
+	}
+}
+
+func (rd *Reader) readInterface(body []byte) error {
+	if len(body) < 8 {
+		return errors.New("short interface description block")
+	}
+	ifc := pcapngInterface{
+		linkType:   uint32(rd.order.Uint16(body[0:2])),
+		snapLen:    rd.order.Uint32(body[4:8]),
+		resolution: 6,
+	}
+	for opts := body[8:]; len(opts) >= 4; {
+		code, length := rd.order.Uint16(opts[0:2]), int(rd.order.Uint16(opts[2:4]))
+		if code == optionEndOfOpt || len(opts) < 4+length {
+			break
+		}
+		if code == optionIfTSResolution && length == 1 {
+			ifc.resolution, ifc.pow2 = opts[4]&0x7f, opts[4]&0x80 != 0
+		}
+		// the values are padded to 32 bits
+		if n := 4 + (length+3)/4*4; n <= len(opts) {
+			opts = opts[n:]
+		} else {
+			break
+		}
+	}
+	rd.interfaces = append(rd.interfaces, ifc)
+	return nil
+}
+
+func (rd *Reader) enhancedPacket(body []byte) (*frame, error) {
+	if len(body) < 20 {
+		return nil, errors.New("short enhanced packet block")
+	}
+	id := rd.order.Uint32(body[0:4])
+	if int(id) >= len(rd.interfaces) {
+		return nil, fmt.Errorf("packet of undescribed interface %d", id)
+	}
+	ifc := rd.interfaces[id]
+	ts := uint64(rd.order.Uint32(body[4:8]))<<32 | uint64(rd.order.Uint32(body[8:12]))
+	capLen := rd.order.Uint32(body[12:16])
+	if uint64(capLen) > uint64(len(body)-20) {
+		return nil, fmt.Errorf("invalid captured length %d", capLen)
+	}
+	return &frame{
+		timestamp: ifc.timestamp(ts),
+		linkType:  ifc.linkType,
+		data:      body[20 : 20+capLen],
+	}, nil
+}
+
+// simplePacket returns the frame of a simple packet block, which is captured
+// on the first interface and has no timestamp.
+func (rd *Reader) simplePacket(body []byte) (*frame, error) {
+	if len(rd.interfaces) == 0 || len(body) < 4 {
+		return nil, errors.New("invalid simple packet block")
+	}
+	ifc := rd.interfaces[0]
+	data := body[4:]
+	if origLen := rd.order.Uint32(body[0:4]); uint64(origLen) < uint64(len(data)) {
+		data = data[:origLen]
+	}
+	if ifc.snapLen != 0 && uint64(ifc.snapLen) < uint64(len(data)) {
+		data = data[:ifc.snapLen]
+	}
+	return &frame{linkType: ifc.linkType, data: data}, nil
+}
+
+// timestamp converts a timestamp in the units of the interface.
+func (ifc pcapngInterface) timestamp(ts uint64) time.Time {
+	if !ifc.pow2 && ifc.resolution <= 9 {
+		unit := uint64(math.Pow10(int(ifc.resolution)))
+		nsec := ts % unit * uint64(math.Pow10(9-int(ifc.resolution)))
+		return time.Unix(int64(ts/unit), int64(nsec)).UTC()
+	}
+	var seconds float64
+	if ifc.pow2 {
+		seconds = float64(ts) / math.Pow(2, float64(ifc.resolution))
+	} else {
+		seconds = float64(ts) / math.Pow10(int(ifc.resolution))
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+// readFull reads len(buf) bytes from r, or returns io.EOF if there are none
+// left, or io.ErrUnexpectedEOF if there are less.
+func readFull(r io.Reader, buf []byte) error {
+	_, err := io.ReadFull(r, buf)
+	return err
+}
+
+// unexpected turns io.EOF into io.ErrUnexpectedEOF, when a block is cut short.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
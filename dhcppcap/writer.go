@@ -0,0 +1,166 @@
+package dhcppcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+// Writer writes packets to a capture in the pcap format, as Ethernet frames
+// with microsecond timestamps.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter writes the header of a capture to w, and returns a Writer of its
+// packets.
+func NewWriter(w io.Writer) (*Writer, error) {
+	hdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(hdr[0:4], pcapMagic)
+	binary.LittleEndian.PutUint16(hdr[4:6], 2) // version 2.4
+	binary.LittleEndian.PutUint16(hdr[6:8], 4)
+	binary.LittleEndian.PutUint32(hdr[16:20], maxFrameSize)
+	binary.LittleEndian.PutUint32(hdr[20:24], linkTypeEthernet)
+	if _, err := w.Write(hdr); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+// WritePacket writes p as an Ethernet frame carrying an IPv4 or IPv6 packet,
+// depending on the family of its addresses. Truncated is ignored. If the
+// hardware addresses are not set, the source is all zeros, and the
+// destination is the broadcast address, or the multicast address of a
+// multicast destination.
+func (w *Writer) WritePacket(p *Packet) error {
+	if p.Src == nil || p.Dst == nil {
+		return errors.New("packet without addresses")
+	}
+	frame, err := makeFrame(p)
+	if err != nil {
+		return err
+	}
+	if len(frame) > maxFrameSize {
+		return fmt.Errorf("frame of %d bytes is too large", len(frame))
+	}
+	hdr := make([]byte, 16)
+	ts := p.Timestamp
+	binary.LittleEndian.PutUint32(hdr[0:4], uint32(ts.Unix()))
+	binary.LittleEndian.PutUint32(hdr[4:8], uint32(ts.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(hdr[8:12], uint32(len(frame)))
+	binary.LittleEndian.PutUint32(hdr[12:16], uint32(len(frame)))
+	if _, err := w.w.Write(append(hdr, frame...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteDHCPv4 writes a DHCPv4 message sent from src to dst at time ts.
+func (w *Writer) WriteDHCPv4(ts time.Time, src, dst *net.UDPAddr, m *dhcpv4.DHCPv4) error {
+	return w.WritePacket(&Packet{Timestamp: ts, Src: src, Dst: dst, Payload: m.ToBytes()})
+}
+
+// WriteDHCPv6 writes a DHCPv6 message sent from src to dst at time ts.
+func (w *Writer) WriteDHCPv6(ts time.Time, src, dst *net.UDPAddr, m dhcpv6.DHCPv6) error {
+	return w.WritePacket(&Packet{Timestamp: ts, Src: src, Dst: dst, Payload: m.ToBytes()})
+}
+
+// makeFrame encapsulates the payload of p in UDP, IP and Ethernet headers.
+func makeFrame(p *Packet) ([]byte, error) {
+	src4, dst4 := p.Src.IP.To4(), p.Dst.IP.To4()
+	src16, dst16 := p.Src.IP.To16(), p.Dst.IP.To16()
+	if src16 == nil || dst16 == nil || (src4 == nil) != (dst4 == nil) {
+		return nil, fmt.Errorf("invalid addresses %v and %v", p.Src, p.Dst)
+	}
+	udpLen := 8 + len(p.Payload)
+	if udpLen > 0xffff {
+		return nil, fmt.Errorf("payload of %d bytes is too large", len(p.Payload))
+	}
+
+	frame := make([]byte, 14, 14+ipv6HeaderLength+udpLen)
+	dstMAC, srcMAC := p.DstMAC, p.SrcMAC
+	if srcMAC == nil {
+		srcMAC = make(net.HardwareAddr, 6)
+	}
+	if dstMAC == nil {
+		dstMAC = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+		if dst4 == nil && dst16.IsMulticast() {
+			// RFC 2464, section 7
+			dstMAC = net.HardwareAddr{0x33, 0x33, dst16[12], dst16[13], dst16[14], dst16[15]}
+		}
+	}
+	if len(dstMAC) != 6 || len(srcMAC) != 6 {
+		return nil, fmt.Errorf("invalid hardware addresses %v and %v", srcMAC, dstMAC)
+	}
+	copy(frame[0:6], dstMAC)
+	copy(frame[6:12], srcMAC)
+
+	udp := make([]byte, 8, udpLen)
+	binary.BigEndian.PutUint16(udp[0:2], uint16(p.Src.Port))
+	binary.BigEndian.PutUint16(udp[2:4], uint16(p.Dst.Port))
+	binary.BigEndian.PutUint16(udp[4:6], uint16(udpLen))
+	udp = append(udp, p.Payload...)
+
+	if src4 != nil {
+		binary.BigEndian.PutUint16(frame[12:14], etherTypeIPv4)
+		ip := make([]byte, 20)
+		ip[0] = 4<<4 | 5 // version 4, 20 bytes header
+		binary.BigEndian.PutUint16(ip[2:4], uint16(20+udpLen))
+		ip[8] = 64 // TTL
+		ip[9] = protocolUDP
+		copy(ip[12:16], src4)
+		copy(ip[16:20], dst4)
+		binary.BigEndian.PutUint16(ip[10:12], checksum(ip))
+		binary.BigEndian.PutUint16(udp[6:8], udpChecksum(src4, dst4, udp))
+		frame = append(frame, ip...)
+	} else {
+		binary.BigEndian.PutUint16(frame[12:14], etherTypeIPv6)
+		ip := make([]byte, ipv6HeaderLength)
+		ip[0] = 6 << 4
+		binary.BigEndian.PutUint16(ip[4:6], uint16(udpLen))
+		ip[6] = protocolUDP
+		ip[7] = 64 // hop limit
+		copy(ip[8:24], src16)
+		copy(ip[24:40], dst16)
+		binary.BigEndian.PutUint16(udp[6:8], udpChecksum(src16, dst16, udp))
+		frame = append(frame, ip...)
+	}
+	return append(frame, udp...), nil
+}
+
+// udpChecksum computes the checksum of a UDP datagram, whose checksum field is
+// zero, with the pseudo-header of its addresses, see RFC 768 and RFC 8200,
+// section 8.1.
+func udpChecksum(src, dst net.IP, udp []byte) uint16 {
+	data := make([]byte, 0, 2*len(src)+8+len(udp))
+	data = append(data, src...)
+	data = append(data, dst...)
+	data = append(data, 0, 0, byte(len(udp)>>8), byte(len(udp)), 0, 0, 0, protocolUDP)
+	data = append(data, udp...)
+	if sum := checksum(data); sum != 0 {
+		return sum
+	}
+	// a zero checksum means that there is none
+	return 0xffff
+}
+
+// checksum computes the Internet checksum of data, see RFC 1071.
+func checksum(data []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i : i+2]))
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,240 @@
+// Package acd implements the IPv4 Address Conflict Detection of RFC 5227: a
+// host probes with ARP that an address is not in use on the link before it
+// configures it, then announces it so that the other hosts update their ARP
+// caches.
+//
+// The DHCPv4 clients probe the addresses they are offered before binding
+// them, see dhcpv4.Manager.CheckAddress, and decline those in use. The
+// servers can probe the addresses before offering them, as an alternative to
+// the ICMP echo requests suggested by RFC 2131, see server4.Server.Probe.
+// Both use a Detector, which other tools can use as well.
+package acd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Conn sends and receives the ARP packets of a link.
+type Conn interface {
+	// WriteARP broadcasts an ARP packet on the link.
+	WriteARP(p *Packet) error
+	// ReadARP returns the next ARP packet received on the link. Once the
+	// deadline set with SetReadDeadline has passed, it returns an error
+	// implementing net.Error whose Timeout method returns true.
+	ReadARP() (*Packet, error)
+	SetReadDeadline(t time.Time) error
+	// HardwareAddr returns the hardware address of the host on the link.
+	HardwareAddr() net.HardwareAddr
+	Close() error
+}
+
+// Timing holds the delays and the number of packets of the probes and the
+// announcements.
+type Timing struct {
+	// ProbeWait is the upper bound of the random delay before the first
+	// probe, so that the hosts starting together do not probe together.
+	ProbeWait time.Duration
+	// ProbeNum probes are sent, each ProbeMin to ProbeMax after the other.
+	ProbeNum int
+	ProbeMin time.Duration
+	ProbeMax time.Duration
+	// AnnounceWait is how long the answers to the last probe are waited
+	// for.
+	AnnounceWait time.Duration
+	// AnnounceNum announcements are sent, AnnounceInterval apart.
+	AnnounceNum      int
+	AnnounceInterval time.Duration
+}
+
+// DefaultTiming is the timing of RFC 5227 section 1.1. A probe lasts between
+// 5 and 7 seconds.
+var DefaultTiming = Timing{
+	ProbeWait:        time.Second,
+	ProbeNum:         3,
+	ProbeMin:         time.Second,
+	ProbeMax:         2 * time.Second,
+	AnnounceWait:     2 * time.Second,
+	AnnounceNum:      2,
+	AnnounceInterval: 2 * time.Second,
+}
+
+// ServerTiming is the timing for servers probing an address before offering
+// it, which cannot delay their offers by seconds: a single probe, answered
+// within half a second by any host using the address.
+var ServerTiming = Timing{
+	ProbeNum:     1,
+	AnnounceWait: 500 * time.Millisecond,
+}
+
+// pollInterval bounds how long a read blocks, so that the cancellation of the
+// context is noticed.
+const pollInterval = 100 * time.Millisecond
+
+// ConflictError is returned when an address is found in use by another host.
+type ConflictError struct {
+	IP           net.IP
+	HardwareAddr net.HardwareAddr
+}
+
+func (e *ConflictError) Error() string {
+	if e.HardwareAddr == nil {
+		// found by other means than ARP, e.g. ICMP
+		return fmt.Sprintf("address %v in use", e.IP)
+	}
+	return fmt.Sprintf("address %v in use by %v", e.IP, e.HardwareAddr)
+}
+
+// IsConflict returns true if err is a ConflictError.
+func IsConflict(err error) bool {
+	_, ok := err.(*ConflictError)
+	return ok
+}
+
+// Detector probes and announces addresses on the link of its Conn. Its
+// methods are safe for concurrent use, one probe or announcement running at a
+// time.
+type Detector struct {
+	Conn   Conn
+	Timing Timing
+
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+// NewDetector returns a Detector using conn, with the DefaultTiming.
+func NewDetector(conn Conn) *Detector {
+	return &Detector{Conn: conn, Timing: DefaultTiming}
+}
+
+// random returns a random duration in [min, max).
+func (d *Detector) random(min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	if d.rand == nil {
+		d.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return min + time.Duration(d.rand.Int63n(int64(max-min)))
+}
+
+// Probe checks that ip is not in use on the link, as described in RFC 5227
+// section 2.1.1. It returns a *ConflictError if another host uses ip, or
+// probes it at the same time.
+func (d *Detector) Probe(ctx context.Context, ip net.IP) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if ip.To4() == nil {
+		return fmt.Errorf("invalid IPv4 address %v", ip)
+	}
+	t := d.Timing
+	if err := d.listen(ctx, ip, d.random(0, t.ProbeWait)); err != nil {
+		return err
+	}
+	probe := NewProbe(d.Conn.HardwareAddr(), ip)
+	for i := 0; i < t.ProbeNum; i++ {
+		if err := d.Conn.WriteARP(probe); err != nil {
+			return err
+		}
+		wait := t.AnnounceWait
+		if i < t.ProbeNum-1 {
+			wait = d.random(t.ProbeMin, t.ProbeMax)
+		}
+		if err := d.listen(ctx, ip, wait); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Announce announces that the host starts using ip, as described in RFC 5227
+// section 2.3, once it is configured.
+func (d *Detector) Announce(ctx context.Context, ip net.IP) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if ip.To4() == nil {
+		return fmt.Errorf("invalid IPv4 address %v", ip)
+	}
+	announcement := NewAnnouncement(d.Conn.HardwareAddr(), ip)
+	for i := 0; i < d.Timing.AnnounceNum; i++ {
+		if i > 0 {
+			timer := time.NewTimer(d.Timing.AnnounceInterval)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+		if err := d.Conn.WriteARP(announcement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listen reads the ARP packets of the link for the given duration, and
+// returns a *ConflictError if one of them shows that ip is in use.
+func (d *Detector) listen(ctx context.Context, ip net.IP, duration time.Duration) error {
+	deadline := time.Now().Add(duration)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		now := time.Now()
+		if !now.Before(deadline) {
+			return nil
+		}
+		readDeadline := deadline
+		if poll := now.Add(pollInterval); poll.Before(readDeadline) {
+			readDeadline = poll
+		}
+		if err := d.Conn.SetReadDeadline(readDeadline); err != nil {
+			return err
+		}
+		p, err := d.Conn.ReadARP()
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+		if d.conflicts(p, ip) {
+			return &ConflictError{IP: ip, HardwareAddr: p.SenderHardwareAddr}
+		}
+	}
+}
+
+// conflicts returns true if p, received while probing ip, shows that another
+// host uses ip, or probes it too.
+func (d *Detector) conflicts(p *Packet, ip net.IP) bool {
+	if bytes.Equal(p.SenderHardwareAddr, d.Conn.HardwareAddr()) {
+		// our own packets, looped back
+		return false
+	}
+	if p.SenderIP.Equal(ip) {
+		return true
+	}
+	return p.IsProbe() && p.TargetIP.Equal(ip)
+}
+
+// Check probes ip on the link of the interface ifname with the DefaultTiming,
+// and announces it if it is not in use. It returns a *ConflictError if it is.
+// Its signature is the one of dhcpv4.Manager.CheckAddress.
+func Check(ctx context.Context, ifname string, ip net.IP) error {
+	conn, err := Listen(ifname)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	d := NewDetector(conn)
+	if err := d.Probe(ctx, ip); err != nil {
+		return err
+	}
+	return d.Announce(ctx, ip)
+}
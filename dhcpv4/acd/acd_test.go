@@ -0,0 +1,175 @@
+package acd
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// link is a broadcast domain connecting fake Conns. As on a real link, the
+// packets a Conn sends are also looped back to it.
+type link struct {
+	lock  sync.Mutex
+	conns []*fakeConn
+}
+
+type fakeConn struct {
+	link     *link
+	hwaddr   net.HardwareAddr
+	packets  chan *Packet
+	deadline time.Time
+	sent     []*Packet
+}
+
+type fakeTimeout struct{}
+
+func (fakeTimeout) Error() string   { return "timeout" }
+func (fakeTimeout) Timeout() bool   { return true }
+func (fakeTimeout) Temporary() bool { return true }
+
+func (l *link) attach(hwaddr net.HardwareAddr) *fakeConn {
+	c := &fakeConn{link: l, hwaddr: hwaddr, packets: make(chan *Packet, 64)}
+	l.lock.Lock()
+	l.conns = append(l.conns, c)
+	l.lock.Unlock()
+	return c
+}
+
+func (c *fakeConn) WriteARP(p *Packet) error {
+	c.link.lock.Lock()
+	defer c.link.lock.Unlock()
+	c.sent = append(c.sent, p)
+	for _, other := range c.link.conns {
+		other.packets <- p
+	}
+	return nil
+}
+
+func (c *fakeConn) ReadARP() (*Packet, error) {
+	timer := time.NewTimer(time.Until(c.deadline))
+	defer timer.Stop()
+	select {
+	case p := <-c.packets:
+		return p, nil
+	case <-timer.C:
+		return nil, fakeTimeout{}
+	}
+}
+
+func (c *fakeConn) SetReadDeadline(t time.Time) error {
+	c.deadline = t
+	return nil
+}
+
+func (c *fakeConn) HardwareAddr() net.HardwareAddr { return c.hwaddr }
+func (c *fakeConn) Close() error                   { return nil }
+
+func (c *fakeConn) sentPackets() []*Packet {
+	c.link.lock.Lock()
+	defer c.link.lock.Unlock()
+	return append([]*Packet(nil), c.sent...)
+}
+
+// defend answers the ARP requests for ip, as the host using it does, until
+// ctx is done.
+func (c *fakeConn) defend(ctx context.Context, ip net.IP) {
+	for {
+		select {
+		case p := <-c.packets:
+			if p.Operation == OperationRequest && p.TargetIP.Equal(ip) && !p.SenderIP.Equal(ip) {
+				c.WriteARP(&Packet{
+					Operation:          OperationReply,
+					SenderHardwareAddr: c.hwaddr,
+					SenderIP:           ip,
+					TargetHardwareAddr: p.SenderHardwareAddr,
+					TargetIP:           p.SenderIP,
+				})
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+var (
+	testIP       = net.IP{192, 168, 1, 10}
+	otherHwAddr  = net.HardwareAddr{0x52, 0x54, 0x00, 0xaa, 0xbb, 0xcc}
+	shortTiming  = Timing{ProbeWait: 10 * time.Millisecond, ProbeNum: 3, ProbeMin: 10 * time.Millisecond, ProbeMax: 20 * time.Millisecond, AnnounceWait: 50 * time.Millisecond, AnnounceNum: 2, AnnounceInterval: 10 * time.Millisecond}
+	testDeadline = 5 * time.Second
+)
+
+func newTestDetector(l *link) (*Detector, *fakeConn) {
+	conn := l.attach(testHwAddr)
+	d := NewDetector(conn)
+	d.Timing = shortTiming
+	return d, conn
+}
+
+func TestProbeFree(t *testing.T) {
+	var l link
+	d, conn := newTestDetector(&l)
+	ctx, cancel := context.WithTimeout(context.Background(), testDeadline)
+	defer cancel()
+
+	require.NoError(t, d.Probe(ctx, testIP))
+	sent := conn.sentPackets()
+	require.Len(t, sent, 3)
+	for _, p := range sent {
+		require.Equal(t, NewProbe(testHwAddr, testIP), p)
+	}
+
+	require.NoError(t, d.Announce(ctx, testIP))
+	sent = conn.sentPackets()[3:]
+	require.Len(t, sent, 2)
+	for _, p := range sent {
+		require.Equal(t, NewAnnouncement(testHwAddr, testIP), p)
+	}
+}
+
+func TestProbeInUse(t *testing.T) {
+	var l link
+	d, _ := newTestDetector(&l)
+	other := l.attach(otherHwAddr)
+	ctx, cancel := context.WithTimeout(context.Background(), testDeadline)
+	defer cancel()
+	go other.defend(ctx, testIP)
+
+	err := d.Probe(ctx, testIP)
+	require.True(t, IsConflict(err), "%v", err)
+	require.Equal(t, &ConflictError{IP: testIP, HardwareAddr: otherHwAddr}, err)
+	require.Equal(t, "address 192.168.1.10 in use by 52:54:00:aa:bb:cc", err.Error())
+
+	// another address is free
+	require.NoError(t, d.Probe(ctx, net.IP{192, 168, 1, 11}))
+}
+
+func TestProbeSimultaneous(t *testing.T) {
+	var l link
+	d, _ := newTestDetector(&l)
+	other := l.attach(otherHwAddr)
+	require.NoError(t, other.WriteARP(NewProbe(otherHwAddr, testIP)))
+
+	err := d.Probe(context.Background(), testIP)
+	require.True(t, IsConflict(err), "%v", err)
+}
+
+func TestProbeCanceled(t *testing.T) {
+	var l link
+	d, _ := newTestDetector(&l)
+	d.Timing = DefaultTiming
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, context.Canceled, d.Probe(ctx, testIP))
+	require.Equal(t, context.Canceled, d.Announce(ctx, testIP))
+}
+
+func TestProbeInvalidAddress(t *testing.T) {
+	var l link
+	d, _ := newTestDetector(&l)
+	require.Error(t, d.Probe(context.Background(), net.ParseIP("2001:db8::1")))
+	require.Error(t, d.Announce(context.Background(), nil))
+}
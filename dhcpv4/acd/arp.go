@@ -0,0 +1,112 @@
+package acd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Operation is the operation of an ARP packet.
+type Operation uint16
+
+// ARP operations
+const (
+	OperationRequest Operation = 1
+	OperationReply   Operation = 2
+)
+
+func (o Operation) String() string {
+	switch o {
+	case OperationRequest:
+		return "request"
+	case OperationReply:
+		return "reply"
+	}
+	return fmt.Sprintf("unknown (%d)", uint16(o))
+}
+
+// packetSize is the size of an ARP packet for IPv4 over Ethernet.
+const packetSize = 28
+
+// ErrInvalidPacket is returned when parsing an ARP packet that is not for
+// IPv4 over Ethernet.
+var ErrInvalidPacket = errors.New("not an ARP packet for IPv4 over Ethernet")
+
+// Packet is an ARP packet for IPv4 over Ethernet, see RFC 826.
+type Packet struct {
+	Operation          Operation
+	SenderHardwareAddr net.HardwareAddr
+	SenderIP           net.IP
+	TargetHardwareAddr net.HardwareAddr
+	TargetIP           net.IP
+}
+
+// NewProbe returns the ARP probe a host with the hardware address hwaddr
+// sends to check that ip is not in use: a request for ip with an
+// all-zeros sender address, so that the caches of the other hosts are not
+// polluted.
+func NewProbe(hwaddr net.HardwareAddr, ip net.IP) *Packet {
+	return &Packet{
+		Operation:          OperationRequest,
+		SenderHardwareAddr: hwaddr,
+		SenderIP:           net.IPv4zero,
+		TargetHardwareAddr: make(net.HardwareAddr, 6),
+		TargetIP:           ip,
+	}
+}
+
+// NewAnnouncement returns the ARP announcement a host with the hardware
+// address hwaddr sends when it starts using ip: a request for ip with ip as
+// sender address, so that the other hosts update their caches.
+func NewAnnouncement(hwaddr net.HardwareAddr, ip net.IP) *Packet {
+	return &Packet{
+		Operation:          OperationRequest,
+		SenderHardwareAddr: hwaddr,
+		SenderIP:           ip,
+		TargetHardwareAddr: make(net.HardwareAddr, 6),
+		TargetIP:           ip,
+	}
+}
+
+// IsProbe returns true if p is an ARP probe.
+func (p *Packet) IsProbe() bool {
+	return p.Operation == OperationRequest && p.SenderIP.Equal(net.IPv4zero)
+}
+
+// ToBytes returns the serialized ARP packet.
+func (p *Packet) ToBytes() []byte {
+	b := make([]byte, packetSize)
+	binary.BigEndian.PutUint16(b[0:2], 1)      // Ethernet
+	binary.BigEndian.PutUint16(b[2:4], 0x0800) // IPv4
+	b[4], b[5] = 6, 4
+	binary.BigEndian.PutUint16(b[6:8], uint16(p.Operation))
+	copy(b[8:14], p.SenderHardwareAddr)
+	copy(b[14:18], p.SenderIP.To4())
+	copy(b[18:24], p.TargetHardwareAddr)
+	copy(b[24:28], p.TargetIP.To4())
+	return b
+}
+
+// FromBytes parses an ARP packet for IPv4 over Ethernet. Trailing bytes, e.g.
+// the padding of short Ethernet frames, are ignored.
+func FromBytes(data []byte) (*Packet, error) {
+	if len(data) < packetSize ||
+		binary.BigEndian.Uint16(data[0:2]) != 1 ||
+		binary.BigEndian.Uint16(data[2:4]) != 0x0800 ||
+		data[4] != 6 || data[5] != 4 {
+		return nil, ErrInvalidPacket
+	}
+	return &Packet{
+		Operation:          Operation(binary.BigEndian.Uint16(data[6:8])),
+		SenderHardwareAddr: net.HardwareAddr(append([]byte(nil), data[8:14]...)),
+		SenderIP:           net.IP(append([]byte(nil), data[14:18]...)),
+		TargetHardwareAddr: net.HardwareAddr(append([]byte(nil), data[18:24]...)),
+		TargetIP:           net.IP(append([]byte(nil), data[24:28]...)),
+	}, nil
+}
+
+func (p *Packet) String() string {
+	return fmt.Sprintf("ARP %v %v/%v -> %v/%v", p.Operation,
+		p.SenderHardwareAddr, p.SenderIP, p.TargetHardwareAddr, p.TargetIP)
+}
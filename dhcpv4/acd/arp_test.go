@@ -0,0 +1,44 @@
+package acd
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testHwAddr = net.HardwareAddr{0x52, 0x54, 0x00, 0x12, 0x34, 0x56}
+
+func TestPacketToBytes(t *testing.T) {
+	p := NewProbe(testHwAddr, net.IPv4(192, 168, 1, 10))
+	require.Equal(t, []byte{
+		0, 1, // Ethernet
+		8, 0, // IPv4
+		6, 4,
+		0, 1, // request
+		0x52, 0x54, 0x00, 0x12, 0x34, 0x56,
+		0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0,
+		192, 168, 1, 10,
+	}, p.ToBytes())
+	require.True(t, p.IsProbe())
+	require.False(t, NewAnnouncement(testHwAddr, net.IPv4(192, 168, 1, 10)).IsProbe())
+}
+
+func TestPacketFromBytes(t *testing.T) {
+	a := NewAnnouncement(testHwAddr, net.IP{192, 168, 1, 10})
+	// with the padding of a short Ethernet frame
+	p, err := FromBytes(append(a.ToBytes(), make([]byte, 18)...))
+	require.NoError(t, err)
+	require.Equal(t, a, p)
+	require.Equal(t, "ARP request 52:54:00:12:34:56/192.168.1.10 -> 00:00:00:00:00:00/192.168.1.10", p.String())
+
+	_, err = FromBytes(a.ToBytes()[:27])
+	require.Equal(t, ErrInvalidPacket, err)
+
+	// IPv6 protocol type
+	data := a.ToBytes()
+	data[2], data[3] = 0x86, 0xdd
+	_, err = FromBytes(data)
+	require.Equal(t, ErrInvalidPacket, err)
+}
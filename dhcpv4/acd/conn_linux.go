@@ -0,0 +1,112 @@
+// +build linux
+
+package acd
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// packetConn is a Conn on an AF_PACKET socket receiving the ARP packets of an
+// Ethernet interface.
+type packetConn struct {
+	fd      int
+	ifindex int
+	hwaddr  net.HardwareAddr
+}
+
+// timeoutError is returned by ReadARP when the read deadline has passed.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Listen returns a Conn on the Ethernet interface ifname. It requires
+// CAP_NET_RAW.
+func Listen(ifname string) (Conn, error) {
+	iface, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return nil, err
+	}
+	if len(iface.HardwareAddr) != 6 {
+		return nil, &net.OpError{Op: "listen", Net: "arp", Err: unix.EINVAL}
+	}
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, int(htons(unix.ETH_P_ARP)))
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: htons(unix.ETH_P_ARP), Ifindex: iface.Index}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &packetConn{fd: fd, ifindex: iface.Index, hwaddr: iface.HardwareAddr}, nil
+}
+
+// WriteARP broadcasts p on the interface.
+func (c *packetConn) WriteARP(p *Packet) error {
+	addr := unix.SockaddrLinklayer{
+		Protocol: htons(unix.ETH_P_ARP),
+		Ifindex:  c.ifindex,
+		Halen:    6,
+		Addr:     [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+	return unix.Sendto(c.fd, p.ToBytes(), 0, &addr)
+}
+
+// ReadARP returns the next ARP packet for IPv4 received on the interface,
+// skipping the others.
+func (c *packetConn) ReadARP() (*Packet, error) {
+	buf := make([]byte, 128)
+	for {
+		n, _, err := unix.Recvfrom(c.fd, buf, 0)
+		if err == unix.EAGAIN || err == unix.EWOULDBLOCK {
+			return nil, timeoutError{}
+		}
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p, err := FromBytes(buf[:n]); err == nil {
+			return p, nil
+		}
+	}
+}
+
+// SetReadDeadline sets the receive timeout of the socket, so that ReadARP
+// returns once t has passed.
+func (c *packetConn) SetReadDeadline(t time.Time) error {
+	var tv unix.Timeval
+	if !t.IsZero() {
+		d := time.Until(t)
+		if d <= 0 {
+			// a zero timeout would block forever
+			d = time.Microsecond
+		}
+		tv = unix.NsecToTimeval(d.Nanoseconds())
+	}
+	return unix.SetsockoptTimeval(c.fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv)
+}
+
+// HardwareAddr returns the hardware address of the interface.
+func (c *packetConn) HardwareAddr() net.HardwareAddr {
+	return c.hwaddr
+}
+
+// Close closes the socket.
+func (c *packetConn) Close() error {
+	return unix.Close(c.fd)
+}
+
+// htons converts a short from host to network byte order.
+func htons(v uint16) uint16 {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	return *(*uint16)(unsafe.Pointer(&b[0]))
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package acd
+
+import (
+	"errors"
+)
+
+// Listen returns a Conn on the Ethernet interface ifname. It is only
+// implemented on Linux.
+func Listen(ifname string) (Conn, error) {
+	return nil, errors.New("address conflict detection is not supported on this platform")
+}
@@ -0,0 +1,111 @@
+package dhcpv4
+
+// This module implements the delayed authentication protocol, in which the
+// messages are authenticated with an HMAC-MD5 computed with a secret shared
+// by the client and the server.
+// https://tools.ietf.org/html/rfc3118#section-5
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// delayedAuthenticationInfoSize is the size of the authentication information
+// of the delayed authentication protocol: the secret ID and the HMAC.
+const delayedAuthenticationInfoSize = 4 + md5.Size
+
+// SignDelayedAuthentication adds to d an authentication option for the
+// delayed authentication protocol, replacing the existing one if any, with the
+// HMAC-MD5 of the message computed with key, the secret identified by
+// secretID. replay is the replay detection value, which must increase with
+// each message, e.g. a timestamp. d must not be modified afterwards, except
+// for its hops and giaddr fields, which relay agents change.
+func SignDelayedAuthentication(d *DHCPv4, secretID uint32, key []byte, replay uint64) error {
+	opt := &OptAuthentication{
+		Protocol:                  AuthenticationProtocolDelayed,
+		Algorithm:                 AuthenticationAlgorithmHMACMD5,
+		RDM:                       ReplayDetectionMonotonic,
+		ReplayDetection:           replay,
+		AuthenticationInformation: make([]byte, delayedAuthenticationInfoSize),
+	}
+	binary.BigEndian.PutUint32(opt.AuthenticationInformation[:4], secretID)
+	d.UpdateOption(opt)
+	msg := d.ToBytes()
+	if _, err := delayedAuthenticationInfo(msg); err != nil {
+		return err
+	}
+	copy(opt.AuthenticationInformation[4:], delayedAuthenticationMAC(msg, key))
+	return nil
+}
+
+// VerifyDelayedAuthentication checks the HMAC of the delayed authentication
+// option of data, a serialized message as it was received. keys returns the
+// secret identified by a secret ID, or nil if it is unknown. Checking the
+// replay detection value is left to the caller, who parses the message.
+func VerifyDelayedAuthentication(data []byte, keys func(secretID uint32) []byte) error {
+	msg := append([]byte{}, data...)
+	info, err := delayedAuthenticationInfo(msg)
+	if err != nil {
+		return err
+	}
+	secretID := binary.BigEndian.Uint32(info[:4])
+	key := keys(secretID)
+	if key == nil {
+		return fmt.Errorf("unknown secret ID %d", secretID)
+	}
+	mac := append([]byte{}, info[4:]...)
+	for i := 4; i < len(info); i++ {
+		info[i] = 0
+	}
+	if !hmac.Equal(mac, delayedAuthenticationMAC(msg, key)) {
+		return errors.New("invalid HMAC in authentication option")
+	}
+	return nil
+}
+
+// delayedAuthenticationInfo returns the authentication information of the
+// delayed authentication option of msg, a serialized message, as a slice of
+// msg.
+func delayedAuthenticationInfo(msg []byte) ([]byte, error) {
+	if len(msg) < HeaderSize {
+		return nil, ErrShortByteStream
+	}
+	if err := checkMagicCookie(msg[HeaderSize:]); err != nil {
+		return nil, err
+	}
+	options, err := splitOptions(msg[HeaderSize+4:])
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range options {
+		if opt.code != OptionAuthentication {
+			continue
+		}
+		if len(opt.data) < 11 || AuthenticationProtocol(opt.data[0]) != AuthenticationProtocolDelayed {
+			return nil, errors.New("not a delayed authentication option")
+		}
+		if AuthenticationAlgorithm(opt.data[1]) != AuthenticationAlgorithmHMACMD5 {
+			return nil, fmt.Errorf("unsupported authentication algorithm %d", opt.data[1])
+		}
+		if len(opt.data) != 11+delayedAuthenticationInfoSize {
+			return nil, fmt.Errorf("invalid authentication information length %d", len(opt.data)-11)
+		}
+		return opt.data[11:], nil
+	}
+	return nil, errors.New("no authentication option in the options field")
+}
+
+// delayedAuthenticationMAC computes the HMAC-MD5 of msg, a serialized message
+// whose authentication option has a zero HMAC. As RFC 3118 mandates, the hops
+// and giaddr fields are zeroed first, since relay agents change them.
+func delayedAuthenticationMAC(msg []byte, key []byte) []byte {
+	msg = append([]byte{}, msg...)
+	msg[3] = 0
+	copy(msg[24:28], []byte{0, 0, 0, 0})
+	mac := hmac.New(md5.New, key)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
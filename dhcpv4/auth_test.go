@@ -0,0 +1,55 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelayedAuthentication(t *testing.T) {
+	key := []byte("secret")
+	keys := func(secretID uint32) []byte {
+		if secretID == 42 {
+			return key
+		}
+		return nil
+	}
+	d, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	require.NoError(t, SignDelayedAuthentication(d, 42, key, 1000))
+	opt := d.GetOneOption(OptionAuthentication).(*OptAuthentication)
+	require.Equal(t, uint64(1000), opt.ReplayDetection)
+	require.Len(t, opt.AuthenticationInformation, 20)
+
+	data := d.ToBytes()
+	require.NoError(t, VerifyDelayedAuthentication(data, keys))
+
+	// relay agents may change hops and giaddr
+	d.SetHopCount(1)
+	d.SetGatewayIPAddr(net.IPv4(10, 0, 0, 1))
+	require.NoError(t, VerifyDelayedAuthentication(d.ToBytes(), keys))
+
+	// but not anything else
+	d.SetYourIPAddr(net.IPv4(10, 0, 0, 2))
+	require.Error(t, VerifyDelayedAuthentication(d.ToBytes(), keys))
+
+	// unknown secret
+	require.Error(t, VerifyDelayedAuthentication(data, func(uint32) []byte { return nil }))
+	require.Error(t, VerifyDelayedAuthentication(data, func(uint32) []byte { return []byte("other") }))
+
+	// resigning replaces the option
+	require.NoError(t, SignDelayedAuthentication(d, 42, key, 1001))
+	require.Len(t, d.GetOption(OptionAuthentication), 1)
+	require.NoError(t, VerifyDelayedAuthentication(d.ToBytes(), keys))
+}
+
+func TestVerifyDelayedAuthenticationMissing(t *testing.T) {
+	d, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	keys := func(uint32) []byte { return []byte("secret") }
+	require.Error(t, VerifyDelayedAuthentication(d.ToBytes(), keys))
+
+	d.AddOption(&OptAuthentication{Protocol: AuthenticationProtocolConfigurationToken})
+	require.Error(t, VerifyDelayedAuthentication(d.ToBytes(), keys))
+}
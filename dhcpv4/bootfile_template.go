@@ -0,0 +1,95 @@
+package dhcpv4
+
+import (
+	"bytes"
+	"strconv"
+	"text/template"
+)
+
+// BootfileTemplate is a template for boot file names and boot URLs, that is
+// evaluated against every request so that a single configuration can serve
+// per-client or per-architecture boot loaders. It uses the text/template
+// syntax, with the following variables:
+//
+//	{{.mac}}    the client hardware address, e.g. 00:11:22:33:44:55
+//	{{.arch}}   the first client system architecture type (option 93), in
+//	            decimal, e.g. 7 for EFI BC
+//	{{.uuid}}   the client machine identifier (option 97), e.g.
+//	            01234567-89ab-cdef-0123-456789abcdef
+//	{{.serial}} the circuit ID set by the relay agent (option 82), which
+//	            usually identifies the switch port or serial console
+//
+// Variables that cannot be found in the request evaluate to the empty string.
+// For example, "{{if eq .arch \"7\"}}ipxe.efi{{else}}undionly.kpxe{{end}}".
+type BootfileTemplate struct {
+	tmpl *template.Template
+}
+
+// NewBootfileTemplate parses text as a boot file template, and returns an
+// error if the template is malformed.
+func NewBootfileTemplate(text string) (*BootfileTemplate, error) {
+	tmpl, err := template.New("bootfile").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &BootfileTemplate{tmpl: tmpl}, nil
+}
+
+// Execute evaluates the template against the given request and returns the
+// resulting boot file name or URL.
+func (t *BootfileTemplate) Execute(request *DHCPv4) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, bootfileTemplateVariables(request)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// WithBootfileTemplate evaluates the template against request, and sets the
+// result as the boot file name of the packet, both in the header and in the
+// Bootfile Name option. If the template cannot be evaluated, or its result does
+// not fit in the header, the packet is left untouched.
+func WithBootfileTemplate(t *BootfileTemplate, request *DHCPv4) Modifier {
+	return func(d *DHCPv4) *DHCPv4 {
+		bootfile, err := t.Execute(request)
+		if err != nil {
+			return d
+		}
+		if err := d.SetBootFileName(bootfile); err != nil {
+			return d
+		}
+		d.AddOption(&OptBootfileName{BootfileName: []byte(bootfile)})
+		return d
+	}
+}
+
+// bootfileTemplateVariables extracts the template variables from a request.
+func bootfileTemplateVariables(request *DHCPv4) map[string]string {
+	vars := map[string]string{
+		"mac":    request.ClientHwAddrToString(),
+		"arch":   "",
+		"uuid":   "",
+		"serial": "",
+	}
+	if opt := request.GetOneOption(OptionClientSystemArchitectureType); opt != nil {
+		// also accept an OptionGeneric built by the caller
+		if at, err := ParseOptClientArchType(opt.ToBytes()); err == nil {
+			vars["arch"] = strconv.Itoa(int(at.ArchTypes[0]))
+		}
+	}
+	if opt := request.GetOneOption(OptionClientMachineIdentifier); opt != nil {
+		// also accept an OptionGeneric built by the caller
+		if id, err := ParseOptClientMachineIdentifier(opt.ToBytes()); err == nil {
+			if uuid, ok := id.UUID(); ok {
+				vars["uuid"] = formatUUID(uuid)
+			}
+		}
+	}
+	if opt := request.GetOneOption(OptionRelayAgentInformation); opt != nil {
+		// also accept an OptionGeneric built by the caller
+		if rai, err := ParseOptRelayAgentInformation(opt.ToBytes()); err == nil {
+			vars["serial"] = string(rai.CircuitID())
+		}
+	}
+	return vars
+}
@@ -0,0 +1,102 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func bootfileTemplateRequest(t *testing.T) *DHCPv4 {
+	d, err := New()
+	require.NoError(t, err)
+	d.SetHwAddrLen(6)
+	d.SetClientHwAddr(net.HardwareAddr{0, 0x11, 0x22, 0x33, 0x44, 0x55})
+	return d
+}
+
+func TestNewBootfileTemplate(t *testing.T) {
+	_, err := NewBootfileTemplate("{{.mac")
+	require.Error(t, err)
+
+	tmpl, err := NewBootfileTemplate("{{.unknown}}")
+	require.NoError(t, err)
+	_, err = tmpl.Execute(bootfileTemplateRequest(t))
+	require.Error(t, err, "unknown variable")
+}
+
+func TestBootfileTemplateExecute(t *testing.T) {
+	d := bootfileTemplateRequest(t)
+	d.AddOption(&OptClientArchType{ArchTypes: []iana.ArchType{iana.EFI_BC}})
+	d.AddOption(&OptionGeneric{
+		OptionCode: OptionClientMachineIdentifier,
+		Data: []byte{
+			0,
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+		},
+	})
+	d.AddOption(&OptionGeneric{
+		OptionCode: OptionRelayAgentInformation,
+		Data:       []byte{2, 2, 'x', 'y', 1, 5, 't', 't', 'y', 'S', '0'},
+	})
+
+	tmpl, err := NewBootfileTemplate("http://boot/{{.arch}}/{{.mac}}/{{.uuid}}?console={{.serial}}")
+	require.NoError(t, err)
+	url, err := tmpl.Execute(d)
+	require.NoError(t, err)
+	require.Equal(t, "http://boot/7/00:11:22:33:44:55/01234567-89ab-cdef-0123-456789abcdef?console=ttyS0", url)
+}
+
+func TestBootfileTemplateExecuteRelayAgentInformation(t *testing.T) {
+	d := bootfileTemplateRequest(t)
+	d.AddOption(&OptRelayAgentInformation{
+		Options: []RelayAgentSubOption{
+			{Code: RelayAgentRemoteID, Data: []byte("xy")},
+			{Code: RelayAgentCircuitID, Data: []byte("ttyS0")},
+		},
+	})
+
+	tmpl, err := NewBootfileTemplate("{{.serial}}")
+	require.NoError(t, err)
+	serial, err := tmpl.Execute(d)
+	require.NoError(t, err)
+	require.Equal(t, "ttyS0", serial)
+}
+
+func TestBootfileTemplateExecuteGenericArchType(t *testing.T) {
+	d := bootfileTemplateRequest(t)
+	d.AddOption(&OptionGeneric{
+		OptionCode: OptionClientSystemArchitectureType,
+		Data:       []byte{0, 9},
+	})
+
+	tmpl, err := NewBootfileTemplate("{{.arch}}")
+	require.NoError(t, err)
+	arch, err := tmpl.Execute(d)
+	require.NoError(t, err)
+	require.Equal(t, "9", arch)
+}
+
+func TestBootfileTemplateExecuteMissingVariables(t *testing.T) {
+	tmpl, err := NewBootfileTemplate("{{if eq .arch \"7\"}}ipxe.efi{{else}}undionly.kpxe{{end}}{{.uuid}}{{.serial}}")
+	require.NoError(t, err)
+	bootfile, err := tmpl.Execute(bootfileTemplateRequest(t))
+	require.NoError(t, err)
+	require.Equal(t, "undionly.kpxe", bootfile)
+}
+
+func TestWithBootfileTemplate(t *testing.T) {
+	request := bootfileTemplateRequest(t)
+	tmpl, err := NewBootfileTemplate("pxelinux.cfg/{{.mac}}")
+	require.NoError(t, err)
+
+	reply, err := New()
+	require.NoError(t, err)
+	reply = WithBootfileTemplate(tmpl, request)(reply)
+	require.Equal(t, "pxelinux.cfg/00:11:22:33:44:55", reply.BootFileNameToString())
+	opt := reply.GetOneOption(OptionBootfileName)
+	require.NotNil(t, opt)
+	require.Equal(t, []byte("pxelinux.cfg/00:11:22:33:44:55"), opt.(*OptBootfileName).BootfileName)
+}
@@ -0,0 +1,76 @@
+package dhcpv4
+
+// This module implements the plain BOOTP packets that DHCP extends.
+// https://tools.ietf.org/html/rfc951
+// https://tools.ietf.org/html/rfc1497
+
+import (
+	"net"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// BOOTPVendorSize is the size in bytes of the vendor area of a BOOTP packet.
+// Unlike DHCP, which allows a longer options field, BOOTP packets always fill
+// it, so that they are at least 300 bytes long.
+const BOOTPVendorSize = 64
+
+// NewBOOTPRequestForInterface builds a new BOOTP request with the hardware
+// address obtained from the specified interface.
+func NewBOOTPRequestForInterface(ifname string) (*DHCPv4, error) {
+	iface, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return nil, err
+	}
+	return NewBOOTPRequest(iface.HardwareAddr)
+}
+
+// NewBOOTPRequest builds a new BOOTP request, with a default Ethernet HW type
+// and the specified hardware address. It has no DHCP message type option, and
+// its vendor area is in the RFC 1497 format, that is the same as DHCP
+// options.
+func NewBOOTPRequest(hwaddr net.HardwareAddr) (*DHCPv4, error) {
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	d.SetOpcode(OpcodeBootRequest)
+	d.SetHwType(iana.HwTypeEthernet)
+	if err := d.SetClientHwAddr(hwaddr); err != nil {
+		return nil, err
+	}
+	d.bootp = true
+	return d, nil
+}
+
+// NewBOOTPReplyFromRequest builds a BOOTP reply from a request. The server
+// is expected to fill in the assigned address, its own address and name, and
+// the boot file name, using the modifiers or the setters.
+func NewBOOTPReplyFromRequest(request *DHCPv4, modifiers ...Modifier) (*DHCPv4, error) {
+	reply, err := NewReplyFromRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	reply.SetClientIPAddr(request.ClientIPAddr())
+	reply.bootp = true
+	for _, mod := range modifiers {
+		reply = mod(reply)
+	}
+	return reply, nil
+}
+
+// IsBOOTP returns true if the packet is a plain BOOTP packet, that is if it
+// has no DHCP message type option.
+func (d *DHCPv4) IsBOOTP() bool {
+	return d.MessageType() == nil
+}
+
+// isZero returns true if data only contains zeros.
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
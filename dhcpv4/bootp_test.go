@@ -0,0 +1,140 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBOOTPRequest(t *testing.T) {
+	hwaddr := net.HardwareAddr{1, 2, 3, 4, 5, 6}
+	request, err := NewBOOTPRequest(hwaddr)
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootRequest, request.Opcode())
+	require.True(t, request.IsBOOTP())
+
+	data := request.ToBytes()
+	require.Equal(t, HeaderSize+BOOTPVendorSize, len(data))
+	// the vendor area is in the RFC 1497 format
+	require.Equal(t, MagicCookie, data[HeaderSize:HeaderSize+4])
+	require.Equal(t, byte(OptionEnd), data[HeaderSize+4])
+
+	parsed, err := FromBytes(data)
+	require.NoError(t, err)
+	require.True(t, parsed.IsBOOTP())
+	require.Equal(t, request.TransactionID(), parsed.TransactionID())
+	require.Equal(t, hwaddr.String(), parsed.ClientHwAddrToString())
+}
+
+func TestNewBOOTPRequestWithOptions(t *testing.T) {
+	request, err := NewBOOTPRequest(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	request.AddOption(&OptHostName{HostName: "client"})
+	require.Equal(t, HeaderSize+BOOTPVendorSize, len(request.ToBytes()))
+
+	// options that do not fit make the vendor area longer
+	request.AddOption(&OptRootPath{Path: string(make([]byte, BOOTPVendorSize))})
+	require.Equal(t, HeaderSize+4+8+2+BOOTPVendorSize+1, len(request.ToBytes()))
+}
+
+func TestNewBOOTPReplyFromRequest(t *testing.T) {
+	request, err := NewBOOTPRequest(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	reply, err := NewBOOTPReplyFromRequest(request, func(d *DHCPv4) *DHCPv4 {
+		d.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+		d.SetServerIPAddr(net.IPv4(192, 168, 0, 1))
+		d.SetServerHostName("bootserver")
+		d.SetBootFileName("/boot/vmunix")
+		return d
+	})
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootReply, reply.Opcode())
+	require.Equal(t, request.TransactionID(), reply.TransactionID())
+
+	parsed, err := FromBytes(reply.ToBytes())
+	require.NoError(t, err)
+	require.True(t, parsed.IsBOOTP())
+	require.True(t, parsed.YourIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+	require.True(t, parsed.ServerIPAddr().Equal(net.IPv4(192, 168, 0, 1)))
+	require.Equal(t, "bootserver", parsed.ServerHostNameToString())
+	require.Equal(t, "/boot/vmunix", parsed.BootFileNameToString())
+}
+
+func TestFromBytesEmptyVendorArea(t *testing.T) {
+	request, err := NewBOOTPRequest(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	request.SetOptions(nil)
+	data := request.ToBytes()
+	// no magic cookie, only zeros
+	require.Equal(t, make([]byte, BOOTPVendorSize), data[HeaderSize:])
+
+	for _, packet := range [][]byte{data, data[:HeaderSize]} {
+		parsed, err := FromBytes(packet)
+		require.NoError(t, err)
+		require.True(t, parsed.IsBOOTP())
+		require.Empty(t, parsed.Options())
+		require.Equal(t, data, parsed.ToBytes())
+	}
+}
+
+func TestIsBOOTP(t *testing.T) {
+	discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	require.False(t, discover.IsBOOTP())
+}
+
+// loopbackBroadcaster sends the packets it is asked to broadcast to a single
+// UDP address instead.
+type loopbackBroadcaster struct {
+	conn *net.UDPConn
+}
+
+func (b loopbackBroadcaster) broadcast(packet *DHCPv4) error {
+	_, err := b.conn.Write(packet.ToBytes())
+	return err
+}
+
+func (b loopbackBroadcaster) Close() error {
+	return b.conn.Close()
+}
+
+func TestClientExchangeBOOTP(t *testing.T) {
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer server.Close()
+	out, err := net.DialUDP("udp4", nil, server.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	in, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	go func() {
+		buf := make([]byte, MaxUDPReceivedPacketSize)
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		request, err := FromBytes(buf[:n])
+		if err != nil {
+			return
+		}
+		reply, err := NewBOOTPReplyFromRequest(request)
+		if err != nil {
+			return
+		}
+		reply.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+		server.WriteTo(reply.ToBytes(), in.LocalAddr())
+	}()
+
+	c := NewClient()
+	c.ifname, c.sender, c.recvConn = "eth0", loopbackBroadcaster{out}, in
+	defer c.Close()
+	request, err := NewBOOTPRequest(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	conversation, err := c.ExchangeBOOTP("eth0", request)
+	require.NoError(t, err)
+	require.Len(t, conversation, 2)
+	require.Equal(t, request, conversation[0])
+	require.Equal(t, request.TransactionID(), conversation[1].TransactionID())
+	require.True(t, conversation[1].YourIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+}
@@ -146,8 +146,8 @@ func InformSelectForAck(ack dhcpv4.DHCPv4, replyPort uint16, selectedImage BootI
 	d.SetOpcode(dhcpv4.OpcodeBootRequest)
 	d.SetHwType(ack.HwType())
 	d.SetHwAddrLen(ack.HwAddrLen())
-	clientHwAddr := ack.ClientHwAddr()
-	d.SetClientHwAddr(clientHwAddr[:])
+	clientHwAddr := ack.ClientHwAddrRaw()
+	d.SetClientHwAddrRaw(clientHwAddr[:])
 	d.SetTransactionID(ack.TransactionID())
 	if ack.IsBroadcast() {
 		d.SetBroadcast()
@@ -163,10 +163,7 @@ func InformSelectForAck(ack dhcpv4.DHCPv4, replyPort uint16, selectedImage BootI
 	}
 
 	// Find server IP address
-	var serverIP net.IP
-	if opt := ack.GetOneOption(dhcpv4.OptionServerIdentifier); opt != nil {
-		serverIP = opt.(*dhcpv4.OptServerIdentifier).ServerID
-	}
+	serverIP := ack.ServerIdentifier()
 	if serverIP.To4() == nil {
 		return nil, fmt.Errorf("could not parse server identifier from ACK")
 	}
@@ -213,7 +210,9 @@ func NewReplyForInformList(inform *dhcpv4.DHCPv4, config ReplyConfig) (*dhcpv4.D
 	reply.SetYourIPAddr(net.IPv4zero)
 	reply.SetGatewayIPAddr(inform.GatewayIPAddr())
 	reply.SetServerIPAddr(config.ServerIP)
-	reply.SetServerHostName([]byte(config.ServerHostname))
+	if err := reply.SetServerHostName(config.ServerHostname); err != nil {
+		return nil, err
+	}
 
 	reply.AddOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeAck})
 	reply.AddOption(&dhcpv4.OptServerIdentifier{ServerID: config.ServerIP})
@@ -253,8 +252,12 @@ func NewReplyForInformSelect(inform *dhcpv4.DHCPv4, config ReplyConfig) (*dhcpv4
 	reply.SetYourIPAddr(net.IPv4zero)
 	reply.SetGatewayIPAddr(inform.GatewayIPAddr())
 	reply.SetServerIPAddr(config.ServerIP)
-	reply.SetServerHostName([]byte(config.ServerHostname))
-	reply.SetBootFileName([]byte(config.BootFileName))
+	if err := reply.SetServerHostName(config.ServerHostname); err != nil {
+		return nil, err
+	}
+	if err := reply.SetBootFileName(config.BootFileName); err != nil {
+		return nil, err
+	}
 
 	reply.AddOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeAck})
 	reply.AddOption(&dhcpv4.OptServerIdentifier{ServerID: config.ServerIP})
@@ -20,6 +20,9 @@ func ParseOptGeneric(data []byte) (*OptGeneric, error) {
 	if len(data) == 0 {
 		return nil, dhcpv4.ErrZeroLengthByteStream
 	}
+	if len(data) < 2 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
 	var (
 		length     int
 		optionData []byte
@@ -11,6 +11,10 @@ func TestParseOptGeneric(t *testing.T) {
 	_, err := ParseOptGeneric([]byte{})
 	require.Error(t, err, "error from empty bytestream")
 
+	// Missing length byte produces error
+	_, err = ParseOptGeneric([]byte{1})
+	require.Error(t, err, "error from missing length")
+
 	// Good parse
 	o, err := ParseOptGeneric([]byte{1, 1, 1})
 	require.NoError(t, err)
@@ -14,6 +14,10 @@ type OptVendorSpecificInformation struct {
 	Options []dhcpv4.Option
 }
 
+func init() {
+	dhcpv4.RegisterVendorOptionParser(AppleVendorID, parseOption)
+}
+
 // parseOption is similar to dhcpv4.ParseOption, except that it switches based
 // on the BSDP specific options.
 func parseOption(data []byte) (dhcpv4.Option, error) {
@@ -1,7 +1,12 @@
 package bsdp
 
 import (
+	"fmt"
+	"math/rand"
+	"net"
+	"reflect"
 	"testing"
+	"testing/quick"
 
 	"github.com/insomniacslk/dhcp/dhcpv4"
 	"github.com/stretchr/testify/require"
@@ -249,3 +254,79 @@ func TestOptVendorSpecificInformationGetOneOption(t *testing.T) {
 	foundOpt = o.GetOneOption(OptionVersion)
 	require.Equal(t, Version1_1, foundOpt.(*OptVersion).Version)
 }
+
+func randomBootImageID(r *rand.Rand) BootImageID {
+	return BootImageID{
+		IsInstall: r.Intn(2) == 0,
+		ImageType: BootImageType(r.Intn(128)),
+		Index:     uint16(r.Intn(1 << 16)),
+	}
+}
+
+// subOptionGenerators build random, valid instances of every BSDP option.
+var subOptionGenerators = []func(r *rand.Rand) dhcpv4.Option{
+	func(r *rand.Rand) dhcpv4.Option {
+		images := make([]BootImage, 1+r.Intn(4))
+		for i := range images {
+			images[i] = BootImage{ID: randomBootImageID(r), Name: fmt.Sprintf("image-%d", r.Intn(1000))}
+		}
+		return &OptBootImageList{Images: images}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		return &OptDefaultBootImageID{ID: randomBootImageID(r)}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		return &OptSelectedBootImageID{ID: randomBootImageID(r)}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		return &OptMachineName{Name: fmt.Sprintf("machine-%d", r.Intn(1000))}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		return &OptMessageType{Type: MessageType(1 + r.Intn(3))}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		return &OptReplyPort{Port: uint16(r.Intn(1 << 16))}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		return &OptServerIdentifier{ServerID: net.IP{byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256))}}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		return &OptServerPriority{Priority: r.Intn(1 << 16)}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		return &OptVersion{Version: []byte{byte(r.Intn(256)), byte(r.Intn(256))}}
+	},
+	func(r *rand.Rand) dhcpv4.Option {
+		data := make([]byte, r.Intn(8))
+		r.Read(data)
+		// codes from 131 on have no typed implementation
+		return &OptGeneric{OptionCode: dhcpv4.OptionCode(131 + r.Intn(124)), Data: data}
+	},
+}
+
+func TestOptVendorSpecificInformationRoundTrip(t *testing.T) {
+	f := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		o := &OptVendorSpecificInformation{}
+		for _, gen := range subOptionGenerators {
+			if r.Intn(2) == 0 {
+				o.Options = append(o.Options, gen(r))
+			}
+		}
+		data := o.ToBytes()
+		parsed, err := ParseOptVendorSpecificInformation(data)
+		if err != nil {
+			t.Logf("cannot parse %v: %v", data, err)
+			return false
+		}
+		if len(o.Options) == 0 {
+			return len(parsed.Options) == 0
+		}
+		if !reflect.DeepEqual(o, parsed) {
+			t.Logf("%#v != %#v", o, parsed)
+			return false
+		}
+		return reflect.DeepEqual(data, parsed.ToBytes())
+	}
+	require.NoError(t, quick.Check(f, nil))
+}
@@ -1,14 +1,14 @@
 package dhcpv4
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"net"
-	"os"
 	"time"
 
 	"golang.org/x/net/ipv4"
-	"golang.org/x/sys/unix"
 )
 
 // MaxUDPReceivedPacketSize is the (arbitrary) maximum UDP packet size supported
@@ -27,10 +27,74 @@ var (
 	DefaultWriteTimeout = 3 * time.Second
 )
 
-// Client is the object that actually performs the DHCP exchange. It currently
-// only has read and write timeout values.
+// Client is the object that actually performs the DHCP exchange. It has read
+// and write timeout values, and an optional retransmission strategy.
 type Client struct {
 	ReadTimeout, WriteTimeout time.Duration
+	// Retransmission, if set, is used by Exchange to retransmit the
+	// messages that do not get a reply in time. ReadTimeout is then
+	// ignored in favour of the timeouts of the strategy.
+	Retransmission RetransmissionStrategy
+
+	// LocalPort, if not zero, is the port from which the client sends its
+	// packets and waits for the replies, instead of ClientPort. Using an
+	// unprivileged port allows running SendReceiveUnicast without root,
+	// provided the server replies to the source port of the request.
+	LocalPort int
+	// ServerPort, if not zero, is the port to which the client sends its
+	// packets, instead of ServerPort, e.g. to test against a server
+	// listening on an unprivileged port.
+	ServerPort int
+
+	// PacketSocket makes the client send and receive whole Ethernet frames
+	// through an AF_PACKET socket bound to the interface, on Linux, instead
+	// of a raw IP socket and a UDP socket. This works on interfaces without
+	// address, whose raw IP broadcasts some configurations drop. It
+	// requires CAP_NET_RAW, and is not supported on the other platforms.
+	PacketSocket bool
+	// VLAN, if not zero, is the 802.1Q VLAN ID with which the PacketSocket
+	// transport tags the frames it sends, and which the frames it receives
+	// must have, to run the exchanges of a VLAN on its parent interface.
+	// The interfaces of the VLANs, such as eth0.100, need none.
+	VLAN uint16
+
+	// RapidCommit makes Exchange ask for the two-message exchange of RFC
+	// 4039: the Discover carries a Rapid Commit option, and an Ack with the
+	// option is accepted in place of an Offer. If the servers only offer,
+	// the exchange goes on with the Request as usual.
+	RapidCommit bool
+
+	// OfferWait, if not zero, is how long Exchange waits for other offers
+	// after the first one, as the select-timeout of ISC dhclient, instead
+	// of requesting the first one.
+	OfferWait time.Duration
+	// SelectOffer, if not nil, picks the offer Exchange requests among the
+	// ones received, in the order they were received, e.g. to prefer a
+	// server with PreferServer, or to spot rogue servers. If it returns
+	// nil, the exchange fails with ErrNoOfferSelected. The first offer is
+	// requested otherwise.
+	SelectOffer func(offers []*DHCPv4) *DHCPv4
+
+	// MaxMessageSize, if not zero, is advertised to the servers in the
+	// Discover and the Request, so that they can send replies larger than
+	// MaxMessageSize, e.g. the MTU of the interface. It is capped to
+	// MaxUDPReceivedPacketSize, the size of the receive buffers.
+	MaxMessageSize uint16
+
+	// Logger, if not nil, is where the client reports the retransmissions
+	// and the failures it recovers from. DefaultLogger is used otherwise.
+	Logger Logger
+
+	// sockets opened by Open, used by all the exchanges on ifname.
+	ifname   string
+	sender   broadcaster
+	recvConn net.Conn
+}
+
+// broadcaster broadcasts packets on an interface, the way the platform allows.
+type broadcaster interface {
+	broadcast(packet *DHCPv4) error
+	Close() error
 }
 
 // NewClient generates a new client to perform a DHCP exchange with, setting the
@@ -42,12 +106,49 @@ func NewClient() *Client {
 	}
 }
 
+// Open opens up front the sockets needed to run exchanges on the given
+// interface, instead of opening them for each exchange, so that the embedding
+// program can drop its privileges afterwards. On Linux and macOS, the
+// exchanges then only make the system calls listed in ExchangeSyscalls, which
+// allows applying a seccomp filter. The sockets are released by Close.
+func (c *Client) Open(ifname string) error {
+	if c.recvConn != nil {
+		return fmt.Errorf("client sockets already open on %s", c.ifname)
+	}
+	sender, conn, err := c.openSockets(ifname)
+	if err != nil {
+		return err
+	}
+	c.ifname, c.sender, c.recvConn = ifname, sender, conn
+	return nil
+}
+
+// Close releases the sockets opened by Open.
+func (c *Client) Close() error {
+	if c.recvConn == nil {
+		return nil
+	}
+	err := c.recvConn.Close()
+	if cerr := c.sender.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	c.ifname, c.sender, c.recvConn = "", nil, nil
+	return err
+}
+
 // MakeRawBroadcastPacket converts payload (a serialized DHCPv4 packet) into a
 // raw packet suitable for UDP broadcast.
 func MakeRawBroadcastPacket(payload []byte) ([]byte, error) {
+	return MakeRawBroadcastPacketFrom(payload, net.IPv4zero, ClientPort, ServerPort)
+}
+
+// MakeRawBroadcastPacketFrom works like MakeRawBroadcastPacket, but the packet
+// is sent from srcPort of src, or of 0.0.0.0 if src is nil, to dstPort instead
+// of the DHCP ports.
+func MakeRawBroadcastPacketFrom(payload []byte, src net.IP, srcPort, dstPort int) ([]byte, error) {
 	udp := make([]byte, 8)
-	binary.BigEndian.PutUint16(udp[:2], ClientPort)
-	binary.BigEndian.PutUint16(udp[2:4], ServerPort)
+	binary.BigEndian.PutUint16(udp[:2], uint16(srcPort))
+	binary.BigEndian.PutUint16(udp[2:4], uint16(dstPort))
 	binary.BigEndian.PutUint16(udp[4:6], uint16(8+len(payload)))
 	binary.BigEndian.PutUint16(udp[6:8], 0) // try to offload the checksum
 
@@ -58,7 +159,7 @@ func MakeRawBroadcastPacket(payload []byte) ([]byte, error) {
 		TTL:      64,
 		Protocol: 17, // UDP
 		Dst:      net.IPv4bcast,
-		Src:      net.IPv4zero,
+		Src:      src,
 	}
 	ret, err := h.Marshal()
 	if err != nil {
@@ -69,74 +170,41 @@ func MakeRawBroadcastPacket(payload []byte) ([]byte, error) {
 	return ret, nil
 }
 
-// MakeBroadcastSocket creates a socket that can be passed to unix.Sendto
-// that will send packets out to the broadcast address.
-func MakeBroadcastSocket(ifname string) (int, error) {
-	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_RAW, unix.IPPROTO_RAW)
-	if err != nil {
-		return fd, err
+// broadcastSource returns the source address of packet when it is broadcast:
+// the client address of the packet, which the clients renewing or rebinding a
+// lease have, or 0.0.0.0.
+func broadcastSource(packet *DHCPv4) net.IP {
+	if ip := packet.ClientIPAddr().To4(); ip != nil && !ip.IsUnspecified() {
+		return ip
 	}
-	err = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
-	if err != nil {
-		return fd, err
-	}
-	err = unix.SetsockoptInt(fd, unix.IPPROTO_IP, unix.IP_HDRINCL, 1)
-	if err != nil {
-		return fd, err
-	}
-	err = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_BROADCAST, 1)
-	if err != nil {
-		return fd, err
-	}
-	err = BindToInterface(fd, ifname)
-	if err != nil {
-		return fd, err
-	}
-	return fd, nil
-}
-
-// MakeListeningSocket creates a listening socket on 0.0.0.0 for the DHCP client
-// port and returns it.
-func MakeListeningSocket(ifname string) (int, error) {
-	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM, unix.IPPROTO_UDP)
-	if err != nil {
-		return fd, err
-	}
-	err = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
-	if err != nil {
-		return fd, err
-	}
-	var addr [4]byte
-	copy(addr[:], net.IPv4zero.To4())
-	if err = unix.Bind(fd, &unix.SockaddrInet4{Port: ClientPort, Addr: addr}); err != nil {
-		return fd, err
-	}
-	err = BindToInterface(fd, ifname)
-	if err != nil {
-		return fd, err
-	}
-	return fd, nil
+	return net.IPv4zero
 }
 
 // Exchange runs a full DORA transaction: Discover, Offer, Request, Acknowledge,
-// over UDP. Does not retry in case of failures. Returns a list of DHCPv4
+// over UDP. Does not retry in case of failures, but retransmits the Discover
+// and the Request if the client has a retransmission strategy. The Request
+// reuses the transaction ID of the Discover, and the secs field of each message
+// is set to the time elapsed since the exchange started. Returns a list of DHCPv4
 // structures representing the exchange. It can contain up to four elements,
 // ordered as Discovery, Offer, Request and Acknowledge. In case of errors, an
 // error is returned, and the list of DHCPv4 objects will be shorted than 4,
-// containing all the sent and received DHCPv4 messages.
+// containing all the sent and received DHCPv4 messages. If the client asks
+// for a rapid commit and the server honors it, the list only contains the
+// Discover and the Acknowledge. The Offer is the first one received, unless
+// the client has an OfferWait or a SelectOffer function.
 func (c *Client) Exchange(ifname string, discover *DHCPv4, modifiers ...Modifier) ([]*DHCPv4, error) {
-	conversation := make([]*DHCPv4, 0)
-	var err error
+	return c.ExchangeContext(context.Background(), ifname, discover, modifiers...)
+}
 
-	// Get our file descriptor for the broadcast socket.
-	sfd, err := MakeBroadcastSocket(ifname)
-	if err != nil {
-		return conversation, err
-	}
-	rfd, err := MakeListeningSocket(ifname)
+// ExchangeContext works like Exchange, but the exchange is aborted as soon as
+// the context is cancelled, in which case the context's error is returned.
+func (c *Client) ExchangeContext(ctx context.Context, ifname string, discover *DHCPv4, modifiers ...Modifier) ([]*DHCPv4, error) {
+	conversation := make([]*DHCPv4, 0)
+	sender, conn, release, err := c.sockets(ifname)
 	if err != nil {
 		return conversation, err
 	}
+	defer release()
 
 	// Discover
 	if discover == nil {
@@ -145,27 +213,52 @@ func (c *Client) Exchange(ifname string, discover *DHCPv4, modifiers ...Modifier
 			return conversation, err
 		}
 	}
+	if c.MaxMessageSize != 0 {
+		// copy the modifiers, which belong to the caller
+		modifiers = append(modifiers[:len(modifiers):len(modifiers)], WithMaximumMessageSize(c.maxMessageSize()))
+	}
 	for _, mod := range modifiers {
 		discover = mod(discover)
 	}
+	replyTypes := []MessageType{MessageTypeOffer}
+	if c.RapidCommit {
+		discover.UpdateOption(&OptRapidCommit{})
+		replyTypes = append(replyTypes, MessageTypeAck)
+	}
+	// All the messages of the exchange share the Discover's transaction ID,
+	// and their secs field counts from now.
+	t := &Transaction{ID: discover.TransactionID(), Start: time.Now()}
+	discover = WithTransaction(t)(discover)
+	c.filterReplies(conn, t.ID)
 	conversation = append(conversation, discover)
 
-	// Offer
-	offer, err := BroadcastSendReceive(sfd, rfd, discover, c.ReadTimeout, c.WriteTimeout, MessageTypeOffer)
+	// Offer, or Ack if the server honors the rapid commit
+	offer, err := c.broadcastSendReceive(ctx, t, sender, conn, discover, replyTypes...)
 	if err != nil {
 		return conversation, err
 	}
 	conversation = append(conversation, offer)
+	if *offer.MessageType() == MessageTypeAck {
+		if offer.GetOneOption(OptionRapidCommit) == nil {
+			return conversation, errors.New("got an ACK without Rapid Commit option in reply to a DISCOVER")
+		}
+		return conversation, nil
+	}
+	if offer, err = c.selectOffer(ctx, conn, discover, offer); err != nil {
+		return conversation, err
+	}
+	conversation[len(conversation)-1] = offer
 
 	// Request
 	request, err := NewRequestFromOffer(offer, modifiers...)
 	if err != nil {
 		return conversation, err
 	}
+	request = WithTransaction(t)(request)
 	conversation = append(conversation, request)
 
 	// Ack
-	ack, err := BroadcastSendReceive(sfd, rfd, request, c.ReadTimeout, c.WriteTimeout, MessageTypeAck)
+	ack, err := c.broadcastSendReceive(ctx, t, sender, conn, request, MessageTypeAck)
 	if err != nil {
 		return conversation, err
 	}
@@ -173,78 +266,336 @@ func (c *Client) Exchange(ifname string, discover *DHCPv4, modifiers ...Modifier
 	return conversation, nil
 }
 
-// BroadcastSendReceive broadcasts packet (with some write timeout) and waits for a
-// response up to some read timeout value. If the message type is not
-// MessageTypeNone, it will wait for a specific message type
-func BroadcastSendReceive(sendFd, recvFd int, packet *DHCPv4, readTimeout, writeTimeout time.Duration, messageType MessageType) (*DHCPv4, error) {
-	packetBytes, err := MakeRawBroadcastPacket(packet.ToBytes())
+// selectOffer collects the offers in reply to discover for OfferWait after
+// first, and returns the one that SelectOffer picks.
+func (c *Client) selectOffer(ctx context.Context, conn net.Conn, discover, first *DHCPv4) (*DHCPv4, error) {
+	offers := []*DHCPv4{first}
+	if c.OfferWait > 0 {
+		conn.SetReadDeadline(time.Now().Add(c.OfferWait))
+		for {
+			offer, err := receiveReply(ctx, conn, discover, MessageTypeOffer)
+			if err == ErrTimeout {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			offers = append(offers, offer)
+		}
+	}
+	if c.SelectOffer == nil {
+		return first, nil
+	}
+	if offer := c.SelectOffer(offers); offer != nil {
+		return offer, nil
+	}
+	return nil, ErrNoOfferSelected
+}
+
+// PreferServer returns a function for Client.SelectOffer picking the first
+// offer of the servers with the given identifiers, in order of preference,
+// or the first offer if none of them made one.
+func PreferServer(serverIDs ...net.IP) func(offers []*DHCPv4) *DHCPv4 {
+	return func(offers []*DHCPv4) *DHCPv4 {
+		for _, id := range serverIDs {
+			for _, offer := range offers {
+				if offer.ServerIdentifier().Equal(id) {
+					return offer
+				}
+			}
+		}
+		if len(offers) == 0 {
+			return nil
+		}
+		return offers[0]
+	}
+}
+
+// ExchangeBOOTP runs a plain BOOTP exchange, RFC 951: it broadcasts a
+// BOOTREQUEST and waits for the BOOTREPLY, which carries the assigned address
+// in yiaddr and the boot server and file in siaddr, sname and file. This is
+// for the servers that do not speak DHCP. If request is nil, one is built for
+// ifname. The request is retransmitted if the client has a retransmission
+// strategy. Returns the request and, if one was received, the reply.
+func (c *Client) ExchangeBOOTP(ifname string, request *DHCPv4, modifiers ...Modifier) ([]*DHCPv4, error) {
+	return c.ExchangeBOOTPContext(context.Background(), ifname, request, modifiers...)
+}
+
+// ExchangeBOOTPContext works like ExchangeBOOTP, but the exchange is aborted
+// as soon as the context is cancelled, in which case the context's error is
+// returned.
+func (c *Client) ExchangeBOOTPContext(ctx context.Context, ifname string, request *DHCPv4, modifiers ...Modifier) ([]*DHCPv4, error) {
+	conversation := make([]*DHCPv4, 0)
+	sender, conn, release, err := c.sockets(ifname)
 	if err != nil {
-		return nil, err
+		return conversation, err
 	}
+	defer release()
 
-	// Create a goroutine to perform the blocking send, and time it out after
-	// a certain amount of time.
-	var (
-		destination [4]byte
-		response    *DHCPv4
-	)
-	copy(destination[:], net.IPv4bcast.To4())
-	remoteAddr := unix.SockaddrInet4{Port: ClientPort, Addr: destination}
-	recvErrors := make(chan error, 1)
-	go func(errs chan<- error) {
-		conn, innerErr := net.FileConn(os.NewFile(uintptr(recvFd), ""))
+	// BOOTREQUEST
+	if request == nil {
+		request, err = NewBOOTPRequestForInterface(ifname)
 		if err != nil {
-			errs <- innerErr
-			return
+			return conversation, err
 		}
-		defer conn.Close()
-		conn.SetReadDeadline(time.Now().Add(readTimeout))
+	}
+	for _, mod := range modifiers {
+		request = mod(request)
+	}
+	t := &Transaction{ID: request.TransactionID(), Start: time.Now()}
+	request = WithTransaction(t)(request)
+	c.filterReplies(conn, t.ID)
+	conversation = append(conversation, request)
 
-		for {
-			buf := make([]byte, MaxUDPReceivedPacketSize)
-			n, _, _, _, innerErr := conn.(*net.UDPConn).ReadMsgUDP(buf, []byte{})
-			if innerErr != nil {
-				errs <- innerErr
-				return
-			}
+	// BOOTREPLY
+	reply, err := c.broadcastSendReceive(ctx, t, sender, conn, request, MessageTypeNone)
+	if err != nil {
+		return conversation, err
+	}
+	return append(conversation, reply), nil
+}
 
-			response, innerErr = FromBytes(buf[:n])
-			if err != nil {
-				errs <- innerErr
-				return
-			}
-			// check that this is a response to our message
-			if response.TransactionID() != packet.TransactionID() {
-				continue
-			}
-			// wait for a response message
-			if response.Opcode() != OpcodeBootReply {
-				continue
-			}
-			// if we are not requested to wait for a specific message type,
-			// return what we have
-			if messageType == MessageTypeNone {
-				break
-			}
-			// break if it's a reply of the desired type, continue otherwise
-			if response.MessageType() != nil && *response.MessageType() == messageType {
-				break
-			}
+// filterReplies restricts conn to the replies of the transaction xid, unless
+// it is the socket opened by Open, which all the exchanges share, and whose
+// filter cannot be changed with the system calls of ExchangeSyscalls.
+func (c *Client) filterReplies(conn net.Conn, xid uint32) {
+	if conn == c.recvConn {
+		return
+	}
+	if err := filterTransaction(conn, xid); err != nil {
+		loggerOrDefault(c.Logger).Printf("Cannot filter the replies of transaction %#x: %v", xid, err)
+	}
+}
+
+// openSockets opens the sockets for the exchanges on ifname, through the
+// transport of the client.
+func (c *Client) openSockets(ifname string) (broadcaster, net.Conn, error) {
+	if c.VLAN > 0xfff {
+		return nil, nil, fmt.Errorf("invalid VLAN ID %d", c.VLAN)
+	}
+	if c.PacketSocket {
+		return openPacketSockets(ifname, c.clientPort(), c.serverPort(), c.VLAN)
+	}
+	if c.VLAN != 0 {
+		return nil, nil, errors.New("VLAN tagging requires the PacketSocket transport")
+	}
+	return openSockets(ifname, c.clientPort(), c.serverPort())
+}
+
+// clientPort returns the port from which the client sends its packets.
+func (c *Client) clientPort() int {
+	if c.LocalPort == 0 {
+		return ClientPort
+	}
+	return c.LocalPort
+}
+
+// serverPort returns the port to which the client sends its packets.
+func (c *Client) serverPort() int {
+	if c.ServerPort == 0 {
+		return ServerPort
+	}
+	return c.ServerPort
+}
+
+// maxMessageSize returns the maximum message size the client advertises.
+func (c *Client) maxMessageSize() uint16 {
+	if c.MaxMessageSize > MaxUDPReceivedPacketSize {
+		return MaxUDPReceivedPacketSize
+	}
+	return c.MaxMessageSize
+}
+
+// sockets returns the sockets opened by Open or, if the client is not open,
+// new sockets for ifname. The returned function releases the sockets, if
+// they are not the client's.
+func (c *Client) sockets(ifname string) (broadcaster, net.Conn, func(), error) {
+	if c.recvConn != nil {
+		if ifname != c.ifname {
+			return nil, nil, nil, fmt.Errorf("client sockets are open on %s, not on %s", c.ifname, ifname)
 		}
-		recvErrors <- nil
-	}(recvErrors)
-	if err = unix.Sendto(sendFd, packetBytes, 0, &remoteAddr); err != nil {
+		return c.sender, c.recvConn, func() {}, nil
+	}
+	sender, conn, err := c.openSockets(ifname)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	release := func() {
+		conn.Close()
+		sender.Close()
+	}
+	return sender, conn, release, nil
+}
+
+// Release relinquishes the lease described by an acknowledge, unicasting a
+// DHCPRELEASE from the leased address to the server that granted it. Servers
+// do not reply to releases, so this only reports errors in sending it.
+func (c *Client) Release(lease *DHCPv4, modifiers ...Modifier) error {
+	release, err := NewReleaseFromACK(lease, modifiers...)
+	if err != nil {
+		return err
+	}
+	laddr := net.UDPAddr{IP: release.ClientIPAddr(), Port: c.clientPort()}
+	raddr := net.UDPAddr{
+		IP:   release.ServerIdentifier(),
+		Port: c.serverPort(),
+	}
+	conn, err := net.ListenUDP("udp4", &laddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout))
+	_, err = conn.WriteTo(release.ToBytes(), &raddr)
+	return err
+}
+
+// Decline broadcasts a DHCPDECLINE on the interface ifname for the address of
+// an acknowledge, found in use by another host, e.g. with acd.Check. Servers
+// do not reply to declines, so this only reports errors in sending it.
+func (c *Client) Decline(ifname string, ack *DHCPv4, modifiers ...Modifier) error {
+	decline, err := NewDecline(ack, modifiers...)
+	if err != nil {
+		return err
+	}
+	sender, _, release, err := c.sockets(ifname)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return sender.broadcast(decline)
+}
+
+// Reboot confirms a lease obtained before the host or the interface ifname
+// restarted, broadcasting a REQUEST in INIT-REBOOT state built by
+// NewRequestForInitReboot. It returns the reply of the server: either an ACK,
+// from which NewLeaseFromACK describes the confirmed lease, or a NAK, after
+// which the client must start over with a DISCOVER.
+func (c *Client) Reboot(ifname string, lease *Lease, modifiers ...Modifier) (*DHCPv4, error) {
+	return c.RebootContext(context.Background(), ifname, lease, modifiers...)
+}
+
+// RebootContext works like Reboot, but stops waiting for a reply as soon as
+// the context is cancelled, in which case the context's error is returned.
+func (c *Client) RebootContext(ctx context.Context, ifname string, lease *Lease, modifiers ...Modifier) (*DHCPv4, error) {
+	request, err := NewRequestForInitReboot(lease, modifiers...)
+	if err != nil {
+		return nil, err
+	}
+	return c.confirm(ctx, ifname, request)
+}
+
+// confirm broadcasts request, a REQUEST in INIT-REBOOT state, on ifname, and
+// returns the reply, which can be either an ACK or a NAK.
+func (c *Client) confirm(ctx context.Context, ifname string, request *DHCPv4) (*DHCPv4, error) {
+	sender, conn, release, err := c.sockets(ifname)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	c.filterReplies(conn, request.TransactionID())
+	t := &Transaction{ID: request.TransactionID(), Start: time.Now()}
+	return c.broadcastSendReceive(ctx, t, sender, conn, request, MessageTypeAck, MessageTypeNak)
+}
+
+// SendReceiveUnicast sends packet directly to the DHCP server at dst through
+// a regular UDP socket, and waits for a reply up to the client's read timeout.
+// No raw socket is needed, which makes it suitable for RENEW and DHCPINFORM
+// flows with a known server. The socket is bound to the client IP address of
+// the packet, if any.
+func (c *Client) SendReceiveUnicast(dst net.IP, packet *DHCPv4) (*DHCPv4, error) {
+	return c.SendReceiveUnicastContext(context.Background(), dst, packet)
+}
+
+// SendReceiveUnicastContext works like SendReceiveUnicast, but stops waiting
+// for a reply as soon as the context is cancelled, in which case the
+// context's error is returned.
+func (c *Client) SendReceiveUnicastContext(ctx context.Context, dst net.IP, packet *DHCPv4) (*DHCPv4, error) {
+	laddr := net.UDPAddr{IP: packet.ClientIPAddr(), Port: c.clientPort()}
+	raddr := net.UDPAddr{IP: dst, Port: c.serverPort()}
+	return sendReceiveUnicast(ctx, &laddr, &raddr, packet, c.ReadTimeout, c.WriteTimeout, MessageTypeNone)
+}
+
+// sendReceiveUnicast sends packet to raddr through a regular UDP socket bound
+// to laddr, and waits for a response up to some read timeout value. If the
+// message type is not MessageTypeNone, it will wait for a specific message
+// type.
+func sendReceiveUnicast(ctx context.Context, laddr, raddr *net.UDPAddr, packet *DHCPv4, readTimeout, writeTimeout time.Duration, messageTypes ...MessageType) (*DHCPv4, error) {
+	conn, err := net.ListenUDP("udp4", laddr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := conn.WriteTo(packet.ToBytes(), raddr); err != nil {
+		return nil, err
+	}
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	return receiveReply(ctx, conn, packet, messageTypes...)
+}
+
+// broadcastSendReceiveConn broadcasts packet through sender and waits for a
+// reply on conn up to some read timeout value.
+func broadcastSendReceiveConn(ctx context.Context, sender broadcaster, conn net.Conn, packet *DHCPv4, readTimeout time.Duration, messageTypes ...MessageType) (*DHCPv4, error) {
+	// Set up the receiving end before sending, so that no reply is lost.
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	if err := sender.broadcast(packet); err != nil {
 		return nil, err
 	}
+	return receiveReply(ctx, conn, packet, messageTypes...)
+}
 
-	select {
-	case err = <-recvErrors:
+// receiveReply reads from conn until a reply to packet is received, the read
+// deadline expires or the context is cancelled. Unless one of the message
+// types is MessageTypeNone, it will wait for a reply of one of them.
+func receiveReply(ctx context.Context, conn net.Conn, packet *DHCPv4, messageTypes ...MessageType) (*DHCPv4, error) {
+	// unblock the pending read if the context is cancelled
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	for {
+		buf := make([]byte, MaxUDPReceivedPacketSize)
+		n, err := conn.Read(buf)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return nil, ErrTimeout
+			}
 			return nil, err
 		}
-	case <-time.After(readTimeout):
-		return nil, errors.New("timed out while listening for replies")
-	}
 
-	return response, nil
+		response, err := FromBytes(buf[:n])
+		if err != nil {
+			// skip non-DHCP packets
+			continue
+		}
+		// check that this is a response to our message
+		if response.TransactionID() != packet.TransactionID() {
+			continue
+		}
+		// wait for a response message
+		if response.Opcode() != OpcodeBootReply {
+			continue
+		}
+		// return if it's a reply of a desired type, or if we are not
+		// requested to wait for a specific message type, continue otherwise
+		for _, messageType := range messageTypes {
+			if messageType == MessageTypeNone {
+				return response, nil
+			}
+			if response.MessageType() != nil && *response.MessageType() == messageType {
+				return response, nil
+			}
+		}
+	}
 }
@@ -0,0 +1,81 @@
+// +build darwin
+
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxBPFDevices is the number of /dev/bpf devices tried by MakeBroadcastSocket
+// before giving up.
+const maxBPFDevices = 256
+
+// MakeBroadcastSocket opens a BPF device attached to the given interface,
+// through which packets are broadcast as raw Ethernet frames. macOS does not
+// allow sending packets from 0.0.0.0 through raw IP sockets, hence the need
+// for BPF.
+func MakeBroadcastSocket(ifname string) (int, error) {
+	fd := -1
+	var err error
+	for i := 0; i < maxBPFDevices; i++ {
+		fd, err = unix.Open(fmt.Sprintf("/dev/bpf%d", i), unix.O_WRONLY, 0)
+		if err != unix.EBUSY {
+			break
+		}
+	}
+	if err != nil {
+		return fd, err
+	}
+	// struct ifreq: the interface name, followed by a union we do not use.
+	var ifreq [unix.IFNAMSIZ + 16]byte
+	if len(ifname) >= unix.IFNAMSIZ {
+		return fd, fmt.Errorf("interface name too long: %s", ifname)
+	}
+	copy(ifreq[:], ifname)
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.BIOCSETIF, uintptr(unsafe.Pointer(&ifreq[0]))); errno != 0 {
+		return fd, errno
+	}
+	return fd, nil
+}
+
+// writeBroadcast sends a packet built by MakeRawBroadcastPacket through a BPF
+// device opened by MakeBroadcastSocket, wrapping it in an Ethernet frame sent
+// to the broadcast address. The kernel fills in the source address.
+func writeBroadcast(fd int, packet []byte) error {
+	if len(packet) < 20 {
+		return fmt.Errorf("packet too short: %d bytes", len(packet))
+	}
+	frame := make([]byte, 14, 14+len(packet))
+	copy(frame[0:6], net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	binary.BigEndian.PutUint16(frame[12:14], 0x0800) // IPv4
+	frame = append(frame, packet...)
+
+	// On darwin the IP header is marshaled for raw sockets, with the length
+	// and fragment offset in host byte order and no checksum, all of which
+	// the kernel fixes up. Nothing does with BPF.
+	ip := frame[14:34]
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(packet)))
+	binary.BigEndian.PutUint16(ip[6:8], binary.LittleEndian.Uint16(ip[6:8]))
+	binary.BigEndian.PutUint16(ip[10:12], 0)
+	binary.BigEndian.PutUint16(ip[10:12], ipChecksum(ip))
+
+	_, err := unix.Write(fd, frame)
+	return err
+}
+
+// attachReplyFilter does nothing: macOS has no filters for the UDP sockets,
+// and the packets that are not replies are told apart once read.
+func attachReplyFilter(fd int, xid *uint32) error {
+	return nil
+}
+
+// ExchangeSyscalls lists the system calls made by Client.Exchange on the
+// sockets opened by Client.Open, for use in sandbox profiles. The Go runtime
+// makes its own system calls on top of these, notably to wait for the socket
+// to be readable.
+var ExchangeSyscalls = []string{"write", "read"}
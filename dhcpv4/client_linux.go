@@ -0,0 +1,73 @@
+// +build linux
+
+package dhcpv4
+
+import (
+	"net"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// MakeBroadcastSocket creates a socket that can be passed to unix.Sendto
+// that will send packets out to the broadcast address.
+func MakeBroadcastSocket(ifname string) (int, error) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_RAW, unix.IPPROTO_RAW)
+	if err != nil {
+		return fd, err
+	}
+	err = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
+	if err != nil {
+		return fd, err
+	}
+	err = unix.SetsockoptInt(fd, unix.IPPROTO_IP, unix.IP_HDRINCL, 1)
+	if err != nil {
+		return fd, err
+	}
+	err = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_BROADCAST, 1)
+	if err != nil {
+		return fd, err
+	}
+	err = BindToInterface(fd, ifname)
+	if err != nil {
+		return fd, err
+	}
+	return fd, nil
+}
+
+// writeBroadcast sends a packet built by MakeRawBroadcastPacket through a
+// socket created by MakeBroadcastSocket.
+func writeBroadcast(fd int, packet []byte) error {
+	var destination [4]byte
+	copy(destination[:], net.IPv4bcast.To4())
+	remoteAddr := unix.SockaddrInet4{Port: ClientPort, Addr: destination}
+	return unix.Sendto(fd, packet, 0, &remoteAddr)
+}
+
+// attachReplyFilter attaches the filter of replyFilter to fd, a UDP socket, so
+// that the client is not woken up by every packet sent to its port on a busy
+// network.
+func attachReplyFilter(fd int, xid *uint32) error {
+	return attachFilter(fd, replyFilter(xid))
+}
+
+// attachFilter attaches the BPF program prog to the socket fd, replacing its
+// filter, if any.
+func attachFilter(fd int, prog []bpf.Instruction) error {
+	raw, err := bpf.Assemble(prog)
+	if err != nil {
+		return err
+	}
+	filter := make([]unix.SockFilter, len(raw))
+	for i, ins := range raw {
+		filter[i] = unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	fprog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	return unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &fprog)
+}
+
+// ExchangeSyscalls lists the system calls made by Client.Exchange on the
+// sockets opened by Client.Open, for use in seccomp filters. The Go runtime
+// makes its own system calls on top of these, notably to wait for the socket
+// to be readable.
+var ExchangeSyscalls = []string{"sendto", "read"}
@@ -0,0 +1,38 @@
+// +build linux
+
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterTransaction(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer conn.Close()
+	sender, err := net.DialUDP("udp4", nil, conn.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer sender.Close()
+
+	discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	offer, err := NewReplyFromRequest(discover)
+	require.NoError(t, err)
+	other, err := NewReplyFromRequest(discover, WithTransactionID(discover.TransactionID()+1))
+	require.NoError(t, err)
+	require.NoError(t, filterTransaction(conn, offer.TransactionID()))
+
+	for _, m := range []*DHCPv4{discover, other, offer} {
+		_, err := sender.Write(m.ToBytes())
+		require.NoError(t, err)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, MaxUDPReceivedPacketSize)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, offer.ToBytes(), buf[:n])
+}
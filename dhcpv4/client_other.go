@@ -0,0 +1,19 @@
+// +build !linux,!darwin,!windows
+
+package dhcpv4
+
+import (
+	"errors"
+	"net"
+)
+
+// filterTransaction does nothing, since there are no sockets to filter.
+func filterTransaction(conn net.Conn, xid uint32) error {
+	return nil
+}
+
+// openSockets fails: the client has no transport for this platform. The rest
+// of the package, the encoding and decoding of the messages, still builds.
+func openSockets(ifname string, clientPort, serverPort int) (broadcaster, net.Conn, error) {
+	return nil, nil, errors.New("DHCPv4 exchanges are not supported on this platform")
+}
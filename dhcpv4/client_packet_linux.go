@@ -0,0 +1,282 @@
+// +build linux
+
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"time"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// packetConn sends and receives the messages of the client as whole Ethernet
+// frames, through an AF_PACKET socket bound to an interface. A BPF filter
+// only lets through the UDP packets to the client port, whose payload Read
+// returns, and Write broadcasts its payload from the hardware address of the
+// interface, since the interface may have no address to send from yet. With
+// a VLAN ID, the frames are sent tagged, and only the frames received tagged
+// with it are let through.
+type packetConn struct {
+	// file wraps the non-blocking socket, so that the reads honor the
+	// deadlines
+	file                   *os.File
+	fd                     int
+	ifindex                int
+	hwaddr                 net.HardwareAddr
+	clientPort, serverPort int
+	vlan                   uint16
+	buf                    []byte
+}
+
+// packetBroadcaster broadcasts packets through a packetConn.
+type packetBroadcaster struct {
+	conn *packetConn
+}
+
+func (b packetBroadcaster) broadcast(packet *DHCPv4) error {
+	_, err := b.conn.writeFrom(packet.ToBytes(), broadcastSource(packet))
+	return err
+}
+
+// Close does nothing: the socket is closed through the receiving connection.
+func (b packetBroadcaster) Close() error {
+	return nil
+}
+
+// openPacketSockets opens an AF_PACKET socket on the Ethernet interface
+// ifname, through which packets are both broadcast from clientPort to
+// serverPort and received on clientPort, in the VLAN of ID vlan if it is not
+// zero.
+func openPacketSockets(ifname string, clientPort, serverPort int, vlan uint16) (broadcaster, net.Conn, error) {
+	iface, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(iface.HardwareAddr) != 6 {
+		return nil, nil, fmt.Errorf("%s is not an Ethernet interface", ifname)
+	}
+	// protocol 0 so that the socket receives nothing until it is bound,
+	// once the filter is attached
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := attachFilter(fd, packetFilter(clientPort, vlan)); err != nil {
+		unix.Close(fd)
+		return nil, nil, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: htons(unix.ETH_P_IP), Ifindex: iface.Index}); err != nil {
+		unix.Close(fd)
+		return nil, nil, err
+	}
+	conn := &packetConn{
+		file:       os.NewFile(uintptr(fd), "packet:"+ifname),
+		fd:         fd,
+		ifindex:    iface.Index,
+		hwaddr:     iface.HardwareAddr,
+		clientPort: clientPort,
+		serverPort: serverPort,
+		vlan:       vlan,
+		buf:        make([]byte, 1<<16),
+	}
+	return packetBroadcaster{conn: conn}, conn, nil
+}
+
+// packetFilter returns a BPF program accepting the Ethernet frames of the
+// unfragmented UDP packets over IPv4 to port, received in the VLAN of ID vlan,
+// or untagged if vlan is zero.
+func packetFilter(port int, vlan uint16) []bpf.Instruction {
+	return append(vlanFilter(vlan), udpFilter(port)...)
+}
+
+// vlanFilter returns the BPF instructions dropping the frames not received in
+// the VLAN of ID vlan, or the tagged ones if vlan is zero. The kernel strips
+// the tags of the received frames, and keeps them aside for the filters.
+func vlanFilter(vlan uint16) []bpf.Instruction {
+	if vlan == 0 {
+		return []bpf.Instruction{
+			bpf.LoadExtension{Num: bpf.ExtVLANTagPresent},
+			bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0, SkipTrue: 1},
+			bpf.RetConstant{Val: 0},
+		}
+	}
+	return []bpf.Instruction{
+		bpf.LoadExtension{Num: bpf.ExtVLANTagPresent},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0, SkipTrue: 3},
+		bpf.LoadExtension{Num: bpf.ExtVLANTag},
+		bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0x0fff},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(vlan), SkipTrue: 1},
+		bpf.RetConstant{Val: 0},
+	}
+}
+
+// udpFilter returns the BPF instructions accepting the untagged Ethernet
+// frames of the unfragmented UDP packets over IPv4 to port, and dropping the
+// others.
+func udpFilter(port int) []bpf.Instruction {
+	return []bpf.Instruction{
+		// IPv4
+		bpf.LoadAbsolute{Off: 12, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: unix.ETH_P_IP, SkipTrue: 8},
+		// UDP
+		bpf.LoadAbsolute{Off: 14 + 9, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: unix.IPPROTO_UDP, SkipTrue: 6},
+		// neither a fragment nor followed by one
+		bpf.LoadAbsolute{Off: 14 + 6, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x3fff, SkipTrue: 4},
+		// destination port, after the IP header of variable length
+		bpf.LoadMemShift{Off: 14},
+		bpf.LoadIndirect{Off: 14 + 2, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: uint32(port), SkipTrue: 1},
+		bpf.RetConstant{Val: 1 << 16},
+		bpf.RetConstant{Val: 0},
+	}
+}
+
+// Read reads the frames received on the interface until one carries a UDP
+// packet to the client port, and copies its payload into p.
+func (c *packetConn) Read(p []byte) (int, error) {
+	n, _, err := c.ReadFrom(p)
+	return n, err
+}
+
+// ReadFrom works like Read, and also returns the source address of the UDP
+// packet, which makes packetConn a net.PacketConn.
+func (c *packetConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	for {
+		n, err := c.file.Read(c.buf)
+		if err != nil {
+			return 0, nil, &net.OpError{Op: "read", Net: "packet", Err: err}
+		}
+		if payload, src, ok := udpPayload(c.buf[:n], c.clientPort); ok {
+			return copy(p, payload), src, nil
+		}
+	}
+}
+
+// WriteTo broadcasts p, whatever addr is.
+func (c *packetConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	return c.Write(p)
+}
+
+// Write broadcasts p, a serialized message, in a frame sent from the hardware
+// address of the interface, and from 0.0.0.0.
+func (c *packetConn) Write(p []byte) (int, error) {
+	return c.writeFrom(p, net.IPv4zero)
+}
+
+// writeFrom works like Write, but the UDP packet is sent from src.
+func (c *packetConn) writeFrom(p []byte, src net.IP) (int, error) {
+	frame, err := makeBroadcastFrame(c.hwaddr, c.vlan, p, src, c.clientPort, c.serverPort)
+	if err != nil {
+		return 0, err
+	}
+	addr := unix.SockaddrLinklayer{
+		Protocol: htons(unix.ETH_P_IP),
+		Ifindex:  c.ifindex,
+		Halen:    6,
+		Addr:     [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+	if err := unix.Sendto(c.fd, frame, 0, &addr); err != nil {
+		return 0, &net.OpError{Op: "write", Net: "packet", Err: err}
+	}
+	return len(p), nil
+}
+
+// Close closes the socket.
+func (c *packetConn) Close() error {
+	return c.file.Close()
+}
+
+// LocalAddr returns the address on which the replies are received.
+func (c *packetConn) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: net.IPv4zero, Port: c.clientPort}
+}
+
+// RemoteAddr returns the address to which the messages are broadcast.
+func (c *packetConn) RemoteAddr() net.Addr {
+	return &net.UDPAddr{IP: net.IPv4bcast, Port: c.serverPort}
+}
+
+// SetDeadline, SetReadDeadline and SetWriteDeadline set the deadlines of the
+// socket.
+func (c *packetConn) SetDeadline(t time.Time) error {
+	return c.file.SetDeadline(t)
+}
+
+func (c *packetConn) SetReadDeadline(t time.Time) error {
+	return c.file.SetReadDeadline(t)
+}
+
+func (c *packetConn) SetWriteDeadline(t time.Time) error {
+	return c.file.SetWriteDeadline(t)
+}
+
+// makeBroadcastFrame wraps payload in the headers of a UDP packet broadcast
+// from srcPort of src to dstPort, built by MakeRawBroadcastPacketFrom, and in
+// an Ethernet frame broadcast from hwaddr, tagged with the VLAN ID vlan if it
+// is not zero.
+func makeBroadcastFrame(hwaddr net.HardwareAddr, vlan uint16, payload []byte, src net.IP, srcPort, dstPort int) ([]byte, error) {
+	packet, err := MakeRawBroadcastPacketFrom(payload, src, srcPort, dstPort)
+	if err != nil {
+		return nil, err
+	}
+	hlen := 14
+	if vlan != 0 {
+		hlen += 4
+	}
+	frame := make([]byte, hlen, hlen+len(packet))
+	copy(frame[0:6], net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	copy(frame[6:12], hwaddr)
+	if vlan != 0 {
+		// 802.1Q tag, with priority 0
+		binary.BigEndian.PutUint16(frame[12:14], unix.ETH_P_8021Q)
+		binary.BigEndian.PutUint16(frame[14:16], vlan&0x0fff)
+	}
+	binary.BigEndian.PutUint16(frame[hlen-2:hlen], unix.ETH_P_IP)
+	frame = append(frame, packet...)
+	// unlike raw IP sockets, packet sockets leave the checksum to us
+	ip := frame[hlen : hlen+20]
+	binary.BigEndian.PutUint16(ip[10:12], ipChecksum(ip))
+	return frame, nil
+}
+
+// udpPayload returns the payload and the source address of frame, an Ethernet
+// frame, if it carries an unfragmented UDP packet over IPv4 to port.
+func udpPayload(frame []byte, port int) ([]byte, *net.UDPAddr, bool) {
+	if len(frame) < 14+20 || binary.BigEndian.Uint16(frame[12:14]) != unix.ETH_P_IP {
+		return nil, nil, false
+	}
+	ip := frame[14:]
+	ihl := int(ip[0]&0x0f) * 4
+	if ip[0]>>4 != 4 || ihl < 20 || ip[9] != unix.IPPROTO_UDP {
+		return nil, nil, false
+	}
+	// the frame may be padded beyond the IP packet
+	totalLen := int(binary.BigEndian.Uint16(ip[2:4]))
+	if totalLen < ihl+8 || totalLen > len(ip) || binary.BigEndian.Uint16(ip[6:8])&0x3fff != 0 {
+		return nil, nil, false
+	}
+	udp := ip[ihl:totalLen]
+	length := int(binary.BigEndian.Uint16(udp[4:6]))
+	if int(binary.BigEndian.Uint16(udp[2:4])) != port || length < 8 || length > len(udp) {
+		return nil, nil, false
+	}
+	src := &net.UDPAddr{
+		IP:   net.IPv4(ip[12], ip[13], ip[14], ip[15]),
+		Port: int(binary.BigEndian.Uint16(udp[0:2])),
+	}
+	return udp[8:length], src, true
+}
+
+// htons converts a short from host to network byte order.
+func htons(v uint16) uint16 {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	return *(*uint16)(unsafe.Pointer(&b[0]))
+}
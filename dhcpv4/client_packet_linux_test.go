@@ -0,0 +1,86 @@
+// +build linux
+
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func TestBroadcastFrame(t *testing.T) {
+	hwaddr := net.HardwareAddr{0, 1, 2, 3, 4, 5}
+	payload := []byte{1, 2, 3}
+	frame, err := makeBroadcastFrame(hwaddr, 0, payload, net.IPv4zero, ClientPort, ServerPort)
+	require.NoError(t, err)
+	require.Equal(t, net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, net.HardwareAddr(frame[0:6]))
+	require.Equal(t, hwaddr, net.HardwareAddr(frame[6:12]))
+	// a header with a valid checksum sums to zero
+	require.Equal(t, uint16(0), ipChecksum(frame[14:34]))
+
+	got, src, ok := udpPayload(frame, ServerPort)
+	require.True(t, ok)
+	require.Equal(t, payload, got)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4zero, Port: ClientPort}, src)
+	_, _, ok = udpPayload(frame, ClientPort)
+	require.False(t, ok)
+	// padded to the minimum frame size
+	got, _, ok = udpPayload(append(frame, make([]byte, 60-len(frame))...), ServerPort)
+	require.True(t, ok)
+	require.Equal(t, payload, got)
+	_, _, ok = udpPayload(frame[:40], ServerPort)
+	require.False(t, ok)
+}
+
+func TestBroadcastFrameVLAN(t *testing.T) {
+	hwaddr := net.HardwareAddr{0, 1, 2, 3, 4, 5}
+	src := net.IPv4(192, 168, 0, 10)
+	frame, err := makeBroadcastFrame(hwaddr, 100, []byte{1, 2, 3}, src, ClientPort, ServerPort)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x81, 0x00, 0x00, 100, 0x08, 0x00}, frame[12:18])
+	require.Equal(t, uint16(0), ipChecksum(frame[18:38]))
+	require.Equal(t, net.IP{192, 168, 0, 10}, net.IP(frame[18+12:18+16]))
+
+	// the kernel strips the tag before the frames are read
+	untagged := append(append([]byte{}, frame[:12]...), frame[16:]...)
+	got, peer, ok := udpPayload(untagged, ServerPort)
+	require.True(t, ok)
+	require.Equal(t, []byte{1, 2, 3}, got)
+	require.True(t, peer.IP.Equal(src))
+}
+
+func TestPacketFilter(t *testing.T) {
+	// the VLAN extensions are not supported by the VM, but must assemble
+	for _, vlan := range []uint16{0, 100} {
+		_, err := bpf.Assemble(packetFilter(ClientPort, vlan))
+		require.NoError(t, err)
+	}
+
+	vm, err := bpf.NewVM(udpFilter(ClientPort))
+	require.NoError(t, err)
+	toClient, err := makeBroadcastFrame(net.HardwareAddr{0, 1, 2, 3, 4, 5}, 0, []byte{1, 2, 3}, nil, ServerPort, ClientPort)
+	require.NoError(t, err)
+	n, err := vm.Run(toClient)
+	require.NoError(t, err)
+	require.NotZero(t, n)
+
+	toServer, err := makeBroadcastFrame(net.HardwareAddr{0, 1, 2, 3, 4, 5}, 0, []byte{1, 2, 3}, nil, ClientPort, ServerPort)
+	require.NoError(t, err)
+	n, err = vm.Run(toServer)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// a fragment
+	toClient[14+6] |= 0x20
+	n, err = vm.Run(toClient)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestOpenPacketSocketsNotEthernet(t *testing.T) {
+	c := NewClient()
+	c.PacketSocket = true
+	require.Error(t, c.Open("lo"))
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package dhcpv4
+
+import (
+	"errors"
+	"net"
+)
+
+// openPacketSockets fails: AF_PACKET sockets only exist on Linux.
+func openPacketSockets(ifname string, clientPort, serverPort int, vlan uint16) (broadcaster, net.Conn, error) {
+	return nil, nil, errors.New("packet sockets are only supported on Linux")
+}
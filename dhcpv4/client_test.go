@@ -0,0 +1,440 @@
+package dhcpv4
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// utility function to create a pair of connected UDP sockets on the loopback
+// interface.
+func setUpLoopbackConns(t *testing.T) (*net.UDPConn, *net.UDPConn) {
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	client, err := net.DialUDP("udp4", nil, server.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	return server, client
+}
+
+func TestNewClient(t *testing.T) {
+	c := NewClient()
+	require.NotNil(t, c)
+	require.Equal(t, DefaultReadTimeout, c.ReadTimeout)
+	require.Equal(t, DefaultWriteTimeout, c.WriteTimeout)
+}
+
+func TestReceiveReply(t *testing.T) {
+	server, client := setUpLoopbackConns(t)
+	defer server.Close()
+	defer client.Close()
+
+	request, err := New()
+	require.NoError(t, err)
+	// a packet with the wrong transaction ID is skipped
+	other, err := NewReplyFromRequest(request)
+	require.NoError(t, err)
+	other.SetTransactionID(request.TransactionID() + 1)
+	reply, err := NewReplyFromRequest(request, func(d *DHCPv4) *DHCPv4 {
+		d.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+		return d
+	})
+	require.NoError(t, err)
+
+	_, err = server.WriteTo(other.ToBytes(), client.LocalAddr())
+	require.NoError(t, err)
+	_, err = server.WriteTo(reply.ToBytes(), client.LocalAddr())
+	require.NoError(t, err)
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	response, err := receiveReply(context.Background(), client, request, MessageTypeAck)
+	require.NoError(t, err)
+	require.Equal(t, request.TransactionID(), response.TransactionID())
+}
+
+func TestReceiveReplyLarge(t *testing.T) {
+	server, client := setUpLoopbackConns(t)
+	defer server.Close()
+	defer client.Close()
+
+	request, err := New()
+	require.NoError(t, err)
+	// a reply larger than the Ethernet MTU, as sent to a client that
+	// advertised a large maximum message size
+	reply, err := NewReplyFromRequest(request,
+		WithMessageType(MessageTypeAck),
+		WithOption(&OptionGeneric{OptionCode: OptionCode(224), Data: make([]byte, 2000)}),
+	)
+	require.NoError(t, err)
+	require.True(t, len(reply.ToBytes()) > 2000)
+	_, err = server.WriteTo(reply.ToBytes(), client.LocalAddr())
+	require.NoError(t, err)
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	response, err := receiveReply(context.Background(), client, request, MessageTypeAck)
+	require.NoError(t, err)
+	require.True(t, reply.Equal(response))
+}
+
+func TestClientMaxMessageSize(t *testing.T) {
+	c := NewClient()
+	c.MaxMessageSize = 1500
+	require.Equal(t, uint16(1500), c.maxMessageSize())
+	c.MaxMessageSize = 9000
+	require.Equal(t, uint16(MaxUDPReceivedPacketSize), c.maxMessageSize())
+}
+
+func TestReceiveReplyTimeout(t *testing.T) {
+	server, client := setUpLoopbackConns(t)
+	defer server.Close()
+	defer client.Close()
+
+	request, err := New()
+	require.NoError(t, err)
+	client.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = receiveReply(context.Background(), client, request, MessageTypeNone)
+	require.Error(t, err)
+}
+
+func TestReceiveReplyContextCancel(t *testing.T) {
+	server, client := setUpLoopbackConns(t)
+	defer server.Close()
+	defer client.Close()
+
+	request, err := New()
+	require.NoError(t, err)
+	client.SetReadDeadline(time.Now().Add(10 * time.Second))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err = receiveReply(ctx, client, request, MessageTypeNone)
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestClientReleaseNoServerIdentifier(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.SetYourIPAddr(net.IPv4(127, 0, 0, 1))
+	require.Error(t, NewClient().Release(ack))
+}
+
+func TestClientOpenInvalidInterface(t *testing.T) {
+	c := NewClient()
+	require.Error(t, c.Open("nonexistent-interface"))
+	require.NoError(t, c.Close())
+}
+
+func TestClientExchangeOtherInterface(t *testing.T) {
+	server, client := setUpLoopbackConns(t)
+	defer server.Close()
+
+	c := NewClient()
+	c.ifname, c.recvConn = "eth0", client
+	conversation, err := c.Exchange("eth1", nil)
+	require.Error(t, err)
+	require.Empty(t, conversation)
+	c.recvConn.Close()
+}
+
+func TestSendReceiveUnicast(t *testing.T) {
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer server.Close()
+
+	inform, err := NewInform(net.HardwareAddr{1, 2, 3, 4, 5, 6}, net.IPv4(127, 0, 0, 1))
+	require.NoError(t, err)
+	go func() {
+		buf := make([]byte, MaxUDPReceivedPacketSize)
+		n, peer, err := server.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		request, err := FromBytes(buf[:n])
+		if err != nil {
+			return
+		}
+		reply, err := NewReplyFromRequest(request)
+		if err != nil {
+			return
+		}
+		reply.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+		server.WriteTo(reply.ToBytes(), peer)
+	}()
+
+	laddr := net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	reply, err := sendReceiveUnicast(context.Background(), &laddr, server.LocalAddr().(*net.UDPAddr), inform, time.Second, time.Second, MessageTypeNone)
+	require.NoError(t, err)
+	require.Equal(t, inform.TransactionID(), reply.TransactionID())
+	require.Equal(t, MessageTypeAck, *reply.MessageType())
+}
+
+func TestClientPorts(t *testing.T) {
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer server.Close()
+	// find a free local port
+	free, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	localPort := free.LocalAddr().(*net.UDPAddr).Port
+	free.Close()
+
+	inform, err := NewInform(net.HardwareAddr{1, 2, 3, 4, 5, 6}, net.IPv4(127, 0, 0, 1))
+	require.NoError(t, err)
+	peers := make(chan net.Addr, 1)
+	go func() {
+		buf := make([]byte, MaxUDPReceivedPacketSize)
+		n, peer, err := server.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		peers <- peer
+		request, err := FromBytes(buf[:n])
+		if err != nil {
+			return
+		}
+		reply, err := NewReplyFromRequest(request, WithMessageType(MessageTypeAck))
+		if err != nil {
+			return
+		}
+		server.WriteTo(reply.ToBytes(), peer)
+	}()
+
+	c := NewClient()
+	c.LocalPort = localPort
+	c.ServerPort = server.LocalAddr().(*net.UDPAddr).Port
+	reply, err := c.SendReceiveUnicast(net.IPv4(127, 0, 0, 1), inform)
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeAck, *reply.MessageType())
+	require.Equal(t, localPort, (<-peers).(*net.UDPAddr).Port)
+}
+
+func TestMakeRawBroadcastPacketFrom(t *testing.T) {
+	payload := []byte{1, 2, 3}
+	packet, err := MakeRawBroadcastPacketFrom(payload, net.IPv4(192, 168, 0, 10), 6868, 6767)
+	require.NoError(t, err)
+	require.Len(t, packet, 28+len(payload))
+	require.Equal(t, net.IP{192, 168, 0, 10}, net.IP(packet[12:16]))
+	require.Equal(t, net.IP{255, 255, 255, 255}, net.IP(packet[16:20]))
+	udp := packet[20:28]
+	require.Equal(t, uint16(6868), binary.BigEndian.Uint16(udp[0:2]))
+	require.Equal(t, uint16(6767), binary.BigEndian.Uint16(udp[2:4]))
+	require.Equal(t, payload, packet[28:])
+
+	packet, err = MakeRawBroadcastPacket(payload)
+	require.NoError(t, err)
+	require.Equal(t, net.IP{0, 0, 0, 0}, net.IP(packet[12:16]))
+	require.Equal(t, uint16(ClientPort), binary.BigEndian.Uint16(packet[20:22]))
+	require.Equal(t, uint16(ServerPort), binary.BigEndian.Uint16(packet[22:24]))
+}
+
+// rapidCommitServer answers the Discovers read from server, with an Ack if
+// honor is true and the Discover carries a Rapid Commit option, and with an
+// Offer otherwise, then answers the Requests with an Ack. The replies are sent
+// to addr.
+func rapidCommitServer(server *net.UDPConn, addr net.Addr, honor bool) {
+	buf := make([]byte, MaxUDPReceivedPacketSize)
+	for {
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		m, err := FromBytes(buf[:n])
+		if err != nil || m.MessageType() == nil {
+			return
+		}
+		mods := []Modifier{
+			WithYourIP(net.IPv4(192, 168, 0, 10)),
+			WithServerIP(net.IPv4(192, 168, 0, 1)),
+			WithOption(&OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)}),
+		}
+		switch {
+		case *m.MessageType() == MessageTypeRequest:
+			mods = append(mods, WithMessageType(MessageTypeAck))
+		case honor && m.GetOneOption(OptionRapidCommit) != nil:
+			mods = append(mods, WithMessageType(MessageTypeAck), WithOption(&OptRapidCommit{}))
+		default:
+			mods = append(mods, WithMessageType(MessageTypeOffer))
+		}
+		reply, err := NewReplyFromRequest(m, mods...)
+		if err != nil {
+			return
+		}
+		server.WriteTo(reply.ToBytes(), addr)
+	}
+}
+
+func TestClientExchangeRapidCommit(t *testing.T) {
+	for _, honor := range []bool{true, false} {
+		server, out := setUpLoopbackConns(t)
+		in, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		require.NoError(t, err)
+		go rapidCommitServer(server, in.LocalAddr(), honor)
+
+		c := NewClient()
+		c.RapidCommit = true
+		c.ifname, c.sender, c.recvConn = "eth0", loopbackBroadcaster{out}, in
+		discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+		require.NoError(t, err)
+		conversation, err := c.Exchange("eth0", discover)
+		require.NoError(t, err)
+		require.NotNil(t, conversation[0].GetOneOption(OptionRapidCommit))
+		if honor {
+			require.Len(t, conversation, 2)
+		} else {
+			require.Len(t, conversation, 4)
+			require.Equal(t, MessageTypeOffer, *conversation[1].MessageType())
+			require.Nil(t, conversation[2].GetOneOption(OptionRapidCommit))
+		}
+		ack := conversation[len(conversation)-1]
+		require.Equal(t, MessageTypeAck, *ack.MessageType())
+		require.True(t, ack.YourIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+		c.Close()
+		server.Close()
+	}
+}
+
+func TestBroadcastSource(t *testing.T) {
+	discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	require.True(t, broadcastSource(discover).Equal(net.IPv4zero))
+	discover.SetClientIPAddr(net.IPv4(192, 168, 0, 10))
+	require.True(t, broadcastSource(discover).Equal(net.IPv4(192, 168, 0, 10)))
+}
+
+func TestClientVLANRequiresPacketSocket(t *testing.T) {
+	c := NewClient()
+	c.VLAN = 100
+	require.Error(t, c.Open("lo"))
+	c.PacketSocket, c.VLAN = true, 4096
+	require.Error(t, c.Open("lo"))
+}
+
+func TestClientReboot(t *testing.T) {
+	lease, err := NewLeaseFromACK(leaseTestACK(t))
+	require.NoError(t, err)
+	for _, mt := range []MessageType{MessageTypeAck, MessageTypeNak} {
+		server, out := setUpLoopbackConns(t)
+		in, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		require.NoError(t, err)
+		requests := make(chan *DHCPv4, 1)
+		go func(mt MessageType) {
+			buf := make([]byte, MaxUDPReceivedPacketSize)
+			n, _, err := server.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request, err := FromBytes(buf[:n])
+			if err != nil {
+				return
+			}
+			requests <- request
+			reply, err := NewReplyFromRequest(request, WithMessageType(mt))
+			if err != nil {
+				return
+			}
+			server.WriteTo(reply.ToBytes(), in.LocalAddr())
+		}(mt)
+
+		c := NewClient()
+		c.ifname, c.sender, c.recvConn = "eth0", loopbackBroadcaster{out}, in
+		reply, err := c.Reboot("eth0", lease)
+		require.NoError(t, err)
+		require.Equal(t, mt, *reply.MessageType())
+		request := <-requests
+		require.Equal(t, MessageTypeRequest, *request.MessageType())
+		require.Equal(t, request.TransactionID(), reply.TransactionID())
+		require.Nil(t, request.GetOneOption(OptionServerIdentifier))
+		c.Close()
+		server.Close()
+	}
+}
+
+// offeringServers answers the Discovers read from server with an Offer from
+// each of the given servers, then the Requests with an Ack from the selected
+// server. The replies are sent to addr.
+func offeringServers(server *net.UDPConn, addr net.Addr, serverIDs ...net.IP) {
+	buf := make([]byte, MaxUDPReceivedPacketSize)
+	for {
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		m, err := FromBytes(buf[:n])
+		if err != nil || m.MessageType() == nil {
+			return
+		}
+		ids, mt := serverIDs, MessageTypeOffer
+		if *m.MessageType() == MessageTypeRequest {
+			ids, mt = []net.IP{m.ServerIdentifier()}, MessageTypeAck
+		}
+		for i, id := range ids {
+			reply, err := NewReplyFromRequest(m,
+				WithMessageType(mt),
+				WithYourIP(net.IPv4(192, 168, 0, byte(10+i))),
+				WithOption(&OptServerIdentifier{ServerID: id}),
+			)
+			if err != nil {
+				return
+			}
+			server.WriteTo(reply.ToBytes(), addr)
+		}
+	}
+}
+
+func TestClientExchangeSelectOffer(t *testing.T) {
+	first, second := net.IPv4(192, 168, 0, 1), net.IPv4(192, 168, 0, 2)
+	for _, tc := range []struct {
+		name   string
+		wait   time.Duration
+		pick   func([]*DHCPv4) *DHCPv4
+		offers int
+		want   net.IP
+		err    error
+	}{
+		{"first offer", 0, nil, 1, first, nil},
+		{"preferred server", 200 * time.Millisecond, PreferServer(second), 2, second, nil},
+		{"unknown server", 200 * time.Millisecond, PreferServer(net.IPv4(192, 168, 0, 3)), 2, first, nil},
+		{"no offer selected", 0, func([]*DHCPv4) *DHCPv4 { return nil }, 1, nil, ErrNoOfferSelected},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server, out := setUpLoopbackConns(t)
+			defer server.Close()
+			in, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			require.NoError(t, err)
+			go offeringServers(server, in.LocalAddr(), first, second)
+
+			c := NewClient()
+			defer c.Close()
+			c.OfferWait, c.SelectOffer = tc.wait, tc.pick
+			var offers []*DHCPv4
+			if tc.pick != nil {
+				c.SelectOffer = func(o []*DHCPv4) *DHCPv4 {
+					offers = o
+					return tc.pick(o)
+				}
+			}
+			c.ifname, c.sender, c.recvConn = "eth0", loopbackBroadcaster{out}, in
+			discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+			require.NoError(t, err)
+			conversation, err := c.Exchange("eth0", discover)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				require.Len(t, offers, tc.offers)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, conversation, 4)
+			if tc.pick != nil {
+				require.Len(t, offers, tc.offers)
+			}
+			require.True(t, conversation[1].ServerIdentifier().Equal(tc.want))
+			require.True(t, conversation[2].ServerIdentifier().Equal(tc.want))
+			require.True(t, conversation[3].ServerIdentifier().Equal(tc.want))
+		})
+	}
+}
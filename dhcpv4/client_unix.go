@@ -0,0 +1,144 @@
+// +build linux darwin
+
+package dhcpv4
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fdBroadcaster broadcasts packets through a socket created by
+// MakeBroadcastSocket, from the client port of their broadcastSource to the
+// server port.
+type fdBroadcaster struct {
+	fd                     int
+	clientPort, serverPort int
+}
+
+func (b fdBroadcaster) broadcast(packet *DHCPv4) error {
+	packetBytes, err := MakeRawBroadcastPacketFrom(packet.ToBytes(), broadcastSource(packet), b.clientPort, b.serverPort)
+	if err != nil {
+		return err
+	}
+	return writeBroadcast(b.fd, packetBytes)
+}
+
+func (b fdBroadcaster) Close() error {
+	return unix.Close(b.fd)
+}
+
+// openSockets opens the broadcast and the listening socket for an interface,
+// the latter on the client port.
+func openSockets(ifname string, clientPort, serverPort int) (broadcaster, net.Conn, error) {
+	sfd, err := MakeBroadcastSocket(ifname)
+	if err != nil {
+		return nil, nil, err
+	}
+	rfd, err := makeListeningSocket(ifname, clientPort)
+	if err != nil {
+		unix.Close(sfd)
+		return nil, nil, err
+	}
+	conn, err := fdConn(rfd)
+	if err != nil {
+		unix.Close(sfd)
+		return nil, nil, err
+	}
+	return fdBroadcaster{fd: sfd, clientPort: clientPort, serverPort: serverPort}, conn, nil
+}
+
+// fdConn wraps a socket into a net.Conn, taking ownership of the file
+// descriptor.
+func fdConn(fd int) (net.Conn, error) {
+	f := os.NewFile(uintptr(fd), "")
+	defer f.Close()
+	return net.FileConn(f)
+}
+
+// MakeListeningSocket creates a listening socket on 0.0.0.0 for the DHCP client
+// port and returns it.
+func MakeListeningSocket(ifname string) (int, error) {
+	return makeListeningSocket(ifname, ClientPort)
+}
+
+func makeListeningSocket(ifname string, port int) (int, error) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM, unix.IPPROTO_UDP)
+	if err != nil {
+		return fd, err
+	}
+	err = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
+	if err != nil {
+		return fd, err
+	}
+	var addr [4]byte
+	copy(addr[:], net.IPv4zero.To4())
+	if err = unix.Bind(fd, &unix.SockaddrInet4{Port: port, Addr: addr}); err != nil {
+		return fd, err
+	}
+	err = BindToInterface(fd, ifname)
+	if err != nil {
+		return fd, err
+	}
+	if err = attachReplyFilter(fd, nil); err != nil {
+		return fd, err
+	}
+	return fd, nil
+}
+
+// filterTransaction restricts the filter of conn, opened by openSockets, to
+// the replies of the transaction xid. The connections of other transports
+// are left alone.
+func filterTransaction(conn net.Conn, xid uint32) error {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ferr error
+	if err := raw.Control(func(fd uintptr) {
+		ferr = attachReplyFilter(int(fd), &xid)
+	}); err != nil {
+		return err
+	}
+	return ferr
+}
+
+// ipChecksum computes the checksum of an IPv4 header.
+func ipChecksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(header[i : i+2]))
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
+
+// BroadcastSendReceive broadcasts packet (with some write timeout) and waits for a
+// response up to some read timeout value. If the message type is not
+// MessageTypeNone, it will wait for a specific message type
+func BroadcastSendReceive(sendFd, recvFd int, packet *DHCPv4, readTimeout, writeTimeout time.Duration, messageType MessageType) (*DHCPv4, error) {
+	return BroadcastSendReceiveContext(context.Background(), sendFd, recvFd, packet, readTimeout, writeTimeout, messageType)
+}
+
+// BroadcastSendReceiveContext works like BroadcastSendReceive, but stops
+// waiting for a response as soon as the context is cancelled, in which case
+// the context's error is returned.
+func BroadcastSendReceiveContext(ctx context.Context, sendFd, recvFd int, packet *DHCPv4, readTimeout, writeTimeout time.Duration, messageType MessageType) (*DHCPv4, error) {
+	conn, err := net.FileConn(os.NewFile(uintptr(recvFd), ""))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return broadcastSendReceiveConn(ctx, fdBroadcaster{fd: sendFd, clientPort: ClientPort, serverPort: ServerPort}, conn, packet, readTimeout, messageType)
+}
@@ -0,0 +1,19 @@
+// +build linux darwin
+
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPChecksum(t *testing.T) {
+	// a UDP packet from 192.168.0.1 to 192.168.0.199, with a zero checksum
+	// field
+	header := []byte{
+		0x45, 0x00, 0x00, 0x73, 0x00, 0x00, 0x40, 0x00, 0x40, 0x11,
+		0x00, 0x00, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8, 0x00, 0xc7,
+	}
+	require.Equal(t, uint16(0xb861), ipChecksum(header))
+}
@@ -0,0 +1,75 @@
+// +build windows
+
+package dhcpv4
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// ipUnicastIf is the IP_UNICAST_IF socket option, which selects the interface
+// through which a socket sends its packets.
+const ipUnicastIf = 31
+
+// udpBroadcaster broadcasts packets to the server port through the same UDP
+// socket used to receive the replies, since Windows does not allow sending raw
+// IP packets.
+type udpBroadcaster struct {
+	conn       *net.UDPConn
+	serverPort int
+}
+
+func (u udpBroadcaster) broadcast(packet *DHCPv4) error {
+	_, err := u.conn.WriteTo(packet.ToBytes(), &net.UDPAddr{IP: net.IPv4bcast, Port: u.serverPort})
+	return err
+}
+
+// Close does nothing: the socket is closed through the receiving connection.
+func (u udpBroadcaster) Close() error {
+	return nil
+}
+
+// filterTransaction does nothing: Windows has no filters for the UDP sockets.
+func filterTransaction(conn net.Conn, xid uint32) error {
+	return nil
+}
+
+// openSockets opens a UDP socket bound to 0.0.0.0 on the client port,
+// through which packets are both broadcast and received. The outgoing
+// interface is selected with IP_UNICAST_IF; the replies are received from all
+// the interfaces and told apart by their transaction ID.
+func openSockets(ifname string, clientPort, serverPort int) (broadcaster, net.Conn, error) {
+	iface, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return nil, nil, err
+	}
+	lc := net.ListenConfig{
+		Control: func(network, address string, c syscall.RawConn) error {
+			var serr error
+			err := c.Control(func(fd uintptr) {
+				h := syscall.Handle(fd)
+				if serr = syscall.SetsockoptInt(h, syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1); serr != nil {
+					return
+				}
+				// IP_UNICAST_IF takes the interface index in network
+				// byte order.
+				var index [4]byte
+				binary.BigEndian.PutUint32(index[:], uint32(iface.Index))
+				serr = syscall.SetsockoptInt(h, syscall.IPPROTO_IP, ipUnicastIf, int(binary.LittleEndian.Uint32(index[:])))
+			})
+			if err != nil {
+				return err
+			}
+			return serr
+		},
+	}
+	pc, err := lc.ListenPacket(context.Background(), "udp4", net.JoinHostPort(net.IPv4zero.String(), strconv.Itoa(clientPort)))
+	if err != nil {
+		return nil, nil, err
+	}
+	conn := pc.(*net.UDPConn)
+	return udpBroadcaster{conn: conn, serverPort: serverPort}, conn, nil
+}
@@ -0,0 +1,60 @@
+package dhcpv4
+
+import "time"
+
+// Clock is the source of time of the lease timers of the Manager: renewal,
+// rebinding, expiration, and the delay before retrying a failed exchange.
+// Tests can replace it to make a lease go through its lifecycle without
+// waiting for it. The timeouts of the exchanges themselves are socket
+// deadlines, which always run on the system clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a Timer that fires once d has elapsed.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer, like time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered when the timer
+	// fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+}
+
+// SystemClock is the Clock of the system, backed by the time package. It is
+// the one used wherever no Clock is set.
+type SystemClock struct{}
+
+// Now implements Clock.Now.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer implements Clock.NewTimer.
+func (SystemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// systemTimer adapts a time.Timer to the Timer interface.
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// clockOrDefault returns c, or SystemClock if c is nil.
+func clockOrDefault(c Clock) Clock {
+	if c == nil {
+		return SystemClock{}
+	}
+	return c
+}
@@ -0,0 +1,115 @@
+package dhcpv4
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock whose time only advances when the tests say so.
+type fakeClock struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	c     chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	c := &fakeClock{now: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	c.cond = sync.NewCond(&c.lock)
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance moves the time forward, firing the timers that expire meanwhile.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	var pending []*fakeTimer
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			t.c <- c.now
+		}
+	}
+	c.timers = pending
+}
+
+// WaitForTimer blocks until a timer is armed.
+func (c *fakeClock) WaitForTimer() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.timers) == 0 {
+		c.cond.Wait()
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	for i, other := range t.clock.timers {
+		if other == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestSystemClock(t *testing.T) {
+	clock := clockOrDefault(nil)
+	require.Equal(t, SystemClock{}, clock)
+	before := time.Now()
+	require.False(t, clock.Now().Before(before))
+	timer := clock.NewTimer(time.Millisecond)
+	<-timer.C()
+	require.False(t, timer.Stop())
+	require.True(t, clock.NewTimer(time.Hour).Stop())
+}
+
+func TestFakeClock(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	timer := clock.NewTimer(time.Minute)
+	clock.Advance(30 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	clock.Advance(30 * time.Second)
+	require.Equal(t, start.Add(time.Minute), <-timer.C())
+	require.False(t, timer.Stop())
+}
@@ -0,0 +1,90 @@
+package dhcpv4
+
+import (
+	"bytes"
+	"net"
+)
+
+// Equal returns true if d and other are the same packet, that is if they
+// serialize to the same bytes. The options must be in the same order.
+func (d *DHCPv4) Equal(other *DHCPv4) bool {
+	if d == nil || other == nil {
+		return d == other
+	}
+	return bytes.Equal(d.ToBytes(), other.ToBytes())
+}
+
+// Clone returns a deep copy of d, which can be modified without affecting d,
+// including through the addresses and the options it holds.
+func (d *DHCPv4) Clone() *DHCPv4 {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.clientIPAddr = cloneIP(d.clientIPAddr)
+	c.yourIPAddr = cloneIP(d.yourIPAddr)
+	c.serverIPAddr = cloneIP(d.serverIPAddr)
+	c.gatewayIPAddr = cloneIP(d.gatewayIPAddr)
+	if d.options != nil {
+		c.options = make([]Option, len(d.options))
+		for i, opt := range d.options {
+			c.options[i] = CloneOption(opt)
+		}
+	}
+	return &c
+}
+
+// cloneIP returns a copy of ip, or nil if ip is nil.
+func cloneIP(ip net.IP) net.IP {
+	if ip == nil {
+		return nil
+	}
+	return append(net.IP{}, ip...)
+}
+
+// OptionEqual returns true if a and b are the same option, that is if they
+// have the same code and serialize to the same bytes. It works with any Option
+// implementation, since serialization is required to be lossless.
+func OptionEqual(a, b Option) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Code() == b.Code() && bytes.Equal(a.ToBytes(), b.ToBytes())
+}
+
+// CloneOption returns a deep copy of opt, of the same type, that shares no
+// memory with it. The option is serialized and parsed back, so this works with
+// every option known to ParseOption; the options whose data it cannot parse,
+// e.g. a malformed OptionGeneric standing for a typed option, are copied as
+// OptionGeneric.
+func CloneOption(opt Option) Option {
+	if opt == nil {
+		return nil
+	}
+	if g, ok := opt.(*OptionGeneric); ok {
+		return &OptionGeneric{OptionCode: g.OptionCode, Data: cloneBytes(g.Data)}
+	}
+	data := append([]byte{}, opt.ToBytes()...)
+	raw := rawOption{code: opt.Code(), wire: data}
+	if opt.Code() != OptionPad && opt.Code() != OptionEnd && len(data) >= 2 {
+		// long options do not fit the length byte, so they are decoded
+		// from their data, as if they were made of several instances
+		raw.data = data[2:]
+		if len(raw.data) > maxOptionLength {
+			raw.wire = nil
+		}
+	}
+	options, err := decodeOptions([]rawOption{raw})
+	if err != nil {
+		return &OptionGeneric{OptionCode: opt.Code(), Data: raw.data}
+	}
+	return options[0]
+}
+
+// cloneBytes returns a copy of b, or nil if b is nil.
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
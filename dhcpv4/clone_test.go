@@ -0,0 +1,78 @@
+package dhcpv4
+
+import (
+	"math/rand"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDHCPv4Clone(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	d.AddOption(&OptRouter{Routers: []net.IP{net.IPv4(192, 168, 0, 1)}})
+	d.AddOption(&OptionGeneric{OptionCode: OptionCode(224), Data: []byte{1, 2}})
+
+	c := d.Clone()
+	require.True(t, d.Equal(c))
+	require.Equal(t, d, c)
+
+	// modifying the copy leaves the original alone
+	c.YourIPAddr()[15] = 11
+	c.GetOneOption(OptionRouter).(*OptRouter).Routers[0][15] = 2
+	c.GetOneOption(OptionCode(224)).(*OptionGeneric).Data[0] = 3
+	require.False(t, d.Equal(c))
+	require.Equal(t, net.IPv4(192, 168, 0, 10), d.YourIPAddr())
+	require.Equal(t, net.IPv4(192, 168, 0, 1), d.GetOneOption(OptionRouter).(*OptRouter).Routers[0])
+	require.Equal(t, []byte{1, 2}, d.GetOneOption(OptionCode(224)).(*OptionGeneric).Data)
+
+	var nilPacket *DHCPv4
+	require.Nil(t, nilPacket.Clone())
+	require.True(t, nilPacket.Equal(nil))
+	require.False(t, d.Equal(nil))
+}
+
+func TestCloneOption(t *testing.T) {
+	for name, gen := range optionGenerators {
+		gen := gen
+		t.Run(name, func(t *testing.T) {
+			f := func(seed int64) bool {
+				opt := gen(rand.New(rand.NewSource(seed)))
+				c := CloneOption(opt)
+				return OptionEqual(opt, c) && reflect.DeepEqual(opt, c)
+			}
+			require.NoError(t, quick.Check(f, nil))
+		})
+	}
+}
+
+func TestCloneOptionSpecialCases(t *testing.T) {
+	require.Nil(t, CloneOption(nil))
+	require.Equal(t, &OptionGeneric{OptionCode: OptionEnd}, CloneOption(&OptionGeneric{OptionCode: OptionEnd}))
+
+	// malformed data for a typed option stays generic
+	bad := &OptionGeneric{OptionCode: OptionSubnetMask, Data: []byte{255, 255}}
+	require.Equal(t, bad, CloneOption(bad))
+
+	// long options are decoded from their data
+	long := &OptDomainSearch{}
+	for i := 0; i < 30; i++ {
+		long.DomainSearch = append(long.DomainSearch, strings.Repeat("a", i+1)+".example.com")
+	}
+	require.True(t, long.Length() > maxOptionLength)
+	require.Equal(t, long, CloneOption(long))
+}
+
+func TestOptionEqual(t *testing.T) {
+	a := &OptSubnetMask{SubnetMask: net.IPv4Mask(255, 255, 255, 0)}
+	b := &OptionGeneric{OptionCode: OptionSubnetMask, Data: []byte{255, 255, 255, 0}}
+	require.True(t, OptionEqual(a, b))
+	require.False(t, OptionEqual(a, &OptSubnetMask{SubnetMask: net.IPv4Mask(255, 255, 0, 0)}))
+	require.False(t, OptionEqual(a, nil))
+	require.True(t, OptionEqual(nil, nil))
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/insomniacslk/dhcp/iana"
 )
@@ -15,9 +17,18 @@ import (
 // HeaderSize is the DHCPv4 header size in bytes.
 const HeaderSize = 236
 
-// MaxMessageSize is the maximum size in bytes that a DHCPv4 packet can hold.
+// MaxMessageSize is the size in bytes of the largest DHCPv4 packet that every
+// client must accept, RFC 2131 section 2. Larger packets can be sent to the
+// clients that advertise a larger size with the Maximum DHCP Message Size
+// option, see DHCPv4.MaximumMessageSize, and are received up to
+// MaxUDPReceivedPacketSize bytes.
 const MaxMessageSize = 576
 
+// MinMessageSize is the size in bytes of a BOOTP packet, to which some relay
+// agents and clients expect every packet to be padded, see RFC 1542 section
+// 2.1.
+const MinMessageSize = HeaderSize + BOOTPVendorSize
+
 // DHCPv4 represents a DHCPv4 packet header and options. See the New* functions
 // to build DHCPv4 packets.
 type DHCPv4 struct {
@@ -36,6 +47,9 @@ type DHCPv4 struct {
 	serverHostName [64]byte
 	bootFileName   [128]byte
 	options        []Option
+	// bootp is set for plain BOOTP packets, whose vendor area is padded to
+	// BOOTPVendorSize bytes when serialized.
+	bootp bool
 }
 
 // Modifier defines the signature for functions that can modify DHCPv4
@@ -152,8 +166,9 @@ func NewDiscovery(hwaddr net.HardwareAddr) (*DHCPv4, error) {
 	// get hw addr
 	d.SetOpcode(OpcodeBootRequest)
 	d.SetHwType(iana.HwTypeEthernet)
-	d.SetHwAddrLen(uint8(len(hwaddr)))
-	d.SetClientHwAddr(hwaddr)
+	if err := d.SetClientHwAddr(hwaddr); err != nil {
+		return nil, err
+	}
 	d.SetBroadcast()
 	d.AddOption(&OptMessageType{MessageType: MessageTypeDiscover})
 	d.AddOption(&OptParameterRequestList{
@@ -206,8 +221,9 @@ func NewInform(hwaddr net.HardwareAddr, localIP net.IP) (*DHCPv4, error) {
 
 	d.SetOpcode(OpcodeBootRequest)
 	d.SetHwType(iana.HwTypeEthernet)
-	d.SetHwAddrLen(uint8(len(hwaddr)))
-	d.SetClientHwAddr(hwaddr)
+	if err := d.SetClientHwAddr(hwaddr); err != nil {
+		return nil, err
+	}
 	d.SetClientIPAddr(localIP)
 	d.AddOption(&OptMessageType{MessageType: MessageTypeInform})
 	return d, nil
@@ -222,8 +238,8 @@ func NewRequestFromOffer(offer *DHCPv4, modifiers ...Modifier) (*DHCPv4, error)
 	d.SetOpcode(OpcodeBootRequest)
 	d.SetHwType(offer.HwType())
 	d.SetHwAddrLen(offer.HwAddrLen())
-	hwaddr := offer.ClientHwAddr()
-	d.SetClientHwAddr(hwaddr[:])
+	hwaddr := offer.ClientHwAddrRaw()
+	d.SetClientHwAddrRaw(hwaddr[:])
 	d.SetTransactionID(offer.TransactionID())
 	if offer.IsBroadcast() {
 		d.SetBroadcast()
@@ -231,14 +247,9 @@ func NewRequestFromOffer(offer *DHCPv4, modifiers ...Modifier) (*DHCPv4, error)
 		d.SetUnicast()
 	}
 	// find server IP address
-	var serverIP []byte
-	for _, opt := range offer.options {
-		if opt.Code() == OptionServerIdentifier {
-			serverIP = opt.(*OptServerIdentifier).ServerID
-		}
-	}
+	serverIP := offer.ServerIdentifier()
 	if serverIP == nil {
-		return nil, errors.New("Missing Server IP Address in DHCP Offer")
+		return nil, ErrNoServerIdentifier
 	}
 	d.SetServerIPAddr(serverIP)
 	d.AddOption(&OptMessageType{MessageType: MessageTypeRequest})
@@ -250,6 +261,113 @@ func NewRequestFromOffer(offer *DHCPv4, modifiers ...Modifier) (*DHCPv4, error)
 	return d, nil
 }
 
+// NewRenewFromACK builds a DHCPv4 request suitable for extending the lease
+// described by an acknowledge, as sent in the RENEWING and REBINDING states.
+// As per RFC 2131 section 4.3.2 the leased address goes in ciaddr, and neither
+// the Server Identifier nor the Requested IP Address options are present.
+func NewRenewFromACK(ack *DHCPv4, modifiers ...Modifier) (*DHCPv4, error) {
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	d.SetOpcode(OpcodeBootRequest)
+	d.SetHwType(ack.HwType())
+	d.SetHwAddrLen(ack.HwAddrLen())
+	hwaddr := ack.ClientHwAddrRaw()
+	d.SetClientHwAddrRaw(hwaddr[:])
+	d.SetUnicast()
+	d.SetClientIPAddr(ack.YourIPAddr())
+	d.AddOption(&OptMessageType{MessageType: MessageTypeRequest})
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
+// NewReleaseFromACK builds a DHCPv4 release for the lease described by an
+// acknowledge. As per RFC 2131 section 4.4.6 the released address goes in
+// ciaddr, and the Server Identifier option tells which server granted it.
+func NewReleaseFromACK(ack *DHCPv4, modifiers ...Modifier) (*DHCPv4, error) {
+	serverID := ack.ServerIdentifier()
+	if serverID == nil {
+		return nil, ErrNoServerIdentifier
+	}
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	d.SetOpcode(OpcodeBootRequest)
+	d.SetHwType(ack.HwType())
+	d.SetHwAddrLen(ack.HwAddrLen())
+	hwaddr := ack.ClientHwAddrRaw()
+	d.SetClientHwAddrRaw(hwaddr[:])
+	d.SetUnicast()
+	d.SetClientIPAddr(ack.YourIPAddr())
+	d.AddOption(&OptMessageType{MessageType: MessageTypeRelease})
+	d.AddOption(&OptServerIdentifier{ServerID: serverID})
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
+// NewDecline builds a DHCPv4 decline for the address offered in an
+// acknowledge, to be broadcast when the client finds out that the address is
+// already in use. As per RFC 2131 section 4.4.4 ciaddr is left empty, and the
+// declined address goes in the Requested IP Address option.
+func NewDecline(ack *DHCPv4, modifiers ...Modifier) (*DHCPv4, error) {
+	serverID := ack.ServerIdentifier()
+	if serverID == nil {
+		return nil, ErrNoServerIdentifier
+	}
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	d.SetOpcode(OpcodeBootRequest)
+	d.SetHwType(ack.HwType())
+	d.SetHwAddrLen(ack.HwAddrLen())
+	hwaddr := ack.ClientHwAddrRaw()
+	d.SetClientHwAddrRaw(hwaddr[:])
+	d.SetBroadcast()
+	d.AddOption(&OptMessageType{MessageType: MessageTypeDecline})
+	d.AddOption(&OptRequestedIPAddress{RequestedAddr: ack.YourIPAddr()})
+	d.AddOption(&OptServerIdentifier{ServerID: serverID})
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
+// NewForceRenew builds a DHCPv4 FORCERENEW for the lease described by an
+// acknowledge, to be unicast by the server to the leased address so that the
+// client renews its lease right away. See RFC 3203. The ACK must hold the
+// Server Identifier option, which is copied. As RFC 3203 requires FORCERENEW
+// messages to be authenticated, they are usually signed afterwards, e.g. with
+// SignDelayedAuthentication.
+func NewForceRenew(ack *DHCPv4, modifiers ...Modifier) (*DHCPv4, error) {
+	serverID := ack.ServerIdentifier()
+	if serverID == nil {
+		return nil, ErrNoServerIdentifier
+	}
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	d.SetOpcode(OpcodeBootReply)
+	d.SetHwType(ack.HwType())
+	d.SetHwAddrLen(ack.HwAddrLen())
+	hwaddr := ack.ClientHwAddrRaw()
+	d.SetClientHwAddrRaw(hwaddr[:])
+	d.SetClientIPAddr(ack.YourIPAddr())
+	d.AddOption(&OptMessageType{MessageType: MessageTypeForceRenew})
+	d.AddOption(&OptServerIdentifier{ServerID: serverID})
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
 // NewReplyFromRequest builds a DHCPv4 reply from a request.
 func NewReplyFromRequest(request *DHCPv4, modifiers ...Modifier) (*DHCPv4, error) {
 	reply, err := New()
@@ -259,8 +377,8 @@ func NewReplyFromRequest(request *DHCPv4, modifiers ...Modifier) (*DHCPv4, error
 	reply.SetOpcode(OpcodeBootReply)
 	reply.SetHwType(request.HwType())
 	reply.SetHwAddrLen(request.HwAddrLen())
-	hwaddr := request.ClientHwAddr()
-	reply.SetClientHwAddr(hwaddr[:])
+	hwaddr := request.ClientHwAddrRaw()
+	reply.SetClientHwAddrRaw(hwaddr[:])
 	reply.SetTransactionID(request.TransactionID())
 	reply.SetFlags(request.Flags())
 	reply.SetGatewayIPAddr(request.GatewayIPAddr())
@@ -271,11 +389,53 @@ func NewReplyFromRequest(request *DHCPv4, modifiers ...Modifier) (*DHCPv4, error
 }
 
 // FromBytes encodes the DHCPv4 packet into a sequence of bytes, and returns an
-// error if the packet is not valid.
+// error if the packet is not valid. If the option overload option is present,
+// the options held by the sname and file fields are appended to the others.
+// Options split into several instances are concatenated, see RFC 3396.
 func FromBytes(data []byte) (*DHCPv4, error) {
+	return FromBytesWithConfig(data, ParseConfig{})
+}
+
+// ParseConfig tells FromBytesWithConfig how to handle malformed packets. The
+// zero value is the behaviour of FromBytes, which rejects the packets it
+// cannot decode, but tolerates what it can ignore.
+type ParseConfig struct {
+	// StrictLengths rejects the options whose length the OptionInfo of
+	// their code does not allow, even if they can be parsed, e.g. a subnet
+	// mask followed by extra bytes.
+	StrictLengths bool
+	// RejectTrailingData rejects the packets without End option, or with
+	// anything but padding after it.
+	RejectTrailingData bool
+	// AllowMissingMagicCookie accepts the packets whose vendor area does
+	// not start with the magic cookie, as plain BOOTP packets whose vendor
+	// area is ignored.
+	AllowMissingMagicCookie bool
+	// KeepMalformedOptions returns the options that cannot be parsed as
+	// OptionGeneric, and stops at a truncated option, instead of rejecting
+	// the packet. Options in the sname and file fields are ignored if these
+	// cannot be split into options.
+	KeepMalformedOptions bool
+}
+
+var (
+	// StrictParseConfig is the ParseConfig of servers exposed to hostile
+	// input, which only accept well-formed packets.
+	StrictParseConfig = ParseConfig{StrictLengths: true, RejectTrailingData: true}
+	// LenientParseConfig is the ParseConfig of sniffers and debugging tools,
+	// which decode as much of a broken packet as they can to display it.
+	LenientParseConfig = ParseConfig{AllowMissingMagicCookie: true, KeepMalformedOptions: true}
+)
+
+// FromBytesWithConfig works like FromBytes, handling the malformed packets
+// as config tells.
+func FromBytesWithConfig(data []byte, config ParseConfig) (*DHCPv4, error) {
 	if len(data) < HeaderSize {
-		return nil, fmt.Errorf("Invalid DHCPv4 header: shorter than %v bytes", HeaderSize)
+		return nil, ErrShortPacket
 	}
+	// the addresses and options point into data, which the caller may
+	// reuse, e.g. as a receive buffer
+	data = append([]byte(nil), data...)
 	d := DHCPv4{
 		opcode:        OpcodeType(data[0]),
 		hwType:        iana.HwTypeType(data[1]),
@@ -292,14 +452,83 @@ func FromBytes(data []byte) (*DHCPv4, error) {
 	copy(d.clientHwAddr[:], data[28:44])
 	copy(d.serverHostName[:], data[44:108])
 	copy(d.bootFileName[:], data[108:236])
-	options, err := OptionsFromBytes(data[236:])
-	if err != nil {
+	if isZero(data[236:]) {
+		// A BOOTP packet with an empty vendor area, as RFC 951 allows.
+		d.bootp = true
+		return &d, nil
+	}
+	if err := checkMagicCookie(data[236:]); err != nil {
+		if !config.AllowMissingMagicCookie {
+			return nil, err
+		}
+		d.bootp = true
+		return &d, nil
+	}
+	field := data[236+len(MagicCookie):]
+	raw, err := splitOptions(field)
+	if err != nil && !config.KeepMalformedOptions {
 		return nil, err
 	}
-	d.options = options
+	if err == nil && config.RejectTrailingData {
+		if err := checkTrailingData(raw, field); err != nil {
+			return nil, err
+		}
+	}
+	if overload := rawOverload(raw); overload != 0 {
+		withOverload, err := d.overloadedOptions(raw, overload)
+		if err != nil && !config.KeepMalformedOptions {
+			return nil, err
+		}
+		if err == nil {
+			raw = withOverload
+		}
+	}
+	raw = concatOptions(raw)
+	if config.StrictLengths {
+		for _, r := range raw {
+			if r.code == OptionPad || r.code == OptionEnd {
+				continue
+			}
+			if info, ok := LookupOptionInfo(r.code); ok {
+				if err := info.ValidateLength(len(r.data)); err != nil {
+					return nil, &OptionParseError{Code: r.code, Err: err}
+				}
+			}
+		}
+	}
+	if !config.KeepMalformedOptions {
+		if d.options, err = decodeOptions(raw); err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+	d.options = make([]Option, 0, len(raw))
+	for _, r := range raw {
+		opt, err := decodeOption(r)
+		if err != nil {
+			opt = &OptionGeneric{OptionCode: r.code, Data: r.data}
+		}
+		d.options = append(d.options, opt)
+	}
 	return &d, nil
 }
 
+// checkTrailingData returns an error if the options split from field do not
+// end with an End option followed by padding only.
+func checkTrailingData(raw []rawOption, field []byte) error {
+	if len(raw) == 0 || raw[len(raw)-1].code != OptionEnd {
+		return ErrNoEndOption
+	}
+	n := 0
+	for _, r := range raw {
+		n += len(r.wire)
+	}
+	if !isZero(field[n:]) {
+		return ErrTrailingData
+	}
+	return nil
+}
+
 // Opcode returns the OpcodeType for the packet,
 func (d *DHCPv4) Opcode() OpcodeType {
 	return d.opcode
@@ -310,12 +539,9 @@ func (d *DHCPv4) OpcodeToString() string {
 	return d.opcode.String()
 }
 
-// SetOpcode sets a new opcode for the packet. It prints a warning if the opcode
-// is unknown, but does not generate an error.
+// SetOpcode sets a new opcode for the packet. Unknown opcodes are accepted, and
+// reported by Validate.
 func (d *DHCPv4) SetOpcode(opcode OpcodeType) {
-	if _, ok := OpcodeToString[opcode]; !ok {
-		log.Printf("Warning: unknown DHCPv4 opcode: %v", opcode)
-	}
 	d.opcode = opcode
 }
 
@@ -336,9 +562,6 @@ func (d *DHCPv4) HwTypeToString() string {
 
 // SetHwType returns the hardware type as defined by IANA.
 func (d *DHCPv4) SetHwType(hwType iana.HwTypeType) {
-	if _, ok := iana.HwTypeToString[hwType]; !ok {
-		log.Printf("Warning: Invalid DHCPv4 hwtype: %v", hwType)
-	}
 	d.hwType = hwType
 }
 
@@ -352,7 +575,6 @@ func (d *DHCPv4) HwAddrLen() uint8 {
 // size 16 that the standard allows.
 func (d *DHCPv4) SetHwAddrLen(hwAddrLen uint8) {
 	if hwAddrLen > 16 {
-		log.Printf("Warning: invalid HwAddrLen: %v > 16, using 16 instead", hwAddrLen)
 		hwAddrLen = 16
 	}
 	d.hwAddrLen = hwAddrLen
@@ -472,24 +694,49 @@ func (d *DHCPv4) SetGatewayIPAddr(gatewayIPAddr net.IP) {
 	d.gatewayIPAddr = gatewayIPAddr
 }
 
-// ClientHwAddr returns the client hardware (MAC) address.
-func (d *DHCPv4) ClientHwAddr() [16]byte {
+// ClientHwAddr returns the client hardware (MAC) address, i.e. the first
+// HwAddrLen bytes of the chaddr field.
+func (d *DHCPv4) ClientHwAddr() net.HardwareAddr {
+	n := int(d.hwAddrLen)
+	if n > len(d.clientHwAddr) {
+		n = len(d.clientHwAddr)
+	}
+	return append(net.HardwareAddr(nil), d.clientHwAddr[:n]...)
+}
+
+// ClientHwAddrRaw returns the chaddr field, whose first HwAddrLen bytes are the
+// client hardware address.
+func (d *DHCPv4) ClientHwAddrRaw() [16]byte {
 	return d.clientHwAddr
 }
 
 // ClientHwAddrToString converts the hardware address field to a string.
+//
+// Deprecated: use ClientHwAddr().String() instead.
 func (d *DHCPv4) ClientHwAddrToString() string {
 	var ret []string
-	for _, b := range d.clientHwAddr[:d.hwAddrLen] {
+	for _, b := range d.ClientHwAddr() {
 		ret = append(ret, fmt.Sprintf("%02x", b))
 	}
 	return strings.Join(ret, ":")
 }
 
-// SetClientHwAddr sets the client hardware address.
-func (d *DHCPv4) SetClientHwAddr(clientHwAddr []byte) {
+// SetClientHwAddr sets the client hardware address, and the hardware address
+// length to its length. It returns an error, and leaves both unchanged, if the
+// address does not fit in the 16 bytes of the chaddr field.
+func (d *DHCPv4) SetClientHwAddr(hwaddr net.HardwareAddr) error {
+	if len(hwaddr) > len(d.clientHwAddr) {
+		return fmt.Errorf("hardware address %v is longer than %d bytes", hwaddr, len(d.clientHwAddr))
+	}
+	d.SetClientHwAddrRaw(hwaddr)
+	d.hwAddrLen = uint8(len(hwaddr))
+	return nil
+}
+
+// SetClientHwAddrRaw sets the chaddr field, truncating it to 16 bytes, and
+// leaves the hardware address length unchanged.
+func (d *DHCPv4) SetClientHwAddrRaw(clientHwAddr []byte) {
 	if len(clientHwAddr) > 16 {
-		log.Printf("Warning: too long HW Address (%d bytes), truncating to 16 bytes", len(clientHwAddr))
 		clientHwAddr = clientHwAddr[:16]
 	}
 	copy(d.clientHwAddr[:len(clientHwAddr)], clientHwAddr)
@@ -499,60 +746,94 @@ func (d *DHCPv4) SetClientHwAddr(clientHwAddr []byte) {
 	}
 }
 
-// ServerHostName returns the server host name as a sequence of bytes.
-func (d *DHCPv4) ServerHostName() [64]byte {
+// ServerHostName returns the server host name, after trimming the null bytes
+// at the end.
+func (d *DHCPv4) ServerHostName() string {
+	return strings.TrimRight(string(d.serverHostName[:]), "\x00")
+}
+
+// ServerHostNameRaw returns the server host name as a sequence of bytes.
+func (d *DHCPv4) ServerHostNameRaw() [64]byte {
 	return d.serverHostName
 }
 
 // ServerHostNameToString returns the server host name as a string, after
 // trimming the null bytes at the end.
+//
+// Deprecated: use ServerHostName instead.
 func (d *DHCPv4) ServerHostNameToString() string {
-	return strings.TrimRight(string(d.serverHostName[:]), "\x00")
+	return d.ServerHostName()
 }
 
-// SetServerHostName replaces the server host name, from a sequence of bytes,
-// truncating it to the maximum length of 64.
-func (d *DHCPv4) SetServerHostName(serverHostName []byte) {
-	if len(serverHostName) > 64 {
-		serverHostName = serverHostName[:64]
-	} else if len(serverHostName) < 64 {
-		for i := len(serverHostName) - 1; i < 64; i++ {
-			serverHostName = append(serverHostName, 0)
-		}
+// SetServerHostName sets the server host name. It returns an error, and leaves
+// the name unchanged, if the name holds null bytes or, with its terminating
+// null byte, does not fit in the 64 bytes of the sname field.
+func (d *DHCPv4) SetServerHostName(name string) error {
+	if err := checkHeaderString("server host name", name, len(d.serverHostName)); err != nil {
+		return err
 	}
-	// need an array, not a slice, so let's copy it
+	d.SetServerHostNameRaw([]byte(name))
+	return nil
+}
+
+// SetServerHostNameRaw replaces the server host name, from a sequence of
+// bytes, truncating it to the maximum length of 64.
+func (d *DHCPv4) SetServerHostNameRaw(serverHostName []byte) {
 	var newServerHostName [64]byte
 	copy(newServerHostName[:], serverHostName)
 	d.serverHostName = newServerHostName
 }
 
-// BootFileName returns the boot file name as a sequence of bytes.
-func (d *DHCPv4) BootFileName() [128]byte {
+// BootFileName returns the boot file name, after trimming the null bytes at the
+// end.
+func (d *DHCPv4) BootFileName() string {
+	return strings.TrimRight(string(d.bootFileName[:]), "\x00")
+}
+
+// BootFileNameRaw returns the boot file name as a sequence of bytes.
+func (d *DHCPv4) BootFileNameRaw() [128]byte {
 	return d.bootFileName
 }
 
 // BootFileNameToString returns the boot file name as a string, after trimming
 // the null bytes at the end.
+//
+// Deprecated: use BootFileName instead.
 func (d *DHCPv4) BootFileNameToString() string {
-	return strings.TrimRight(string(d.bootFileName[:]), "\x00")
+	return d.BootFileName()
 }
 
-// SetBootFileName replaces the boot file name, from a sequence of bytes,
-// truncating it to the maximum length oh 128.
-func (d *DHCPv4) SetBootFileName(bootFileName []byte) {
-	if len(bootFileName) > 128 {
-		bootFileName = bootFileName[:128]
-	} else if len(bootFileName) < 128 {
-		for i := len(bootFileName) - 1; i < 128; i++ {
-			bootFileName = append(bootFileName, 0)
-		}
+// SetBootFileName sets the boot file name. It returns an error, and leaves the
+// name unchanged, if the name holds null bytes or, with its terminating null
+// byte, does not fit in the 128 bytes of the file field.
+func (d *DHCPv4) SetBootFileName(name string) error {
+	if err := checkHeaderString("boot file name", name, len(d.bootFileName)); err != nil {
+		return err
 	}
-	// need an array, not a slice, so let's copy it
+	d.SetBootFileNameRaw([]byte(name))
+	return nil
+}
+
+// SetBootFileNameRaw replaces the boot file name, from a sequence of bytes,
+// truncating it to the maximum length of 128.
+func (d *DHCPv4) SetBootFileNameRaw(bootFileName []byte) {
 	var newBootFileName [128]byte
 	copy(newBootFileName[:], bootFileName)
 	d.bootFileName = newBootFileName
 }
 
+// checkHeaderString checks that s can be stored as the null-terminated string
+// of a header field of size bytes.
+func checkHeaderString(field, s string, size int) error {
+	if strings.IndexByte(s, 0) >= 0 {
+		return fmt.Errorf("%s %q holds a null byte", field, s)
+	}
+	if len(s) >= size {
+		return fmt.Errorf("%s %q is longer than %d bytes", field, s, size-1)
+	}
+	return nil
+}
+
 // Options returns the DHCPv4 options defined for the packet.
 func (d *DHCPv4) Options() []Option {
 	return d.options
@@ -583,6 +864,33 @@ func (d *DHCPv4) GetOneOption(code OptionCode) Option {
 	return nil
 }
 
+// GetMergedOption returns the option with the given code as a single value,
+// the data of its instances being concatenated in order as described in RFC
+// 3396, or nil if there is none. This is the option a receiver of the message
+// gets, since FromBytes concatenates the instances found on the wire, while
+// GetOption returns the instances as they were added. An error is returned if
+// the concatenated data cannot be parsed.
+func (d *DHCPv4) GetMergedOption(code OptionCode) (Option, error) {
+	opts := d.GetOption(code)
+	switch {
+	case len(opts) == 0:
+		return nil, nil
+	case len(opts) == 1 || code == OptionPad || code == OptionEnd:
+		return opts[0], nil
+	}
+	var data []byte
+	for _, opt := range opts {
+		// the length byte is wrong for options longer than maxOptionLength,
+		// but the data is complete
+		data = append(data, opt.ToBytes()[2:]...)
+	}
+	merged, err := decodeOptions([]rawOption{{code: code, data: data}})
+	if err != nil {
+		return nil, err
+	}
+	return merged[0], nil
+}
+
 // StrippedOptions works like Options, but it does not return anything after the
 // End option.
 func (d *DHCPv4) StrippedOptions() []Option {
@@ -616,14 +924,223 @@ func (d *DHCPv4) AddOption(option Option) {
 	}
 }
 
+// UpdateOption replaces the first option with the same code as option, or adds
+// option with AddOption if there is none.
+func (d *DHCPv4) UpdateOption(option Option) {
+	for idx, opt := range d.options {
+		if opt.Code() == option.Code() {
+			d.options[idx] = option
+			return
+		}
+	}
+	d.AddOption(option)
+}
+
 // MessageType returns the message type, trying to extract it from the
 // OptMessageType option. It returns nil if the message type cannot be extracted
 func (d *DHCPv4) MessageType() *MessageType {
-	opt := d.GetOneOption(OptionDHCPMessageType)
-	if opt == nil {
+	opt, ok := d.GetOneOption(OptionDHCPMessageType).(*OptMessageType)
+	if !ok {
+		return nil
+	}
+	return &opt.MessageType
+}
+
+// SubnetMask returns the subnet mask from the OptSubnetMask option, or nil if
+// it is not present.
+func (d *DHCPv4) SubnetMask() net.IPMask {
+	opt, ok := d.GetOneOption(OptionSubnetMask).(*OptSubnetMask)
+	if !ok {
 		return nil
 	}
-	return &(opt.(*OptMessageType).MessageType)
+	return opt.SubnetMask
+}
+
+// Router returns the routers from the OptRouter option, or nil if it is not
+// present.
+func (d *DHCPv4) Router() []net.IP {
+	opt, ok := d.GetOneOption(OptionRouter).(*OptRouter)
+	if !ok {
+		return nil
+	}
+	return opt.Routers
+}
+
+// DNS returns the name servers from the OptDomainNameServer option, or nil if
+// it is not present.
+func (d *DHCPv4) DNS() []net.IP {
+	opt, ok := d.GetOneOption(OptionDomainNameServer).(*OptDomainNameServer)
+	if !ok {
+		return nil
+	}
+	return opt.NameServers
+}
+
+// NTPServers returns the servers from the OptNTPServers option, or nil if it
+// is not present.
+func (d *DHCPv4) NTPServers() []net.IP {
+	opt, ok := d.GetOneOption(OptionNTPServers).(*OptNTPServers)
+	if !ok {
+		return nil
+	}
+	return opt.NTPServers
+}
+
+// TimeOffset returns the offset from the OptTimeOffset option, or nil if it is
+// not present.
+func (d *DHCPv4) TimeOffset() *TimeOffset {
+	opt, ok := d.GetOneOption(OptionTimeOffset).(*OptTimeOffset)
+	if !ok {
+		return nil
+	}
+	return &opt.Offset
+}
+
+// NetBIOSNameServers returns the servers from the OptNetBIOSNameServer
+// option, or nil if it is not present.
+func (d *DHCPv4) NetBIOSNameServers() []net.IP {
+	opt, ok := d.GetOneOption(OptionNetBIOSOverTCPIPNameServer).(*OptNetBIOSNameServer)
+	if !ok {
+		return nil
+	}
+	return opt.NameServers
+}
+
+// NetBIOSNodeType returns the node type from the OptNetBIOSNodeType option,
+// or 0 if it is not present.
+func (d *DHCPv4) NetBIOSNodeType() NetBIOSNodeType {
+	opt, ok := d.GetOneOption(OptionNetBIOSOverTCPIPNodeType).(*OptNetBIOSNodeType)
+	if !ok {
+		return 0
+	}
+	return opt.NodeType
+}
+
+// NetBIOSScope returns the scope from the OptNetBIOSScope option, or an empty
+// string if it is not present.
+func (d *DHCPv4) NetBIOSScope() string {
+	opt, ok := d.GetOneOption(OptionNetBIOSOverTCPIPScope).(*OptNetBIOSScope)
+	if !ok {
+		return ""
+	}
+	return opt.Scope
+}
+
+// HostName returns the host name from the OptHostName option, or an empty
+// string if it is not present.
+func (d *DHCPv4) HostName() string {
+	opt, ok := d.GetOneOption(OptionHostName).(*OptHostName)
+	if !ok {
+		return ""
+	}
+	return opt.HostName
+}
+
+// DomainName returns the domain name from the OptDomainName option, or an
+// empty string if it is not present.
+func (d *DHCPv4) DomainName() string {
+	opt, ok := d.GetOneOption(OptionDomainName).(*OptDomainName)
+	if !ok {
+		return ""
+	}
+	return opt.DomainName
+}
+
+// DomainSearch returns the domains from the OptDomainSearch option, or nil if
+// it is not present.
+func (d *DHCPv4) DomainSearch() []string {
+	opt, ok := d.GetOneOption(OptionDNSDomainSearchList).(*OptDomainSearch)
+	if !ok {
+		return nil
+	}
+	return opt.DomainSearch
+}
+
+// BroadcastAddress returns the broadcast address from the OptBroadcastAddress
+// option, or nil if it is not present.
+func (d *DHCPv4) BroadcastAddress() net.IP {
+	opt, ok := d.GetOneOption(OptionBroadcastAddress).(*OptBroadcastAddress)
+	if !ok {
+		return nil
+	}
+	return opt.BroadcastAddress
+}
+
+// EffectiveBroadcastAddress returns the broadcast address the client should
+// configure with the address it is offered: the one of the OptBroadcastAddress
+// option if it belongs to the subnet given by the subnet mask option, or else
+// the all-ones address of that subnet. It returns nil if the packet has no
+// subnet mask option or no "your IP".
+func (d *DHCPv4) EffectiveBroadcastAddress() net.IP {
+	mask, ip := d.SubnetMask(), d.YourIPAddr()
+	if mask == nil || ip == nil || ip.IsUnspecified() {
+		return nil
+	}
+	if opt, ok := d.GetOneOption(OptionBroadcastAddress).(*OptBroadcastAddress); ok && opt.InSubnet(ip, mask) {
+		return opt.BroadcastAddress
+	}
+	return SubnetBroadcastAddress(ip, mask)
+}
+
+// SubnetSelection returns the address from the OptSubnetSelection option, or
+// nil if it is not present.
+func (d *DHCPv4) SubnetSelection() net.IP {
+	opt, ok := d.GetOneOption(OptionSubnetSelection).(*OptSubnetSelection)
+	if !ok {
+		return nil
+	}
+	return opt.Subnet
+}
+
+// SelectedSubnet returns the address whose subnet the server should allocate
+// an address on: the one of the subnet selection option, or else of the link
+// selection sub-option of the relay agent information option, to which RFC
+// 3527 gives a lower precedence, or else the giaddr. It returns nil if there is
+// none, and the subnet is the one of the interface the packet was received on.
+func (d *DHCPv4) SelectedSubnet() net.IP {
+	if ip := d.SubnetSelection(); ip != nil {
+		return ip
+	}
+	if opt, ok := d.GetOneOption(OptionRelayAgentInformation).(*OptRelayAgentInformation); ok {
+		if ip := opt.LinkSelection(); ip != nil {
+			return ip
+		}
+	}
+	if giaddr := d.GatewayIPAddr(); giaddr != nil && !giaddr.IsUnspecified() {
+		return giaddr
+	}
+	return nil
+}
+
+// RequestedIPAddress returns the address from the OptRequestedIPAddress
+// option, or nil if it is not present.
+func (d *DHCPv4) RequestedIPAddress() net.IP {
+	opt, ok := d.GetOneOption(OptionRequestedIPAddress).(*OptRequestedIPAddress)
+	if !ok {
+		return nil
+	}
+	return opt.RequestedAddr
+}
+
+// ServerIdentifier returns the server identifier from the
+// OptServerIdentifier option, or nil if it is not present.
+func (d *DHCPv4) ServerIdentifier() net.IP {
+	opt, ok := d.GetOneOption(OptionServerIdentifier).(*OptServerIdentifier)
+	if !ok {
+		return nil
+	}
+	return opt.ServerID
+}
+
+// IPAddressLeaseTime returns the lease time from the OptIPAddressLeaseTime
+// option, or def if it is not present. An infinite lease time is returned as
+// math.MaxUint32 seconds.
+func (d *DHCPv4) IPAddressLeaseTime(def time.Duration) time.Duration {
+	opt, ok := d.GetOneOption(OptionIPAddressLeaseTime).(*OptIPAddressLeaseTime)
+	if !ok {
+		return def
+	}
+	return time.Duration(opt.LeaseTime) * time.Second
 }
 
 func (d *DHCPv4) String() string {
@@ -681,33 +1198,25 @@ func (d *DHCPv4) Summary() string {
 }
 
 // ValidateOptions runs sanity checks on the DHCPv4 packet and prints a number
-// of warnings if something is incorrect.
+// of warnings to DefaultLogger if something is incorrect.
+//
+// Deprecated: use Validate, which returns the problems instead of printing
+// them.
 func (d *DHCPv4) ValidateOptions() {
-	// TODO find duplicate options
-	foundOptionEnd := false
-	for _, opt := range d.options {
-		if foundOptionEnd {
-			if opt.Code() == OptionEnd {
-				log.Print("Warning: found duplicate End option")
-			}
-			if opt.Code() != OptionEnd && opt.Code() != OptionPad {
-				log.Printf("Warning: found option %v (%v) after End option", opt.Code(), opt.Code().String())
-			}
-		}
-		if opt.Code() == OptionEnd {
-			foundOptionEnd = true
-		}
-	}
-	if !foundOptionEnd {
-		log.Print("Warning: no End option found")
+	for _, err := range d.Validate() {
+		DefaultLogger.Printf("Warning: %v", err)
 	}
 }
 
 // IsOptionRequested returns true if that option is within the requested
 // options of the DHCPv4 message.
 func (d *DHCPv4) IsOptionRequested(requested OptionCode) bool {
-	for _, optprl := range d.GetOption(OptionParameterRequestList) {
-		for _, o := range optprl.(*OptParameterRequestList).RequestedOpts {
+	for _, opt := range d.GetOption(OptionParameterRequestList) {
+		optprl, ok := opt.(*OptParameterRequestList)
+		if !ok {
+			continue
+		}
+		for _, o := range optprl.RequestedOpts {
 			if o == requested {
 				return true
 			}
@@ -717,37 +1226,130 @@ func (d *DHCPv4) IsOptionRequested(requested OptionCode) bool {
 }
 
 // ToBytes encodes a DHCPv4 structure into a sequence of bytes in its wire
-// format.
+// format. If the option overload option is present, the options that do not
+// fit in MaxMessageSize spill over into the sname and file fields it
+// designates, which replace their contents. Options longer than 255 bytes
+// are split into several instances, see RFC 3396.
 func (d *DHCPv4) ToBytes() []byte {
-	// This won't check if the End option is present, you've been warned
-	var ret []byte
-	u32 := make([]byte, 4)
-	u16 := make([]byte, 2)
-
-	ret = append(ret, byte(d.opcode))
-	ret = append(ret, byte(d.hwType))
-	ret = append(ret, byte(d.hwAddrLen))
-	ret = append(ret, byte(d.hopCount))
-	binary.BigEndian.PutUint32(u32, d.transactionID)
-	ret = append(ret, u32...)
-	binary.BigEndian.PutUint16(u16, d.numSeconds)
-	ret = append(ret, u16...)
-	binary.BigEndian.PutUint16(u16, d.flags)
-	ret = append(ret, u16...)
-	ret = append(ret, d.clientIPAddr.To4()...)
-	ret = append(ret, d.yourIPAddr.To4()...)
-	ret = append(ret, d.serverIPAddr.To4()...)
-	ret = append(ret, d.gatewayIPAddr.To4()...)
-	ret = append(ret, d.clientHwAddr[:16]...)
-	ret = append(ret, d.serverHostName[:64]...)
-	ret = append(ret, d.bootFileName[:128]...)
-
-	d.ValidateOptions() // print warnings about broken options, if any
-	ret = append(ret, MagicCookie...)
+	// This won't check if the End option is present, use Validate for that
+	return d.appendTo(make([]byte, 0, d.size()))
+}
+
+// ToBytesWithMaxSize works like ToBytes, but makes sure that the packet is at
+// least MinMessageSize bytes long, padding it with Pad options, and at most
+// maxSize bytes long, e.g. the MaximumMessageSize of the request it answers.
+// If it is too large and has no option overload option, the options spill over
+// into the sname and file fields, provided they are empty, and the option
+// overload option is added. ErrMessageTooLarge is returned if the packet does
+// not fit anyway.
+func (d *DHCPv4) ToBytesWithMaxSize(maxSize int) ([]byte, error) {
+	buf := d.ToBytes()
+	if len(buf) > maxSize && d.GetOneOption(OptionOptionOverload) == nil {
+		var overload Overload
+		if isZero(d.bootFileName[:]) {
+			overload |= OverloadFile
+		}
+		if isZero(d.serverHostName[:]) {
+			overload |= OverloadSName
+		}
+		if overload != 0 {
+			// the option overload option goes first, so that it stays in
+			// the options field
+			overloaded := *d
+			overloaded.options = append([]Option{&OptOptionOverload{Overload: overload}}, d.options...)
+			buf = overloaded.ToBytes()
+		}
+	}
+	if len(buf) > maxSize {
+		return nil, ErrMessageTooLarge
+	}
+	for len(buf) < MinMessageSize {
+		buf = append(buf, byte(OptionPad))
+	}
+	return buf, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, and works like ToBytes.
+func (d *DHCPv4) MarshalBinary() ([]byte, error) {
+	return d.appendTo(make([]byte, 0, d.size())), nil
+}
+
+// serializeBufPool holds the buffers used by WriteTo, so that serializing
+// many packets does not allocate a new buffer every time.
+var serializeBufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, MaxMessageSize)
+		return &b
+	},
+}
+
+// WriteTo implements io.WriterTo, serializing the packet into a pooled buffer
+// and writing it to w with a single Write call.
+func (d *DHCPv4) WriteTo(w io.Writer) (int64, error) {
+	bp := serializeBufPool.Get().(*[]byte)
+	buf := d.appendTo((*bp)[:0])
+	n, err := w.Write(buf)
+	*bp = buf
+	serializeBufPool.Put(bp)
+	return int64(n), err
+}
+
+// size returns the size in bytes of the serialized packet.
+func (d *DHCPv4) size() int {
+	size := HeaderSize
+	if !d.bootp || len(d.options) > 0 {
+		size += len(MagicCookie)
+	}
 	for _, opt := range d.options {
-		ret = append(ret, opt.ToBytes()...)
+		size += optionSize(opt)
 	}
-	return ret
+	if d.bootp && size < HeaderSize+BOOTPVendorSize {
+		size = HeaderSize + BOOTPVendorSize
+	}
+	return size
+}
+
+// appendTo appends the serialized packet to buf and returns the extended
+// buffer. The header is written in place, so if buf has enough capacity no
+// allocation is needed besides the ones done by the options.
+func (d *DHCPv4) appendTo(buf []byte) []byte {
+	var zeroHeader [HeaderSize]byte
+	start := len(buf)
+	buf = append(buf, zeroHeader[:]...)
+	hdr := buf[start:]
+	hdr[0] = byte(d.opcode)
+	hdr[1] = byte(d.hwType)
+	hdr[2] = d.hwAddrLen
+	hdr[3] = d.hopCount
+	binary.BigEndian.PutUint32(hdr[4:8], d.transactionID)
+	binary.BigEndian.PutUint16(hdr[8:10], d.numSeconds)
+	binary.BigEndian.PutUint16(hdr[10:12], d.flags)
+	copy(hdr[12:16], d.clientIPAddr.To4())
+	copy(hdr[16:20], d.yourIPAddr.To4())
+	copy(hdr[20:24], d.serverIPAddr.To4())
+	copy(hdr[24:28], d.gatewayIPAddr.To4())
+	copy(hdr[28:44], d.clientHwAddr[:])
+	copy(hdr[44:108], d.serverHostName[:])
+	copy(hdr[108:236], d.bootFileName[:])
+
+	options := d.options
+	if overload, ok := d.GetOneOption(OptionOptionOverload).(*OptOptionOverload); ok {
+		options = d.overloadOptions(hdr, overload.Overload)
+	}
+	if !d.bootp || len(options) > 0 {
+		buf = append(buf, MagicCookie...)
+		for _, opt := range options {
+			buf = appendOption(buf, opt)
+		}
+	}
+	if d.bootp {
+		// fill the vendor area with Pad options, or zeros if there are no
+		// options at all
+		for len(buf)-start < HeaderSize+BOOTPVendorSize {
+			buf = append(buf, 0)
+		}
+	}
+	return buf
 }
 
 // OptionGetter is a interface that knows how to retrieve an option from a
@@ -1,8 +1,15 @@
 package dhcpv4
 
 import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
 	"net"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/quick"
+	"time"
 
 	"github.com/insomniacslk/dhcp/iana"
 	"github.com/stretchr/testify/require"
@@ -71,11 +78,11 @@ func TestFromBytes(t *testing.T) {
 	require.True(t, d.ClientIPAddr().Equal(net.IPv4zero))
 	require.True(t, d.YourIPAddr().Equal(net.IPv4zero))
 	require.True(t, d.GatewayIPAddr().Equal(net.IPv4zero))
-	clientHwAddr := d.ClientHwAddr()
+	clientHwAddr := d.ClientHwAddrRaw()
 	require.Equal(t, clientHwAddr[:], []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
-	hostname := d.ServerHostName()
+	hostname := d.ServerHostNameRaw()
 	require.Equal(t, hostname[:], expectedHostname)
-	bootfileName := d.BootFileName()
+	bootfileName := d.BootFileNameRaw()
 	require.Equal(t, bootfileName[:], expectedBootfilename)
 	// no need to check Magic Cookie as it is already validated in FromBytes
 	// above
@@ -122,6 +129,85 @@ func TestFromBytesInvalidOptions(t *testing.T) {
 	require.Error(t, err)
 }
 
+// parseConfigTestPacket returns a Discover followed by the given options.
+func parseConfigTestPacket(t *testing.T, options ...byte) []byte {
+	d, err := New()
+	require.NoError(t, err)
+	data := d.ToBytes()[:HeaderSize]
+	data = append(data, MagicCookie...)
+	data = append(data, byte(OptionDHCPMessageType), 1, byte(MessageTypeDiscover))
+	return append(data, options...)
+}
+
+func TestFromBytesWithConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name                      string
+		data                      []byte
+		defaults, strict, lenient bool
+	}{
+		{"valid", parseConfigTestPacket(t, 255, 0, 0), true, true, true},
+		{"no end", parseConfigTestPacket(t), true, false, true},
+		{"trailing data", parseConfigTestPacket(t, 255, 1, 2), true, false, true},
+		{"truncated address list", parseConfigTestPacket(t, 4, 5, 10, 0, 0, 1, 10, 255), true, false, true},
+		{"truncated option", parseConfigTestPacket(t, 12, 5, 'h'), false, false, true},
+		{"malformed option", parseConfigTestPacket(t, 1, 2, 255, 255, 255), false, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := FromBytes(tc.data)
+			require.Equal(t, tc.defaults, err == nil, "%v", err)
+			_, err = FromBytesWithConfig(tc.data, StrictParseConfig)
+			require.Equal(t, tc.strict, err == nil, "%v", err)
+			d, err := FromBytesWithConfig(tc.data, LenientParseConfig)
+			require.Equal(t, tc.lenient, err == nil, "%v", err)
+			require.Equal(t, MessageTypeDiscover, *d.MessageType())
+		})
+	}
+}
+
+func TestFromBytesWithConfigErrors(t *testing.T) {
+	_, err := FromBytesWithConfig(parseConfigTestPacket(t), ParseConfig{RejectTrailingData: true})
+	require.Equal(t, ErrNoEndOption, err)
+	_, err = FromBytesWithConfig(parseConfigTestPacket(t, 255, 1), ParseConfig{RejectTrailingData: true})
+	require.Equal(t, ErrTrailingData, err)
+	// the options without a specific type are checked too
+	_, err = FromBytesWithConfig(parseConfigTestPacket(t, 4, 5, 10, 0, 0, 1, 10, 255), ParseConfig{StrictLengths: true})
+	require.IsType(t, &OptionParseError{}, err)
+	require.Equal(t, OptionTimeServer, err.(*OptionParseError).Code)
+}
+
+func TestFromBytesWithConfigLenient(t *testing.T) {
+	// the option that cannot be parsed is kept as is
+	d, err := FromBytesWithConfig(parseConfigTestPacket(t, 1, 2, 255, 255, 255), LenientParseConfig)
+	require.NoError(t, err)
+	require.Equal(t, &OptionGeneric{OptionCode: OptionSubnetMask, Data: []byte{255, 255}}, d.GetOneOption(OptionSubnetMask))
+	require.Equal(t, 255, int(d.Options()[len(d.Options())-1].Code()))
+
+	// the options before a truncated one are decoded
+	d, err = FromBytesWithConfig(parseConfigTestPacket(t, 12, 2, 'h', 'i', 12, 5, 'h'), LenientParseConfig)
+	require.NoError(t, err)
+	require.Equal(t, "hi", d.HostName())
+
+	// the accessors ignore the options kept as is
+	d, err = FromBytesWithConfig(parseConfigTestPacket(t, 53, 2, 1, 1, 255), LenientParseConfig)
+	require.NoError(t, err)
+	require.IsType(t, &OptionGeneric{}, d.GetOneOption(OptionDHCPMessageType))
+	require.Nil(t, d.MessageType())
+	d.UpdateOption(&OptionGeneric{OptionCode: OptionParameterRequestList})
+	require.False(t, d.IsOptionRequested(OptionRouter))
+	d = WithRequestedOptions(OptionRouter)(d)
+	require.True(t, d.IsOptionRequested(OptionRouter))
+
+	// without magic cookie, a plain BOOTP packet
+	data := parseConfigTestPacket(t, 255)
+	copy(data[HeaderSize:], []byte{1, 2, 3, 4})
+	_, err = FromBytes(data)
+	require.Equal(t, ErrInvalidMagicCookie, err)
+	d, err = FromBytesWithConfig(data, LenientParseConfig)
+	require.NoError(t, err)
+	require.True(t, d.IsBOOTP())
+	require.Empty(t, d.Options())
+}
+
 func TestSettersAndGetters(t *testing.T) {
 	data := []byte{
 		1,                      // dhcp request
@@ -212,30 +298,30 @@ func TestSettersAndGetters(t *testing.T) {
 	require.True(t, d.GatewayIPAddr().Equal(net.IPv4(16, 15, 14, 13)))
 
 	// getter/setter for ClientHwAddr
-	hwaddr := d.ClientHwAddr()
+	hwaddr := d.ClientHwAddrRaw()
 	require.Equal(t, []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, hwaddr[:])
 	d.SetFlags(0)
 
 	// getter/setter for ServerHostName
-	serverhostname := d.ServerHostName()
+	serverhostname := d.ServerHostNameRaw()
 	require.Equal(t, expectedHostname, serverhostname[:])
 	newHostname := []byte{'t', 'e', 's', 't'}
 	for i := 0; i < 60; i++ {
 		newHostname = append(newHostname, 0)
 	}
-	d.SetServerHostName(newHostname)
-	serverhostname = d.ServerHostName()
+	d.SetServerHostNameRaw(newHostname)
+	serverhostname = d.ServerHostNameRaw()
 	require.Equal(t, newHostname, serverhostname[:])
 
 	// getter/setter for BootFileName
-	bootfilename := d.BootFileName()
+	bootfilename := d.BootFileNameRaw()
 	require.Equal(t, expectedBootfilename, bootfilename[:])
 	newBootfilename := []byte{'t', 'e', 's', 't'}
 	for i := 0; i < 124; i++ {
 		newBootfilename = append(newBootfilename, 0)
 	}
-	d.SetBootFileName(newBootfilename)
-	bootfilename = d.BootFileName()
+	d.SetBootFileNameRaw(newBootfilename)
+	bootfilename = d.BootFileNameRaw()
 	require.Equal(t, newBootfilename, bootfilename[:])
 }
 
@@ -270,20 +356,52 @@ func TestToStringMethods(t *testing.T) {
 
 	// ClientHwAddrToString
 	d.SetHwAddrLen(6)
-	d.SetClientHwAddr([]byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	d.SetClientHwAddrRaw([]byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
 	require.Equal(t, "aa:bb:cc:dd:ee:ff", d.ClientHwAddrToString())
-	d.SetClientHwAddr([]byte{1, 2, 3, 4, 1, 2, 3, 4, 1, 2, 3, 4, 1, 2, 3, 4, 1, 2, 3, 4}) // 20 bytes
+	d.SetClientHwAddrRaw([]byte{1, 2, 3, 4, 1, 2, 3, 4, 1, 2, 3, 4, 1, 2, 3, 4, 1, 2, 3, 4}) // 20 bytes
 	require.Equal(t, "01:02:03:04:01:02", d.ClientHwAddrToString())
 
 	// ServerHostNameToString
-	d.SetServerHostName([]byte("my.host.local"))
+	d.SetServerHostName("my.host.local")
 	require.Equal(t, "my.host.local", d.ServerHostNameToString())
 
 	// BootFileNameToString
-	d.SetBootFileName([]byte("/my/boot/file"))
+	d.SetBootFileName("/my/boot/file")
 	require.Equal(t, "/my/boot/file", d.BootFileNameToString())
 }
 
+func TestHeaderAccessors(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	hwaddr := net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 1, 2}
+	require.NoError(t, d.SetClientHwAddr(hwaddr))
+	require.Equal(t, hwaddr, d.ClientHwAddr())
+	require.Equal(t, uint8(8), d.HwAddrLen())
+	require.Error(t, d.SetClientHwAddr(make(net.HardwareAddr, 17)))
+	require.Equal(t, hwaddr, d.ClientHwAddr())
+	// the address is a copy
+	d.ClientHwAddr()[0] = 0
+	require.Equal(t, hwaddr, d.ClientHwAddr())
+	// a length larger than the chaddr field, as sent by broken clients
+	d.hwAddrLen = 20
+	require.Len(t, d.ClientHwAddr(), 16)
+
+	require.NoError(t, d.SetServerHostName("boot.example.com"))
+	require.Equal(t, "boot.example.com", d.ServerHostName())
+	require.Error(t, d.SetServerHostName(strings.Repeat("a", 64)))
+	require.Error(t, d.SetServerHostName("boot\x00"))
+	require.Equal(t, "boot.example.com", d.ServerHostName())
+	require.NoError(t, d.SetServerHostName(strings.Repeat("a", 63)))
+
+	require.NoError(t, d.SetBootFileName("pxelinux.0"))
+	require.Equal(t, "pxelinux.0", d.BootFileName())
+	require.Error(t, d.SetBootFileName(strings.Repeat("a", 128)))
+	require.Equal(t, "pxelinux.0", d.BootFileName())
+	require.NoError(t, d.SetBootFileName(""))
+	require.Equal(t, "", d.BootFileName())
+}
+
 func TestNewToBytes(t *testing.T) {
 	// the following bytes match what dhcpv4.New would create. Keep them in
 	// sync!
@@ -345,6 +463,46 @@ func TestGetOption(t *testing.T) {
 	require.Equal(t, d.GetOneOption(OptionRouter), nil)
 }
 
+func TestGetMergedOption(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	merged, err := d.GetMergedOption(OptionBootfileName)
+	require.NoError(t, err)
+	require.Nil(t, merged)
+
+	hostnameOpt := &OptHostName{HostName: "darkstar"}
+	d.AddOption(hostnameOpt)
+	d.AddOption(&OptBootfileName{[]byte("boot")})
+	d.AddOption(&OptBootfileName{[]byte(".img")})
+	merged, err = d.GetMergedOption(OptionHostName)
+	require.NoError(t, err)
+	require.Equal(t, hostnameOpt, merged)
+	merged, err = d.GetMergedOption(OptionBootfileName)
+	require.NoError(t, err)
+	require.Equal(t, &OptBootfileName{[]byte("boot.img")}, merged)
+	// the instances are still there
+	require.Len(t, d.GetOption(OptionBootfileName), 2)
+
+	// the merged option is the one the receiver gets
+	parsed, err := FromBytes(d.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, merged, parsed.GetOneOption(OptionBootfileName))
+
+	// instances of a long option
+	long := &OptionGeneric{OptionCode: OptionCode(224), Data: bytes.Repeat([]byte{1}, 300)}
+	d.AddOption(long)
+	d.AddOption(&OptionGeneric{OptionCode: OptionCode(224), Data: []byte{2}})
+	merged, err = d.GetMergedOption(OptionCode(224))
+	require.NoError(t, err)
+	require.Equal(t, &OptionGeneric{OptionCode: OptionCode(224), Data: append(bytes.Repeat([]byte{1}, 300), 2)}, merged)
+
+	// instances that are invalid together
+	d.AddOption(&OptIPAddressLeaseTime{LeaseTime: 3600})
+	d.AddOption(&OptIPAddressLeaseTime{LeaseTime: 7200})
+	_, err = d.GetMergedOption(OptionIPAddressLeaseTime)
+	require.Error(t, err)
+}
+
 func TestAddOption(t *testing.T) {
 	d, err := New()
 	if err != nil {
@@ -363,6 +521,20 @@ func TestAddOption(t *testing.T) {
 	require.Equal(t, options[3].Code(), OptionEnd)
 }
 
+func TestUpdateOption(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.AddOption(&OptHostName{HostName: "darkstar"})
+	d.UpdateOption(&OptHostName{HostName: "lightstar"})
+	d.UpdateOption(&OptDomainName{DomainName: "example.com"})
+
+	options := d.Options()
+	require.Len(t, options, 3)
+	require.Equal(t, &OptHostName{HostName: "lightstar"}, options[0])
+	require.Equal(t, &OptDomainName{DomainName: "example.com"}, options[1])
+	require.Equal(t, OptionEnd, options[2].Code())
+}
+
 func TestStrippedOptions(t *testing.T) {
 	// Normal set of options that terminate with OptionEnd.
 	d, err := New()
@@ -429,6 +601,104 @@ func TestDHCPv4NewRequestFromOfferWithModifier(t *testing.T) {
 	require.Equal(t, "User Class Information -> linuxboot", req.options[3].String())
 }
 
+func TestNewRenewFromACK(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.SetOpcode(OpcodeBootReply)
+	ack.SetBroadcast()
+	ack.SetClientHwAddr([]byte{1, 2, 3, 4, 5, 6})
+	ack.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	ack.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+	ack.AddOption(&OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)})
+
+	req, err := NewRenewFromACK(ack)
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootRequest, req.Opcode())
+	require.NotNil(t, req.MessageType())
+	require.Equal(t, MessageTypeRequest, *req.MessageType())
+	require.True(t, req.IsUnicast())
+	require.True(t, req.ClientIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+	require.Equal(t, ack.ClientHwAddr(), req.ClientHwAddr())
+	require.False(t, HasOption(req, OptionServerIdentifier))
+	require.False(t, HasOption(req, OptionRequestedIPAddress))
+}
+
+func TestNewReleaseFromACK(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.SetOpcode(OpcodeBootReply)
+	ack.SetClientHwAddr([]byte{1, 2, 3, 4, 5, 6})
+	ack.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	ack.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+
+	_, err = NewReleaseFromACK(ack)
+	require.Error(t, err, "missing server identifier")
+
+	ack.AddOption(&OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)})
+	rel, err := NewReleaseFromACK(ack)
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootRequest, rel.Opcode())
+	require.NotNil(t, rel.MessageType())
+	require.Equal(t, MessageTypeRelease, *rel.MessageType())
+	require.True(t, rel.IsUnicast())
+	require.True(t, rel.ClientIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+	require.Equal(t, ack.ClientHwAddr(), rel.ClientHwAddr())
+	require.NotEqual(t, ack.TransactionID(), rel.TransactionID())
+	sid := rel.GetOneOption(OptionServerIdentifier)
+	require.NotNil(t, sid)
+	require.True(t, sid.(*OptServerIdentifier).ServerID.Equal(net.IPv4(192, 168, 0, 1)))
+	require.False(t, HasOption(rel, OptionRequestedIPAddress))
+}
+
+func TestNewDecline(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.SetOpcode(OpcodeBootReply)
+	ack.SetClientHwAddr([]byte{1, 2, 3, 4, 5, 6})
+	ack.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	ack.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+
+	_, err = NewDecline(ack)
+	require.Error(t, err, "missing server identifier")
+
+	ack.AddOption(&OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)})
+	dec, err := NewDecline(ack)
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootRequest, dec.Opcode())
+	require.NotNil(t, dec.MessageType())
+	require.Equal(t, MessageTypeDecline, *dec.MessageType())
+	require.True(t, dec.IsBroadcast())
+	require.True(t, dec.ClientIPAddr().Equal(net.IPv4zero))
+	require.Equal(t, ack.ClientHwAddr(), dec.ClientHwAddr())
+	req := dec.GetOneOption(OptionRequestedIPAddress)
+	require.NotNil(t, req)
+	require.True(t, req.(*OptRequestedIPAddress).RequestedAddr.Equal(net.IPv4(192, 168, 0, 10)))
+	require.True(t, HasOption(dec, OptionServerIdentifier))
+}
+
+func TestNewForceRenew(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.SetOpcode(OpcodeBootReply)
+	ack.SetClientHwAddr([]byte{1, 2, 3, 4, 5, 6})
+	ack.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	ack.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+
+	_, err = NewForceRenew(ack)
+	require.Error(t, err, "missing server identifier")
+
+	ack.AddOption(&OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)})
+	fr, err := NewForceRenew(ack)
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootReply, fr.Opcode())
+	require.NotNil(t, fr.MessageType())
+	require.Equal(t, MessageTypeForceRenew, *fr.MessageType())
+	require.Equal(t, "FORCERENEW", fr.MessageType().String())
+	require.True(t, fr.ClientIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+	require.Equal(t, ack.ClientHwAddr(), fr.ClientHwAddr())
+	require.True(t, HasOption(fr, OptionServerIdentifier))
+}
+
 func TestNewReplyFromRequest(t *testing.T) {
 	discover, err := New()
 	require.NoError(t, err)
@@ -469,7 +739,7 @@ func TestNewDiscovery(t *testing.T) {
 	require.Equal(t, iana.HwTypeEthernet, m.HwType())
 	var expectedHwAddr [16]byte
 	copy(expectedHwAddr[:], hwAddr)
-	require.Equal(t, expectedHwAddr, m.ClientHwAddr())
+	require.Equal(t, expectedHwAddr, m.ClientHwAddrRaw())
 	require.Equal(t, len(hwAddr), int(m.HwAddrLen()))
 	require.True(t, m.IsBroadcast())
 	require.True(t, HasOption(m, OptionParameterRequestList))
@@ -486,7 +756,7 @@ func TestNewInform(t *testing.T) {
 	require.Equal(t, iana.HwTypeEthernet, m.HwType())
 	var expectedHwAddr [16]byte
 	copy(expectedHwAddr[:], hwAddr)
-	require.Equal(t, expectedHwAddr, m.ClientHwAddr())
+	require.Equal(t, expectedHwAddr, m.ClientHwAddrRaw())
 	require.Equal(t, len(hwAddr), int(m.HwAddrLen()))
 	require.NotNil(t, m.MessageType())
 	require.Equal(t, MessageTypeInform, *m.MessageType())
@@ -505,3 +775,201 @@ func TestIsOptionRequested(t *testing.T) {
 
 // TODO
 //      test Summary() and String()
+
+func TestFromBytesToBytesRoundTrip(t *testing.T) {
+	f := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		d, err := New()
+		if err != nil {
+			return false
+		}
+		d.SetOpcode(OpcodeType(1 + r.Intn(2)))
+		d.SetHwType(iana.HwTypeEthernet)
+		d.SetHwAddrLen(6)
+		d.SetHopCount(uint8(r.Intn(256)))
+		d.SetTransactionID(r.Uint32())
+		d.SetNumSeconds(uint16(r.Intn(1 << 16)))
+		d.SetFlags(uint16(r.Intn(1 << 16)))
+		d.SetClientIPAddr(net.IP(randomBytes(r, 4, 4)))
+		d.SetYourIPAddr(net.IP(randomBytes(r, 4, 4)))
+		d.SetServerIPAddr(net.IP(randomBytes(r, 4, 4)))
+		d.SetGatewayIPAddr(net.IP(randomBytes(r, 4, 4)))
+		d.SetClientHwAddr(randomBytes(r, 6, 6))
+		d.SetServerHostNameRaw([]byte(randomString(r, 0, 64)))
+		d.SetBootFileNameRaw([]byte(randomString(r, 0, 128)))
+		for _, gen := range optionGenerators {
+			if r.Intn(2) == 0 {
+				d.AddOption(gen(r))
+			}
+		}
+
+		data := d.ToBytes()
+		parsed, err := FromBytes(data)
+		if err != nil {
+			t.Logf("cannot parse %v: %v", data, err)
+			return false
+		}
+		if !reflect.DeepEqual(data, parsed.ToBytes()) {
+			t.Logf("%v != %v", d, parsed)
+			return false
+		}
+		return reflect.DeepEqual(d.Options(), parsed.Options())
+	}
+	require.NoError(t, quick.Check(f, nil))
+}
+
+func TestMarshalBinaryAndWriteTo(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.SetClientHwAddr([]byte{1, 2, 3, 4, 5, 6})
+	d.AddOption(&OptMessageType{MessageType: MessageTypeDiscover})
+	d.AddOption(&OptHostName{HostName: "test"})
+	expected := d.ToBytes()
+
+	data, err := d.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, expected, data)
+	require.Equal(t, len(data), cap(data), "buffer should be preallocated")
+
+	var buf bytes.Buffer
+	for i := 0; i < 2; i++ {
+		buf.Reset()
+		n, err := d.WriteTo(&buf)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(expected)), n)
+		require.Equal(t, expected, buf.Bytes())
+	}
+}
+
+func TestToBytesNilIPs(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.SetGatewayIPAddr(nil)
+	data := d.ToBytes()
+	require.Equal(t, d.size(), len(data))
+	require.Equal(t, []byte{0, 0, 0, 0}, data[24:28])
+}
+
+func benchmarkPacket(b *testing.B) *DHCPv4 {
+	d, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		b.Fatal(err)
+	}
+	d.AddOption(&OptHostName{HostName: "benchmark"})
+	d.AddOption(&OptClassIdentifier{Identifier: "PXEClient:Arch:00000:UNDI:002001"})
+	return d
+}
+
+func BenchmarkToBytes(b *testing.B) {
+	d := benchmarkPacket(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.MarshalBinary()
+	}
+}
+
+func BenchmarkWriteTo(b *testing.B) {
+	d := benchmarkPacket(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.WriteTo(ioutil.Discard)
+	}
+}
+
+func TestOptionAccessors(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	require.Nil(t, d.SubnetMask())
+	require.Nil(t, d.Router())
+	require.Nil(t, d.DNS())
+	require.Nil(t, d.NTPServers())
+	require.Nil(t, d.TimeOffset())
+	require.Equal(t, "", d.HostName())
+	require.Equal(t, "", d.DomainName())
+	require.Nil(t, d.BroadcastAddress())
+	require.Nil(t, d.RequestedIPAddress())
+	require.Nil(t, d.ServerIdentifier())
+	require.Equal(t, time.Hour, d.IPAddressLeaseTime(time.Hour))
+
+	d.AddOption(&OptSubnetMask{SubnetMask: net.IPv4Mask(255, 255, 255, 0)})
+	d.AddOption(&OptRouter{Routers: []net.IP{net.IPv4(192, 168, 0, 1)}})
+	d.AddOption(&OptDomainNameServer{NameServers: []net.IP{net.IPv4(8, 8, 8, 8)}})
+	d.AddOption(&OptNTPServers{NTPServers: []net.IP{net.IPv4(192, 168, 0, 2)}})
+	d.AddOption(&OptTimeOffset{Offset: 3600})
+	d.AddOption(&OptHostName{HostName: "client"})
+	d.AddOption(&OptDomainName{DomainName: "example.com"})
+	d.AddOption(&OptBroadcastAddress{BroadcastAddress: net.IPv4(192, 168, 0, 255)})
+	d.AddOption(&OptRequestedIPAddress{RequestedAddr: net.IPv4(192, 168, 0, 10)})
+	d.AddOption(&OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)})
+	d.AddOption(&OptIPAddressLeaseTime{LeaseTime: 3600})
+
+	require.Equal(t, net.IPv4Mask(255, 255, 255, 0), d.SubnetMask())
+	require.Equal(t, []net.IP{net.IPv4(192, 168, 0, 1)}, d.Router())
+	require.Equal(t, []net.IP{net.IPv4(8, 8, 8, 8)}, d.DNS())
+	require.Equal(t, []net.IP{net.IPv4(192, 168, 0, 2)}, d.NTPServers())
+	require.Equal(t, TimeOffset(3600), *d.TimeOffset())
+	require.Equal(t, "client", d.HostName())
+	require.Equal(t, "example.com", d.DomainName())
+	require.Equal(t, net.IPv4(192, 168, 0, 255), d.BroadcastAddress())
+	require.Equal(t, net.IPv4(192, 168, 0, 10), d.RequestedIPAddress())
+	require.Equal(t, net.IPv4(192, 168, 0, 1), d.ServerIdentifier())
+	require.Equal(t, time.Hour, d.IPAddressLeaseTime(0))
+}
+
+func TestSelectedSubnet(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	require.Nil(t, d.SubnetSelection())
+	require.Nil(t, d.SelectedSubnet())
+
+	d.SetGatewayIPAddr(net.IP{10, 0, 0, 1})
+	require.Equal(t, net.IP{10, 0, 0, 1}, d.SelectedSubnet())
+
+	d = WithLinkSelection(net.IP{10, 0, 1, 0})(d)
+	require.Equal(t, net.IP{10, 0, 1, 0}, d.SelectedSubnet())
+
+	d = WithSubnetSelection(net.IP{10, 0, 2, 0})(d)
+	require.Equal(t, net.IP{10, 0, 2, 0}, d.SubnetSelection())
+	require.Equal(t, net.IP{10, 0, 2, 0}, d.SelectedSubnet())
+
+	// and once on the wire
+	d, err = FromBytes(d.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 2, 0}, d.SelectedSubnet())
+	opt := d.GetOneOption(OptionRelayAgentInformation).(*OptRelayAgentInformation)
+	require.Equal(t, net.IP{10, 0, 1, 0}, opt.LinkSelection())
+}
+
+func TestEffectiveBroadcastAddress(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	require.Nil(t, d.EffectiveBroadcastAddress())
+
+	d.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	require.Nil(t, d.EffectiveBroadcastAddress(), "no subnet mask")
+
+	d.AddOption(&OptSubnetMask{SubnetMask: net.IPv4Mask(255, 255, 255, 0)})
+	require.Equal(t, net.IP{192, 168, 0, 255}, d.EffectiveBroadcastAddress())
+
+	d.AddOption(&OptBroadcastAddress{BroadcastAddress: net.IP{192, 168, 0, 0}})
+	require.Equal(t, net.IP{192, 168, 0, 0}, d.EffectiveBroadcastAddress())
+
+	// outside of the subnet
+	d.UpdateOption(&OptBroadcastAddress{BroadcastAddress: net.IP{10, 0, 0, 255}})
+	require.Equal(t, net.IP{192, 168, 0, 255}, d.EffectiveBroadcastAddress())
+}
+
+func TestFromBytesCopiesData(t *testing.T) {
+	m, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	m.SetGatewayIPAddr(net.IPv4(10, 0, 0, 1))
+	m.UpdateOption(&OptionGeneric{OptionCode: 224, Data: []byte{1, 2}})
+	data := m.ToBytes()
+	d, err := FromBytes(data)
+	require.NoError(t, err)
+	for i := range data {
+		data[i] = 0
+	}
+	require.Equal(t, net.IP{10, 0, 0, 1}, d.GatewayIPAddr())
+	require.Equal(t, []byte{224, 2, 1, 2}, d.GetOneOption(224).ToBytes())
+}
@@ -0,0 +1,76 @@
+package dhcpv4
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrShortByteStream is an error that is thrown any time a short byte stream is
+// detected during option parsing.
+var ErrShortByteStream = errors.New("short byte stream")
+
+// ErrZeroLengthByteStream is an error that is thrown any time a zero-length
+// byte stream is encountered.
+var ErrZeroLengthByteStream = errors.New("zero-length byte stream")
+
+// ErrShortPacket is returned when parsing a packet shorter than the fixed
+// DHCPv4 header.
+var ErrShortPacket = fmt.Errorf("invalid DHCPv4 header: shorter than %v bytes", HeaderSize)
+
+// ErrInvalidMagicCookie is returned when the options field of a packet does
+// not start with the Magic Cookie.
+var ErrInvalidMagicCookie = errors.New("invalid magic cookie")
+
+// ErrNoEndOption is returned when strictly parsing a packet whose options do
+// not end with the End option.
+var ErrNoEndOption = errors.New("no End option")
+
+// ErrTrailingData is returned when strictly parsing a packet with data after
+// the End option.
+var ErrTrailingData = errors.New("data after the End option")
+
+// ErrMessageTooLarge is returned when a packet does not fit in the size its
+// receiver accepts.
+var ErrMessageTooLarge = errors.New("message larger than the maximum message size")
+
+// ErrNoMessageType is returned when a message has no DHCP Message Type
+// option, but one is required.
+var ErrNoMessageType = errors.New("no DHCP Message Type option")
+
+// ErrNoServerIdentifier is returned when a message has no Server Identifier
+// option, but one is required, e.g. to build a Request or a Release from it.
+var ErrNoServerIdentifier = errors.New("no Server Identifier option")
+
+// ErrNoLeaseTime is returned when an acknowledge has no IP Address Lease Time
+// option.
+var ErrNoLeaseTime = errors.New("no IP Address Lease Time option")
+
+// ErrTimeout is returned by the exchanges when no reply is received in time,
+// retransmissions included.
+var ErrTimeout = errors.New("timed out while listening for replies")
+
+// ErrNoAttempts is returned by the exchanges when the retransmission strategy
+// of the client does not allow a single transmission.
+var ErrNoAttempts = errors.New("no transmission allowed by the retransmission strategy")
+
+// ErrNoOfferSelected is returned by Client.Exchange when the SelectOffer
+// function of the client rejects all the offers.
+var ErrNoOfferSelected = errors.New("no offer selected")
+
+// OptionParseError is returned when an option of a packet cannot be parsed.
+type OptionParseError struct {
+	// Code is the code of the option.
+	Code OptionCode
+	// Err is the reason the option cannot be parsed, e.g.
+	// ErrShortByteStream.
+	Err error
+}
+
+func (e *OptionParseError) Error() string {
+	return fmt.Sprintf("cannot parse option %v: %v", e.Code, e.Err)
+}
+
+// Unwrap returns the reason the option cannot be parsed.
+func (e *OptionParseError) Unwrap() error {
+	return e.Err
+}
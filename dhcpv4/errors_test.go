@@ -0,0 +1,59 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromBytesErrors(t *testing.T) {
+	_, err := FromBytes(make([]byte, HeaderSize-1))
+	require.Equal(t, ErrShortPacket, err)
+
+	d, err := New()
+	require.NoError(t, err)
+	data := d.ToBytes()
+	data[HeaderSize] = 0
+	_, err = FromBytes(data)
+	require.Equal(t, ErrInvalidMagicCookie, err)
+
+	// a subnet mask option holding 3 bytes instead of 4
+	data = append(d.ToBytes()[:HeaderSize+4], 1, 3, 255, 255, 255, 255)
+	_, err = FromBytes(data)
+	perr, ok := err.(*OptionParseError)
+	require.True(t, ok, "should get an OptionParseError")
+	require.Equal(t, OptionSubnetMask, perr.Code)
+
+	// a truncated option
+	data = append(d.ToBytes()[:HeaderSize+4], 1, 4, 255)
+	_, err = FromBytes(data)
+	perr, ok = err.(*OptionParseError)
+	require.True(t, ok, "should get an OptionParseError")
+	require.Equal(t, OptionSubnetMask, perr.Code)
+}
+
+func TestNoServerIdentifierErrors(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	_, err = NewReleaseFromACK(ack)
+	require.Equal(t, ErrNoServerIdentifier, err)
+	_, err = NewForceRenew(ack)
+	require.Equal(t, ErrNoServerIdentifier, err)
+}
+
+func TestGenericServerIdentifierErrors(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	ack.AddOption(&OptionGeneric{OptionCode: OptionServerIdentifier, Data: []byte{192, 168, 0, 1}})
+	_, err = NewRequestFromOffer(ack)
+	require.Equal(t, ErrNoServerIdentifier, err)
+	_, err = NewReleaseFromACK(ack)
+	require.Equal(t, ErrNoServerIdentifier, err)
+	_, err = NewDecline(ack)
+	require.Equal(t, ErrNoServerIdentifier, err)
+	_, err = NewForceRenew(ack)
+	require.Equal(t, ErrNoServerIdentifier, err)
+}
@@ -0,0 +1,31 @@
+package dhcpv4
+
+import (
+	"golang.org/x/net/bpf"
+)
+
+// replyFilter returns a BPF program for the UDP sockets on which the client
+// receives the replies, which see the packets from their UDP header on. It
+// accepts the BOOTREPLY messages long enough to be parsed and, if xid is not
+// nil, only those of the transaction xid.
+func replyFilter(xid *uint32) []bpf.Instruction {
+	type check struct {
+		load bpf.Instruction
+		cond bpf.JumpTest
+		val  uint32
+	}
+	checks := []check{
+		{bpf.LoadExtension{Num: bpf.ExtLen}, bpf.JumpLessThan, 8 + 236},
+		{bpf.LoadAbsolute{Off: 8, Size: 1}, bpf.JumpNotEqual, uint32(OpcodeBootReply)},
+	}
+	if xid != nil {
+		checks = append(checks, check{bpf.LoadAbsolute{Off: 8 + 4, Size: 4}, bpf.JumpNotEqual, *xid})
+	}
+	var prog []bpf.Instruction
+	for i, c := range checks {
+		// a failed check skips the following ones and the accept
+		skip := 2*(len(checks)-i-1) + 1
+		prog = append(prog, c.load, bpf.JumpIf{Cond: c.cond, Val: c.val, SkipTrue: uint8(skip)})
+	}
+	return append(prog, bpf.RetConstant{Val: 1 << 16}, bpf.RetConstant{Val: 0})
+}
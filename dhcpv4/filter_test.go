@@ -0,0 +1,38 @@
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+// runFilter runs prog on a UDP packet carrying payload, and returns true if
+// the packet is accepted.
+func runFilter(t *testing.T, prog []bpf.Instruction, payload []byte) bool {
+	vm, err := bpf.NewVM(prog)
+	require.NoError(t, err)
+	udp := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint16(udp[2:4], ClientPort)
+	n, err := vm.Run(append(udp, payload...))
+	require.NoError(t, err)
+	return n > 0
+}
+
+func TestReplyFilter(t *testing.T) {
+	discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	offer, err := NewReplyFromRequest(discover)
+	require.NoError(t, err)
+	xid, other := offer.TransactionID(), offer.TransactionID()+1
+
+	require.True(t, runFilter(t, replyFilter(nil), offer.ToBytes()))
+	require.True(t, runFilter(t, replyFilter(&xid), offer.ToBytes()))
+	require.False(t, runFilter(t, replyFilter(&other), offer.ToBytes()))
+	// requests and truncated replies
+	require.False(t, runFilter(t, replyFilter(nil), discover.ToBytes()))
+	require.False(t, runFilter(t, replyFilter(&xid), discover.ToBytes()))
+	require.False(t, runFilter(t, replyFilter(nil), offer.ToBytes()[:200]))
+}
@@ -0,0 +1,77 @@
+package fixtures
+
+import (
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// Android phone of the AndroidJoin conversation.
+var (
+	AndroidHwAddr   = net.HardwareAddr{0xda, 0xa1, 0x19, 0x6e, 0x2b, 0x0c}
+	AndroidIP       = net.IP{192, 168, 1, 101}
+	AndroidHostName = "Pixel-7"
+)
+
+// androidClientOptions are the options that Android sends in both its discover
+// and its request, after the message type and the addresses.
+func androidClientOptions() []dhcpv4.Modifier {
+	return []dhcpv4.Modifier{
+		dhcpv4.WithMaximumMessageSize(1500),
+		dhcpv4.WithVendorClass("android-dhcp-13"),
+		dhcpv4.WithOption(&dhcpv4.OptHostName{HostName: AndroidHostName}),
+		dhcpv4.WithRequestedOptions(
+			dhcpv4.OptionSubnetMask,
+			dhcpv4.OptionRouter,
+			dhcpv4.OptionDomainNameServer,
+			dhcpv4.OptionDomainName,
+			dhcpv4.OptionInterfaceMTU,
+			dhcpv4.OptionBroadcastAddress,
+			dhcpv4.OptionIPAddressLeaseTime,
+			dhcpv4.OptionRenewTimeValue,
+			dhcpv4.OptionRebindingTimeValue,
+			dhcpv4.OptionVendorSpecificInformation,
+			dhcpv4.OptionURL,
+		),
+	}
+}
+
+// AndroidJoin returns the conversation of an Android phone joining a Wi-Fi
+// network. Android uses a randomized, locally administered hardware address
+// per network, identifies itself with an android-dhcp class identifier and
+// its host name, and does not set the broadcast flag, so the server unicasts
+// its replies to the offered address. The acknowledge carries the interface
+// MTU that Android requests.
+func AndroidJoin() *Conversation {
+	const xid = 0x3e4f2a17
+	clientID := dhcpv4.WithClientIdentifier(dhcpv4.NewClientIdentifierFromHwAddr(AndroidHwAddr))
+	discover := newRequest(AndroidHwAddr, xid, append([]dhcpv4.Modifier{
+		dhcpv4.WithMessageType(dhcpv4.MessageTypeDiscover),
+		clientID,
+	}, androidClientOptions()...)...)
+
+	offer := must(dhcpv4.NewReplyFromRequest(discover,
+		leaseOptions(dhcpv4.MessageTypeOffer, AndroidIP)...))
+
+	request := newRequest(AndroidHwAddr, xid, append([]dhcpv4.Modifier{
+		dhcpv4.WithMessageType(dhcpv4.MessageTypeRequest),
+		clientID,
+		dhcpv4.WithOption(&dhcpv4.OptRequestedIPAddress{RequestedAddr: AndroidIP}),
+		dhcpv4.WithOption(&dhcpv4.OptServerIdentifier{ServerID: ServerIP}),
+	}, androidClientOptions()...)...)
+
+	ack := must(dhcpv4.NewReplyFromRequest(request,
+		append(leaseOptions(dhcpv4.MessageTypeAck, AndroidIP), dhcpv4.WithInterfaceMTU(1500))...))
+
+	toClient := &net.UDPAddr{IP: AndroidIP, Port: dhcpv4.ClientPort}
+	return &Conversation{
+		Name:        "Android join",
+		Description: "an Android phone joins a Wi-Fi network",
+		Packets: []Packet{
+			{Src: clientBroadcast, Dst: toServers, Message: discover},
+			{FromServer: true, Src: fromServer, Dst: toClient, Message: offer},
+			{Src: clientBroadcast, Dst: toServers, Message: request},
+			{FromServer: true, Src: fromServer, Dst: toClient, Message: ack},
+		},
+	}
+}
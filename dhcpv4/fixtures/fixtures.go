@@ -0,0 +1,128 @@
+// Package fixtures provides realistic DHCPv4 conversations of common client
+// types, fully decoded, as test data for servers and middlewares built with
+// this library. They also document, by example, what these clients send and
+// what the replies they expect look like.
+//
+// The conversations take place on the 192.168.1.0/24 network, whose server
+// and router is 192.168.1.1. Every call returns new packets, so that tests can
+// modify them freely.
+package fixtures
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// Packet is a message of a conversation, with the addresses of its IP and UDP
+// headers.
+type Packet struct {
+	// FromServer is true for the replies of the server.
+	FromServer bool
+	Src        *net.UDPAddr
+	Dst        *net.UDPAddr
+	Message    *dhcpv4.DHCPv4
+}
+
+// Conversation is an exchange of messages between a client and a server, in
+// the order they are sent.
+type Conversation struct {
+	Name        string
+	Description string
+	Packets     []Packet
+}
+
+// Messages returns the messages of the conversation, in order.
+func (c *Conversation) Messages() []*dhcpv4.DHCPv4 {
+	msgs := make([]*dhcpv4.DHCPv4, 0, len(c.Packets))
+	for _, p := range c.Packets {
+		msgs = append(msgs, p.Message)
+	}
+	return msgs
+}
+
+// Requests returns the messages sent by the client, in order.
+func (c *Conversation) Requests() []*dhcpv4.DHCPv4 {
+	var msgs []*dhcpv4.DHCPv4
+	for _, p := range c.Packets {
+		if !p.FromServer {
+			msgs = append(msgs, p.Message)
+		}
+	}
+	return msgs
+}
+
+// All returns all the conversations of the package.
+func All() []*Conversation {
+	return []*Conversation{
+		PXEBoot(),
+		AndroidJoin(),
+		WindowsRenew(),
+	}
+}
+
+// Addresses shared by the conversations.
+var (
+	ServerIP  = net.IP{192, 168, 1, 1}
+	Netmask   = net.IPv4Mask(255, 255, 255, 0)
+	Broadcast = net.IP{192, 168, 1, 255}
+	DNS       = []net.IP{{192, 168, 1, 1}, {9, 9, 9, 9}}
+)
+
+const leaseTime = 24 * time.Hour
+
+var (
+	clientBroadcast = &net.UDPAddr{IP: net.IPv4zero, Port: dhcpv4.ClientPort}
+	toServers       = &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ServerPort}
+	toClients       = &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ClientPort}
+	fromServer      = &net.UDPAddr{IP: ServerIP, Port: dhcpv4.ServerPort}
+)
+
+// must panics if err is not nil. The conversations are fixed, so an error is a
+// bug of this package.
+func must(d *dhcpv4.DHCPv4, err error) *dhcpv4.DHCPv4 {
+	if err != nil {
+		panic("fixtures: " + err.Error())
+	}
+	return d
+}
+
+// newRequest returns a client message with the header fields set, and the
+// options of the modifiers in their order.
+func newRequest(hwaddr net.HardwareAddr, xid uint32, modifiers ...dhcpv4.Modifier) *dhcpv4.DHCPv4 {
+	d := must(dhcpv4.New())
+	d.SetOpcode(dhcpv4.OpcodeBootRequest)
+	must(d, d.SetClientHwAddr(hwaddr))
+	d.SetTransactionID(xid)
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d
+}
+
+// leaseOptions are the options of the offers and acknowledges of the server.
+func leaseOptions(t dhcpv4.MessageType, yourIP net.IP) []dhcpv4.Modifier {
+	return []dhcpv4.Modifier{
+		dhcpv4.WithMessageType(t),
+		dhcpv4.WithYourIP(yourIP),
+		dhcpv4.WithOption(&dhcpv4.OptServerIdentifier{ServerID: ServerIP}),
+		dhcpv4.WithLeaseTime(leaseTime),
+		dhcpv4.WithOption(seconds(dhcpv4.OptionRenewTimeValue, leaseTime/2)),
+		dhcpv4.WithOption(seconds(dhcpv4.OptionRebindingTimeValue, leaseTime*7/8)),
+		dhcpv4.WithNetmask(Netmask),
+		dhcpv4.WithBroadcastAddress(Broadcast),
+		dhcpv4.WithRouter(ServerIP),
+		dhcpv4.WithDNS(DNS...),
+		dhcpv4.WithOption(&dhcpv4.OptDomainName{DomainName: "lan"}),
+	}
+}
+
+// seconds returns an option holding a duration in seconds, such as the
+// renewal and rebinding times.
+func seconds(code dhcpv4.OptionCode, d time.Duration) dhcpv4.Option {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, uint32(d/time.Second))
+	return &dhcpv4.OptionGeneric{OptionCode: code, Data: data}
+}
@@ -0,0 +1,108 @@
+package fixtures
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/pxe"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func messageTypes(c *Conversation) []dhcpv4.MessageType {
+	var types []dhcpv4.MessageType
+	for _, m := range c.Messages() {
+		types = append(types, *m.MessageType())
+	}
+	return types
+}
+
+func TestConversations(t *testing.T) {
+	for _, c := range All() {
+		t.Run(c.Name, func(t *testing.T) {
+			require.NotEmpty(t, c.Description)
+			require.NotEmpty(t, c.Packets)
+			xid := c.Packets[0].Message.TransactionID()
+			for i, p := range c.Packets {
+				m := p.Message
+				require.Equal(t, xid, m.TransactionID(), "packet %d", i)
+				if p.FromServer {
+					require.Equal(t, dhcpv4.OpcodeBootReply, m.Opcode(), "packet %d", i)
+					require.Equal(t, dhcpv4.ServerPort, p.Src.Port, "packet %d", i)
+					require.Equal(t, dhcpv4.ClientPort, p.Dst.Port, "packet %d", i)
+					require.True(t, ServerIP.Equal(m.ServerIdentifier()), "packet %d", i)
+				} else {
+					require.Equal(t, dhcpv4.OpcodeBootRequest, m.Opcode(), "packet %d", i)
+					require.Equal(t, dhcpv4.ClientPort, p.Src.Port, "packet %d", i)
+					require.Equal(t, dhcpv4.ServerPort, p.Dst.Port, "packet %d", i)
+				}
+
+				// the fixtures are what goes on the wire
+				wire, err := dhcpv4.FromBytes(m.ToBytes())
+				require.NoError(t, err, "packet %d", i)
+				require.True(t, m.Equal(wire), "packet %d", i)
+				for _, opt := range wire.Options() {
+					require.NoError(t, dhcpv4.ValidateOption(opt), "packet %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestConversationsAreNew(t *testing.T) {
+	a, b := PXEBoot(), PXEBoot()
+	a.Packets[0].Message.SetTransactionID(1)
+	require.NotEqual(t, uint32(1), b.Packets[0].Message.TransactionID())
+}
+
+func TestPXEBoot(t *testing.T) {
+	c := PXEBoot()
+	require.Equal(t, []dhcpv4.MessageType{
+		dhcpv4.MessageTypeDiscover,
+		dhcpv4.MessageTypeOffer,
+		dhcpv4.MessageTypeRequest,
+		dhcpv4.MessageTypeAck,
+	}, messageTypes(c))
+	for _, m := range c.Requests() {
+		arch, ok := pxe.RequestArch(m)
+		require.True(t, ok)
+		require.Equal(t, iana.EFI_X86_64, arch)
+		require.True(t, m.IsBroadcast())
+	}
+	ack := c.Packets[3].Message
+	require.Equal(t, PXEBootFile, ack.BootFileNameToString())
+	require.True(t, ServerIP.Equal(ack.ServerIPAddr()))
+	require.True(t, PXEClientIP.Equal(ack.YourIPAddr()))
+}
+
+func TestAndroidJoin(t *testing.T) {
+	c := AndroidJoin()
+	require.Equal(t, []dhcpv4.MessageType{
+		dhcpv4.MessageTypeDiscover,
+		dhcpv4.MessageTypeOffer,
+		dhcpv4.MessageTypeRequest,
+		dhcpv4.MessageTypeAck,
+	}, messageTypes(c))
+	request := c.Packets[2].Message
+	require.False(t, request.IsBroadcast())
+	require.Equal(t, AndroidHostName, request.HostName())
+	require.True(t, AndroidIP.Equal(request.RequestedIPAddress()))
+	ack := c.Packets[3].Message
+	require.Equal(t, uint16(1500), ack.InterfaceMTU())
+	require.True(t, AndroidIP.Equal(c.Packets[3].Dst.IP))
+}
+
+func TestWindowsRenew(t *testing.T) {
+	c := WindowsRenew()
+	require.Equal(t, []dhcpv4.MessageType{
+		dhcpv4.MessageTypeRequest,
+		dhcpv4.MessageTypeAck,
+	}, messageTypes(c))
+	request := c.Packets[0].Message
+	require.True(t, WindowsIP.Equal(request.ClientIPAddr()))
+	require.Nil(t, request.RequestedIPAddress())
+	require.Nil(t, request.ServerIdentifier())
+	require.True(t, request.IsOptionRequested(dhcpv4.OptionClasslessStaticRouteOption))
+	require.Equal(t, net.IPv4(192, 168, 1, 255).To4(), c.Packets[1].Message.EffectiveBroadcastAddress())
+}
@@ -0,0 +1,110 @@
+package fixtures
+
+import (
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/pxe"
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// PXE client of the PXEBoot conversation.
+var (
+	PXEClientHwAddr = net.HardwareAddr{0x52, 0x54, 0x00, 0x12, 0x34, 0x56}
+	PXEClientUUID   = [16]byte{
+		0x4c, 0x4c, 0x45, 0x44, 0x00, 0x4a, 0x34, 0x10,
+		0x80, 0x38, 0xb5, 0xc0, 0x4f, 0x48, 0x35, 0x32,
+	}
+	PXEClientIP = net.IP{192, 168, 1, 100}
+)
+
+// PXEBootFile is the boot file the server gives to the client of the PXEBoot
+// conversation.
+const PXEBootFile = "ipxe.efi"
+
+// pxeClientOptions are the options that the UEFI PXE firmware sends in both its
+// discover and its request.
+func pxeClientOptions() []dhcpv4.Modifier {
+	return []dhcpv4.Modifier{
+		dhcpv4.WithMaximumMessageSize(1472),
+		dhcpv4.WithRequestedOptions(
+			dhcpv4.OptionSubnetMask,
+			dhcpv4.OptionTimeOffset,
+			dhcpv4.OptionRouter,
+			dhcpv4.OptionTimeServer,
+			dhcpv4.OptionNameServer,
+			dhcpv4.OptionDomainNameServer,
+			dhcpv4.OptionHostName,
+			dhcpv4.OptionBootFileSize,
+			dhcpv4.OptionDomainName,
+			dhcpv4.OptionRootPath,
+			dhcpv4.OptionExtensionsPath,
+			dhcpv4.OptionMaximumDatagramAssemblySize,
+			dhcpv4.OptionDefaultIPTTL,
+			dhcpv4.OptionBroadcastAddress,
+			dhcpv4.OptionNetworkInformationServiceDomain,
+			dhcpv4.OptionNetworkInformationServers,
+			dhcpv4.OptionNTPServers,
+			dhcpv4.OptionVendorSpecificInformation,
+			dhcpv4.OptionRequestedIPAddress,
+			dhcpv4.OptionIPAddressLeaseTime,
+			dhcpv4.OptionServerIdentifier,
+			dhcpv4.OptionRenewTimeValue,
+			dhcpv4.OptionRebindingTimeValue,
+			dhcpv4.OptionClassIdentifier,
+			dhcpv4.OptionTFTPServerName,
+			dhcpv4.OptionBootfileName,
+			dhcpv4.OptionClientMachineIdentifier,
+			dhcpv4.OptionTFTPServerIPAddress,
+			dhcpv4.OptionCallServerIPAddress,
+			dhcpv4.OptionDiscriminationString,
+			dhcpv4.OptionRemoteStatisticsServerIPAddress,
+			dhcpv4.Option8021PVLANID,
+			dhcpv4.Option8021QL2Priority,
+			dhcpv4.OptionDiffservCodePoint,
+			dhcpv4.OptionHTTPProxyForPhoneSpecificApplications,
+		),
+		dhcpv4.WithMachineUUID(PXEClientUUID),
+		dhcpv4.WithClientNDI(dhcpv4.NDITypeUNDI, 3, 16),
+		dhcpv4.WithClientArch(iana.EFI_X86_64),
+		dhcpv4.WithVendorClass("PXEClient:Arch:00009:UNDI:003016"),
+	}
+}
+
+// PXEBoot returns the conversation of a UEFI x86-64 machine booting from the
+// network. Its firmware broadcasts its messages, and identifies itself with
+// the client system architecture, network interface and machine identifier
+// options, and a PXEClient class identifier. The server points it to the
+// PXEBootFile boot file on its TFTP server, both in the header fields and in
+// the options.
+func PXEBoot() *Conversation {
+	const xid = 0x9a0b4c1d
+	discover := newRequest(PXEClientHwAddr, xid, append([]dhcpv4.Modifier{
+		dhcpv4.WithMessageType(dhcpv4.MessageTypeDiscover),
+	}, pxeClientOptions()...)...)
+	discover.SetBroadcast()
+
+	offer := must(pxe.NewBootReply(discover, iana.EFI_X86_64, ServerIP, PXEBootFile,
+		leaseOptions(dhcpv4.MessageTypeOffer, PXEClientIP)...))
+
+	request := newRequest(PXEClientHwAddr, xid, append([]dhcpv4.Modifier{
+		dhcpv4.WithMessageType(dhcpv4.MessageTypeRequest),
+		dhcpv4.WithOption(&dhcpv4.OptRequestedIPAddress{RequestedAddr: PXEClientIP}),
+		dhcpv4.WithOption(&dhcpv4.OptServerIdentifier{ServerID: ServerIP}),
+	}, pxeClientOptions()...)...)
+	request.SetBroadcast()
+
+	ack := must(pxe.NewBootReply(request, iana.EFI_X86_64, ServerIP, PXEBootFile,
+		leaseOptions(dhcpv4.MessageTypeAck, PXEClientIP)...))
+
+	return &Conversation{
+		Name:        "PXE boot",
+		Description: "a UEFI x86-64 PXE client gets an address and a boot file",
+		Packets: []Packet{
+			{Src: clientBroadcast, Dst: toServers, Message: discover},
+			{FromServer: true, Src: fromServer, Dst: toClients, Message: offer},
+			{Src: clientBroadcast, Dst: toServers, Message: request},
+			{FromServer: true, Src: fromServer, Dst: toClients, Message: ack},
+		},
+	}
+}
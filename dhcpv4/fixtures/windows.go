@@ -0,0 +1,67 @@
+package fixtures
+
+import (
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// Windows machine of the WindowsRenew conversation.
+var (
+	WindowsHwAddr   = net.HardwareAddr{0x00, 0x15, 0x5d, 0x01, 0x6f, 0x2a}
+	WindowsIP       = net.IP{192, 168, 1, 102}
+	WindowsHostName = "DESKTOP-4F2K9QX"
+)
+
+// WindowsRenew returns the conversation of a Windows machine renewing its
+// lease at T1. As RFC 2131 mandates in the RENEWING state, the request is
+// unicast to the server that granted the lease, carries the leased address in
+// ciaddr, and has neither the requested IP address nor the server identifier
+// options. Windows sends its FQDN and the MSFT 5.0 class identifier, and
+// requests the classless static routes under both their standard (121) and
+// their Microsoft (249) codes.
+func WindowsRenew() *Conversation {
+	const xid = 0x7b1c05e9
+	request := newRequest(WindowsHwAddr, xid,
+		dhcpv4.WithMessageType(dhcpv4.MessageTypeRequest),
+		dhcpv4.WithClientIdentifier(dhcpv4.NewClientIdentifierFromHwAddr(WindowsHwAddr)),
+		dhcpv4.WithOption(&dhcpv4.OptHostName{HostName: WindowsHostName}),
+		// flags, two deprecated RCODEs and the name in ASCII, see RFC 4702
+		dhcpv4.WithOption(&dhcpv4.OptionGeneric{
+			OptionCode: dhcpv4.OptionFQDN,
+			Data:       append([]byte{0, 0, 0}, WindowsHostName...),
+		}),
+		dhcpv4.WithVendorClass("MSFT 5.0"),
+		dhcpv4.WithRequestedOptions(
+			dhcpv4.OptionSubnetMask,
+			dhcpv4.OptionRouter,
+			dhcpv4.OptionDomainNameServer,
+			dhcpv4.OptionDomainName,
+			dhcpv4.OptionPerformRouterDiscovery,
+			dhcpv4.OptionStaticRoutingTable,
+			dhcpv4.OptionVendorSpecificInformation,
+			dhcpv4.OptionNetBIOSOverTCPIPNameServer,
+			dhcpv4.OptionNetBIOSOverTCPIPNodeType,
+			dhcpv4.OptionNetBIOSOverTCPIPScope,
+			dhcpv4.OptionDNSDomainSearchList,
+			dhcpv4.OptionClasslessStaticRouteOption,
+			dhcpv4.OptionCode(249),
+			dhcpv4.OptionCode(252),
+		),
+	)
+	request.SetClientIPAddr(WindowsIP)
+
+	ack := must(dhcpv4.NewReplyFromRequest(request,
+		leaseOptions(dhcpv4.MessageTypeAck, WindowsIP)...))
+	ack.SetClientIPAddr(WindowsIP)
+
+	client := &net.UDPAddr{IP: WindowsIP, Port: dhcpv4.ClientPort}
+	return &Conversation{
+		Name:        "Windows renew",
+		Description: "a Windows machine renews its lease",
+		Packets: []Packet{
+			{Src: client, Dst: &net.UDPAddr{IP: ServerIP, Port: dhcpv4.ServerPort}, Message: request},
+			{FromServer: true, Src: fromServer, Dst: client, Message: ack},
+		},
+	}
+}
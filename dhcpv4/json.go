@@ -0,0 +1,239 @@
+package dhcpv4
+
+// This module implements the JSON and YAML representations of packets and
+// options, meant to be logged or exported by tools, and read back.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// optionJSON is the representation of an option. Name and Value are the two
+// halves of what String returns, for humans, while Data is the hexadecimal
+// payload of the option, which is what is decoded.
+type optionJSON struct {
+	Code  OptionCode `json:"code" yaml:"code"`
+	Name  string     `json:"name" yaml:"name"`
+	Value string     `json:"value,omitempty" yaml:"value,omitempty"`
+	Data  string     `json:"data" yaml:"data"`
+}
+
+func newOptionJSON(o Option) optionJSON {
+	oj := optionJSON{Code: o.Code(), Name: o.Code().String()}
+	if oj.Code == OptionPad || oj.Code == OptionEnd {
+		return oj
+	}
+	s := o.String()
+	if i := strings.Index(s, " -> "); i >= 0 {
+		oj.Name, oj.Value = s[:i], s[i+len(" -> "):]
+	} else if s != oj.Name {
+		oj.Value = s
+	}
+	if b := o.ToBytes(); len(b) > 2 {
+		oj.Data = hex.EncodeToString(b[2:])
+	}
+	return oj
+}
+
+// raw returns the code and the payload of the option.
+func (oj optionJSON) raw() (rawOption, error) {
+	data, err := hex.DecodeString(oj.Data)
+	if err != nil {
+		return rawOption{}, fmt.Errorf("invalid data of option %v: %v", oj.Code, err)
+	}
+	return rawOption{code: oj.Code, data: data}, nil
+}
+
+// option parses the payload into the type of option of its code, like
+// FromBytes would.
+func (oj optionJSON) option() (Option, error) {
+	raw, err := oj.raw()
+	if err != nil {
+		return nil, err
+	}
+	if raw.code == OptionPad || raw.code == OptionEnd {
+		return &OptionGeneric{OptionCode: raw.code}, nil
+	}
+	return decodeOption(raw)
+}
+
+// marshalOptionJSON is the MarshalJSON method of the options.
+func marshalOptionJSON(o Option) ([]byte, error) {
+	return json.Marshal(newOptionJSON(o))
+}
+
+// unmarshalOptionJSON is the UnmarshalJSON method of the options. The option
+// is parsed from its data, and must be of the type of o.
+func unmarshalOptionJSON(data []byte, o Option) error {
+	var oj optionJSON
+	if err := json.Unmarshal(data, &oj); err != nil {
+		return err
+	}
+	opt, err := oj.option()
+	if err != nil {
+		return err
+	}
+	dst, src := reflect.ValueOf(o), reflect.ValueOf(opt)
+	if dst.Type() != src.Type() {
+		return fmt.Errorf("option %v is decoded as %T, not %T", oj.Code, opt, o)
+	}
+	dst.Elem().Set(src.Elem())
+	return nil
+}
+
+// packetJSON is the representation of a packet. The field names are the ones
+// of Summary.
+type packetJSON struct {
+	Opcode         OpcodeType      `json:"opcode" yaml:"opcode"`
+	HwType         iana.HwTypeType `json:"hwtype" yaml:"hwtype"`
+	HwAddrLen      uint8           `json:"hwaddrlen" yaml:"hwaddrlen"`
+	HopCount       uint8           `json:"hopcount" yaml:"hopcount"`
+	TransactionID  string          `json:"transactionid" yaml:"transactionid"`
+	NumSeconds     uint16          `json:"numseconds" yaml:"numseconds"`
+	Flags          uint16          `json:"flags" yaml:"flags"`
+	ClientIPAddr   string          `json:"clientipaddr" yaml:"clientipaddr"`
+	YourIPAddr     string          `json:"youripaddr" yaml:"youripaddr"`
+	ServerIPAddr   string          `json:"serveripaddr" yaml:"serveripaddr"`
+	GatewayIPAddr  string          `json:"gatewayipaddr" yaml:"gatewayipaddr"`
+	ClientHwAddr   string          `json:"clienthwaddr" yaml:"clienthwaddr"`
+	ServerHostName string          `json:"serverhostname,omitempty" yaml:"serverhostname,omitempty"`
+	BootFileName   string          `json:"bootfilename,omitempty" yaml:"bootfilename,omitempty"`
+	BOOTP          bool            `json:"bootp,omitempty" yaml:"bootp,omitempty"`
+	Options        []optionJSON    `json:"options" yaml:"options"`
+}
+
+func (d *DHCPv4) toJSON() *packetJSON {
+	pj := packetJSON{
+		Opcode:         d.opcode,
+		HwType:         d.hwType,
+		HwAddrLen:      d.hwAddrLen,
+		HopCount:       d.hopCount,
+		TransactionID:  fmt.Sprintf("0x%08x", d.transactionID),
+		NumSeconds:     d.numSeconds,
+		Flags:          d.flags,
+		ClientIPAddr:   ipv4String(d.clientIPAddr),
+		YourIPAddr:     ipv4String(d.yourIPAddr),
+		ServerIPAddr:   ipv4String(d.serverIPAddr),
+		GatewayIPAddr:  ipv4String(d.gatewayIPAddr),
+		ClientHwAddr:   d.ClientHwAddr().String(),
+		ServerHostName: d.ServerHostName(),
+		BootFileName:   d.BootFileName(),
+		BOOTP:          d.bootp,
+		Options:        make([]optionJSON, 0, len(d.options)),
+	}
+	for _, opt := range d.options {
+		pj.Options = append(pj.Options, newOptionJSON(opt))
+	}
+	return &pj
+}
+
+func (pj *packetJSON) packet() (*DHCPv4, error) {
+	xid, err := strconv.ParseUint(pj.TransactionID, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction ID %q", pj.TransactionID)
+	}
+	d := DHCPv4{
+		opcode:        pj.Opcode,
+		hwType:        pj.HwType,
+		hopCount:      pj.HopCount,
+		transactionID: uint32(xid),
+		numSeconds:    pj.NumSeconds,
+		flags:         pj.Flags,
+		bootp:         pj.BOOTP,
+		options:       make([]Option, 0, len(pj.Options)),
+	}
+	for _, f := range []struct {
+		ip   *net.IP
+		addr string
+	}{
+		{&d.clientIPAddr, pj.ClientIPAddr},
+		{&d.yourIPAddr, pj.YourIPAddr},
+		{&d.serverIPAddr, pj.ServerIPAddr},
+		{&d.gatewayIPAddr, pj.GatewayIPAddr},
+	} {
+		if *f.ip = net.ParseIP(f.addr).To4(); *f.ip == nil {
+			return nil, fmt.Errorf("invalid IPv4 address %q", f.addr)
+		}
+	}
+	hwaddr, err := hex.DecodeString(strings.Replace(pj.ClientHwAddr, ":", "", -1))
+	if err != nil || len(hwaddr) > len(d.clientHwAddr) {
+		return nil, fmt.Errorf("invalid client hardware address %q", pj.ClientHwAddr)
+	}
+	d.SetClientHwAddrRaw(hwaddr)
+	d.hwAddrLen = pj.HwAddrLen
+	if len(pj.ServerHostName) > len(d.serverHostName) {
+		return nil, fmt.Errorf("server host name %q is longer than %d bytes", pj.ServerHostName, len(d.serverHostName))
+	}
+	d.SetServerHostNameRaw([]byte(pj.ServerHostName))
+	if len(pj.BootFileName) > len(d.bootFileName) {
+		return nil, fmt.Errorf("boot file name %q is longer than %d bytes", pj.BootFileName, len(d.bootFileName))
+	}
+	d.SetBootFileNameRaw([]byte(pj.BootFileName))
+	for _, oj := range pj.Options {
+		opt, err := oj.option()
+		if err != nil {
+			return nil, err
+		}
+		d.options = append(d.options, opt)
+	}
+	return &d, nil
+}
+
+// ipv4String returns the dotted representation of an IPv4 address, which is
+// 0.0.0.0 if it is not set.
+func ipv4String(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.String()
+	}
+	return net.IPv4zero.String()
+}
+
+// MarshalJSON implements json.Marshaler. The header fields are named as in
+// Summary, and each option has its code, its name, its value as displayed by
+// String, and its data in hexadecimal.
+func (d *DHCPv4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.toJSON())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The options are parsed from
+// their data, the names and values are only informative.
+func (d *DHCPv4) UnmarshalJSON(data []byte) error {
+	var pj packetJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return err
+	}
+	nd, err := pj.packet()
+	if err != nil {
+		return err
+	}
+	*d = *nd
+	return nil
+}
+
+// MarshalYAML implements the Marshaler interface of the YAML packages, with
+// the fields of MarshalJSON.
+func (d *DHCPv4) MarshalYAML() (interface{}, error) {
+	return d.toJSON(), nil
+}
+
+// UnmarshalYAML implements the Unmarshaler interface of the YAML packages,
+// reading the fields of MarshalYAML.
+func (d *DHCPv4) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var pj packetJSON
+	if err := unmarshal(&pj); err != nil {
+		return err
+	}
+	nd, err := pj.packet()
+	if err != nil {
+		return err
+	}
+	*d = *nd
+	return nil
+}
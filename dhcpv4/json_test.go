@@ -0,0 +1,149 @@
+package dhcpv4
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketJSON(t *testing.T) {
+	ack := leaseTestACK(t)
+	ack.SetTransactionID(0xaabbccdd)
+	ack.SetBroadcast()
+	require.NoError(t, ack.SetServerHostName("server"))
+	ack.AddOption(&OptionGeneric{OptionCode: OptionCode(224), Data: []byte{1, 2}})
+
+	data, err := json.Marshal(ack)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"opcode": 2,
+		"hwtype": 1,
+		"hwaddrlen": 6,
+		"hopcount": 0,
+		"transactionid": "0xaabbccdd",
+		"numseconds": 0,
+		"flags": 32768,
+		"clientipaddr": "0.0.0.0",
+		"youripaddr": "192.168.0.10",
+		"serveripaddr": "0.0.0.0",
+		"gatewayipaddr": "0.0.0.0",
+		"clienthwaddr": "00:11:22:33:44:55",
+		"serverhostname": "server",
+		"options": [
+			{"code": 53, "name": "DHCP Message Type", "value": "ACK", "data": "05"},
+			{"code": 54, "name": "Server Identifier", "value": "192.168.0.1", "data": "c0a80001"},
+			{"code": 51, "name": "IP Addresses Lease Time", "value": "3600", "data": "00000e10"},
+			{"code": 1, "name": "Subnet Mask", "value": "ffffff00", "data": "ffffff00"},
+			{"code": 3, "name": "Routers", "value": "192.168.0.254", "data": "c0a800fe"},
+			{"code": 6, "name": "Domain Name Servers", "value": "8.8.8.8, 8.8.4.4", "data": "0808080808080404"},
+			{"code": 224, "name": "Unknown", "value": "[1 2]", "data": "0102"},
+			{"code": 255, "name": "End", "data": ""}
+		]
+	}`, string(data))
+
+	var decoded DHCPv4
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, ack.ToBytes(), decoded.ToBytes())
+	require.Equal(t, ack.Summary(), decoded.Summary())
+}
+
+func TestPacketJSONBOOTP(t *testing.T) {
+	req, err := NewBOOTPRequest(net.HardwareAddr{0, 0x11, 0x22, 0x33, 0x44, 0x55})
+	require.NoError(t, err)
+	require.NoError(t, req.SetBootFileName("pxelinux.0"))
+
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	var decoded DHCPv4
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.True(t, decoded.bootp)
+	require.Equal(t, "pxelinux.0", decoded.BootFileName())
+	require.Equal(t, req.ToBytes(), decoded.ToBytes())
+}
+
+func TestPacketYAML(t *testing.T) {
+	ack := leaseTestACK(t)
+	v, err := ack.MarshalYAML()
+	require.NoError(t, err)
+
+	// a YAML decoder fills the value passed to the unmarshal function from
+	// the document, which is what a JSON round trip of v does here
+	var decoded DHCPv4
+	require.NoError(t, decoded.UnmarshalYAML(func(out interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, out)
+	}))
+	require.Equal(t, ack.ToBytes(), decoded.ToBytes())
+}
+
+func TestPacketUnmarshalJSONErrors(t *testing.T) {
+	valid := map[string]interface{}{
+		"transactionid": "0x1",
+		"clientipaddr":  "0.0.0.0",
+		"youripaddr":    "0.0.0.0",
+		"serveripaddr":  "0.0.0.0",
+		"gatewayipaddr": "0.0.0.0",
+		"clienthwaddr":  "00:11:22:33:44:55",
+	}
+	for _, tt := range []struct {
+		field string
+		value interface{}
+	}{
+		{"transactionid", "0x100000000"},
+		{"youripaddr", "::1"},
+		{"clienthwaddr", "zz"},
+		{"clienthwaddr", "00:01:02:03:04:05:06:07:08:09:0a:0b:0c:0d:0e:0f:10"},
+		{"options", []interface{}{map[string]interface{}{"code": 1, "data": "ffff"}}},
+		{"options", []interface{}{map[string]interface{}{"code": 1, "data": "not hex"}}},
+	} {
+		fields := make(map[string]interface{})
+		for k, v := range valid {
+			fields[k] = v
+		}
+		fields[tt.field] = tt.value
+		data, err := json.Marshal(fields)
+		require.NoError(t, err)
+		var d DHCPv4
+		require.Error(t, json.Unmarshal(data, &d), "%s: %v", tt.field, tt.value)
+	}
+
+	data, err := json.Marshal(valid)
+	require.NoError(t, err)
+	var d DHCPv4
+	require.NoError(t, json.Unmarshal(data, &d))
+	require.Equal(t, uint32(1), d.TransactionID())
+}
+
+func TestOptionJSON(t *testing.T) {
+	mask := &OptSubnetMask{SubnetMask: net.IPMask{255, 255, 255, 0}}
+	data, err := json.Marshal(mask)
+	require.NoError(t, err)
+	var decodedMask OptSubnetMask
+	require.NoError(t, json.Unmarshal(data, &decodedMask))
+	require.Equal(t, *mask, decodedMask)
+
+	// the type of the option must match its code
+	var router OptRouter
+	require.Error(t, json.Unmarshal(data, &router))
+
+	// a generic option keeps the data, whatever the code
+	var generic OptionGeneric
+	require.NoError(t, json.Unmarshal(data, &generic))
+	require.Equal(t, OptionGeneric{OptionCode: OptionSubnetMask, Data: []byte{255, 255, 255, 0}}, generic)
+
+	route := &OptClasslessStaticRoute{
+		Microsoft: true,
+		Routes:    []Route{{Dest: &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}, Router: net.IP{192, 168, 0, 1}}},
+	}
+	data, err = json.Marshal(route)
+	require.NoError(t, err)
+	var decodedRoute OptClasslessStaticRoute
+	require.NoError(t, json.Unmarshal(data, &decodedRoute))
+	require.Equal(t, route.ToBytes(), decodedRoute.ToBytes())
+	require.True(t, decodedRoute.Microsoft)
+}
@@ -0,0 +1,265 @@
+package dhcpv4
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// Lease describes the configuration obtained by a client from a DHCPv4 server.
+// It can be persisted as JSON, so that a client can resume it after a restart
+// with a REQUEST in INIT-REBOOT state.
+type Lease struct {
+	// ClientHwAddr is the hardware address the lease was obtained for.
+	ClientHwAddr net.HardwareAddr
+	// IP is the assigned address.
+	IP         net.IP
+	SubnetMask net.IPMask
+	Routers    []net.IP
+	DNS        []net.IP
+	// DomainSearch is the DNS search list, from the domain search option or,
+	// failing that, the domain name option.
+	DomainSearch []string
+	// ServerID is the address of the server that granted the lease.
+	ServerID net.IP
+	// LeaseTime, RenewalTime (T1) and RebindingTime (T2) are relative to
+	// Acquired. They are negative for an infinite lease.
+	LeaseTime     time.Duration
+	RenewalTime   time.Duration
+	RebindingTime time.Duration
+	// Acquired is the time the lease was obtained or last extended.
+	Acquired time.Time
+}
+
+// NewLeaseFromACK builds a Lease from the acknowledge of a server, considering
+// it acquired now.
+func NewLeaseFromACK(ack *DHCPv4) (*Lease, error) {
+	if ack.MessageType() == nil || *ack.MessageType() != MessageTypeAck {
+		return nil, errors.New("not a DHCPACK")
+	}
+	lease, t1, t2, err := leaseTimes(ack)
+	if err != nil {
+		return nil, err
+	}
+	serverID := ack.ServerIdentifier()
+	if serverID == nil {
+		return nil, ErrNoServerIdentifier
+	}
+	l := Lease{
+		ClientHwAddr:  ack.ClientHwAddr(),
+		IP:            append(net.IP(nil), ack.YourIPAddr().To4()...),
+		ServerID:      append(net.IP(nil), serverID.To4()...),
+		LeaseTime:     lease,
+		RenewalTime:   t1,
+		RebindingTime: t2,
+		Acquired:      time.Now(),
+	}
+	if mask := ack.SubnetMask(); mask != nil {
+		l.SubnetMask = append(net.IPMask(nil), mask...)
+	}
+	if routers := defaultRouters(ack); routers != nil {
+		l.Routers = copyIPs(routers)
+	}
+	if dns := ack.DNS(); dns != nil {
+		l.DNS = copyIPs(dns)
+	}
+	if search := ack.DomainSearch(); len(search) > 0 {
+		l.DomainSearch = append([]string(nil), search...)
+	} else if domain := ack.DomainName(); domain != "" {
+		l.DomainSearch = []string{domain}
+	}
+	return &l, nil
+}
+
+// NewRequestForInitReboot builds a REQUEST in INIT-REBOOT state confirming a
+// lease, e.g. one loaded after a restart. As per RFC 2131 section 4.3.2 it is
+// to be broadcast, the leased address goes in the Requested IP Address option,
+// ciaddr is left empty and there is no Server Identifier, so that any server
+// of the network can tell whether the lease is still valid there.
+func NewRequestForInitReboot(lease *Lease, modifiers ...Modifier) (*DHCPv4, error) {
+	ip := lease.IP.To4()
+	if ip == nil {
+		return nil, errors.New("lease without IPv4 address")
+	}
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	d.SetOpcode(OpcodeBootRequest)
+	d.SetHwType(iana.HwTypeEthernet)
+	if err := d.SetClientHwAddr(lease.ClientHwAddr); err != nil {
+		return nil, err
+	}
+	d.SetBroadcast()
+	d.AddOption(&OptMessageType{MessageType: MessageTypeRequest})
+	d.AddOption(&OptRequestedIPAddress{RequestedAddr: ip})
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
+// defaultRouters returns the routers of the default routes given by d. As RFC
+// 3442 mandates, they come from the classless static route option, or its
+// Microsoft variant, if it is present, and from the router option otherwise.
+func defaultRouters(d *DHCPv4) []net.IP {
+	if d.classlessStaticRouteOption() == nil {
+		return d.Router()
+	}
+	routes, err := d.Routes()
+	if err != nil {
+		return nil
+	}
+	var routers []net.IP
+	for _, r := range routes {
+		if routeWidth(r.Dest) == 0 {
+			routers = append(routers, r.Router)
+		}
+	}
+	return routers
+}
+
+func copyIPs(ips []net.IP) []net.IP {
+	ret := make([]net.IP, 0, len(ips))
+	for _, ip := range ips {
+		ret = append(ret, append(net.IP(nil), ip.To4()...))
+	}
+	return ret
+}
+
+// Infinite returns true if the lease never expires.
+func (l *Lease) Infinite() bool {
+	return l.LeaseTime < 0
+}
+
+// Expires returns the time the lease expires at. It returns the zero time for
+// infinite leases.
+func (l *Lease) Expires() time.Time {
+	if l.Infinite() {
+		return time.Time{}
+	}
+	return l.Acquired.Add(l.LeaseTime)
+}
+
+// Expired returns true if the lease is expired at the given time.
+func (l *Lease) Expired(now time.Time) bool {
+	return !l.Infinite() && !now.Before(l.Expires())
+}
+
+// leaseJSON is the JSON representation of a Lease. Addresses are stored in
+// their textual form and durations in seconds, so that the persisted lease is
+// readable and stable.
+type leaseJSON struct {
+	ClientHwAddr  string    `json:"client_hw_addr"`
+	IP            string    `json:"ip"`
+	SubnetMask    string    `json:"subnet_mask,omitempty"`
+	Routers       []string  `json:"routers,omitempty"`
+	DNS           []string  `json:"dns,omitempty"`
+	DomainSearch  []string  `json:"domain_search,omitempty"`
+	ServerID      string    `json:"server_id"`
+	LeaseTime     int64     `json:"lease_time"`
+	RenewalTime   int64     `json:"renewal_time"`
+	RebindingTime int64     `json:"rebinding_time"`
+	Acquired      time.Time `json:"acquired"`
+}
+
+func ipsToStrings(ips []net.IP) []string {
+	var ret []string
+	for _, ip := range ips {
+		ret = append(ret, ip.String())
+	}
+	return ret
+}
+
+func stringsToIPs(strs []string) ([]net.IP, error) {
+	var ret []net.IP
+	for _, s := range strs {
+		ip := net.ParseIP(s).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IPv4 address %q", s)
+		}
+		ret = append(ret, ip)
+	}
+	return ret, nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l *Lease) MarshalJSON() ([]byte, error) {
+	lj := leaseJSON{
+		ClientHwAddr:  l.ClientHwAddr.String(),
+		IP:            l.IP.String(),
+		Routers:       ipsToStrings(l.Routers),
+		DNS:           ipsToStrings(l.DNS),
+		DomainSearch:  l.DomainSearch,
+		ServerID:      l.ServerID.String(),
+		LeaseTime:     durationToSeconds(l.LeaseTime),
+		RenewalTime:   durationToSeconds(l.RenewalTime),
+		RebindingTime: durationToSeconds(l.RebindingTime),
+		Acquired:      l.Acquired,
+	}
+	if l.SubnetMask != nil {
+		lj.SubnetMask = net.IP(l.SubnetMask).String()
+	}
+	return json.Marshal(&lj)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *Lease) UnmarshalJSON(data []byte) error {
+	var lj leaseJSON
+	if err := json.Unmarshal(data, &lj); err != nil {
+		return err
+	}
+	var (
+		nl  Lease
+		err error
+	)
+	if nl.ClientHwAddr, err = net.ParseMAC(lj.ClientHwAddr); err != nil {
+		return err
+	}
+	if nl.IP = net.ParseIP(lj.IP).To4(); nl.IP == nil {
+		return fmt.Errorf("invalid IPv4 address %q", lj.IP)
+	}
+	if nl.ServerID = net.ParseIP(lj.ServerID).To4(); nl.ServerID == nil {
+		return fmt.Errorf("invalid server identifier %q", lj.ServerID)
+	}
+	if lj.SubnetMask != "" {
+		mask := net.ParseIP(lj.SubnetMask).To4()
+		if mask == nil {
+			return fmt.Errorf("invalid subnet mask %q", lj.SubnetMask)
+		}
+		nl.SubnetMask = net.IPMask(mask)
+	}
+	if nl.Routers, err = stringsToIPs(lj.Routers); err != nil {
+		return err
+	}
+	if nl.DNS, err = stringsToIPs(lj.DNS); err != nil {
+		return err
+	}
+	nl.DomainSearch = lj.DomainSearch
+	nl.LeaseTime = secondsToDuration(lj.LeaseTime)
+	nl.RenewalTime = secondsToDuration(lj.RenewalTime)
+	nl.RebindingTime = secondsToDuration(lj.RebindingTime)
+	nl.Acquired = lj.Acquired
+	*l = nl
+	return nil
+}
+
+// durationToSeconds converts a lease duration to seconds, keeping -1 for
+// infinite leases.
+func durationToSeconds(d time.Duration) int64 {
+	if d < 0 {
+		return -1
+	}
+	return int64(d / time.Second)
+}
+
+func secondsToDuration(s int64) time.Duration {
+	if s < 0 {
+		return -1
+	}
+	return time.Duration(s) * time.Second
+}
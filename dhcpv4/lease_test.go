@@ -0,0 +1,162 @@
+package dhcpv4
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func leaseTestACK(t *testing.T) *DHCPv4 {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.SetOpcode(OpcodeBootReply)
+	ack.SetClientHwAddr([]byte{0, 0x11, 0x22, 0x33, 0x44, 0x55})
+	ack.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	ack.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+	ack.AddOption(&OptServerIdentifier{ServerID: net.IP{192, 168, 0, 1}})
+	ack.AddOption(&OptIPAddressLeaseTime{LeaseTime: 3600})
+	ack.AddOption(&OptSubnetMask{SubnetMask: net.IPMask{255, 255, 255, 0}})
+	ack.AddOption(&OptRouter{Routers: []net.IP{net.IPv4(192, 168, 0, 254)}})
+	ack.AddOption(&OptDomainNameServer{NameServers: []net.IP{net.IPv4(8, 8, 8, 8), net.IPv4(8, 8, 4, 4)}})
+	return ack
+}
+
+func TestNewRequestForInitReboot(t *testing.T) {
+	lease, err := NewLeaseFromACK(leaseTestACK(t))
+	require.NoError(t, err)
+	request, err := NewRequestForInitReboot(lease, WithClientIdentifier(NewClientIdentifierFromHwAddr(lease.ClientHwAddr)))
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootRequest, request.Opcode())
+	require.Equal(t, MessageTypeRequest, *request.MessageType())
+	require.Equal(t, lease.ClientHwAddr, request.ClientHwAddr())
+	require.True(t, request.ClientIPAddr().IsUnspecified())
+	require.True(t, request.IsBroadcast())
+	require.Nil(t, request.GetOneOption(OptionServerIdentifier))
+	require.NotNil(t, request.GetOneOption(OptionClientIdentifier))
+	opt, ok := request.GetOneOption(OptionRequestedIPAddress).(*OptRequestedIPAddress)
+	require.True(t, ok)
+	require.True(t, opt.RequestedAddr.Equal(lease.IP))
+
+	_, err = NewRequestForInitReboot(&Lease{ClientHwAddr: lease.ClientHwAddr})
+	require.Error(t, err)
+}
+
+func TestNewLeaseFromACK(t *testing.T) {
+	lease, err := NewLeaseFromACK(leaseTestACK(t))
+	require.NoError(t, err)
+	require.Equal(t, net.HardwareAddr{0, 0x11, 0x22, 0x33, 0x44, 0x55}, lease.ClientHwAddr)
+	require.Equal(t, net.IP{192, 168, 0, 10}, lease.IP)
+	require.Equal(t, net.IPMask{255, 255, 255, 0}, lease.SubnetMask)
+	require.Equal(t, []net.IP{net.IP{192, 168, 0, 254}}, lease.Routers)
+	require.Equal(t, []net.IP{net.IP{8, 8, 8, 8}, net.IP{8, 8, 4, 4}}, lease.DNS)
+	require.Equal(t, net.IP{192, 168, 0, 1}, lease.ServerID)
+	require.Equal(t, time.Hour, lease.LeaseTime)
+	require.Equal(t, 30*time.Minute, lease.RenewalTime)
+	require.Equal(t, 52*time.Minute+30*time.Second, lease.RebindingTime)
+	require.False(t, lease.Infinite())
+	require.False(t, lease.Expired(time.Now()))
+	require.True(t, lease.Expired(time.Now().Add(2*time.Hour)))
+}
+
+func TestNewLeaseFromACKClasslessStaticRoute(t *testing.T) {
+	// the router option is ignored in favor of the default routes of the
+	// classless static route option
+	ack := leaseTestACK(t)
+	ack.AddOption(&OptClasslessStaticRoute{Routes: []Route{
+		{Dest: mustParseCIDR(t, "10.0.0.0/8"), Router: net.IP{192, 168, 0, 2}},
+		{Dest: mustParseCIDR(t, "0.0.0.0/0"), Router: net.IP{192, 168, 0, 1}},
+	}})
+	lease, err := NewLeaseFromACK(ack)
+	require.NoError(t, err)
+	require.Equal(t, []net.IP{net.IP{192, 168, 0, 1}}, lease.Routers)
+}
+
+func TestNewLeaseFromACKDomainSearch(t *testing.T) {
+	ack := leaseTestACK(t)
+	ack.AddOption(&OptDomainName{DomainName: "example.com"})
+	lease, err := NewLeaseFromACK(ack)
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com"}, lease.DomainSearch)
+
+	// the domain search option takes precedence over the domain name
+	ack.AddOption(&OptDomainSearch{DomainSearch: []string{"eng.example.com", "example.com"}})
+	lease, err = NewLeaseFromACK(ack)
+	require.NoError(t, err)
+	require.Equal(t, []string{"eng.example.com", "example.com"}, lease.DomainSearch)
+
+	data, err := json.Marshal(lease)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"domain_search":["eng.example.com","example.com"]`)
+	var decoded Lease
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, lease.DomainSearch, decoded.DomainSearch)
+}
+
+func TestNewLeaseFromACKErrors(t *testing.T) {
+	offer, err := New()
+	require.NoError(t, err)
+	offer.AddOption(&OptMessageType{MessageType: MessageTypeOffer})
+	_, err = NewLeaseFromACK(offer)
+	require.Error(t, err, "not an ACK")
+
+	ack, err := New()
+	require.NoError(t, err)
+	ack.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+	_, err = NewLeaseFromACK(ack)
+	require.Error(t, err, "no lease time")
+
+	ack.AddOption(&OptIPAddressLeaseTime{LeaseTime: 3600})
+	_, err = NewLeaseFromACK(ack)
+	require.Error(t, err, "no server identifier")
+}
+
+func TestLeaseInfinite(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+	ack.AddOption(&OptServerIdentifier{ServerID: net.IP{192, 168, 0, 1}})
+	ack.AddOption(&OptIPAddressLeaseTime{LeaseTime: infiniteLeaseTime})
+	lease, err := NewLeaseFromACK(ack)
+	require.NoError(t, err)
+	require.True(t, lease.Infinite())
+	require.True(t, lease.Expires().IsZero())
+	require.False(t, lease.Expired(time.Now().Add(100*365*24*time.Hour)))
+}
+
+func TestLeaseJSON(t *testing.T) {
+	lease, err := NewLeaseFromACK(leaseTestACK(t))
+	require.NoError(t, err)
+	lease.Acquired = time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	data, err := json.Marshal(lease)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"client_hw_addr": "00:11:22:33:44:55",
+		"ip": "192.168.0.10",
+		"subnet_mask": "255.255.255.0",
+		"routers": ["192.168.0.254"],
+		"dns": ["8.8.8.8", "8.8.4.4"],
+		"server_id": "192.168.0.1",
+		"lease_time": 3600,
+		"renewal_time": 1800,
+		"rebinding_time": 3150,
+		"acquired": "2018-07-01T12:00:00Z"
+	}`, string(data))
+
+	var decoded Lease
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, *lease, decoded)
+}
+
+func TestLeaseUnmarshalJSONErrors(t *testing.T) {
+	var lease Lease
+	require.Error(t, json.Unmarshal([]byte(`{"client_hw_addr": "nope"}`), &lease))
+	require.Error(t, json.Unmarshal([]byte(`{"client_hw_addr": "00:11:22:33:44:55", "ip": "::1"}`), &lease))
+	require.Error(t, json.Unmarshal([]byte(`{"client_hw_addr": "00:11:22:33:44:55", "ip": "10.0.0.1"}`), &lease))
+	require.Error(t, json.Unmarshal([]byte(`{"client_hw_addr": "00:11:22:33:44:55", "ip": "10.0.0.1", "server_id": "10.0.0.254", "dns": ["x"]}`), &lease))
+	require.NoError(t, json.Unmarshal([]byte(`{"client_hw_addr": "00:11:22:33:44:55", "ip": "10.0.0.1", "server_id": "10.0.0.254", "lease_time": -1}`), &lease))
+	require.True(t, lease.Infinite())
+}
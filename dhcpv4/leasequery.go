@@ -0,0 +1,115 @@
+package dhcpv4
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// This module implements the DHCPLEASEQUERY of RFC 4388, with which an access
+// concentrator, acting as a relay agent, asks the servers about the lease of a
+// client, e.g. to rebuild its table of the clients after a restart.
+// https://tools.ietf.org/html/rfc4388
+
+// leaseQueryReplies are the message types of the replies to a DHCPLEASEQUERY.
+var leaseQueryReplies = []MessageType{
+	MessageTypeLeaseActive,
+	MessageTypeLeaseUnassigned,
+	MessageTypeLeaseUnknown,
+}
+
+// newLeaseQuery builds a DHCPLEASEQUERY sent by the access concentrator with
+// the address giaddr, with no query yet. It asks for the lease time and the
+// leasequery options.
+func newLeaseQuery(giaddr net.IP) (*DHCPv4, error) {
+	if giaddr.To4() == nil || giaddr.IsUnspecified() {
+		return nil, errors.New("a leasequery needs the IPv4 address of the access concentrator")
+	}
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	d.SetHwType(0)
+	d.SetHwAddrLen(0)
+	d.SetGatewayIPAddr(giaddr)
+	d.AddOption(&OptMessageType{MessageType: MessageTypeLeaseQuery})
+	d.AddOption(&OptParameterRequestList{RequestedOpts: []OptionCode{
+		OptionIPAddressLeaseTime,
+		OptionClientLastTransactionTime,
+		OptionAssociatedIP,
+		OptionRelayAgentInformation,
+	}})
+	return d, nil
+}
+
+// NewLeaseQueryByIP builds a DHCPLEASEQUERY for the lease of the address ip,
+// sent by the access concentrator with the address giaddr, where the servers
+// send their replies.
+func NewLeaseQueryByIP(giaddr, ip net.IP, modifiers ...Modifier) (*DHCPv4, error) {
+	d, err := newLeaseQuery(giaddr)
+	if err != nil {
+		return nil, err
+	}
+	d.SetClientIPAddr(ip)
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
+// NewLeaseQueryByHwAddr builds a DHCPLEASEQUERY for the leases of the client
+// with the Ethernet address hwaddr, sent by the access concentrator with the
+// address giaddr.
+func NewLeaseQueryByHwAddr(giaddr net.IP, hwaddr net.HardwareAddr, modifiers ...Modifier) (*DHCPv4, error) {
+	d, err := newLeaseQuery(giaddr)
+	if err != nil {
+		return nil, err
+	}
+	d.SetHwType(iana.HwTypeEthernet)
+	if err := d.SetClientHwAddr(hwaddr); err != nil {
+		return nil, err
+	}
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
+// NewLeaseQueryByClientID builds a DHCPLEASEQUERY for the leases of the client
+// with the given client identifier, sent by the access concentrator with the
+// address giaddr.
+func NewLeaseQueryByClientID(giaddr net.IP, id *OptClientIdentifier, modifiers ...Modifier) (*DHCPv4, error) {
+	d, err := newLeaseQuery(giaddr)
+	if err != nil {
+		return nil, err
+	}
+	d.AddOption(id)
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
+// LeaseQuery unicasts a DHCPLEASEQUERY to the server at dst, and waits for
+// its reply up to the client's read timeout: a DHCPLEASEACTIVE, a
+// DHCPLEASEUNASSIGNED or a DHCPLEASEUNKNOWN. The servers reply to the giaddr
+// of the query on the server port, as to a relay agent, which is where the
+// reply is waited for, unless the client has a LocalPort.
+func (c *Client) LeaseQuery(dst net.IP, query *DHCPv4) (*DHCPv4, error) {
+	return c.LeaseQueryContext(context.Background(), dst, query)
+}
+
+// LeaseQueryContext works like LeaseQuery, but stops waiting for a reply as
+// soon as the context is cancelled, in which case the context's error is
+// returned.
+func (c *Client) LeaseQueryContext(ctx context.Context, dst net.IP, query *DHCPv4) (*DHCPv4, error) {
+	port := c.LocalPort
+	if port == 0 {
+		port = ServerPort
+	}
+	laddr := net.UDPAddr{IP: query.GatewayIPAddr(), Port: port}
+	raddr := net.UDPAddr{IP: dst, Port: c.serverPort()}
+	return sendReceiveUnicast(ctx, &laddr, &raddr, query, c.ReadTimeout, c.WriteTimeout, leaseQueryReplies...)
+}
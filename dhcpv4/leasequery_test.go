@@ -0,0 +1,42 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLeaseQuery(t *testing.T) {
+	giaddr := net.IPv4(10, 0, 0, 1)
+	byIP, err := NewLeaseQueryByIP(giaddr, net.IPv4(10, 0, 0, 10))
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootRequest, byIP.Opcode())
+	require.Equal(t, MessageTypeLeaseQuery, *byIP.MessageType())
+	require.True(t, byIP.GatewayIPAddr().Equal(giaddr))
+	require.True(t, byIP.ClientIPAddr().Equal(net.IPv4(10, 0, 0, 10)))
+	require.Equal(t, uint8(0), byIP.HwAddrLen())
+	require.True(t, byIP.IsOptionRequested(OptionClientLastTransactionTime))
+
+	hwaddr := net.HardwareAddr{1, 2, 3, 4, 5, 6}
+	byHwAddr, err := NewLeaseQueryByHwAddr(giaddr, hwaddr)
+	require.NoError(t, err)
+	require.Equal(t, hwaddr, byHwAddr.ClientHwAddr())
+	require.Equal(t, iana.HwTypeEthernet, byHwAddr.HwType())
+	require.True(t, byHwAddr.ClientIPAddr().IsUnspecified())
+
+	id := NewClientIdentifierFromHwAddr(hwaddr)
+	byClientID, err := NewLeaseQueryByClientID(giaddr, id)
+	require.NoError(t, err)
+	require.Equal(t, id, byClientID.GetOneOption(OptionClientIdentifier))
+	require.Equal(t, uint8(0), byClientID.HwAddrLen())
+
+	// parses back
+	parsed, err := FromBytes(byHwAddr.ToBytes())
+	require.NoError(t, err)
+	require.True(t, byHwAddr.Equal(parsed))
+
+	_, err = NewLeaseQueryByIP(nil, net.IPv4(10, 0, 0, 10))
+	require.Error(t, err)
+}
@@ -0,0 +1,115 @@
+// +build linux
+
+package dhcpv4
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// linkPollInterval is how often WatchLink checks whether its context is done
+// while waiting for netlink messages.
+const linkPollInterval = time.Second
+
+// WatchLink follows the carrier of the Manager's interface through netlink,
+// calling LinkDown and LinkUp as it changes, until the context is cancelled.
+// The current state of the carrier is reported first.
+func (m *Manager) WatchLink(ctx context.Context) error {
+	iface, err := net.InterfaceByName(m.ifname)
+	if err != nil {
+		return err
+	}
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: unix.RTMGRP_LINK}); err != nil {
+		return err
+	}
+	tv := unix.NsecToTimeval(linkPollInterval.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		return err
+	}
+	// the current state is read after subscribing, so that no change is
+	// missed in between
+	if err := m.pollCarrier(iface.Index); err != nil {
+		return err
+	}
+	buf := make([]byte, 1<<16)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch err {
+		case nil:
+		case unix.EAGAIN, unix.EINTR:
+			continue
+		case unix.ENOBUFS:
+			// some messages were dropped, read the state again
+			if err := m.pollCarrier(iface.Index); err != nil {
+				return err
+			}
+			continue
+		default:
+			return err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		if up, ok := carrierFromMessages(msgs, iface.Index); ok {
+			m.setCarrier(up)
+		}
+	}
+}
+
+// pollCarrier reads the state of the carrier of an interface, and reports it.
+func (m *Manager) pollCarrier(index int) error {
+	data, err := syscall.NetlinkRIB(unix.RTM_GETLINK, unix.AF_UNSPEC)
+	if err != nil {
+		return err
+	}
+	msgs, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return err
+	}
+	if up, ok := carrierFromMessages(msgs, index); ok {
+		m.setCarrier(up)
+	}
+	return nil
+}
+
+func (m *Manager) setCarrier(up bool) {
+	if up {
+		m.LinkUp()
+	} else {
+		m.LinkDown()
+	}
+}
+
+// carrierFromMessages returns the last state of the carrier of an interface
+// found in netlink link messages, and false if there is none.
+func carrierFromMessages(msgs []syscall.NetlinkMessage, index int) (up bool, found bool) {
+	for _, msg := range msgs {
+		if msg.Header.Type != unix.RTM_NEWLINK && msg.Header.Type != unix.RTM_DELLINK {
+			continue
+		}
+		if len(msg.Data) < unix.SizeofIfInfomsg {
+			continue
+		}
+		info := (*unix.IfInfomsg)(unsafe.Pointer(&msg.Data[0]))
+		if int(info.Index) != index {
+			continue
+		}
+		up = msg.Header.Type == unix.RTM_NEWLINK && info.Flags&unix.IFF_LOWER_UP != 0
+		found = true
+	}
+	return up, found
+}
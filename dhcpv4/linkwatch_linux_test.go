@@ -0,0 +1,42 @@
+// +build linux
+
+package dhcpv4
+
+import (
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// linkMessage builds a netlink link message for an interface.
+func linkMessage(typ uint16, index int32, flags uint32) syscall.NetlinkMessage {
+	data := make([]byte, unix.SizeofIfInfomsg)
+	info := (*unix.IfInfomsg)(unsafe.Pointer(&data[0]))
+	info.Index, info.Flags = index, flags
+	return syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: typ}, Data: data}
+}
+
+func TestCarrierFromMessages(t *testing.T) {
+	_, found := carrierFromMessages(nil, 2)
+	require.False(t, found)
+
+	msgs := []syscall.NetlinkMessage{
+		linkMessage(unix.RTM_NEWLINK, 2, unix.IFF_UP|unix.IFF_LOWER_UP),
+		linkMessage(unix.RTM_NEWLINK, 3, unix.IFF_UP),
+	}
+	up, found := carrierFromMessages(msgs, 2)
+	require.True(t, found)
+	require.True(t, up)
+	up, found = carrierFromMessages(msgs, 3)
+	require.True(t, found)
+	require.False(t, up, "no carrier without IFF_LOWER_UP")
+
+	// the last message wins
+	msgs = append(msgs, linkMessage(unix.RTM_DELLINK, 2, unix.IFF_UP|unix.IFF_LOWER_UP))
+	up, found = carrierFromMessages(msgs, 2)
+	require.True(t, found)
+	require.False(t, up)
+}
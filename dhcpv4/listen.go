@@ -0,0 +1,85 @@
+package dhcpv4
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// ReceivedPacket is a message received by Client.Listen, with where and when
+// it was received.
+type ReceivedPacket struct {
+	Message *DHCPv4
+	// Peer is the address and port the message was sent from, e.g. the
+	// ones of a server.
+	Peer *net.UDPAddr
+	// Interface is the name of the interface on which the message was
+	// received.
+	Interface string
+	Received  time.Time
+}
+
+// Listen receives the replies sent to the client port on the interface
+// ifname, through the transport of the client, and returns a channel on which
+// they are delivered, decoded, whichever client they are for. This allows
+// building monitors, e.g. to spot rogue servers, independently of the
+// exchanges. Listen opens its own sockets, even if the client is open, which
+// are closed along with the channel when ctx is done or reading fails.
+func (c *Client) Listen(ctx context.Context, ifname string) (<-chan *ReceivedPacket, error) {
+	sender, conn, err := c.openSockets(ifname)
+	if err != nil {
+		return nil, err
+	}
+	// only the receiving side is needed
+	sender.Close()
+	pc, ok := conn.(net.PacketConn)
+	if !ok {
+		conn.Close()
+		return nil, errors.New("the transport of the client does not tell the source of the packets")
+	}
+	return c.listen(ctx, pc, ifname), nil
+}
+
+// listen delivers the messages read from conn on the returned channel, until
+// ctx is done or reading fails, then closes conn and the channel.
+func (c *Client) listen(ctx context.Context, conn net.PacketConn, ifname string) <-chan *ReceivedPacket {
+	packets := make(chan *ReceivedPacket)
+	done := make(chan struct{})
+	go func() {
+		// unblock the pending read once ctx is done
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	go func() {
+		defer close(packets)
+		defer close(done)
+		defer conn.Close()
+		for {
+			// a new buffer each time, since the messages refer to it
+			buf := make([]byte, MaxUDPReceivedPacketSize)
+			n, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				if ctx.Err() == nil {
+					loggerOrDefault(c.Logger).Printf("Cannot listen on %s: %v", ifname, err)
+				}
+				return
+			}
+			m, err := FromBytes(buf[:n])
+			if err != nil {
+				continue
+			}
+			p := &ReceivedPacket{Message: m, Interface: ifname, Received: time.Now()}
+			p.Peer, _ = peer.(*net.UDPAddr)
+			select {
+			case packets <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return packets
+}
@@ -0,0 +1,36 @@
+package dhcpv4
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientListen(t *testing.T) {
+	server, conn := setUpLoopbackConns(t)
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	packets := NewClient().listen(ctx, server, "lo")
+
+	discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	offer, err := NewReplyFromRequest(discover, WithMessageType(MessageTypeOffer))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("not DHCP"))
+	require.NoError(t, err)
+	_, err = conn.Write(offer.ToBytes())
+	require.NoError(t, err)
+
+	p := <-packets
+	require.Equal(t, offer.TransactionID(), p.Message.TransactionID())
+	require.Equal(t, MessageTypeOffer, *p.Message.MessageType())
+	require.Equal(t, conn.LocalAddr().String(), p.Peer.String())
+	require.Equal(t, "lo", p.Interface)
+	require.False(t, p.Received.IsZero())
+
+	cancel()
+	_, ok := <-packets
+	require.False(t, ok)
+}
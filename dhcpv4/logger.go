@@ -0,0 +1,29 @@
+package dhcpv4
+
+// Logger is the interface through which this package and the ones built on it,
+// e.g. server4, report what cannot be returned as an error, like the messages
+// that are dropped or the exchanges that are retried. *log.Logger satisfies
+// it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// NopLogger is a Logger that discards everything.
+type NopLogger struct{}
+
+// Printf implements Logger.Printf.
+func (NopLogger) Printf(format string, v ...interface{}) {}
+
+// DefaultLogger is used wherever no Logger is set, e.g. by a Client whose
+// Logger field is nil. It discards everything; an application that wants the
+// messages can set it, before using the package, e.g. to
+// log.New(os.Stderr, "", log.LstdFlags).
+var DefaultLogger Logger = NopLogger{}
+
+// loggerOrDefault returns l, or DefaultLogger if l is nil.
+func loggerOrDefault(l Logger) Logger {
+	if l == nil {
+		return DefaultLogger
+	}
+	return l
+}
@@ -0,0 +1,26 @@
+package dhcpv4
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerOrDefault(t *testing.T) {
+	require.Equal(t, DefaultLogger, loggerOrDefault(nil))
+	l := log.New(&bytes.Buffer{}, "", 0)
+	require.Equal(t, Logger(l), loggerOrDefault(l))
+}
+
+func TestValidateOptionsLogs(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(l Logger) { DefaultLogger = l }(DefaultLogger)
+	DefaultLogger = log.New(&buf, "", 0)
+
+	d, err := New()
+	require.NoError(t, err)
+	d.ValidateOptions()
+	require.Contains(t, buf.String(), "Warning: ")
+}
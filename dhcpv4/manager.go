@@ -0,0 +1,781 @@
+package dhcpv4
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+	"github.com/insomniacslk/dhcp/identity"
+)
+
+// ClientState represents the state of a DHCPv4 client, as described in RFC
+// 2131 section 4.4.
+type ClientState int
+
+// Client states handled by the Manager
+const (
+	StateInit ClientState = iota
+	StateSelecting
+	StateBound
+	StateRenewing
+	StateRebinding
+	StateRebooting
+)
+
+func (s ClientState) String() string {
+	if str, ok := ClientStateToString[s]; ok {
+		return str
+	}
+	return "Unknown"
+}
+
+// ClientStateToString maps a ClientState to its mnemonic name.
+var ClientStateToString = map[ClientState]string{
+	StateInit:      "INIT",
+	StateSelecting: "SELECTING",
+	StateBound:     "BOUND",
+	StateRenewing:  "RENEWING",
+	StateRebinding: "REBINDING",
+	StateRebooting: "REBOOTING",
+}
+
+// LeaseEventType represents the kind of change that happened to a lease.
+type LeaseEventType int
+
+// Lease events posted by the Manager
+const (
+	// LeaseBound is posted when a new lease is obtained with a full DORA
+	// exchange.
+	LeaseBound LeaseEventType = iota
+	// LeaseRenewed is posted when the current lease is extended, either by
+	// the original server (RENEWING) or by any server (REBINDING), or
+	// confirmed after the link came back up (REBOOTING).
+	LeaseRenewed
+	// LeaseExpired is posted when the lease could not be extended before
+	// its expiration, or when the server answered with a NAK.
+	LeaseExpired
+)
+
+func (e LeaseEventType) String() string {
+	if s, ok := LeaseEventTypeToString[e]; ok {
+		return s
+	}
+	return "Unknown"
+}
+
+// LeaseEventTypeToString maps a LeaseEventType to its mnemonic name.
+var LeaseEventTypeToString = map[LeaseEventType]string{
+	LeaseBound:   "Bound",
+	LeaseRenewed: "Renewed",
+	LeaseExpired: "Expired",
+}
+
+// LeaseEvent is posted on the Manager's event channel every time the state of
+// the lease changes. Ack is the acknowledge that the lease is based upon, or
+// the last known one if the lease expired.
+type LeaseEvent struct {
+	Type LeaseEventType
+	Ack  *DHCPv4
+}
+
+const (
+	// infiniteLeaseTime is the lease time value that represents infinity,
+	// see RFC 2131 section 3.3.
+	infiniteLeaseTime = 0xffffffff
+
+	// minRetransmitInterval is the minimum interval between two RENEW or
+	// REBIND messages, see RFC 2131 section 4.4.5.
+	minRetransmitInterval = 60 * time.Second
+
+	// initRetryInterval is the time to wait before starting over after a
+	// failed DORA exchange.
+	initRetryInterval = 10 * time.Second
+
+	// declineRetryInterval is the time to wait before starting over after
+	// declining an address in use, see RFC 2131 section 3.1.
+	declineRetryInterval = 10 * time.Second
+)
+
+// Manager is a stateful DHCPv4 client. It obtains a lease on an interface and
+// keeps it alive according to RFC 2131: when T1 expires it unicasts a renewal
+// request to the server that granted the lease, when T2 expires it falls back
+// to broadcasting a rebinding request, and when the lease expires it starts
+// over with a new DORA exchange. While bound, if VerifyForceRenew is set, it
+// also listens for the FORCERENEW messages of the server, see RFC 3203, upon
+// which it moves to the RENEWING state right away. Every change is posted on
+// the Events channel.
+//
+// The lease times are measured on the wall clock, so that they keep running
+// while the system is suspended. When the system resumes, the lease is
+// confirmed, or extended, right away instead of when the timers were due.
+//
+// The Manager can be told about the carrier of the interface with LinkDown and
+// LinkUp, e.g. by WatchLink on Linux. While the link is down, the timers are
+// paused; when it comes back up, the lease is confirmed with a REQUEST in
+// INIT-REBOOT state, since the host may have been moved to another network.
+type Manager struct {
+	// Client is used to run the exchanges with the server. FORCERENEW
+	// messages are received on its LocalPort, if set. The failures of the
+	// exchanges are reported to its Logger.
+	Client *Client
+
+	// VerifyForceRenew is called with the FORCERENEW messages as they
+	// were received, and those for which it returns an error are ignored.
+	// RFC 3203 requires them to be authenticated, which can be checked
+	// e.g. with VerifyDelayedAuthentication. If nil, the FORCERENEW
+	// messages are not listened for.
+	VerifyForceRenew func(data []byte) error
+
+	// Clock is the source of time of the lease timers. If nil,
+	// SystemClock is used.
+	Clock Clock
+
+	// CheckAddress, if not nil, is called with the address of every new
+	// lease before binding it, and of every lease confirmed in INIT-REBOOT
+	// state, to check that no other host uses it, e.g. acd.Check, which
+	// also announces the address with gratuitous ARP when it is free. If it
+	// returns an *acd.ConflictError, the address is declined and the
+	// manager starts over after a while, as RFC 2131 sections 3.1 and 3.2
+	// require. Other errors are logged, and the address is bound anyway.
+	CheckAddress func(ctx context.Context, ifname string, ip net.IP) error
+
+	// Identities, if not nil, provides the identity of the interface, sent
+	// in every message as a RFC 4361 node-specific client identifier, so
+	// that a DHCPv6 client sharing the Store is seen as the same client.
+	// Network, if not nil, returns the network the interface is attached
+	// to, for identity.PolicyPerNetwork. The identity is looked up every
+	// time the manager starts over, and when the link comes back up: if it
+	// changed, the host moved to another network, and the lease is dropped
+	// rather than confirmed with the identity of the previous network.
+	Identities *identity.Store
+	Network    func() string
+
+	ifname   string
+	lock     sync.Mutex
+	state    ClientState
+	ack      *DHCPv4
+	clientID *OptClientIdentifier
+	boundAt  time.Time
+	events   chan LeaseEvent
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// linkDown tells whether the carrier is lost, and linkChanged is
+	// closed, then replaced, each time it changes.
+	linkDown    bool
+	linkChanged chan struct{}
+}
+
+// NewManager creates a new stateful client for the given interface, using a
+// Client with default settings.
+func NewManager(ifname string) *Manager {
+	return &Manager{
+		Client: NewClient(),
+		ifname: ifname,
+		state:  StateInit,
+		events: make(chan LeaseEvent, 16),
+
+		linkChanged: make(chan struct{}),
+	}
+}
+
+// Start starts the state machine in background. It returns an error if the
+// manager is already running.
+func (m *Manager) Start() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.cancel != nil {
+		return errors.New("manager already started")
+	}
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(context.Background())
+	m.done = make(chan struct{})
+	go m.run(ctx)
+	return nil
+}
+
+// Close stops the state machine and waits for it to terminate. The lease is
+// not released.
+func (m *Manager) Close() {
+	m.lock.Lock()
+	cancel, done := m.cancel, m.done
+	m.lock.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	m.lock.Lock()
+	m.cancel = nil
+	m.lock.Unlock()
+}
+
+// Events returns the channel where lease events are posted.
+func (m *Manager) Events() <-chan LeaseEvent {
+	return m.events
+}
+
+// State returns the current state of the client.
+func (m *Manager) State() ClientState {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.state
+}
+
+// Ack returns the acknowledge of the current lease, or nil if no lease has
+// been obtained yet.
+func (m *Manager) Ack() *DHCPv4 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.ack
+}
+
+// Lease returns the current lease, suitable for being persisted, or nil if no
+// lease has been obtained yet.
+func (m *Manager) Lease() *Lease {
+	m.lock.Lock()
+	ack, boundAt := m.ack, m.boundAt
+	m.lock.Unlock()
+	if ack == nil {
+		return nil
+	}
+	lease, err := NewLeaseFromACK(ack)
+	if err != nil {
+		return nil
+	}
+	lease.Acquired = boundAt
+	return lease
+}
+
+// clock returns the Clock of the Manager, or SystemClock if there is none.
+func (m *Manager) clock() Clock {
+	return clockOrDefault(m.Clock)
+}
+
+func (m *Manager) setState(state ClientState) {
+	m.lock.Lock()
+	m.state = state
+	m.lock.Unlock()
+}
+
+// LinkDown tells the Manager that the interface lost its carrier. The timers
+// are paused, and the exchange in progress, if any, is aborted, until LinkUp
+// is called.
+func (m *Manager) LinkDown() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.linkDown {
+		return
+	}
+	m.linkDown = true
+	close(m.linkChanged)
+	m.linkChanged = make(chan struct{})
+}
+
+// LinkUp tells the Manager that the interface got its carrier back. If the
+// client had a lease, it confirms it with a REQUEST in INIT-REBOOT state, RFC
+// 2131 section 3.2. Otherwise it goes on obtaining one.
+func (m *Manager) LinkUp() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if !m.linkDown {
+		return
+	}
+	m.linkDown = false
+	switch m.state {
+	case StateBound, StateRenewing, StateRebinding:
+		m.state = StateRebooting
+	}
+	close(m.linkChanged)
+	m.linkChanged = make(chan struct{})
+}
+
+// link returns whether the carrier is lost, and a channel closed when that
+// changes.
+func (m *Manager) link() (bool, <-chan struct{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.linkDown, m.linkChanged
+}
+
+func (m *Manager) run(ctx context.Context) {
+	defer close(m.done)
+	for {
+		down, changed := m.link()
+		if down {
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		// each step is aborted if the carrier is lost meanwhile
+		stepCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-changed:
+				cancel()
+			case <-stepCtx.Done():
+			}
+		}()
+		switch m.State() {
+		case StateInit, StateSelecting:
+			m.init(stepCtx)
+		case StateBound:
+			m.bound(stepCtx)
+		case StateRenewing, StateRebinding:
+			m.extend(stepCtx)
+		case StateRebooting:
+			m.reboot(stepCtx)
+		}
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// post sends an event to the event channel, giving up if the context is
+// cancelled.
+func (m *Manager) post(ctx context.Context, ev LeaseEvent) bool {
+	select {
+	case m.events <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sleep waits for the given duration, and returns false if the context was
+// cancelled in the meantime.
+func (m *Manager) sleep(ctx context.Context, d time.Duration) bool {
+	t := m.clock().NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// init obtains a new lease with a full DORA exchange.
+func (m *Manager) init(ctx context.Context) bool {
+	clientID, err := m.identify()
+	if err != nil {
+		loggerOrDefault(m.Client.Logger).Printf("Cannot get the identity of %s: %v", m.ifname, err)
+		return m.sleep(ctx, initRetryInterval)
+	}
+	m.lock.Lock()
+	m.clientID = clientID
+	m.lock.Unlock()
+	m.setState(StateSelecting)
+	conversation, err := m.Client.ExchangeContext(ctx, m.ifname, nil, m.modifiers()...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		loggerOrDefault(m.Client.Logger).Printf("Cannot obtain a lease on %s: %v", m.ifname, err)
+		m.setState(StateInit)
+		return m.sleep(ctx, initRetryInterval)
+	}
+	ack := conversation[len(conversation)-1]
+	if _, _, _, err := leaseTimes(ack); err != nil {
+		loggerOrDefault(m.Client.Logger).Printf("Ignoring the lease obtained on %s: %v", m.ifname, err)
+		m.setState(StateInit)
+		return m.sleep(ctx, initRetryInterval)
+	}
+	if !m.checkAddress(ctx, ack) {
+		if ctx.Err() != nil {
+			return false
+		}
+		m.setState(StateInit)
+		return m.sleep(ctx, declineRetryInterval)
+	}
+	m.bind(ack)
+	return m.post(ctx, LeaseEvent{Type: LeaseBound, Ack: ack})
+}
+
+// checkAddress checks the address of a new lease with CheckAddress, if set,
+// and declines it if it is in use. It returns true if the address can be
+// bound.
+func (m *Manager) checkAddress(ctx context.Context, ack *DHCPv4) bool {
+	if m.CheckAddress == nil {
+		return true
+	}
+	err := m.CheckAddress(ctx, m.ifname, ack.YourIPAddr())
+	switch {
+	case err == nil:
+		return true
+	case ctx.Err() != nil:
+		return false
+	case !acd.IsConflict(err):
+		loggerOrDefault(m.Client.Logger).Printf("Cannot check the address %v on %s, binding it anyway: %v", ack.YourIPAddr(), m.ifname, err)
+		return true
+	}
+	loggerOrDefault(m.Client.Logger).Printf("Declining the address %v on %s: %v", ack.YourIPAddr(), m.ifname, err)
+	if err := m.Client.Decline(m.ifname, ack, m.modifiers()...); err != nil {
+		loggerOrDefault(m.Client.Logger).Printf("Cannot decline the address %v on %s: %v", ack.YourIPAddr(), m.ifname, err)
+	}
+	return false
+}
+
+// bind records a new acknowledge and moves to the BOUND state.
+func (m *Manager) bind(ack *DHCPv4) {
+	m.lock.Lock()
+	m.ack = ack
+	// the lease times are measured on the wall clock, which unlike the
+	// monotonic one keeps going while the system is suspended
+	m.boundAt = m.clock().Now().Round(0)
+	m.state = StateBound
+	m.lock.Unlock()
+}
+
+// bound waits for T1 to expire, or for a FORCERENEW, and moves to the
+// RENEWING state. If the system resumes from a suspend meanwhile, it moves to
+// the REBOOTING state instead, to confirm the lease right away.
+func (m *Manager) bound(ctx context.Context) bool {
+	m.lock.Lock()
+	ack, boundAt := m.ack, m.boundAt
+	m.lock.Unlock()
+	forceRenew, stop := m.listenForceRenew(ack)
+	defer stop()
+	var renewAt time.Time
+	if lease, t1, _, _ := leaseTimes(ack); lease >= 0 {
+		// infinite leases are only renewed on FORCERENEW
+		renewAt = boundAt.Add(t1)
+	}
+	switch waitUntil(ctx, m.clock(), renewAt, forceRenew) {
+	case wakeResumed:
+		m.setState(StateRebooting)
+		return true
+	case wakeCancelled:
+		if ctx.Err() != nil {
+			return false
+		}
+	}
+	m.setState(StateRenewing)
+	return true
+}
+
+// listenForceRenew listens for FORCERENEW messages on the leased address, and
+// returns a channel closed when a valid one is received, and a function to
+// stop listening. If there is no VerifyForceRenew, or if the address cannot be
+// listened on, e.g. because it is not configured yet, the channel is never
+// closed.
+func (m *Manager) listenForceRenew(ack *DHCPv4) (<-chan struct{}, func()) {
+	if m.VerifyForceRenew == nil {
+		return nil, func() {}
+	}
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: ack.YourIPAddr(), Port: m.Client.clientPort()})
+	if err != nil {
+		return nil, func() {}
+	}
+	received := make(chan struct{})
+	go func() {
+		buf := make([]byte, MaxUDPReceivedPacketSize)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if m.isForceRenew(ack, buf[:n]) {
+				close(received)
+				return
+			}
+		}
+	}()
+	return received, func() { conn.Close() }
+}
+
+// isForceRenew returns true if data is a valid FORCERENEW for the lease
+// described by ack. As RFC 3203 section 4 requires, the FORCERENEW messages
+// that cannot be authenticated are discarded.
+func (m *Manager) isForceRenew(ack *DHCPv4, data []byte) bool {
+	if m.VerifyForceRenew == nil {
+		return false
+	}
+	msg, err := FromBytes(data)
+	if err != nil {
+		return false
+	}
+	if mt := msg.MessageType(); mt == nil || *mt != MessageTypeForceRenew {
+		return false
+	}
+	if msg.ClientHwAddrRaw() != ack.ClientHwAddrRaw() {
+		return false
+	}
+	return m.VerifyForceRenew(data) == nil
+}
+
+// extend tries to extend the current lease, unicasting to the server that
+// granted it while in RENEWING state and broadcasting while in REBINDING state.
+// An infinite lease, which is only renewed on FORCERENEW, is kept and the
+// client goes back to the BOUND state if the server does not extend it.
+func (m *Manager) extend(ctx context.Context) bool {
+	m.lock.Lock()
+	ack, boundAt, state := m.ack, m.boundAt, m.state
+	m.lock.Unlock()
+	lease, _, t2, _ := leaseTimes(ack)
+
+	reply, err := m.renew(ctx, ack, state == StateRebinding)
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		loggerOrDefault(m.Client.Logger).Printf("Cannot extend the lease on %s: %v", m.ifname, err)
+	} else {
+		switch *reply.MessageType() {
+		case MessageTypeAck:
+			if _, _, _, err := leaseTimes(reply); err == nil {
+				if !m.checkAddress(ctx, reply) {
+					if ctx.Err() != nil {
+						return false
+					}
+					m.setState(StateInit)
+					return m.post(ctx, LeaseEvent{Type: LeaseExpired, Ack: ack}) && m.sleep(ctx, declineRetryInterval)
+				}
+				m.bind(reply)
+				return m.post(ctx, LeaseEvent{Type: LeaseRenewed, Ack: reply})
+			}
+		case MessageTypeNak:
+			m.setState(StateInit)
+			return m.post(ctx, LeaseEvent{Type: LeaseExpired, Ack: ack})
+		}
+	}
+
+	if lease < 0 {
+		m.setState(StateBound)
+		return true
+	}
+	rebindAt := boundAt.Add(t2)
+	expireAt := boundAt.Add(lease)
+	now := m.clock().Now()
+	if !now.Before(expireAt) {
+		m.setState(StateInit)
+		return m.post(ctx, LeaseEvent{Type: LeaseExpired, Ack: ack})
+	}
+	if state == StateRenewing && !now.Before(rebindAt) {
+		m.setState(StateRebinding)
+		return true
+	}
+	// wait one-half of the remaining time until the next deadline, down to
+	// a minimum of 60 seconds.
+	deadline := expireAt
+	if state == StateRenewing {
+		deadline = rebindAt
+	}
+	wait := deadline.Sub(now) / 2
+	if wait < minRetransmitInterval {
+		wait = minRetransmitInterval
+	}
+	if remaining := deadline.Sub(now); wait > remaining {
+		wait = remaining
+	}
+	// after a suspend, the times are checked again and the lease extended
+	// right away, rather than waiting for a deadline that may have passed
+	return waitUntil(ctx, m.clock(), now.Add(wait), nil) != wakeCancelled
+}
+
+// renew sends a request to extend the lease described by ack, and returns the
+// reply, which can be either an ACK or a NAK.
+func (m *Manager) renew(ctx context.Context, ack *DHCPv4, broadcast bool) (*DHCPv4, error) {
+	request, err := NewRenewFromACK(ack, m.modifiers()...)
+	if err != nil {
+		return nil, err
+	}
+	var reply *DHCPv4
+	if broadcast {
+		sender, conn, err := m.Client.openSockets(m.ifname)
+		if err != nil {
+			return nil, err
+		}
+		defer sender.Close()
+		defer conn.Close()
+		m.Client.filterReplies(conn, request.TransactionID())
+		reply, err = broadcastSendReceiveConn(ctx, sender, conn, request, m.Client.ReadTimeout, MessageTypeNone)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		serverID := ack.ServerIdentifier()
+		if serverID == nil {
+			return nil, ErrNoServerIdentifier
+		}
+		laddr := net.UDPAddr{IP: ack.YourIPAddr(), Port: m.Client.clientPort()}
+		raddr := net.UDPAddr{IP: serverID, Port: m.Client.serverPort()}
+		reply, err = sendReceiveUnicast(ctx, &laddr, &raddr, request, m.Client.ReadTimeout, m.Client.WriteTimeout, MessageTypeNone)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if reply.MessageType() == nil {
+		return nil, ErrNoMessageType
+	}
+	return reply, nil
+}
+
+// reboot confirms the current lease with a REQUEST in INIT-REBOOT state, and
+// checks its address again once acknowledged. If no server answers, the lease
+// keeps being used until it has to be extended, as RFC 2131 section 3.2
+// allows.
+func (m *Manager) reboot(ctx context.Context) bool {
+	m.lock.Lock()
+	ack, boundAt := m.ack, m.boundAt
+	m.lock.Unlock()
+	if lease, _, _, _ := leaseTimes(ack); lease >= 0 && !m.clock().Now().Before(boundAt.Add(lease)) {
+		m.setState(StateInit)
+		return m.post(ctx, LeaseEvent{Type: LeaseExpired, Ack: ack})
+	}
+	if m.Identities != nil {
+		clientID, err := m.identify()
+		m.lock.Lock()
+		moved := err == nil && m.clientID != nil && !bytes.Equal(clientID.ToBytes(), m.clientID.ToBytes())
+		m.lock.Unlock()
+		if moved {
+			m.setState(StateInit)
+			return m.post(ctx, LeaseEvent{Type: LeaseExpired, Ack: ack})
+		}
+	}
+	reply, err := m.confirm(ctx, ack)
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		loggerOrDefault(m.Client.Logger).Printf("Cannot confirm the lease on %s, keeping it: %v", m.ifname, err)
+	} else {
+		switch *reply.MessageType() {
+		case MessageTypeAck:
+			if _, _, _, err := leaseTimes(reply); err == nil {
+				if !m.checkAddress(ctx, reply) {
+					if ctx.Err() != nil {
+						return false
+					}
+					m.setState(StateInit)
+					return m.post(ctx, LeaseEvent{Type: LeaseExpired, Ack: ack}) && m.sleep(ctx, declineRetryInterval)
+				}
+				m.bind(reply)
+				return m.post(ctx, LeaseEvent{Type: LeaseRenewed, Ack: reply})
+			}
+		case MessageTypeNak:
+			m.setState(StateInit)
+			return m.post(ctx, LeaseEvent{Type: LeaseExpired, Ack: ack})
+		}
+	}
+	m.setState(StateBound)
+	return true
+}
+
+// confirm broadcasts a REQUEST in INIT-REBOOT state for the lease described by
+// ack, and returns the reply, which can be either an ACK or a NAK.
+func (m *Manager) confirm(ctx context.Context, ack *DHCPv4) (*DHCPv4, error) {
+	request, err := newInitRebootRequest(ack)
+	if err != nil {
+		return nil, err
+	}
+	for _, mod := range m.modifiers() {
+		request = mod(request)
+	}
+	return m.Client.confirm(ctx, m.ifname, request)
+}
+
+// identify returns the client identifier of the interface, from Identities,
+// or nil if there is none.
+func (m *Manager) identify() (*OptClientIdentifier, error) {
+	if m.Identities == nil {
+		return nil, nil
+	}
+	iface, err := net.InterfaceByName(m.ifname)
+	if err != nil {
+		return nil, err
+	}
+	var network string
+	if m.Network != nil {
+		network = m.Network()
+	}
+	id, err := m.Identities.Get(m.ifname, iface.HardwareAddr, network)
+	if err != nil {
+		return nil, err
+	}
+	return NewNodeSpecificClientIdentifier(id.IAID, id.DUID), nil
+}
+
+// modifiers returns the modifiers that the messages about the current lease
+// need, which set the client identifier it was obtained with.
+func (m *Manager) modifiers() []Modifier {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.clientID == nil {
+		return nil
+	}
+	return []Modifier{WithClientIdentifier(m.clientID)}
+}
+
+// newInitRebootRequest builds a REQUEST in INIT-REBOOT state for the lease
+// described by ack. As per RFC 2131 section 4.3.2 it is broadcast, the address
+// goes in the Requested IP Address option, and there is no Server Identifier.
+func newInitRebootRequest(ack *DHCPv4) (*DHCPv4, error) {
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	d.SetOpcode(OpcodeBootRequest)
+	d.SetHwType(ack.HwType())
+	d.SetHwAddrLen(ack.HwAddrLen())
+	hwaddr := ack.ClientHwAddrRaw()
+	d.SetClientHwAddrRaw(hwaddr[:])
+	d.SetBroadcast()
+	d.AddOption(&OptMessageType{MessageType: MessageTypeRequest})
+	d.AddOption(&OptRequestedIPAddress{RequestedAddr: ack.YourIPAddr()})
+	return d, nil
+}
+
+// leaseTimes returns the lease duration, the renewal time (T1) and the
+// rebinding time (T2) of an acknowledge. If the server did not send T1 and T2,
+// they default to 0.5 and 0.875 times the lease duration, as per RFC 2131
+// section 4.4.5. An infinite lease is reported as a negative duration.
+func leaseTimes(ack *DHCPv4) (lease, t1, t2 time.Duration, err error) {
+	leaseTime, ok := getUint32Option(ack, OptionIPAddressLeaseTime)
+	if !ok {
+		return 0, 0, 0, ErrNoLeaseTime
+	}
+	if leaseTime == infiniteLeaseTime {
+		return -1, -1, -1, nil
+	}
+	lease = time.Duration(leaseTime) * time.Second
+	t1 = lease / 2
+	t2 = lease * 7 / 8
+	if v, ok := getUint32Option(ack, OptionRenewTimeValue); ok {
+		t1 = time.Duration(v) * time.Second
+	}
+	if v, ok := getUint32Option(ack, OptionRebindingTimeValue); ok {
+		t2 = time.Duration(v) * time.Second
+	}
+	if t2 > lease {
+		t2 = lease
+	}
+	if t1 > t2 {
+		t1 = t2
+	}
+	return lease, t1, t2, nil
+}
+
+// getUint32Option returns the value of a 4-bytes option, regardless of whether
+// it was parsed into a specific structure or not.
+func getUint32Option(d *DHCPv4, code OptionCode) (uint32, bool) {
+	opt := d.GetOneOption(code)
+	if opt == nil {
+		return 0, false
+	}
+	data := opt.ToBytes()
+	if len(data) != 6 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(data[2:6]), true
+}
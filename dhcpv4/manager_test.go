@@ -0,0 +1,459 @@
+package dhcpv4
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+	"github.com/insomniacslk/dhcp/identity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientStateString(t *testing.T) {
+	require.Equal(t, "INIT", StateInit.String())
+	require.Equal(t, "REBINDING", StateRebinding.String())
+	require.Equal(t, "Unknown", ClientState(99).String())
+}
+
+func TestLeaseEventTypeString(t *testing.T) {
+	require.Equal(t, "Bound", LeaseBound.String())
+	require.Equal(t, "Expired", LeaseExpired.String())
+	require.Equal(t, "Unknown", LeaseEventType(99).String())
+}
+
+func TestLeaseTimesDefaults(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	_, _, _, err = leaseTimes(ack)
+	require.Error(t, err, "no lease time option")
+
+	ack.AddOption(&OptIPAddressLeaseTime{LeaseTime: 3600})
+	lease, t1, t2, err := leaseTimes(ack)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, lease)
+	require.Equal(t, 30*time.Minute, t1)
+	require.Equal(t, 52*time.Minute+30*time.Second, t2)
+}
+
+func TestLeaseTimesExplicit(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.AddOption(&OptIPAddressLeaseTime{LeaseTime: 3600})
+	ack.AddOption(&OptionGeneric{OptionCode: OptionRenewTimeValue, Data: []byte{0, 0, 0x03, 0x84}})
+	ack.AddOption(&OptionGeneric{OptionCode: OptionRebindingTimeValue, Data: []byte{0, 0, 0x07, 0x08}})
+	lease, t1, t2, err := leaseTimes(ack)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, lease)
+	require.Equal(t, 15*time.Minute, t1)
+	require.Equal(t, 30*time.Minute, t2)
+}
+
+func TestLeaseTimesClamped(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.AddOption(&OptIPAddressLeaseTime{LeaseTime: 60})
+	ack.AddOption(&OptionGeneric{OptionCode: OptionRenewTimeValue, Data: []byte{0, 0, 0, 120}})
+	ack.AddOption(&OptionGeneric{OptionCode: OptionRebindingTimeValue, Data: []byte{0, 0, 0, 90}})
+	lease, t1, t2, err := leaseTimes(ack)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, lease)
+	require.Equal(t, time.Minute, t2)
+	require.Equal(t, time.Minute, t1)
+}
+
+func TestLeaseTimesInfinite(t *testing.T) {
+	ack, err := New()
+	require.NoError(t, err)
+	ack.AddOption(&OptIPAddressLeaseTime{LeaseTime: 0xffffffff})
+	lease, _, _, err := leaseTimes(ack)
+	require.NoError(t, err)
+	require.True(t, lease < 0)
+}
+
+func TestManagerStartClose(t *testing.T) {
+	m := NewManager("nonexistent0")
+	require.Equal(t, StateInit, m.State())
+	require.Nil(t, m.Ack())
+	require.NoError(t, m.Start())
+	require.Error(t, m.Start())
+	m.Close()
+	// closing twice is harmless
+	m.Close()
+}
+
+func TestManagerLease(t *testing.T) {
+	m := NewManager("nonexistent0")
+	require.Nil(t, m.Lease())
+
+	m.bind(leaseTestACK(t))
+	lease := m.Lease()
+	require.NotNil(t, lease)
+	require.Equal(t, net.IP{192, 168, 0, 10}, lease.IP)
+	require.Equal(t, m.boundAt, lease.Acquired)
+}
+
+// forceRenewTestACK returns an acknowledge for a lease of 127.0.0.1, which
+// the tests can listen on.
+func forceRenewTestACK(t *testing.T) *DHCPv4 {
+	ack := leaseTestACK(t)
+	ack.SetYourIPAddr(net.IPv4(127, 0, 0, 1))
+	return ack
+}
+
+func TestManagerForceRenew(t *testing.T) {
+	// find a free port
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	conn.Close()
+
+	m := NewManager("nonexistent0")
+	m.Client.LocalPort = addr.Port
+	m.VerifyForceRenew = func(data []byte) error { return nil }
+	ack := forceRenewTestACK(t)
+	m.bind(ack)
+
+	done := make(chan bool)
+	go func() {
+		done <- m.bound(context.Background())
+	}()
+
+	other, err := NewForceRenew(ack, func(d *DHCPv4) *DHCPv4 {
+		d.SetClientHwAddr(net.HardwareAddr{6, 5, 4, 3, 2, 1})
+		return d
+	})
+	require.NoError(t, err)
+	forceRenew, err := NewForceRenew(ack)
+	require.NoError(t, err)
+	out, err := net.DialUDP("udp4", nil, addr)
+	require.NoError(t, err)
+	defer out.Close()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ok := <-done:
+			require.True(t, ok)
+			require.Equal(t, StateRenewing, m.State())
+			return
+		case <-ticker.C:
+			// the FORCERENEW for another client is ignored
+			out.Write(other.ToBytes())
+			out.Write(forceRenew.ToBytes())
+		case <-timeout:
+			t.Fatal("FORCERENEW not handled")
+		}
+	}
+}
+
+func TestManagerForceRenewInfiniteLease(t *testing.T) {
+	// find free ports, for the FORCERENEW messages and for a server that
+	// does not answer
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	conn.Close()
+	silent, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer silent.Close()
+
+	m := NewManager("nonexistent0")
+	m.Client.LocalPort = addr.Port
+	m.Client.ServerPort = silent.LocalAddr().(*net.UDPAddr).Port
+	m.Client.ReadTimeout = 100 * time.Millisecond
+	m.VerifyForceRenew = func(data []byte) error { return nil }
+	ack := forceRenewTestACK(t)
+	ack.UpdateOption(&OptServerIdentifier{ServerID: net.IPv4(127, 0, 0, 1)})
+	ack.UpdateOption(&OptIPAddressLeaseTime{LeaseTime: infiniteLeaseTime})
+	m.bind(ack)
+
+	done := make(chan bool)
+	go func() {
+		done <- m.bound(context.Background())
+	}()
+	forceRenew, err := NewForceRenew(ack)
+	require.NoError(t, err)
+	out, err := net.DialUDP("udp4", nil, addr)
+	require.NoError(t, err)
+	defer out.Close()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+wait:
+	for {
+		select {
+		case ok := <-done:
+			require.True(t, ok)
+			break wait
+		case <-ticker.C:
+			out.Write(forceRenew.ToBytes())
+		case <-timeout:
+			t.Fatal("FORCERENEW not handled")
+		}
+	}
+	require.Equal(t, StateRenewing, m.State())
+
+	// the server does not answer, and the lease is kept
+	require.True(t, m.extend(context.Background()))
+	require.Equal(t, StateBound, m.State())
+	require.Equal(t, ack, m.Ack())
+	require.Len(t, m.Events(), 0)
+}
+
+func TestManagerIsForceRenew(t *testing.T) {
+	m := NewManager("nonexistent0")
+	ack := forceRenewTestACK(t)
+	forceRenew, err := NewForceRenew(ack)
+	require.NoError(t, err)
+	require.Equal(t, OpcodeBootReply, forceRenew.Opcode())
+	require.True(t, forceRenew.ClientIPAddr().Equal(ack.YourIPAddr()))
+	// without authentication, FORCERENEW messages are discarded
+	require.False(t, m.isForceRenew(ack, forceRenew.ToBytes()))
+
+	m.VerifyForceRenew = func(data []byte) error { return nil }
+	require.True(t, m.isForceRenew(ack, forceRenew.ToBytes()))
+	require.False(t, m.isForceRenew(ack, ack.ToBytes()))
+
+	// with authentication
+	key := []byte("secret")
+	m.VerifyForceRenew = func(data []byte) error {
+		return VerifyDelayedAuthentication(data, func(uint32) []byte { return key })
+	}
+	require.False(t, m.isForceRenew(ack, forceRenew.ToBytes()))
+	require.NoError(t, SignDelayedAuthentication(forceRenew, 1, key, 1))
+	require.True(t, m.isForceRenew(ack, forceRenew.ToBytes()))
+}
+
+func TestManagerLinkDownUp(t *testing.T) {
+	m := NewManager("nonexistent0")
+	m.LinkUp()
+	require.Equal(t, StateInit, m.State(), "LinkUp without LinkDown does nothing")
+
+	m.bind(leaseTestACK(t))
+	m.LinkDown()
+	m.LinkDown()
+	require.Equal(t, StateBound, m.State())
+	m.LinkUp()
+	require.Equal(t, StateRebooting, m.State())
+
+	// without lease, the client goes on obtaining one
+	m = NewManager("nonexistent0")
+	m.LinkDown()
+	m.LinkUp()
+	require.Equal(t, StateInit, m.State())
+}
+
+func TestManagerLinkDownPauses(t *testing.T) {
+	m := NewManager("nonexistent0")
+	m.bind(leaseTestACK(t))
+	m.LinkDown()
+	require.NoError(t, m.Start())
+	defer m.Close()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, StateBound, m.State())
+}
+
+func TestNewInitRebootRequest(t *testing.T) {
+	ack := leaseTestACK(t)
+	request, err := newInitRebootRequest(ack)
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeRequest, *request.MessageType())
+	require.Equal(t, ack.ClientHwAddr(), request.ClientHwAddr())
+	require.True(t, request.ClientIPAddr().IsUnspecified())
+	require.True(t, request.IsBroadcast())
+	require.Nil(t, request.GetOneOption(OptionServerIdentifier))
+	opt, ok := request.GetOneOption(OptionRequestedIPAddress).(*OptRequestedIPAddress)
+	require.True(t, ok)
+	require.True(t, opt.RequestedAddr.Equal(ack.YourIPAddr()))
+}
+
+func TestManagerReboot(t *testing.T) {
+	for _, tc := range []struct {
+		mt        MessageType
+		conflict  bool
+		wantEvent LeaseEventType
+		wantState ClientState
+	}{
+		{MessageTypeAck, false, LeaseRenewed, StateBound},
+		{MessageTypeNak, false, LeaseExpired, StateInit},
+		// the decline of an address in use fails on the loopback
+		// connections, and is only logged
+		{MessageTypeAck, true, LeaseExpired, StateInit},
+	} {
+		mt := tc.mt
+		server, out := setUpLoopbackConns(t)
+		in, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		require.NoError(t, err)
+		go func(mt MessageType) {
+			buf := make([]byte, MaxUDPReceivedPacketSize)
+			n, _, err := server.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request, err := FromBytes(buf[:n])
+			if err != nil {
+				return
+			}
+			reply, err := NewReplyFromRequest(request,
+				WithMessageType(mt),
+				WithYourIP(net.IPv4(192, 168, 0, 10)),
+				WithServerIP(net.IPv4(192, 168, 0, 1)),
+				WithLeaseTime(time.Hour),
+			)
+			if err != nil {
+				return
+			}
+			server.WriteTo(reply.ToBytes(), in.LocalAddr())
+		}(mt)
+
+		m := NewManager("eth0")
+		m.Client.ifname, m.Client.sender, m.Client.recvConn = "eth0", loopbackBroadcaster{out}, in
+		var checked net.IP
+		m.CheckAddress = func(ctx context.Context, ifname string, ip net.IP) error {
+			checked = ip
+			if tc.conflict {
+				return &acd.ConflictError{IP: ip}
+			}
+			return nil
+		}
+		m.bind(leaseTestACK(t))
+		m.setState(StateRebooting)
+		// the manager waits before starting over after a decline
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan bool)
+		go func() {
+			done <- m.reboot(ctx)
+		}()
+		ev := <-m.Events()
+		cancel()
+		require.Equal(t, !tc.conflict, <-done)
+		require.Equal(t, tc.wantEvent, ev.Type)
+		require.Equal(t, tc.wantState, m.State())
+		if mt == MessageTypeAck {
+			require.True(t, checked.Equal(net.IPv4(192, 168, 0, 10)))
+		} else {
+			require.Nil(t, checked)
+		}
+		m.Client.Close()
+		server.Close()
+	}
+}
+
+func TestManagerBoundResumed(t *testing.T) {
+	defer simulateSuspend()()
+	m := NewManager("nonexistent0")
+	m.bind(leaseTestACK(t))
+	require.True(t, m.bound(context.Background()))
+	require.Equal(t, StateRebooting, m.State())
+}
+
+func TestManagerLeaseLifecycle(t *testing.T) {
+	// the lease can neither be renewed from an address that is not
+	// configured, nor rebound on an interface that does not exist, so that
+	// it goes through T1 and T2 until it expires
+	clock := newFakeClock()
+	m := NewManager("nonexistent0")
+	m.Clock = clock
+	ack := leaseTestACK(t)
+	ack.SetYourIPAddr(net.IPv4(192, 0, 2, 10))
+	m.bind(ack)
+	require.Equal(t, clock.Now(), m.Lease().Acquired)
+	ctx := context.Background()
+
+	done := make(chan bool)
+	go func() {
+		done <- m.bound(ctx)
+	}()
+	clock.WaitForTimer()
+	clock.Advance(30 * time.Minute)
+	require.True(t, <-done)
+	require.Equal(t, StateRenewing, m.State())
+
+	// the renewal is retried after half the time until T2
+	go func() {
+		done <- m.extend(ctx)
+	}()
+	clock.WaitForTimer()
+	clock.Advance(11*time.Minute + 15*time.Second)
+	require.True(t, <-done)
+	require.Equal(t, StateRenewing, m.State())
+
+	clock.Advance(15 * time.Minute)
+	require.True(t, m.extend(ctx))
+	require.Equal(t, StateRebinding, m.State())
+
+	clock.Advance(10 * time.Minute)
+	require.True(t, m.extend(ctx))
+	ev := <-m.Events()
+	require.Equal(t, LeaseExpired, ev.Type)
+	require.Equal(t, StateInit, m.State())
+}
+
+func TestManagerCheckAddress(t *testing.T) {
+	ack := leaseTestACK(t)
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"free", nil, true},
+		{"in use", &acd.ConflictError{IP: ack.YourIPAddr()}, false},
+		{"unsupported", errors.New("unsupported"), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewManager("nonexistent0")
+			var checked net.IP
+			m.CheckAddress = func(ctx context.Context, ifname string, ip net.IP) error {
+				require.Equal(t, "nonexistent0", ifname)
+				checked = ip
+				return tc.err
+			}
+			// the decline of an address in use fails on the nonexistent
+			// interface, and is only logged
+			require.Equal(t, tc.want, m.checkAddress(context.Background(), ack))
+			require.Equal(t, ack.YourIPAddr(), checked)
+		})
+	}
+
+	m := NewManager("nonexistent0")
+	require.True(t, m.checkAddress(context.Background(), ack))
+}
+
+func TestManagerIdentity(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+	require.NotEmpty(t, ifaces)
+
+	network := "home"
+	m := NewManager(ifaces[0].Name)
+	m.Identities = identity.NewStore("", identity.PolicyPerNetwork)
+	m.Network = func() string { return network }
+	require.Empty(t, m.modifiers())
+
+	clientID, err := m.identify()
+	require.NoError(t, err)
+	require.Equal(t, ClientIdentifierNodeSpecific, clientID.Type)
+	id, err := m.Identities.Get(ifaces[0].Name, ifaces[0].HardwareAddr, network)
+	require.NoError(t, err)
+	require.Equal(t, id.IAID, clientID.IAID)
+	require.Equal(t, id.DUID, *clientID.DUID)
+
+	// the messages about the lease carry the client identifier
+	m.clientID = clientID
+	m.bind(leaseTestACK(t))
+	request, err := NewRenewFromACK(m.Ack(), m.modifiers()...)
+	require.NoError(t, err)
+	require.Equal(t, clientID, request.GetOneOption(OptionClientIdentifier))
+
+	// on another network, the lease is dropped instead of being confirmed
+	network = "office"
+	m.setState(StateRebooting)
+	require.True(t, m.reboot(context.Background()))
+	ev := <-m.Events()
+	require.Equal(t, LeaseExpired, ev.Type)
+	require.Equal(t, StateInit, m.State())
+}
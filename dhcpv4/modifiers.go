@@ -2,6 +2,9 @@ package dhcpv4
 
 import (
 	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/iana"
 )
 
 // WithUserClass adds a user class option to the packet.
@@ -21,15 +24,16 @@ func WithUserClass(uc []byte, rfc bool) Modifier {
 
 // WithNetboot adds bootfile URL and bootfile param options to a DHCPv4 packet.
 func WithNetboot(d *DHCPv4) *DHCPv4 {
-	params := d.GetOneOption(OptionParameterRequestList)
+	// a malformed list, kept by a lenient parsing, is replaced
+	params, ok := d.GetOneOption(OptionParameterRequestList).(*OptParameterRequestList)
 
 	var (
 		OptParams                 *OptParameterRequestList
 		foundOptionTFTPServerName bool
 		foundOptionBootfileName   bool
 	)
-	if params != nil {
-		OptParams = params.(*OptParameterRequestList)
+	if ok {
+		OptParams = params
 		for _, option := range OptParams.RequestedOpts {
 			if option == OptionTFTPServerName {
 				foundOptionTFTPServerName = true
@@ -47,7 +51,7 @@ func WithNetboot(d *DHCPv4) *DHCPv4 {
 		OptParams = &OptParameterRequestList{
 			RequestedOpts: []OptionCode{OptionTFTPServerName, OptionBootfileName},
 		}
-		d.AddOption(OptParams)
+		d.UpdateOption(OptParams)
 	}
 	return d
 }
@@ -55,12 +59,11 @@ func WithNetboot(d *DHCPv4) *DHCPv4 {
 // WithRequestedOptions adds requested options to the packet
 func WithRequestedOptions(optionCodes ...OptionCode) Modifier {
 	return func(d *DHCPv4) *DHCPv4 {
-		params := d.GetOneOption(OptionParameterRequestList)
-		if params == nil {
-			params = &OptParameterRequestList{}
-			d.AddOption(params)
+		opts, ok := d.GetOneOption(OptionParameterRequestList).(*OptParameterRequestList)
+		if !ok {
+			opts = &OptParameterRequestList{}
+			d.UpdateOption(opts)
 		}
-		opts := params.(*OptParameterRequestList)
 		for _, optionCode := range optionCodes {
 			opts.RequestedOpts = append(opts.RequestedOpts, optionCode)
 		}
@@ -78,3 +81,161 @@ func WithRelay(ip net.IP) Modifier {
 		return d
 	}
 }
+
+// WithSubnetSelection sets the subnet selection option, asking the server to
+// allocate an address on the subnet of ip.
+func WithSubnetSelection(ip net.IP) Modifier {
+	return WithOption(&OptSubnetSelection{Subnet: ip})
+}
+
+// WithLinkSelection sets the link selection sub-option of the relay agent
+// information option, which relay agents add when the giaddr is not on the
+// subnet of the client. The other sub-options are kept.
+func WithLinkSelection(ip net.IP) Modifier {
+	return func(d *DHCPv4) *DHCPv4 {
+		opt, ok := d.GetOneOption(OptionRelayAgentInformation).(*OptRelayAgentInformation)
+		if !ok {
+			opt = &OptRelayAgentInformation{}
+		}
+		opt.SetLinkSelection(ip)
+		d.UpdateOption(opt)
+		return d
+	}
+}
+
+// WithOption sets an option of the packet, replacing the one with the same
+// code if any.
+func WithOption(opt Option) Modifier {
+	return func(d *DHCPv4) *DHCPv4 {
+		d.UpdateOption(opt)
+		return d
+	}
+}
+
+// WithVendorClass sets the Class Identifier option of the packet, e.g. to
+// "PXEClient" in the replies to PXE clients.
+func WithVendorClass(class string) Modifier {
+	return WithOption(&OptClassIdentifier{Identifier: class})
+}
+
+// WithMessageType sets the DHCP message type of the packet.
+func WithMessageType(t MessageType) Modifier {
+	return WithOption(&OptMessageType{MessageType: t})
+}
+
+// WithTransactionID sets the transaction ID of the packet.
+func WithTransactionID(xid uint32) Modifier {
+	return func(d *DHCPv4) *DHCPv4 {
+		d.SetTransactionID(xid)
+		return d
+	}
+}
+
+// WithYourIP sets the address offered to the client (yiaddr).
+func WithYourIP(ip net.IP) Modifier {
+	return func(d *DHCPv4) *DHCPv4 {
+		d.SetYourIPAddr(ip)
+		return d
+	}
+}
+
+// WithServerIP sets the address of the next server (siaddr).
+func WithServerIP(ip net.IP) Modifier {
+	return func(d *DHCPv4) *DHCPv4 {
+		d.SetServerIPAddr(ip)
+		return d
+	}
+}
+
+// WithLeaseTime sets the IP address lease time option, rounded down to the
+// second. Durations beyond the range of the option are capped.
+func WithLeaseTime(leaseTime time.Duration) Modifier {
+	secs := leaseTime / time.Second
+	if secs < 0 {
+		secs = 0
+	} else if secs > 0xffffffff {
+		secs = 0xffffffff
+	}
+	return WithOption(&OptIPAddressLeaseTime{LeaseTime: uint32(secs)})
+}
+
+// WithDNS sets the domain name server option.
+func WithDNS(dnses ...net.IP) Modifier {
+	return WithOption(&OptDomainNameServer{NameServers: dnses})
+}
+
+// WithRouter sets the router option.
+func WithRouter(routers ...net.IP) Modifier {
+	return WithOption(&OptRouter{Routers: routers})
+}
+
+// WithClasslessStaticRoutes sets the classless static route option, its
+// Microsoft variant, or both, as selected by which, e.g. with
+// RequestedClasslessRouteOptions. The other one is left as is.
+func WithClasslessStaticRoutes(which ClasslessRouteOptions, routes ...Route) Modifier {
+	return func(d *DHCPv4) *DHCPv4 {
+		if which != ClasslessRouteMicrosoft {
+			d.UpdateOption(&OptClasslessStaticRoute{Routes: routes})
+		}
+		if which != ClasslessRouteStandard {
+			d.UpdateOption(&OptClasslessStaticRoute{Routes: routes, Microsoft: true})
+		}
+		return d
+	}
+}
+
+// WithNetmask sets the subnet mask option.
+func WithNetmask(mask net.IPMask) Modifier {
+	return WithOption(&OptSubnetMask{SubnetMask: mask})
+}
+
+// WithBroadcastAddress sets the broadcast address option, see
+// SubnetBroadcastAddress.
+func WithBroadcastAddress(ip net.IP) Modifier {
+	return WithOption(&OptBroadcastAddress{BroadcastAddress: ip})
+}
+
+// WithInterfaceMTU sets the interface MTU option. Clients reject an MTU
+// smaller than MinInterfaceMTU.
+func WithInterfaceMTU(mtu uint16) Modifier {
+	return WithOption(&OptInterfaceMTU{MTU: mtu})
+}
+
+// WithNTPServers sets the NTP servers option.
+func WithNTPServers(servers ...net.IP) Modifier {
+	return WithOption(&OptNTPServers{NTPServers: servers})
+}
+
+// WithTimeOffset sets the time offset option, rounded down to the second.
+func WithTimeOffset(offset time.Duration) Modifier {
+	return WithOption(&OptTimeOffset{Offset: NewTimeOffset(offset)})
+}
+
+// WithMaximumMessageSize sets the maximum DHCP message size option, which
+// tells the server the size of the largest message the client accepts.
+func WithMaximumMessageSize(size uint16) Modifier {
+	return WithOption(&OptMaximumDHCPMessageSize{Size: size})
+}
+
+// WithClientIdentifier sets the client identifier option, see
+// NewClientIdentifierFromHwAddr and NewNodeSpecificClientIdentifier.
+func WithClientIdentifier(id *OptClientIdentifier) Modifier {
+	return WithOption(id)
+}
+
+// WithClientArch sets the client system architecture option, which PXE clients
+// send so that servers pick a boot file they can run.
+func WithClientArch(archTypes ...iana.ArchType) Modifier {
+	return WithOption(&OptClientArchType{ArchTypes: archTypes})
+}
+
+// WithClientNDI sets the client network interface identifier option of a PXE
+// client, usually of type NDITypeUNDI.
+func WithClientNDI(typ, major, minor uint8) Modifier {
+	return WithOption(&OptClientNDI{Type: typ, Major: major, Minor: minor})
+}
+
+// WithMachineUUID sets the client machine identifier option to a UUID.
+func WithMachineUUID(uuid [16]byte) Modifier {
+	return WithOption(NewMachineUUID(uuid))
+}
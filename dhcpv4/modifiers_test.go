@@ -3,7 +3,9 @@ package dhcpv4
 import (
 	"net"
 	"testing"
+	"time"
 
+	"github.com/insomniacslk/dhcp/iana"
 	"github.com/stretchr/testify/require"
 )
 
@@ -99,3 +101,82 @@ func TestWithRelay(t *testing.T) {
 	require.Equal(t, ip, d.GatewayIPAddr())
 	require.Equal(t, uint8(1), d.HopCount())
 }
+
+func TestWithOption(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d = WithOption(&OptHostName{HostName: "darkstar"})(d)
+	d = WithOption(&OptHostName{HostName: "lightstar"})(d)
+	require.Len(t, d.GetOption(OptionHostName), 1)
+	require.Equal(t, "lightstar", d.HostName())
+}
+
+func TestReplyModifiers(t *testing.T) {
+	request, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	d, err := NewReplyFromRequest(request,
+		WithMessageType(MessageTypeOffer),
+		WithTransactionID(0xaabbccdd),
+		WithYourIP(net.IPv4(192, 168, 0, 10)),
+		WithServerIP(net.IPv4(192, 168, 0, 1)),
+		WithLeaseTime(time.Hour),
+		WithDNS(net.IPv4(8, 8, 8, 8), net.IPv4(8, 8, 4, 4)),
+		WithRouter(net.IPv4(192, 168, 0, 254)),
+		WithNetmask(net.IPv4Mask(255, 255, 255, 0)),
+		WithNTPServers(net.IPv4(192, 168, 0, 123)),
+		WithTimeOffset(-5*time.Hour),
+		WithInterfaceMTU(1400),
+		WithBroadcastAddress(net.IPv4(192, 168, 0, 255)),
+	)
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeOffer, *d.MessageType())
+	require.Equal(t, uint32(0xaabbccdd), d.TransactionID())
+	require.True(t, d.YourIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+	require.True(t, d.ServerIPAddr().Equal(net.IPv4(192, 168, 0, 1)))
+	require.Equal(t, time.Hour, d.IPAddressLeaseTime(0))
+	require.Equal(t, []net.IP{net.IPv4(8, 8, 8, 8), net.IPv4(8, 8, 4, 4)}, d.DNS())
+	require.Equal(t, []net.IP{net.IPv4(192, 168, 0, 254)}, d.Router())
+	require.Equal(t, net.IPv4Mask(255, 255, 255, 0), d.SubnetMask())
+	require.Equal(t, []net.IP{net.IPv4(192, 168, 0, 123)}, d.NTPServers())
+	require.Equal(t, -5*time.Hour, d.TimeOffset().Duration())
+	require.Equal(t, uint16(1400), d.InterfaceMTU())
+	require.Equal(t, net.IPv4(192, 168, 0, 255), d.BroadcastAddress())
+}
+
+func TestWithLeaseTimeBounds(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d = WithLeaseTime(-time.Second)(d)
+	require.Equal(t, time.Duration(0), d.IPAddressLeaseTime(time.Minute))
+
+	d = WithLeaseTime(200 * 365 * 24 * time.Hour)(d)
+	require.Equal(t, 0xffffffff*time.Second, d.IPAddressLeaseTime(0))
+}
+
+func TestWithClientIdentifier(t *testing.T) {
+	hwaddr := net.HardwareAddr{1, 2, 3, 4, 5, 6}
+	d, err := NewDiscovery(hwaddr)
+	require.NoError(t, err)
+	d = WithClientIdentifier(NewClientIdentifierFromHwAddr(hwaddr))(d)
+	require.Equal(t, NewClientIdentifierFromHwAddr(hwaddr), d.GetOneOption(OptionClientIdentifier))
+}
+
+func TestPXEClientModifiers(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d = WithClientArch(iana.EFI_X86_64)(d)
+	d = WithClientNDI(NDITypeUNDI, 3, 16)(d)
+	d = WithMachineUUID(testMachineUUID)(d)
+	d, err = FromBytes(d.ToBytes())
+	require.NoError(t, err)
+
+	arch, ok := d.GetOneOption(OptionClientSystemArchitectureType).(*OptClientArchType)
+	require.True(t, ok)
+	require.Equal(t, []iana.ArchType{iana.EFI_X86_64}, arch.ArchTypes)
+	require.Equal(t, &OptClientNDI{Type: NDITypeUNDI, Major: 3, Minor: 16}, d.GetOneOption(OptionClientNetworkInterfaceIdentifier))
+	id, ok := d.GetOneOption(OptionClientMachineIdentifier).(*OptClientMachineIdentifier)
+	require.True(t, ok)
+	uuid, ok := id.UUID()
+	require.True(t, ok)
+	require.Equal(t, testMachineUUID, uuid)
+}
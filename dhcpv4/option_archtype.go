@@ -51,27 +51,29 @@ func (o *OptClientArchType) String() string {
 	return fmt.Sprintf("Client System Architecture Type -> %v", archTypes)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptClientArchType) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptClientArchType) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // ParseOptClientArchType returns a new OptClientArchType from a byte stream,
 // or error if any.
 func ParseOptClientArchType(data []byte) (*OptClientArchType, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
-	}
-	code := OptionCode(data[0])
-	if code != OptionClientSystemArchitectureType {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionClientSystemArchitectureType, code)
-	}
-	length := int(data[1])
-	if length == 0 || length%2 != 0 {
-		return nil, fmt.Errorf("Invalid length: expected multiple of 2 larger than 2, got %v", length)
+	buf, err := newOptionLexer(data, OptionClientSystemArchitectureType)
+	if err != nil {
+		return nil, err
 	}
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
+	if buf.Len() == 0 || buf.Len()%2 != 0 {
+		return nil, fmt.Errorf("Invalid length: expected multiple of 2 larger than 2, got %v", buf.Len())
 	}
-	archTypes := make([]iana.ArchType, 0, length%2)
-	for idx := 0; idx < length; idx += 2 {
-		b := data[2+idx : 2+idx+2]
-		archTypes = append(archTypes, iana.ArchType(binary.BigEndian.Uint16(b)))
+	archTypes := make([]iana.ArchType, 0, buf.Len()/2)
+	for buf.Has(2) {
+		archTypes = append(archTypes, iana.ArchType(buf.Read16()))
 	}
 	return &OptClientArchType{ArchTypes: archTypes}, nil
 }
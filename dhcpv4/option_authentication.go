@@ -0,0 +1,118 @@
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/insomniacslk/dhcp/uio"
+)
+
+// This option implements the authentication option.
+// https://tools.ietf.org/html/rfc3118
+
+// AuthenticationProtocol is the authentication protocol of an authentication
+// option.
+type AuthenticationProtocol uint8
+
+// Authentication protocols, see RFC 3118, section 4.
+const (
+	AuthenticationProtocolConfigurationToken AuthenticationProtocol = 0
+	AuthenticationProtocolDelayed            AuthenticationProtocol = 1
+)
+
+func (p AuthenticationProtocol) String() string {
+	switch p {
+	case AuthenticationProtocolConfigurationToken:
+		return "configuration token"
+	case AuthenticationProtocolDelayed:
+		return "delayed authentication"
+	}
+	return fmt.Sprintf("unknown (%d)", uint8(p))
+}
+
+// AuthenticationAlgorithm is the algorithm of an authentication option.
+type AuthenticationAlgorithm uint8
+
+// AuthenticationAlgorithmHMACMD5 is the only algorithm defined for the delayed
+// authentication protocol.
+const AuthenticationAlgorithmHMACMD5 AuthenticationAlgorithm = 1
+
+// ReplayDetectionMethod is the replay detection method of an authentication
+// option.
+type ReplayDetectionMethod uint8
+
+// ReplayDetectionMonotonic is the only replay detection method defined: the
+// replay detection field holds a monotonically increasing counter, such as a
+// timestamp.
+const ReplayDetectionMonotonic ReplayDetectionMethod = 0
+
+// OptAuthentication represents the authentication option.
+type OptAuthentication struct {
+	Protocol                  AuthenticationProtocol
+	Algorithm                 AuthenticationAlgorithm
+	RDM                       ReplayDetectionMethod
+	ReplayDetection           uint64
+	AuthenticationInformation []byte
+}
+
+// ParseOptAuthentication constructs an OptAuthentication struct from a
+// sequence of bytes and returns it, or an error.
+func ParseOptAuthentication(data []byte) (*OptAuthentication, error) {
+	buf, err := newOptionLexer(data, OptionAuthentication)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() < 11 {
+		return nil, fmt.Errorf("expected length of at least 11, got %v instead", buf.Len())
+	}
+	return parseAuthentication(buf.ReadAll()), nil
+}
+
+// parseAuthentication parses the data of an authentication option, which must
+// be at least 11 bytes long.
+func parseAuthentication(data []byte) *OptAuthentication {
+	buf := uio.NewBigEndianBuffer(data)
+	return &OptAuthentication{
+		Protocol:                  AuthenticationProtocol(buf.Read8()),
+		Algorithm:                 AuthenticationAlgorithm(buf.Read8()),
+		RDM:                       ReplayDetectionMethod(buf.Read8()),
+		ReplayDetection:           buf.Read64(),
+		AuthenticationInformation: buf.ReadAll(),
+	}
+}
+
+// Code returns the option code.
+func (o *OptAuthentication) Code() OptionCode {
+	return OptionAuthentication
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptAuthentication) ToBytes() []byte {
+	ret := make([]byte, 13, 2+o.Length())
+	ret[0], ret[1] = byte(o.Code()), byte(o.Length())
+	ret[2], ret[3], ret[4] = byte(o.Protocol), byte(o.Algorithm), byte(o.RDM)
+	binary.BigEndian.PutUint64(ret[5:13], o.ReplayDetection)
+	return append(ret, o.AuthenticationInformation...)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptAuthentication) String() string {
+	return fmt.Sprintf("Authentication -> protocol %v, algorithm %d, RDM %d, replay detection %d, information %v",
+		o.Protocol, o.Algorithm, o.RDM, o.ReplayDetection, o.AuthenticationInformation)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptAuthentication) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptAuthentication) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptAuthentication) Length() int {
+	return 11 + len(o.AuthenticationInformation)
+}
@@ -0,0 +1,50 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptAuthenticationInterfaceMethods(t *testing.T) {
+	o := OptAuthentication{
+		Protocol:                  AuthenticationProtocolDelayed,
+		Algorithm:                 AuthenticationAlgorithmHMACMD5,
+		RDM:                       ReplayDetectionMonotonic,
+		ReplayDetection:           0x0102030405060708,
+		AuthenticationInformation: []byte{0xaa, 0xbb},
+	}
+	require.Equal(t, OptionAuthentication, o.Code(), "Code")
+	require.Equal(t, 13, o.Length(), "Length")
+	expected := []byte{
+		byte(OptionAuthentication), 13,
+		1, 1, 0, // protocol, algorithm, RDM
+		1, 2, 3, 4, 5, 6, 7, 8, // replay detection
+		0xaa, 0xbb,
+	}
+	require.Equal(t, expected, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Authentication -> protocol delayed authentication, algorithm 1, RDM 0, replay detection 72623859790382856, information [170 187]", o.String())
+}
+
+func TestParseOptAuthentication(t *testing.T) {
+	data := []byte{byte(OptionAuthentication), 11, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	o, err := ParseOptAuthentication(data)
+	require.NoError(t, err)
+	require.Equal(t, &OptAuthentication{ReplayDetection: 1, AuthenticationInformation: []byte{}}, o)
+	require.Equal(t, "configuration token", o.Protocol.String())
+
+	// Short byte stream
+	data = []byte{byte(OptionAuthentication), 11, 0, 0, 0}
+	_, err = ParseOptAuthentication(data)
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	data = []byte{54, 11, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	_, err = ParseOptAuthentication(data)
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	data = []byte{byte(OptionAuthentication), 10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	_, err = ParseOptAuthentication(data)
+	require.Error(t, err, "should get error from bad length")
+}
@@ -32,23 +32,24 @@ func (op *OptBootfileName) String() string {
 
 }
 
+// MarshalJSON implements json.Marshaler.
+func (op *OptBootfileName) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(op)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (op *OptBootfileName) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, op)
+}
+
 // ParseOptBootfileName returns a new OptBootfile from a byte stream or error if any
 func ParseOptBootfileName(data []byte) (*OptBootfileName, error) {
-	if len(data) < 3 {
-		return nil, ErrShortByteStream
-	}
-	code := OptionCode(data[0])
-	if code != OptionBootfileName {
-		return nil, fmt.Errorf("ParseOptBootfileName: invalid code: %v; want %v", code, OptionBootfileName)
-	}
-	length := int(data[1])
-	if length < 1 {
-		return nil, fmt.Errorf("Bootfile name has invalid length of %d", length)
+	buf, err := newOptionLexer(data, OptionBootfileName)
+	if err != nil {
+		return nil, err
 	}
-	bootFileNameData := data[2:]
-	if len(bootFileNameData) < length {
-		return nil, fmt.Errorf("ParseOptBootfileName: short data: %d bytes; want %d",
-			len(bootFileNameData), length)
+	if buf.Len() < 1 {
+		return nil, fmt.Errorf("Bootfile name has invalid length of %d", buf.Len())
 	}
-	return &OptBootfileName{BootfileName: bootFileNameData[:length]}, nil
+	return &OptBootfileName{BootfileName: buf.ReadAll()}, nil
 }
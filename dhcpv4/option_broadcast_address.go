@@ -5,10 +5,11 @@ import (
 	"net"
 )
 
-// This option implements the server identifier option
-// https://tools.ietf.org/html/rfc2132
+// This option implements the broadcast address option
+// https://tools.ietf.org/html/rfc2132#section-5.3
 
-// OptBroadcastAddress represents an option encapsulating the server identifier.
+// OptBroadcastAddress represents an option encapsulating the broadcast address
+// of the subnet of the client.
 type OptBroadcastAddress struct {
 	BroadcastAddress net.IP
 }
@@ -16,21 +17,14 @@ type OptBroadcastAddress struct {
 // ParseOptBroadcastAddress returns a new OptBroadcastAddress from a byte
 // stream, or error if any.
 func ParseOptBroadcastAddress(data []byte) (*OptBroadcastAddress, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionBroadcastAddress)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionBroadcastAddress {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionBroadcastAddress, code)
+	if buf.Len() != 4 {
+		return nil, fmt.Errorf("expected length 4, got %v instead", buf.Len())
 	}
-	length := int(data[1])
-	if length != 4 {
-		return nil, fmt.Errorf("unexepcted length: expected 4, got %v", length)
-	}
-	if len(data) < 6 {
-		return nil, ErrShortByteStream
-	}
-	return &OptBroadcastAddress{BroadcastAddress: net.IP(data[2 : 2+length])}, nil
+	return &OptBroadcastAddress{BroadcastAddress: net.IP(buf.Consume(4))}, nil
 }
 
 // Code returns the option code.
@@ -49,8 +43,45 @@ func (o *OptBroadcastAddress) String() string {
 	return fmt.Sprintf("Broadcast Address -> %v", o.BroadcastAddress.String())
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptBroadcastAddress) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptBroadcastAddress) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptBroadcastAddress) Length() int {
 	return len(o.BroadcastAddress.To4())
 }
+
+// InSubnet reports whether the broadcast address is the one of the subnet of
+// ip with the given mask, that is whether it is in the subnet and has all the
+// host bits set. Some networks use the all-zeros host address instead, as
+// allowed by RFC 1122, and it is accepted too.
+func (o *OptBroadcastAddress) InSubnet(ip net.IP, mask net.IPMask) bool {
+	subnet := &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+	if subnet.IP == nil || !subnet.Contains(o.BroadcastAddress) {
+		return false
+	}
+	bcast := o.BroadcastAddress.To4()
+	return bcast.Equal(SubnetBroadcastAddress(ip, mask)) || bcast.Equal(subnet.IP)
+}
+
+// SubnetBroadcastAddress returns the broadcast address of the subnet of ip with
+// the given mask, or nil if they are not IPv4.
+func SubnetBroadcastAddress(ip net.IP, mask net.IPMask) net.IP {
+	ip4 := ip.To4()
+	if ip4 == nil || len(mask) != net.IPv4len {
+		return nil
+	}
+	bcast := make(net.IP, net.IPv4len)
+	for i := range bcast {
+		bcast[i] = ip4[i] | ^mask[i]
+	}
+	return bcast
+}
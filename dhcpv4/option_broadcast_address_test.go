@@ -42,3 +42,28 @@ func TestParseOptBroadcastAddress(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, net.IP{192, 168, 0, 1}, o.BroadcastAddress)
 }
+
+func TestSubnetBroadcastAddress(t *testing.T) {
+	require.Equal(t, net.IP{192, 168, 0, 255}, SubnetBroadcastAddress(net.IPv4(192, 168, 0, 10), net.IPv4Mask(255, 255, 255, 0)))
+	require.Equal(t, net.IP{10, 0, 3, 255}, SubnetBroadcastAddress(net.IP{10, 0, 1, 1}, net.CIDRMask(22, 32)))
+	require.Nil(t, SubnetBroadcastAddress(net.ParseIP("2001:db8::1"), net.CIDRMask(24, 32)))
+	require.Nil(t, SubnetBroadcastAddress(net.IPv4(192, 168, 0, 10), net.CIDRMask(64, 128)))
+}
+
+func TestOptBroadcastAddressInSubnet(t *testing.T) {
+	ip, mask := net.IPv4(192, 168, 0, 10), net.IPv4Mask(255, 255, 255, 0)
+	for _, tc := range []struct {
+		bcast net.IP
+		want  bool
+	}{
+		{net.IP{192, 168, 0, 255}, true},
+		{net.IPv4(192, 168, 0, 255), true},
+		{net.IP{192, 168, 0, 0}, true},
+		{net.IP{192, 168, 0, 20}, false},
+		{net.IP{192, 168, 1, 255}, false},
+		{nil, false},
+	} {
+		o := OptBroadcastAddress{BroadcastAddress: tc.bcast}
+		require.Equal(t, tc.want, o.InSubnet(ip, mask), tc.bcast.String())
+	}
+}
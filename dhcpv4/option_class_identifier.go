@@ -2,6 +2,7 @@ package dhcpv4
 
 import (
 	"fmt"
+	"strings"
 )
 
 // This option implements the Class Identifier option
@@ -15,19 +16,11 @@ type OptClassIdentifier struct {
 // ParseOptClassIdentifier constructs an OptClassIdentifier struct from a sequence of
 // bytes and returns it, or an error.
 func ParseOptClassIdentifier(data []byte) (*OptClassIdentifier, error) {
-	// Should at least have code and length
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionClassIdentifier)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionClassIdentifier {
-		return nil, fmt.Errorf("expected option %v, got %v instead", OptionClassIdentifier, code)
-	}
-	length := int(data[1])
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
-	}
-	return &OptClassIdentifier{Identifier: string(data[2 : 2+length])}, nil
+	return &OptClassIdentifier{Identifier: string(buf.ReadAll())}, nil
 }
 
 // Code returns the option code.
@@ -45,8 +38,38 @@ func (o *OptClassIdentifier) String() string {
 	return fmt.Sprintf("Class Identifier -> %v", o.Identifier)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptClassIdentifier) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptClassIdentifier) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code and byte
 // for length, if any).
 func (o *OptClassIdentifier) Length() int {
 	return len(o.Identifier)
 }
+
+// VendorClass returns the identifier of the Class Identifier option of the
+// packet, or an empty string if there is none.
+func (d *DHCPv4) VendorClass() string {
+	if opt, ok := d.GetOneOption(OptionClassIdentifier).(*OptClassIdentifier); ok {
+		return opt.Identifier
+	}
+	return ""
+}
+
+// MatchVendorClass returns true if the class identifier class starts with
+// prefix, on a boundary between the colon-separated fields that the PXE
+// clients, among others, use. For instance, "PXEClient:Arch:00007" matches
+// "PXEClient:Arch:00007:UNDI:003016" but not "PXEClient:Arch:000070".
+func MatchVendorClass(class, prefix string) bool {
+	if !strings.HasPrefix(class, prefix) {
+		return false
+	}
+	return prefix == "" || len(class) == len(prefix) || strings.HasSuffix(prefix, ":") || class[len(prefix)] == ':'
+}
@@ -39,3 +39,33 @@ func TestOptClassIdentifierString(t *testing.T) {
 	o := OptClassIdentifier{Identifier: "testy test"}
 	require.Equal(t, "Class Identifier -> testy test", o.String())
 }
+
+func TestVendorClass(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	require.Equal(t, "", d.VendorClass())
+	d = WithVendorClass("PXEClient")(d)
+	require.Equal(t, "PXEClient", d.VendorClass())
+	// the option is replaced, not added
+	d = WithVendorClass("HTTPClient")(d)
+	require.Equal(t, "HTTPClient", d.VendorClass())
+	require.Len(t, d.GetOption(OptionClassIdentifier), 1)
+}
+
+func TestMatchVendorClass(t *testing.T) {
+	for _, tt := range []struct {
+		class, prefix string
+		match         bool
+	}{
+		{"PXEClient:Arch:00007:UNDI:003016", "PXEClient", true},
+		{"PXEClient:Arch:00007:UNDI:003016", "PXEClient:Arch:00007", true},
+		{"PXEClient:Arch:00007:UNDI:003016", "PXEClient:Arch:", true},
+		{"PXEClient:Arch:000070", "PXEClient:Arch:00007", false},
+		{"PXEClient:Arch:00009", "PXEClient:Arch:00007", false},
+		{"PXEClient", "PXEClient", true},
+		{"PXE", "PXEClient", false},
+		{"anything", "", true},
+	} {
+		require.Equal(t, tt.match, MatchVendorClass(tt.class, tt.prefix), "%q %q", tt.class, tt.prefix)
+	}
+}
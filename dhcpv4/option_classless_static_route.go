@@ -0,0 +1,173 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// This option implements the Classless Static Route option
+// https://tools.ietf.org/html/rfc3442
+
+// OptClasslessStaticRoute represents the Classless Static Route option. Each
+// route is encoded as the prefix length of the destination, followed by the
+// significant octets of the destination and by the router, so that the
+// network 10.0.0.0/8 only takes one octet. The destinations are masked when
+// serialized and parsed.
+//
+// As RFC 3442 mandates, a client that receives this option ignores the router
+// and static route options, see DHCPv4.Routes.
+//
+// The Microsoft DHCP clients and servers use the same format in the private
+// option 249, which the option is serialized as if Microsoft is true. See
+// WithClasslessStaticRoutes to send both.
+type OptClasslessStaticRoute struct {
+	Routes    []Route
+	Microsoft bool
+}
+
+// ParseOptClasslessStaticRoute returns a new OptClasslessStaticRoute from a
+// byte stream, or error if any.
+func ParseOptClasslessStaticRoute(data []byte) (*OptClasslessStaticRoute, error) {
+	return parseOptClasslessStaticRoute(data, OptionClasslessStaticRouteOption)
+}
+
+// ParseOptMicrosoftClasslessStaticRoute returns a new OptClasslessStaticRoute
+// from a byte stream of the Microsoft variant of the option, or error if any.
+func ParseOptMicrosoftClasslessStaticRoute(data []byte) (*OptClasslessStaticRoute, error) {
+	return parseOptClasslessStaticRoute(data, OptionMicrosoftClasslessStaticRoute)
+}
+
+func parseOptClasslessStaticRoute(data []byte, code OptionCode) (*OptClasslessStaticRoute, error) {
+	buf, err := newOptionLexer(data, code)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() < 5 {
+		return nil, fmt.Errorf("Invalid length: expected at least 5, got %v", buf.Len())
+	}
+	routes, err := parseClasslessStaticRoutes(buf.ReadAll())
+	if err != nil {
+		return nil, err
+	}
+	return &OptClasslessStaticRoute{Routes: routes, Microsoft: code == OptionMicrosoftClasslessStaticRoute}, nil
+}
+
+// Code returns the option code.
+func (o *OptClasslessStaticRoute) Code() OptionCode {
+	if o.Microsoft {
+		return OptionMicrosoftClasslessStaticRoute
+	}
+	return OptionClasslessStaticRouteOption
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptClasslessStaticRoute) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length())}
+	for _, r := range o.Routes {
+		width := routeWidth(r.Dest)
+		dest := r.Dest.IP.To4().Mask(net.CIDRMask(width, 32))
+		ret = append(ret, byte(width))
+		ret = append(ret, dest[:(width+7)/8]...)
+		ret = append(ret, r.Router.To4()...)
+	}
+	return ret
+}
+
+// String returns a human-readable string.
+func (o *OptClasslessStaticRoute) String() string {
+	routes := make([]string, 0, len(o.Routes))
+	for _, r := range o.Routes {
+		routes = append(routes, r.String())
+	}
+	name := "Classless Static Route"
+	if o.Microsoft {
+		name = "Microsoft Classless Static Route"
+	}
+	return fmt.Sprintf("%s -> %v", name, strings.Join(routes, ", "))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptClasslessStaticRoute) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptClasslessStaticRoute) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code an byte
+// length).
+func (o *OptClasslessStaticRoute) Length() int {
+	var length int
+	for _, r := range o.Routes {
+		length += 1 + (routeWidth(r.Dest)+7)/8 + 4
+	}
+	return length
+}
+
+// routeWidth returns the prefix length of an IPv4 destination, whose mask may
+// be in its 16-byte form.
+func routeWidth(dest *net.IPNet) int {
+	ones, bits := dest.Mask.Size()
+	if bits == 8*net.IPv6len {
+		ones -= 8 * (net.IPv6len - net.IPv4len)
+	}
+	if ones < 0 {
+		return 0
+	}
+	return ones
+}
+
+// ClasslessStaticRoute returns the routes from the OptClasslessStaticRoute
+// option, or from its Microsoft variant if it is not present, or nil if
+// neither is. Use Routes to get the routes that the client has to install,
+// which takes the router option into account.
+func (d *DHCPv4) ClasslessStaticRoute() []Route {
+	opt, ok := d.classlessStaticRouteOption().(*OptClasslessStaticRoute)
+	if !ok {
+		return nil
+	}
+	return opt.Routes
+}
+
+// classlessStaticRouteOption returns the classless static route option, or its
+// Microsoft variant if it is not present, or nil if neither is.
+func (d *DHCPv4) classlessStaticRouteOption() Option {
+	if opt := d.GetOneOption(OptionClasslessStaticRouteOption); opt != nil {
+		return opt
+	}
+	return d.GetOneOption(OptionMicrosoftClasslessStaticRoute)
+}
+
+// ClasslessRouteOptions selects the options carrying the classless static
+// routes of a reply.
+type ClasslessRouteOptions int
+
+// The choices of ClasslessRouteOptions.
+const (
+	// ClasslessRouteStandard sends the option 121 of RFC 3442 only.
+	ClasslessRouteStandard ClasslessRouteOptions = iota
+	// ClasslessRouteMicrosoft sends the option 249 only, for the Windows
+	// clients older than Vista.
+	ClasslessRouteMicrosoft
+	// ClasslessRouteBoth sends both options, for networks with both kinds
+	// of clients.
+	ClasslessRouteBoth
+)
+
+// RequestedClasslessRouteOptions returns the options carrying the classless
+// static routes that the client asks for in its parameter request list: the
+// option 249 if it only asks for that one, and the option 121 otherwise.
+func RequestedClasslessRouteOptions(request *DHCPv4) ClasslessRouteOptions {
+	standard := request.IsOptionRequested(OptionClasslessStaticRouteOption)
+	microsoft := request.IsOptionRequested(OptionMicrosoftClasslessStaticRoute)
+	switch {
+	case standard && microsoft:
+		return ClasslessRouteBoth
+	case microsoft:
+		return ClasslessRouteMicrosoft
+	}
+	return ClasslessRouteStandard
+}
@@ -0,0 +1,160 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptClasslessStaticRoute(t *testing.T) {
+	data := []byte{
+		byte(OptionClasslessStaticRouteOption), 19,
+		0, 192, 168, 0, 1,
+		8, 10, 192, 168, 0, 2,
+		20, 172, 16, 240, 192, 168, 0, 3,
+	}
+	opt, err := ParseOptClasslessStaticRoute(data)
+	require.NoError(t, err)
+	require.Equal(t, []Route{
+		{Dest: mustParseCIDR(t, "0.0.0.0/0"), Router: net.IP{192, 168, 0, 1}},
+		{Dest: mustParseCIDR(t, "10.0.0.0/8"), Router: net.IP{192, 168, 0, 2}},
+		{Dest: mustParseCIDR(t, "172.16.240.0/20"), Router: net.IP{192, 168, 0, 3}},
+	}, opt.Routes)
+	require.Equal(t, OptionClasslessStaticRouteOption, opt.Code())
+	require.Equal(t, 19, opt.Length())
+	require.Equal(t, data, opt.ToBytes())
+	require.Equal(t, "Classless Static Route -> 0.0.0.0/0 via 192.168.0.1, 10.0.0.0/8 via 192.168.0.2, 172.16.240.0/20 via 192.168.0.3", opt.String())
+
+	// wrong code
+	_, err = ParseOptClasslessStaticRoute([]byte{54, 5, 0, 192, 168, 0, 1})
+	require.Error(t, err)
+	// no route
+	_, err = ParseOptClasslessStaticRoute([]byte{byte(OptionClasslessStaticRouteOption), 0})
+	require.Error(t, err)
+	// short byte stream
+	_, err = ParseOptClasslessStaticRoute([]byte{byte(OptionClasslessStaticRouteOption), 7, 8, 10, 192, 168, 0, 2})
+	require.Error(t, err)
+	// truncated route
+	_, err = ParseOptClasslessStaticRoute([]byte{byte(OptionClasslessStaticRouteOption), 6, 16, 10, 0, 192, 168, 0})
+	require.Error(t, err)
+}
+
+func TestOptClasslessStaticRouteToBytes(t *testing.T) {
+	// the destinations are masked, and the masks may be in their 16-byte form
+	opt := OptClasslessStaticRoute{Routes: []Route{
+		{Dest: &net.IPNet{IP: net.IPv4(10, 1, 2, 3), Mask: net.CIDRMask(24, 32)}, Router: net.IPv4(192, 168, 0, 1)},
+		{Dest: &net.IPNet{IP: net.IPv4(10, 9, 9, 9), Mask: net.CIDRMask(96+9, 128)}, Router: net.IPv4(192, 168, 0, 2)},
+	}}
+	require.Equal(t, []byte{
+		byte(OptionClasslessStaticRouteOption), 15,
+		24, 10, 1, 2, 192, 168, 0, 1,
+		9, 10, 0, 192, 168, 0, 2,
+	}, opt.ToBytes())
+}
+
+func TestClasslessStaticRoute(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	require.Nil(t, d.ClasslessStaticRoute())
+
+	routes := []Route{{Dest: mustParseCIDR(t, "10.0.0.0/8"), Router: net.IP{192, 168, 0, 2}}}
+	d.UpdateOption(&OptClasslessStaticRoute{Routes: routes})
+	parsed, err := FromBytes(d.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, routes, parsed.ClasslessStaticRoute())
+}
+
+func TestClasslessStaticRouteLong(t *testing.T) {
+	// 60 routes to /32 destinations take more than 255 bytes
+	var routes []Route
+	for i := 0; i < 60; i++ {
+		routes = append(routes, Route{
+			Dest:   &net.IPNet{IP: net.IP{10, 0, 0, byte(i)}, Mask: net.CIDRMask(32, 32)},
+			Router: net.IP{192, 168, 0, 1},
+		})
+	}
+	d, err := New()
+	require.NoError(t, err)
+	d.UpdateOption(&OptClasslessStaticRoute{Routes: routes})
+	parsed, err := FromBytes(d.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, routes, parsed.ClasslessStaticRoute())
+}
+
+func TestParseOptMicrosoftClasslessStaticRoute(t *testing.T) {
+	data := []byte{byte(OptionMicrosoftClasslessStaticRoute), 5, 0, 192, 168, 0, 1}
+	opt, err := ParseOptMicrosoftClasslessStaticRoute(data)
+	require.NoError(t, err)
+	require.True(t, opt.Microsoft)
+	require.Equal(t, OptionMicrosoftClasslessStaticRoute, opt.Code())
+	require.Equal(t, data, opt.ToBytes())
+	require.Equal(t, "Microsoft Classless Static Route -> 0.0.0.0/0 via 192.168.0.1", opt.String())
+
+	parsed, err := ParseOption(data)
+	require.NoError(t, err)
+	require.Equal(t, opt, parsed)
+
+	// the two codes are not interchangeable
+	_, err = ParseOptMicrosoftClasslessStaticRoute([]byte{byte(OptionClasslessStaticRouteOption), 5, 0, 192, 168, 0, 1})
+	require.Error(t, err)
+	_, err = ParseOptClasslessStaticRoute(data)
+	require.Error(t, err)
+}
+
+func TestWithClasslessStaticRoutes(t *testing.T) {
+	routes := []Route{{Dest: mustParseCIDR(t, "10.0.0.0/8"), Router: net.IP{192, 168, 0, 2}}}
+	for _, tc := range []struct {
+		which     ClasslessRouteOptions
+		standard  bool
+		microsoft bool
+	}{
+		{ClasslessRouteStandard, true, false},
+		{ClasslessRouteMicrosoft, false, true},
+		{ClasslessRouteBoth, true, true},
+	} {
+		d, err := New()
+		require.NoError(t, err)
+		d = WithClasslessStaticRoutes(tc.which, routes...)(d)
+		require.Equal(t, tc.standard, d.GetOneOption(OptionClasslessStaticRouteOption) != nil)
+		require.Equal(t, tc.microsoft, d.GetOneOption(OptionMicrosoftClasslessStaticRoute) != nil)
+		require.Equal(t, routes, d.ClasslessStaticRoute())
+	}
+}
+
+func TestRequestedClasslessRouteOptions(t *testing.T) {
+	for _, tc := range []struct {
+		prl  []OptionCode
+		want ClasslessRouteOptions
+	}{
+		{nil, ClasslessRouteStandard},
+		{[]OptionCode{OptionRouter, OptionClasslessStaticRouteOption}, ClasslessRouteStandard},
+		{[]OptionCode{OptionRouter, OptionMicrosoftClasslessStaticRoute}, ClasslessRouteMicrosoft},
+		{[]OptionCode{OptionClasslessStaticRouteOption, OptionMicrosoftClasslessStaticRoute}, ClasslessRouteBoth},
+	} {
+		d, err := New()
+		require.NoError(t, err)
+		if tc.prl != nil {
+			d = WithRequestedOptions(tc.prl...)(d)
+		}
+		require.Equal(t, tc.want, RequestedClasslessRouteOptions(d), "%v", tc.prl)
+	}
+}
+
+func TestRoutesMicrosoftClasslessStaticRoute(t *testing.T) {
+	// a Windows server only sends the Microsoft variant, which replaces the
+	// router option like the standard one
+	d, err := New()
+	require.NoError(t, err)
+	d = WithRouter(net.IP{192, 168, 0, 254})(d)
+	d = WithClasslessStaticRoutes(ClasslessRouteMicrosoft, Route{Dest: mustParseCIDR(t, "0.0.0.0/0"), Router: net.IP{192, 168, 0, 1}})(d)
+	routes, err := d.Routes()
+	require.NoError(t, err)
+	require.Equal(t, []Route{{Dest: mustParseCIDR(t, "0.0.0.0/0"), Router: net.IP{192, 168, 0, 1}}}, routes)
+
+	// the standard option takes precedence
+	d = WithClasslessStaticRoutes(ClasslessRouteStandard, Route{Dest: mustParseCIDR(t, "0.0.0.0/0"), Router: net.IP{192, 168, 0, 3}})(d)
+	routes, err = d.Routes()
+	require.NoError(t, err)
+	require.Equal(t, net.IP{192, 168, 0, 3}, routes[0].Router)
+}
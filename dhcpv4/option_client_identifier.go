@@ -0,0 +1,145 @@
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/insomniacslk/dhcp/uio"
+)
+
+// This option implements the client identifier option, in the classic form of
+// RFC 2132 and in the node-specific form of RFC 4361, which holds an IAID and
+// a DUID as DHCPv6 clients use.
+// https://tools.ietf.org/html/rfc2132#section-9.14
+// https://tools.ietf.org/html/rfc4361#section-6.1
+
+// ClientIdentifierNodeSpecific is the type of the node-specific client
+// identifiers of RFC 4361.
+const ClientIdentifierNodeSpecific uint8 = 255
+
+// OptClientIdentifier represents the client identifier option.
+type OptClientIdentifier struct {
+	// Type is the hardware type of Identifier when it is a hardware address,
+	// 0 for other identifiers, or ClientIdentifierNodeSpecific.
+	Type uint8
+	// Identifier is the identifier, unless Type is
+	// ClientIdentifierNodeSpecific.
+	Identifier []byte
+	// IAID and DUID are the identifier if Type is
+	// ClientIdentifierNodeSpecific.
+	IAID uint32
+	DUID *dhcpv6.Duid
+}
+
+// NewClientIdentifierFromHwAddr returns a client identifier holding an
+// Ethernet hardware address, as most clients send.
+func NewClientIdentifierFromHwAddr(hwaddr net.HardwareAddr) *OptClientIdentifier {
+	return &OptClientIdentifier{
+		Type:       uint8(iana.HwTypeEthernet),
+		Identifier: hwaddr,
+	}
+}
+
+// NewNodeSpecificClientIdentifier returns a RFC 4361 client identifier,
+// holding the IAID and the DUID that the client also uses for DHCPv6.
+func NewNodeSpecificClientIdentifier(iaid uint32, duid dhcpv6.Duid) *OptClientIdentifier {
+	return &OptClientIdentifier{
+		Type: ClientIdentifierNodeSpecific,
+		IAID: iaid,
+		DUID: &duid,
+	}
+}
+
+// ParseOptClientIdentifier constructs an OptClientIdentifier struct from a
+// sequence of bytes and returns it, or an error.
+func ParseOptClientIdentifier(data []byte) (*OptClientIdentifier, error) {
+	buf, err := newOptionLexer(data, OptionClientIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	// Should at least have the type and one byte of identifier.
+	if buf.Len() < 2 {
+		return nil, fmt.Errorf("expected length of at least 2, got %v instead", buf.Len())
+	}
+	return parseClientIdentifier(buf.ReadAll())
+}
+
+// parseClientIdentifier parses the data of a client identifier option.
+func parseClientIdentifier(data []byte) (*OptClientIdentifier, error) {
+	buf := uio.NewBigEndianBuffer(data)
+	opt := OptClientIdentifier{Type: buf.Read8()}
+	if err := buf.Error(); err != nil {
+		return nil, err
+	}
+	if opt.Type != ClientIdentifierNodeSpecific {
+		opt.Identifier = buf.ReadAll()
+		return &opt, nil
+	}
+	if !buf.Has(4 + 2) {
+		return nil, fmt.Errorf("node-specific client identifier too short: %d bytes", len(data))
+	}
+	opt.IAID = buf.Read32()
+	duid, err := dhcpv6.DuidFromBytes(buf.ReadAll())
+	if err != nil {
+		return nil, err
+	}
+	opt.DUID = duid
+	return &opt, nil
+}
+
+// Code returns the option code.
+func (o *OptClientIdentifier) Code() OptionCode {
+	return OptionClientIdentifier
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptClientIdentifier) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length()), o.Type}
+	if o.Type != ClientIdentifierNodeSpecific {
+		return append(ret, o.Identifier...)
+	}
+	var iaid [4]byte
+	binary.BigEndian.PutUint32(iaid[:], o.IAID)
+	ret = append(ret, iaid[:]...)
+	if o.DUID != nil {
+		ret = append(ret, o.DUID.ToBytes()...)
+	}
+	return ret
+}
+
+// String returns a human-readable string for this option.
+func (o *OptClientIdentifier) String() string {
+	if o.Type == ClientIdentifierNodeSpecific {
+		return fmt.Sprintf("Client identifier -> IAID %d, %v", o.IAID, o.DUID)
+	}
+	if o.Type == uint8(iana.HwTypeEthernet) {
+		return fmt.Sprintf("Client identifier -> %v", net.HardwareAddr(o.Identifier))
+	}
+	return fmt.Sprintf("Client identifier -> type %d, %v", o.Type, o.Identifier)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptClientIdentifier) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptClientIdentifier) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptClientIdentifier) Length() int {
+	if o.Type != ClientIdentifierNodeSpecific {
+		return 1 + len(o.Identifier)
+	}
+	length := 1 + 4
+	if o.DUID != nil {
+		length += o.DUID.Length()
+	}
+	return length
+}
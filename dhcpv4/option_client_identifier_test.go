@@ -0,0 +1,67 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptClientIdentifierInterfaceMethods(t *testing.T) {
+	o := NewClientIdentifierFromHwAddr(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.Equal(t, OptionClientIdentifier, o.Code(), "Code")
+	require.Equal(t, 7, o.Length(), "Length")
+	require.Equal(t, []byte{byte(OptionClientIdentifier), 7, 1, 1, 2, 3, 4, 5, 6}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Client identifier -> 01:02:03:04:05:06", o.String())
+}
+
+func TestOptClientIdentifierNodeSpecific(t *testing.T) {
+	duid := dhcpv6.Duid{
+		Type:          dhcpv6.DUID_LL,
+		HwType:        iana.HwTypeEthernet,
+		LinkLayerAddr: net.HardwareAddr{1, 2, 3, 4, 5, 6},
+	}
+	o := NewNodeSpecificClientIdentifier(0xaabbccdd, duid)
+	expected := []byte{
+		byte(OptionClientIdentifier), 15,
+		255,                    // node-specific
+		0xaa, 0xbb, 0xcc, 0xdd, // IAID
+		0, 3, 0, 1, 1, 2, 3, 4, 5, 6, // DUID-LL
+	}
+	require.Equal(t, expected, o.ToBytes())
+	require.Equal(t, "Client identifier -> IAID 2864434397, DUID{type=DUID-LL hwtype=Ethernet hwaddr=01:02:03:04:05:06}", o.String())
+
+	parsed, err := ParseOptClientIdentifier(expected)
+	require.NoError(t, err)
+	require.Equal(t, o, parsed)
+}
+
+func TestParseOptClientIdentifier(t *testing.T) {
+	data := []byte{byte(OptionClientIdentifier), 4, 0, 'f', 'o', 'o'}
+	o, err := ParseOptClientIdentifier(data)
+	require.NoError(t, err)
+	require.Equal(t, &OptClientIdentifier{Type: 0, Identifier: []byte("foo")}, o)
+	require.Equal(t, "Client identifier -> type 0, [102 111 111]", o.String())
+
+	// Short byte stream
+	data = []byte{byte(OptionClientIdentifier), 2, 1}
+	_, err = ParseOptClientIdentifier(data)
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	data = []byte{54, 2, 1, 1}
+	_, err = ParseOptClientIdentifier(data)
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	data = []byte{byte(OptionClientIdentifier), 1, 1, 1}
+	_, err = ParseOptClientIdentifier(data)
+	require.Error(t, err, "should get error from bad length")
+
+	// Truncated node-specific identifier
+	data = []byte{byte(OptionClientIdentifier), 4, 255, 0, 0, 0}
+	_, err = ParseOptClientIdentifier(data)
+	require.Error(t, err, "should get error from short node-specific identifier")
+}
@@ -0,0 +1,93 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the Client Machine Identifier option
+// https://tools.ietf.org/html/rfc4578#section-2.3
+
+// MachineIdentifierUUID is the only machine identifier type defined by RFC
+// 4578, a 16 bytes UUID.
+const MachineIdentifierUUID uint8 = 0
+
+// OptClientMachineIdentifier represents the Client Machine Identifier option,
+// which carries the UUID of a PXE client, usually the SMBIOS one. The
+// identifier is kept as is, since some clients send identifiers of other
+// lengths.
+type OptClientMachineIdentifier struct {
+	Type       uint8
+	Identifier []byte
+}
+
+// NewMachineUUID returns a Client Machine Identifier option carrying a UUID.
+func NewMachineUUID(uuid [16]byte) *OptClientMachineIdentifier {
+	return &OptClientMachineIdentifier{Type: MachineIdentifierUUID, Identifier: uuid[:]}
+}
+
+// ParseOptClientMachineIdentifier returns a new OptClientMachineIdentifier from
+// a byte stream, or error if any.
+func ParseOptClientMachineIdentifier(data []byte) (*OptClientMachineIdentifier, error) {
+	buf, err := newOptionLexer(data, OptionClientMachineIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() < 1 {
+		return nil, fmt.Errorf("expected length at least 1, got %v instead", buf.Len())
+	}
+	return &OptClientMachineIdentifier{
+		Type:       buf.Read8(),
+		Identifier: buf.ReadAll(),
+	}, nil
+}
+
+// UUID returns the UUID carried by the option, and false if the option does
+// not carry one.
+func (o *OptClientMachineIdentifier) UUID() ([16]byte, bool) {
+	var uuid [16]byte
+	if o.Type != MachineIdentifierUUID || len(o.Identifier) != len(uuid) {
+		return uuid, false
+	}
+	copy(uuid[:], o.Identifier)
+	return uuid, true
+}
+
+// Code returns the option code.
+func (o *OptClientMachineIdentifier) Code() OptionCode {
+	return OptionClientMachineIdentifier
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptClientMachineIdentifier) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length()), o.Type}
+	return append(ret, o.Identifier...)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptClientMachineIdentifier) String() string {
+	if uuid, ok := o.UUID(); ok {
+		return fmt.Sprintf("Client Machine Identifier -> %s", formatUUID(uuid))
+	}
+	return fmt.Sprintf("Client Machine Identifier -> type %d %v", o.Type, o.Identifier)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptClientMachineIdentifier) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptClientMachineIdentifier) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptClientMachineIdentifier) Length() int {
+	return 1 + len(o.Identifier)
+}
+
+// formatUUID returns the canonical textual form of a UUID.
+func formatUUID(u [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
@@ -0,0 +1,50 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testMachineUUID = [16]byte{
+	0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+	0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+}
+
+func TestOptClientMachineIdentifierInterfaceMethods(t *testing.T) {
+	o := NewMachineUUID(testMachineUUID)
+	require.Equal(t, OptionClientMachineIdentifier, o.Code(), "Code")
+	require.Equal(t, 17, o.Length(), "Length")
+	require.Equal(t, append([]byte{97, 17, 0}, testMachineUUID[:]...), o.ToBytes(), "ToBytes")
+	require.Equal(t, "Client Machine Identifier -> 01234567-89ab-cdef-0123-456789abcdef", o.String())
+
+	o = &OptClientMachineIdentifier{Type: 1, Identifier: []byte{1, 2}}
+	require.Equal(t, "Client Machine Identifier -> type 1 [1 2]", o.String())
+}
+
+func TestParseOptClientMachineIdentifier(t *testing.T) {
+	o, err := ParseOptClientMachineIdentifier(append([]byte{97, 17, 0}, testMachineUUID[:]...))
+	require.NoError(t, err)
+	uuid, ok := o.UUID()
+	require.True(t, ok)
+	require.Equal(t, testMachineUUID, uuid)
+
+	// identifiers that are not UUIDs are kept as is
+	o, err = ParseOptClientMachineIdentifier([]byte{97, 3, 0, 1, 2})
+	require.NoError(t, err)
+	require.Equal(t, &OptClientMachineIdentifier{Type: 0, Identifier: []byte{1, 2}}, o)
+	_, ok = o.UUID()
+	require.False(t, ok)
+
+	// Short byte stream
+	_, err = ParseOptClientMachineIdentifier([]byte{97, 17, 0})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptClientMachineIdentifier([]byte{94, 1, 0})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Missing type
+	_, err = ParseOptClientMachineIdentifier([]byte{97, 0})
+	require.Error(t, err, "should get error from bad length")
+}
@@ -0,0 +1,72 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the Client Network Interface Identifier option
+// https://tools.ietf.org/html/rfc4578#section-2.2
+
+// NDITypeUNDI is the only network interface type defined by RFC 4578, the
+// Universal Network Device Interface of the PXE specification.
+const NDITypeUNDI uint8 = 1
+
+// OptClientNDI represents the Client Network Interface Identifier option,
+// which tells the type and version of the network interface of a PXE client.
+type OptClientNDI struct {
+	Type  uint8
+	Major uint8
+	Minor uint8
+}
+
+// ParseOptClientNDI returns a new OptClientNDI from a byte stream, or error if
+// any.
+func ParseOptClientNDI(data []byte) (*OptClientNDI, error) {
+	buf, err := newOptionLexer(data, OptionClientNetworkInterfaceIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 3 {
+		return nil, fmt.Errorf("expected length 3, got %v instead", buf.Len())
+	}
+	return &OptClientNDI{
+		Type:  buf.Read8(),
+		Major: buf.Read8(),
+		Minor: buf.Read8(),
+	}, nil
+}
+
+// Code returns the option code.
+func (o *OptClientNDI) Code() OptionCode {
+	return OptionClientNetworkInterfaceIdentifier
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptClientNDI) ToBytes() []byte {
+	return []byte{byte(o.Code()), byte(o.Length()), o.Type, o.Major, o.Minor}
+}
+
+// String returns a human-readable string for this option.
+func (o *OptClientNDI) String() string {
+	typ := fmt.Sprintf("type %d", o.Type)
+	if o.Type == NDITypeUNDI {
+		typ = "UNDI"
+	}
+	return fmt.Sprintf("Client Network Interface Identifier -> %s %d.%d", typ, o.Major, o.Minor)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptClientNDI) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptClientNDI) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptClientNDI) Length() int {
+	return 3
+}
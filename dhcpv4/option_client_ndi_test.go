@@ -0,0 +1,36 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptClientNDIInterfaceMethods(t *testing.T) {
+	o := OptClientNDI{Type: NDITypeUNDI, Major: 2, Minor: 1}
+	require.Equal(t, OptionClientNetworkInterfaceIdentifier, o.Code(), "Code")
+	require.Equal(t, 3, o.Length(), "Length")
+	require.Equal(t, []byte{94, 3, 1, 2, 1}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Client Network Interface Identifier -> UNDI 2.1", o.String())
+
+	o.Type = 7
+	require.Equal(t, "Client Network Interface Identifier -> type 7 2.1", o.String())
+}
+
+func TestParseOptClientNDI(t *testing.T) {
+	o, err := ParseOptClientNDI([]byte{94, 3, 1, 3, 0})
+	require.NoError(t, err)
+	require.Equal(t, &OptClientNDI{Type: NDITypeUNDI, Major: 3, Minor: 0}, o)
+
+	// Short byte stream
+	_, err = ParseOptClientNDI([]byte{94, 3, 1})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptClientNDI([]byte{93, 3, 1, 2, 1})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Wrong length
+	_, err = ParseOptClientNDI([]byte{94, 2, 1, 2})
+	require.Error(t, err, "should get error from bad length")
+}
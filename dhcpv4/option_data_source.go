@@ -0,0 +1,78 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the data source option
+// https://tools.ietf.org/html/rfc6926
+
+// dataSourceRemote is the flag of the data source option telling that the
+// information comes from a partner server.
+const dataSourceRemote = 0x01
+
+// OptDataSource represents the source of the information about a lease in a
+// bulk leasequery reply. Flags holds the raw value of the option, of which
+// only the REMOTE bit is defined.
+type OptDataSource struct {
+	Flags uint8
+}
+
+// ParseOptDataSource constructs an OptDataSource struct from a sequence of
+// bytes and returns it, or an error.
+func ParseOptDataSource(data []byte) (*OptDataSource, error) {
+	buf, err := newOptionLexer(data, OptionDataSource)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 1 {
+		return nil, fmt.Errorf("expected length 1, got %v instead", buf.Len())
+	}
+	return &OptDataSource{Flags: buf.Read8()}, nil
+}
+
+// Remote returns true if the information comes from a partner server rather
+// than from the server answering the query.
+func (o *OptDataSource) Remote() bool {
+	return o.Flags&dataSourceRemote != 0
+}
+
+// SetRemote sets or clears the REMOTE flag.
+func (o *OptDataSource) SetRemote(remote bool) {
+	if remote {
+		o.Flags |= dataSourceRemote
+	} else {
+		o.Flags &^= dataSourceRemote
+	}
+}
+
+// Code returns the option code.
+func (o *OptDataSource) Code() OptionCode {
+	return OptionDataSource
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptDataSource) ToBytes() []byte {
+	return []byte{byte(o.Code()), byte(o.Length()), o.Flags}
+}
+
+// String returns a human-readable string for this option.
+func (o *OptDataSource) String() string {
+	return fmt.Sprintf("Data Source -> remote=%v", o.Remote())
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptDataSource) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptDataSource) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptDataSource) Length() int {
+	return 1
+}
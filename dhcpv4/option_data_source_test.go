@@ -0,0 +1,37 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptDataSourceInterfaceMethods(t *testing.T) {
+	o := OptDataSource{}
+	o.SetRemote(true)
+	require.Equal(t, OptionDataSource, o.Code(), "Code")
+	require.Equal(t, 1, o.Length(), "Length")
+	require.Equal(t, []byte{157, 1, 1}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Data Source -> remote=true", o.String())
+	o.SetRemote(false)
+	require.False(t, o.Remote())
+}
+
+func TestParseOptDataSource(t *testing.T) {
+	o, err := ParseOptDataSource([]byte{157, 1, 0x81})
+	require.NoError(t, err)
+	require.True(t, o.Remote())
+	require.Equal(t, uint8(0x81), o.Flags)
+
+	// Short byte stream
+	_, err = ParseOptDataSource([]byte{157, 1})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptDataSource([]byte{156, 1, 1})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	_, err = ParseOptDataSource([]byte{157, 2, 1, 1})
+	require.Error(t, err, "should get error from bad length")
+}
@@ -0,0 +1,91 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the DHCP state option
+// https://tools.ietf.org/html/rfc6926
+
+// DHCPState is the state of a lease, as reported by bulk leasequery.
+type DHCPState uint8
+
+// Lease states as defined by RFC 6926
+const (
+	DHCPStateAvailable     DHCPState = 1
+	DHCPStateActive        DHCPState = 2
+	DHCPStateExpired       DHCPState = 3
+	DHCPStateReleased      DHCPState = 4
+	DHCPStateAbandoned     DHCPState = 5
+	DHCPStateReset         DHCPState = 6
+	DHCPStateRemote        DHCPState = 7
+	DHCPStateTransitioning DHCPState = 8
+)
+
+// DHCPStateToString maps lease states to their names.
+var DHCPStateToString = map[DHCPState]string{
+	DHCPStateAvailable:     "AVAILABLE",
+	DHCPStateActive:        "ACTIVE",
+	DHCPStateExpired:       "EXPIRED",
+	DHCPStateReleased:      "RELEASED",
+	DHCPStateAbandoned:     "ABANDONED",
+	DHCPStateReset:         "RESET",
+	DHCPStateRemote:        "REMOTE",
+	DHCPStateTransitioning: "TRANSITIONING",
+}
+
+func (s DHCPState) String() string {
+	if name, ok := DHCPStateToString[s]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// OptDHCPState represents the state of a lease.
+type OptDHCPState struct {
+	State DHCPState
+}
+
+// ParseOptDHCPState constructs an OptDHCPState struct from a sequence of
+// bytes and returns it, or an error.
+func ParseOptDHCPState(data []byte) (*OptDHCPState, error) {
+	buf, err := newOptionLexer(data, OptionDHCPState)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 1 {
+		return nil, fmt.Errorf("expected length 1, got %v instead", buf.Len())
+	}
+	return &OptDHCPState{State: DHCPState(buf.Read8())}, nil
+}
+
+// Code returns the option code.
+func (o *OptDHCPState) Code() OptionCode {
+	return OptionDHCPState
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptDHCPState) ToBytes() []byte {
+	return []byte{byte(o.Code()), byte(o.Length()), byte(o.State)}
+}
+
+// String returns a human-readable string for this option.
+func (o *OptDHCPState) String() string {
+	return fmt.Sprintf("DHCP State -> %v", o.State)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptDHCPState) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptDHCPState) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptDHCPState) Length() int {
+	return 1
+}
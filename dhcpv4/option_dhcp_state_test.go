@@ -0,0 +1,33 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptDHCPStateInterfaceMethods(t *testing.T) {
+	o := OptDHCPState{State: DHCPStateActive}
+	require.Equal(t, OptionDHCPState, o.Code(), "Code")
+	require.Equal(t, 1, o.Length(), "Length")
+	require.Equal(t, []byte{156, 1, 2}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "DHCP State -> ACTIVE", o.String())
+}
+
+func TestParseOptDHCPState(t *testing.T) {
+	o, err := ParseOptDHCPState([]byte{156, 1, 5})
+	require.NoError(t, err)
+	require.Equal(t, &OptDHCPState{State: DHCPStateAbandoned}, o)
+
+	// Short byte stream
+	_, err = ParseOptDHCPState([]byte{156, 1})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptDHCPState([]byte{157, 1, 1})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	_, err = ParseOptDHCPState([]byte{156, 2, 1, 1})
+	require.Error(t, err, "should get error from bad length")
+}
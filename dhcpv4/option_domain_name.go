@@ -13,18 +13,11 @@ type OptDomainName struct {
 // ParseOptDomainName returns a new OptDomainName from a byte
 // stream, or error if any.
 func ParseOptDomainName(data []byte) (*OptDomainName, error) {
-	if len(data) < 3 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionDomainName)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionDomainName {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionDomainName, code)
-	}
-	length := int(data[1])
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
-	}
-	return &OptDomainName{DomainName: string(data[2 : 2+length])}, nil
+	return &OptDomainName{DomainName: string(buf.ReadAll())}, nil
 }
 
 // Code returns the option code.
@@ -42,6 +35,16 @@ func (o *OptDomainName) String() string {
 	return fmt.Sprintf("Domain Name -> %v", o.DomainName)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptDomainName) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptDomainName) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptDomainName) Length() int {
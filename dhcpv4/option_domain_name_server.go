@@ -17,24 +17,13 @@ type OptDomainNameServer struct {
 // ParseOptDomainNameServer returns a new OptDomainNameServer from a byte
 // stream, or error if any.
 func ParseOptDomainNameServer(data []byte) (*OptDomainNameServer, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionDomainNameServer)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionDomainNameServer {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionDomainNameServer, code)
-	}
-	length := int(data[1])
-	if length == 0 || length%4 != 0 {
-		return nil, fmt.Errorf("Invalid length: expected multiple of 4 larger than 4, got %v", length)
-	}
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
-	}
-	nameservers := make([]net.IP, 0, length%4)
-	for idx := 0; idx < length; idx += 4 {
-		b := data[2+idx : 2+idx+4]
-		nameservers = append(nameservers, net.IPv4(b[0], b[1], b[2], b[3]))
+	nameservers, err := readIPv4List(buf)
+	if err != nil {
+		return nil, err
 	}
 	return &OptDomainNameServer{NameServers: nameservers}, nil
 }
@@ -65,6 +54,16 @@ func (o *OptDomainNameServer) String() string {
 	return fmt.Sprintf("Domain Name Servers -> %v", servers)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptDomainNameServer) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptDomainNameServer) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptDomainNameServer) Length() int {
@@ -10,6 +10,8 @@ import (
 )
 
 // OptDomainSearch represents an option encapsulating a domain search list.
+// The domains are serialized with the compression of RFC 1035, as RFC 3397
+// requires.
 type OptDomainSearch struct {
 	DomainSearch []string
 }
@@ -22,18 +24,14 @@ func (op *OptDomainSearch) Code() OptionCode {
 // ToBytes returns a serialized stream of bytes for this option.
 func (op *OptDomainSearch) ToBytes() []byte {
 	buf := []byte{byte(op.Code()), byte(op.Length())}
-	buf = append(buf, rfc1035label.LabelsToBytes(op.DomainSearch)...)
+	buf = append(buf, rfc1035label.CompressedLabelsToBytes(op.DomainSearch)...)
 	return buf
 }
 
 // Length returns the length of the data portion (excluding option code an byte
-// length). 
+// length).
 func (op *OptDomainSearch) Length() int {
-	var length int
-	for _, label := range op.DomainSearch {
-		length += len(label) + 2 // add the first and the last length bytes
-	}
-	return length
+	return len(rfc1035label.CompressedLabelsToBytes(op.DomainSearch))
 }
 
 // String returns a human-readable string.
@@ -41,21 +39,24 @@ func (op *OptDomainSearch) String() string {
 	return fmt.Sprintf("DNS Domain Search List -> %v", op.DomainSearch)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (op *OptDomainSearch) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(op)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (op *OptDomainSearch) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, op)
+}
+
 // ParseOptDomainSearch returns a new OptDomainSearch from a byte stream, or
 // error if any.
 func ParseOptDomainSearch(data []byte) (*OptDomainSearch, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
-	}
-	code := OptionCode(data[0])
-	if code != OptionDNSDomainSearchList {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionDNSDomainSearchList, code)
-	}
-	length := int(data[1])
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionDNSDomainSearchList)
+	if err != nil {
+		return nil, err
 	}
-	domainSearch, err := rfc1035label.LabelsFromBytes(data[2:length+2])
+	domainSearch, err := rfc1035label.LabelsFromBytes(buf.ReadAll())
 	if err != nil {
 		return nil, err
 	}
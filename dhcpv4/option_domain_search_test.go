@@ -35,3 +35,25 @@ func TestOptDomainSearchToBytes(t *testing.T) {
 	}
 	require.Equal(t, opt.ToBytes(), expected)
 }
+
+func TestOptDomainSearchCompression(t *testing.T) {
+	data := []byte{
+		119, // OptionDNSDomainSearchList
+		26,  // length
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
+		3, 'e', 'n', 'g', 0xc0, 0,
+		4, 'l', 'a', 'b', 's', 0xc0, 13,
+	}
+	opt := OptDomainSearch{
+		DomainSearch: []string{
+			"example.com",
+			"eng.example.com",
+			"labs.eng.example.com",
+		},
+	}
+	require.Equal(t, 26, opt.Length())
+	require.Equal(t, data, opt.ToBytes())
+	parsed, err := ParseOptDomainSearch(data)
+	require.NoError(t, err)
+	require.Equal(t, &opt, parsed)
+}
@@ -1,6 +1,7 @@
 package dhcpv4
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 )
@@ -19,19 +20,15 @@ func ParseOptionGeneric(data []byte) (*OptionGeneric, error) {
 	if len(data) == 0 {
 		return nil, errors.New("invalid zero-length bytestream")
 	}
-	var (
-		length     int
-		optionData []byte
-	)
 	code := OptionCode(data[0])
-	if code != OptionPad && code != OptionEnd {
-		length = int(data[1])
-		if len(data) < length+2 {
-			return nil, fmt.Errorf("invalid data length: declared %v, actual %v", length, len(data))
-		}
-		optionData = data[2 : length+2]
+	if code == OptionPad || code == OptionEnd {
+		return &OptionGeneric{OptionCode: code}, nil
 	}
-	return &OptionGeneric{OptionCode: code, Data: optionData}, nil
+	buf, err := newOptionLexer(data, code)
+	if err != nil {
+		return nil, err
+	}
+	return &OptionGeneric{OptionCode: code, Data: buf.ReadAll()}, nil
 }
 
 // Code returns the generic option code.
@@ -59,3 +56,23 @@ func (o OptionGeneric) String() string {
 func (o OptionGeneric) Length() int {
 	return len(o.Data)
 }
+
+// MarshalJSON implements json.Marshaler.
+func (o OptionGeneric) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The data is kept as is, even if
+// the code has a specific option type.
+func (o *OptionGeneric) UnmarshalJSON(data []byte) error {
+	var oj optionJSON
+	if err := json.Unmarshal(data, &oj); err != nil {
+		return err
+	}
+	raw, err := oj.raw()
+	if err != nil {
+		return err
+	}
+	*o = OptionGeneric{OptionCode: raw.code, Data: raw.data}
+	return nil
+}
@@ -10,6 +10,15 @@ func TestParseOptionGeneric(t *testing.T) {
 	// Empty bytestream produces error
 	_, err := ParseOptionGeneric([]byte{})
 	require.Error(t, err, "error from empty bytestream")
+
+	// Missing length byte produces error
+	_, err = ParseOptionGeneric([]byte{5})
+	require.Error(t, err, "error from missing length")
+
+	// Pad and End have no length byte
+	o, err := ParseOptionGeneric([]byte{byte(OptionEnd)})
+	require.NoError(t, err)
+	require.Equal(t, OptionEnd, o.Code())
 }
 
 func TestOptionGenericCode(t *testing.T) {
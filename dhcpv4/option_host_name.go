@@ -13,18 +13,11 @@ type OptHostName struct {
 // ParseOptHostName returns a new OptHostName from a byte stream, or error if
 // any.
 func ParseOptHostName(data []byte) (*OptHostName, error) {
-	if len(data) < 3 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionHostName)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionHostName {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionHostName, code)
-	}
-	length := int(data[1])
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
-	}
-	return &OptHostName{HostName: string(data[2 : 2+length])}, nil
+	return &OptHostName{HostName: string(buf.ReadAll())}, nil
 }
 
 // Code returns the option code.
@@ -42,6 +35,16 @@ func (o *OptHostName) String() string {
 	return fmt.Sprintf("Host Name -> %v", o.HostName)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptHostName) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptHostName) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptHostName) Length() int {
@@ -0,0 +1,298 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ValueType is the type of the value carried by an option, as it appears on
+// the wire.
+type ValueType int
+
+// Value types of the options
+const (
+	// ValueOpaque is a value with a structure of its own, or unknown.
+	ValueOpaque ValueType = iota
+	// ValueEmpty is no value at all, the option is a flag.
+	ValueEmpty
+	ValueBool
+	ValueUint8
+	ValueUint16
+	ValueUint32
+	ValueInt32
+	ValueIPv4
+	ValueString
+	ValueUint8List
+	ValueUint16List
+	ValueIPv4List
+	// ValueIPv4PairList is a list of address and mask, or destination and
+	// router, pairs.
+	ValueIPv4PairList
+	// ValueDomainList is a list of domain names in the compressed format of
+	// RFC 1035.
+	ValueDomainList
+	// ValueEncapsulated is a sequence of code/length/value sub-options.
+	ValueEncapsulated
+)
+
+var valueTypeToString = map[ValueType]string{
+	ValueOpaque:       "opaque",
+	ValueEmpty:        "empty",
+	ValueBool:         "bool",
+	ValueUint8:        "uint8",
+	ValueUint16:       "uint16",
+	ValueUint32:       "uint32",
+	ValueInt32:        "int32",
+	ValueIPv4:         "ipv4",
+	ValueString:       "string",
+	ValueUint8List:    "uint8 list",
+	ValueUint16List:   "uint16 list",
+	ValueIPv4List:     "ipv4 list",
+	ValueIPv4PairList: "ipv4 pair list",
+	ValueDomainList:   "domain list",
+	ValueEncapsulated: "encapsulated",
+}
+
+func (v ValueType) String() string {
+	if s, ok := valueTypeToString[v]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown (%d)", int(v))
+}
+
+// Unlimited is the MaxLength of the options whose data has no upper bound.
+// Options longer than 255 bytes are split into several instances, see RFC
+// 3396.
+const Unlimited = -1
+
+// OptionInfo describes an option code: where it is defined, the type of its
+// value and the lengths its data may have. It lets user interfaces offer
+// guided editing of the options, and validate them before they are sent.
+type OptionInfo struct {
+	Code OptionCode
+	Name string
+	// RFC is the number of the RFC defining the option, or 0 if it is not
+	// defined by an RFC, e.g. a vendor or site-specific option.
+	RFC  int
+	Type ValueType
+	// MinLength and MaxLength bound the length of the data, MaxLength is
+	// Unlimited if it has no upper bound.
+	MinLength int
+	MaxLength int
+	// Multiple, if larger than 1, is the size of the items the data is made
+	// of, e.g. 4 for a list of IPv4 addresses.
+	Multiple int
+}
+
+// ValidateLength returns an error if length is not an allowed length for the
+// data of the option.
+func (i OptionInfo) ValidateLength(length int) error {
+	if length < i.MinLength {
+		return fmt.Errorf("%v: length %d shorter than %d", i.Code, length, i.MinLength)
+	}
+	if i.MaxLength != Unlimited && length > i.MaxLength {
+		return fmt.Errorf("%v: length %d longer than %d", i.Code, length, i.MaxLength)
+	}
+	if i.Multiple > 1 && length%i.Multiple != 0 {
+		return fmt.Errorf("%v: length %d not a multiple of %d", i.Code, length, i.Multiple)
+	}
+	return nil
+}
+
+// ValidateOption returns an error if the data of opt has a length that the
+// description of its code does not allow. Options without a description are
+// valid.
+func ValidateOption(opt Option) error {
+	info, ok := LookupOptionInfo(opt.Code())
+	if !ok {
+		return nil
+	}
+	return info.ValidateLength(opt.Length())
+}
+
+func fixed(code OptionCode, rfc int, typ ValueType, length int) OptionInfo {
+	return OptionInfo{Code: code, RFC: rfc, Type: typ, MinLength: length, MaxLength: length}
+}
+
+func atLeast(code OptionCode, rfc int, typ ValueType, length int) OptionInfo {
+	return OptionInfo{Code: code, RFC: rfc, Type: typ, MinLength: length, MaxLength: Unlimited}
+}
+
+func list(code OptionCode, rfc int, typ ValueType, min, size int) OptionInfo {
+	return OptionInfo{Code: code, RFC: rfc, Type: typ, MinLength: min, MaxLength: Unlimited, Multiple: size}
+}
+
+var (
+	optionInfosMutex sync.RWMutex
+	optionInfos      = make(map[OptionCode]OptionInfo)
+)
+
+func init() {
+	for _, info := range []OptionInfo{
+		fixed(OptionSubnetMask, 2132, ValueIPv4, 4),
+		fixed(OptionTimeOffset, 2132, ValueInt32, 4),
+		list(OptionRouter, 2132, ValueIPv4List, 4, 4),
+		list(OptionTimeServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionNameServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionDomainNameServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionLogServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionQuoteServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionLPRServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionImpressServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionResourceLocationServer, 2132, ValueIPv4List, 4, 4),
+		atLeast(OptionHostName, 2132, ValueString, 1),
+		fixed(OptionBootFileSize, 2132, ValueUint16, 2),
+		atLeast(OptionMeritDumpFile, 2132, ValueString, 1),
+		atLeast(OptionDomainName, 2132, ValueString, 1),
+		fixed(OptionSwapServer, 2132, ValueIPv4, 4),
+		atLeast(OptionRootPath, 2132, ValueString, 1),
+		atLeast(OptionExtensionsPath, 2132, ValueString, 1),
+		fixed(OptionIPForwarding, 2132, ValueBool, 1),
+		fixed(OptionNonLocalSourceRouting, 2132, ValueBool, 1),
+		list(OptionPolicyFilter, 2132, ValueIPv4PairList, 8, 8),
+		fixed(OptionMaximumDatagramAssemblySize, 2132, ValueUint16, 2),
+		fixed(OptionDefaultIPTTL, 2132, ValueUint8, 1),
+		fixed(OptionPathMTUAgingTimeout, 2132, ValueUint32, 4),
+		list(OptionPathMTUPlateauTable, 2132, ValueUint16List, 2, 2),
+		fixed(OptionInterfaceMTU, 2132, ValueUint16, 2),
+		fixed(OptionAllSubnetsAreLocal, 2132, ValueBool, 1),
+		fixed(OptionBroadcastAddress, 2132, ValueIPv4, 4),
+		fixed(OptionPerformMaskDiscovery, 2132, ValueBool, 1),
+		fixed(OptionMaskSupplier, 2132, ValueBool, 1),
+		fixed(OptionPerformRouterDiscovery, 2132, ValueBool, 1),
+		fixed(OptionRouterSolicitationAddress, 2132, ValueIPv4, 4),
+		list(OptionStaticRoutingTable, 2132, ValueIPv4PairList, 8, 8),
+		fixed(OptionTrailerEncapsulation, 2132, ValueBool, 1),
+		fixed(OptionArpCacheTimeout, 2132, ValueUint32, 4),
+		fixed(OptionEthernetEncapsulation, 2132, ValueBool, 1),
+		fixed(OptionDefaulTCPTTL, 2132, ValueUint8, 1),
+		fixed(OptionTCPKeepaliveInterval, 2132, ValueUint32, 4),
+		fixed(OptionTCPKeepaliveGarbage, 2132, ValueBool, 1),
+		atLeast(OptionNetworkInformationServiceDomain, 2132, ValueString, 1),
+		list(OptionNetworkInformationServers, 2132, ValueIPv4List, 4, 4),
+		list(OptionNTPServers, 2132, ValueIPv4List, 4, 4),
+		atLeast(OptionVendorSpecificInformation, 2132, ValueEncapsulated, 1),
+		list(OptionNetBIOSOverTCPIPNameServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionNetBIOSOverTCPIPDatagramDistributionServer, 2132, ValueIPv4List, 4, 4),
+		fixed(OptionNetBIOSOverTCPIPNodeType, 2132, ValueUint8, 1),
+		atLeast(OptionNetBIOSOverTCPIPScope, 2132, ValueString, 1),
+		list(OptionXWindowSystemFontServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionXWindowSystemDisplayManger, 2132, ValueIPv4List, 4, 4),
+		fixed(OptionRequestedIPAddress, 2132, ValueIPv4, 4),
+		fixed(OptionIPAddressLeaseTime, 2132, ValueUint32, 4),
+		fixed(OptionOptionOverload, 2132, ValueUint8, 1),
+		fixed(OptionDHCPMessageType, 2132, ValueUint8, 1),
+		fixed(OptionServerIdentifier, 2132, ValueIPv4, 4),
+		atLeast(OptionParameterRequestList, 2132, ValueUint8List, 1),
+		atLeast(OptionMessage, 2132, ValueString, 1),
+		fixed(OptionMaximumDHCPMessageSize, 2132, ValueUint16, 2),
+		fixed(OptionRenewTimeValue, 2132, ValueUint32, 4),
+		fixed(OptionRebindingTimeValue, 2132, ValueUint32, 4),
+		atLeast(OptionClassIdentifier, 2132, ValueString, 1),
+		atLeast(OptionClientIdentifier, 2132, ValueOpaque, 2),
+		atLeast(OptionNetWareIPDomainName, 2242, ValueString, 1),
+		atLeast(OptionNetWareIPInformation, 2242, ValueEncapsulated, 0),
+		atLeast(OptionNetworkInformationServicePlusDomain, 2132, ValueString, 1),
+		list(OptionNetworkInformationServicePlusServers, 2132, ValueIPv4List, 4, 4),
+		atLeast(OptionTFTPServerName, 2132, ValueString, 1),
+		atLeast(OptionBootfileName, 2132, ValueString, 1),
+		list(OptionMobileIPHomeAgent, 2132, ValueIPv4List, 0, 4),
+		list(OptionSimpleMailTransportProtocolServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionPostOfficeProtocolServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionNetworkNewsTransportProtocolServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionDefaultWorldWideWebServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionDefaultFingerServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionDefaultInternetRelayChatServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionStreetTalkServer, 2132, ValueIPv4List, 4, 4),
+		list(OptionStreetTalkDirectoryAssistanceServer, 2132, ValueIPv4List, 4, 4),
+		atLeast(OptionUserClassInformation, 3004, ValueOpaque, 1),
+		atLeast(OptionSLPDirectoryAgent, 2610, ValueOpaque, 1),
+		atLeast(OptionSLPServiceScope, 2610, ValueOpaque, 1),
+		fixed(OptionRapidCommit, 4039, ValueEmpty, 0),
+		atLeast(OptionFQDN, 4702, ValueOpaque, 3),
+		atLeast(OptionRelayAgentInformation, 3046, ValueEncapsulated, 2),
+		atLeast(OptionInternetStorageNameService, 4174, ValueOpaque, 14),
+		list(OptionNDSServers, 2241, ValueIPv4List, 4, 4),
+		atLeast(OptionNDSTreeName, 2241, ValueString, 1),
+		atLeast(OptionNDSContext, 2241, ValueString, 1),
+		atLeast(OptionBCMCSControllerDomainNameList, 4280, ValueDomainList, 1),
+		list(OptionBCMCSControllerIPv4AddressList, 4280, ValueIPv4List, 4, 4),
+		atLeast(OptionAuthentication, 3118, ValueOpaque, 11),
+		fixed(OptionClientLastTransactionTime, 4388, ValueUint32, 4),
+		list(OptionAssociatedIP, 4388, ValueIPv4List, 4, 4),
+		list(OptionClientSystemArchitectureType, 4578, ValueUint16List, 2, 2),
+		fixed(OptionClientNetworkInterfaceIdentifier, 4578, ValueOpaque, 3),
+		atLeast(OptionClientMachineIdentifier, 4578, ValueOpaque, 1),
+		atLeast(OptionOpenGroupUserAuthentication, 2485, ValueString, 1),
+		atLeast(OptionGeoConfCivic, 4776, ValueOpaque, 3),
+		atLeast(OptionIEEE10031TZString, 4833, ValueString, 1),
+		atLeast(OptionReferenceToTZDatabase, 4833, ValueString, 1),
+		fixed(OptionAutoConfigure, 2563, ValueUint8, 1),
+		list(OptionNameServiceSearch, 2937, ValueUint16List, 0, 2),
+		fixed(OptionSubnetSelection, 3011, ValueIPv4, 4),
+		atLeast(OptionDNSDomainSearchList, 3397, ValueDomainList, 1),
+		atLeast(OptionSIPServersDHCPOption, 3361, ValueOpaque, 1),
+		atLeast(OptionClasslessStaticRouteOption, 3442, ValueOpaque, 5),
+		atLeast(OptionCCC, 3495, ValueEncapsulated, 1),
+		fixed(OptionGeoConf, 6225, ValueOpaque, 16),
+		atLeast(OptionVendorIdentifyingVendorClass, 3925, ValueOpaque, 5),
+		atLeast(OptionVendorIdentifyingVendorSpecific, 3925, ValueOpaque, 5),
+		list(OptionTFTPServerAddress, 5859, ValueIPv4List, 4, 4),
+		atLeast(OptionStatusCode, 6926, ValueOpaque, 1),
+		fixed(OptionBaseTime, 6926, ValueUint32, 4),
+		fixed(OptionStartTimeOfState, 6926, ValueUint32, 4),
+		fixed(OptionQueryStartTime, 6926, ValueUint32, 4),
+		fixed(OptionQueryEndTime, 6926, ValueUint32, 4),
+		fixed(OptionDHCPState, 6926, ValueUint8, 1),
+		fixed(OptionDataSource, 6926, ValueUint8, 1),
+		fixed(OptionPXELinuxMagicString, 5071, ValueOpaque, 4),
+		atLeast(OptionPXELinuxConfigFile, 5071, ValueString, 1),
+		atLeast(OptionPXELinuxPathPrefix, 5071, ValueString, 1),
+		fixed(OptionPXELinuxRebootTime, 5071, ValueUint32, 4),
+		atLeast(OptionOPTION6RD, 5969, ValueOpaque, 22),
+		atLeast(OptionMicrosoftClasslessStaticRoute, 0, ValueOpaque, 5),
+	} {
+		optionInfos[info.Code] = info
+	}
+}
+
+// RegisterOptionInfo registers the description of an option code, e.g. of a
+// site-specific option in the 224-254 range. It replaces the description of
+// the code, if any. The name defaults to the one of OptionCodeToString.
+func RegisterOptionInfo(info OptionInfo) {
+	optionInfosMutex.Lock()
+	defer optionInfosMutex.Unlock()
+	optionInfos[info.Code] = info
+}
+
+// LookupOptionInfo returns the description of an option code, and false if
+// the code has none.
+func LookupOptionInfo(code OptionCode) (OptionInfo, bool) {
+	optionInfosMutex.RLock()
+	info, ok := optionInfos[code]
+	optionInfosMutex.RUnlock()
+	if ok && info.Name == "" {
+		info.Name = code.String()
+	}
+	return info, ok
+}
+
+// OptionInfos returns the descriptions of all the option codes that have
+// one, sorted by code.
+func OptionInfos() []OptionInfo {
+	optionInfosMutex.RLock()
+	codes := make([]OptionCode, 0, len(optionInfos))
+	for code := range optionInfos {
+		codes = append(codes, code)
+	}
+	optionInfosMutex.RUnlock()
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	infos := make([]OptionInfo, 0, len(codes))
+	for _, code := range codes {
+		if info, ok := LookupOptionInfo(code); ok {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
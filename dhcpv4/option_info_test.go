@@ -0,0 +1,89 @@
+package dhcpv4
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupOptionInfo(t *testing.T) {
+	info, ok := LookupOptionInfo(OptionRouter)
+	require.True(t, ok)
+	require.Equal(t, OptionInfo{
+		Code:      OptionRouter,
+		Name:      "Router",
+		RFC:       2132,
+		Type:      ValueIPv4List,
+		MinLength: 4,
+		MaxLength: Unlimited,
+		Multiple:  4,
+	}, info)
+	require.Equal(t, "ipv4 list", info.Type.String())
+
+	_, ok = LookupOptionInfo(OptionCode(230))
+	require.False(t, ok)
+}
+
+func TestOptionInfoValidateLength(t *testing.T) {
+	router, _ := LookupOptionInfo(OptionRouter)
+	require.NoError(t, router.ValidateLength(8))
+	require.Error(t, router.ValidateLength(0))
+	require.Error(t, router.ValidateLength(6))
+
+	mask, _ := LookupOptionInfo(OptionSubnetMask)
+	require.NoError(t, mask.ValidateLength(4))
+	require.Error(t, mask.ValidateLength(5))
+
+	rapid, _ := LookupOptionInfo(OptionRapidCommit)
+	require.NoError(t, rapid.ValidateLength(0))
+	require.Error(t, rapid.ValidateLength(1))
+}
+
+func TestValidateOption(t *testing.T) {
+	require.NoError(t, ValidateOption(&OptRouter{Routers: []net.IP{net.IPv4(10, 0, 0, 1)}}))
+	require.Error(t, ValidateOption(&OptRouter{}))
+	require.Error(t, ValidateOption(&OptionGeneric{OptionCode: OptionSubnetMask, Data: []byte{255, 255}}))
+	// no description
+	require.NoError(t, ValidateOption(&OptionGeneric{OptionCode: OptionCode(231), Data: []byte{1}}))
+}
+
+func TestValidateOptionGenerated(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for name, gen := range optionGenerators {
+		if name == "Generic" {
+			// random data for any code
+			continue
+		}
+		for i := 0; i < 20; i++ {
+			require.NoError(t, ValidateOption(gen(r)), name)
+		}
+	}
+}
+
+func TestRegisterOptionInfo(t *testing.T) {
+	code := OptionCode(232)
+	defer func() {
+		optionInfosMutex.Lock()
+		delete(optionInfos, code)
+		optionInfosMutex.Unlock()
+	}()
+	RegisterOptionInfo(OptionInfo{Code: code, Name: "Site Proxy", Type: ValueIPv4, MinLength: 4, MaxLength: 4})
+	info, ok := LookupOptionInfo(code)
+	require.True(t, ok)
+	require.Equal(t, "Site Proxy", info.Name)
+	require.Error(t, ValidateOption(&OptionGeneric{OptionCode: code, Data: []byte{1}}))
+}
+
+func TestOptionInfos(t *testing.T) {
+	infos := OptionInfos()
+	require.NotEmpty(t, infos)
+	for i, info := range infos {
+		require.NotEmpty(t, info.Name, "code %d", info.Code)
+		require.NotEqual(t, "Unknown", info.Name, "code %d", info.Code)
+		if i > 0 {
+			require.True(t, infos[i-1].Code < info.Code)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// This option implements the interface MTU option
+// https://tools.ietf.org/html/rfc2132#section-5.1
+
+// OptInterfaceMTU represents the interface MTU option, the MTU the client
+// should use on the interface it got the lease on.
+type OptInterfaceMTU struct {
+	MTU uint16
+}
+
+// ParseOptInterfaceMTU returns a new OptInterfaceMTU from a byte stream, or
+// error if any, including an MTU smaller than MinInterfaceMTU.
+func ParseOptInterfaceMTU(data []byte) (*OptInterfaceMTU, error) {
+	buf, err := newOptionLexer(data, OptionInterfaceMTU)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 2 {
+		return nil, fmt.Errorf("expected length 2, got %v instead", buf.Len())
+	}
+	o := &OptInterfaceMTU{MTU: buf.Read16()}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Validate returns an error if the MTU is smaller than MinInterfaceMTU.
+func (o *OptInterfaceMTU) Validate() error {
+	if o.MTU < MinInterfaceMTU {
+		return fmt.Errorf("interface MTU %d smaller than %d", o.MTU, MinInterfaceMTU)
+	}
+	return nil
+}
+
+// Code returns the option code.
+func (o *OptInterfaceMTU) Code() OptionCode {
+	return OptionInterfaceMTU
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptInterfaceMTU) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length()), 0, 0}
+	binary.BigEndian.PutUint16(ret[2:], o.MTU)
+	return ret
+}
+
+// String returns a human-readable string for this option.
+func (o *OptInterfaceMTU) String() string {
+	return fmt.Sprintf("Interface MTU -> %d", o.MTU)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptInterfaceMTU) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptInterfaceMTU) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptInterfaceMTU) Length() int {
+	return 2
+}
@@ -0,0 +1,45 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptInterfaceMTUInterfaceMethods(t *testing.T) {
+	o := OptInterfaceMTU{MTU: 1500}
+	require.Equal(t, OptionInterfaceMTU, o.Code(), "Code")
+	require.Equal(t, 2, o.Length(), "Length")
+	require.Equal(t, []byte{26, 2, 0x05, 0xdc}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Interface MTU -> 1500", o.String())
+	require.NoError(t, o.Validate())
+
+	o.MTU = MinInterfaceMTU - 1
+	require.Error(t, o.Validate())
+}
+
+func TestParseOptInterfaceMTU(t *testing.T) {
+	o, err := ParseOptInterfaceMTU([]byte{26, 2, 0x23, 0x28})
+	require.NoError(t, err)
+	require.Equal(t, &OptInterfaceMTU{MTU: 9000}, o)
+
+	o, err = ParseOptInterfaceMTU([]byte{26, 2, 0, 68})
+	require.NoError(t, err)
+	require.Equal(t, uint16(MinInterfaceMTU), o.MTU)
+
+	// MTU too small
+	_, err = ParseOptInterfaceMTU([]byte{26, 2, 0, 67})
+	require.Error(t, err, "should get error from MTU smaller than 68")
+
+	// Short byte stream
+	_, err = ParseOptInterfaceMTU([]byte{26, 2, 5})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptInterfaceMTU([]byte{57, 2, 0x05, 0xdc})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Wrong length
+	_, err = ParseOptInterfaceMTU([]byte{26, 3, 0, 0x05, 0xdc})
+	require.Error(t, err, "should get error from bad length")
+}
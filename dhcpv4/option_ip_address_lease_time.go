@@ -16,20 +16,14 @@ type OptIPAddressLeaseTime struct {
 // ParseOptIPAddressLeaseTime constructs an OptIPAddressLeaseTime struct from a
 // sequence of bytes and returns it, or an error.
 func ParseOptIPAddressLeaseTime(data []byte) (*OptIPAddressLeaseTime, error) {
-	// Should at least have code, length, and lease time.
-	if len(data) < 6 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionIPAddressLeaseTime)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionIPAddressLeaseTime {
-		return nil, fmt.Errorf("expected option %v, got %v instead", OptionIPAddressLeaseTime, code)
+	if buf.Len() != 4 {
+		return nil, fmt.Errorf("expected length 4, got %v instead", buf.Len())
 	}
-	length := int(data[1])
-	if length != 4 {
-		return nil, fmt.Errorf("expected length 4, got %v instead", length)
-	}
-	leaseTime := binary.BigEndian.Uint32(data[2:6])
-	return &OptIPAddressLeaseTime{LeaseTime: leaseTime}, nil
+	return &OptIPAddressLeaseTime{LeaseTime: buf.Read32()}, nil
 }
 
 // Code returns the option code.
@@ -50,6 +44,16 @@ func (o *OptIPAddressLeaseTime) String() string {
 	return fmt.Sprintf("IP Addresses Lease Time -> %v", o.LeaseTime)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptIPAddressLeaseTime) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptIPAddressLeaseTime) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code and byte
 // for length, if any).
 func (o *OptIPAddressLeaseTime) Length() int {
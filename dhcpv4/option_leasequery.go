@@ -0,0 +1,139 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// This module implements the options of leasequery
+// https://tools.ietf.org/html/rfc4388
+
+// OptClientLastTransactionTime represents the client last transaction time
+// option, the number of seconds since the server last heard from the client
+// the lease is reported about.
+type OptClientLastTransactionTime struct {
+	Time uint32
+}
+
+// ParseOptClientLastTransactionTime constructs an OptClientLastTransactionTime
+// struct from a sequence of bytes and returns it, or an error.
+func ParseOptClientLastTransactionTime(data []byte) (*OptClientLastTransactionTime, error) {
+	t, err := parseTimeOption(data, OptionClientLastTransactionTime)
+	if err != nil {
+		return nil, err
+	}
+	return &OptClientLastTransactionTime{Time: t}, nil
+}
+
+// Code returns the option code.
+func (o *OptClientLastTransactionTime) Code() OptionCode {
+	return OptionClientLastTransactionTime
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptClientLastTransactionTime) ToBytes() []byte {
+	return timeOptionToBytes(OptionClientLastTransactionTime, o.Time)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptClientLastTransactionTime) String() string {
+	return fmt.Sprintf("Client Last Transaction Time -> %v", o.Time)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptClientLastTransactionTime) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptClientLastTransactionTime) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptClientLastTransactionTime) Length() int {
+	return 4
+}
+
+// OptAssociatedIP represents the associated IP option, the addresses bound to
+// the client of a leasequery reply besides its ciaddr.
+type OptAssociatedIP struct {
+	IPs []net.IP
+}
+
+// ParseOptAssociatedIP returns a new OptAssociatedIP from a byte stream, or
+// error if any.
+func ParseOptAssociatedIP(data []byte) (*OptAssociatedIP, error) {
+	buf, err := newOptionLexer(data, OptionAssociatedIP)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := readIPv4List(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &OptAssociatedIP{IPs: ips}, nil
+}
+
+// Code returns the option code.
+func (o *OptAssociatedIP) Code() OptionCode {
+	return OptionAssociatedIP
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptAssociatedIP) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length())}
+	for _, ip := range o.IPs {
+		ret = append(ret, ip.To4()...)
+	}
+	return ret
+}
+
+// String returns a human-readable string.
+func (o *OptAssociatedIP) String() string {
+	ips := make([]string, 0, len(o.IPs))
+	for _, ip := range o.IPs {
+		ips = append(ips, ip.String())
+	}
+	return fmt.Sprintf("Associated IP -> %v", strings.Join(ips, ", "))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptAssociatedIP) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptAssociatedIP) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code an byte
+// length).
+func (o *OptAssociatedIP) Length() int {
+	return len(o.IPs) * 4
+}
+
+// ClientLastTransactionTime returns how long ago the server last heard from
+// the client, from the client last transaction time option of a leasequery
+// reply, and false if the option is not present.
+func (d *DHCPv4) ClientLastTransactionTime() (time.Duration, bool) {
+	opt, ok := d.GetOneOption(OptionClientLastTransactionTime).(*OptClientLastTransactionTime)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(opt.Time) * time.Second, true
+}
+
+// AssociatedIPs returns the addresses of the associated IP option of a
+// leasequery reply, or nil if it is not present.
+func (d *DHCPv4) AssociatedIPs() []net.IP {
+	opt, ok := d.GetOneOption(OptionAssociatedIP).(*OptAssociatedIP)
+	if !ok {
+		return nil
+	}
+	return opt.IPs
+}
@@ -0,0 +1,55 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptClientLastTransactionTime(t *testing.T) {
+	o := OptClientLastTransactionTime{Time: 300}
+	require.Equal(t, OptionClientLastTransactionTime, o.Code(), "Code")
+	require.Equal(t, 4, o.Length(), "Length")
+	require.Equal(t, []byte{91, 4, 0, 0, 1, 44}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Client Last Transaction Time -> 300", o.String())
+
+	parsed, err := ParseOptClientLastTransactionTime(o.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, &o, parsed)
+
+	// Bad length
+	_, err = ParseOptClientLastTransactionTime([]byte{91, 2, 1, 44})
+	require.Error(t, err, "should get error from bad length")
+}
+
+func TestOptAssociatedIP(t *testing.T) {
+	o := OptAssociatedIP{IPs: []net.IP{net.IPv4(192, 168, 0, 10), net.IPv4(192, 168, 0, 11)}}
+	require.Equal(t, OptionAssociatedIP, o.Code(), "Code")
+	require.Equal(t, 8, o.Length(), "Length")
+	require.Equal(t, "Associated IP -> 192.168.0.10, 192.168.0.11", o.String())
+
+	parsed, err := ParseOptAssociatedIP([]byte{92, 8, 192, 168, 0, 10, 192, 168, 0, 11})
+	require.NoError(t, err)
+	require.Equal(t, o.IPs, parsed.IPs)
+
+	// Bad length
+	_, err = ParseOptAssociatedIP([]byte{92, 3, 192, 168, 0})
+	require.Error(t, err, "should get error from bad length")
+}
+
+func TestLeaseQueryGetters(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	_, ok := d.ClientLastTransactionTime()
+	require.False(t, ok)
+	require.Nil(t, d.AssociatedIPs())
+
+	d.AddOption(&OptClientLastTransactionTime{Time: 60})
+	d.AddOption(&OptAssociatedIP{IPs: []net.IP{{192, 168, 0, 10}}})
+	last, ok := d.ClientLastTransactionTime()
+	require.True(t, ok)
+	require.Equal(t, time.Minute, last)
+	require.Equal(t, []net.IP{{192, 168, 0, 10}}, d.AssociatedIPs())
+}
@@ -0,0 +1,217 @@
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// This module implements the time options of bulk leasequery
+// https://tools.ietf.org/html/rfc6926
+
+// parseTimeOption parses an option carrying a 32-bit time value, checking its
+// code and length.
+func parseTimeOption(data []byte, expected OptionCode) (uint32, error) {
+	buf, err := newOptionLexer(data, expected)
+	if err != nil {
+		return 0, err
+	}
+	if buf.Len() != 4 {
+		return 0, fmt.Errorf("expected length 4, got %v instead", buf.Len())
+	}
+	return buf.Read32(), nil
+}
+
+// timeOptionToBytes serializes an option carrying a 32-bit time value.
+func timeOptionToBytes(code OptionCode, t uint32) []byte {
+	data := []byte{byte(code), 4, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(data[2:6], t)
+	return data
+}
+
+// OptBaseTime represents the base time option, the current time of the server in
+// seconds since the epoch. The other times of a reply are relative to it.
+type OptBaseTime struct {
+	Time uint32
+}
+
+// ParseOptBaseTime constructs an OptBaseTime struct from a sequence of bytes and
+// returns it, or an error.
+func ParseOptBaseTime(data []byte) (*OptBaseTime, error) {
+	t, err := parseTimeOption(data, OptionBaseTime)
+	if err != nil {
+		return nil, err
+	}
+	return &OptBaseTime{Time: t}, nil
+}
+
+// Code returns the option code.
+func (o *OptBaseTime) Code() OptionCode {
+	return OptionBaseTime
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptBaseTime) ToBytes() []byte {
+	return timeOptionToBytes(OptionBaseTime, o.Time)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptBaseTime) String() string {
+	return fmt.Sprintf("Base Time -> %v", o.Time)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptBaseTime) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptBaseTime) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptBaseTime) Length() int {
+	return 4
+}
+
+// OptStartTimeOfState represents the start time of state option, the number of seconds
+// between the start of the current state of a lease and the base time.
+type OptStartTimeOfState struct {
+	Time uint32
+}
+
+// ParseOptStartTimeOfState constructs an OptStartTimeOfState struct from a sequence of bytes and
+// returns it, or an error.
+func ParseOptStartTimeOfState(data []byte) (*OptStartTimeOfState, error) {
+	t, err := parseTimeOption(data, OptionStartTimeOfState)
+	if err != nil {
+		return nil, err
+	}
+	return &OptStartTimeOfState{Time: t}, nil
+}
+
+// Code returns the option code.
+func (o *OptStartTimeOfState) Code() OptionCode {
+	return OptionStartTimeOfState
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptStartTimeOfState) ToBytes() []byte {
+	return timeOptionToBytes(OptionStartTimeOfState, o.Time)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptStartTimeOfState) String() string {
+	return fmt.Sprintf("Start Time Of State -> %v", o.Time)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptStartTimeOfState) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptStartTimeOfState) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptStartTimeOfState) Length() int {
+	return 4
+}
+
+// OptQueryStartTime represents the query start time option, the time in seconds since
+// the epoch from which the changed bindings are queried.
+type OptQueryStartTime struct {
+	Time uint32
+}
+
+// ParseOptQueryStartTime constructs an OptQueryStartTime struct from a sequence of bytes and
+// returns it, or an error.
+func ParseOptQueryStartTime(data []byte) (*OptQueryStartTime, error) {
+	t, err := parseTimeOption(data, OptionQueryStartTime)
+	if err != nil {
+		return nil, err
+	}
+	return &OptQueryStartTime{Time: t}, nil
+}
+
+// Code returns the option code.
+func (o *OptQueryStartTime) Code() OptionCode {
+	return OptionQueryStartTime
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptQueryStartTime) ToBytes() []byte {
+	return timeOptionToBytes(OptionQueryStartTime, o.Time)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptQueryStartTime) String() string {
+	return fmt.Sprintf("Query Start Time -> %v", o.Time)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptQueryStartTime) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptQueryStartTime) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptQueryStartTime) Length() int {
+	return 4
+}
+
+// OptQueryEndTime represents the query end time option, the time in seconds since
+// the epoch until which the changed bindings are queried.
+type OptQueryEndTime struct {
+	Time uint32
+}
+
+// ParseOptQueryEndTime constructs an OptQueryEndTime struct from a sequence of bytes and
+// returns it, or an error.
+func ParseOptQueryEndTime(data []byte) (*OptQueryEndTime, error) {
+	t, err := parseTimeOption(data, OptionQueryEndTime)
+	if err != nil {
+		return nil, err
+	}
+	return &OptQueryEndTime{Time: t}, nil
+}
+
+// Code returns the option code.
+func (o *OptQueryEndTime) Code() OptionCode {
+	return OptionQueryEndTime
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptQueryEndTime) ToBytes() []byte {
+	return timeOptionToBytes(OptionQueryEndTime, o.Time)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptQueryEndTime) String() string {
+	return fmt.Sprintf("Query End Time -> %v", o.Time)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptQueryEndTime) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptQueryEndTime) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptQueryEndTime) Length() int {
+	return 4
+}
@@ -0,0 +1,43 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptBaseTimeInterfaceMethods(t *testing.T) {
+	o := OptBaseTime{Time: 43200}
+	require.Equal(t, OptionBaseTime, o.Code(), "Code")
+	require.Equal(t, 4, o.Length(), "Length")
+	require.Equal(t, []byte{152, 4, 0, 0, 168, 192}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Base Time -> 43200", o.String())
+}
+
+func TestParseOptTimes(t *testing.T) {
+	o1, err := ParseOptStartTimeOfState([]byte{153, 4, 0, 0, 0, 60})
+	require.NoError(t, err)
+	require.Equal(t, &OptStartTimeOfState{Time: 60}, o1)
+
+	o2, err := ParseOptQueryStartTime([]byte{154, 4, 0, 0, 1, 0})
+	require.NoError(t, err)
+	require.Equal(t, &OptQueryStartTime{Time: 256}, o2)
+
+	o3, err := ParseOptQueryEndTime([]byte{155, 4, 1, 0, 0, 0})
+	require.NoError(t, err)
+	require.Equal(t, &OptQueryEndTime{Time: 1 << 24}, o3)
+}
+
+func TestParseOptBaseTime(t *testing.T) {
+	// Short byte stream
+	_, err := ParseOptBaseTime([]byte{152, 4, 168, 192})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptBaseTime([]byte{153, 4, 0, 0, 168, 192})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	_, err = ParseOptBaseTime([]byte{152, 5, 1, 1, 1, 1, 1})
+	require.Error(t, err, "should get error from bad length")
+}
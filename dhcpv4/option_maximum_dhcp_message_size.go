@@ -16,20 +16,14 @@ type OptMaximumDHCPMessageSize struct {
 // ParseOptMaximumDHCPMessageSize constructs an OptMaximumDHCPMessageSize struct from a sequence of
 // bytes and returns it, or an error.
 func ParseOptMaximumDHCPMessageSize(data []byte) (*OptMaximumDHCPMessageSize, error) {
-	// Should at least have code, length, and message size.
-	if len(data) < 4 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionMaximumDHCPMessageSize)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionMaximumDHCPMessageSize {
-		return nil, fmt.Errorf("expected option %v, got %v instead", OptionMaximumDHCPMessageSize, code)
+	if buf.Len() != 2 {
+		return nil, fmt.Errorf("expected length 2, got %v instead", buf.Len())
 	}
-	length := int(data[1])
-	if length != 2 {
-		return nil, fmt.Errorf("expected length 2, got %v instead", length)
-	}
-	msgSize := binary.BigEndian.Uint16(data[2:4])
-	return &OptMaximumDHCPMessageSize{Size: msgSize}, nil
+	return &OptMaximumDHCPMessageSize{Size: buf.Read16()}, nil
 }
 
 // Code returns the option code.
@@ -50,8 +44,30 @@ func (o *OptMaximumDHCPMessageSize) String() string {
 	return fmt.Sprintf("Maximum DHCP Message Size -> %v", o.Size)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptMaximumDHCPMessageSize) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptMaximumDHCPMessageSize) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code and byte
 // for length, if any).
 func (o *OptMaximumDHCPMessageSize) Length() int {
 	return 2
 }
+
+// MaximumMessageSize returns the size in bytes of the largest message that the
+// sender of d accepts: the one advertised in the Maximum DHCP Message Size
+// option, or MaxMessageSize if the option is not present or holds a smaller,
+// invalid, value.
+func (d *DHCPv4) MaximumMessageSize() int {
+	opt, ok := d.GetOneOption(OptionMaximumDHCPMessageSize).(*OptMaximumDHCPMessageSize)
+	if !ok || int(opt.Size) < MaxMessageSize {
+		return MaxMessageSize
+	}
+	return int(opt.Size)
+}
@@ -39,3 +39,17 @@ func TestOptMaximumDHCPMessageSizeString(t *testing.T) {
 	o := OptMaximumDHCPMessageSize{Size: 1500}
 	require.Equal(t, "Maximum DHCP Message Size -> 1500", o.String())
 }
+
+func TestMaximumMessageSize(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	require.Equal(t, MaxMessageSize, d.MaximumMessageSize())
+
+	d = WithMaximumMessageSize(1500)(d)
+	require.Equal(t, &OptMaximumDHCPMessageSize{Size: 1500}, d.GetOneOption(OptionMaximumDHCPMessageSize))
+	require.Equal(t, 1500, d.MaximumMessageSize())
+
+	// smaller than the minimum legal value
+	d = WithMaximumMessageSize(300)(d)
+	require.Equal(t, MaxMessageSize, d.MaximumMessageSize())
+}
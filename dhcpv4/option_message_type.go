@@ -15,20 +15,14 @@ type OptMessageType struct {
 // ParseOptMessageType constructs an OptMessageType struct from a sequence of
 // bytes and returns it, or an error.
 func ParseOptMessageType(data []byte) (*OptMessageType, error) {
-	// Should at least have code, length, and message type.
-	if len(data) < 3 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionDHCPMessageType)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionDHCPMessageType {
-		return nil, fmt.Errorf("expected option %v, got %v instead", OptionDHCPMessageType, code)
+	if buf.Len() != 1 {
+		return nil, fmt.Errorf("expected length 1, got %v instead", buf.Len())
 	}
-	length := int(data[1])
-	if length != 1 {
-		return nil, ErrShortByteStream
-	}
-	messageType := MessageType(data[2])
-	return &OptMessageType{MessageType: messageType}, nil
+	return &OptMessageType{MessageType: MessageType(buf.Read8())}, nil
 }
 
 // Code returns the option code.
@@ -46,6 +40,16 @@ func (o *OptMessageType) String() string {
 	return fmt.Sprintf("DHCP Message Type -> %s", o.MessageType.String())
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptMessageType) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptMessageType) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code and byte
 // for length, if any).
 func (o *OptMessageType) Length() int {
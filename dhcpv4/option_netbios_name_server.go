@@ -0,0 +1,71 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"net"
+)
+
+// This option implements the NetBIOS over TCP/IP name server option
+// https://tools.ietf.org/html/rfc2132#section-8.5
+
+// OptNetBIOSNameServer represents an option encapsulating the NetBIOS name
+// servers (NBNS), listed in order of preference.
+type OptNetBIOSNameServer struct {
+	NameServers []net.IP
+}
+
+// ParseOptNetBIOSNameServer returns a new OptNetBIOSNameServer from a byte
+// stream, or error if any.
+func ParseOptNetBIOSNameServer(data []byte) (*OptNetBIOSNameServer, error) {
+	buf, err := newOptionLexer(data, OptionNetBIOSOverTCPIPNameServer)
+	if err != nil {
+		return nil, err
+	}
+	nameServers, err := readIPv4List(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &OptNetBIOSNameServer{NameServers: nameServers}, nil
+}
+
+// Code returns the option code.
+func (o *OptNetBIOSNameServer) Code() OptionCode {
+	return OptionNetBIOSOverTCPIPNameServer
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptNetBIOSNameServer) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length())}
+	for _, ns := range o.NameServers {
+		ret = append(ret, ns.To4()...)
+	}
+	return ret
+}
+
+// String returns a human-readable string.
+func (o *OptNetBIOSNameServer) String() string {
+	var nameServers string
+	for idx, ns := range o.NameServers {
+		nameServers += ns.String()
+		if idx < len(o.NameServers)-1 {
+			nameServers += ", "
+		}
+	}
+	return fmt.Sprintf("NetBIOS Name Servers -> %v", nameServers)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptNetBIOSNameServer) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptNetBIOSNameServer) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code an byte
+// length).
+func (o *OptNetBIOSNameServer) Length() int {
+	return len(o.NameServers) * 4
+}
@@ -0,0 +1,57 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptNetBIOSNameServer(t *testing.T) {
+	data := []byte{
+		byte(OptionNetBIOSOverTCPIPNameServer),
+		8,               // Length
+		192, 168, 0, 10, // name server #1
+		192, 168, 0, 20, // name server #2
+	}
+	o, err := ParseOptNetBIOSNameServer(data)
+	require.NoError(t, err)
+	nameServers := []net.IP{
+		net.IPv4(192, 168, 0, 10),
+		net.IPv4(192, 168, 0, 20),
+	}
+	require.Equal(t, &OptNetBIOSNameServer{NameServers: nameServers}, o)
+	require.Equal(t, OptionNetBIOSOverTCPIPNameServer, o.Code())
+	require.Equal(t, 8, o.Length())
+	require.Equal(t, data, o.ToBytes())
+	require.Equal(t, "NetBIOS Name Servers -> 192.168.0.10, 192.168.0.20", o.String())
+
+	// Short byte stream
+	_, err = ParseOptNetBIOSNameServer([]byte{byte(OptionNetBIOSOverTCPIPNameServer)})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptNetBIOSNameServer([]byte{54, 4, 1, 1, 1, 1})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	_, err = ParseOptNetBIOSNameServer([]byte{byte(OptionNetBIOSOverTCPIPNameServer), 6, 1, 1, 1})
+	require.Error(t, err, "should get error from bad length")
+}
+
+func TestGetNetBIOSOptions(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	require.Nil(t, d.NetBIOSNameServers())
+	require.Equal(t, NetBIOSNodeType(0), d.NetBIOSNodeType())
+	require.Equal(t, "", d.NetBIOSScope())
+
+	d.AddOption(&OptNetBIOSNameServer{NameServers: []net.IP{net.IPv4(10, 0, 0, 1)}})
+	d.AddOption(&OptNetBIOSNodeType{NodeType: NetBIOSHNode})
+	d.AddOption(&OptNetBIOSScope{Scope: "corp"})
+	parsed, err := FromBytes(d.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, []net.IP{net.IPv4(10, 0, 0, 1)}, parsed.NetBIOSNameServers())
+	require.Equal(t, NetBIOSHNode, parsed.NetBIOSNodeType())
+	require.Equal(t, "corp", parsed.NetBIOSScope())
+}
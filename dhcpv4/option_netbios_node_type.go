@@ -0,0 +1,89 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the NetBIOS over TCP/IP node type option
+// https://tools.ietf.org/html/rfc2132#section-8.7
+
+// NetBIOSNodeType is the way a NetBIOS over TCP/IP client resolves names, as
+// described in RFC 1001 and RFC 1002.
+type NetBIOSNodeType uint8
+
+// NetBIOS node types
+const (
+	// NetBIOSBNode resolves names by broadcasting.
+	NetBIOSBNode NetBIOSNodeType = 0x1
+	// NetBIOSPNode resolves names with the name servers only.
+	NetBIOSPNode NetBIOSNodeType = 0x2
+	// NetBIOSMNode broadcasts first, then asks the name servers.
+	NetBIOSMNode NetBIOSNodeType = 0x4
+	// NetBIOSHNode asks the name servers first, then broadcasts. It is the
+	// usual choice when name servers, e.g. WINS, are available.
+	NetBIOSHNode NetBIOSNodeType = 0x8
+)
+
+func (n NetBIOSNodeType) String() string {
+	if s, ok := NetBIOSNodeTypeToString[n]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown (0x%02x)", uint8(n))
+}
+
+// NetBIOSNodeTypeToString maps a NetBIOSNodeType to its mnemonic name.
+var NetBIOSNodeTypeToString = map[NetBIOSNodeType]string{
+	NetBIOSBNode: "B-node",
+	NetBIOSPNode: "P-node",
+	NetBIOSMNode: "M-node",
+	NetBIOSHNode: "H-node",
+}
+
+// OptNetBIOSNodeType represents the NetBIOS over TCP/IP node type option.
+type OptNetBIOSNodeType struct {
+	NodeType NetBIOSNodeType
+}
+
+// ParseOptNetBIOSNodeType returns a new OptNetBIOSNodeType from a byte stream,
+// or error if any.
+func ParseOptNetBIOSNodeType(data []byte) (*OptNetBIOSNodeType, error) {
+	buf, err := newOptionLexer(data, OptionNetBIOSOverTCPIPNodeType)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 1 {
+		return nil, fmt.Errorf("expected length 1, got %v instead", buf.Len())
+	}
+	return &OptNetBIOSNodeType{NodeType: NetBIOSNodeType(buf.Read8())}, nil
+}
+
+// Code returns the option code.
+func (o *OptNetBIOSNodeType) Code() OptionCode {
+	return OptionNetBIOSOverTCPIPNodeType
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptNetBIOSNodeType) ToBytes() []byte {
+	return []byte{byte(o.Code()), byte(o.Length()), byte(o.NodeType)}
+}
+
+// String returns a human-readable string.
+func (o *OptNetBIOSNodeType) String() string {
+	return fmt.Sprintf("NetBIOS Node Type -> %v", o.NodeType)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptNetBIOSNodeType) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptNetBIOSNodeType) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptNetBIOSNodeType) Length() int {
+	return 1
+}
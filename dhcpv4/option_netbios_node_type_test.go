@@ -0,0 +1,35 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptNetBIOSNodeType(t *testing.T) {
+	data := []byte{byte(OptionNetBIOSOverTCPIPNodeType), 1, 0x8}
+	o, err := ParseOptNetBIOSNodeType(data)
+	require.NoError(t, err)
+	require.Equal(t, &OptNetBIOSNodeType{NodeType: NetBIOSHNode}, o)
+	require.Equal(t, OptionNetBIOSOverTCPIPNodeType, o.Code())
+	require.Equal(t, 1, o.Length())
+	require.Equal(t, data, o.ToBytes())
+	require.Equal(t, "NetBIOS Node Type -> H-node", o.String())
+
+	// Short byte stream
+	_, err = ParseOptNetBIOSNodeType([]byte{byte(OptionNetBIOSOverTCPIPNodeType), 1})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptNetBIOSNodeType([]byte{54, 1, 1})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	_, err = ParseOptNetBIOSNodeType([]byte{byte(OptionNetBIOSOverTCPIPNodeType), 2, 1, 1})
+	require.Error(t, err, "should get error from bad length")
+}
+
+func TestNetBIOSNodeTypeString(t *testing.T) {
+	require.Equal(t, "B-node", NetBIOSBNode.String())
+	require.Equal(t, "unknown (0x03)", NetBIOSNodeType(3).String())
+}
@@ -0,0 +1,55 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the NetBIOS over TCP/IP scope option
+// https://tools.ietf.org/html/rfc2132#section-8.8
+
+// OptNetBIOSScope represents the NetBIOS over TCP/IP scope option, the scope
+// ID of the client as a character string, see RFC 1001.
+type OptNetBIOSScope struct {
+	Scope string
+}
+
+// ParseOptNetBIOSScope constructs an OptNetBIOSScope struct from a sequence of
+// bytes and returns it, or an error.
+func ParseOptNetBIOSScope(data []byte) (*OptNetBIOSScope, error) {
+	buf, err := newOptionLexer(data, OptionNetBIOSOverTCPIPScope)
+	if err != nil {
+		return nil, err
+	}
+	return &OptNetBIOSScope{Scope: string(buf.ReadAll())}, nil
+}
+
+// Code returns the option code.
+func (o *OptNetBIOSScope) Code() OptionCode {
+	return OptionNetBIOSOverTCPIPScope
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptNetBIOSScope) ToBytes() []byte {
+	return append([]byte{byte(o.Code()), byte(o.Length())}, []byte(o.Scope)...)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptNetBIOSScope) String() string {
+	return fmt.Sprintf("NetBIOS Scope -> %v", o.Scope)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptNetBIOSScope) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptNetBIOSScope) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptNetBIOSScope) Length() int {
+	return len(o.Scope)
+}
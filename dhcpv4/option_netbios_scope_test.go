@@ -0,0 +1,26 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptNetBIOSScope(t *testing.T) {
+	data := []byte{byte(OptionNetBIOSOverTCPIPScope), 4, 'c', 'o', 'r', 'p'}
+	o, err := ParseOptNetBIOSScope(data)
+	require.NoError(t, err)
+	require.Equal(t, &OptNetBIOSScope{Scope: "corp"}, o)
+	require.Equal(t, OptionNetBIOSOverTCPIPScope, o.Code())
+	require.Equal(t, 4, o.Length())
+	require.Equal(t, data, o.ToBytes())
+	require.Equal(t, "NetBIOS Scope -> corp", o.String())
+
+	// Short byte stream
+	_, err = ParseOptNetBIOSScope([]byte{byte(OptionNetBIOSOverTCPIPScope), 4, 'c'})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptNetBIOSScope([]byte{54, 1, 'c'})
+	require.Error(t, err, "should get error from wrong code")
+}
@@ -15,24 +15,13 @@ type OptNTPServers struct {
 
 // ParseOptNTPServers returns a new OptNTPServers from a byte stream, or error if any.
 func ParseOptNTPServers(data []byte) (*OptNTPServers, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionNTPServers)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionNTPServers {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionNTPServers, code)
-	}
-	length := int(data[1])
-	if length == 0 || length%4 != 0 {
-		return nil, fmt.Errorf("Invalid length: expected multiple of 4 larger than 4, got %v", length)
-	}
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
-	}
-	ntpServers := make([]net.IP, 0, length%4)
-	for idx := 0; idx < length; idx += 4 {
-		b := data[2+idx : 2+idx+4]
-		ntpServers = append(ntpServers, net.IPv4(b[0], b[1], b[2], b[3]))
+	ntpServers, err := readIPv4List(buf)
+	if err != nil {
+		return nil, err
 	}
 	return &OptNTPServers{NTPServers: ntpServers}, nil
 }
@@ -63,6 +52,16 @@ func (o *OptNTPServers) String() string {
 	return fmt.Sprintf("NTP Servers -> %v", ntpServers)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptNTPServers) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptNTPServers) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptNTPServers) Length() int {
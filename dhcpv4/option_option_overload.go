@@ -0,0 +1,201 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the option overload option, which tells that the
+// file and sname header fields hold options instead of names.
+// https://tools.ietf.org/html/rfc2132#section-9.3
+
+// Overload tells which header fields hold options.
+type Overload uint8
+
+// Values of the option overload option.
+const (
+	OverloadFile  Overload = 1
+	OverloadSName Overload = 2
+	OverloadBoth  Overload = OverloadFile | OverloadSName
+)
+
+func (o Overload) String() string {
+	switch o {
+	case OverloadFile:
+		return "file"
+	case OverloadSName:
+		return "sname"
+	case OverloadBoth:
+		return "file and sname"
+	}
+	return fmt.Sprintf("unknown (%d)", uint8(o))
+}
+
+// OptOptionOverload represents the option overload option.
+type OptOptionOverload struct {
+	Overload Overload
+}
+
+// ParseOptOptionOverload constructs an OptOptionOverload struct from a
+// sequence of bytes and returns it, or an error.
+func ParseOptOptionOverload(data []byte) (*OptOptionOverload, error) {
+	buf, err := newOptionLexer(data, OptionOptionOverload)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 1 {
+		return nil, fmt.Errorf("expected length 1, got %v instead", buf.Len())
+	}
+	return &OptOptionOverload{Overload: Overload(buf.Read8())}, nil
+}
+
+// Code returns the option code.
+func (o *OptOptionOverload) Code() OptionCode {
+	return OptionOptionOverload
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptOptionOverload) ToBytes() []byte {
+	return []byte{byte(o.Code()), byte(o.Length()), byte(o.Overload)}
+}
+
+// String returns a human-readable string for this option.
+func (o *OptOptionOverload) String() string {
+	return fmt.Sprintf("Option Overload -> %v", o.Overload)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptOptionOverload) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptOptionOverload) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptOptionOverload) Length() int {
+	return 1
+}
+
+// overloadedOptionsSize is the size of the options field, magic cookie
+// excluded, when the file and sname fields are overloaded: the options spill
+// over into them only beyond it, so that the packet fits in MaxMessageSize.
+const overloadedOptionsSize = MaxMessageSize - HeaderSize - 4
+
+// rawOverload returns the value of the option overload option among the raw
+// options, or 0 if there is none.
+func rawOverload(raw []rawOption) Overload {
+	for _, opt := range raw {
+		if opt.code == OptionOptionOverload && len(opt.data) == 1 {
+			return Overload(opt.data[0])
+		}
+	}
+	return 0
+}
+
+// overloadedOptions adds to the raw options of the options field the ones
+// held by the header fields that the option overload option designates,
+// before the End option and in the order RFC 2131 mandates: file first, then
+// sname. The fields are then cleared, since they do not hold names. Pad
+// options are dropped, as they only fill the fields.
+func (d *DHCPv4) overloadedOptions(raw []rawOption, overload Overload) ([]rawOption, error) {
+	var extra []rawOption
+	split := func(field []byte) error {
+		// split a copy, since the options keep referencing their data
+		opts, err := splitOptions(append([]byte{}, field...))
+		if err != nil {
+			return err
+		}
+		for _, opt := range opts {
+			if opt.code != OptionPad && opt.code != OptionEnd {
+				extra = append(extra, opt)
+			}
+		}
+		for i := range field {
+			field[i] = 0
+		}
+		return nil
+	}
+	if overload&OverloadFile != 0 {
+		if err := split(d.bootFileName[:]); err != nil {
+			return nil, fmt.Errorf("invalid options in file field: %v", err)
+		}
+	}
+	if overload&OverloadSName != 0 {
+		if err := split(d.serverHostName[:]); err != nil {
+			return nil, fmt.Errorf("invalid options in sname field: %v", err)
+		}
+	}
+	if len(extra) == 0 {
+		return raw, nil
+	}
+	options := make([]rawOption, 0, len(raw)+len(extra))
+	for i, opt := range raw {
+		if opt.code == OptionEnd {
+			options = append(options, extra...)
+			return append(options, raw[i:]...), nil
+		}
+		options = append(options, opt)
+	}
+	return append(options, extra...), nil
+}
+
+// overloadOptions lays the options out in the options field and in the header
+// fields that the option overload option designates, filled in this order. The
+// options for the designated fields are written into hdr, each followed by an
+// End option, and the ones for the options field are returned, End included.
+// If the options do not fit, or if the option overload option itself would
+// not end up in the options field, all of them go in the options field.
+func (d *DHCPv4) overloadOptions(hdr []byte, overload Overload) []Option {
+	var (
+		areas   [3][]Option
+		sizes   = [3]int{overloadedOptionsSize, 0, 0}
+		options []Option
+	)
+	if overload&OverloadFile != 0 {
+		sizes[1] = len(d.bootFileName)
+	}
+	if overload&OverloadSName != 0 {
+		sizes[2] = len(d.serverHostName)
+	}
+	for _, opt := range d.options {
+		if opt.Code() == OptionEnd {
+			break
+		}
+		options = append(options, opt)
+	}
+	area, used := 0, 0
+	for _, opt := range options {
+		size := optionSize(opt)
+		// keep one byte of each area for the End option
+		for area < len(areas) && used+size > sizes[area]-1 {
+			area, used = area+1, 0
+		}
+		if area == len(areas) || (area > 0 && opt.Code() == OptionOptionOverload) {
+			areas = [3][]Option{options}
+			break
+		}
+		areas[area] = append(areas[area], opt)
+		used += size
+	}
+	end := &OptionGeneric{OptionCode: OptionEnd}
+	write := func(field []byte, opts []Option) {
+		buf := field[:0]
+		for _, opt := range opts {
+			buf = appendOption(buf, opt)
+		}
+		buf = append(buf, byte(OptionEnd))
+		for i := len(buf); i < len(field); i++ {
+			field[i] = 0
+		}
+	}
+	if overload&OverloadFile != 0 {
+		write(hdr[108:236], areas[1])
+	}
+	if overload&OverloadSName != 0 {
+		write(hdr[44:108], areas[2])
+	}
+	return append(areas[0], end)
+}
@@ -0,0 +1,153 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptOptionOverloadInterfaceMethods(t *testing.T) {
+	o := OptOptionOverload{Overload: OverloadBoth}
+	require.Equal(t, OptionOptionOverload, o.Code(), "Code")
+	require.Equal(t, 1, o.Length(), "Length")
+	require.Equal(t, []byte{52, 1, 3}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Option Overload -> file and sname", o.String())
+}
+
+func TestParseOptOptionOverload(t *testing.T) {
+	o, err := ParseOptOptionOverload([]byte{52, 1, 1})
+	require.NoError(t, err)
+	require.Equal(t, &OptOptionOverload{Overload: OverloadFile}, o)
+
+	// Short byte stream
+	_, err = ParseOptOptionOverload([]byte{52, 1})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptOptionOverload([]byte{53, 1, 1})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	_, err = ParseOptOptionOverload([]byte{52, 2, 1, 1})
+	require.Error(t, err, "should get error from bad length")
+}
+
+func TestFromBytesOptionOverload(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.AddOption(&OptMessageType{MessageType: MessageTypeAck})
+	d.AddOption(&OptOptionOverload{Overload: OverloadBoth})
+	data := d.ToBytes()
+	// the file field holds the domain name, the sname field the host name,
+	// and each is terminated by End
+	file := append((&OptDomainName{DomainName: "example.com"}).ToBytes(), 255)
+	sname := append((&OptHostName{HostName: "client"}).ToBytes(), 255)
+	copy(data[108:236], file)
+	copy(data[44:108], sname)
+
+	parsed, err := FromBytes(data)
+	require.NoError(t, err)
+	require.Equal(t, []Option{
+		&OptMessageType{MessageType: MessageTypeAck},
+		&OptOptionOverload{Overload: OverloadBoth},
+		&OptDomainName{DomainName: "example.com"},
+		&OptHostName{HostName: "client"},
+		&OptionGeneric{OptionCode: OptionEnd},
+	}, parsed.Options())
+	// the fields do not hold names
+	require.Equal(t, "", parsed.BootFileNameToString())
+	require.Equal(t, "", parsed.ServerHostNameToString())
+
+	// all of them fit in the options field once serialized again
+	data = parsed.ToBytes()
+	require.Equal(t, byte(OptionEnd), data[44])
+	require.Equal(t, byte(OptionEnd), data[108])
+	reparsed, err := FromBytes(data)
+	require.NoError(t, err)
+	require.Equal(t, parsed.Options(), reparsed.Options())
+}
+
+func TestFromBytesOptionOverloadInvalid(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.AddOption(&OptOptionOverload{Overload: OverloadSName})
+	data := d.ToBytes()
+	// truncated option in sname
+	copy(data[44:108], []byte{3, 10})
+	_, err = FromBytes(data)
+	require.Error(t, err)
+}
+
+func TestToBytesOptionOverload(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.SetServerHostName("server")
+	d.AddOption(&OptOptionOverload{Overload: OverloadFile})
+	// the sixth option does not fit in the options field
+	for i := 0; i < 6; i++ {
+		d.AddOption(&OptionGeneric{OptionCode: OptionCode(200 + i), Data: make([]byte, 60)})
+	}
+	data := d.ToBytes()
+	require.True(t, len(data) <= MaxMessageSize, "packet too long: %d bytes", len(data))
+	require.Equal(t, OptionCode(205), OptionCode(data[108]))
+	// the sname field is not overloaded
+	parsed, err := FromBytes(data)
+	require.NoError(t, err)
+	require.Equal(t, "server", parsed.ServerHostNameToString())
+	require.Equal(t, d.Options(), parsed.Options())
+}
+
+func TestToBytesOptionOverloadTooLong(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.AddOption(&OptOptionOverload{Overload: OverloadBoth})
+	for i := 0; i < 10; i++ {
+		d.AddOption(&OptionGeneric{OptionCode: OptionCode(200 + i), Data: make([]byte, 60)})
+	}
+	data := d.ToBytes()
+	// everything went in the options field
+	require.Equal(t, HeaderSize+4+3+10*62+1, len(data))
+	require.Equal(t, byte(OptionEnd), data[44])
+	require.Equal(t, byte(OptionEnd), data[108])
+	parsed, err := FromBytes(data)
+	require.NoError(t, err)
+	require.Equal(t, d.Options(), parsed.Options())
+}
+
+func TestToBytesWithMaxSize(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.AddOption(&OptMessageType{MessageType: MessageTypeOffer})
+	data, err := d.ToBytesWithMaxSize(MaxMessageSize)
+	require.NoError(t, err)
+	require.Equal(t, MinMessageSize, len(data))
+	parsed, err := FromBytes(data)
+	require.NoError(t, err)
+	require.Equal(t, d.StrippedOptions(), parsed.StrippedOptions())
+
+	// the options spill over into sname and file
+	for i := 0; i < 6; i++ {
+		d.AddOption(&OptionGeneric{OptionCode: OptionCode(200 + i), Data: make([]byte, 60)})
+	}
+	require.True(t, len(d.ToBytes()) > MaxMessageSize)
+	data, err = d.ToBytesWithMaxSize(MaxMessageSize)
+	require.NoError(t, err)
+	require.True(t, len(data) <= MaxMessageSize, "packet too long: %d bytes", len(data))
+	parsed, err = FromBytes(data)
+	require.NoError(t, err)
+	require.Equal(t, &OptOptionOverload{Overload: OverloadBoth}, parsed.GetOneOption(OptionOptionOverload))
+	require.Equal(t, d.Options(), parsed.Options()[1:])
+	// the packet itself is unchanged
+	require.Nil(t, d.GetOneOption(OptionOptionOverload))
+
+	// unless the receiver accepts it as is
+	data, err = d.ToBytesWithMaxSize(1500)
+	require.NoError(t, err)
+	require.Equal(t, d.ToBytes(), data)
+
+	// only empty fields are overloaded
+	d.SetServerHostName("server")
+	d.SetBootFileName("pxelinux.0")
+	_, err = d.ToBytesWithMaxSize(MaxMessageSize)
+	require.Equal(t, ErrMessageTooLarge, err)
+}
@@ -16,21 +16,13 @@ type OptParameterRequestList struct {
 // ParseOptParameterRequestList returns a new OptParameterRequestList from a
 // byte stream, or error if any.
 func ParseOptParameterRequestList(data []byte) (*OptParameterRequestList, error) {
-	// Should at least have code + length byte.
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
-	}
-	code := OptionCode(data[0])
-	if code != OptionParameterRequestList {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionParameterRequestList, code)
-	}
-	length := int(data[1])
-	if len(data) < length+2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionParameterRequestList)
+	if err != nil {
+		return nil, err
 	}
 	var requestedOpts []OptionCode
-	for _, opt := range data[2 : length+2] {
-		requestedOpts = append(requestedOpts, OptionCode(opt))
+	for buf.Has(1) {
+		requestedOpts = append(requestedOpts, OptionCode(buf.Read8()))
 	}
 	return &OptParameterRequestList{RequestedOpts: requestedOpts}, nil
 }
@@ -62,6 +54,16 @@ func (o *OptParameterRequestList) String() string {
 	return fmt.Sprintf("Parameter Request List -> [%v]", strings.Join(optNames, ", "))
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptParameterRequestList) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptParameterRequestList) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code and byte
 // for length, if any).
 func (o *OptParameterRequestList) Length() int {
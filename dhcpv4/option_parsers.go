@@ -0,0 +1,48 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OptionParser parses an option, given as code, length and data like
+// ParseOption expects, into a specific type.
+type OptionParser func(data []byte) (Option, error)
+
+var (
+	optionParsersMutex sync.RWMutex
+	optionParsers      = make(map[OptionCode]OptionParser)
+)
+
+// RegisterOptionParser registers the parser of the options with the given
+// code, so that ParseOption and the packet parsers return the type of a
+// proprietary option, e.g. a site-specific one between 224 and 254, instead
+// of OptionGeneric. A later registration for the same code replaces the
+// earlier one, and a nil parser removes the registration. It returns an error
+// for the codes whose format the library knows, since the library relies on
+// the types it returns for them, e.g. to read the DHCP Message Type.
+//
+// The parser only sees options of up to 255 bytes of data: the longer ones,
+// split in several instances as described in RFC 3396, are returned as
+// OptionGeneric unless the library knows their format.
+func RegisterOptionParser(code OptionCode, parser OptionParser) error {
+	if libraryParsesOption(code) {
+		return fmt.Errorf("cannot register a parser for option %v: it is parsed by the library", code)
+	}
+	optionParsersMutex.Lock()
+	defer optionParsersMutex.Unlock()
+	if parser == nil {
+		delete(optionParsers, code)
+		return nil
+	}
+	optionParsers[code] = parser
+	return nil
+}
+
+// LookupOptionParser returns the parser registered for the given code, or
+// nil if there is none.
+func LookupOptionParser(code OptionCode) OptionParser {
+	optionParsersMutex.RLock()
+	defer optionParsersMutex.RUnlock()
+	return optionParsers[code]
+}
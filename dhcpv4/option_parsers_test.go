@@ -0,0 +1,82 @@
+package dhcpv4
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// optSiteLocal is a proprietary option holding a single byte.
+type optSiteLocal struct {
+	Value uint8
+}
+
+func (o *optSiteLocal) Code() OptionCode { return 224 }
+func (o *optSiteLocal) ToBytes() []byte  { return []byte{224, 1, o.Value} }
+func (o *optSiteLocal) Length() int      { return 1 }
+func (o *optSiteLocal) String() string   { return fmt.Sprintf("Site Local -> %d", o.Value) }
+
+func parseOptSiteLocal(data []byte) (Option, error) {
+	if len(data) != 3 || data[1] != 1 {
+		return nil, errors.New("invalid site local option")
+	}
+	return &optSiteLocal{Value: data[2]}, nil
+}
+
+func TestRegisterOptionParser(t *testing.T) {
+	data := []byte{224, 1, 42}
+	opt, err := ParseOption(data)
+	require.NoError(t, err)
+	require.IsType(t, &OptionGeneric{}, opt)
+	require.Nil(t, LookupOptionParser(224))
+
+	require.NoError(t, RegisterOptionParser(224, parseOptSiteLocal))
+	defer RegisterOptionParser(224, nil)
+	require.NotNil(t, LookupOptionParser(224))
+
+	opt, err = ParseOption(data)
+	require.NoError(t, err)
+	require.Equal(t, &optSiteLocal{Value: 42}, opt)
+
+	_, err = ParseOption([]byte{224, 2, 42, 43})
+	require.Error(t, err)
+	require.IsType(t, &OptionParseError{}, err)
+	require.Equal(t, OptionCode(224), err.(*OptionParseError).Code)
+
+	// the options of a packet go through the registered parser too
+	d, err := New()
+	require.NoError(t, err)
+	d.AddOption(&optSiteLocal{Value: 7})
+	d, err = FromBytes(d.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, &optSiteLocal{Value: 7}, d.GetOneOption(224))
+
+	require.NoError(t, RegisterOptionParser(224, nil))
+	require.Nil(t, LookupOptionParser(224))
+	opt, err = ParseOption(data)
+	require.NoError(t, err)
+	require.IsType(t, &OptionGeneric{}, opt)
+}
+
+func TestRegisterOptionParserLibraryOption(t *testing.T) {
+	for _, code := range []OptionCode{OptionHostName, OptionDHCPMessageType, OptionServerIdentifier} {
+		err := RegisterOptionParser(code, func(data []byte) (Option, error) {
+			return &OptionGeneric{OptionCode: code, Data: data[2:]}, nil
+		})
+		require.Error(t, err)
+		require.Nil(t, LookupOptionParser(code))
+	}
+	opt, err := ParseOption([]byte{byte(OptionHostName), 2, 'h', 'i'})
+	require.NoError(t, err)
+	require.IsType(t, &OptHostName{}, opt)
+}
+
+func TestLibraryParsesOption(t *testing.T) {
+	for code := 0; code < 256; code++ {
+		opt, err := ParseOption([]byte{byte(code), 0})
+		_, generic := opt.(*OptionGeneric)
+		require.Equal(t, err != nil || !generic, libraryParsesOption(OptionCode(code)), "option %d", code)
+	}
+}
@@ -0,0 +1,57 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the Rapid Commit option.
+// https://tools.ietf.org/html/rfc4039
+
+// OptRapidCommit represents the Rapid Commit option. It has no data: a client
+// includes it in a Discover to accept an Ack right away, and a server includes
+// it in such an Ack.
+type OptRapidCommit struct{}
+
+// ParseOptRapidCommit constructs an OptRapidCommit struct from a sequence of
+// bytes and returns it, or an error.
+func ParseOptRapidCommit(data []byte) (*OptRapidCommit, error) {
+	buf, err := newOptionLexer(data, OptionRapidCommit)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 0 {
+		return nil, fmt.Errorf("expected length 0, got %v instead", buf.Len())
+	}
+	return &OptRapidCommit{}, nil
+}
+
+// Code returns the option code.
+func (o *OptRapidCommit) Code() OptionCode {
+	return OptionRapidCommit
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptRapidCommit) ToBytes() []byte {
+	return []byte{byte(o.Code()), byte(o.Length())}
+}
+
+// String returns a human-readable string for this option.
+func (o *OptRapidCommit) String() string {
+	return "Rapid Commit"
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptRapidCommit) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptRapidCommit) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptRapidCommit) Length() int {
+	return 0
+}
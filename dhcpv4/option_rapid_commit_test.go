@@ -0,0 +1,33 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptRapidCommitInterfaceMethods(t *testing.T) {
+	o := OptRapidCommit{}
+	require.Equal(t, OptionRapidCommit, o.Code(), "Code")
+	require.Equal(t, 0, o.Length(), "Length")
+	require.Equal(t, []byte{80, 0}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Rapid Commit", o.String())
+}
+
+func TestParseOptRapidCommit(t *testing.T) {
+	o, err := ParseOptRapidCommit([]byte{80, 0})
+	require.NoError(t, err)
+	require.Equal(t, &OptRapidCommit{}, o)
+
+	// Short byte stream
+	_, err = ParseOptRapidCommit([]byte{80})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptRapidCommit([]byte{81, 0})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	_, err = ParseOptRapidCommit([]byte{80, 1, 0})
+	require.Error(t, err, "should get error from bad length")
+}
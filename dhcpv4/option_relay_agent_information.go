@@ -0,0 +1,189 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// This option implements the relay agent information option
+// https://tools.ietf.org/html/rfc3046
+
+// RelayAgentSubOptionCode is the code of a sub-option of the Relay Agent
+// Information option.
+type RelayAgentSubOptionCode uint8
+
+// Relay Agent Information sub-options
+const (
+	// RelayAgentCircuitID is defined in RFC 3046
+	RelayAgentCircuitID RelayAgentSubOptionCode = 1
+	// RelayAgentRemoteID is defined in RFC 3046
+	RelayAgentRemoteID RelayAgentSubOptionCode = 2
+	// RelayAgentLinkSelection is defined in RFC 3527
+	RelayAgentLinkSelection RelayAgentSubOptionCode = 5
+	// RelayAgentSubscriberID is defined in RFC 3993
+	RelayAgentSubscriberID RelayAgentSubOptionCode = 6
+	// RelayAgentVirtualSubnetSelection is defined in RFC 6607
+	RelayAgentVirtualSubnetSelection RelayAgentSubOptionCode = 151
+)
+
+// RelayAgentSubOptionCodeToString maps relay agent sub-option codes to their
+// names.
+var RelayAgentSubOptionCodeToString = map[RelayAgentSubOptionCode]string{
+	RelayAgentCircuitID:              "Circuit ID",
+	RelayAgentRemoteID:               "Remote ID",
+	RelayAgentLinkSelection:          "Link Selection",
+	RelayAgentSubscriberID:           "Subscriber ID",
+	RelayAgentVirtualSubnetSelection: "Virtual Subnet Selection",
+}
+
+func (c RelayAgentSubOptionCode) String() string {
+	if s, ok := RelayAgentSubOptionCodeToString[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown (%d)", uint8(c))
+}
+
+// RelayAgentSubOption is a single sub-option of the Relay Agent Information
+// option.
+type RelayAgentSubOption struct {
+	Code RelayAgentSubOptionCode
+	Data []byte
+}
+
+// OptRelayAgentInformation represents the Relay Agent Information option,
+// holding the sub-options in the order they appear on the wire.
+type OptRelayAgentInformation struct {
+	Options []RelayAgentSubOption
+}
+
+// ParseOptRelayAgentInformation returns a new OptRelayAgentInformation from a
+// byte stream, or error if any.
+func ParseOptRelayAgentInformation(data []byte) (*OptRelayAgentInformation, error) {
+	buf, err := newOptionLexer(data, OptionRelayAgentInformation)
+	if err != nil {
+		return nil, err
+	}
+	var opts []RelayAgentSubOption
+	for buf.Has(1) {
+		code := RelayAgentSubOptionCode(buf.Read8())
+		length := int(buf.Read8())
+		subData := buf.Consume(length)
+		if buf.Error() != nil {
+			return nil, ErrShortByteStream
+		}
+		opts = append(opts, RelayAgentSubOption{Code: code, Data: subData})
+	}
+	return &OptRelayAgentInformation{Options: opts}, nil
+}
+
+// Code returns the option code.
+func (o *OptRelayAgentInformation) Code() OptionCode {
+	return OptionRelayAgentInformation
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptRelayAgentInformation) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length())}
+	for _, opt := range o.Options {
+		ret = append(ret, byte(opt.Code), byte(len(opt.Data)))
+		ret = append(ret, opt.Data...)
+	}
+	return ret
+}
+
+// String returns a human-readable string.
+func (o *OptRelayAgentInformation) String() string {
+	var subs []string
+	for _, opt := range o.Options {
+		var value string
+		switch opt.Code {
+		case RelayAgentLinkSelection:
+			value = net.IP(opt.Data).String()
+		default:
+			value = fmt.Sprintf("%v", opt.Data)
+		}
+		subs = append(subs, fmt.Sprintf("%v: %v", opt.Code, value))
+	}
+	return fmt.Sprintf("Relay Agent Information -> %v", strings.Join(subs, ", "))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptRelayAgentInformation) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptRelayAgentInformation) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code an byte
+// length).
+func (o *OptRelayAgentInformation) Length() int {
+	var length int
+	for _, opt := range o.Options {
+		length += 2 + len(opt.Data)
+	}
+	return length
+}
+
+// GetSubOption returns the data of the first sub-option with the given code,
+// or nil if there is none.
+func (o *OptRelayAgentInformation) GetSubOption(code RelayAgentSubOptionCode) []byte {
+	for _, opt := range o.Options {
+		if opt.Code == code {
+			return opt.Data
+		}
+	}
+	return nil
+}
+
+// AddSubOption appends a sub-option.
+func (o *OptRelayAgentInformation) AddSubOption(code RelayAgentSubOptionCode, data []byte) {
+	o.Options = append(o.Options, RelayAgentSubOption{Code: code, Data: data})
+}
+
+// CircuitID returns the Circuit ID sub-option, or nil if not present.
+func (o *OptRelayAgentInformation) CircuitID() []byte {
+	return o.GetSubOption(RelayAgentCircuitID)
+}
+
+// RemoteID returns the Remote ID sub-option, or nil if not present.
+func (o *OptRelayAgentInformation) RemoteID() []byte {
+	return o.GetSubOption(RelayAgentRemoteID)
+}
+
+// LinkSelection returns the address of the Link Selection sub-option, or nil
+// if not present or invalid.
+func (o *OptRelayAgentInformation) LinkSelection() net.IP {
+	data := o.GetSubOption(RelayAgentLinkSelection)
+	if len(data) != net.IPv4len {
+		return nil
+	}
+	return net.IP(data)
+}
+
+// SetLinkSelection sets the Link Selection sub-option to ip, replacing the
+// existing one if any.
+func (o *OptRelayAgentInformation) SetLinkSelection(ip net.IP) {
+	for i, opt := range o.Options {
+		if opt.Code == RelayAgentLinkSelection {
+			o.Options[i].Data = ip.To4()
+			return
+		}
+	}
+	o.AddSubOption(RelayAgentLinkSelection, ip.To4())
+}
+
+// SubscriberID returns the Subscriber ID sub-option, or an empty string if not
+// present.
+func (o *OptRelayAgentInformation) SubscriberID() string {
+	return string(o.GetSubOption(RelayAgentSubscriberID))
+}
+
+// VirtualSubnetSelection returns the raw Virtual Subnet Selection sub-option,
+// including its type byte, or nil if not present.
+func (o *OptRelayAgentInformation) VirtualSubnetSelection() []byte {
+	return o.GetSubOption(RelayAgentVirtualSubnetSelection)
+}
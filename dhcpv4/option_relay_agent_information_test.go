@@ -0,0 +1,87 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptRelayAgentInformationInterfaceMethods(t *testing.T) {
+	o := OptRelayAgentInformation{}
+	o.AddSubOption(RelayAgentCircuitID, []byte("eth0"))
+	o.AddSubOption(RelayAgentLinkSelection, []byte{10, 0, 0, 0})
+
+	require.Equal(t, OptionRelayAgentInformation, o.Code(), "Code")
+	expected := []byte{
+		82, 12,
+		1, 4, 'e', 't', 'h', '0',
+		5, 4, 10, 0, 0, 0,
+	}
+	require.Equal(t, expected, o.ToBytes(), "ToBytes")
+	require.Equal(t, 12, o.Length(), "Length")
+	require.Equal(t, "Relay Agent Information -> Circuit ID: [101 116 104 48], Link Selection: 10.0.0.0", o.String(), "String")
+}
+
+func TestParseOptRelayAgentInformation(t *testing.T) {
+	var (
+		o   *OptRelayAgentInformation
+		err error
+	)
+	o, err = ParseOptRelayAgentInformation([]byte{})
+	require.Error(t, err, "empty byte stream")
+
+	o, err = ParseOptRelayAgentInformation([]byte{82, 4, 1, 2})
+	require.Error(t, err, "short byte stream")
+
+	o, err = ParseOptRelayAgentInformation([]byte{82, 1, 1})
+	require.Error(t, err, "truncated sub-option header")
+
+	o, err = ParseOptRelayAgentInformation([]byte{82, 3, 1, 2, 'a'})
+	require.Error(t, err, "truncated sub-option data")
+
+	o, err = ParseOptRelayAgentInformation([]byte{53, 0})
+	require.Error(t, err, "wrong option code")
+
+	o, err = ParseOptRelayAgentInformation([]byte{
+		82, 25,
+		1, 4, 'e', 't', 'h', '0',
+		2, 3, 'r', 'i', 'd',
+		5, 4, 10, 0, 0, 0,
+		6, 3, 's', 'u', 'b',
+		151, 1, 255,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, len(o.Options))
+	require.Equal(t, []byte("eth0"), o.CircuitID())
+	require.Equal(t, []byte("rid"), o.RemoteID())
+	require.Equal(t, net.IP{10, 0, 0, 0}, o.LinkSelection())
+	require.Equal(t, "sub", o.SubscriberID())
+	require.Equal(t, []byte{255}, o.VirtualSubnetSelection())
+}
+
+func TestOptRelayAgentInformationSetLinkSelection(t *testing.T) {
+	o := OptRelayAgentInformation{}
+	o.AddSubOption(RelayAgentCircuitID, []byte("eth0"))
+	o.SetLinkSelection(net.IPv4(10, 0, 1, 0))
+	require.Equal(t, net.IP{10, 0, 1, 0}, o.LinkSelection())
+	o.SetLinkSelection(net.IP{10, 0, 2, 0})
+	require.Equal(t, net.IP{10, 0, 2, 0}, o.LinkSelection())
+	require.Len(t, o.Options, 2)
+	require.Equal(t, []byte("eth0"), o.CircuitID())
+}
+
+func TestOptRelayAgentInformationMissingSubOptions(t *testing.T) {
+	o := OptRelayAgentInformation{}
+	o.AddSubOption(RelayAgentLinkSelection, []byte{10, 0})
+	require.Nil(t, o.CircuitID())
+	require.Nil(t, o.RemoteID())
+	require.Nil(t, o.LinkSelection(), "invalid link selection")
+	require.Equal(t, "", o.SubscriberID())
+	require.Nil(t, o.VirtualSubnetSelection())
+}
+
+func TestRelayAgentSubOptionCodeString(t *testing.T) {
+	require.Equal(t, "Circuit ID", RelayAgentCircuitID.String())
+	require.Equal(t, "Unknown (42)", RelayAgentSubOptionCode(42).String())
+}
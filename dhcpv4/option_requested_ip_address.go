@@ -17,21 +17,14 @@ type OptRequestedIPAddress struct {
 // ParseOptRequestedIPAddress returns a new OptServerIdentifier from a byte
 // stream, or error if any.
 func ParseOptRequestedIPAddress(data []byte) (*OptRequestedIPAddress, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionRequestedIPAddress)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionRequestedIPAddress {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionRequestedIPAddress, code)
+	if buf.Len() != 4 {
+		return nil, fmt.Errorf("expected length 4, got %v instead", buf.Len())
 	}
-	length := int(data[1])
-	if length != 4 {
-		return nil, fmt.Errorf("unexepcted length: expected 4, got %v", length)
-	}
-	if len(data) < 6 {
-		return nil, ErrShortByteStream
-	}
-	return &OptRequestedIPAddress{RequestedAddr: net.IP(data[2 : 2+length])}, nil
+	return &OptRequestedIPAddress{RequestedAddr: net.IP(buf.Consume(4))}, nil
 }
 
 // Code returns the option code.
@@ -50,6 +43,16 @@ func (o *OptRequestedIPAddress) String() string {
 	return fmt.Sprintf("Requested IP Address -> %v", o.RequestedAddr.String())
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptRequestedIPAddress) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptRequestedIPAddress) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptRequestedIPAddress) Length() int {
@@ -15,19 +15,11 @@ type OptRootPath struct {
 // ParseOptRootPath constructs an OptRootPath struct from a sequence of  bytes
 // and returns it, or an error.
 func ParseOptRootPath(data []byte) (*OptRootPath, error) {
-	// Should at least have code and length
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionRootPath)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionRootPath {
-		return nil, fmt.Errorf("expected option %v, got %v instead", OptionRootPath, code)
-	}
-	length := int(data[1])
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
-	}
-	return &OptRootPath{Path: string(data[2 : 2+length])}, nil
+	return &OptRootPath{Path: string(buf.ReadAll())}, nil
 }
 
 // Code returns the option code.
@@ -45,6 +37,16 @@ func (o *OptRootPath) String() string {
 	return fmt.Sprintf("Root Path -> %v", o.Path)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptRootPath) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptRootPath) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code and byte
 // for length, if any).
 func (o *OptRootPath) Length() int {
@@ -15,24 +15,13 @@ type OptRouter struct {
 
 // ParseOptRouter returns a new OptRouter from a byte stream, or error if any.
 func ParseOptRouter(data []byte) (*OptRouter, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionRouter)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionRouter {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionRouter, code)
-	}
-	length := int(data[1])
-	if length == 0 || length%4 != 0 {
-		return nil, fmt.Errorf("Invalid length: expected multiple of 4 larger than 4, got %v", length)
-	}
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
-	}
-	routers := make([]net.IP, 0, length%4)
-	for idx := 0; idx < length; idx += 4 {
-		b := data[2+idx : 2+idx+4]
-		routers = append(routers, net.IPv4(b[0], b[1], b[2], b[3]))
+	routers, err := readIPv4List(buf)
+	if err != nil {
+		return nil, err
 	}
 	return &OptRouter{Routers: routers}, nil
 }
@@ -63,6 +52,16 @@ func (o *OptRouter) String() string {
 	return fmt.Sprintf("Routers -> %v", routers)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptRouter) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptRouter) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptRouter) Length() int {
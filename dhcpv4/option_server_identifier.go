@@ -16,21 +16,14 @@ type OptServerIdentifier struct {
 // ParseOptServerIdentifier returns a new OptServerIdentifier from a byte
 // stream, or error if any.
 func ParseOptServerIdentifier(data []byte) (*OptServerIdentifier, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionServerIdentifier)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionServerIdentifier {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionServerIdentifier, code)
+	if buf.Len() != 4 {
+		return nil, fmt.Errorf("expected length 4, got %v instead", buf.Len())
 	}
-	length := int(data[1])
-	if length != 4 {
-		return nil, fmt.Errorf("unexepcted length: expected 4, got %v", length)
-	}
-	if len(data) < 6 {
-		return nil, ErrShortByteStream
-	}
-	return &OptServerIdentifier{ServerID: net.IP(data[2 : 2+length])}, nil
+	return &OptServerIdentifier{ServerID: net.IP(buf.Consume(4))}, nil
 }
 
 // Code returns the option code.
@@ -49,6 +42,16 @@ func (o *OptServerIdentifier) String() string {
 	return fmt.Sprintf("Server Identifier -> %v", o.ServerID.String())
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptServerIdentifier) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptServerIdentifier) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptServerIdentifier) Length() int {
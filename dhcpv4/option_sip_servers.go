@@ -0,0 +1,119 @@
+package dhcpv4
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/rfc1035label"
+	"github.com/insomniacslk/dhcp/uio"
+)
+
+// This option implements the SIP servers option
+// https://tools.ietf.org/html/rfc3361
+
+// The encodings of the SIP servers option.
+const (
+	SIPServersEncodingDomainNames uint8 = 0
+	SIPServersEncodingAddresses   uint8 = 1
+)
+
+// OptSIPServers represents the SIP servers option, which lists the outbound
+// proxies of a SIP user agent either by domain name or by address. The two
+// encodings cannot be mixed: Addresses is serialized if it is not empty, and
+// Domains otherwise.
+type OptSIPServers struct {
+	Domains   []string
+	Addresses []net.IP
+}
+
+// ParseOptSIPServers returns a new OptSIPServers from a byte stream, or error
+// if any.
+func ParseOptSIPServers(data []byte) (*OptSIPServers, error) {
+	buf, err := newOptionLexer(data, OptionSIPServersDHCPOption)
+	if err != nil {
+		return nil, err
+	}
+	return parseSIPServers(buf.ReadAll())
+}
+
+// parseSIPServers parses the data of an OptSIPServers.
+func parseSIPServers(data []byte) (*OptSIPServers, error) {
+	buf := uio.NewBigEndianBuffer(data)
+	encoding := buf.Read8()
+	if err := buf.Error(); err != nil {
+		return nil, ErrShortByteStream
+	}
+	switch encoding {
+	case SIPServersEncodingDomainNames:
+		domains, err := rfc1035label.LabelsFromBytes(buf.ReadAll())
+		if err != nil {
+			return nil, err
+		}
+		if len(domains) == 0 {
+			return nil, errors.New("no SIP server")
+		}
+		return &OptSIPServers{Domains: domains}, nil
+	case SIPServersEncodingAddresses:
+		addrs, err := readIPv4List(buf)
+		if err != nil {
+			return nil, err
+		}
+		return &OptSIPServers{Addresses: addrs}, nil
+	}
+	return nil, fmt.Errorf("unknown SIP servers encoding %d", encoding)
+}
+
+// Encoding returns the encoding the option is serialized with.
+func (o *OptSIPServers) Encoding() uint8 {
+	if len(o.Addresses) > 0 {
+		return SIPServersEncodingAddresses
+	}
+	return SIPServersEncodingDomainNames
+}
+
+// Code returns the option code.
+func (o *OptSIPServers) Code() OptionCode {
+	return OptionSIPServersDHCPOption
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptSIPServers) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length())}
+	return append(ret, o.data()...)
+}
+
+func (o *OptSIPServers) data() []byte {
+	ret := []byte{o.Encoding()}
+	if o.Encoding() == SIPServersEncodingAddresses {
+		for _, addr := range o.Addresses {
+			ret = append(ret, addr.To4()...)
+		}
+		return ret
+	}
+	return append(ret, rfc1035label.CompressedLabelsToBytes(o.Domains)...)
+}
+
+// String returns a human-readable string.
+func (o *OptSIPServers) String() string {
+	if o.Encoding() == SIPServersEncodingAddresses {
+		return fmt.Sprintf("SIP Servers -> %v", o.Addresses)
+	}
+	return fmt.Sprintf("SIP Servers -> %v", o.Domains)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptSIPServers) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptSIPServers) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code an byte
+// length).
+func (o *OptSIPServers) Length() int {
+	return len(o.data())
+}
@@ -0,0 +1,66 @@
+package dhcpv4
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptSIPServersInterfaceMethods(t *testing.T) {
+	o := OptSIPServers{Domains: []string{"sip.example.com", "sip2.example.com"}}
+	require.Equal(t, OptionSIPServersDHCPOption, o.Code(), "Code")
+	require.Equal(t, SIPServersEncodingDomainNames, o.Encoding())
+	data := []byte{
+		120, 25,
+		0,
+		3, 's', 'i', 'p', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0,
+		4, 's', 'i', 'p', '2', 0xc0, 0x04,
+	}
+	require.Equal(t, 25, o.Length(), "Length")
+	require.Equal(t, data, o.ToBytes(), "ToBytes")
+	require.Equal(t, "SIP Servers -> [sip.example.com sip2.example.com]", o.String())
+
+	o = OptSIPServers{Addresses: []net.IP{net.IPv4(192, 0, 2, 5), net.IPv4(192, 0, 2, 6)}}
+	require.Equal(t, SIPServersEncodingAddresses, o.Encoding())
+	require.Equal(t, []byte{120, 9, 1, 192, 0, 2, 5, 192, 0, 2, 6}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "SIP Servers -> [192.0.2.5 192.0.2.6]", o.String())
+}
+
+func TestParseOptSIPServers(t *testing.T) {
+	o, err := ParseOptSIPServers([]byte{120, 5, 1, 192, 0, 2, 5})
+	require.NoError(t, err)
+	require.Equal(t, &OptSIPServers{Addresses: []net.IP{net.IPv4(192, 0, 2, 5)}}, o)
+
+	o, err = ParseOptSIPServers([]byte{120, 6, 0, 3, 's', 'i', 'p', 0})
+	require.NoError(t, err)
+	require.Equal(t, &OptSIPServers{Domains: []string{"sip"}}, o)
+
+	// no encoding
+	_, err = ParseOptSIPServers([]byte{120, 0})
+	require.Error(t, err)
+	// unknown encoding
+	_, err = ParseOptSIPServers([]byte{120, 5, 2, 192, 0, 2, 5})
+	require.Error(t, err)
+	// no server
+	_, err = ParseOptSIPServers([]byte{120, 1, 0})
+	require.Error(t, err)
+	_, err = ParseOptSIPServers([]byte{120, 1, 1})
+	require.Error(t, err)
+	// truncated address
+	_, err = ParseOptSIPServers([]byte{120, 4, 1, 192, 0, 2})
+	require.Error(t, err)
+	// wrong code
+	_, err = ParseOptSIPServers([]byte{119, 5, 1, 192, 0, 2, 5})
+	require.Error(t, err)
+}
+
+func TestOptSIPServersLong(t *testing.T) {
+	long := &OptSIPServers{}
+	for i := 0; i < 30; i++ {
+		long.Domains = append(long.Domains, strings.Repeat("s", i+1)+".sip.example.com")
+	}
+	require.True(t, long.Length() > maxOptionLength)
+	require.Equal(t, long, CloneOption(long))
+}
@@ -0,0 +1,74 @@
+package dhcpv4
+
+import (
+	"fmt"
+)
+
+// This option implements the status code option
+// https://tools.ietf.org/html/rfc6926
+
+// StatusCode is the result of a bulk leasequery, as carried by the status code
+// option.
+type StatusCode uint8
+
+func (s StatusCode) String() string {
+	if name, ok := StatusCodeToString[s]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// OptStatusCode represents the result of a bulk leasequery, with an optional
+// UTF-8 message.
+type OptStatusCode struct {
+	StatusCode    StatusCode
+	StatusMessage string
+}
+
+// ParseOptStatusCode constructs an OptStatusCode struct from a sequence of
+// bytes and returns it, or an error.
+func ParseOptStatusCode(data []byte) (*OptStatusCode, error) {
+	buf, err := newOptionLexer(data, OptionStatusCode)
+	if err != nil {
+		return nil, err
+	}
+	// Should at least have the status code.
+	if buf.Len() < 1 {
+		return nil, ErrShortByteStream
+	}
+	return &OptStatusCode{
+		StatusCode:    StatusCode(buf.Read8()),
+		StatusMessage: string(buf.ReadAll()),
+	}, nil
+}
+
+// Code returns the option code.
+func (o *OptStatusCode) Code() OptionCode {
+	return OptionStatusCode
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptStatusCode) ToBytes() []byte {
+	return append([]byte{byte(o.Code()), byte(o.Length()), byte(o.StatusCode)}, []byte(o.StatusMessage)...)
+}
+
+// String returns a human-readable string for this option.
+func (o *OptStatusCode) String() string {
+	return fmt.Sprintf("Status Code -> %v, %v", o.StatusCode, o.StatusMessage)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptStatusCode) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptStatusCode) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptStatusCode) Length() int {
+	return 1 + len(o.StatusMessage)
+}
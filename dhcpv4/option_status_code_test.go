@@ -0,0 +1,47 @@
+package dhcpv4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptStatusCodeInterfaceMethods(t *testing.T) {
+	o := OptStatusCode{StatusCode: StatusNotAllowed, StatusMessage: "no"}
+	require.Equal(t, OptionStatusCode, o.Code(), "Code")
+	require.Equal(t, 3, o.Length(), "Length")
+	require.Equal(t, []byte{151, 3, 4, 'n', 'o'}, o.ToBytes(), "ToBytes")
+}
+
+func TestParseOptStatusCode(t *testing.T) {
+	data := []byte{151, 3, 3, 'b', 'a', 'd'}
+	o, err := ParseOptStatusCode(data)
+	require.NoError(t, err)
+	require.Equal(t, &OptStatusCode{StatusCode: StatusMalformedQuery, StatusMessage: "ba"}, o)
+
+	data = []byte{151, 1, 0}
+	o, err = ParseOptStatusCode(data)
+	require.NoError(t, err)
+	require.Equal(t, &OptStatusCode{StatusCode: StatusSuccess}, o)
+
+	// Short byte stream
+	data = []byte{151, 4, 0}
+	_, err = ParseOptStatusCode(data)
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	data = []byte{152, 1, 0}
+	_, err = ParseOptStatusCode(data)
+	require.Error(t, err, "should get error from wrong code")
+
+	// Bad length
+	data = []byte{151, 0, 0}
+	_, err = ParseOptStatusCode(data)
+	require.Error(t, err, "should get error from bad length")
+}
+
+func TestOptStatusCodeString(t *testing.T) {
+	o := OptStatusCode{StatusCode: StatusQueryTerminated, StatusMessage: "shutting down"}
+	require.Equal(t, "Status Code -> QueryTerminated, shutting down", o.String())
+	require.Equal(t, "Unknown", StatusCode(42).String())
+}
@@ -16,21 +16,14 @@ type OptSubnetMask struct {
 // ParseOptSubnetMask returns a new OptSubnetMask from a byte
 // stream, or error if any.
 func ParseOptSubnetMask(data []byte) (*OptSubnetMask, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
+	buf, err := newOptionLexer(data, OptionSubnetMask)
+	if err != nil {
+		return nil, err
 	}
-	code := OptionCode(data[0])
-	if code != OptionSubnetMask {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionSubnetMask, code)
+	if buf.Len() != 4 {
+		return nil, fmt.Errorf("expected length 4, got %v instead", buf.Len())
 	}
-	length := int(data[1])
-	if length != 4 {
-		return nil, fmt.Errorf("unexepcted length: expected 4, got %v", length)
-	}
-	if len(data) < 6 {
-		return nil, ErrShortByteStream
-	}
-	return &OptSubnetMask{SubnetMask: net.IPMask(data[2 : 2+length])}, nil
+	return &OptSubnetMask{SubnetMask: net.IPMask(buf.Consume(4))}, nil
 }
 
 // Code returns the option code.
@@ -49,6 +42,16 @@ func (o *OptSubnetMask) String() string {
 	return fmt.Sprintf("Subnet Mask -> %v", o.SubnetMask.String())
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptSubnetMask) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptSubnetMask) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code an byte
 // length).
 func (o *OptSubnetMask) Length() int {
@@ -0,0 +1,62 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"net"
+)
+
+// This option implements the subnet selection option
+// https://tools.ietf.org/html/rfc3011
+
+// OptSubnetSelection represents the subnet selection option, with which a
+// client or a relay agent asks the server to allocate an address on the subnet
+// of the given address, instead of the subnet of the giaddr or of the
+// interface the message was received on.
+type OptSubnetSelection struct {
+	Subnet net.IP
+}
+
+// ParseOptSubnetSelection returns a new OptSubnetSelection from a byte stream,
+// or error if any.
+func ParseOptSubnetSelection(data []byte) (*OptSubnetSelection, error) {
+	buf, err := newOptionLexer(data, OptionSubnetSelection)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 4 {
+		return nil, fmt.Errorf("expected length 4, got %v instead", buf.Len())
+	}
+	return &OptSubnetSelection{Subnet: net.IP(buf.Consume(4))}, nil
+}
+
+// Code returns the option code.
+func (o *OptSubnetSelection) Code() OptionCode {
+	return OptionSubnetSelection
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptSubnetSelection) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length())}
+	return append(ret, o.Subnet.To4()...)
+}
+
+// String returns a human-readable string.
+func (o *OptSubnetSelection) String() string {
+	return fmt.Sprintf("Subnet Selection -> %v", o.Subnet.String())
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptSubnetSelection) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptSubnetSelection) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code an byte
+// length).
+func (o *OptSubnetSelection) Length() int {
+	return len(o.Subnet.To4())
+}
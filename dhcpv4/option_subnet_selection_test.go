@@ -0,0 +1,34 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptSubnetSelectionInterfaceMethods(t *testing.T) {
+	o := OptSubnetSelection{Subnet: net.IPv4(10, 1, 2, 0)}
+	require.Equal(t, OptionSubnetSelection, o.Code(), "Code")
+	require.Equal(t, 4, o.Length(), "Length")
+	require.Equal(t, []byte{118, 4, 10, 1, 2, 0}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Subnet Selection -> 10.1.2.0", o.String())
+}
+
+func TestParseOptSubnetSelection(t *testing.T) {
+	o, err := ParseOptSubnetSelection([]byte{118, 4, 192, 168, 5, 1})
+	require.NoError(t, err)
+	require.Equal(t, &OptSubnetSelection{Subnet: net.IP{192, 168, 5, 1}}, o)
+
+	// Short byte stream
+	_, err = ParseOptSubnetSelection([]byte{118, 4, 192, 168})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong length
+	_, err = ParseOptSubnetSelection([]byte{118, 3, 192, 168, 5})
+	require.Error(t, err, "should get error from wrong length")
+
+	// Wrong code
+	_, err = ParseOptSubnetSelection([]byte{54, 4, 192, 168, 5, 1})
+	require.Error(t, err, "should get error from wrong code")
+}
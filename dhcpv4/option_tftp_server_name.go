@@ -31,24 +31,24 @@ func (op *OptTFTPServerName) String() string {
 	return fmt.Sprintf("TFTP Server Name -> %s", op.TFTPServerName)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (op *OptTFTPServerName) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(op)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (op *OptTFTPServerName) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, op)
+}
+
 // ParseOptTFTPServerName returns a new OptTFTPServerName fomr a byte stream or error if any
 func ParseOptTFTPServerName(data []byte) (*OptTFTPServerName, error) {
-	if len(data) < 3 {
-		return nil, ErrShortByteStream
-	}
-	code := OptionCode(data[0])
-	if code != OptionTFTPServerName {
-		return nil, fmt.Errorf("ParseOptTFTPServerName: invalid code: %v; want %v",
-			code, OptionTFTPServerName)
-	}
-	length := int(data[1])
-	if length < 1 {
-		return nil, fmt.Errorf("TFTP server name has invalid length of %d", length)
+	buf, err := newOptionLexer(data, OptionTFTPServerName)
+	if err != nil {
+		return nil, err
 	}
-	TFTPServerNameData := data[2:]
-	if len(TFTPServerNameData) < length {
-		return nil, fmt.Errorf("ParseOptTFTPServerName: short data: %d bytes; want %d",
-			len(TFTPServerNameData), length)
+	if buf.Len() < 1 {
+		return nil, fmt.Errorf("TFTP server name has invalid length of %d", buf.Len())
 	}
-	return &OptTFTPServerName{TFTPServerName: TFTPServerNameData[:length]}, nil
+	return &OptTFTPServerName{TFTPServerName: buf.ReadAll()}, nil
 }
@@ -0,0 +1,82 @@
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// This option implements the time offset option
+// https://tools.ietf.org/html/rfc2132#section-3.4
+//
+// RFC 4833 deprecates it in favor of the time zone options, since a fixed
+// offset ignores daylight saving time, but many clients still request it.
+
+// TimeOffset is the offset of the subnet of the client from UTC, in seconds.
+// It is positive east of the prime meridian.
+type TimeOffset int32
+
+// NewTimeOffset returns the TimeOffset of a duration, truncated to the second.
+func NewTimeOffset(d time.Duration) TimeOffset {
+	return TimeOffset(d / time.Second)
+}
+
+// Duration returns the offset as a time.Duration.
+func (t TimeOffset) Duration() time.Duration {
+	return time.Duration(t) * time.Second
+}
+
+func (t TimeOffset) String() string {
+	return t.Duration().String()
+}
+
+// OptTimeOffset represents the time offset option.
+type OptTimeOffset struct {
+	Offset TimeOffset
+}
+
+// ParseOptTimeOffset returns a new OptTimeOffset from a byte stream, or error
+// if any.
+func ParseOptTimeOffset(data []byte) (*OptTimeOffset, error) {
+	buf, err := newOptionLexer(data, OptionTimeOffset)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 4 {
+		return nil, fmt.Errorf("expected length 4, got %v instead", buf.Len())
+	}
+	return &OptTimeOffset{Offset: TimeOffset(int32(buf.Read32()))}, nil
+}
+
+// Code returns the option code.
+func (o *OptTimeOffset) Code() OptionCode {
+	return OptionTimeOffset
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptTimeOffset) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length()), 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(ret[2:], uint32(o.Offset))
+	return ret
+}
+
+// String returns a human-readable string for this option.
+func (o *OptTimeOffset) String() string {
+	return fmt.Sprintf("Time Offset -> %v", o.Offset)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptTimeOffset) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptTimeOffset) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptTimeOffset) Length() int {
+	return 4
+}
@@ -0,0 +1,44 @@
+package dhcpv4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptTimeOffsetInterfaceMethods(t *testing.T) {
+	o := OptTimeOffset{Offset: -3600}
+	require.Equal(t, OptionTimeOffset, o.Code(), "Code")
+	require.Equal(t, 4, o.Length(), "Length")
+	require.Equal(t, []byte{2, 4, 0xff, 0xff, 0xf1, 0xf0}, o.ToBytes(), "ToBytes")
+	require.Equal(t, "Time Offset -> -1h0m0s", o.String())
+}
+
+func TestParseOptTimeOffset(t *testing.T) {
+	o, err := ParseOptTimeOffset([]byte{2, 4, 0, 0, 0x1c, 0x20})
+	require.NoError(t, err)
+	require.Equal(t, &OptTimeOffset{Offset: 7200}, o)
+	require.Equal(t, 2*time.Hour, o.Offset.Duration())
+
+	o, err = ParseOptTimeOffset([]byte{2, 4, 0xff, 0xff, 0xf1, 0xf0})
+	require.NoError(t, err)
+	require.Equal(t, TimeOffset(-3600), o.Offset)
+
+	// Short byte stream
+	_, err = ParseOptTimeOffset([]byte{2, 4, 0, 0})
+	require.Error(t, err, "should get error from short byte stream")
+
+	// Wrong code
+	_, err = ParseOptTimeOffset([]byte{54, 4, 0, 0, 0, 0})
+	require.Error(t, err, "should get error from wrong code")
+
+	// Wrong length
+	_, err = ParseOptTimeOffset([]byte{2, 2, 0, 0})
+	require.Error(t, err, "should get error from bad length")
+}
+
+func TestNewTimeOffset(t *testing.T) {
+	require.Equal(t, TimeOffset(-19800), NewTimeOffset(-5*time.Hour-30*time.Minute))
+	require.Equal(t, TimeOffset(1), NewTimeOffset(1500*time.Millisecond))
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/insomniacslk/dhcp/uio"
 )
 
 // This option implements the User Class option
@@ -57,25 +59,27 @@ func (op *OptUserClass) String() string {
 	return fmt.Sprintf("User Class Information -> %v", strings.Join(ucStrings, ", "))
 }
 
+// MarshalJSON implements json.Marshaler.
+func (op *OptUserClass) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(op)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (op *OptUserClass) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, op)
+}
+
 // ParseOptUserClass returns a new OptUserClass from a byte stream or
 // error if any
 func ParseOptUserClass(data []byte) (*OptUserClass, error) {
-	opt := OptUserClass{}
-
-	if len(data) < 3 {
-		return nil, ErrShortByteStream
-	}
-	code := OptionCode(data[0])
-	if code != OptionUserClassInformation {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionUserClassInformation, code)
+	buf, err := newOptionLexer(data, OptionUserClassInformation)
+	if err != nil {
+		return nil, err
 	}
-
-	totalLength := int(data[1])
-	data = data[2:]
-	if len(data) < totalLength {
-		return nil, fmt.Errorf("ParseOptUserClass: short data: length is %d but got %d bytes",
-			totalLength, len(data))
+	if buf.Len() < 1 {
+		return nil, ErrShortByteStream
 	}
+	ucData := buf.ReadAll()
 
 	// Check if option is Microsoft style instead of RFC compliant, issue #113
 
@@ -84,30 +88,19 @@ func ParseOptUserClass(data []byte) (*OptUserClass, error) {
 	// by seeing if all the UC_Len_i lengths are consistent with the overall
 	// option length. If the lengths don't add up, we assume that the option
 	// is a single string and non RFC3004 compliant
-	var counting int
-	for counting < totalLength {
-		// UC_Len_i does not include itself so add 1
-		counting += int(data[counting]) + 1
+	opt := OptUserClass{Rfc3004: true}
+	ucBuf := uio.NewBigEndianBuffer(ucData)
+	for ucBuf.Has(1) {
+		ucLen := int(ucBuf.Read8())
+		opt.UserClasses = append(opt.UserClasses, ucBuf.Consume(ucLen))
 	}
-	if counting != totalLength {
-		opt.UserClasses = append(opt.UserClasses, data[:totalLength])
-		return &opt, nil
+	if ucBuf.Error() != nil {
+		return &OptUserClass{UserClasses: [][]byte{ucData}}, nil
 	}
-	opt.Rfc3004 = true
-	for i := 0; i < totalLength; {
-		ucLen := int(data[i])
-		if ucLen == 0 {
+	for _, uc := range opt.UserClasses {
+		if len(uc) == 0 {
 			return nil, errors.New("User Class value has invalid length of 0")
 		}
-		base := i + 1
-		if len(data) < base+ucLen {
-			return nil, fmt.Errorf("ParseOptUserClass: short data: %d bytes; want: %d", len(data), base+ucLen)
-		}
-		opt.UserClasses = append(opt.UserClasses, data[base:base+ucLen])
-		i += base + ucLen
-	}
-	if len(opt.UserClasses) < 1 {
-		return nil, errors.New("ParseOptUserClass: at least one user class is required")
 	}
 	return &opt, nil
 }
@@ -0,0 +1,252 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/insomniacslk/dhcp/uio"
+)
+
+// This option implements the Vendor Specific Information option
+// https://tools.ietf.org/html/rfc2132#section-8.4
+
+// VendorSubOption is a sub-option of the Vendor Specific Information option,
+// in the encapsulated format of RFC 2132. The Pad (0) and End (255)
+// sub-options are a single byte on the wire, and have no Data.
+type VendorSubOption struct {
+	Code uint8
+	Data []byte
+}
+
+// OptVendorSpecificInformation represents the Vendor Specific Information
+// option. Its content is defined by each vendor, most of which use the
+// encapsulated format of RFC 2132, that is a sequence of code/length/value
+// sub-options like the DHCP options themselves, e.g. PXE. When the content is
+// in that format, the parser fills in Options, in the order the sub-options
+// appear on the wire. Otherwise it fills in Data with the raw content. Data is
+// only serialized if Options is nil.
+//
+// The meaning of the sub-options depends on the vendor, usually identified by
+// the class identifier option. The vendor packages register the parsers of
+// their sub-options with RegisterVendorOptionParser, and Decode and
+// DHCPv4.VendorOptions use them to get the typed sub-options.
+type OptVendorSpecificInformation struct {
+	Options []VendorSubOption
+	Data    []byte
+}
+
+// ParseOptVendorSpecificInformation returns a new OptVendorSpecificInformation
+// from a byte stream, or error if any.
+func ParseOptVendorSpecificInformation(data []byte) (*OptVendorSpecificInformation, error) {
+	buf, err := newOptionLexer(data, OptionVendorSpecificInformation)
+	if err != nil {
+		return nil, err
+	}
+	return parseVendorSpecificInformation(buf.ReadAll()), nil
+}
+
+// parseVendorSpecificInformation parses the data of an
+// OptVendorSpecificInformation. It never fails, since any content that is not
+// in the encapsulated format is kept as is.
+func parseVendorSpecificInformation(data []byte) *OptVendorSpecificInformation {
+	if len(data) == 0 {
+		return &OptVendorSpecificInformation{}
+	}
+	var opts []VendorSubOption
+	buf := uio.NewBigEndianBuffer(data)
+	for buf.Has(1) {
+		code := buf.Read8()
+		if code == uint8(OptionPad) || code == uint8(OptionEnd) {
+			opts = append(opts, VendorSubOption{Code: code})
+			if code == uint8(OptionEnd) && buf.Has(1) {
+				// anything after End would be lost
+				return &OptVendorSpecificInformation{Data: data}
+			}
+			continue
+		}
+		length := int(buf.Read8())
+		subData := buf.Consume(length)
+		if buf.Error() != nil {
+			return &OptVendorSpecificInformation{Data: data}
+		}
+		opts = append(opts, VendorSubOption{Code: code, Data: subData})
+	}
+	return &OptVendorSpecificInformation{Options: opts}
+}
+
+// Code returns the option code.
+func (o *OptVendorSpecificInformation) Code() OptionCode {
+	return OptionVendorSpecificInformation
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptVendorSpecificInformation) ToBytes() []byte {
+	ret := []byte{byte(o.Code()), byte(o.Length())}
+	if o.Options == nil {
+		return append(ret, o.Data...)
+	}
+	for _, opt := range o.Options {
+		if opt.Code == uint8(OptionPad) || opt.Code == uint8(OptionEnd) {
+			ret = append(ret, opt.Code)
+			continue
+		}
+		ret = append(ret, opt.Code, byte(len(opt.Data)))
+		ret = append(ret, opt.Data...)
+	}
+	return ret
+}
+
+// String returns a human-readable string.
+func (o *OptVendorSpecificInformation) String() string {
+	if o.Options == nil {
+		return fmt.Sprintf("Vendor Specific Information -> %v", o.Data)
+	}
+	var subs []string
+	for _, opt := range o.Options {
+		if opt.Code == uint8(OptionPad) || opt.Code == uint8(OptionEnd) {
+			continue
+		}
+		subs = append(subs, fmt.Sprintf("%d: %v", opt.Code, opt.Data))
+	}
+	return fmt.Sprintf("Vendor Specific Information -> %v", strings.Join(subs, ", "))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptVendorSpecificInformation) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptVendorSpecificInformation) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and
+// byte length).
+func (o *OptVendorSpecificInformation) Length() int {
+	if o.Options == nil {
+		return len(o.Data)
+	}
+	var length int
+	for _, opt := range o.Options {
+		if opt.Code == uint8(OptionPad) || opt.Code == uint8(OptionEnd) {
+			length++
+			continue
+		}
+		length += 2 + len(opt.Data)
+	}
+	return length
+}
+
+// GetSubOption returns the data of the first sub-option with the given code,
+// or nil if there is none.
+func (o *OptVendorSpecificInformation) GetSubOption(code uint8) []byte {
+	for _, opt := range o.Options {
+		if opt.Code == code {
+			return opt.Data
+		}
+	}
+	return nil
+}
+
+// AddSubOption appends a sub-option. If the option held raw data, it is
+// discarded.
+func (o *OptVendorSpecificInformation) AddSubOption(code uint8, data []byte) {
+	o.Options = append(o.Options, VendorSubOption{Code: code, Data: data})
+	o.Data = nil
+}
+
+// VendorOptionParser parses a vendor sub-option, given as code, length and
+// data like ParseOption expects, into the vendor's specific type.
+type VendorOptionParser func(data []byte) (Option, error)
+
+var (
+	vendorOptionParsersMutex sync.RWMutex
+	vendorOptionParsers      = make(map[string]VendorOptionParser)
+)
+
+// RegisterVendorOptionParser registers the parser of the sub-options of the
+// vendors whose class identifier starts with classPrefix, e.g. "PXEClient".
+// A later registration for the same prefix replaces the earlier one.
+func RegisterVendorOptionParser(classPrefix string, parser VendorOptionParser) {
+	vendorOptionParsersMutex.Lock()
+	defer vendorOptionParsersMutex.Unlock()
+	vendorOptionParsers[classPrefix] = parser
+}
+
+// LookupVendorOptionParser returns the parser registered for the given class
+// identifier, the one with the longest matching prefix, or nil if there is
+// none.
+func LookupVendorOptionParser(classID string) VendorOptionParser {
+	vendorOptionParsersMutex.RLock()
+	defer vendorOptionParsersMutex.RUnlock()
+	var (
+		parser VendorOptionParser
+		best   = -1
+	)
+	for prefix, p := range vendorOptionParsers {
+		if strings.HasPrefix(classID, prefix) && len(prefix) > best {
+			parser, best = p, len(prefix)
+		}
+	}
+	return parser
+}
+
+// Decode parses the sub-options with the given parser, or as OptionGeneric if
+// parser is nil, and returns them in the order they appear on the wire,
+// without Pad and End. It returns an error if the content is not in the
+// encapsulated format.
+func (o *OptVendorSpecificInformation) Decode(parser VendorOptionParser) ([]Option, error) {
+	if o.Options == nil && len(o.Data) > 0 {
+		return nil, fmt.Errorf("vendor specific information is not encapsulated")
+	}
+	opts := make([]Option, 0, len(o.Options))
+	for _, sub := range o.Options {
+		if sub.Code == uint8(OptionPad) || sub.Code == uint8(OptionEnd) {
+			continue
+		}
+		if len(sub.Data) > maxOptionLength {
+			return nil, fmt.Errorf("vendor sub-option %d too long", sub.Code)
+		}
+		data := append([]byte{sub.Code, byte(len(sub.Data))}, sub.Data...)
+		var (
+			opt Option
+			err error
+		)
+		if parser != nil {
+			opt, err = parser(data)
+		} else {
+			opt, err = ParseOptionGeneric(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
+
+// VendorOptions returns the typed sub-options of the Vendor Specific
+// Information option of d, decoded with the parser registered for classID. If
+// classID is empty, the class identifier option of d is used; servers whose
+// replies do not carry it pass the one of the request. Without a registered
+// parser, the sub-options are returned as OptionGeneric. It returns nil if d
+// has no Vendor Specific Information option.
+func (d *DHCPv4) VendorOptions(classID string) ([]Option, error) {
+	opt := d.GetOneOption(OptionVendorSpecificInformation)
+	if opt == nil {
+		return nil, nil
+	}
+	vsi, ok := opt.(*OptVendorSpecificInformation)
+	if !ok {
+		// e.g. an OptionGeneric, or the option of a vendor package
+		vsi = parseVendorSpecificInformation(opt.ToBytes()[2:])
+	}
+	if classID == "" {
+		if ci, ok := d.GetOneOption(OptionClassIdentifier).(*OptClassIdentifier); ok {
+			classID = ci.Identifier
+		}
+	}
+	return vsi.Decode(LookupVendorOptionParser(classID))
+}
@@ -0,0 +1,115 @@
+package dhcpv4
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptVendorSpecificInformation(t *testing.T) {
+	data := []byte{
+		43, 10,
+		1, 4, 192, 168, 0, 1, // sub-option 1
+		0,    // Pad
+		2, 0, // empty sub-option 2
+		255, // End
+	}
+	opt, err := ParseOptVendorSpecificInformation(data)
+	require.NoError(t, err)
+	require.Equal(t, []VendorSubOption{
+		{Code: 1, Data: []byte{192, 168, 0, 1}},
+		{Code: 0},
+		{Code: 2, Data: []byte{}},
+		{Code: 255},
+	}, opt.Options)
+	require.Nil(t, opt.Data)
+	require.Equal(t, []byte{192, 168, 0, 1}, opt.GetSubOption(1))
+	require.Nil(t, opt.GetSubOption(3))
+	require.Equal(t, 10, opt.Length())
+	require.Equal(t, data, opt.ToBytes())
+	require.Equal(t, "Vendor Specific Information -> 1: [192 168 0 1], 2: []", opt.String())
+
+	// not in the encapsulated format
+	for _, raw := range [][]byte{
+		{1, 4, 192, 168},
+		{1},
+		{255, 1},
+	} {
+		opt, err = ParseOptVendorSpecificInformation(append([]byte{43, byte(len(raw))}, raw...))
+		require.NoError(t, err)
+		require.Nil(t, opt.Options)
+		require.Equal(t, raw, opt.Data)
+		_, err = opt.Decode(nil)
+		require.Error(t, err)
+	}
+
+	_, err = ParseOptVendorSpecificInformation([]byte{43, 3, 1})
+	require.Error(t, err, "short byte stream")
+	_, err = ParseOptVendorSpecificInformation([]byte{44, 0})
+	require.Error(t, err, "wrong code")
+}
+
+func TestOptVendorSpecificInformationAddSubOption(t *testing.T) {
+	opt := &OptVendorSpecificInformation{Data: []byte{1}}
+	opt.AddSubOption(1, []byte{2})
+	require.Nil(t, opt.Data)
+	require.Equal(t, []byte{43, 3, 1, 1, 2}, opt.ToBytes())
+}
+
+// testVendorSubOption is the typed sub-option of a test vendor.
+type testVendorSubOption struct {
+	OptionGeneric
+}
+
+func TestVendorOptions(t *testing.T) {
+	RegisterVendorOptionParser("test-vendor", func(data []byte) (Option, error) {
+		g, err := ParseOptionGeneric(data)
+		if err != nil {
+			return nil, err
+		}
+		if g.OptionCode == 2 {
+			return nil, fmt.Errorf("bad sub-option")
+		}
+		return &testVendorSubOption{*g}, nil
+	})
+	defer func() {
+		vendorOptionParsersMutex.Lock()
+		delete(vendorOptionParsers, "test-vendor")
+		vendorOptionParsersMutex.Unlock()
+	}()
+	require.NotNil(t, LookupVendorOptionParser("test-vendor:1.0"))
+	require.Nil(t, LookupVendorOptionParser("other"))
+
+	d, err := New()
+	require.NoError(t, err)
+	opts, err := d.VendorOptions("")
+	require.NoError(t, err)
+	require.Nil(t, opts)
+
+	vsi := &OptVendorSpecificInformation{}
+	vsi.AddSubOption(1, net.IPv4(10, 0, 0, 1).To4())
+	vsi.AddSubOption(uint8(OptionEnd), nil)
+	d.AddOption(vsi)
+
+	// without the class identifier, the sub-options are generic
+	opts, err = d.VendorOptions("")
+	require.NoError(t, err)
+	require.Equal(t, []Option{&OptionGeneric{OptionCode: 1, Data: []byte{10, 0, 0, 1}}}, opts)
+
+	opts, err = d.VendorOptions("test-vendor:1.0")
+	require.NoError(t, err)
+	require.Equal(t, []Option{&testVendorSubOption{OptionGeneric{OptionCode: 1, Data: []byte{10, 0, 0, 1}}}}, opts)
+
+	// the class identifier of the packet is used by default
+	d.AddOption(&OptClassIdentifier{Identifier: "test-vendor:1.0"})
+	opts, err = d.VendorOptions("")
+	require.NoError(t, err)
+	require.IsType(t, &testVendorSubOption{}, opts[0])
+
+	// the errors of the parser are returned
+	vsi.AddSubOption(2, nil)
+	_, err = d.VendorOptions("")
+	require.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+
+	"github.com/insomniacslk/dhcp/uio"
 )
 
 // This option implements the Vendor-Identifying Vendor Class Option
@@ -23,39 +25,31 @@ type OptVIVC struct {
 // ParseOptVIVC contructs an OptVIVC tsruct from a sequence of bytes and returns
 // it, or an error.
 func ParseOptVIVC(data []byte) (*OptVIVC, error) {
-	if len(data) < 2 {
-		return nil, ErrShortByteStream
-	}
-	code := OptionCode(data[0])
-	if code != OptionVendorIdentifyingVendorClass {
-		return nil, fmt.Errorf("expected code %v, got %v", OptionVendorIdentifyingVendorClass, code)
+	buf, err := newOptionLexer(data, OptionVendorIdentifyingVendorClass)
+	if err != nil {
+		return nil, err
 	}
-
-	length := int(data[1])
-	if len(data) < 2+length {
-		return nil, ErrShortByteStream
+	ids, err := parseVIVCIdentifiers(buf.ReadAll())
+	if err != nil {
+		return nil, err
 	}
-	data = data[2:length+2]
+	return &OptVIVC{Identifiers: ids}, nil
+}
 
+// parseVIVCIdentifiers parses the data of an OptVIVC.
+func parseVIVCIdentifiers(data []byte) ([]VIVCIdentifier, error) {
 	ids := []VIVCIdentifier{}
-	for len(data) > 5 {
-		entID := binary.BigEndian.Uint32(data[0:4])
-		idLen := int(data[4])
-		data = data[5:]
-
-		if idLen > len(data) {
+	buf := uio.NewBigEndianBuffer(data)
+	for buf.Has(1) {
+		entID := buf.Read32()
+		idLen := int(buf.Read8())
+		idData := buf.Consume(idLen)
+		if buf.Error() != nil {
 			return nil, ErrShortByteStream
 		}
-
-		ids = append(ids, VIVCIdentifier{EntID: entID, Data: data[:idLen]})
-		data = data[idLen:]
+		ids = append(ids, VIVCIdentifier{EntID: entID, Data: idData})
 	}
-
-	if len(data) != 0 {
-		return nil, ErrShortByteStream
-	}
-
-	return &OptVIVC{Identifiers: ids}, nil
+	return ids, nil
 }
 
 // Code returns the option code.
@@ -90,6 +84,16 @@ func (o *OptVIVC) String() string {
 	return buf.String()[:buf.Len()-1]
 }
 
+// MarshalJSON implements json.Marshaler.
+func (o *OptVIVC) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptVIVC) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
 // Length returns the length of the data portion (excluding option code and byte
 // for length, if any).
 func (o *OptVIVC) Length() int {
@@ -0,0 +1,228 @@
+package dhcpv4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/insomniacslk/dhcp/uio"
+)
+
+// This option implements the Vendor-Identifying Vendor-Specific Information
+// option
+// https://tools.ietf.org/html/rfc3925
+
+// VIVSVendor holds the data of one vendor in the Vendor-Identifying
+// Vendor-Specific Information option, identified by its enterprise number.
+// The data is usually made of sub-options, in the same format as the ones of
+// the Vendor Specific Information option.
+type VIVSVendor struct {
+	EntID iana.EnterpriseID
+	Data  []byte
+}
+
+// OptVIVS represents the Vendor-Identifying Vendor-Specific Information
+// option.
+type OptVIVS struct {
+	Vendors []VIVSVendor
+}
+
+// ParseOptVIVS constructs an OptVIVS struct from a sequence of bytes and
+// returns it, or an error.
+func ParseOptVIVS(data []byte) (*OptVIVS, error) {
+	buf, err := newOptionLexer(data, OptionVendorIdentifyingVendorSpecific)
+	if err != nil {
+		return nil, err
+	}
+	vendors, err := parseVIVSVendors(buf.ReadAll())
+	if err != nil {
+		return nil, err
+	}
+	return &OptVIVS{Vendors: vendors}, nil
+}
+
+// parseVIVSVendors parses the data of an OptVIVS.
+func parseVIVSVendors(data []byte) ([]VIVSVendor, error) {
+	vendors := []VIVSVendor{}
+	buf := uio.NewBigEndianBuffer(data)
+	for buf.Has(1) {
+		entID := iana.EnterpriseID(buf.Read32())
+		dataLen := int(buf.Read8())
+		vendorData := buf.Consume(dataLen)
+		if buf.Error() != nil {
+			return nil, ErrShortByteStream
+		}
+		vendors = append(vendors, VIVSVendor{EntID: entID, Data: vendorData})
+	}
+	return vendors, nil
+}
+
+// Code returns the option code.
+func (o *OptVIVS) Code() OptionCode {
+	return OptionVendorIdentifyingVendorSpecific
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptVIVS) ToBytes() []byte {
+	buf := []byte{byte(o.Code()), byte(o.Length())}
+	for _, v := range o.Vendors {
+		var entID [4]byte
+		binary.BigEndian.PutUint32(entID[:], uint32(v.EntID))
+		buf = append(buf, entID[:]...)
+		buf = append(buf, byte(len(v.Data)))
+		buf = append(buf, v.Data...)
+	}
+	return buf
+}
+
+// String returns a human-readable string for this option.
+func (o *OptVIVS) String() string {
+	buf := bytes.Buffer{}
+	fmt.Fprintf(&buf, "Vendor-Identifying Vendor-Specific ->")
+	for i, v := range o.Vendors {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, " %v: %v", v.EntID, v.Data)
+	}
+	return buf.String()
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptVIVS) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *OptVIVS) UnmarshalJSON(data []byte) error {
+	return unmarshalOptionJSON(data, o)
+}
+
+// Length returns the length of the data portion (excluding option code and byte
+// for length, if any).
+func (o *OptVIVS) Length() int {
+	n := 0
+	for _, v := range o.Vendors {
+		// each vendor has a header of entID (4 bytes) and length (1 byte)
+		n += 5 + len(v.Data)
+	}
+	return n
+}
+
+// GetVendorData returns the data of the given vendor, or nil if there is
+// none.
+func (o *OptVIVS) GetVendorData(entID iana.EnterpriseID) []byte {
+	for _, v := range o.Vendors {
+		if v.EntID == entID {
+			return v.Data
+		}
+	}
+	return nil
+}
+
+// VIVSSubOptions are the sub-options of a vendor in the Vendor-Identifying
+// Vendor-Specific Information option, by code.
+type VIVSSubOptions map[uint8][]byte
+
+// SubOptions returns the sub-options of the vendor data, or an error if the
+// data is not made of code/length/value sub-options as RFC 3925 section 4
+// specifies. The data of a sub-option appearing several times is
+// concatenated.
+func (v *VIVSVendor) SubOptions() (VIVSSubOptions, error) {
+	opts := make(VIVSSubOptions)
+	buf := uio.NewBigEndianBuffer(v.Data)
+	for buf.Has(1) {
+		code := buf.Read8()
+		data := buf.Consume(int(buf.Read8()))
+		if buf.Error() != nil {
+			return nil, fmt.Errorf("invalid sub-options of vendor %v", v.EntID)
+		}
+		opts[code] = append(append([]byte{}, opts[code]...), data...)
+	}
+	return opts, nil
+}
+
+// SetSubOptions replaces the vendor data with the given sub-options, in
+// increasing code order.
+func (v *VIVSVendor) SetSubOptions(opts VIVSSubOptions) {
+	codes := make([]int, 0, len(opts))
+	for code := range opts {
+		codes = append(codes, int(code))
+	}
+	sort.Ints(codes)
+	v.Data = []byte{}
+	for _, code := range codes {
+		v.Data = append(v.Data, byte(code), byte(len(opts[uint8(code)])))
+		v.Data = append(v.Data, opts[uint8(code)]...)
+	}
+}
+
+// SubOptions returns the sub-options of the given vendor, or nil if the vendor
+// has no data in the option.
+func (o *OptVIVS) SubOptions(entID iana.EnterpriseID) (VIVSSubOptions, error) {
+	for _, v := range o.Vendors {
+		if v.EntID == entID {
+			return v.SubOptions()
+		}
+	}
+	return nil, nil
+}
+
+// SetSubOption sets a sub-option of the given vendor, adding the vendor if it
+// is not in the option yet. It returns an error if the existing data of the
+// vendor is not made of sub-options.
+func (o *OptVIVS) SetSubOption(entID iana.EnterpriseID, code uint8, data []byte) error {
+	for i := range o.Vendors {
+		if o.Vendors[i].EntID == entID {
+			opts, err := o.Vendors[i].SubOptions()
+			if err != nil {
+				return err
+			}
+			opts[code] = data
+			o.Vendors[i].SetSubOptions(opts)
+			return nil
+		}
+	}
+	v := VIVSVendor{EntID: entID}
+	v.SetSubOptions(VIVSSubOptions{code: data})
+	o.Vendors = append(o.Vendors, v)
+	return nil
+}
+
+// Decode returns the sub-options of the given vendor, decoded with the parser
+// registered for its enterprise number, or as OptionGeneric if there is none.
+// It returns nil if the vendor has no data in the option.
+func (o *OptVIVS) Decode(entID iana.EnterpriseID) ([]Option, error) {
+	for _, v := range o.Vendors {
+		if v.EntID == entID {
+			return parseVendorSpecificInformation(v.Data).Decode(LookupEnterpriseOptionParser(entID))
+		}
+	}
+	return nil, nil
+}
+
+var (
+	enterpriseOptionParsersMutex sync.RWMutex
+	enterpriseOptionParsers      = make(map[iana.EnterpriseID]VendorOptionParser)
+)
+
+// RegisterEnterpriseOptionParser registers the parser of the sub-options that
+// the given vendor sends in the Vendor-Identifying Vendor-Specific
+// Information option. A later registration for the same vendor replaces the
+// earlier one.
+func RegisterEnterpriseOptionParser(entID iana.EnterpriseID, parser VendorOptionParser) {
+	enterpriseOptionParsersMutex.Lock()
+	defer enterpriseOptionParsersMutex.Unlock()
+	enterpriseOptionParsers[entID] = parser
+}
+
+// LookupEnterpriseOptionParser returns the parser registered for the given
+// vendor, or nil if there is none.
+func LookupEnterpriseOptionParser(entID iana.EnterpriseID) VendorOptionParser {
+	enterpriseOptionParsersMutex.RLock()
+	defer enterpriseOptionParsersMutex.RUnlock()
+	return enterpriseOptionParsers[entID]
+}
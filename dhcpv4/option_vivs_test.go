@@ -0,0 +1,108 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleVIVSOpt = OptVIVS{
+	Vendors: []VIVSVendor{
+		{EntID: iana.EnterpriseIDCableLabs, Data: []byte{
+			1, 2, 2, 3, // Option Request
+			2, 4, 10, 0, 0, 1, // TFTP Servers
+		}},
+		{EntID: 0xcafe, Data: []byte{1, 1, 42}},
+	},
+}
+
+var sampleVIVSOptRaw = []byte{
+	byte(OptionVendorIdentifyingVendorSpecific), 23,
+	0x0, 0x0, 0x11, 0x8b, 10,
+	1, 2, 2, 3,
+	2, 4, 10, 0, 0, 1,
+	0x0, 0x0, 0xca, 0xfe, 3,
+	1, 1, 42,
+}
+
+func TestOptVIVSInterfaceMethods(t *testing.T) {
+	require.Equal(t, OptionVendorIdentifyingVendorSpecific, sampleVIVSOpt.Code(), "Code")
+	require.Equal(t, 23, sampleVIVSOpt.Length(), "Length")
+	require.Equal(t, sampleVIVSOptRaw, sampleVIVSOpt.ToBytes(), "ToBytes")
+	require.Equal(t, "Vendor-Identifying Vendor-Specific -> CableLabs: [1 2 2 3 2 4 10 0 0 1], Unknown (51966): [1 1 42]", sampleVIVSOpt.String())
+}
+
+func TestParseOptVIVS(t *testing.T) {
+	o, err := ParseOptVIVS(sampleVIVSOptRaw)
+	require.NoError(t, err)
+	require.Equal(t, &sampleVIVSOpt, o)
+
+	// short byte stream
+	_, err = ParseOptVIVS([]byte{byte(OptionVendorIdentifyingVendorSpecific)})
+	require.Error(t, err)
+	// wrong code
+	_, err = ParseOptVIVS([]byte{43, 2, 1, 2})
+	require.Error(t, err)
+	// truncated vendor header
+	_, err = ParseOptVIVS([]byte{byte(OptionVendorIdentifyingVendorSpecific), 3, 0, 0, 1})
+	require.Error(t, err)
+	// vendor data longer than the option
+	_, err = ParseOptVIVS([]byte{byte(OptionVendorIdentifyingVendorSpecific), 6, 0, 0, 0, 1, 2, 1})
+	require.Error(t, err)
+}
+
+func TestOptVIVSDecode(t *testing.T) {
+	require.Equal(t, sampleVIVSOpt.Vendors[1].Data, sampleVIVSOpt.GetVendorData(0xcafe))
+	require.Nil(t, sampleVIVSOpt.GetVendorData(1))
+
+	opts, err := sampleVIVSOpt.Decode(iana.EnterpriseIDCableLabs)
+	require.NoError(t, err)
+	require.Equal(t, []Option{
+		&OptVendorSubOption{SubCode: 1, Name: "Option Request", Value: []uint8{2, 3}, Data: []byte{2, 3}},
+		&OptVendorSubOption{SubCode: 2, Name: "TFTP Servers", Value: []net.IP{{10, 0, 0, 1}}, Data: []byte{10, 0, 0, 1}},
+	}, opts)
+
+	// without a registered parser, the sub-options are generic
+	opts, err = sampleVIVSOpt.Decode(0xcafe)
+	require.NoError(t, err)
+	require.Equal(t, []Option{&OptionGeneric{OptionCode: 1, Data: []byte{42}}}, opts)
+
+	opts, err = sampleVIVSOpt.Decode(1)
+	require.NoError(t, err)
+	require.Nil(t, opts)
+}
+
+func TestOptVIVSSubOptions(t *testing.T) {
+	opts, err := sampleVIVSOpt.SubOptions(iana.EnterpriseIDCableLabs)
+	require.NoError(t, err)
+	require.Equal(t, VIVSSubOptions{1: {2, 3}, 2: {10, 0, 0, 1}}, opts)
+	opts, err = sampleVIVSOpt.SubOptions(1)
+	require.NoError(t, err)
+	require.Nil(t, opts)
+
+	// malformed vendor data
+	_, err = (&VIVSVendor{EntID: 1, Data: []byte{1, 3, 0}}).SubOptions()
+	require.Error(t, err)
+
+	// repeated sub-options are concatenated, empty ones are kept
+	opts, err = (&VIVSVendor{EntID: 1, Data: []byte{3, 1, 'a', 4, 0, 3, 1, 'b'}}).SubOptions()
+	require.NoError(t, err)
+	require.Equal(t, VIVSSubOptions{3: []byte("ab"), 4: {}}, opts)
+}
+
+func TestOptVIVSSetSubOption(t *testing.T) {
+	var o OptVIVS
+	require.NoError(t, o.SetSubOption(iana.EnterpriseIDCableLabs, 2, []byte{10, 0, 0, 1}))
+	require.NoError(t, o.SetSubOption(iana.EnterpriseIDCableLabs, 1, []byte{2, 3}))
+	require.NoError(t, o.SetSubOption(0xcafe, 1, []byte{42}))
+	// sub-options are serialized in increasing code order
+	require.Equal(t, sampleVIVSOptRaw, o.ToBytes())
+
+	require.NoError(t, o.SetSubOption(0xcafe, 1, []byte{43}))
+	require.Equal(t, []byte{1, 1, 43}, o.GetVendorData(0xcafe))
+
+	o.Vendors = append(o.Vendors, VIVSVendor{EntID: 1, Data: []byte{1}})
+	require.Error(t, o.SetSubOption(1, 1, nil))
+}
@@ -4,15 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-)
-
-// ErrShortByteStream is an error that is thrown any time a short byte stream is
-// detected during option parsing.
-var ErrShortByteStream = errors.New("short byte stream")
+	"net"
 
-// ErrZeroLengthByteStream is an error that is thrown any time a zero-length
-// byte stream is encountered.
-var ErrZeroLengthByteStream = errors.New("zero-length byte stream")
+	"github.com/insomniacslk/dhcp/rfc1035label"
+	"github.com/insomniacslk/dhcp/uio"
+)
 
 // MagicCookie is the magic 4-byte value at the beginning of the list of options
 // in a DHCPv4 packet.
@@ -21,7 +17,9 @@ var MagicCookie = []byte{99, 130, 83, 99}
 // OptionCode is a single byte representing the code for a given Option.
 type OptionCode byte
 
-// Option is an interface that all DHCP v4 options adhere to.
+// Option is an interface that all DHCP v4 options adhere to. Serialization must
+// be lossless: parsing the output of ToBytes with ParseOption must return an
+// option equal to the original one.
 type Option interface {
 	Code() OptionCode
 	ToBytes() []byte
@@ -30,11 +28,35 @@ type Option interface {
 }
 
 // ParseOption parses a sequence of bytes as a single DHCPv4 option, returning
-// the specific option structure or error, if any.
+// the specific option structure or error, if any. The options whose format is
+// unknown to the library are parsed by the parser registered with
+// RegisterOptionParser, if any, or returned as OptionGeneric.
 func ParseOption(data []byte) (Option, error) {
 	if len(data) == 0 {
 		return nil, errors.New("invalid zero-length DHCPv4 option")
 	}
+	var (
+		opt Option
+		err error
+	)
+	if parser := LookupOptionParser(OptionCode(data[0])); parser != nil {
+		opt, err = parser(data)
+	} else if opt, err = parseLibraryOption(data); err == errUnknownOption {
+		opt, err = ParseOptionGeneric(data)
+	}
+	if err != nil {
+		return nil, &OptionParseError{Code: OptionCode(data[0]), Err: err}
+	}
+	return opt, nil
+}
+
+// errUnknownOption is returned by parseLibraryOption for the codes whose
+// format the library does not know.
+var errUnknownOption = errors.New("unknown option")
+
+// parseLibraryOption parses the options whose format the library knows, and
+// returns errUnknownOption for the others.
+func parseLibraryOption(data []byte) (Option, error) {
 	var (
 		opt Option
 		err error
@@ -42,6 +64,8 @@ func ParseOption(data []byte) (Option, error) {
 	switch OptionCode(data[0]) {
 	case OptionSubnetMask:
 		opt, err = ParseOptSubnetMask(data)
+	case OptionTimeOffset:
+		opt, err = ParseOptTimeOffset(data)
 	case OptionRouter:
 		opt, err = ParseOptRouter(data)
 	case OptionDomainNameServer:
@@ -50,6 +74,8 @@ func ParseOption(data []byte) (Option, error) {
 		opt, err = ParseOptHostName(data)
 	case OptionDomainName:
 		opt, err = ParseOptDomainName(data)
+	case OptionInterfaceMTU:
+		opt, err = ParseOptInterfaceMTU(data)
 	case OptionBroadcastAddress:
 		opt, err = ParseOptBroadcastAddress(data)
 	case OptionNTPServers:
@@ -68,6 +94,8 @@ func ParseOption(data []byte) (Option, error) {
 		opt, err = ParseOptMaximumDHCPMessageSize(data)
 	case OptionClassIdentifier:
 		opt, err = ParseOptClassIdentifier(data)
+	case OptionClientIdentifier:
+		opt, err = ParseOptClientIdentifier(data)
 	case OptionTFTPServerName:
 		opt, err = ParseOptTFTPServerName(data)
 	case OptionBootfileName:
@@ -76,14 +104,62 @@ func ParseOption(data []byte) (Option, error) {
 		opt, err = ParseOptUserClass(data)
 	case OptionClientSystemArchitectureType:
 		opt, err = ParseOptClientArchType(data)
+	case OptionClientNetworkInterfaceIdentifier:
+		opt, err = ParseOptClientNDI(data)
+	case OptionClientMachineIdentifier:
+		opt, err = ParseOptClientMachineIdentifier(data)
 	case OptionVendorIdentifyingVendorClass:
 		opt, err = ParseOptVIVC(data)
 	case OptionDNSDomainSearchList:
 		opt, err = ParseOptDomainSearch(data)
 	case OptionRootPath:
 		opt, err = ParseOptRootPath(data)
+	case OptionRelayAgentInformation:
+		opt, err = ParseOptRelayAgentInformation(data)
+	case OptionSubnetSelection:
+		opt, err = ParseOptSubnetSelection(data)
+	case OptionSIPServersDHCPOption:
+		opt, err = ParseOptSIPServers(data)
+	case OptionClientLastTransactionTime:
+		opt, err = ParseOptClientLastTransactionTime(data)
+	case OptionAssociatedIP:
+		opt, err = ParseOptAssociatedIP(data)
+	case OptionStatusCode:
+		opt, err = ParseOptStatusCode(data)
+	case OptionBaseTime:
+		opt, err = ParseOptBaseTime(data)
+	case OptionStartTimeOfState:
+		opt, err = ParseOptStartTimeOfState(data)
+	case OptionQueryStartTime:
+		opt, err = ParseOptQueryStartTime(data)
+	case OptionQueryEndTime:
+		opt, err = ParseOptQueryEndTime(data)
+	case OptionDHCPState:
+		opt, err = ParseOptDHCPState(data)
+	case OptionDataSource:
+		opt, err = ParseOptDataSource(data)
+	case OptionOptionOverload:
+		opt, err = ParseOptOptionOverload(data)
+	case OptionAuthentication:
+		opt, err = ParseOptAuthentication(data)
+	case OptionRapidCommit:
+		opt, err = ParseOptRapidCommit(data)
+	case OptionVendorSpecificInformation:
+		opt, err = ParseOptVendorSpecificInformation(data)
+	case OptionVendorIdentifyingVendorSpecific:
+		opt, err = ParseOptVIVS(data)
+	case OptionClasslessStaticRouteOption:
+		opt, err = ParseOptClasslessStaticRoute(data)
+	case OptionMicrosoftClasslessStaticRoute:
+		opt, err = ParseOptMicrosoftClasslessStaticRoute(data)
+	case OptionNetBIOSOverTCPIPNameServer:
+		opt, err = ParseOptNetBIOSNameServer(data)
+	case OptionNetBIOSOverTCPIPNodeType:
+		opt, err = ParseOptNetBIOSNodeType(data)
+	case OptionNetBIOSOverTCPIPScope:
+		opt, err = ParseOptNetBIOSScope(data)
 	default:
-		opt, err = ParseOptionGeneric(data)
+		return nil, errUnknownOption
 	}
 	if err != nil {
 		return nil, err
@@ -91,15 +167,19 @@ func ParseOption(data []byte) (Option, error) {
 	return opt, nil
 }
 
+// libraryParsesOption reports whether the library knows the format of the
+// options with the given code.
+func libraryParsesOption(code OptionCode) bool {
+	_, err := parseLibraryOption([]byte{byte(code), 0})
+	return err != errUnknownOption
+}
+
 // OptionsFromBytes parses a sequence of bytes until the end and builds a list
 // of options from it. The sequence must contain the Magic Cookie. Returns an
 // error if any invalid option or length is found.
 func OptionsFromBytes(data []byte) ([]Option, error) {
-	if len(data) < len(MagicCookie) {
-		return nil, errors.New("invalid options: shorter than 4 bytes")
-	}
-	if !bytes.Equal(data[:len(MagicCookie)], MagicCookie) {
-		return nil, fmt.Errorf("invalid magic cookie: %v", data[:len(MagicCookie)])
+	if err := checkMagicCookie(data); err != nil {
+		return nil, err
 	}
 	opts, err := OptionsFromBytesWithoutMagicCookie(data[len(MagicCookie):])
 	if err != nil {
@@ -111,33 +191,226 @@ func OptionsFromBytes(data []byte) ([]Option, error) {
 // OptionsFromBytesWithoutMagicCookie parses a sequence of bytes until the end
 // and builds a list of options from it. The sequence should not contain the
 // DHCP magic cookie. Returns an error if any invalid option or length is found.
+// The instances of an option that is split because it is longer than 255
+// bytes are concatenated into one, as described in RFC 3396.
 func OptionsFromBytesWithoutMagicCookie(data []byte) ([]Option, error) {
-	options := make([]Option, 0, 10)
+	raw, err := splitOptions(data)
+	if err != nil {
+		return nil, err
+	}
+	return decodeOptions(concatOptions(raw))
+}
+
+// checkMagicCookie returns an error if data does not start with the Magic
+// Cookie.
+func checkMagicCookie(data []byte) error {
+	if len(data) < len(MagicCookie) || !bytes.Equal(data[:len(MagicCookie)], MagicCookie) {
+		return ErrInvalidMagicCookie
+	}
+	return nil
+}
+
+// maxOptionLength is the maximum length of the data of an option instance.
+// Longer options are split into several instances of the same option.
+const maxOptionLength = 255
+
+// rawOption is an option as found on the wire, before decoding.
+type rawOption struct {
+	code OptionCode
+	data []byte
+	// wire is the serialized option, if it is made of a single instance.
+	wire []byte
+}
+
+// splitOptions splits a sequence of bytes into options, until the End option
+// included. On error, the options found before the invalid one are returned.
+func splitOptions(data []byte) ([]rawOption, error) {
+	options := make([]rawOption, 0, 10)
 	idx := 0
-	for {
-		if idx == len(data) {
-			break
+	for idx < len(data) {
+		code := OptionCode(data[idx])
+		// Pad and End have no length byte, every other option has one, even
+		// if its data section is empty
+		if code == OptionPad || code == OptionEnd {
+			options = append(options, rawOption{code: code, wire: data[idx : idx+1]})
+			idx++
+			if code == OptionEnd {
+				break
+			}
+			continue
 		}
-		// This should never happen.
-		if idx > len(data) {
-			return nil, errors.New("read past the end of options")
+		if idx+2 > len(data) {
+			return options, &OptionParseError{Code: code, Err: ErrShortByteStream}
 		}
-		opt, err := ParseOption(data[idx:])
-		idx++
+		length := int(data[idx+1])
+		if idx+2+length > len(data) {
+			return options, &OptionParseError{Code: code, Err: fmt.Errorf("invalid data length: declared %v, actual %v",
+				length, len(data)-idx-2)}
+		}
+		options = append(options, rawOption{
+			code: code,
+			data: data[idx+2 : idx+2+length],
+			wire: data[idx : idx+2+length],
+		})
+		idx += 2 + length
+	}
+	return options, nil
+}
+
+// concatOptions concatenates the data of the instances of the same option, in
+// the order in which they appear, as described in RFC 3396. The resulting
+// option takes the place of the first instance.
+func concatOptions(raw []rawOption) []rawOption {
+	first := make(map[OptionCode]int, len(raw))
+	options := make([]rawOption, 0, len(raw))
+	for _, opt := range raw {
+		if opt.code == OptionPad || opt.code == OptionEnd {
+			options = append(options, opt)
+			continue
+		}
+		i, ok := first[opt.code]
+		if !ok {
+			first[opt.code] = len(options)
+			options = append(options, opt)
+			continue
+		}
+		data := make([]byte, 0, len(options[i].data)+len(opt.data))
+		data = append(data, options[i].data...)
+		options[i] = rawOption{code: opt.code, data: append(data, opt.data...)}
+	}
+	return options
+}
+
+// decodeOptions builds the options from their raw representation.
+func decodeOptions(raw []rawOption) ([]Option, error) {
+	options := make([]Option, 0, len(raw))
+	for _, r := range raw {
+		opt, err := decodeOption(r)
 		if err != nil {
 			return nil, err
 		}
 		options = append(options, opt)
-		if opt.Code() == OptionEnd {
-			break
+	}
+	return options, nil
+}
+
+// decodeOption builds an option from its raw representation.
+func decodeOption(r rawOption) (Option, error) {
+	switch {
+	case r.wire != nil:
+		return ParseOption(r.wire)
+	case len(r.data) <= maxOptionLength:
+		return ParseOption(append([]byte{byte(r.code), byte(len(r.data))}, r.data...))
+	}
+	opt, err := parseLongOption(r.code, r.data)
+	if err != nil {
+		return nil, &OptionParseError{Code: r.code, Err: err}
+	}
+	return opt, nil
+}
+
+// parseLongOption builds an option longer than maxOptionLength. Only the
+// options whose format allows it get their specific structure, the others are
+// returned as OptionGeneric.
+func parseLongOption(code OptionCode, data []byte) (Option, error) {
+	switch code {
+	case OptionDNSDomainSearchList:
+		domainSearch, err := rfc1035label.LabelsFromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return &OptDomainSearch{DomainSearch: domainSearch}, nil
+	case OptionSIPServersDHCPOption:
+		return parseSIPServers(data)
+	case OptionVendorIdentifyingVendorClass:
+		ids, err := parseVIVCIdentifiers(data)
+		if err != nil {
+			return nil, err
 		}
+		return &OptVIVC{Identifiers: ids}, nil
+	case OptionVendorIdentifyingVendorSpecific:
+		vendors, err := parseVIVSVendors(data)
+		if err != nil {
+			return nil, err
+		}
+		return &OptVIVS{Vendors: vendors}, nil
+	case OptionClientIdentifier:
+		return parseClientIdentifier(data)
+	case OptionVendorSpecificInformation:
+		return parseVendorSpecificInformation(data), nil
+	case OptionClasslessStaticRouteOption, OptionMicrosoftClasslessStaticRoute:
+		routes, err := parseClasslessStaticRoutes(data)
+		if err != nil {
+			return nil, err
+		}
+		return &OptClasslessStaticRoute{Routes: routes, Microsoft: code == OptionMicrosoftClasslessStaticRoute}, nil
+	}
+	return &OptionGeneric{OptionCode: code, Data: data}, nil
+}
 
-		// Options with zero length have no length byte, so here we handle the
-		// ones with nonzero length
-		if opt.Length() > 0 {
-			idx++
+// optionSize returns the size of the serialized option, including the
+// additional instances needed if it is longer than maxOptionLength.
+func optionSize(opt Option) int {
+	if opt.Code() == OptionPad || opt.Code() == OptionEnd {
+		return 1
+	}
+	length := opt.Length()
+	if length <= maxOptionLength {
+		return 2 + length
+	}
+	instances := (length + maxOptionLength - 1) / maxOptionLength
+	return 2*instances + length
+}
+
+// appendOption appends the serialized option to buf. Options longer than
+// maxOptionLength are split into several instances, as described in RFC 3396.
+func appendOption(buf []byte, opt Option) []byte {
+	data := opt.ToBytes()
+	if opt.Code() == OptionPad || opt.Code() == OptionEnd || opt.Length() <= maxOptionLength {
+		return append(buf, data...)
+	}
+	for data = data[2:]; len(data) > 0; {
+		n := len(data)
+		if n > maxOptionLength {
+			n = maxOptionLength
 		}
-		idx += opt.Length()
+		buf = append(buf, byte(opt.Code()), byte(n))
+		buf = append(buf, data[:n]...)
+		data = data[n:]
 	}
-	return options, nil
+	return buf
+}
+
+// newOptionLexer checks that data starts with the code and the length of an
+// option with the given code, followed by as many bytes of data, and returns a
+// Lexer over these bytes. The option parsers read their data through it, so
+// that a malformed option cannot make them read out of bounds.
+func newOptionLexer(data []byte, code OptionCode) (*uio.Lexer, error) {
+	buf := uio.NewBigEndianBuffer(data)
+	c := OptionCode(buf.Read8())
+	length := int(buf.Read8())
+	if buf.Error() != nil {
+		return nil, ErrShortByteStream
+	}
+	if c != code {
+		return nil, fmt.Errorf("expected code %v, got %v", code, c)
+	}
+	optData := buf.Consume(length)
+	if buf.Error() != nil {
+		return nil, ErrShortByteStream
+	}
+	return uio.NewBigEndianBuffer(optData), nil
+}
+
+// readIPv4List reads the IPv4 addresses that make the rest of buf, of which
+// there must be at least one.
+func readIPv4List(buf *uio.Lexer) ([]net.IP, error) {
+	if buf.Len() == 0 || buf.Len()%net.IPv4len != 0 {
+		return nil, fmt.Errorf("Invalid length: expected multiple of 4 larger than 4, got %v", buf.Len())
+	}
+	ips := make([]net.IP, 0, buf.Len()/net.IPv4len)
+	for buf.Has(net.IPv4len) {
+		ips = append(ips, buf.ReadIPv4())
+	}
+	return ips, nil
 }
@@ -1,7 +1,17 @@
 package dhcpv4
 
 import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/quick"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
 
 	"github.com/stretchr/testify/require"
 )
@@ -199,3 +209,424 @@ func TestOptionsFromBytesShortOption(t *testing.T) {
 	_, err := OptionsFromBytes(options)
 	require.Error(t, err)
 }
+
+// randomBytes returns between min and max random bytes.
+func randomBytes(r *rand.Rand, min, max int) []byte {
+	b := make([]byte, min+r.Intn(max-min+1))
+	r.Read(b)
+	return b
+}
+
+// randomString returns between min and max random printable characters.
+func randomString(r *rand.Rand, min, max int) string {
+	b := make([]byte, min+r.Intn(max-min+1))
+	for i := range b {
+		b[i] = byte('!' + r.Intn('~'-'!'+1))
+	}
+	return string(b)
+}
+
+// randomLabel returns a random domain name made of one to four labels.
+func randomLabel(r *rand.Rand) string {
+	labels := make([]string, 1+r.Intn(4))
+	for i := range labels {
+		b := make([]byte, 1+r.Intn(10))
+		for j := range b {
+			b[j] = byte('a' + r.Intn(26))
+		}
+		labels[i] = string(b)
+	}
+	return strings.Join(labels, ".")
+}
+
+// randomIPs returns between 1 and max random IPv4 addresses, in the 16-bytes
+// representation used by the parsers of IP lists.
+func randomIPs(r *rand.Rand, max int) []net.IP {
+	ips := make([]net.IP, 1+r.Intn(max))
+	for i := range ips {
+		ips[i] = net.IPv4(byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
+	}
+	return ips
+}
+
+// randomRoutes returns between 1 and 10 random classless static routes.
+func randomRoutes(r *rand.Rand) []Route {
+	routes := make([]Route, 1+r.Intn(10))
+	for i := range routes {
+		width := r.Intn(33)
+		mask := net.CIDRMask(width, 32)
+		routes[i] = Route{
+			Dest:   &net.IPNet{IP: net.IP(randomBytes(r, 4, 4)).Mask(mask), Mask: mask},
+			Router: net.IP(randomBytes(r, 4, 4)),
+		}
+	}
+	return routes
+}
+
+// optionGenerators build random, valid instances of every typed option, in the
+// same representation that the corresponding parser produces.
+var optionGenerators = map[string]func(r *rand.Rand) Option{
+	"SubnetMask": func(r *rand.Rand) Option {
+		return &OptSubnetMask{SubnetMask: net.IPMask(randomBytes(r, 4, 4))}
+	},
+	"Router": func(r *rand.Rand) Option {
+		return &OptRouter{Routers: randomIPs(r, 10)}
+	},
+	"DomainNameServer": func(r *rand.Rand) Option {
+		return &OptDomainNameServer{NameServers: randomIPs(r, 10)}
+	},
+	"HostName": func(r *rand.Rand) Option {
+		return &OptHostName{HostName: randomString(r, 1, 64)}
+	},
+	"DomainName": func(r *rand.Rand) Option {
+		return &OptDomainName{DomainName: randomLabel(r)}
+	},
+	"BroadcastAddress": func(r *rand.Rand) Option {
+		return &OptBroadcastAddress{BroadcastAddress: net.IP(randomBytes(r, 4, 4))}
+	},
+	"InterfaceMTU": func(r *rand.Rand) Option {
+		return &OptInterfaceMTU{MTU: uint16(MinInterfaceMTU + r.Intn(1<<16-MinInterfaceMTU))}
+	},
+	"SubnetSelection": func(r *rand.Rand) Option {
+		return &OptSubnetSelection{Subnet: net.IP(randomBytes(r, 4, 4))}
+	},
+	"SIPServers": func(r *rand.Rand) Option {
+		// a single generator for both encodings, since each code appears
+		// once in the generated packets
+		if r.Intn(2) == 0 {
+			return &OptSIPServers{Addresses: randomIPs(r, 8)}
+		}
+		domains := make([]string, 1+r.Intn(3))
+		for i := range domains {
+			domains[i] = randomLabel(r)
+		}
+		return &OptSIPServers{Domains: domains}
+	},
+	"TimeOffset": func(r *rand.Rand) Option {
+		return &OptTimeOffset{Offset: TimeOffset(r.Uint32())}
+	},
+	"NTPServers": func(r *rand.Rand) Option {
+		return &OptNTPServers{NTPServers: randomIPs(r, 10)}
+	},
+	"RequestedIPAddress": func(r *rand.Rand) Option {
+		return &OptRequestedIPAddress{RequestedAddr: net.IP(randomBytes(r, 4, 4))}
+	},
+	"IPAddressLeaseTime": func(r *rand.Rand) Option {
+		return &OptIPAddressLeaseTime{LeaseTime: r.Uint32()}
+	},
+	"MessageType": func(r *rand.Rand) Option {
+		return &OptMessageType{MessageType: MessageType(r.Intn(256))}
+	},
+	"ServerIdentifier": func(r *rand.Rand) Option {
+		return &OptServerIdentifier{ServerID: net.IP(randomBytes(r, 4, 4))}
+	},
+	"ParameterRequestList": func(r *rand.Rand) Option {
+		var opts []OptionCode
+		for _, b := range randomBytes(r, 1, 32) {
+			opts = append(opts, OptionCode(b))
+		}
+		return &OptParameterRequestList{RequestedOpts: opts}
+	},
+	"MaximumDHCPMessageSize": func(r *rand.Rand) Option {
+		return &OptMaximumDHCPMessageSize{Size: uint16(r.Intn(1 << 16))}
+	},
+	"ClassIdentifier": func(r *rand.Rand) Option {
+		return &OptClassIdentifier{Identifier: randomString(r, 1, 64)}
+	},
+	"TFTPServerName": func(r *rand.Rand) Option {
+		return &OptTFTPServerName{TFTPServerName: []byte(randomString(r, 1, 64))}
+	},
+	"BootfileName": func(r *rand.Rand) Option {
+		return &OptBootfileName{BootfileName: []byte(randomString(r, 1, 128))}
+	},
+	"UserClass": func(r *rand.Rand) Option {
+		classes := make([][]byte, 1+r.Intn(5))
+		for i := range classes {
+			classes[i] = randomBytes(r, 1, 20)
+		}
+		return &OptUserClass{UserClasses: classes, Rfc3004: true}
+	},
+	"ClientArchType": func(r *rand.Rand) Option {
+		archTypes := make([]iana.ArchType, 1+r.Intn(5))
+		for i := range archTypes {
+			archTypes[i] = iana.ArchType(r.Intn(1 << 16))
+		}
+		return &OptClientArchType{ArchTypes: archTypes}
+	},
+	"ClientNDI": func(r *rand.Rand) Option {
+		b := randomBytes(r, 3, 3)
+		return &OptClientNDI{Type: b[0], Major: b[1], Minor: b[2]}
+	},
+	"ClientMachineIdentifier": func(r *rand.Rand) Option {
+		return &OptClientMachineIdentifier{Type: uint8(r.Intn(256)), Identifier: randomBytes(r, 0, 20)}
+	},
+	"VIVC": func(r *rand.Rand) Option {
+		ids := make([]VIVCIdentifier, 1+r.Intn(5))
+		for i := range ids {
+			ids[i] = VIVCIdentifier{EntID: r.Uint32(), Data: randomBytes(r, 0, 20)}
+		}
+		return &OptVIVC{Identifiers: ids}
+	},
+	"DomainSearch": func(r *rand.Rand) Option {
+		domains := make([]string, 1+r.Intn(5))
+		for i := range domains {
+			domains[i] = randomLabel(r)
+			// share suffixes, so that the domains are compressed
+			if i > 0 && r.Intn(2) == 0 {
+				domains[i] += "." + domains[r.Intn(i)]
+			}
+		}
+		return &OptDomainSearch{DomainSearch: domains}
+	},
+	"RootPath": func(r *rand.Rand) Option {
+		return &OptRootPath{Path: randomString(r, 1, 64)}
+	},
+	"RelayAgentInformation": func(r *rand.Rand) Option {
+		o := &OptRelayAgentInformation{}
+		for i := 0; i < 1+r.Intn(4); i++ {
+			o.AddSubOption(RelayAgentSubOptionCode(r.Intn(256)), randomBytes(r, 0, 16))
+		}
+		return o
+	},
+	"ClientLastTransactionTime": func(r *rand.Rand) Option {
+		return &OptClientLastTransactionTime{Time: r.Uint32()}
+	},
+	"AssociatedIP": func(r *rand.Rand) Option {
+		return &OptAssociatedIP{IPs: randomIPs(r, 10)}
+	},
+	"StatusCode": func(r *rand.Rand) Option {
+		return &OptStatusCode{StatusCode: StatusCode(r.Intn(256)), StatusMessage: randomString(r, 0, 64)}
+	},
+	"BaseTime": func(r *rand.Rand) Option {
+		return &OptBaseTime{Time: r.Uint32()}
+	},
+	"StartTimeOfState": func(r *rand.Rand) Option {
+		return &OptStartTimeOfState{Time: r.Uint32()}
+	},
+	"QueryStartTime": func(r *rand.Rand) Option {
+		return &OptQueryStartTime{Time: r.Uint32()}
+	},
+	"QueryEndTime": func(r *rand.Rand) Option {
+		return &OptQueryEndTime{Time: r.Uint32()}
+	},
+	"DHCPState": func(r *rand.Rand) Option {
+		return &OptDHCPState{State: DHCPState(r.Intn(256))}
+	},
+	"DataSource": func(r *rand.Rand) Option {
+		return &OptDataSource{Flags: uint8(r.Intn(256))}
+	},
+	"OptionOverload": func(r *rand.Rand) Option {
+		return &OptOptionOverload{Overload: Overload(r.Intn(256))}
+	},
+	"ClientIdentifier": func(r *rand.Rand) Option {
+		if r.Intn(2) == 0 {
+			return &OptClientIdentifier{Type: uint8(r.Intn(255)), Identifier: randomBytes(r, 1, 64)}
+		}
+		return NewNodeSpecificClientIdentifier(r.Uint32(), dhcpv6.Duid{
+			Type:          dhcpv6.DUID_LL,
+			HwType:        iana.HwTypeEthernet,
+			LinkLayerAddr: randomBytes(r, 6, 6),
+		})
+	},
+	"Authentication": func(r *rand.Rand) Option {
+		return &OptAuthentication{
+			Protocol:                  AuthenticationProtocol(r.Intn(256)),
+			Algorithm:                 AuthenticationAlgorithm(r.Intn(256)),
+			RDM:                       ReplayDetectionMethod(r.Intn(256)),
+			ReplayDetection:           r.Uint64(),
+			AuthenticationInformation: randomBytes(r, 0, 64),
+		}
+	},
+	"RapidCommit": func(r *rand.Rand) Option {
+		return &OptRapidCommit{}
+	},
+	"VIVS": func(r *rand.Rand) Option {
+		vendors := make([]VIVSVendor, 1+r.Intn(5))
+		for i := range vendors {
+			vendors[i] = VIVSVendor{EntID: iana.EnterpriseID(r.Uint32()), Data: randomBytes(r, 0, 20)}
+		}
+		return &OptVIVS{Vendors: vendors}
+	},
+	"VendorSpecificInformation": func(r *rand.Rand) Option {
+		opt := &OptVendorSpecificInformation{}
+		for i := 1 + r.Intn(5); i > 0; i-- {
+			opt.AddSubOption(uint8(1+r.Intn(254)), randomBytes(r, 0, 40))
+		}
+		if r.Intn(2) == 0 {
+			opt.AddSubOption(uint8(OptionEnd), nil)
+		}
+		return opt
+	},
+	"NetBIOSNameServer": func(r *rand.Rand) Option {
+		return &OptNetBIOSNameServer{NameServers: randomIPs(r, 10)}
+	},
+	"NetBIOSNodeType": func(r *rand.Rand) Option {
+		return &OptNetBIOSNodeType{NodeType: NetBIOSNodeType(r.Intn(256))}
+	},
+	"NetBIOSScope": func(r *rand.Rand) Option {
+		return &OptNetBIOSScope{Scope: randomString(r, 1, 64)}
+	},
+	"ClasslessStaticRoute": func(r *rand.Rand) Option {
+		return &OptClasslessStaticRoute{Routes: randomRoutes(r)}
+	},
+	"MicrosoftClasslessStaticRoute": func(r *rand.Rand) Option {
+		return &OptClasslessStaticRoute{Routes: randomRoutes(r), Microsoft: true}
+	},
+	"Generic": func(r *rand.Rand) Option {
+		// skip the codes that have a typed implementation
+		for {
+			code := OptionCode(1 + r.Intn(254))
+			if _, ok := optionGeneratorCodes[code]; !ok {
+				return &OptionGeneric{OptionCode: code, Data: randomBytes(r, 0, 64)}
+			}
+		}
+	},
+}
+
+// optionGeneratorCodes is the set of option codes with a typed implementation.
+var optionGeneratorCodes = map[OptionCode]struct{}{}
+
+func init() {
+	r := rand.New(rand.NewSource(0))
+	for name, gen := range optionGenerators {
+		if name != "Generic" {
+			optionGeneratorCodes[gen(r).Code()] = struct{}{}
+		}
+	}
+}
+
+func TestOptionsRoundTrip(t *testing.T) {
+	for name, gen := range optionGenerators {
+		gen := gen
+		t.Run(name, func(t *testing.T) {
+			f := func(seed int64) bool {
+				opt := gen(rand.New(rand.NewSource(seed)))
+				data := opt.ToBytes()
+				parsed, err := ParseOption(data)
+				if err != nil {
+					t.Logf("cannot parse %v: %v", data, err)
+					return false
+				}
+				if !reflect.DeepEqual(opt, parsed) {
+					t.Logf("%#v != %#v", opt, parsed)
+					return false
+				}
+				return reflect.DeepEqual(data, parsed.ToBytes())
+			}
+			require.NoError(t, quick.Check(f, nil))
+		})
+	}
+}
+
+func TestOptionsTruncated(t *testing.T) {
+	for name, gen := range optionGenerators {
+		gen := gen
+		t.Run(name, func(t *testing.T) {
+			f := func(seed int64) bool {
+				data := gen(rand.New(rand.NewSource(seed))).ToBytes()
+				for i := 0; i < len(data); i++ {
+					// a length byte that promises more data than there is
+					short := append([]byte{}, data[:i]...)
+					if len(short) > 1 {
+						short[1] = byte(len(data) - 2)
+					}
+					if _, err := ParseOption(short); err == nil {
+						t.Logf("parsed truncated option %v", short)
+						return false
+					}
+					// a length byte that matches the truncated data
+					if len(short) > 1 {
+						short[1] = byte(len(short) - 2)
+						_, _ = ParseOption(short)
+					}
+				}
+				return true
+			}
+			require.NoError(t, quick.Check(f, nil))
+		})
+	}
+}
+
+func TestOptionsFromBytesRoundTrip(t *testing.T) {
+	f := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		data := append([]byte{}, MagicCookie...)
+		var opts []Option
+		// each option at most once, since the instances of an option are
+		// concatenated
+		for _, gen := range optionGenerators {
+			if r.Intn(2) == 0 {
+				opt := gen(r)
+				opts = append(opts, opt)
+				data = append(data, opt.ToBytes()...)
+			}
+		}
+		parsed, err := OptionsFromBytes(data)
+		if err != nil {
+			t.Logf("cannot parse %v: %v", data, err)
+			return false
+		}
+		for i := range opts {
+			if i >= len(parsed) || !reflect.DeepEqual(opts[i], parsed[i]) {
+				t.Logf("option #%d: %#v != %#v", i, opts[i], parsed)
+				return false
+			}
+		}
+		return len(opts) == len(parsed)
+	}
+	require.NoError(t, quick.Check(f, nil))
+}
+
+func TestOptionsFromBytesConcatenation(t *testing.T) {
+	options := []byte{
+		99, 130, 83, 99, // Magic Cookie
+		43, 2, 1, 2, // first instance of Vendor Specific Information
+		1, 4, 255, 255, 255, 0, // Subnet Mask
+		43, 1, 3, // second instance
+		255, // End
+	}
+	opts, err := OptionsFromBytes(options)
+	require.NoError(t, err)
+	require.Equal(t, []Option{
+		&OptVendorSpecificInformation{Data: []byte{1, 2, 3}},
+		&OptSubnetMask{SubnetMask: net.IPMask{255, 255, 255, 0}},
+		&OptionGeneric{OptionCode: OptionEnd},
+	}, opts)
+}
+
+func TestLongOptionsRoundTrip(t *testing.T) {
+	var domains []string
+	for i := 0; i < 30; i++ {
+		domains = append(domains, fmt.Sprintf("domain%d.example.com", i))
+	}
+	ids := []VIVCIdentifier{
+		{EntID: 1, Data: bytes.Repeat([]byte{1}, 200)},
+		{EntID: 2, Data: bytes.Repeat([]byte{2}, 200)},
+	}
+	long := []Option{
+		&OptDomainSearch{DomainSearch: domains},
+		&OptVIVC{Identifiers: ids},
+		&OptVIVS{Vendors: []VIVSVendor{
+			{EntID: 1, Data: bytes.Repeat([]byte{1}, 200)},
+			{EntID: 2, Data: bytes.Repeat([]byte{2}, 200)},
+		}},
+		&OptionGeneric{OptionCode: OptionCode(224), Data: bytes.Repeat([]byte{3}, 600)},
+		// not in the encapsulated format, since 601 is not a multiple of 5
+		&OptVendorSpecificInformation{Data: bytes.Repeat([]byte{3}, 601)},
+	}
+	for _, opt := range long {
+		d, err := New()
+		require.NoError(t, err)
+		d.AddOption(opt)
+		data := d.ToBytes()
+		require.Equal(t, HeaderSize+4+optionSize(opt)+1, len(data))
+		// the first instance is as long as possible
+		require.Equal(t, byte(opt.Code()), data[HeaderSize+4])
+		require.Equal(t, byte(255), data[HeaderSize+5])
+
+		parsed, err := FromBytes(data)
+		require.NoError(t, err)
+		require.Equal(t, []Option{opt, &OptionGeneric{OptionCode: OptionEnd}}, parsed.Options())
+		require.Equal(t, data, parsed.ToBytes())
+	}
+}
@@ -0,0 +1,68 @@
+// +build linux
+
+package dhcpv4
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// ClientCapabilities are the capabilities that Client.Exchange needs to open
+// its sockets: CAP_NET_RAW for the raw broadcast socket and for binding to an
+// interface, and CAP_NET_BIND_SERVICE for listening on the DHCP client port.
+var ClientCapabilities = []uintptr{unix.CAP_NET_RAW, unix.CAP_NET_BIND_SERVICE}
+
+// DropPrivileges switches all the threads of the process to the given user
+// and group, dropping the supplementary groups and every capability but the
+// ones in keep. It must be called by a privileged process, typically after
+// opening the sockets that need privileges. It does not work in programs using
+// cgo.
+func DropPrivileges(uid, gid int, keep ...uintptr) error {
+	data, err := capData(keep)
+	if err != nil {
+		return err
+	}
+	// The keep capabilities flag is per thread too, and the threads
+	// without it would lose their permitted capabilities with the user,
+	// and fail to set them afterwards.
+	if _, _, errno := syscall.AllThreadsSyscall(unix.SYS_PRCTL, unix.PR_SET_KEEPCAPS, 1, 0); errno != 0 {
+		return fmt.Errorf("cannot keep capabilities: %v", errno)
+	}
+	if err := syscall.Setgroups(nil); err != nil {
+		return fmt.Errorf("cannot drop supplementary groups: %v", err)
+	}
+	if err := syscall.Setresgid(gid, gid, gid); err != nil {
+		return fmt.Errorf("cannot set group to %d: %v", gid, err)
+	}
+	if err := syscall.Setresuid(uid, uid, uid); err != nil {
+		return fmt.Errorf("cannot set user to %d: %v", uid, err)
+	}
+	// Unlike the IDs, capabilities are per thread, so they have to be set
+	// on all of them.
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	if _, _, errno := syscall.AllThreadsSyscall(unix.SYS_CAPSET, uintptr(unsafe.Pointer(&hdr)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return fmt.Errorf("cannot set capabilities: %v", errno)
+	}
+	if _, _, errno := syscall.AllThreadsSyscall(unix.SYS_PRCTL, unix.PR_SET_KEEPCAPS, 0, 0); errno != 0 {
+		return fmt.Errorf("cannot reset keep capabilities flag: %v", errno)
+	}
+	return nil
+}
+
+// capData returns the capability sets, in the layout of version 3 of capset,
+// where the given capabilities are effective and permitted, and nothing is
+// inheritable. It fails on the capabilities that the layout cannot hold.
+func capData(caps []uintptr) ([2]unix.CapUserData, error) {
+	var data [2]unix.CapUserData
+	for _, c := range caps {
+		if c >= 64 {
+			return data, fmt.Errorf("invalid capability %d", c)
+		}
+		data[c/32].Effective |= 1 << (c % 32)
+		data[c/32].Permitted |= 1 << (c % 32)
+	}
+	return data, nil
+}
@@ -0,0 +1,80 @@
+// +build linux
+
+package dhcpv4
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestCapData(t *testing.T) {
+	data, err := capData(ClientCapabilities)
+	require.NoError(t, err)
+	mask := uint32(1<<unix.CAP_NET_RAW | 1<<unix.CAP_NET_BIND_SERVICE)
+	require.Equal(t, mask, data[0].Effective)
+	require.Equal(t, mask, data[0].Permitted)
+	require.Equal(t, uint32(0), data[0].Inheritable)
+	require.Equal(t, unix.CapUserData{}, data[1])
+
+	data, err = capData([]uintptr{unix.CAP_SYSLOG})
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), data[0].Effective)
+	require.Equal(t, uint32(1<<(unix.CAP_SYSLOG-32)), data[1].Effective)
+}
+
+func TestCapDataEmpty(t *testing.T) {
+	data, err := capData(nil)
+	require.NoError(t, err)
+	require.Equal(t, [2]unix.CapUserData{}, data)
+}
+
+func TestCapDataInvalid(t *testing.T) {
+	_, err := capData([]uintptr{64})
+	require.Error(t, err)
+}
+
+// TestDropPrivileges drops the privileges of a child process running
+// dropPrivilegesChild, since they cannot be regained.
+func TestDropPrivileges(t *testing.T) {
+	if os.Getenv("DHCPV4_DROP_PRIVILEGES") == "1" {
+		dropPrivilegesChild()
+		return
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("requires root")
+	}
+	if _, _, errno := syscall.AllThreadsSyscall(unix.SYS_GETPID, 0, 0, 0); errno == unix.ENOTSUP {
+		t.Skip("not supported with cgo")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDropPrivileges$")
+	cmd.Env = append(os.Environ(), "DHCPV4_DROP_PRIVILEGES=1")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "%s", out)
+}
+
+// dropPrivilegesChild switches to nobody, keeping CAP_NET_BIND_SERVICE, and
+// exits with a non-zero status if it cannot bind a privileged port anymore.
+func dropPrivilegesChild() {
+	fail := func(err error) {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+	if err := DropPrivileges(65534, 65534, unix.CAP_NET_BIND_SERVICE); err != nil {
+		fail(err)
+	}
+	if os.Getuid() != 65534 {
+		os.Stderr.WriteString("user not changed\n")
+		os.Exit(1)
+	}
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1})
+	if err != nil {
+		fail(err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,127 @@
+package pxe
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// maxSubOptionLength is the maximum length of the data portion of an option,
+// and therefore of the whole option 43 and of each of its sub-options.
+const maxSubOptionLength = 255
+
+// BootMenu builds the PXE sub-options that present a boot menu to PXE
+// clients, as sent by PXE and proxyDHCP servers in their offers. Use AddItem
+// to append entries to the menu, then Option to build the option 43.
+type BootMenu struct {
+	// Control tells the client how to discover boot servers. When items are
+	// bound to specific boot servers, DiscoveryOnlyBootServers is usually
+	// what is wanted.
+	Control DiscoveryControl
+	// Prompt is shown to the user along with the menu. If empty, no menu
+	// prompt sub-option is sent and the client shows the menu right away.
+	Prompt string
+	// Timeout is the number of seconds the prompt is shown before the first
+	// item is booted. 255 means waiting forever for the user.
+	Timeout uint8
+
+	items   []BootMenuItem
+	servers []BootServer
+}
+
+// NewBootMenu returns an empty boot menu with the given prompt and timeout.
+func NewBootMenu(prompt string, timeout uint8) *BootMenu {
+	return &BootMenu{Prompt: prompt, Timeout: timeout}
+}
+
+// AddItem appends an item to the menu. Servers, if any, are the addresses of
+// the boot servers that are contacted when the item is chosen, and are added
+// to the Boot Servers sub-option under the item's boot server type. It
+// returns the menu itself, so that calls can be chained.
+func (m *BootMenu) AddItem(typ BootServerType, description string, servers ...net.IP) *BootMenu {
+	m.items = append(m.items, BootMenuItem{Type: typ, Description: description})
+	if len(servers) == 0 {
+		return m
+	}
+	for i := range m.servers {
+		if m.servers[i].Type == typ {
+			m.servers[i].Addresses = append(m.servers[i].Addresses, servers...)
+			return m
+		}
+	}
+	m.servers = append(m.servers, BootServer{Type: typ, Addresses: servers})
+	return m
+}
+
+// Items returns the items of the menu, in order.
+func (m *BootMenu) Items() []BootMenuItem {
+	return m.items
+}
+
+// Option builds the vendor specific information option holding the discovery
+// control, boot servers, boot menu and menu prompt sub-options, terminated by
+// the End sub-option. It returns an error if the menu is empty or does not fit
+// in a single option.
+func (m *BootMenu) Option() (*OptVendorSpecificInformation, error) {
+	if len(m.items) == 0 {
+		return nil, errors.New("boot menu has no items")
+	}
+	for _, item := range m.items {
+		if len(item.Description) > maxSubOptionLength-3 {
+			return nil, fmt.Errorf("boot menu item description too long: %q", item.Description)
+		}
+		if item.Type != BootServerTypeLocalBoot && m.Control&DiscoveryOnlyBootServers != 0 && !m.hasServers(item.Type) {
+			return nil, fmt.Errorf("no boot server for boot menu item %q", item.Description)
+		}
+	}
+	for _, server := range m.servers {
+		for _, addr := range server.Addresses {
+			if addr.To4() == nil {
+				return nil, fmt.Errorf("invalid boot server address %v", addr)
+			}
+		}
+	}
+
+	opt := &OptVendorSpecificInformation{}
+	opt.Options = append(opt.Options, &OptDiscoveryControl{Control: m.Control})
+	if len(m.servers) > 0 {
+		opt.Options = append(opt.Options, &OptBootServers{Servers: m.servers})
+	}
+	opt.Options = append(opt.Options, &OptBootMenu{Items: m.items})
+	if m.Prompt != "" {
+		opt.Options = append(opt.Options, &OptMenuPrompt{Timeout: m.Timeout, Prompt: m.Prompt})
+	}
+	for _, o := range opt.Options {
+		if o.Length() > maxSubOptionLength {
+			return nil, fmt.Errorf("%v too long: %d bytes", OptionCodeToString[o.Code()], o.Length())
+		}
+	}
+	if opt.Length() > maxSubOptionLength {
+		return nil, fmt.Errorf("boot menu too long: %d bytes", opt.Length())
+	}
+	return opt, nil
+}
+
+func (m *BootMenu) hasServers(typ BootServerType) bool {
+	for _, server := range m.servers {
+		if server.Type == typ && len(server.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WithBootMenu adds the given PXE vendor specific information option to the
+// packet, along with the PXEClient class identifier that PXE clients expect to
+// find in replies carrying PXE sub-options.
+func WithBootMenu(opt *OptVendorSpecificInformation) dhcpv4.Modifier {
+	return func(d *dhcpv4.DHCPv4) *dhcpv4.DHCPv4 {
+		if d.GetOneOption(dhcpv4.OptionClassIdentifier) == nil {
+			d.AddOption(&dhcpv4.OptClassIdentifier{Identifier: PXEClientVendorClassIdentifier})
+		}
+		d.AddOption(opt)
+		return d
+	}
+}
@@ -0,0 +1,86 @@
+package pxe
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootMenuOption(t *testing.T) {
+	m := NewBootMenu("Select OS", 10)
+	m.Control = DiscoveryDisableMulticast | DiscoveryOnlyBootServers
+	m.AddItem(BootServerTypeLocalBoot, "Local").
+		AddItem(32768, "Linux", net.IP{10, 0, 0, 1}).
+		AddItem(32768, "Linux rescue", net.IP{10, 0, 0, 2})
+	require.Equal(t, 3, len(m.Items()))
+
+	opt, err := m.Option()
+	require.NoError(t, err)
+	require.Equal(t, 4, len(opt.Options))
+	require.Equal(t, &OptDiscoveryControl{Control: DiscoveryDisableMulticast | DiscoveryOnlyBootServers}, opt.Options[0])
+	require.Equal(t, &OptBootServers{Servers: []BootServer{
+		BootServer{Type: 32768, Addresses: []net.IP{net.IP{10, 0, 0, 1}, net.IP{10, 0, 0, 2}}},
+	}}, opt.Options[1])
+	require.Equal(t, OptionBootMenu, opt.Options[2].Code())
+	require.Equal(t, &OptMenuPrompt{Timeout: 10, Prompt: "Select OS"}, opt.Options[3])
+
+	// the serialized option must end with the End sub-option and parse back
+	data := opt.ToBytes()
+	require.Equal(t, byte(dhcpv4.OptionEnd), data[len(data)-1])
+	parsed, err := ParseOptVendorSpecificInformation(data)
+	require.NoError(t, err)
+	require.Equal(t, opt, parsed)
+}
+
+func TestBootMenuOptionNoPrompt(t *testing.T) {
+	m := NewBootMenu("", 0)
+	m.AddItem(BootServerTypeLocalBoot, "Local")
+	opt, err := m.Option()
+	require.NoError(t, err)
+	require.Nil(t, opt.GetOneOption(OptionMenuPrompt))
+	require.Nil(t, opt.GetOneOption(OptionBootServers))
+}
+
+func TestBootMenuOptionErrors(t *testing.T) {
+	_, err := NewBootMenu("Boot", 5).Option()
+	require.Error(t, err, "no items")
+
+	m := NewBootMenu("Boot", 5)
+	m.Control = DiscoveryOnlyBootServers
+	m.AddItem(32768, "Linux")
+	_, err = m.Option()
+	require.Error(t, err, "no boot server for item")
+
+	m = NewBootMenu("Boot", 5)
+	m.AddItem(32768, "Linux", net.ParseIP("2001:db8::1"))
+	_, err = m.Option()
+	require.Error(t, err, "IPv6 boot server")
+
+	m = NewBootMenu("Boot", 5)
+	m.AddItem(32768, strings.Repeat("a", 253))
+	_, err = m.Option()
+	require.Error(t, err, "description too long")
+
+	m = NewBootMenu("Boot", 5)
+	for i := 0; i < 10; i++ {
+		m.AddItem(32768, strings.Repeat("a", 30))
+	}
+	_, err = m.Option()
+	require.Error(t, err, "menu too long")
+}
+
+func TestWithBootMenu(t *testing.T) {
+	opt, err := NewBootMenu("Boot", 5).AddItem(BootServerTypeLocalBoot, "Local").Option()
+	require.NoError(t, err)
+
+	d, err := dhcpv4.New()
+	require.NoError(t, err)
+	d = WithBootMenu(opt)(d)
+	require.Equal(t, opt, d.GetOneOption(dhcpv4.OptionVendorSpecificInformation))
+	ci := d.GetOneOption(dhcpv4.OptionClassIdentifier)
+	require.NotNil(t, ci)
+	require.Equal(t, PXEClientVendorClassIdentifier, ci.(*dhcpv4.OptClassIdentifier).Identifier)
+}
@@ -0,0 +1,88 @@
+package pxe
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// maxServerHostNameLength and maxBootFileNameLength are the sizes of the
+// sname and file fields of the DHCPv4 header.
+const (
+	maxServerHostNameLength = 64
+	maxBootFileNameLength   = 128
+)
+
+// RequestArch returns the architecture of the PXE client that sent request,
+// taken from the client system architecture option (93) if present, or from
+// the class identifier option (60) otherwise. It returns false if the request
+// does not come from a PXE client.
+func RequestArch(request *dhcpv4.DHCPv4) (iana.ArchType, bool) {
+	if opt, ok := request.GetOneOption(dhcpv4.OptionClientSystemArchitectureType).(*dhcpv4.OptClientArchType); ok && len(opt.ArchTypes) > 0 {
+		return opt.ArchTypes[0], true
+	}
+	if opt, ok := request.GetOneOption(dhcpv4.OptionClassIdentifier).(*dhcpv4.OptClassIdentifier); ok {
+		return ClientArch(opt.Identifier)
+	}
+	return 0, false
+}
+
+// WithBootFile sets everything a PXE client needs to download bootfile from
+// the TFTP server at tftpServer: the server address (siaddr), the server host
+// name (sname) and the boot file name (file) of the header, the TFTP server
+// name (66) and bootfile name (67) options, the PXEClient class identifier
+// (60), and a vendor specific information option (43) telling the client to
+// download the boot file right away rather than discover boot servers.
+//
+// Old PXE ROMs only look at the header fields, others only at the options,
+// hence both are set. The server host name is the address of the TFTP server,
+// which the clients do not have to resolve.
+func WithBootFile(tftpServer net.IP, bootfile string) dhcpv4.Modifier {
+	return func(d *dhcpv4.DHCPv4) *dhcpv4.DHCPv4 {
+		name := tftpServer.String()
+		d.SetServerIPAddr(tftpServer)
+		d.SetServerHostNameRaw([]byte(name))
+		d.SetBootFileNameRaw([]byte(bootfile))
+		d.UpdateOption(&dhcpv4.OptTFTPServerName{TFTPServerName: []byte(name)})
+		d.UpdateOption(&dhcpv4.OptBootfileName{BootfileName: []byte(bootfile)})
+		d.UpdateOption(&dhcpv4.OptClassIdentifier{Identifier: PXEClientVendorClassIdentifier})
+		d.UpdateOption(&OptVendorSpecificInformation{
+			Options: []dhcpv4.Option{
+				&OptDiscoveryControl{Control: DiscoveryBootFileDirect},
+			},
+		})
+		return d
+	}
+}
+
+// NewBootReply builds the reply to the request of a PXE client, making it
+// download bootfile from the TFTP server at tftpServer as described in
+// WithBootFile. The bootfile is meant for the given architecture: an error is
+// returned if the request does not come from a PXE client of that
+// architecture, or if the boot file name or the TFTP server do not fit in the
+// header. The modifiers are applied before the boot parameters, and usually
+// set the message type and the address offered to the client.
+func NewBootReply(request *dhcpv4.DHCPv4, arch iana.ArchType, tftpServer net.IP, bootfile string, modifiers ...dhcpv4.Modifier) (*dhcpv4.DHCPv4, error) {
+	clientArch, ok := RequestArch(request)
+	if !ok {
+		return nil, errors.New("not a request from a PXE client")
+	}
+	if clientArch != arch {
+		return nil, fmt.Errorf("boot file for %v, but the client is %v",
+			iana.ArchTypeToString(arch), iana.ArchTypeToString(clientArch))
+	}
+	if tftpServer.To4() == nil {
+		return nil, fmt.Errorf("invalid TFTP server address %v", tftpServer)
+	}
+	if len(tftpServer.String()) >= maxServerHostNameLength {
+		return nil, fmt.Errorf("TFTP server name too long: %v", tftpServer)
+	}
+	if bootfile == "" || len(bootfile) >= maxBootFileNameLength {
+		return nil, fmt.Errorf("invalid boot file name length: %d", len(bootfile))
+	}
+	modifiers = append(modifiers[:len(modifiers):len(modifiers)], WithBootFile(tftpServer.To4(), bootfile))
+	return dhcpv4.NewReplyFromRequest(request, modifiers...)
+}
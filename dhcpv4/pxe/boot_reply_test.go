@@ -0,0 +1,79 @@
+package pxe
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func newPXEDiscover(t *testing.T, class string, archs ...iana.ArchType) *dhcpv4.DHCPv4 {
+	d, err := dhcpv4.NewDiscovery(net.HardwareAddr{0, 1, 2, 3, 4, 5})
+	require.NoError(t, err)
+	if class != "" {
+		d.AddOption(&dhcpv4.OptClassIdentifier{Identifier: class})
+	}
+	if len(archs) > 0 {
+		d.AddOption(&dhcpv4.OptClientArchType{ArchTypes: archs})
+	}
+	return d
+}
+
+func TestRequestArch(t *testing.T) {
+	arch, ok := RequestArch(newPXEDiscover(t, "PXEClient:Arch:00007:UNDI:003016"))
+	require.True(t, ok)
+	require.Equal(t, iana.EFI_BC, arch)
+
+	// option 93 takes precedence
+	arch, ok = RequestArch(newPXEDiscover(t, "PXEClient:Arch:00007:UNDI:003016", iana.EFI_X86_64))
+	require.True(t, ok)
+	require.Equal(t, iana.EFI_X86_64, arch)
+
+	_, ok = RequestArch(newPXEDiscover(t, "MSFT 5.0"))
+	require.False(t, ok)
+	_, ok = RequestArch(newPXEDiscover(t, ""))
+	require.False(t, ok)
+}
+
+func TestNewBootReply(t *testing.T) {
+	discover := newPXEDiscover(t, "PXEClient:Arch:00000:UNDI:002001", iana.INTEL_X86PC)
+	tftp := net.IPv4(192, 0, 2, 69)
+	offer, err := NewBootReply(discover, iana.INTEL_X86PC, tftp, "pxelinux.0",
+		dhcpv4.WithMessageType(dhcpv4.MessageTypeOffer),
+		dhcpv4.WithYourIP(net.IPv4(192, 0, 2, 10)),
+	)
+	require.NoError(t, err)
+	require.Equal(t, dhcpv4.MessageTypeOffer, *offer.MessageType())
+	require.Equal(t, discover.TransactionID(), offer.TransactionID())
+	require.True(t, offer.ServerIPAddr().Equal(tftp))
+	require.Equal(t, "192.0.2.69", offer.ServerHostNameToString())
+	require.Equal(t, "pxelinux.0", offer.BootFileNameToString())
+	require.Equal(t, &dhcpv4.OptTFTPServerName{TFTPServerName: []byte("192.0.2.69")}, offer.GetOneOption(dhcpv4.OptionTFTPServerName))
+	require.Equal(t, &dhcpv4.OptBootfileName{BootfileName: []byte("pxelinux.0")}, offer.GetOneOption(dhcpv4.OptionBootfileName))
+	require.Equal(t, &dhcpv4.OptClassIdentifier{Identifier: "PXEClient"}, offer.GetOneOption(dhcpv4.OptionClassIdentifier))
+
+	// the vendor options survive the serialization
+	parsed, err := dhcpv4.FromBytes(offer.ToBytes())
+	require.NoError(t, err)
+	opts, err := parsed.VendorOptions("")
+	require.NoError(t, err)
+	require.Equal(t, []dhcpv4.Option{&OptDiscoveryControl{Control: DiscoveryBootFileDirect}}, opts)
+}
+
+func TestNewBootReplyErrors(t *testing.T) {
+	tftp := net.IPv4(192, 0, 2, 69)
+	efi := newPXEDiscover(t, "PXEClient:Arch:00007:UNDI:003016")
+
+	_, err := NewBootReply(newPXEDiscover(t, "MSFT 5.0"), iana.EFI_BC, tftp, "ipxe.efi")
+	require.Error(t, err, "not a PXE client")
+	_, err = NewBootReply(efi, iana.INTEL_X86PC, tftp, "pxelinux.0")
+	require.Error(t, err, "wrong architecture")
+	_, err = NewBootReply(efi, iana.EFI_BC, net.ParseIP("2001:db8::69"), "ipxe.efi")
+	require.Error(t, err, "IPv6 TFTP server")
+	_, err = NewBootReply(efi, iana.EFI_BC, tftp, "")
+	require.Error(t, err, "empty boot file")
+	_, err = NewBootReply(efi, iana.EFI_BC, tftp, string(make([]byte, 128)))
+	require.Error(t, err, "boot file too long")
+}
@@ -0,0 +1,13 @@
+/*
+The pxe package implements the PXE-specific sub-options that are carried in
+the Vendor Specific Information option (43) of DHCP packets exchanged with PXE
+clients, most notably the boot menu that PXE and proxyDHCP servers use to let
+the user pick what to boot. For the simpler case of a single boot file,
+NewBootReply builds a complete reply in one call.
+
+The specification is the Preboot Execution Environment (PXE) Specification,
+version 2.1, section 2.4:
+http://www.pix.net/software/pxeboot/archive/pxespec.pdf
+*/
+
+package pxe
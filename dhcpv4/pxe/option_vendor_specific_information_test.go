@@ -0,0 +1,81 @@
+package pxe
+
+import (
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptVendorSpecificInformationInterfaceMethods(t *testing.T) {
+	o := &OptVendorSpecificInformation{
+		Options: []dhcpv4.Option{
+			&OptDiscoveryControl{Control: DiscoveryDisableMulticast},
+			&OptMenuPrompt{Timeout: 5, Prompt: "Go"},
+		},
+	}
+	expected := []byte{
+		43, 9,
+		6, 1, 2,
+		10, 3, 5, 'G', 'o',
+		255,
+	}
+	require.Equal(t, dhcpv4.OptionVendorSpecificInformation, o.Code(), "Code")
+	require.Equal(t, 9, o.Length(), "Length")
+	require.Equal(t, expected, o.ToBytes(), "ToBytes")
+	require.Equal(t,
+		"Vendor Specific Information ->\n"+
+			"  PXE Discovery Control -> Disable Multicast\n"+
+			"  PXE Menu Prompt -> Go (timeout 5s)",
+		o.String(), "String")
+}
+
+func TestParseOptVendorSpecificInformation(t *testing.T) {
+	var (
+		o   *OptVendorSpecificInformation
+		err error
+	)
+	o, err = ParseOptVendorSpecificInformation([]byte{})
+	require.Error(t, err, "empty byte stream")
+
+	o, err = ParseOptVendorSpecificInformation([]byte{1, 1, 255})
+	require.Error(t, err, "wrong option code")
+
+	o, err = ParseOptVendorSpecificInformation([]byte{43, 5, 6, 1})
+	require.Error(t, err, "short byte stream")
+
+	o, err = ParseOptVendorSpecificInformation([]byte{43, 3, 6, 2, 1})
+	require.Error(t, err, "invalid sub-option")
+
+	o, err = ParseOptVendorSpecificInformation([]byte{
+		43, 10,
+		6, 1, 2,
+		1, 4, 224, 0, 1, 1,
+		255,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(o.Options))
+	require.Equal(t, &OptDiscoveryControl{Control: DiscoveryDisableMulticast}, o.Options[0])
+	require.Equal(t, OptionMTFTPIPAddress, o.Options[1].Code())
+
+	// missing terminator and trailing data after the terminator are tolerated
+	o, err = ParseOptVendorSpecificInformation([]byte{43, 3, 6, 1, 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(o.Options))
+	o, err = ParseOptVendorSpecificInformation([]byte{43, 5, 6, 1, 2, 255, 0})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(o.Options))
+}
+
+func TestOptVendorSpecificInformationGetOption(t *testing.T) {
+	o := &OptVendorSpecificInformation{
+		Options: []dhcpv4.Option{
+			&OptDiscoveryControl{Control: DiscoveryDisableMulticast},
+			&OptMenuPrompt{Timeout: 5, Prompt: "Go"},
+		},
+	}
+	require.Equal(t, []dhcpv4.Option{o.Options[1]}, o.GetOption(OptionMenuPrompt))
+	require.Equal(t, o.Options[0], o.GetOneOption(OptionDiscoveryControl))
+	require.Nil(t, o.GetOneOption(OptionBootMenu))
+	require.Empty(t, o.GetOption(OptionBootMenu))
+}
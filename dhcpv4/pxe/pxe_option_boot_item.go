@@ -0,0 +1,64 @@
+package pxe
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// OptBootItem represents the PXE Boot Item sub-option, which a client sends in
+// its request to a boot server to tell the boot menu item it chose, and which
+// the boot server sends back in its reply. Layer is the layer of the boot
+// file being requested, 0 for the first one.
+type OptBootItem struct {
+	Type  BootServerType
+	Layer uint16
+}
+
+// ParseOptBootItem returns a new OptBootItem from a byte stream, or error if
+// any.
+func ParseOptBootItem(data []byte) (*OptBootItem, error) {
+	if len(data) < 2 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	code := dhcpv4.OptionCode(data[0])
+	if code != OptionBootItem {
+		return nil, fmt.Errorf("expected code %v, got %v", OptionBootItem, code)
+	}
+	length := int(data[1])
+	if length != 4 {
+		return nil, fmt.Errorf("unexpected length: expected 4, got %v", length)
+	}
+	if len(data) < 6 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	return &OptBootItem{
+		Type:  BootServerType(binary.BigEndian.Uint16(data[2:4])),
+		Layer: binary.BigEndian.Uint16(data[4:6]),
+	}, nil
+}
+
+// Code returns the option code.
+func (o *OptBootItem) Code() dhcpv4.OptionCode {
+	return OptionBootItem
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptBootItem) ToBytes() []byte {
+	bs := []byte{byte(o.Code()), byte(o.Length()), 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(bs[2:4], uint16(o.Type))
+	binary.BigEndian.PutUint16(bs[4:6], o.Layer)
+	return bs
+}
+
+// String returns a human-readable string.
+func (o *OptBootItem) String() string {
+	return fmt.Sprintf("PXE Boot Item -> %v (%d), layer %d", o.Type, uint16(o.Type), o.Layer)
+}
+
+// Length returns the length of the data portion (excluding option code and
+// byte length).
+func (o *OptBootItem) Length() int {
+	return 4
+}
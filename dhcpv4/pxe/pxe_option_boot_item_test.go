@@ -0,0 +1,37 @@
+package pxe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptBootItemInterfaceMethods(t *testing.T) {
+	o := OptBootItem{Type: BootServerTypeLinuxInstall, Layer: 1}
+	require.Equal(t, OptionBootItem, o.Code(), "Code")
+	require.Equal(t, []byte{71, 4, 0, 12, 0, 1}, o.ToBytes(), "ToBytes")
+	require.Equal(t, 4, o.Length(), "Length")
+	require.Equal(t, "PXE Boot Item -> Linux Install (12), layer 1", o.String(), "String")
+}
+
+func TestParseOptBootItem(t *testing.T) {
+	var (
+		o   *OptBootItem
+		err error
+	)
+	o, err = ParseOptBootItem([]byte{})
+	require.Error(t, err, "empty byte stream")
+
+	o, err = ParseOptBootItem([]byte{71, 2, 0, 12})
+	require.Error(t, err, "wrong length")
+
+	o, err = ParseOptBootItem([]byte{71, 4, 0, 12})
+	require.Error(t, err, "short byte stream")
+
+	o, err = ParseOptBootItem([]byte{53, 4, 0, 12, 0, 0})
+	require.Error(t, err, "wrong option code")
+
+	o, err = ParseOptBootItem([]byte{71, 4, 0x80, 0, 0, 2})
+	require.NoError(t, err)
+	require.Equal(t, &OptBootItem{Type: 32768, Layer: 2}, o)
+}
@@ -0,0 +1,97 @@
+package pxe
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// BootMenuItem is an entry of the boot menu. Type selects the boot server
+// type that is contacted when the item is chosen, BootServerTypeLocalBoot
+// meaning that the client should boot from the local disk.
+type BootMenuItem struct {
+	Type        BootServerType
+	Description string
+}
+
+func (i BootMenuItem) String() string {
+	return fmt.Sprintf("%v (%d): %v", i.Type, uint16(i.Type), i.Description)
+}
+
+// OptBootMenu represents the PXE Boot Menu sub-option, listing the items that
+// are presented to the user, in order.
+type OptBootMenu struct {
+	Items []BootMenuItem
+}
+
+// ParseOptBootMenu returns a new OptBootMenu from a byte stream, or error if
+// any.
+func ParseOptBootMenu(data []byte) (*OptBootMenu, error) {
+	if len(data) < 2 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	code := dhcpv4.OptionCode(data[0])
+	if code != OptionBootMenu {
+		return nil, fmt.Errorf("expected code %v, got %v", OptionBootMenu, code)
+	}
+	length := int(data[1])
+	if len(data) < length+2 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	data = data[2 : length+2]
+
+	var items []BootMenuItem
+	for len(data) > 0 {
+		// type (2 bytes) + description length (1 byte)
+		if len(data) < 3 {
+			return nil, dhcpv4.ErrShortByteStream
+		}
+		typ := BootServerType(binary.BigEndian.Uint16(data[:2]))
+		descLen := int(data[2])
+		data = data[3:]
+		if len(data) < descLen {
+			return nil, dhcpv4.ErrShortByteStream
+		}
+		items = append(items, BootMenuItem{Type: typ, Description: string(data[:descLen])})
+		data = data[descLen:]
+	}
+	return &OptBootMenu{Items: items}, nil
+}
+
+// Code returns the option code.
+func (o *OptBootMenu) Code() dhcpv4.OptionCode {
+	return OptionBootMenu
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptBootMenu) ToBytes() []byte {
+	bs := []byte{byte(o.Code()), byte(o.Length())}
+	for _, item := range o.Items {
+		var t [2]byte
+		binary.BigEndian.PutUint16(t[:], uint16(item.Type))
+		bs = append(bs, t[:]...)
+		bs = append(bs, byte(len(item.Description)))
+		bs = append(bs, []byte(item.Description)...)
+	}
+	return bs
+}
+
+// String returns a human-readable string.
+func (o *OptBootMenu) String() string {
+	s := "PXE Boot Menu ->"
+	for _, item := range o.Items {
+		s += "\n  " + item.String()
+	}
+	return s
+}
+
+// Length returns the length of the data portion (excluding option code and
+// byte length).
+func (o *OptBootMenu) Length() int {
+	var length int
+	for _, item := range o.Items {
+		length += 3 + len(item.Description)
+	}
+	return length
+}
@@ -0,0 +1,54 @@
+package pxe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptBootMenuInterfaceMethods(t *testing.T) {
+	o := OptBootMenu{
+		Items: []BootMenuItem{
+			BootMenuItem{Type: BootServerTypeLocalBoot, Description: "Local"},
+			BootMenuItem{Type: 32769, Description: "Linux"},
+		},
+	}
+	expected := []byte{
+		9, 16,
+		0, 0, 5, 'L', 'o', 'c', 'a', 'l',
+		128, 1, 5, 'L', 'i', 'n', 'u', 'x',
+	}
+	require.Equal(t, OptionBootMenu, o.Code(), "Code")
+	require.Equal(t, expected, o.ToBytes(), "ToBytes")
+	require.Equal(t, 16, o.Length(), "Length")
+	require.Equal(t,
+		"PXE Boot Menu ->\n"+
+			"  Local Boot (0): Local\n"+
+			"  Unknown (32769): Linux",
+		o.String(), "String")
+}
+
+func TestParseOptBootMenu(t *testing.T) {
+	var (
+		o   *OptBootMenu
+		err error
+	)
+	o, err = ParseOptBootMenu([]byte{})
+	require.Error(t, err, "empty byte stream")
+
+	o, err = ParseOptBootMenu([]byte{9, 5, 0, 0})
+	require.Error(t, err, "short byte stream")
+
+	o, err = ParseOptBootMenu([]byte{9, 2, 0, 0})
+	require.Error(t, err, "truncated item header")
+
+	o, err = ParseOptBootMenu([]byte{9, 5, 0, 0, 5, 'L', 'o'})
+	require.Error(t, err, "truncated description")
+
+	o, err = ParseOptBootMenu([]byte{53, 0})
+	require.Error(t, err, "wrong option code")
+
+	o, err = ParseOptBootMenu([]byte{9, 8, 0, 0, 5, 'L', 'o', 'c', 'a', 'l'})
+	require.NoError(t, err)
+	require.Equal(t, []BootMenuItem{BootMenuItem{Type: BootServerTypeLocalBoot, Description: "Local"}}, o.Items)
+}
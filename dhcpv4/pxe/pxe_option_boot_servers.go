@@ -0,0 +1,106 @@
+package pxe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// BootServer describes a set of boot servers of a given type.
+type BootServer struct {
+	Type      BootServerType
+	Addresses []net.IP
+}
+
+func (b BootServer) String() string {
+	addrs := make([]string, 0, len(b.Addresses))
+	for _, a := range b.Addresses {
+		addrs = append(addrs, a.String())
+	}
+	return fmt.Sprintf("%v (%d): %v", b.Type, uint16(b.Type), strings.Join(addrs, ", "))
+}
+
+// OptBootServers represents the PXE Boot Servers sub-option, listing the boot
+// servers the client can contact for each boot server type.
+type OptBootServers struct {
+	Servers []BootServer
+}
+
+// ParseOptBootServers returns a new OptBootServers from a byte stream, or error
+// if any.
+func ParseOptBootServers(data []byte) (*OptBootServers, error) {
+	if len(data) < 2 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	code := dhcpv4.OptionCode(data[0])
+	if code != OptionBootServers {
+		return nil, fmt.Errorf("expected code %v, got %v", OptionBootServers, code)
+	}
+	length := int(data[1])
+	if len(data) < length+2 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	data = data[2 : length+2]
+
+	var servers []BootServer
+	for len(data) > 0 {
+		// type (2 bytes) + IP count (1 byte)
+		if len(data) < 3 {
+			return nil, dhcpv4.ErrShortByteStream
+		}
+		server := BootServer{Type: BootServerType(binary.BigEndian.Uint16(data[:2]))}
+		count := int(data[2])
+		data = data[3:]
+		if len(data) < count*net.IPv4len {
+			return nil, dhcpv4.ErrShortByteStream
+		}
+		for i := 0; i < count; i++ {
+			server.Addresses = append(server.Addresses, net.IP(data[:net.IPv4len]))
+			data = data[net.IPv4len:]
+		}
+		servers = append(servers, server)
+	}
+	return &OptBootServers{Servers: servers}, nil
+}
+
+// Code returns the option code.
+func (o *OptBootServers) Code() dhcpv4.OptionCode {
+	return OptionBootServers
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptBootServers) ToBytes() []byte {
+	bs := []byte{byte(o.Code()), byte(o.Length())}
+	for _, server := range o.Servers {
+		var t [2]byte
+		binary.BigEndian.PutUint16(t[:], uint16(server.Type))
+		bs = append(bs, t[:]...)
+		bs = append(bs, byte(len(server.Addresses)))
+		for _, addr := range server.Addresses {
+			bs = append(bs, addr.To4()...)
+		}
+	}
+	return bs
+}
+
+// String returns a human-readable string.
+func (o *OptBootServers) String() string {
+	s := "PXE Boot Servers ->"
+	for _, server := range o.Servers {
+		s += "\n  " + server.String()
+	}
+	return s
+}
+
+// Length returns the length of the data portion (excluding option code and
+// byte length).
+func (o *OptBootServers) Length() int {
+	var length int
+	for _, server := range o.Servers {
+		length += 3 + len(server.Addresses)*net.IPv4len
+	}
+	return length
+}
@@ -0,0 +1,63 @@
+package pxe
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptBootServersInterfaceMethods(t *testing.T) {
+	o := OptBootServers{
+		Servers: []BootServer{
+			BootServer{
+				Type:      BootServerTypeMicrosoftWindows,
+				Addresses: []net.IP{net.IP{10, 0, 0, 1}, net.IP{10, 0, 0, 2}},
+			},
+			BootServer{
+				Type:      32768,
+				Addresses: []net.IP{net.IPv4(192, 168, 0, 1)},
+			},
+		},
+	}
+	expected := []byte{
+		8, 18,
+		0, 1, 2, 10, 0, 0, 1, 10, 0, 0, 2,
+		128, 0, 1, 192, 168, 0, 1,
+	}
+	require.Equal(t, OptionBootServers, o.Code(), "Code")
+	require.Equal(t, expected, o.ToBytes(), "ToBytes")
+	require.Equal(t, 18, o.Length(), "Length")
+	require.Equal(t,
+		"PXE Boot Servers ->\n"+
+			"  Microsoft Windows NT Boot Server (1): 10.0.0.1, 10.0.0.2\n"+
+			"  Unknown (32768): 192.168.0.1",
+		o.String(), "String")
+}
+
+func TestParseOptBootServers(t *testing.T) {
+	var (
+		o   *OptBootServers
+		err error
+	)
+	o, err = ParseOptBootServers([]byte{})
+	require.Error(t, err, "empty byte stream")
+
+	o, err = ParseOptBootServers([]byte{8, 5, 0, 1})
+	require.Error(t, err, "short byte stream")
+
+	o, err = ParseOptBootServers([]byte{8, 2, 0, 1})
+	require.Error(t, err, "truncated server header")
+
+	o, err = ParseOptBootServers([]byte{8, 5, 0, 1, 2, 10, 0})
+	require.Error(t, err, "truncated addresses")
+
+	o, err = ParseOptBootServers([]byte{53, 0})
+	require.Error(t, err, "wrong option code")
+
+	o, err = ParseOptBootServers([]byte{8, 7, 0, 1, 1, 10, 0, 0, 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(o.Servers))
+	require.Equal(t, BootServerTypeMicrosoftWindows, o.Servers[0].Type)
+	require.Equal(t, []net.IP{net.IP{10, 0, 0, 1}}, o.Servers[0].Addresses)
+}
@@ -0,0 +1,93 @@
+package pxe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// DiscoveryControl is a bit field that tells the PXE client how to discover
+// boot servers.
+type DiscoveryControl uint8
+
+// Discovery control bits, as defined in the PXE specification section 2.4.2.
+const (
+	// DiscoveryDisableBroadcast disables broadcast discovery.
+	DiscoveryDisableBroadcast DiscoveryControl = 1 << iota
+	// DiscoveryDisableMulticast disables multicast discovery.
+	DiscoveryDisableMulticast
+	// DiscoveryOnlyBootServers makes the client only use and accept the
+	// servers listed in the Boot Servers option.
+	DiscoveryOnlyBootServers
+	// DiscoveryBootFileDirect makes the client download the boot file name
+	// found in the offer right away, without prompting nor showing the menu.
+	DiscoveryBootFileDirect
+)
+
+// discoveryControlToString maps each discovery control bit to its name.
+var discoveryControlToString = []struct {
+	bit  DiscoveryControl
+	name string
+}{
+	{DiscoveryDisableBroadcast, "Disable Broadcast"},
+	{DiscoveryDisableMulticast, "Disable Multicast"},
+	{DiscoveryOnlyBootServers, "Only Boot Servers"},
+	{DiscoveryBootFileDirect, "Boot File Direct"},
+}
+
+func (d DiscoveryControl) String() string {
+	var flags []string
+	for _, f := range discoveryControlToString {
+		if d&f.bit != 0 {
+			flags = append(flags, f.name)
+		}
+	}
+	if len(flags) == 0 {
+		return "None"
+	}
+	return strings.Join(flags, ", ")
+}
+
+// OptDiscoveryControl represents the PXE Discovery Control sub-option.
+type OptDiscoveryControl struct {
+	Control DiscoveryControl
+}
+
+// ParseOptDiscoveryControl returns a new OptDiscoveryControl from a byte
+// stream, or error if any.
+func ParseOptDiscoveryControl(data []byte) (*OptDiscoveryControl, error) {
+	if len(data) < 3 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	code := dhcpv4.OptionCode(data[0])
+	if code != OptionDiscoveryControl {
+		return nil, fmt.Errorf("expected code %v, got %v", OptionDiscoveryControl, code)
+	}
+	length := int(data[1])
+	if length != 1 {
+		return nil, fmt.Errorf("unexpected length: expected 1, got %v", length)
+	}
+	return &OptDiscoveryControl{Control: DiscoveryControl(data[2])}, nil
+}
+
+// Code returns the option code.
+func (o *OptDiscoveryControl) Code() dhcpv4.OptionCode {
+	return OptionDiscoveryControl
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptDiscoveryControl) ToBytes() []byte {
+	return []byte{byte(o.Code()), byte(o.Length()), byte(o.Control)}
+}
+
+// String returns a human-readable string.
+func (o *OptDiscoveryControl) String() string {
+	return fmt.Sprintf("PXE Discovery Control -> %v", o.Control)
+}
+
+// Length returns the length of the data portion (excluding option code and
+// byte length).
+func (o *OptDiscoveryControl) Length() int {
+	return 1
+}
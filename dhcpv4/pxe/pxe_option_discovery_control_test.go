@@ -0,0 +1,39 @@
+package pxe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptDiscoveryControlInterfaceMethods(t *testing.T) {
+	o := OptDiscoveryControl{Control: DiscoveryDisableMulticast | DiscoveryBootFileDirect}
+	require.Equal(t, OptionDiscoveryControl, o.Code(), "Code")
+	require.Equal(t, []byte{6, 1, 10}, o.ToBytes(), "ToBytes")
+	require.Equal(t, 1, o.Length(), "Length")
+	require.Equal(t, "PXE Discovery Control -> Disable Multicast, Boot File Direct", o.String(), "String")
+}
+
+func TestDiscoveryControlString(t *testing.T) {
+	require.Equal(t, "None", DiscoveryControl(0).String())
+	require.Equal(t, "Disable Broadcast", DiscoveryDisableBroadcast.String())
+}
+
+func TestParseOptDiscoveryControl(t *testing.T) {
+	var (
+		o   *OptDiscoveryControl
+		err error
+	)
+	o, err = ParseOptDiscoveryControl([]byte{})
+	require.Error(t, err, "empty byte stream")
+
+	o, err = ParseOptDiscoveryControl([]byte{6, 2, 1, 1})
+	require.Error(t, err, "wrong length")
+
+	o, err = ParseOptDiscoveryControl([]byte{53, 1, 1})
+	require.Error(t, err, "wrong option code")
+
+	o, err = ParseOptDiscoveryControl([]byte{6, 1, 3})
+	require.NoError(t, err)
+	require.Equal(t, DiscoveryDisableBroadcast|DiscoveryDisableMulticast, o.Control)
+}
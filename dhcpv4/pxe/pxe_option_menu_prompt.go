@@ -0,0 +1,58 @@
+package pxe
+
+import (
+	"fmt"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// OptMenuPrompt represents the PXE Menu Prompt sub-option. Timeout is the
+// number of seconds the prompt is shown before the first boot menu item is
+// selected: 0 selects it right away without showing the menu, 255 waits
+// forever for the user.
+type OptMenuPrompt struct {
+	Timeout uint8
+	Prompt  string
+}
+
+// ParseOptMenuPrompt returns a new OptMenuPrompt from a byte stream, or error
+// if any.
+func ParseOptMenuPrompt(data []byte) (*OptMenuPrompt, error) {
+	if len(data) < 3 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	code := dhcpv4.OptionCode(data[0])
+	if code != OptionMenuPrompt {
+		return nil, fmt.Errorf("expected code %v, got %v", OptionMenuPrompt, code)
+	}
+	length := int(data[1])
+	if length < 1 {
+		return nil, fmt.Errorf("unexpected length: expected at least 1, got %v", length)
+	}
+	if len(data) < length+2 {
+		return nil, dhcpv4.ErrShortByteStream
+	}
+	return &OptMenuPrompt{Timeout: data[2], Prompt: string(data[3 : length+2])}, nil
+}
+
+// Code returns the option code.
+func (o *OptMenuPrompt) Code() dhcpv4.OptionCode {
+	return OptionMenuPrompt
+}
+
+// ToBytes returns a serialized stream of bytes for this option.
+func (o *OptMenuPrompt) ToBytes() []byte {
+	bs := []byte{byte(o.Code()), byte(o.Length()), o.Timeout}
+	return append(bs, []byte(o.Prompt)...)
+}
+
+// String returns a human-readable string.
+func (o *OptMenuPrompt) String() string {
+	return fmt.Sprintf("PXE Menu Prompt -> %v (timeout %ds)", o.Prompt, o.Timeout)
+}
+
+// Length returns the length of the data portion (excluding option code and
+// byte length).
+func (o *OptMenuPrompt) Length() int {
+	return 1 + len(o.Prompt)
+}
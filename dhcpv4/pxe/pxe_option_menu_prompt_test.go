@@ -0,0 +1,38 @@
+package pxe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptMenuPromptInterfaceMethods(t *testing.T) {
+	o := OptMenuPrompt{Timeout: 10, Prompt: "Boot"}
+	require.Equal(t, OptionMenuPrompt, o.Code(), "Code")
+	require.Equal(t, []byte{10, 5, 10, 'B', 'o', 'o', 't'}, o.ToBytes(), "ToBytes")
+	require.Equal(t, 5, o.Length(), "Length")
+	require.Equal(t, "PXE Menu Prompt -> Boot (timeout 10s)", o.String(), "String")
+}
+
+func TestParseOptMenuPrompt(t *testing.T) {
+	var (
+		o   *OptMenuPrompt
+		err error
+	)
+	o, err = ParseOptMenuPrompt([]byte{})
+	require.Error(t, err, "empty byte stream")
+
+	o, err = ParseOptMenuPrompt([]byte{10, 0, 0})
+	require.Error(t, err, "zero length")
+
+	o, err = ParseOptMenuPrompt([]byte{10, 5, 10, 'B'})
+	require.Error(t, err, "short byte stream")
+
+	o, err = ParseOptMenuPrompt([]byte{53, 1, 10})
+	require.Error(t, err, "wrong option code")
+
+	o, err = ParseOptMenuPrompt([]byte{10, 5, 255, 'B', 'o', 'o', 't'})
+	require.NoError(t, err)
+	require.Equal(t, uint8(255), o.Timeout)
+	require.Equal(t, "Boot", o.Prompt)
+}
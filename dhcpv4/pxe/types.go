@@ -0,0 +1,106 @@
+package pxe
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// PXEClientVendorClassIdentifier is the prefix of the Class Identifier option
+// (60) sent by PXE clients. Servers must send it back in their replies.
+const PXEClientVendorClassIdentifier = "PXEClient"
+
+// Options (occur as sub-options of DHCP option 43).
+const (
+	OptionMTFTPIPAddress         dhcpv4.OptionCode = 1
+	OptionMTFTPClientPort        dhcpv4.OptionCode = 2
+	OptionMTFTPServerPort        dhcpv4.OptionCode = 3
+	OptionMTFTPTimeout           dhcpv4.OptionCode = 4
+	OptionMTFTPDelay             dhcpv4.OptionCode = 5
+	OptionDiscoveryControl       dhcpv4.OptionCode = 6
+	OptionDiscoveryMulticastAddr dhcpv4.OptionCode = 7
+	OptionBootServers            dhcpv4.OptionCode = 8
+	OptionBootMenu               dhcpv4.OptionCode = 9
+	OptionMenuPrompt             dhcpv4.OptionCode = 10
+	OptionMulticastAddrsAlloc    dhcpv4.OptionCode = 11
+	OptionCredentialTypes        dhcpv4.OptionCode = 12
+	OptionBootItem               dhcpv4.OptionCode = 71
+)
+
+// OptionCodeToString maps PXE OptionCodes to human-readable strings
+// describing what they are.
+var OptionCodeToString = map[dhcpv4.OptionCode]string{
+	OptionMTFTPIPAddress:         "PXE MTFTP IP Address",
+	OptionMTFTPClientPort:        "PXE MTFTP Client Port",
+	OptionMTFTPServerPort:        "PXE MTFTP Server Port",
+	OptionMTFTPTimeout:           "PXE MTFTP Timeout",
+	OptionMTFTPDelay:             "PXE MTFTP Delay",
+	OptionDiscoveryControl:       "PXE Discovery Control",
+	OptionDiscoveryMulticastAddr: "PXE Discovery Multicast Address",
+	OptionBootServers:            "PXE Boot Servers",
+	OptionBootMenu:               "PXE Boot Menu",
+	OptionMenuPrompt:             "PXE Menu Prompt",
+	OptionMulticastAddrsAlloc:    "PXE Multicast Addresses Allocation",
+	OptionCredentialTypes:        "PXE Credential Types",
+	OptionBootItem:               "PXE Boot Item",
+}
+
+// BootServerType identifies a class of boot servers, and is used both in the
+// boot servers list and in the boot menu items.
+type BootServerType uint16
+
+// Boot server types defined by the PXE specification. Types 32768-65534 are
+// vendor-specific.
+const (
+	BootServerTypeLocalBoot        BootServerType = 0
+	BootServerTypeMicrosoftWindows BootServerType = 1
+	BootServerTypeIntelLCM         BootServerType = 2
+	BootServerTypeDOSUNDI          BootServerType = 3
+	BootServerTypeNECESMPRO        BootServerType = 4
+	BootServerTypeIBMWSoD          BootServerType = 5
+	BootServerTypeIBMLCCM          BootServerType = 6
+	BootServerTypeCAUnicenterTNG   BootServerType = 7
+	BootServerTypeHPOpenView       BootServerType = 8
+	BootServerTypeLinuxInstall     BootServerType = 12
+	BootServerTypeApiTest          BootServerType = 65535
+)
+
+func (t BootServerType) String() string {
+	if s, ok := BootServerTypeToString[t]; ok {
+		return s
+	}
+	return "Unknown"
+}
+
+// BootServerTypeToString maps a BootServerType to its mnemonic name.
+var BootServerTypeToString = map[BootServerType]string{
+	BootServerTypeLocalBoot:        "Local Boot",
+	BootServerTypeMicrosoftWindows: "Microsoft Windows NT Boot Server",
+	BootServerTypeIntelLCM:         "Intel LCM Boot Server",
+	BootServerTypeDOSUNDI:          "DOS/UNDI",
+	BootServerTypeNECESMPRO:        "NEC ESMPRO",
+	BootServerTypeIBMWSoD:          "IBM WSoD",
+	BootServerTypeIBMLCCM:          "IBM LCCM",
+	BootServerTypeCAUnicenterTNG:   "CA Unicenter TNG",
+	BootServerTypeHPOpenView:       "HP OpenView",
+	BootServerTypeLinuxInstall:     "Linux Install",
+	BootServerTypeApiTest:          "API Test",
+}
+
+// ClientArch returns the client system architecture that PXE clients put in
+// their class identifier, e.g. iana.EFI_X86_64 for
+// "PXEClient:Arch:00009:UNDI:003016". It returns false if the class identifier
+// is not the one of a PXE client, or does not carry the architecture.
+func ClientArch(class string) (iana.ArchType, bool) {
+	fields := strings.Split(class, ":")
+	if len(fields) < 3 || fields[0] != PXEClientVendorClassIdentifier || fields[1] != "Arch" {
+		return 0, false
+	}
+	arch, err := strconv.ParseUint(fields[2], 10, 16)
+	if err != nil {
+		return 0, false
+	}
+	return iana.ArchType(arch), true
+}
@@ -0,0 +1,27 @@
+package pxe
+
+import (
+	"testing"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientArch(t *testing.T) {
+	arch, ok := ClientArch("PXEClient:Arch:00009:UNDI:003016")
+	require.True(t, ok)
+	require.Equal(t, iana.EFI_X86_64, arch)
+	arch, ok = ClientArch("PXEClient:Arch:00000")
+	require.True(t, ok)
+	require.Equal(t, iana.INTEL_X86PC, arch)
+
+	for _, class := range []string{
+		"PXEClient",
+		"PXEClient:UNDI:003016",
+		"PXEClient:Arch:x",
+		"HTTPClient:Arch:00016",
+	} {
+		_, ok = ClientArch(class)
+		require.False(t, ok, class)
+	}
+}
@@ -0,0 +1,33 @@
+/*
+The relay4 package provides a DHCPv4 relay agent, as described in RFC 1542
+and RFC 3046: it relays the requests that clients broadcast on a network to
+DHCP servers on other networks, stamping them with its address (giaddr) and
+optionally with a relay agent information option, and relays the replies of
+the servers back to the clients.
+
+  Example program:
+
+
+package main
+
+import (
+	"log"
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/relay4"
+)
+
+func main() {
+	relay, err := relay4.NewRelay("eth1", net.ParseIP("10.0.0.1"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer relay.Close()
+	if err := relay.ActivateAndServe(); err != nil {
+		log.Panic(err)
+	}
+}
+
+*/
+
+package relay4
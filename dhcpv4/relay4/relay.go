@@ -0,0 +1,340 @@
+package relay4
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+	"golang.org/x/net/ipv4"
+)
+
+// Relay is a DHCPv4 relay agent serving the clients of one network, the one of
+// its client-facing interface. It is created with NewRelay.
+type Relay struct {
+	// Address is the address of the relay agent on the network of the
+	// clients. It is stamped as the gateway address (giaddr) of the requests
+	// of the clients, so that the servers know their network, and the
+	// servers send their replies to it.
+	Address net.IP
+	// Servers are the addresses of the DHCP servers to which the requests
+	// are relayed. Every request is relayed to all of them.
+	Servers []net.IP
+	// AgentInfo, if not nil, is added to the requests of the clients, e.g.
+	// with the circuit ID of the interface, and stripped from the replies.
+	AgentInfo *dhcpv4.OptRelayAgentInformation
+	// MaxHopCount is the number of times a request can be relayed before it
+	// is dropped. If zero, server4.DefaultMaxHopCount is used.
+	MaxHopCount uint8
+	// ServerPort is the port on which the relay agent listens, and to which
+	// it relays the requests, and ClientPort the one to which it relays the
+	// replies. If zero, dhcpv4.ServerPort and dhcpv4.ClientPort are used.
+	ServerPort, ClientPort int
+
+	// RewriteRequest, if not nil, is called on the requests once their
+	// gateway address and relay agent information option are set, just
+	// before they are relayed, and may change their options. The request is
+	// dropped if it returns false.
+	RewriteRequest func(request *dhcpv4.DHCPv4) bool
+	// RewriteReply, if not nil, is called on the replies once the relay
+	// agent information option is stripped, just before they are relayed
+	// to the client. The reply is dropped if it returns false.
+	RewriteReply func(reply *dhcpv4.DHCPv4) bool
+
+	// Unicast, if not nil, sends the replies to the clients that have no
+	// address yet and did not ask for broadcast replies. These replies are
+	// broadcast otherwise.
+	Unicast server4.UnicastReplier
+
+	// Logger, if not nil, is where the relay agent reports what it does.
+	// dhcpv4.DefaultLogger is used otherwise.
+	Logger dhcpv4.Logger
+
+	// ifindex is the index of the client-facing interface, or 0 to serve
+	// the clients of all the interfaces.
+	ifindex    int
+	conn       net.PacketConn
+	connMutex  sync.Mutex
+	shouldStop chan bool
+}
+
+// NewRelay returns a Relay serving the clients of the given interface, whose
+// first IPv4 address is used as gateway address, and relaying their requests
+// to the given servers.
+func NewRelay(ifname string, servers ...net.IP) (*Relay, error) {
+	iface, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	var address net.IP
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+			address = ipnet.IP.To4()
+			break
+		}
+	}
+	if address == nil {
+		return nil, fmt.Errorf("interface %s has no IPv4 address", ifname)
+	}
+	return &Relay{
+		Address:    address,
+		Servers:    servers,
+		ifindex:    iface.Index,
+		shouldStop: make(chan bool, 1),
+	}, nil
+}
+
+// LocalAddr returns the local address of the listening socket, or nil if not
+// listening
+func (r *Relay) LocalAddr() net.Addr {
+	r.connMutex.Lock()
+	defer r.connMutex.Unlock()
+	if r.conn == nil {
+		return nil
+	}
+	return r.conn.LocalAddr()
+}
+
+// ActivateAndServe starts the relay agent. It listens on the server port of
+// all the addresses, since the clients broadcast their requests, and the
+// servers send their replies to Address.
+func (r *Relay) ActivateAndServe() error {
+	if r.Address.To4() == nil {
+		return fmt.Errorf("invalid relay agent address %v", r.Address)
+	}
+	if len(r.Servers) == 0 {
+		return errors.New("no server to relay to")
+	}
+	r.connMutex.Lock()
+	if r.conn == nil {
+		conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: r.serverPort()})
+		if err != nil {
+			r.connMutex.Unlock()
+			return err
+		}
+		r.conn = conn
+	}
+	pc, ok := r.conn.(*net.UDPConn)
+	r.connMutex.Unlock()
+	defer func() {
+		r.connMutex.Lock()
+		r.conn.Close()
+		r.conn = nil
+		r.connMutex.Unlock()
+	}()
+	if !ok {
+		return fmt.Errorf("Error: not an UDPConn")
+	}
+	logger := r.logger()
+	logger.Printf("Relay agent listening on %s, relaying to %v", pc.LocalAddr(), r.Servers)
+	// the interface of the requests tells whether they come from the
+	// clients, and the broadcast replies have to be sent out of the
+	// client-facing interface
+	p := ipv4.NewPacketConn(pc)
+	if err := p.SetControlMessage(ipv4.FlagInterface, true); err != nil {
+		logger.Printf("Cannot get the interface of the messages: %v", err)
+	}
+	rbuf := make([]byte, dhcpv4.MaxUDPReceivedPacketSize)
+	for {
+		select {
+		case <-r.shouldStop:
+			return nil
+		default:
+		}
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, cm, peer, err := p.ReadFrom(rbuf)
+		if err != nil {
+			switch err.(type) {
+			case net.Error:
+				// silently skip and continue
+			default:
+				logger.Printf("Error reading from packet conn: %v", err)
+			}
+			continue
+		}
+		m, err := dhcpv4.FromBytes(rbuf[:n])
+		if err != nil {
+			logger.Printf("Error parsing DHCPv4 message from %v: %v", peer, err)
+			continue
+		}
+		var ifindex int
+		if cm != nil {
+			ifindex = cm.IfIndex
+		}
+		r.handle(p, ifindex, peer, m)
+	}
+}
+
+// Close sends a termination request to the relay agent, and closes the UDP
+// listener
+func (r *Relay) Close() error {
+	select {
+	case r.shouldStop <- true:
+	default:
+	}
+	r.connMutex.Lock()
+	defer r.connMutex.Unlock()
+	if r.conn != nil {
+		return r.conn.Close()
+	}
+	return nil
+}
+
+// packetWriter is the part of ipv4.PacketConn used to send the messages.
+type packetWriter interface {
+	WriteTo(b []byte, cm *ipv4.ControlMessage, dst net.Addr) (int, error)
+}
+
+// handle relays m, received from peer on the interface with the given index,
+// or 0 if unknown.
+func (r *Relay) handle(w packetWriter, ifindex int, peer net.Addr, m *dhcpv4.DHCPv4) {
+	logger := r.logger()
+	switch m.Opcode() {
+	case dhcpv4.OpcodeBootRequest:
+		if r.ifindex != 0 && ifindex != 0 && ifindex != r.ifindex {
+			// not from the network of the clients
+			return
+		}
+		if !r.relayRequest(m) {
+			return
+		}
+		buf := m.ToBytes()
+		for _, server := range r.Servers {
+			if _, err := w.WriteTo(buf, nil, &net.UDPAddr{IP: server, Port: r.serverPort()}); err != nil {
+				logger.Printf("Cannot relay request from %v to %v: %v", peer, server, err)
+			}
+		}
+	case dhcpv4.OpcodeBootReply:
+		if !r.relayReply(m) {
+			return
+		}
+		if r.Unicast != nil && toHwAddr(m) {
+			if err := r.Unicast.Send(m, r.Address); err != nil {
+				logger.Printf("Cannot relay reply from %v to %v: %v", peer, m.ClientHwAddr(), err)
+			}
+			return
+		}
+		dst := r.replyAddr(m)
+		var cm *ipv4.ControlMessage
+		if dst.IP.Equal(net.IPv4bcast) && r.ifindex != 0 {
+			cm = &ipv4.ControlMessage{IfIndex: r.ifindex}
+		}
+		if _, err := w.WriteTo(m.ToBytes(), cm, dst); err != nil {
+			logger.Printf("Cannot relay reply from %v to %v: %v", peer, dst, err)
+		}
+	}
+}
+
+// relayRequest prepares request to be relayed to the servers, as described in
+// RFC 1542, section 4.1.1, and returns false if it must be dropped. The
+// requests of the clients get the address of the relay agent and its relay
+// agent information option, while the ones of downstream relay agents keep
+// theirs.
+func (r *Relay) relayRequest(request *dhcpv4.DHCPv4) bool {
+	logger := r.logger()
+	if request.HopCount() > r.maxHopCount() {
+		logger.Printf("Dropping request from %v: hop count %d greater than %d", request.ClientHwAddr(), request.HopCount(), r.maxHopCount())
+		return false
+	}
+	if giaddr := request.GatewayIPAddr(); giaddr == nil || giaddr.IsUnspecified() {
+		if request.GetOneOption(dhcpv4.OptionRelayAgentInformation) != nil {
+			// RFC 3046, section 2.1: an untrusted client may be
+			// spoofing the option
+			logger.Printf("Dropping request from %v: relay agent information option without gateway address", request.ClientHwAddr())
+			return false
+		}
+		request.SetGatewayIPAddr(r.Address.To4())
+		if r.AgentInfo != nil {
+			// the option must be the last one, AddOption puts it
+			// before the End option
+			request.AddOption(dhcpv4.CloneOption(r.AgentInfo))
+		}
+	}
+	request.SetHopCount(request.HopCount() + 1)
+	if r.RewriteRequest != nil && !r.RewriteRequest(request) {
+		return false
+	}
+	return true
+}
+
+// relayReply prepares reply to be relayed to the client, as described in RFC
+// 1542, section 4.1.2, and returns false if it must be dropped, e.g. because it
+// is not sent to the relay agent.
+func (r *Relay) relayReply(reply *dhcpv4.DHCPv4) bool {
+	if !reply.GatewayIPAddr().Equal(r.Address) {
+		return false
+	}
+	// RFC 3046, section 2.2: the option is not relayed to the client
+	var options []dhcpv4.Option
+	for _, opt := range reply.Options() {
+		if opt.Code() != dhcpv4.OptionRelayAgentInformation {
+			options = append(options, opt)
+		}
+	}
+	reply.SetOptions(options)
+	if r.RewriteReply != nil && !r.RewriteReply(reply) {
+		return false
+	}
+	return true
+}
+
+// replyAddr returns the address to which reply is relayed, when it is not sent
+// to the client hardware address: the client address if the client has one,
+// or the broadcast address.
+func (r *Relay) replyAddr(reply *dhcpv4.DHCPv4) *net.UDPAddr {
+	port := r.ClientPort
+	if port == 0 {
+		port = dhcpv4.ClientPort
+	}
+	if ciaddr := reply.ClientIPAddr(); ciaddr != nil && !ciaddr.IsUnspecified() && !isNak(reply) {
+		return &net.UDPAddr{IP: ciaddr, Port: port}
+	}
+	return &net.UDPAddr{IP: net.IPv4bcast, Port: port}
+}
+
+func (r *Relay) serverPort() int {
+	if r.ServerPort == 0 {
+		return dhcpv4.ServerPort
+	}
+	return r.ServerPort
+}
+
+// toHwAddr returns true if reply must be sent to the client hardware address
+// and to the offered address, since the client has no address yet and did not
+// ask for broadcast replies.
+func toHwAddr(reply *dhcpv4.DHCPv4) bool {
+	if ciaddr := reply.ClientIPAddr(); ciaddr != nil && !ciaddr.IsUnspecified() {
+		return false
+	}
+	return !reply.IsBroadcast() && !isNak(reply)
+}
+
+// isNak returns true if reply is a DHCPNAK, which is always broadcast.
+func isNak(reply *dhcpv4.DHCPv4) bool {
+	mt := reply.MessageType()
+	return mt != nil && *mt == dhcpv4.MessageTypeNak
+}
+
+// maxHopCount returns the hop count threshold of the relay agent.
+func (r *Relay) maxHopCount() uint8 {
+	if r.MaxHopCount == 0 {
+		return server4.DefaultMaxHopCount
+	}
+	return r.MaxHopCount
+}
+
+// logger returns the Logger of the relay agent, or dhcpv4.DefaultLogger if
+// there is none.
+func (r *Relay) logger() dhcpv4.Logger {
+	if r.Logger == nil {
+		return dhcpv4.DefaultLogger
+	}
+	return r.Logger
+}
@@ -0,0 +1,236 @@
+package relay4
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/ipv4"
+)
+
+// writtenPacket is a packet written to a recordingWriter.
+type writtenPacket struct {
+	data []byte
+	cm   *ipv4.ControlMessage
+	addr net.Addr
+}
+
+// recordingWriter is a packetWriter recording the packets written to it.
+type recordingWriter struct {
+	written []writtenPacket
+}
+
+func (w *recordingWriter) WriteTo(b []byte, cm *ipv4.ControlMessage, dst net.Addr) (int, error) {
+	w.written = append(w.written, writtenPacket{append([]byte{}, b...), cm, dst})
+	return len(b), nil
+}
+
+// recordingReplier is a server4.UnicastReplier recording the replies sent.
+type recordingReplier struct {
+	sent []*dhcpv4.DHCPv4
+}
+
+func (r *recordingReplier) Send(reply *dhcpv4.DHCPv4, src net.IP) error {
+	if !src.Equal(net.IPv4(192, 168, 1, 1)) {
+		return errors.New("wrong source address")
+	}
+	r.sent = append(r.sent, reply)
+	return nil
+}
+
+func (r *recordingReplier) Close() error {
+	return nil
+}
+
+var (
+	testHwAddr  = net.HardwareAddr{1, 2, 3, 4, 5, 6}
+	testPeer    = &net.UDPAddr{IP: net.IPv4zero, Port: dhcpv4.ClientPort}
+	testServers = []net.IP{net.IPv4(10, 0, 0, 1), net.IPv4(10, 0, 0, 2)}
+)
+
+func testRelay() *Relay {
+	info := &dhcpv4.OptRelayAgentInformation{}
+	info.AddSubOption(dhcpv4.RelayAgentCircuitID, []byte("eth1"))
+	return &Relay{
+		Address:   net.IPv4(192, 168, 1, 1),
+		Servers:   testServers,
+		AgentInfo: info,
+		ifindex:   2,
+	}
+}
+
+// received returns m as received from the network.
+func received(t *testing.T, m *dhcpv4.DHCPv4) *dhcpv4.DHCPv4 {
+	m, err := dhcpv4.FromBytes(m.ToBytes())
+	require.NoError(t, err)
+	return m
+}
+
+func testReply(t *testing.T, mt dhcpv4.MessageType, modifiers ...dhcpv4.Modifier) *dhcpv4.DHCPv4 {
+	discover, err := dhcpv4.NewDiscovery(testHwAddr)
+	require.NoError(t, err)
+	discover.SetFlags(0)
+	discover.SetGatewayIPAddr(net.IPv4(192, 168, 1, 1))
+	discover.UpdateOption(testRelay().AgentInfo)
+	modifiers = append([]dhcpv4.Modifier{
+		dhcpv4.WithMessageType(mt),
+		dhcpv4.WithYourIP(net.IPv4(192, 168, 1, 100)),
+	}, modifiers...)
+	reply, err := dhcpv4.NewReplyFromRequest(discover, modifiers...)
+	require.NoError(t, err)
+	return received(t, reply)
+}
+
+func withClientIP(ip net.IP) dhcpv4.Modifier {
+	return func(d *dhcpv4.DHCPv4) *dhcpv4.DHCPv4 {
+		d.SetClientIPAddr(ip)
+		return d
+	}
+}
+
+func withBroadcast(d *dhcpv4.DHCPv4) *dhcpv4.DHCPv4 {
+	d.SetBroadcast()
+	return d
+}
+
+func TestRelayRequest(t *testing.T) {
+	r := testRelay()
+	w := &recordingWriter{}
+	discover, err := dhcpv4.NewDiscovery(testHwAddr)
+	require.NoError(t, err)
+	discover = received(t, discover)
+
+	r.handle(w, 2, testPeer, discover)
+	require.Len(t, w.written, 2)
+	for i, server := range testServers {
+		require.Nil(t, w.written[i].cm)
+		require.Equal(t, &net.UDPAddr{IP: server, Port: dhcpv4.ServerPort}, w.written[i].addr)
+		relayed, err := dhcpv4.FromBytes(w.written[i].data)
+		require.NoError(t, err)
+		require.True(t, relayed.GatewayIPAddr().Equal(r.Address))
+		require.Equal(t, uint8(1), relayed.HopCount())
+		// the relay agent information option is the last one
+		options := relayed.Options()
+		require.Equal(t, dhcpv4.OptionEnd, options[len(options)-1].Code())
+		require.Equal(t, r.AgentInfo.ToBytes(), options[len(options)-2].ToBytes())
+	}
+
+	// from another interface
+	w = &recordingWriter{}
+	r.handle(w, 3, testPeer, discover)
+	require.Empty(t, w.written)
+}
+
+func TestRelayRequestFromRelay(t *testing.T) {
+	r := testRelay()
+	w := &recordingWriter{}
+	discover, err := dhcpv4.NewDiscovery(testHwAddr)
+	require.NoError(t, err)
+	discover.SetGatewayIPAddr(net.IPv4(172, 16, 0, 1))
+	discover.SetHopCount(1)
+	discover = received(t, discover)
+
+	r.handle(w, 2, &net.UDPAddr{IP: net.IPv4(172, 16, 0, 1), Port: dhcpv4.ServerPort}, discover)
+	require.Len(t, w.written, 2)
+	relayed, err := dhcpv4.FromBytes(w.written[0].data)
+	require.NoError(t, err)
+	require.True(t, relayed.GatewayIPAddr().Equal(net.IPv4(172, 16, 0, 1)))
+	require.Equal(t, uint8(2), relayed.HopCount())
+	require.Nil(t, relayed.GetOneOption(dhcpv4.OptionRelayAgentInformation))
+}
+
+func TestRelayRequestDropped(t *testing.T) {
+	r := testRelay()
+	w := &recordingWriter{}
+
+	// relayed too many times
+	discover, err := dhcpv4.NewDiscovery(testHwAddr)
+	require.NoError(t, err)
+	discover.SetGatewayIPAddr(net.IPv4(172, 16, 0, 1))
+	discover.SetHopCount(5)
+	r.handle(w, 2, testPeer, received(t, discover))
+
+	// relay agent information option sent by a client
+	discover, err = dhcpv4.NewDiscovery(testHwAddr)
+	require.NoError(t, err)
+	discover.UpdateOption(r.AgentInfo)
+	r.handle(w, 2, testPeer, received(t, discover))
+
+	// dropped by the hook, which sees the final request
+	discover, err = dhcpv4.NewDiscovery(testHwAddr)
+	require.NoError(t, err)
+	r.RewriteRequest = func(request *dhcpv4.DHCPv4) bool {
+		require.True(t, request.GatewayIPAddr().Equal(r.Address))
+		require.NotNil(t, request.GetOneOption(dhcpv4.OptionRelayAgentInformation))
+		return false
+	}
+	r.handle(w, 2, testPeer, received(t, discover))
+	require.Empty(t, w.written)
+}
+
+func TestRelayReply(t *testing.T) {
+	r := testRelay()
+	server := &net.UDPAddr{IP: testServers[0], Port: dhcpv4.ServerPort}
+	w := &recordingWriter{}
+	r.RewriteReply = func(reply *dhcpv4.DHCPv4) bool {
+		reply.UpdateOption(&dhcpv4.OptDomainName{DomainName: "example.com"})
+		return true
+	}
+
+	// broadcast out of the client-facing interface
+	r.handle(w, 1, server, testReply(t, dhcpv4.MessageTypeOffer))
+	require.Len(t, w.written, 1)
+	require.Equal(t, &ipv4.ControlMessage{IfIndex: 2}, w.written[0].cm)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ClientPort}, w.written[0].addr)
+	offer, err := dhcpv4.FromBytes(w.written[0].data)
+	require.NoError(t, err)
+	require.Nil(t, offer.GetOneOption(dhcpv4.OptionRelayAgentInformation))
+	require.Equal(t, "example.com", offer.DomainName())
+
+	// unicast to the address of the client
+	w = &recordingWriter{}
+	r.handle(w, 1, server, testReply(t, dhcpv4.MessageTypeAck, withClientIP(net.IPv4(192, 168, 1, 100))))
+	require.Len(t, w.written, 1)
+	require.Nil(t, w.written[0].cm)
+	require.Equal(t, &net.UDPAddr{IP: net.IP{192, 168, 1, 100}, Port: dhcpv4.ClientPort}, w.written[0].addr)
+
+	// naks are broadcast
+	w = &recordingWriter{}
+	r.handle(w, 1, server, testReply(t, dhcpv4.MessageTypeNak, withClientIP(net.IPv4(192, 168, 1, 100))))
+	require.Len(t, w.written, 1)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ClientPort}, w.written[0].addr)
+
+	// sent to the hardware address of the client
+	w = &recordingWriter{}
+	unicast := &recordingReplier{}
+	r.Unicast = unicast
+	r.handle(w, 1, server, testReply(t, dhcpv4.MessageTypeOffer))
+	r.handle(w, 1, server, testReply(t, dhcpv4.MessageTypeOffer, withBroadcast))
+	require.Len(t, unicast.sent, 1)
+	require.Len(t, w.written, 1)
+
+	// not sent to this relay agent
+	w = &recordingWriter{}
+	reply := testReply(t, dhcpv4.MessageTypeOffer)
+	reply.SetGatewayIPAddr(net.IPv4(172, 16, 0, 1))
+	r.handle(w, 1, server, reply)
+	require.Empty(t, w.written)
+}
+
+func TestRelayPorts(t *testing.T) {
+	r := testRelay()
+	r.ServerPort, r.ClientPort = 6767, 6868
+	w := &recordingWriter{}
+	discover, err := dhcpv4.NewDiscovery(testHwAddr)
+	require.NoError(t, err)
+	r.handle(w, 2, testPeer, received(t, discover))
+	require.Len(t, w.written, 2)
+	require.Equal(t, &net.UDPAddr{IP: testServers[0], Port: 6767}, w.written[0].addr)
+
+	w = &recordingWriter{}
+	r.handle(w, 1, &net.UDPAddr{IP: testServers[0], Port: 6767}, testReply(t, dhcpv4.MessageTypeOffer))
+	require.Len(t, w.written, 1)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4bcast, Port: 6868}, w.written[0].addr)
+}
@@ -0,0 +1,100 @@
+package dhcpv4
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// RetransmissionStrategy decides how long a client waits for a reply before
+// retransmitting a message, and when it gives up.
+type RetransmissionStrategy interface {
+	// Timeout returns how long to wait for a reply after the given
+	// transmission, numbered from 0, and false if the message must not be
+	// transmitted that many times.
+	Timeout(attempt int) (time.Duration, bool)
+}
+
+// ExponentialBackoff is the retransmission strategy suggested by RFC 2131,
+// Section 4.1: the delay before the first retransmission is Initial, and it
+// doubles with each subsequent retransmission up to Max. Each delay is
+// randomized by a uniform amount between -Randomization and +Randomization.
+type ExponentialBackoff struct {
+	Initial       time.Duration
+	Max           time.Duration
+	Randomization time.Duration
+	// MaxAttempts is the maximum number of transmissions of a message,
+	// including the first one. Messages are always transmitted at least
+	// once.
+	MaxAttempts int
+}
+
+// NewExponentialBackoff returns the ExponentialBackoff strategy recommended by
+// RFC 2131: waiting 4, 8, 16, 32 and 64 seconds, give or take one second,
+// before giving up.
+func NewExponentialBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		Initial:       4 * time.Second,
+		Max:           64 * time.Second,
+		Randomization: time.Second,
+		MaxAttempts:   5,
+	}
+}
+
+// Timeout implements RetransmissionStrategy.Timeout.
+func (e *ExponentialBackoff) Timeout(attempt int) (time.Duration, bool) {
+	maxAttempts := e.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	if attempt < 0 || attempt >= maxAttempts {
+		return 0, false
+	}
+	d := e.Initial
+	for i := 0; i < attempt && d < e.Max; i++ {
+		d *= 2
+	}
+	if d > e.Max {
+		d = e.Max
+	}
+	if e.Randomization > 0 {
+		d += time.Duration(rand.Int63n(int64(2*e.Randomization)+1)) - e.Randomization
+	}
+	if d <= 0 {
+		d = time.Millisecond
+	}
+	return d, true
+}
+
+// broadcastSendReceive broadcasts packet and waits for a reply of one of the
+// given types, retransmitting it according to the client's retransmission
+// strategy.
+// Without a strategy, packet is sent once and the reply is waited for up to
+// the client's read timeout. The secs field of packet is refreshed from the
+// transaction before each transmission.
+func (c *Client) broadcastSendReceive(ctx context.Context, t *Transaction, sender broadcaster, conn net.Conn, packet *DHCPv4, messageTypes ...MessageType) (*DHCPv4, error) {
+	if c.Retransmission == nil {
+		packet.SetNumSeconds(t.Seconds())
+		return broadcastSendReceiveConn(ctx, sender, conn, packet, c.ReadTimeout, messageTypes...)
+	}
+	var err error
+	for attempt := 0; ; attempt++ {
+		timeout, ok := c.Retransmission.Timeout(attempt)
+		if !ok {
+			if attempt == 0 {
+				return nil, ErrNoAttempts
+			}
+			return nil, err
+		}
+		if attempt > 0 {
+			loggerOrDefault(c.Logger).Printf("No reply to transaction 0x%08x, retransmitting (attempt %d)", packet.TransactionID(), attempt+1)
+		}
+		packet.SetNumSeconds(t.Seconds())
+		var reply *DHCPv4
+		reply, err = broadcastSendReceiveConn(ctx, sender, conn, packet, timeout, messageTypes...)
+		if err != ErrTimeout {
+			return reply, err
+		}
+	}
+}
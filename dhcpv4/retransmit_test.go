@@ -0,0 +1,78 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponentialBackoffTimeout(t *testing.T) {
+	e := NewExponentialBackoff()
+	e.Randomization = 0
+	expected := []time.Duration{
+		4 * time.Second,
+		8 * time.Second,
+		16 * time.Second,
+		32 * time.Second,
+		64 * time.Second,
+	}
+	for attempt, want := range expected {
+		d, ok := e.Timeout(attempt)
+		require.True(t, ok)
+		require.Equal(t, want, d)
+	}
+	_, ok := e.Timeout(len(expected))
+	require.False(t, ok)
+	_, ok = e.Timeout(-1)
+	require.False(t, ok)
+}
+
+func TestExponentialBackoffTimeoutMax(t *testing.T) {
+	e := &ExponentialBackoff{
+		Initial:     time.Second,
+		Max:         3 * time.Second,
+		MaxAttempts: 4,
+	}
+	d, ok := e.Timeout(3)
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, d)
+}
+
+func TestExponentialBackoffTimeoutNoAttempts(t *testing.T) {
+	e := &ExponentialBackoff{Initial: time.Second, Max: time.Second}
+	_, ok := e.Timeout(0)
+	require.True(t, ok)
+	_, ok = e.Timeout(1)
+	require.False(t, ok)
+}
+
+type noAttempts struct{}
+
+func (noAttempts) Timeout(attempt int) (time.Duration, bool) {
+	return 0, false
+}
+
+func TestClientExchangeNoAttempts(t *testing.T) {
+	_, out := setUpLoopbackConns(t)
+	in, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	c := NewClient()
+	defer c.Close()
+	c.Retransmission = noAttempts{}
+	c.ifname, c.sender, c.recvConn = "eth0", loopbackBroadcaster{out}, in
+	discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	_, err = c.Exchange("eth0", discover)
+	require.Equal(t, ErrNoAttempts, err)
+}
+
+func TestExponentialBackoffTimeoutRandomization(t *testing.T) {
+	e := NewExponentialBackoff()
+	for i := 0; i < 100; i++ {
+		d, ok := e.Timeout(0)
+		require.True(t, ok)
+		require.True(t, d >= 3*time.Second && d <= 5*time.Second, d)
+	}
+}
@@ -0,0 +1,167 @@
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/uio"
+)
+
+// This module reconciles the options that configure the routes and the MTU of
+// the client, so that the consumers applying a lease do not need to.
+// https://tools.ietf.org/html/rfc2132#section-3.5
+// https://tools.ietf.org/html/rfc2132#section-5.8
+// https://tools.ietf.org/html/rfc3442
+
+// MinInterfaceMTU is the smallest MTU the interface MTU option can hold.
+const MinInterfaceMTU = 68
+
+// Route is a route to a destination network through a router.
+type Route struct {
+	Dest   *net.IPNet
+	Router net.IP
+}
+
+func (r Route) String() string {
+	return fmt.Sprintf("%v via %v", r.Dest, r.Router)
+}
+
+// Routes returns the routes given by the router, static route and classless
+// static route options, in this order of preference: the routers give default
+// routes, the others routes to the networks they list. As RFC 3442 mandates,
+// the router and static route options are ignored if the classless static
+// route option is present. Duplicate routes are removed, and the destinations
+// are masked.
+func (d *DHCPv4) Routes() ([]Route, error) {
+	var routes []Route
+	switch opt := d.classlessStaticRouteOption().(type) {
+	case *OptClasslessStaticRoute:
+		return dedupRoutes(opt.Routes), nil
+	case nil:
+	default:
+		// e.g. an OptionGeneric built by hand
+		classless, err := parseClasslessStaticRoutes(opt.ToBytes()[2:])
+		if err != nil {
+			return nil, err
+		}
+		return dedupRoutes(classless), nil
+	}
+	for _, router := range d.Router() {
+		routes = append(routes, Route{
+			Dest:   &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)},
+			Router: router.To4(),
+		})
+	}
+	if data, ok := genericOptionData(d.GetOneOption(OptionStaticRoutingTable)); ok {
+		static, err := parseStaticRoutes(data)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, static...)
+	}
+	return dedupRoutes(routes), nil
+}
+
+// InterfaceMTU returns the MTU from the interface MTU option, or 0 if it is
+// not present or smaller than MinInterfaceMTU.
+func (d *DHCPv4) InterfaceMTU() uint16 {
+	var mtu uint16
+	switch opt := d.GetOneOption(OptionInterfaceMTU).(type) {
+	case *OptInterfaceMTU:
+		mtu = opt.MTU
+	case nil:
+		return 0
+	default:
+		// e.g. an OptionGeneric built by hand
+		data := opt.ToBytes()[2:]
+		if len(data) != 2 {
+			return 0
+		}
+		mtu = binary.BigEndian.Uint16(data)
+	}
+	if mtu < MinInterfaceMTU {
+		return 0
+	}
+	return mtu
+}
+
+// genericOptionData returns the data of opt if it is an OptionGeneric.
+func genericOptionData(opt Option) ([]byte, bool) {
+	switch o := opt.(type) {
+	case *OptionGeneric:
+		return o.Data, true
+	case OptionGeneric:
+		return o.Data, true
+	}
+	return nil, false
+}
+
+// parseClasslessStaticRoutes parses the data of a classless static route
+// option, where each route is encoded as the prefix length of the
+// destination, its significant octets, and the router.
+func parseClasslessStaticRoutes(data []byte) ([]Route, error) {
+	var routes []Route
+	buf := uio.NewBigEndianBuffer(data)
+	for buf.Has(1) {
+		width := int(buf.Read8())
+		if width > 32 {
+			return nil, fmt.Errorf("invalid classless static route: prefix length %d", width)
+		}
+		dest := make(net.IP, 4)
+		copy(dest, buf.Consume((width+7)/8))
+		router := buf.CopyN(4)
+		if buf.Error() != nil {
+			return nil, ErrShortByteStream
+		}
+		mask := net.CIDRMask(width, 32)
+		routes = append(routes, Route{
+			Dest:   &net.IPNet{IP: dest.Mask(mask), Mask: mask},
+			Router: net.IP(router),
+		})
+	}
+	return routes, nil
+}
+
+// parseStaticRoutes parses the data of a static route option, made of pairs
+// of destination and router, the mask of the destination being the one of its
+// address class. The default route is not allowed in it, and is skipped.
+func parseStaticRoutes(data []byte) ([]Route, error) {
+	if len(data)%8 != 0 {
+		return nil, fmt.Errorf("invalid static routes length %d, not a multiple of 8", len(data))
+	}
+	var routes []Route
+	for i := 0; i < len(data); i += 8 {
+		dest := net.IP(append([]byte{}, data[i:i+4]...))
+		if dest.Equal(net.IPv4zero) {
+			continue
+		}
+		mask := dest.DefaultMask()
+		if mask == nil {
+			mask = net.CIDRMask(32, 32)
+		}
+		routes = append(routes, Route{
+			Dest:   &net.IPNet{IP: dest.Mask(mask), Mask: mask},
+			Router: net.IP(append([]byte{}, data[i+4:i+8]...)),
+		})
+	}
+	return routes, nil
+}
+
+// dedupRoutes removes the routes equal to a previous one.
+func dedupRoutes(routes []Route) []Route {
+	var ret []Route
+	for _, r := range routes {
+		dup := false
+		for _, other := range ret {
+			if r.Dest.String() == other.Dest.String() && r.Router.Equal(other.Router) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,90 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseCIDR(t *testing.T, s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	require.NoError(t, err)
+	return n
+}
+
+func TestRoutes(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	routes, err := d.Routes()
+	require.NoError(t, err)
+	require.Empty(t, routes)
+
+	d.AddOption(&OptRouter{Routers: []net.IP{net.IPv4(192, 168, 0, 1), net.IPv4(192, 168, 0, 1)}})
+	d.AddOption(&OptionGeneric{OptionCode: OptionStaticRoutingTable, Data: []byte{
+		10, 1, 2, 3, 192, 168, 0, 2, // class A
+		172, 16, 5, 0, 192, 168, 0, 3, // class B
+		0, 0, 0, 0, 192, 168, 0, 4, // default route, not allowed
+	}})
+	routes, err = d.Routes()
+	require.NoError(t, err)
+	require.Equal(t, []Route{
+		{Dest: mustParseCIDR(t, "0.0.0.0/0"), Router: net.IP{192, 168, 0, 1}},
+		{Dest: mustParseCIDR(t, "10.0.0.0/8"), Router: net.IP{192, 168, 0, 2}},
+		{Dest: mustParseCIDR(t, "172.16.0.0/16"), Router: net.IP{192, 168, 0, 3}},
+	}, routes)
+
+	// the classless static routes override the others
+	d.AddOption(&OptionGeneric{OptionCode: OptionClasslessStaticRouteOption, Data: []byte{
+		0, 192, 168, 0, 5,
+		24, 10, 0, 1, 192, 168, 0, 6,
+		32, 10, 0, 2, 3, 192, 168, 0, 7,
+	}})
+	routes, err = d.Routes()
+	require.NoError(t, err)
+	require.Equal(t, []Route{
+		{Dest: mustParseCIDR(t, "0.0.0.0/0"), Router: net.IP{192, 168, 0, 5}},
+		{Dest: mustParseCIDR(t, "10.0.1.0/24"), Router: net.IP{192, 168, 0, 6}},
+		{Dest: mustParseCIDR(t, "10.0.2.3/32"), Router: net.IP{192, 168, 0, 7}},
+	}, routes)
+	require.Equal(t, "10.0.1.0/24 via 192.168.0.6", routes[1].String())
+
+	// and so does the typed option
+	d.UpdateOption(&OptClasslessStaticRoute{Routes: []Route{
+		{Dest: mustParseCIDR(t, "10.0.3.0/24"), Router: net.IP{192, 168, 0, 8}},
+	}})
+	routes, err = d.Routes()
+	require.NoError(t, err)
+	require.Equal(t, []Route{
+		{Dest: mustParseCIDR(t, "10.0.3.0/24"), Router: net.IP{192, 168, 0, 8}},
+	}, routes)
+}
+
+func TestRoutesInvalid(t *testing.T) {
+	for _, opt := range []Option{
+		&OptionGeneric{OptionCode: OptionClasslessStaticRouteOption, Data: []byte{33, 10, 0, 0, 0, 0, 1, 1, 1, 1}},
+		&OptionGeneric{OptionCode: OptionClasslessStaticRouteOption, Data: []byte{24, 10, 0, 1, 192, 168}},
+		&OptionGeneric{OptionCode: OptionStaticRoutingTable, Data: []byte{10, 0, 0, 0, 192, 168, 0}},
+	} {
+		d, err := New()
+		require.NoError(t, err)
+		d.AddOption(opt)
+		_, err = d.Routes()
+		require.Error(t, err, opt.String())
+	}
+}
+
+func TestInterfaceMTU(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	require.Equal(t, uint16(0), d.InterfaceMTU())
+
+	d.AddOption(&OptionGeneric{OptionCode: OptionInterfaceMTU, Data: []byte{0x05, 0xdc}})
+	require.Equal(t, uint16(1500), d.InterfaceMTU())
+
+	d.SetOptions([]Option{&OptionGeneric{OptionCode: OptionInterfaceMTU, Data: []byte{0, 67}}})
+	require.Equal(t, uint16(0), d.InterfaceMTU())
+
+	d.SetOptions([]Option{&OptInterfaceMTU{MTU: 9000}})
+	require.Equal(t, uint16(9000), d.InterfaceMTU())
+}
@@ -0,0 +1,65 @@
+// +build linux
+
+package server4
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenFdsStart is the first file descriptor passed by systemd, as per
+// sd_listen_fds(3).
+const listenFdsStart = 3
+
+// ActivationConns returns the UDP sockets passed to the process by systemd
+// socket activation, as described by the LISTEN_PID and LISTEN_FDS
+// environment variables. It returns no connection and no error if the process
+// was not socket-activated. The environment variables are unset, so that they
+// are not inherited by child processes.
+func ActivationConns() ([]net.PacketConn, error) {
+	pid, nfds := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	return activationConns(pid, nfds, listenFdsStart)
+}
+
+// activationConns wraps the nfds file descriptors starting at start into
+// packet connections, if pid is the PID of the current process.
+func activationConns(pid, nfds string, start int) ([]net.PacketConn, error) {
+	if pid == "" || nfds == "" {
+		return nil, nil
+	}
+	p, err := strconv.Atoi(pid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_PID %q: %v", pid, err)
+	}
+	if p != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(nfds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", nfds)
+	}
+	conns := make([]net.PacketConn, 0, n)
+	for fd := start; fd < start+n; fd++ {
+		unix.CloseOnExec(fd)
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		conn, err := net.FilePacketConn(f)
+		// FilePacketConn duplicates the descriptor, the original is not
+		// needed anymore.
+		f.Close()
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, fmt.Errorf("file descriptor %d is not a packet socket: %v", fd, err)
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
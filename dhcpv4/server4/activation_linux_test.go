@@ -0,0 +1,59 @@
+// +build linux
+
+package server4
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivationConnsNotActivated(t *testing.T) {
+	conns, err := activationConns("", "", listenFdsStart)
+	require.NoError(t, err)
+	require.Empty(t, conns)
+
+	// the variables are meant for another process
+	conns, err = activationConns(strconv.Itoa(os.Getpid()+1), "1", listenFdsStart)
+	require.NoError(t, err)
+	require.Empty(t, conns)
+}
+
+func TestActivationConnsInvalid(t *testing.T) {
+	_, err := activationConns("abc", "1", listenFdsStart)
+	require.Error(t, err)
+	_, err = activationConns(strconv.Itoa(os.Getpid()), "abc", listenFdsStart)
+	require.Error(t, err)
+}
+
+func TestActivationConns(t *testing.T) {
+	udp, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer udp.Close()
+	// activationConns takes ownership of the duplicated descriptor
+	f, err := udp.File()
+	require.NoError(t, err)
+
+	conns, err := activationConns(strconv.Itoa(os.Getpid()), "1", int(f.Fd()))
+	require.NoError(t, err)
+	require.Len(t, conns, 1)
+	require.Equal(t, udp.LocalAddr().String(), conns[0].LocalAddr().String())
+
+	s := NewServerWithConn(conns[0], nil)
+	require.Equal(t, udp.LocalAddr().String(), s.LocalAddr().String())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ActivateAndServe()
+	}()
+	require.NoError(t, s.Close())
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
@@ -0,0 +1,338 @@
+package server4
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+)
+
+// ErrNoAddress is returned by an Allocator that has no address to give to a
+// client.
+var ErrNoAddress = errors.New("no address available")
+
+// AllocationRequest describes the client an Allocator picks an address for.
+type AllocationRequest struct {
+	HWAddr net.HardwareAddr
+	// ClientID is the client identifier option of the client, if any.
+	ClientID []byte
+	// Hint is the address the client asks for, or nil. Allocators should
+	// honour it if they can.
+	Hint net.IP
+	// Subnet is an address of the subnet the address must belong to, as
+	// returned by DHCPv4.SelectedSubnet, or nil for the subnet of the
+	// server.
+	Subnet net.IP
+	// HostName is the host name option of the client, if any, which IPAM
+	// systems commonly record along with the address.
+	HostName string
+}
+
+// NewAllocationRequest returns the AllocationRequest of a Discover or a
+// Request.
+func NewAllocationRequest(m *dhcpv4.DHCPv4) AllocationRequest {
+	req := AllocationRequest{
+		HWAddr:   m.ClientHwAddr(),
+		Hint:     m.RequestedIPAddress(),
+		Subnet:   m.SelectedSubnet(),
+		HostName: m.HostName(),
+	}
+	if req.Hint == nil && m.ClientIPAddr() != nil && !m.ClientIPAddr().IsUnspecified() {
+		req.Hint = m.ClientIPAddr()
+	}
+	if opt := m.GetOneOption(dhcpv4.OptionClientIdentifier); opt != nil {
+		req.ClientID = opt.ToBytes()[2:]
+	}
+	return req
+}
+
+// Allocator picks the addresses of the clients. The server uses its pools by
+// default, see PoolAllocator, but an Allocator can get the addresses from an
+// external IPAM system, such as NetBox or Infoblox, through its API. The
+// calls may block, up to the deadline of the context. Implementations must be
+// safe for concurrent use.
+type Allocator interface {
+	// Allocate returns the address of a client, the same one every time if
+	// possible, or ErrNoAddress if there is none to give.
+	Allocate(ctx context.Context, req AllocationRequest) (net.IP, error)
+	// Release tells that the client no longer uses the address, e.g.
+	// because it released or declined it, or its lease expired.
+	Release(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error
+}
+
+// Renewer is implemented by the Allocators that are told when a client renews
+// the lease of its address, e.g. to extend the reservation of the address in
+// an IPAM system.
+type Renewer interface {
+	// Renew tells that the client keeps using the address. It fails if the
+	// address is not allocated to the client.
+	Renew(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error
+}
+
+// Allocate returns the address reserved for a client in the Reservations of
+// the server, if any, or an address from the Allocator of the server, or from
+// its pools if it has none.
+func (s *Server) Allocate(ctx context.Context, req AllocationRequest) (net.IP, error) {
+	if r := s.Reservations.Match(req); r != nil && r.IP != nil {
+		return r.IP, nil
+	}
+	return s.allocator().Allocate(ctx, req)
+}
+
+// ReleaseAllocation releases the address of a client in the Allocator of the
+// server, or in its pools if it has none.
+func (s *Server) ReleaseAllocation(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	return s.allocator().Release(ctx, hwaddr, ip)
+}
+
+// RenewAllocation renews the address of a client in the Allocator of the
+// server, if it implements Renewer. The pools of the server need no renewal.
+func (s *Server) RenewAllocation(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	if r, ok := s.allocator().(Renewer); ok {
+		return r.Renew(ctx, hwaddr, ip)
+	}
+	return nil
+}
+
+func (s *Server) allocator() Allocator {
+	if s.Allocator != nil {
+		return s.Allocator
+	}
+	return &PoolAllocator{Server: s}
+}
+
+// PoolAllocator allocates the addresses from the pools of a server, skipping
+// those that are bound to another client in its Leases or abandoned. It keeps
+// the address of a client as long as it can, then tries its hint. It skips
+// the addresses reserved for other clients, and the ones that the PingCheck of
+// the server finds in use, and ignores the subnet of the requests. Allocating
+// does not bind the address: two clients can be offered the same one, and the
+// second one to request it is refused by LeaseStore.Bind.
+type PoolAllocator struct {
+	Server *Server
+}
+
+// Allocate implements Allocator.Allocate.
+func (a *PoolAllocator) Allocate(ctx context.Context, req AllocationRequest) (net.IP, error) {
+	now := time.Now()
+	pools := a.Server.Pools()
+	if b := a.Server.Leases.Lookup(req.HWAddr); b != nil && a.available(pools, b.IP, req, now) {
+		return b.IP, nil
+	}
+	if req.Hint != nil && a.available(pools, req.Hint, req, now) {
+		inUse, err := a.inUse(ctx, req.Hint, req)
+		if err != nil {
+			return nil, err
+		}
+		if !inUse {
+			return req.Hint.To4(), nil
+		}
+	}
+	for _, p := range pools {
+		for k := ipKey(p.Start); k <= ipKey(p.End); k++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			ip := keyToIP(k)
+			if a.available(nil, ip, req, now) {
+				inUse, err := a.inUse(ctx, ip, req)
+				if err != nil {
+					return nil, err
+				}
+				if !inUse {
+					return ip, nil
+				}
+			}
+			if k == ^uint32(0) {
+				break
+			}
+		}
+	}
+	return nil, ErrNoAddress
+}
+
+// available returns true if ip belongs to one of the pools, unless pools is
+// nil, and can be given to the client of req at the given time.
+func (a *PoolAllocator) available(pools []*Pool, ip net.IP, req AllocationRequest, now time.Time) bool {
+	if pools != nil {
+		in := false
+		for _, p := range pools {
+			if p.Contains(ip) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+	if a.Server.IsAbandoned(ip, now) || a.Server.Reservations.Reserved(ip, req) {
+		return false
+	}
+	b := a.Server.Leases.LookupIP(ip)
+	return b == nil || b.Expired(now) || string(b.HWAddr) == string(req.HWAddr)
+}
+
+// inUse checks ip with the PingCheck of the server, if any, unless it is
+// already bound to the client of req, and returns true if it is in use. The
+// addresses in use are abandoned.
+func (a *PoolAllocator) inUse(ctx context.Context, ip net.IP, req AllocationRequest) (bool, error) {
+	if a.Server.PingCheck == nil {
+		return false, nil
+	}
+	if b := a.Server.Leases.LookupIP(ip); b != nil && string(b.HWAddr) == string(req.HWAddr) {
+		return false, nil
+	}
+	err := a.Server.abandonConflict(ip, a.Server.PingCheck(ctx, ip))
+	if acd.IsConflict(err) {
+		return true, nil
+	}
+	return false, err
+}
+
+// Release implements Allocator.Release. It removes the binding of the client
+// if it is for ip.
+func (a *PoolAllocator) Release(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	if b := a.Server.Leases.Lookup(hwaddr); b != nil && b.IP.Equal(ip) {
+		a.Server.Leases.Release(hwaddr)
+	}
+	return nil
+}
+
+// DefaultAllocationCacheTTL is the default time during which a CachingAllocator
+// reuses the address an Allocator gave to a client.
+const DefaultAllocationCacheTTL = 5 * time.Minute
+
+type cachedAllocation struct {
+	ip      net.IP
+	expires time.Time
+}
+
+// CachingAllocator wraps the Allocator of an external IPAM system, so that it
+// is not queried for every message and its failures do not stop the server.
+// The address given to a client is reused for TTL. When Backend fails, or
+// does not answer within Timeout, the last address of the client is reused
+// even if it is older than TTL, otherwise Fallback, if not nil, allocates
+// one, e.g. a PoolAllocator on a reserved range.
+type CachingAllocator struct {
+	Backend  Allocator
+	Fallback Allocator
+	TTL      time.Duration
+	// Timeout bounds the calls to Backend, if not zero.
+	Timeout time.Duration
+	// Logger, if not nil, is where the failures of Backend are reported.
+	// dhcpv4.DefaultLogger is used otherwise.
+	Logger dhcpv4.Logger
+
+	lock    sync.Mutex
+	entries map[string]cachedAllocation
+}
+
+// NewCachingAllocator returns a CachingAllocator wrapping backend, with the
+// DefaultAllocationCacheTTL and no fallback.
+func NewCachingAllocator(backend Allocator) *CachingAllocator {
+	return &CachingAllocator{Backend: backend, TTL: DefaultAllocationCacheTTL}
+}
+
+func (a *CachingAllocator) logger() dhcpv4.Logger {
+	if a.Logger == nil {
+		return dhcpv4.DefaultLogger
+	}
+	return a.Logger
+}
+
+func (a *CachingAllocator) backendContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.Timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, a.Timeout)
+}
+
+// Allocate implements Allocator.Allocate.
+func (a *CachingAllocator) Allocate(ctx context.Context, req AllocationRequest) (net.IP, error) {
+	key := string(req.HWAddr)
+	now := time.Now()
+	a.lock.Lock()
+	cached, ok := a.entries[key]
+	a.lock.Unlock()
+	if ok && now.Before(cached.expires) && (req.Hint == nil || req.Hint.Equal(cached.ip)) {
+		return cached.ip, nil
+	}
+
+	bctx, cancel := a.backendContext(ctx)
+	ip, err := a.Backend.Allocate(bctx, req)
+	cancel()
+	switch {
+	case err == nil:
+		a.lock.Lock()
+		if a.entries == nil {
+			a.entries = make(map[string]cachedAllocation)
+		}
+		a.entries[key] = cachedAllocation{ip: ip, expires: now.Add(a.TTL)}
+		a.lock.Unlock()
+		return ip, nil
+	case err == ErrNoAddress || ctx.Err() != nil:
+		// an answer of the backend, or the caller gave up
+		return nil, err
+	}
+	if ok {
+		a.logger().Printf("Allocator failed, reusing %v for %v: %v", cached.ip, req.HWAddr, err)
+		return cached.ip, nil
+	}
+	if a.Fallback != nil {
+		a.logger().Printf("Allocator failed, using the fallback for %v: %v", req.HWAddr, err)
+		return a.Fallback.Allocate(ctx, req)
+	}
+	return nil, err
+}
+
+// Release implements Allocator.Release. The address is released in Backend
+// and in Fallback, if not nil, since it may come from either, and forgotten
+// even if they fail.
+func (a *CachingAllocator) Release(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	a.lock.Lock()
+	if cached, ok := a.entries[string(hwaddr)]; ok && cached.ip.Equal(ip) {
+		delete(a.entries, string(hwaddr))
+	}
+	a.lock.Unlock()
+	bctx, cancel := a.backendContext(ctx)
+	err := a.Backend.Release(bctx, hwaddr, ip)
+	cancel()
+	if a.Fallback != nil {
+		if ferr := a.Fallback.Release(ctx, hwaddr, ip); err == nil {
+			err = ferr
+		}
+	}
+	return err
+}
+
+// Renew implements Renewer.Renew. The address is renewed in Backend, if it
+// implements Renewer, and cached for TTL again.
+func (a *CachingAllocator) Renew(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	if r, ok := a.Backend.(Renewer); ok {
+		bctx, cancel := a.backendContext(ctx)
+		err := r.Renew(bctx, hwaddr, ip)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.entries == nil {
+		a.entries = make(map[string]cachedAllocation)
+	}
+	a.entries[string(hwaddr)] = cachedAllocation{ip: ip, expires: time.Now().Add(a.TTL)}
+	return nil
+}
+
+// Len returns the number of cached addresses, expired ones included.
+func (a *CachingAllocator) Len() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return len(a.entries)
+}
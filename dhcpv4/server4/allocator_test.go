@@ -0,0 +1,205 @@
+package server4
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	allocHwAddr1 = net.HardwareAddr{0, 1, 2, 3, 4, 1}
+	allocHwAddr2 = net.HardwareAddr{0, 1, 2, 3, 4, 2}
+)
+
+func newAllocatorTestServer(t *testing.T) *Server {
+	s := NewServer(net.UDPAddr{}, nil)
+	p, err := NewPool(net.IPv4(10, 0, 0, 10), net.IPv4(10, 0, 0, 12))
+	require.NoError(t, err)
+	require.NoError(t, s.AddPool(p))
+	return s
+}
+
+func TestNewAllocationRequest(t *testing.T) {
+	m, err := dhcpv4.NewDiscovery(allocHwAddr1)
+	require.NoError(t, err)
+	m = dhcpv4.WithOption(&dhcpv4.OptRequestedIPAddress{RequestedAddr: net.IPv4(10, 0, 0, 11)})(m)
+	m = dhcpv4.WithOption(&dhcpv4.OptHostName{HostName: "host1"})(m)
+	m = dhcpv4.WithRelay(net.IPv4(10, 1, 0, 1))(m)
+	req := NewAllocationRequest(m)
+	require.Equal(t, allocHwAddr1, req.HWAddr)
+	require.True(t, req.Hint.Equal(net.IPv4(10, 0, 0, 11)))
+	require.True(t, req.Subnet.Equal(net.IPv4(10, 1, 0, 1)))
+	require.Equal(t, "host1", req.HostName)
+	require.Nil(t, req.ClientID)
+
+	// a renewing client asks for its current address
+	m, err = dhcpv4.New()
+	require.NoError(t, err)
+	m.SetClientIPAddr(net.IPv4(10, 0, 0, 12))
+	require.True(t, NewAllocationRequest(m).Hint.Equal(net.IPv4(10, 0, 0, 12)))
+}
+
+func TestPoolAllocator(t *testing.T) {
+	s := newAllocatorTestServer(t)
+	ctx := context.Background()
+
+	ip, err := s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: allocHwAddr1, IP: ip, Expires: time.Now().Add(time.Hour)}))
+
+	// the hint is honoured, unless it is bound to another client or outside
+	// of the pools
+	ip, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2, Hint: net.IPv4(10, 0, 0, 12)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 12}, ip)
+	ip, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2, Hint: net.IPv4(10, 0, 0, 10)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 11}, ip)
+	ip, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2, Hint: net.IPv4(10, 0, 0, 20)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 11}, ip)
+
+	// a bound client keeps its address
+	ip, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1, Hint: net.IPv4(10, 0, 0, 12)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+
+	// abandoned addresses are skipped
+	s.Abandon(net.IPv4(10, 0, 0, 11), time.Now().Add(time.Hour))
+	s.Abandon(net.IPv4(10, 0, 0, 12), time.Now().Add(time.Hour))
+	_, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2})
+	require.Equal(t, ErrNoAddress, err)
+
+	require.NoError(t, s.ReleaseAllocation(ctx, allocHwAddr1, net.IPv4(10, 0, 0, 10)))
+	require.Nil(t, s.Leases.Lookup(allocHwAddr1))
+	ip, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+}
+
+// fakeIPAM is an Allocator counting its calls, which fails while err is set.
+type fakeIPAM struct {
+	lock     sync.Mutex
+	err      error
+	next     byte
+	calls    int
+	released []net.IP
+}
+
+func (f *fakeIPAM) Allocate(ctx context.Context, req AllocationRequest) (net.IP, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.next++
+	return net.IP{192, 0, 2, f.next}, nil
+}
+
+func (f *fakeIPAM) Release(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.released = append(f.released, ip)
+	return f.err
+}
+
+func TestCachingAllocator(t *testing.T) {
+	backend := &fakeIPAM{}
+	a := NewCachingAllocator(backend)
+	ctx := context.Background()
+	req := AllocationRequest{HWAddr: allocHwAddr1}
+
+	ip, err := a.Allocate(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, net.IP{192, 0, 2, 1}, ip)
+	ip, err = a.Allocate(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, net.IP{192, 0, 2, 1}, ip)
+	require.Equal(t, 1, backend.calls, "cached")
+	require.Equal(t, 1, a.Len())
+
+	// a different hint goes to the backend, whose answer is cached for no
+	// time this time
+	a.TTL = 0
+	_, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1, Hint: net.IPv4(192, 0, 2, 9)})
+	require.NoError(t, err)
+	require.Equal(t, 2, backend.calls)
+
+	// the backend fails: the last address is reused even once expired
+	backend.err = errors.New("IPAM unreachable")
+	ip, err = a.Allocate(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, net.IP{192, 0, 2, 2}, ip)
+	_, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2})
+	require.Equal(t, backend.err, err)
+
+	// ErrNoAddress is an answer, not a failure
+	backend.err = ErrNoAddress
+	_, err = a.Allocate(ctx, req)
+	require.Equal(t, ErrNoAddress, err)
+
+	backend.err = nil
+	require.NoError(t, a.Release(ctx, allocHwAddr1, net.IPv4(192, 0, 2, 2)))
+	require.Equal(t, 0, a.Len())
+	require.Equal(t, []net.IP{net.IPv4(192, 0, 2, 2)}, backend.released)
+}
+
+func TestCachingAllocatorFallback(t *testing.T) {
+	s := newAllocatorTestServer(t)
+	backend := &fakeIPAM{err: errors.New("IPAM unreachable")}
+	a := NewCachingAllocator(backend)
+	a.Fallback = &PoolAllocator{Server: s}
+	s.Allocator = a
+
+	ip, err := s.Allocate(context.Background(), AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+	require.Equal(t, 0, a.Len(), "fallback addresses are not cached")
+}
+
+// slowIPAM blocks until the context is done.
+type slowIPAM struct{}
+
+func (slowIPAM) Allocate(ctx context.Context, req AllocationRequest) (net.IP, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowIPAM) Release(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCachingAllocatorTimeout(t *testing.T) {
+	a := NewCachingAllocator(slowIPAM{})
+	a.Timeout = 10 * time.Millisecond
+	_, err := a.Allocate(context.Background(), AllocationRequest{HWAddr: allocHwAddr1})
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, context.DeadlineExceeded, a.Release(context.Background(), allocHwAddr1, net.IPv4(192, 0, 2, 1)))
+}
+
+func TestRenewAllocation(t *testing.T) {
+	ctx := context.Background()
+	// the pools of the server need no renewal
+	s := newAllocatorTestServer(t)
+	require.NoError(t, s.RenewAllocation(ctx, allocHwAddr1, net.IPv4(10, 0, 0, 10)))
+
+	p, err := NewPool(net.IPv4(10, 0, 0, 10), net.IPv4(10, 0, 0, 12))
+	require.NoError(t, err)
+	ra := NewRangeAllocator(p)
+	a := NewCachingAllocator(ra)
+	s.Allocator = a
+	ip, err := s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.NoError(t, s.RenewAllocation(ctx, allocHwAddr1, ip))
+	require.Equal(t, ErrNotAllocated, s.RenewAllocation(ctx, allocHwAddr2, ip))
+	require.Equal(t, 1, a.Len())
+}
@@ -0,0 +1,93 @@
+// +build linux
+
+package server4
+
+import (
+	"net"
+	"unsafe"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"golang.org/x/sys/unix"
+)
+
+// atfCom marks an ARP entry as complete. Without ATF_PERM, the entry is
+// temporary and expires like a learned one.
+const atfCom = 0x02
+
+// arpreq is the argument of the SIOCSARP ioctl, struct arpreq in
+// <net/if_arp.h>.
+type arpreq struct {
+	pa       unix.RawSockaddrInet4
+	haFamily uint16
+	haData   [14]byte
+	flags    int32
+	netmask  [16]byte
+	dev      [unix.IFNAMSIZ]byte
+}
+
+// ARPReplier sends the replies through the server socket after installing a
+// temporary ARP entry mapping the offered address to the client hardware
+// address, as ISC dhcpd does. Unlike a RawReplier, the replies go through
+// the IP stack, e.g. through its firewall rules. See UnicastToHwAddr for when
+// to use it. Installing ARP entries requires CAP_NET_ADMIN. ARPReplier
+// implements UnicastReplier.
+type ARPReplier struct {
+	// ClientPort, if not zero, is the port to which the replies are sent,
+	// instead of dhcpv4.ClientPort.
+	ClientPort int
+
+	conn   net.PacketConn
+	ifname string
+	fd     int
+}
+
+// NewARPReplier returns an ARPReplier sending the replies through conn, the
+// server socket, to the clients attached to the given interface.
+func NewARPReplier(conn net.PacketConn, ifname string) (*ARPReplier, error) {
+	if len(ifname) >= unix.IFNAMSIZ {
+		return nil, unix.EINVAL
+	}
+	// any socket will do for the ioctl
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &ARPReplier{conn: conn, ifname: ifname, fd: fd}, nil
+}
+
+// Send installs the ARP entry of the client, then sends reply to the client
+// port of the offered address (yiaddr). The source address is the one of the
+// server socket, so src is ignored.
+func (r *ARPReplier) Send(reply *dhcpv4.DHCPv4, src net.IP) error {
+	yiaddr, chaddr, err := unicastDestination(reply)
+	if err != nil {
+		return err
+	}
+	if err := r.setARPEntry(yiaddr, chaddr); err != nil {
+		return err
+	}
+	_, port := replyPorts(0, r.ClientPort)
+	_, err = r.conn.WriteTo(reply.ToBytes(), &net.UDPAddr{IP: yiaddr, Port: port})
+	return err
+}
+
+// setARPEntry installs a temporary ARP entry mapping ip to hwaddr.
+func (r *ARPReplier) setARPEntry(ip net.IP, hwaddr net.HardwareAddr) error {
+	var req arpreq
+	req.pa.Family = unix.AF_INET
+	copy(req.pa.Addr[:], ip.To4())
+	req.haFamily = unix.ARPHRD_ETHER
+	copy(req.haData[:], hwaddr)
+	req.flags = atfCom
+	copy(req.dev[:], r.ifname)
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(r.fd), unix.SIOCSARP, uintptr(unsafe.Pointer(&req))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Close closes the socket used to install the ARP entries. The server socket
+// is left open.
+func (r *ARPReplier) Close() error {
+	return unix.Close(r.fd)
+}
@@ -0,0 +1,15 @@
+// +build linux
+
+package server4
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestARPReqSize(t *testing.T) {
+	// sizeof(struct arpreq)
+	require.Equal(t, uintptr(68), unsafe.Sizeof(arpreq{}))
+}
@@ -0,0 +1,68 @@
+package server4
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// DefaultDuplicateWindow is the default window of DropDuplicates, shorter than
+// the first retransmission interval of the clients, 4 seconds give or take
+// one as per RFC 2131, section 4.1.
+const DefaultDuplicateWindow = 2 * time.Second
+
+// duplicateKey identifies the copies of a message.
+type duplicateKey struct {
+	offerKey
+	mt dhcpv4.MessageType
+}
+
+// DropDuplicates returns a middleware that drops the messages with the same
+// type, transaction ID and client hardware address as a message passed to the
+// handler less than window ago. These are the copies of a message received
+// through several paths, e.g. broadcast on the link and forwarded by a relay
+// agent, or by redundant relay agents, which would otherwise be handled, and
+// answered, twice, possibly at the same time by different workers. The
+// window should be shorter than the retransmission interval of the clients,
+// so that the retransmissions of a message whose reply was lost are answered.
+func DropDuplicates(window time.Duration) Middleware {
+	var (
+		lock   sync.Mutex
+		seen   = make(map[duplicateKey]time.Time)
+		pruned time.Time
+	)
+	// first returns true if m is the first copy of a message, and records it
+	first := func(m *dhcpv4.DHCPv4, now time.Time) bool {
+		var mt dhcpv4.MessageType
+		if t := m.MessageType(); t != nil {
+			mt = *t
+		}
+		k := duplicateKey{offerKey: exchangeKey(m), mt: mt}
+		lock.Lock()
+		defer lock.Unlock()
+		if now.Sub(pruned) >= window {
+			for k, t := range seen {
+				if now.Sub(t) >= window {
+					delete(seen, k)
+				}
+			}
+			pruned = now
+		}
+		if t, ok := seen[k]; ok && now.Sub(t) < window {
+			return false
+		}
+		seen[k] = now
+		return true
+	}
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			if !first(m, time.Now()) {
+				rc.logger().Printf("Dropping message from %v: duplicate of xid %#x", peer, m.TransactionID())
+				return
+			}
+			next(conn, peer, m, rc)
+		}
+	}
+}
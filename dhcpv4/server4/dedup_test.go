@@ -0,0 +1,35 @@
+package server4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropDuplicates(t *testing.T) {
+	var count int
+	h := DropDuplicates(100 * time.Millisecond)(countingHandler(&count))
+	discover, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	request := discover.Clone()
+	request.UpdateOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeRequest})
+	other, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 7})
+	require.NoError(t, err)
+	other.SetTransactionID(discover.TransactionID())
+
+	h(nil, nil, discover, nil)
+	h(nil, nil, discover, nil)
+	require.Equal(t, 1, count)
+	// same exchange, different type, or different client
+	h(nil, nil, request, nil)
+	h(nil, nil, other, nil)
+	require.Equal(t, 3, count)
+
+	// a retransmission after the window
+	time.Sleep(100 * time.Millisecond)
+	h(nil, nil, discover, nil)
+	require.Equal(t, 4, count)
+}
@@ -0,0 +1,8 @@
+/*
+The server4 package provides the building blocks of a DHCPv4 server: the
+server loop, the lease store keeping track of the addresses handed out to the
+clients, the allocators picking these addresses from the pools of the server or
+from an external IPAM system, and the related helpers.
+*/
+
+package server4
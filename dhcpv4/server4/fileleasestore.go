@@ -0,0 +1,153 @@
+package server4
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// fileLeasesVersion is the version of the files written by FileLeaseStore.
+const fileLeasesVersion = 1
+
+type fileLeases struct {
+	Version int               `json:"version"`
+	Leases  []snapshotBinding `json:"leases"`
+}
+
+// FileLeaseStore is a LeaseStore persisted to a JSON file, so that the leases
+// of a server survive its restarts. The bindings are kept in a
+// MemoryLeaseStore, and every change rewrites the whole file, atomically,
+// which suits servers with up to a few thousand leases.
+type FileLeaseStore struct {
+	// Logger, if not nil, is where the failures to persist the releases are
+	// reported. dhcpv4.DefaultLogger is used otherwise.
+	Logger dhcpv4.Logger
+
+	path string
+	// lock serializes the changes, so that the file is written in the
+	// order of the changes
+	lock sync.Mutex
+	mem  *MemoryLeaseStore
+}
+
+// OpenFileLeaseStore returns a FileLeaseStore persisted to the file at path,
+// loading the bindings it holds. The file is created on the first change if it
+// does not exist.
+func OpenFileLeaseStore(path string) (*FileLeaseStore, error) {
+	s := FileLeaseStore{path: path, mem: NewMemoryLeaseStore(0)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var leases fileLeases
+	if err := json.Unmarshal(data, &leases); err != nil {
+		return nil, fmt.Errorf("cannot load leases from %s: %v", path, err)
+	}
+	if leases.Version != fileLeasesVersion {
+		return nil, fmt.Errorf("unsupported leases file version %d", leases.Version)
+	}
+	if s.mem, err = loadBindings(leases.Leases); err != nil {
+		return nil, fmt.Errorf("cannot load leases from %s: %v", path, err)
+	}
+	return &s, nil
+}
+
+// Lookup implements LeaseStore.Lookup.
+func (s *FileLeaseStore) Lookup(hwaddr net.HardwareAddr) *Binding {
+	return s.mem.Lookup(hwaddr)
+}
+
+// LookupIP implements LeaseStore.LookupIP.
+func (s *FileLeaseStore) LookupIP(ip net.IP) *Binding {
+	return s.mem.LookupIP(ip)
+}
+
+// Bind implements LeaseStore.Bind. If the file cannot be written, the
+// binding is undone and the error is returned, so that the server does not
+// hand out a lease it would forget.
+func (s *FileLeaseStore) Bind(b Binding) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	old := s.mem.Lookup(b.HWAddr)
+	if err := s.mem.Bind(b); err != nil {
+		return err
+	}
+	if err := s.save(); err != nil {
+		if old != nil {
+			s.mem.Bind(*old)
+		} else {
+			s.mem.Release(b.HWAddr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Release implements LeaseStore.Release. The failures to write the file are
+// only reported, the binding being released anyway.
+func (s *FileLeaseStore) Release(hwaddr net.HardwareAddr) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.mem.Lookup(hwaddr) == nil {
+		return
+	}
+	s.mem.Release(hwaddr)
+	if err := s.save(); err != nil {
+		s.logger().Printf("Cannot persist the release of %v: %v", hwaddr, err)
+	}
+}
+
+// Range implements LeaseStore.Range, with the same restrictions as
+// MemoryLeaseStore.Range.
+func (s *FileLeaseStore) Range(f func(b Binding) bool) {
+	s.mem.Range(f)
+}
+
+// Len implements LeaseStore.Len.
+func (s *FileLeaseStore) Len() int {
+	return s.mem.Len()
+}
+
+// save writes the bindings to a temporary file that replaces the file of the
+// store, so that a crash never leaves it half written. It must be called with
+// the lock held.
+func (s *FileLeaseStore) save() error {
+	data, err := json.Marshal(&fileLeases{Version: fileLeasesVersion, Leases: snapshotBindings(s.mem)})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (s *FileLeaseStore) logger() dhcpv4.Logger {
+	if s.Logger == nil {
+		return dhcpv4.DefaultLogger
+	}
+	return s.Logger
+}
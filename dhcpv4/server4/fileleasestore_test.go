@@ -0,0 +1,94 @@
+package server4
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLeaseStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "leases")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "leases.json")
+
+	s, err := OpenFileLeaseStore(path)
+	require.NoError(t, err)
+	require.Equal(t, 0, s.Len())
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, s.Bind(Binding{HWAddr: allocHwAddr1, IP: net.IPv4(10, 0, 0, 10), Expires: expires}))
+	require.NoError(t, s.Bind(Binding{HWAddr: allocHwAddr2, IP: net.IPv4(10, 0, 0, 11), Expires: expires}))
+	require.Equal(t, ErrAddressInUse, s.Bind(Binding{HWAddr: allocHwAddr2, IP: net.IPv4(10, 0, 0, 10), Expires: expires}))
+	s.Release(allocHwAddr2)
+
+	// the leases survive a restart
+	s, err = OpenFileLeaseStore(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, s.Len())
+	b := s.LookupIP(net.IPv4(10, 0, 0, 10))
+	require.NotNil(t, b)
+	require.Equal(t, allocHwAddr1, b.HWAddr)
+	require.True(t, expires.Equal(b.Expires))
+	require.Nil(t, s.Lookup(allocHwAddr2))
+
+	// no temporary file is left behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestFileLeaseStoreReopenHwAddrLengths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "leases")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "leases.json")
+
+	s, err := OpenFileLeaseStore(path)
+	require.NoError(t, err)
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, s.Bind(Binding{HWAddr: net.HardwareAddr{}, IP: net.IPv4(10, 0, 0, 10), Expires: expires}))
+	require.NoError(t, s.Bind(Binding{HWAddr: net.HardwareAddr{1, 2, 3}, IP: net.IPv4(10, 0, 0, 11), Expires: expires}))
+
+	s, err = OpenFileLeaseStore(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, s.Len())
+	b := s.LookupIP(net.IPv4(10, 0, 0, 10))
+	require.NotNil(t, b)
+	require.Len(t, b.HWAddr, 0)
+	b = s.Lookup(net.HardwareAddr{1, 2, 3})
+	require.NotNil(t, b)
+	require.Equal(t, net.IP{10, 0, 0, 11}, b.IP)
+}
+
+func TestFileLeaseStoreBindFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "leases")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := OpenFileLeaseStore(filepath.Join(dir, "missing", "leases.json"))
+	require.NoError(t, err)
+	require.Error(t, s.Bind(Binding{HWAddr: allocHwAddr1, IP: net.IPv4(10, 0, 0, 10), Expires: time.Now().Add(time.Hour)}))
+	require.Equal(t, 0, s.Len())
+}
+
+func TestOpenFileLeaseStoreInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "leases")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "leases.json")
+
+	for _, data := range []string{
+		`not json`,
+		`{"version": 2, "leases": []}`,
+		`{"version": 1, "leases": [{"hw_addr": "00:01:02:03:04:01", "ip": "10.0.0.10"}, {"hw_addr": "00:01:02:03:04:02", "ip": "10.0.0.10"}]}`,
+	} {
+		require.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+		_, err := OpenFileLeaseStore(path)
+		require.Error(t, err, data)
+	}
+}
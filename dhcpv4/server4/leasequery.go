@@ -0,0 +1,120 @@
+package server4
+
+import (
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// LeaseQueryReply builds the reply of the server to a DHCPLEASEQUERY, as
+// described in RFC 4388, section 6.4, from its lease store: a DHCPLEASEACTIVE
+// if the queried address or hardware address has a binding that has not
+// expired at the given time, a DHCPLEASEUNASSIGNED if the queried address
+// belongs to the pools or the reservations of the server but is not bound,
+// and a DHCPLEASEUNKNOWN otherwise. The lease store does not record the
+// client identifiers, so the queries by client identifier get a
+// DHCPLEASEUNKNOWN. The DHCPLEASEACTIVE replies hold the client last
+// transaction time option if the Updated time of the binding is known; they
+// never hold the associated IP option, as the lease store binds a single
+// address to each client.
+func (s *Server) LeaseQueryReply(query *dhcpv4.DHCPv4, serverID net.IP, now time.Time) (*dhcpv4.DHCPv4, error) {
+	reply, err := dhcpv4.NewReplyFromRequest(query)
+	if err != nil {
+		return nil, err
+	}
+	reply.AddOption(&dhcpv4.OptServerIdentifier{ServerID: serverID})
+	var b *Binding
+	ciaddr := query.ClientIPAddr()
+	byIP := ciaddr != nil && !ciaddr.IsUnspecified()
+	switch {
+	case byIP:
+		b = s.Leases.LookupIP(ciaddr)
+	case query.HwAddrLen() > 0:
+		b = s.Leases.Lookup(query.ClientHwAddr())
+	}
+	switch {
+	case b != nil && !b.Expired(now):
+		reply.SetClientIPAddr(b.IP)
+		if err := reply.SetClientHwAddr(b.HWAddr); err != nil {
+			return nil, err
+		}
+		// the lease store does not record the hardware types: the
+		// queries by hardware address keep theirs, and the others get
+		// Ethernet for the addresses of its length, and zero otherwise
+		if byIP {
+			htype := iana.HwTypeType(0)
+			if len(b.HWAddr) == 6 {
+				htype = iana.HwTypeEthernet
+			}
+			reply.SetHwType(htype)
+		}
+		// the remaining lease time, rounded up
+		remaining := (b.Expires.Sub(now) + time.Second - 1) / time.Second
+		reply.AddOption(&dhcpv4.OptIPAddressLeaseTime{LeaseTime: uint32(remaining)})
+		if !b.Updated.IsZero() {
+			elapsed := now.Sub(b.Updated) / time.Second
+			if elapsed < 0 {
+				elapsed = 0
+			}
+			reply.AddOption(&dhcpv4.OptClientLastTransactionTime{Time: uint32(elapsed)})
+		}
+		reply.AddOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeLeaseActive})
+	case byIP && s.manages(ciaddr):
+		reply.SetClientIPAddr(ciaddr)
+		reply.AddOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeLeaseUnassigned})
+	default:
+		reply.SetClientIPAddr(ciaddr)
+		reply.AddOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeLeaseUnknown})
+	}
+	return reply, nil
+}
+
+// manages returns true if ip belongs to the pools or to the reservations of
+// the server.
+func (s *Server) manages(ip net.IP) bool {
+	if s.Reservations.LookupIP(ip) != nil {
+		return true
+	}
+	for _, p := range s.Pools() {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnswerLeaseQueries returns a middleware answering the DHCPLEASEQUERY
+// messages with LeaseQueryReply, with serverID as server identifier, and
+// passing the other messages to the handler. As RFC 4388 requires, the
+// replies are sent to the giaddr of the queries, on the port they came from,
+// and the queries without giaddr are dropped.
+func AnswerLeaseQueries(s *Server, serverID net.IP) Middleware {
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			mt := m.MessageType()
+			if mt == nil || *mt != dhcpv4.MessageTypeLeaseQuery {
+				next(conn, peer, m, rc)
+				return
+			}
+			giaddr := m.GatewayIPAddr()
+			if giaddr == nil || giaddr.IsUnspecified() {
+				rc.logger().Printf("Dropping leasequery from %v: no giaddr", peer)
+				return
+			}
+			reply, err := s.LeaseQueryReply(m, serverID, time.Now())
+			if err != nil {
+				rc.logger().Printf("Cannot answer leasequery from %v: %v", giaddr, err)
+				return
+			}
+			port := dhcpv4.ServerPort
+			if addr, ok := peer.(*net.UDPAddr); ok {
+				port = addr.Port
+			}
+			if _, err := conn.WriteTo(reply.ToBytes(), &net.UDPAddr{IP: giaddr, Port: port}); err != nil {
+				rc.logger().Printf("Cannot send leasequery reply to %v: %v", giaddr, err)
+			}
+		}
+	}
+}
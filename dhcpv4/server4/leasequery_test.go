@@ -0,0 +1,115 @@
+package server4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaseQueryReply(t *testing.T) {
+	s := newAllocatorTestServer(t)
+	now := time.Now()
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: allocHwAddr1, IP: net.IPv4(10, 0, 0, 10), Expires: now.Add(time.Hour), Updated: now.Add(-90 * time.Second)}))
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: allocHwAddr2, IP: net.IPv4(10, 0, 0, 11), Expires: now.Add(-time.Minute)}))
+	giaddr, serverID := net.IPv4(192, 168, 0, 1), net.IPv4(10, 0, 0, 1)
+
+	byIP := func(ip net.IP) *dhcpv4.DHCPv4 {
+		q, err := dhcpv4.NewLeaseQueryByIP(giaddr, ip)
+		require.NoError(t, err)
+		return q
+	}
+	byHwAddr := func(hwaddr net.HardwareAddr) *dhcpv4.DHCPv4 {
+		q, err := dhcpv4.NewLeaseQueryByHwAddr(giaddr, hwaddr)
+		require.NoError(t, err)
+		return q
+	}
+	for _, tc := range []struct {
+		name   string
+		query  *dhcpv4.DHCPv4
+		want   dhcpv4.MessageType
+		ciaddr net.IP
+	}{
+		{"bound address", byIP(net.IPv4(10, 0, 0, 10)), dhcpv4.MessageTypeLeaseActive, net.IPv4(10, 0, 0, 10)},
+		{"expired address", byIP(net.IPv4(10, 0, 0, 11)), dhcpv4.MessageTypeLeaseUnassigned, net.IPv4(10, 0, 0, 11)},
+		{"free address", byIP(net.IPv4(10, 0, 0, 12)), dhcpv4.MessageTypeLeaseUnassigned, net.IPv4(10, 0, 0, 12)},
+		{"foreign address", byIP(net.IPv4(10, 0, 1, 10)), dhcpv4.MessageTypeLeaseUnknown, net.IPv4(10, 0, 1, 10)},
+		{"bound client", byHwAddr(allocHwAddr1), dhcpv4.MessageTypeLeaseActive, net.IPv4(10, 0, 0, 10)},
+		{"expired client", byHwAddr(allocHwAddr2), dhcpv4.MessageTypeLeaseUnknown, net.IPv4zero},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reply, err := s.LeaseQueryReply(tc.query, serverID, now)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, *reply.MessageType())
+			require.True(t, reply.ClientIPAddr().Equal(tc.ciaddr), reply.ClientIPAddr())
+			require.Equal(t, tc.query.TransactionID(), reply.TransactionID())
+			require.True(t, reply.ServerIdentifier().Equal(serverID))
+			if tc.want == dhcpv4.MessageTypeLeaseActive {
+				require.Equal(t, allocHwAddr1, reply.ClientHwAddr())
+				require.Equal(t, iana.HwTypeEthernet, reply.HwType())
+				require.Equal(t, time.Hour, reply.IPAddressLeaseTime(0))
+				cltt, ok := reply.ClientLastTransactionTime()
+				require.True(t, ok)
+				require.Equal(t, 90*time.Second, cltt)
+				require.Nil(t, reply.AssociatedIPs())
+			}
+		})
+	}
+}
+
+func TestLeaseQueryReplyHwAddrLengths(t *testing.T) {
+	s := newAllocatorTestServer(t)
+	now := time.Now()
+	short := net.HardwareAddr{1, 2, 3}
+	long := make(net.HardwareAddr, 20)
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: short, IP: net.IPv4(10, 0, 0, 10), Expires: now.Add(time.Hour)}))
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: long, IP: net.IPv4(10, 0, 0, 11), Expires: now.Add(time.Hour)}))
+	giaddr, serverID := net.IPv4(192, 168, 0, 1), net.IPv4(10, 0, 0, 1)
+
+	query, err := dhcpv4.NewLeaseQueryByIP(giaddr, net.IPv4(10, 0, 0, 10))
+	require.NoError(t, err)
+	reply, err := s.LeaseQueryReply(query, serverID, now)
+	require.NoError(t, err)
+	require.Equal(t, dhcpv4.MessageTypeLeaseActive, *reply.MessageType())
+	require.Equal(t, short, reply.ClientHwAddr())
+	require.Equal(t, iana.HwTypeType(0), reply.HwType())
+
+	query, err = dhcpv4.NewLeaseQueryByIP(giaddr, net.IPv4(10, 0, 0, 11))
+	require.NoError(t, err)
+	_, err = s.LeaseQueryReply(query, serverID, now)
+	require.Error(t, err)
+}
+
+func TestAnswerLeaseQueries(t *testing.T) {
+	s := newAllocatorTestServer(t)
+	var count int
+	h := AnswerLeaseQueries(s, net.IPv4(10, 0, 0, 1))(countingHandler(&count))
+	conn := &recordingConn{}
+
+	query, err := dhcpv4.NewLeaseQueryByIP(net.IPv4(192, 168, 0, 1), net.IPv4(10, 0, 0, 10))
+	require.NoError(t, err)
+	h(conn, nil, query, nil)
+	require.Len(t, conn.written, 1)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: dhcpv4.ServerPort}, conn.written[0].addr)
+	reply, err := dhcpv4.FromBytes(conn.written[0].data)
+	require.NoError(t, err)
+	require.Equal(t, dhcpv4.MessageTypeLeaseUnassigned, *reply.MessageType())
+
+	// to the port of the requester
+	h(conn, &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 6767}, query, nil)
+	require.Len(t, conn.written, 2)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 6767}, conn.written[1].addr)
+
+	// not relayed
+	query.SetGatewayIPAddr(net.IPv4zero)
+	h(conn, nil, query, nil)
+	require.Len(t, conn.written, 2)
+
+	discover, err := dhcpv4.NewDiscovery(allocHwAddr1)
+	require.NoError(t, err)
+	h(conn, nil, discover, nil)
+	require.Equal(t, 1, count)
+}
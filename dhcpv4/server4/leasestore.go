@@ -0,0 +1,270 @@
+package server4
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrAddressInUse is returned when binding an address that is currently bound
+// to another client.
+var ErrAddressInUse = errors.New("address already in use")
+
+// Binding associates an IPv4 address with the hardware address of the client
+// it was handed out to, until it expires.
+type Binding struct {
+	HWAddr  net.HardwareAddr
+	IP      net.IP
+	Expires time.Time
+	// Updated is the time of the last transaction with the client, if
+	// known, as reported to the leasequery requesters.
+	Updated time.Time
+}
+
+// Expired returns true if the binding is expired at the given time.
+func (b *Binding) Expired(now time.Time) bool {
+	return !now.Before(b.Expires)
+}
+
+// LeaseStore keeps track of the bindings of a server. Implementations must be
+// safe for concurrent use.
+type LeaseStore interface {
+	// Lookup returns the binding of a hardware address, or nil if there is
+	// none.
+	Lookup(hwaddr net.HardwareAddr) *Binding
+	// LookupIP returns the binding of an address, or nil if there is none.
+	LookupIP(ip net.IP) *Binding
+	// Bind stores a binding, replacing the previous binding of the same
+	// hardware address if any. It fails with ErrAddressInUse if the address
+	// is bound to another client and that binding has not expired yet.
+	Bind(b Binding) error
+	// Release removes the binding of a hardware address, if any.
+	Release(hwaddr net.HardwareAddr)
+	// Range calls f for each binding, in no particular order, until f
+	// returns false.
+	Range(f func(b Binding) bool)
+	// Len returns the number of bindings.
+	Len() int
+}
+
+// DefaultLeaseStoreShards is the number of shards used by NewMemoryLeaseStore
+// when none is specified.
+const DefaultLeaseStoreShards = 256
+
+type hwShard struct {
+	sync.Mutex
+	bindings map[string]*Binding
+}
+
+type ipShard struct {
+	sync.RWMutex
+	bindings map[uint32]*Binding
+}
+
+// MemoryLeaseStore is an in-memory LeaseStore. Bindings are indexed both by
+// hardware address and by IP address, and each index is split into shards
+// with their own lock, so that concurrent operations on different clients
+// rarely contend.
+//
+// To avoid deadlocks, the hardware address shard is always locked before the
+// IP address shards, and IP address shards are locked in ascending order. The
+// hardware address index is allowed to hold stale entries, pointing to
+// bindings that were taken over by another client after expiring; those are
+// detected and cleaned up when the hardware address is looked up.
+type MemoryLeaseStore struct {
+	hwShards []hwShard
+	ipShards []ipShard
+}
+
+// NewMemoryLeaseStore returns an empty MemoryLeaseStore split into the given
+// number of shards. If shards is not positive, DefaultLeaseStoreShards is
+// used.
+func NewMemoryLeaseStore(shards int) *MemoryLeaseStore {
+	if shards <= 0 {
+		shards = DefaultLeaseStoreShards
+	}
+	s := MemoryLeaseStore{
+		hwShards: make([]hwShard, shards),
+		ipShards: make([]ipShard, shards),
+	}
+	for i := 0; i < shards; i++ {
+		s.hwShards[i].bindings = make(map[string]*Binding)
+		s.ipShards[i].bindings = make(map[uint32]*Binding)
+	}
+	return &s
+}
+
+// ipKey converts an IPv4 address to the key used in the IP address index.
+func ipKey(ip net.IP) uint32 {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(ip4)
+}
+
+func (s *MemoryLeaseStore) hwShardIndex(key string) int {
+	// FNV-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return int(h % uint32(len(s.hwShards)))
+}
+
+func (s *MemoryLeaseStore) ipShardIndex(key uint32) int {
+	// consecutive addresses are spread across shards by the multiplicative
+	// hash, pools being usually allocated sequentially
+	return int((key * 2654435761) % uint32(len(s.ipShards)))
+}
+
+// copyBinding returns a copy of b that does not share memory with it.
+func copyBinding(b *Binding) *Binding {
+	return &Binding{
+		HWAddr:  append(net.HardwareAddr(nil), b.HWAddr...),
+		IP:      append(net.IP(nil), b.IP...),
+		Expires: b.Expires,
+		Updated: b.Updated,
+	}
+}
+
+// Lookup implements LeaseStore.Lookup.
+func (s *MemoryLeaseStore) Lookup(hwaddr net.HardwareAddr) *Binding {
+	hk := string(hwaddr)
+	hs := &s.hwShards[s.hwShardIndex(hk)]
+	hs.Lock()
+	defer hs.Unlock()
+	b := hs.bindings[hk]
+	if b == nil {
+		return nil
+	}
+	ik := ipKey(b.IP)
+	is := &s.ipShards[s.ipShardIndex(ik)]
+	is.RLock()
+	current := is.bindings[ik]
+	is.RUnlock()
+	if current != b {
+		// the address was taken over by another client
+		delete(hs.bindings, hk)
+		return nil
+	}
+	return copyBinding(b)
+}
+
+// LookupIP implements LeaseStore.LookupIP.
+func (s *MemoryLeaseStore) LookupIP(ip net.IP) *Binding {
+	ik := ipKey(ip)
+	is := &s.ipShards[s.ipShardIndex(ik)]
+	is.RLock()
+	defer is.RUnlock()
+	b := is.bindings[ik]
+	if b == nil {
+		return nil
+	}
+	return copyBinding(b)
+}
+
+// Bind implements LeaseStore.Bind.
+func (s *MemoryLeaseStore) Bind(b Binding) error {
+	if b.IP.To4() == nil {
+		return errors.New("not an IPv4 address")
+	}
+	nb := copyBinding(&b)
+	nb.IP = nb.IP.To4()
+	hk := string(nb.HWAddr)
+	ik := ipKey(nb.IP)
+
+	hs := &s.hwShards[s.hwShardIndex(hk)]
+	hs.Lock()
+	defer hs.Unlock()
+
+	// lock the shard of the new address and, if the client is moving to a
+	// different address, the shard of the old one, in ascending order.
+	old := hs.bindings[hk]
+	var oik uint32
+	newIdx, oldIdx := s.ipShardIndex(ik), -1
+	if old != nil {
+		oik = ipKey(old.IP)
+		if oik != ik {
+			oldIdx = s.ipShardIndex(oik)
+		}
+	}
+	first, second := newIdx, oldIdx
+	if second >= 0 && second < first {
+		first, second = second, first
+	}
+	if second == first {
+		second = -1
+	}
+	s.ipShards[first].Lock()
+	defer s.ipShards[first].Unlock()
+	if second >= 0 {
+		s.ipShards[second].Lock()
+		defer s.ipShards[second].Unlock()
+	}
+
+	is := &s.ipShards[newIdx]
+	if current := is.bindings[ik]; current != nil && string(current.HWAddr) != hk && !current.Expired(time.Now()) {
+		return ErrAddressInUse
+	}
+	if old != nil && oik != ik {
+		ois := &s.ipShards[s.ipShardIndex(oik)]
+		if ois.bindings[oik] == old {
+			delete(ois.bindings, oik)
+		}
+	}
+	is.bindings[ik] = nb
+	hs.bindings[hk] = nb
+	return nil
+}
+
+// Release implements LeaseStore.Release.
+func (s *MemoryLeaseStore) Release(hwaddr net.HardwareAddr) {
+	hk := string(hwaddr)
+	hs := &s.hwShards[s.hwShardIndex(hk)]
+	hs.Lock()
+	defer hs.Unlock()
+	b := hs.bindings[hk]
+	if b == nil {
+		return
+	}
+	ik := ipKey(b.IP)
+	is := &s.ipShards[s.ipShardIndex(ik)]
+	is.Lock()
+	if is.bindings[ik] == b {
+		delete(is.bindings, ik)
+	}
+	is.Unlock()
+	delete(hs.bindings, hk)
+}
+
+// Range implements LeaseStore.Range. Each shard is locked while it is being
+// visited, so f must not call other methods of the store.
+func (s *MemoryLeaseStore) Range(f func(b Binding) bool) {
+	for i := range s.ipShards {
+		is := &s.ipShards[i]
+		is.RLock()
+		for _, b := range is.bindings {
+			if !f(*copyBinding(b)) {
+				is.RUnlock()
+				return
+			}
+		}
+		is.RUnlock()
+	}
+}
+
+// Len implements LeaseStore.Len.
+func (s *MemoryLeaseStore) Len() int {
+	var n int
+	for i := range s.ipShards {
+		is := &s.ipShards[i]
+		is.RLock()
+		n += len(is.bindings)
+		is.RUnlock()
+	}
+	return n
+}
@@ -0,0 +1,186 @@
+package server4
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func hwaddrFromInt(i uint32) net.HardwareAddr {
+	hw := net.HardwareAddr{0x02, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(hw[2:], i)
+	return hw
+}
+
+func ipFromInt(i uint32) net.IP {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, 10<<24|i)
+	return ip
+}
+
+func TestMemoryLeaseStoreBind(t *testing.T) {
+	s := NewMemoryLeaseStore(0)
+	hw1, hw2 := hwaddrFromInt(1), hwaddrFromInt(2)
+	expires := time.Now().Add(time.Hour)
+
+	require.Nil(t, s.Lookup(hw1))
+	require.Nil(t, s.LookupIP(ipFromInt(1)))
+	require.Error(t, s.Bind(Binding{HWAddr: hw1, IP: net.ParseIP("2001:db8::1"), Expires: expires}))
+
+	require.NoError(t, s.Bind(Binding{HWAddr: hw1, IP: ipFromInt(1), Expires: expires}))
+	b := s.Lookup(hw1)
+	require.NotNil(t, b)
+	require.Equal(t, hw1, b.HWAddr)
+	require.True(t, b.IP.Equal(ipFromInt(1)))
+	require.Equal(t, b, s.LookupIP(net.IPv4(10, 0, 0, 1)))
+	require.Equal(t, 1, s.Len())
+
+	// the address belongs to hw1
+	require.Equal(t, ErrAddressInUse, s.Bind(Binding{HWAddr: hw2, IP: ipFromInt(1), Expires: expires}))
+
+	// moving hw1 to another address frees the first one
+	require.NoError(t, s.Bind(Binding{HWAddr: hw1, IP: ipFromInt(2), Expires: expires}))
+	require.Nil(t, s.LookupIP(ipFromInt(1)))
+	require.True(t, s.Lookup(hw1).IP.Equal(ipFromInt(2)))
+	require.Equal(t, 1, s.Len())
+	require.NoError(t, s.Bind(Binding{HWAddr: hw2, IP: ipFromInt(1), Expires: expires}))
+	require.Equal(t, 2, s.Len())
+}
+
+func TestMemoryLeaseStoreExpiredTakeover(t *testing.T) {
+	s := NewMemoryLeaseStore(4)
+	hw1, hw2 := hwaddrFromInt(1), hwaddrFromInt(2)
+	require.NoError(t, s.Bind(Binding{HWAddr: hw1, IP: ipFromInt(1), Expires: time.Now().Add(-time.Second)}))
+	require.NoError(t, s.Bind(Binding{HWAddr: hw2, IP: ipFromInt(1), Expires: time.Now().Add(time.Hour)}))
+	require.Nil(t, s.Lookup(hw1), "binding was taken over")
+	require.Equal(t, hw2, s.Lookup(hw2).HWAddr)
+	require.Equal(t, 1, s.Len())
+
+	// releasing the stale client must not affect the new one
+	s.Release(hw1)
+	require.NotNil(t, s.LookupIP(ipFromInt(1)))
+}
+
+func TestMemoryLeaseStoreRelease(t *testing.T) {
+	s := NewMemoryLeaseStore(0)
+	hw := hwaddrFromInt(1)
+	s.Release(hw)
+	require.NoError(t, s.Bind(Binding{HWAddr: hw, IP: ipFromInt(1), Expires: time.Now().Add(time.Hour)}))
+	s.Release(hw)
+	require.Nil(t, s.Lookup(hw))
+	require.Nil(t, s.LookupIP(ipFromInt(1)))
+	require.Equal(t, 0, s.Len())
+}
+
+func TestMemoryLeaseStoreRange(t *testing.T) {
+	s := NewMemoryLeaseStore(8)
+	for i := uint32(0); i < 100; i++ {
+		require.NoError(t, s.Bind(Binding{HWAddr: hwaddrFromInt(i), IP: ipFromInt(i), Expires: time.Now().Add(time.Hour)}))
+	}
+	seen := make(map[string]bool)
+	s.Range(func(b Binding) bool {
+		seen[b.HWAddr.String()] = true
+		return true
+	})
+	require.Equal(t, 100, len(seen))
+
+	var n int
+	s.Range(func(b Binding) bool {
+		n++
+		return n < 10
+	})
+	require.Equal(t, 10, n)
+}
+
+func TestMemoryLeaseStoreLookupReturnsCopy(t *testing.T) {
+	s := NewMemoryLeaseStore(0)
+	hw := hwaddrFromInt(1)
+	require.NoError(t, s.Bind(Binding{HWAddr: hw, IP: ipFromInt(1), Expires: time.Now().Add(time.Hour)}))
+	b := s.Lookup(hw)
+	b.IP[3] = 42
+	require.True(t, s.Lookup(hw).IP.Equal(ipFromInt(1)))
+}
+
+func TestMemoryLeaseStoreConcurrentBind(t *testing.T) {
+	const (
+		clients   = 64
+		addresses = 256
+	)
+	s := NewMemoryLeaseStore(16)
+	var wg sync.WaitGroup
+	for c := uint32(0); c < clients; c++ {
+		wg.Add(1)
+		go func(c uint32) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(c)))
+			for i := 0; i < 1000; i++ {
+				ip := ipFromInt(uint32(r.Intn(addresses)))
+				s.Bind(Binding{HWAddr: hwaddrFromInt(c), IP: ip, Expires: time.Now().Add(time.Hour)})
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	// every client holds at most one address, and the two indices agree
+	require.True(t, s.Len() <= clients)
+	s.Range(func(b Binding) bool {
+		hb := s.Lookup(b.HWAddr)
+		require.NotNil(t, hb)
+		require.True(t, hb.IP.Equal(b.IP))
+		return true
+	})
+}
+
+// fillLeaseStore binds n consecutive addresses to n different clients.
+func fillLeaseStore(b *testing.B, s LeaseStore, n uint32) {
+	expires := time.Now().Add(time.Hour)
+	for i := uint32(0); i < n; i++ {
+		if err := s.Bind(Binding{HWAddr: hwaddrFromInt(i), IP: ipFromInt(i), Expires: expires}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMemoryLeaseStoreAllocate measures concurrent allocations of free
+// addresses in a store already holding one million bindings.
+func BenchmarkMemoryLeaseStoreAllocate(b *testing.B) {
+	const size = 1 << 20
+	s := NewMemoryLeaseStore(0)
+	fillLeaseStore(b, s, size)
+	var next uint32 = size
+	expires := time.Now().Add(time.Hour)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			i := atomic.AddUint32(&next, 1)
+			if err := s.Bind(Binding{HWAddr: hwaddrFromInt(i), IP: ipFromInt(i), Expires: expires}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkMemoryLeaseStoreLookup measures concurrent lookups in a store
+// holding one million bindings.
+func BenchmarkMemoryLeaseStoreLookup(b *testing.B) {
+	const size = 1 << 20
+	s := NewMemoryLeaseStore(0)
+	fillLeaseStore(b, s, size)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			if s.Lookup(hwaddrFromInt(uint32(r.Intn(size)))) == nil {
+				b.Fatal("binding not found")
+			}
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package server4
+
+import (
+	"net"
+	"sync"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// Middleware wraps a Handler, typically to drop some of the messages before
+// they reach it. Middlewares work the same way in front of a relay agent
+// built on Server.
+type Middleware func(Handler) Handler
+
+// Chain wraps handler with the given middlewares, the first one being the
+// first to see the messages.
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// DefaultMaxHopCount is the default hop count threshold recommended by
+// RFC 1542, section 4.1.1.
+const DefaultMaxHopCount = 4
+
+// MaxHopCount returns a middleware that drops the messages whose hops field is
+// greater than max, that is the messages relayed more than max times. This
+// protects against relay loops.
+func MaxHopCount(max uint8) Middleware {
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			if m.HopCount() > max {
+				rc.logger().Printf("Dropping message from %v: hop count %d greater than %d", peer, m.HopCount(), max)
+				return
+			}
+			next(conn, peer, m, rc)
+		}
+	}
+}
+
+// KnownRelays is a list of the networks of the relay agents allowed to relay
+// messages to the server. It can be changed while the server is running.
+type KnownRelays struct {
+	mu   sync.RWMutex
+	nets []net.IPNet
+}
+
+// NewKnownRelays returns a KnownRelays list holding the given networks.
+func NewKnownRelays(nets ...net.IPNet) *KnownRelays {
+	return &KnownRelays{nets: append([]net.IPNet(nil), nets...)}
+}
+
+// Add adds a network to the list.
+func (k *KnownRelays) Add(n net.IPNet) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.nets = append(k.nets, n)
+}
+
+// Remove removes a network from the list, if present.
+func (k *KnownRelays) Remove(n net.IPNet) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for i, other := range k.nets {
+		if other.IP.Equal(n.IP) && other.Mask.String() == n.Mask.String() {
+			k.nets = append(k.nets[:i], k.nets[i+1:]...)
+			return
+		}
+	}
+}
+
+// Contains returns true if the address belongs to one of the networks of the
+// list.
+func (k *KnownRelays) Contains(ip net.IP) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, n := range k.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateGatewayIPAddr returns a middleware that drops the relayed messages,
+// those with a giaddr, unless both the giaddr and the address they come from
+// belong to known relay agents. This protects against clients that pretend
+// to be relay agents to get addresses from other networks. Messages without
+// a giaddr are passed through.
+func ValidateGatewayIPAddr(relays *KnownRelays) Middleware {
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			giaddr := m.GatewayIPAddr()
+			if giaddr != nil && !giaddr.IsUnspecified() {
+				if !relays.Contains(giaddr) {
+					rc.logger().Printf("Dropping message from %v: unknown giaddr %v", peer, giaddr)
+					return
+				}
+				if src := peerIP(peer); src == nil || !relays.Contains(src) {
+					rc.logger().Printf("Dropping message with giaddr %v: sent by unknown relay %v", giaddr, peer)
+					return
+				}
+			}
+			next(conn, peer, m, rc)
+		}
+	}
+}
+
+// ClassifyVendorClass returns a middleware that assigns the clients whose
+// class identifier matches prefix, as dhcpv4.MatchVendorClass does, to class,
+// so that the handler can branch on it, e.g. to serve a different boot file to
+// the "PXEClient:Arch:00007" clients.
+func ClassifyVendorClass(prefix, class string) Middleware {
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			if rc != nil && rc.ClassIdentifier != "" && dhcpv4.MatchVendorClass(rc.ClassIdentifier, prefix) {
+				rc.Classes = append(rc.Classes, class)
+			}
+			next(conn, peer, m, rc)
+		}
+	}
+}
+
+// peerIP returns the IP address of a peer, or nil if it has none.
+func peerIP(peer net.Addr) net.IP {
+	switch addr := peer.(type) {
+	case *net.UDPAddr:
+		return addr.IP
+	case *net.IPAddr:
+		return addr.IP
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package server4
+
+import (
+	"bytes"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+// countingHandler returns a handler that counts the messages it handles.
+func countingHandler(count *int) Handler {
+	return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		*count++
+	}
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+				order = append(order, name)
+				next(conn, peer, m, rc)
+			}
+		}
+	}
+	var count int
+	h := Chain(countingHandler(&count), mw("first"), mw("second"))
+	h(nil, nil, nil, nil)
+	require.Equal(t, []string{"first", "second"}, order)
+	require.Equal(t, 1, count)
+}
+
+func TestMaxHopCount(t *testing.T) {
+	var count int
+	h := MaxHopCount(DefaultMaxHopCount)(countingHandler(&count))
+	m, err := dhcpv4.New()
+	require.NoError(t, err)
+	m.SetHopCount(DefaultMaxHopCount)
+	h(nil, nil, m, nil)
+	require.Equal(t, 1, count)
+	m.SetHopCount(DefaultMaxHopCount + 1)
+	h(nil, nil, m, nil)
+	require.Equal(t, 1, count)
+
+	// the dropped messages are reported to the logger of the context
+	var buf bytes.Buffer
+	h(nil, nil, m, &RequestContext{Logger: log.New(&buf, "", 0)})
+	require.Equal(t, 1, count)
+	require.Contains(t, buf.String(), "hop count 5 greater than 4")
+}
+
+func TestValidateGatewayIPAddr(t *testing.T) {
+	_, relayNet, err := net.ParseCIDR("192.168.1.0/24")
+	require.NoError(t, err)
+	relays := NewKnownRelays(*relayNet)
+	var count int
+	h := ValidateGatewayIPAddr(relays)(countingHandler(&count))
+	m, err := dhcpv4.New()
+	require.NoError(t, err)
+	relay := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 1), Port: dhcpv4.ServerPort}
+	client := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: dhcpv4.ClientPort}
+
+	// not relayed
+	h(nil, client, m, nil)
+	require.Equal(t, 1, count)
+
+	// relayed by a known relay
+	m.SetGatewayIPAddr(net.IPv4(192, 168, 1, 1))
+	h(nil, relay, m, nil)
+	require.Equal(t, 2, count)
+
+	// known giaddr, but not sent by a known relay
+	h(nil, client, m, nil)
+	require.Equal(t, 2, count)
+
+	// unknown giaddr
+	m.SetGatewayIPAddr(net.IPv4(172, 16, 0, 1))
+	h(nil, relay, m, nil)
+	require.Equal(t, 2, count)
+
+	// the list can change at runtime
+	_, otherNet, err := net.ParseCIDR("172.16.0.0/16")
+	require.NoError(t, err)
+	relays.Add(*otherNet)
+	h(nil, relay, m, nil)
+	require.Equal(t, 3, count)
+	relays.Remove(*otherNet)
+	require.False(t, relays.Contains(net.IPv4(172, 16, 0, 1)))
+}
+
+func TestClassifyVendorClass(t *testing.T) {
+	var classes []string
+	h := Chain(func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		classes = rc.Classes
+	}, ClassifyVendorClass("PXEClient", "pxe"), ClassifyVendorClass("PXEClient:Arch:00007", "efi"))
+
+	m, err := dhcpv4.New()
+	require.NoError(t, err)
+	m = dhcpv4.WithVendorClass("PXEClient:Arch:00007:UNDI:003016")(m)
+	h(nil, nil, m, NewRequestContext(nil, m, time.Now()))
+	require.Equal(t, []string{"pxe", "efi"}, classes)
+
+	m = dhcpv4.WithVendorClass("PXEClient:Arch:00000:UNDI:002001")(m)
+	h(nil, nil, m, NewRequestContext(nil, m, time.Now()))
+	require.Equal(t, []string{"pxe"}, classes)
+}
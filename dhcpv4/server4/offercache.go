@@ -0,0 +1,129 @@
+package server4
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// DefaultOfferCacheTTL is the default time during which an OfferCache keeps
+// the offers, a few retransmission intervals of the clients.
+const DefaultOfferCacheTTL = 5 * time.Second
+
+// offerKey identifies the exchange an offer belongs to.
+type offerKey struct {
+	hwaddr string
+	xid    uint32
+}
+
+// cachedOffer is an offer as it was sent.
+type cachedOffer struct {
+	data    []byte
+	addr    net.Addr
+	expires time.Time
+}
+
+// OfferCache keeps the offers sent by the server for a short time, so that the
+// retransmissions of a Discover get the offer sent for the first one, with the
+// same yiaddr, instead of a new one. This saves allocations, and keeps the
+// pools from churning on lossy networks. It is used through the CacheOffers
+// middleware.
+type OfferCache struct {
+	ttl    time.Duration
+	mu     sync.Mutex
+	offers map[offerKey]cachedOffer
+	pruned time.Time
+}
+
+// NewOfferCache returns an empty OfferCache keeping the offers for ttl.
+func NewOfferCache(ttl time.Duration) *OfferCache {
+	return &OfferCache{ttl: ttl, offers: make(map[offerKey]cachedOffer)}
+}
+
+// exchangeKey returns the key of the exchange of a message.
+func exchangeKey(m *dhcpv4.DHCPv4) offerKey {
+	return offerKey{hwaddr: string(m.ClientHwAddr()), xid: m.TransactionID()}
+}
+
+// lookup returns the offer sent in response to the given Discover, and the
+// address it was sent to, if it has not expired at the given time.
+func (c *OfferCache) lookup(discover *dhcpv4.DHCPv4, now time.Time) ([]byte, net.Addr, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	offer, ok := c.offers[exchangeKey(discover)]
+	if !ok || !now.Before(offer.expires) {
+		return nil, nil, false
+	}
+	return offer.data, offer.addr, true
+}
+
+// store records an offer sent to addr, and drops the expired ones at most once
+// per TTL.
+func (c *OfferCache) store(offer *dhcpv4.DHCPv4, data []byte, addr net.Addr, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Sub(c.pruned) >= c.ttl {
+		for k, o := range c.offers {
+			if !now.Before(o.expires) {
+				delete(c.offers, k)
+			}
+		}
+		c.pruned = now
+	}
+	c.offers[exchangeKey(offer)] = cachedOffer{
+		data:    append([]byte{}, data...),
+		addr:    addr,
+		expires: now.Add(c.ttl),
+	}
+}
+
+// Len returns the number of offers in the cache, give or take the expired
+// ones not pruned yet.
+func (c *OfferCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.offers)
+}
+
+// offerRecorder records in a cache the offers written to the connection.
+type offerRecorder struct {
+	net.PacketConn
+	cache *OfferCache
+}
+
+func (r offerRecorder) WriteTo(p []byte, addr net.Addr) (int, error) {
+	n, err := r.PacketConn.WriteTo(p, addr)
+	if err != nil {
+		return n, err
+	}
+	if m, perr := dhcpv4.FromBytes(p); perr == nil {
+		if mt := m.MessageType(); mt != nil && *mt == dhcpv4.MessageTypeOffer {
+			r.cache.store(m, p, addr, time.Now())
+		}
+	}
+	return n, err
+}
+
+// CacheOffers returns a middleware answering the retransmitted Discovers with
+// the offer cached for the exchange, if any, instead of passing them to the
+// handler. The offers that the handler sends are added to the cache.
+func CacheOffers(cache *OfferCache) Middleware {
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			mt := m.MessageType()
+			if mt == nil || *mt != dhcpv4.MessageTypeDiscover {
+				next(conn, peer, m, rc)
+				return
+			}
+			if data, addr, ok := cache.lookup(m, time.Now()); ok {
+				if _, err := conn.WriteTo(data, addr); err != nil {
+					rc.logger().Printf("Cannot resend cached offer to %v: %v", addr, err)
+				}
+				return
+			}
+			next(offerRecorder{PacketConn: conn, cache: cache}, peer, m, rc)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package server4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+// writtenPacket is a packet written to a recordingConn.
+type writtenPacket struct {
+	data []byte
+	addr net.Addr
+}
+
+// recordingConn is a PacketConn recording the packets written to it.
+type recordingConn struct {
+	net.PacketConn
+	written []writtenPacket
+}
+
+func (c *recordingConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	c.written = append(c.written, writtenPacket{append([]byte{}, p...), addr})
+	return len(p), nil
+}
+
+func TestCacheOffers(t *testing.T) {
+	var next byte = 10
+	handler := func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		offer, err := dhcpv4.NewReplyFromRequest(m,
+			dhcpv4.WithMessageType(dhcpv4.MessageTypeOffer),
+			dhcpv4.WithYourIP(net.IPv4(192, 168, 0, next)),
+		)
+		require.NoError(t, err)
+		next++
+		conn.WriteTo(offer.ToBytes(), peer)
+	}
+	cache := NewOfferCache(DefaultOfferCacheTTL)
+	h := CacheOffers(cache)(handler)
+	conn := &recordingConn{}
+	peer := &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ClientPort}
+
+	discover, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	h(conn, peer, discover, nil)
+	h(conn, peer, discover, nil)
+	require.Len(t, conn.written, 2)
+	require.Equal(t, conn.written[0], conn.written[1])
+	require.Equal(t, 1, cache.Len())
+	offer, err := dhcpv4.FromBytes(conn.written[1].data)
+	require.NoError(t, err)
+	require.True(t, offer.YourIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+
+	// another exchange gets a new offer
+	other, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	h(conn, peer, other, nil)
+	require.Len(t, conn.written, 3)
+	offer, err = dhcpv4.FromBytes(conn.written[2].data)
+	require.NoError(t, err)
+	require.True(t, offer.YourIPAddr().Equal(net.IPv4(192, 168, 0, 11)))
+}
+
+func TestOfferCacheExpiry(t *testing.T) {
+	cache := NewOfferCache(time.Second)
+	discover, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	offer, err := dhcpv4.NewReplyFromRequest(discover, dhcpv4.WithMessageType(dhcpv4.MessageTypeOffer))
+	require.NoError(t, err)
+	now := time.Now()
+	cache.store(offer, offer.ToBytes(), nil, now)
+
+	_, _, ok := cache.lookup(discover, now.Add(500*time.Millisecond))
+	require.True(t, ok)
+	_, _, ok = cache.lookup(discover, now.Add(time.Second))
+	require.False(t, ok)
+
+	// expired offers are dropped when storing new ones
+	other, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 7})
+	require.NoError(t, err)
+	offer, err = dhcpv4.NewReplyFromRequest(other, dhcpv4.WithMessageType(dhcpv4.MessageTypeOffer))
+	require.NoError(t, err)
+	cache.store(offer, offer.ToBytes(), nil, now.Add(2*time.Second))
+	require.Equal(t, 1, cache.Len())
+}
+
+func TestOfferCachePrunesOncePerTTL(t *testing.T) {
+	cache := NewOfferCache(time.Second)
+	now := time.Now()
+	store := func(last byte, at time.Duration) {
+		discover, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, last})
+		require.NoError(t, err)
+		offer, err := dhcpv4.NewReplyFromRequest(discover, dhcpv4.WithMessageType(dhcpv4.MessageTypeOffer))
+		require.NoError(t, err)
+		cache.store(offer, offer.ToBytes(), nil, now.Add(at))
+	}
+	store(1, 0)
+	store(2, 500*time.Millisecond)
+	store(3, time.Second)
+	require.Equal(t, 2, cache.Len())
+	// the second offer expired, but the cache was pruned less than a TTL ago
+	store(4, 1800*time.Millisecond)
+	require.Equal(t, 3, cache.Len())
+	store(5, 2*time.Second)
+	require.Equal(t, 2, cache.Len())
+}
@@ -0,0 +1,106 @@
+package server4
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// DefaultPingTimeout is how long a Pinger waits for an echo reply, as the
+// ping-timeout of ISC dhcpd.
+const DefaultPingTimeout = time.Second
+
+// Pinger checks that an address is not in use with an ICMP echo request, as
+// RFC 2131, section 4.4.1 suggests, and as ISC dhcpd does before offering an
+// address. Unlike the ARP probe of an acd.Detector, it works for the clients
+// of relay agents, but hosts commonly filter echo requests. Its Probe method
+// can be used as the PingCheck of a Server, or the Probe of a RangeAllocator.
+type Pinger struct {
+	// Timeout is how long to wait for a reply. If zero,
+	// DefaultPingTimeout is used.
+	Timeout time.Duration
+	// Privileged tells to send the requests through a raw ICMP socket,
+	// which requires CAP_NET_RAW, rather than through an ICMP datagram
+	// socket, which Linux only allows to the groups of the
+	// net.ipv4.ping_group_range sysctl.
+	Privileged bool
+}
+
+// Probe sends an echo request to ip, and returns an *acd.ConflictError if a
+// reply comes back within the timeout of the Pinger, nil otherwise. It stops
+// waiting when ctx is done, and returns its error.
+func (p *Pinger) Probe(ctx context.Context, ip net.IP) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	network, dst := "udp4", net.Addr(&net.UDPAddr{IP: ip})
+	if p.Privileged {
+		network, dst = "ip4:icmp", &net.IPAddr{IP: ip}
+	}
+	conn, err := icmp.ListenPacket(network, "0.0.0.0")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// the kernel replaces the identifier of the requests sent through
+	// datagram sockets, so the replies are matched by sequence number
+	id, seq := rand.Intn(0xffff), rand.Intn(0xffff)
+	req := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("DHCP ping check")},
+	}
+	buf, err := req.Marshal(nil)
+	if err != nil {
+		return err
+	}
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = DefaultPingTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	if _, err := conn.WriteTo(buf, dst); err != nil {
+		return err
+	}
+
+	rbuf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(rbuf)
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				return ctx.Err()
+			}
+			return err
+		}
+		if !addrIP(peer).Equal(ip) {
+			continue
+		}
+		reply, err := icmp.ParseMessage(ipv4.ICMPTypeEchoReply.Protocol(), rbuf[:n])
+		if err != nil || reply.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.Seq == seq && (!p.Privileged || echo.ID == id) {
+			return &acd.ConflictError{IP: ip}
+		}
+	}
+}
+
+// addrIP returns the IP address of a UDP or IP address.
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.IPAddr:
+		return a.IP
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package server4
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPingerProbe(t *testing.T) {
+	p := &Pinger{Timeout: time.Second, Privileged: true}
+	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		t.Skipf("cannot open a raw ICMP socket: %v", err)
+	}
+	conn.Close()
+
+	// the loopback address always answers
+	err = p.Probe(context.Background(), net.IPv4(127, 0, 0, 1))
+	require.True(t, acd.IsConflict(err), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.Timeout = 10 * time.Millisecond
+	require.Equal(t, context.Canceled, p.Probe(ctx, net.IPv4(192, 0, 2, 1)))
+}
+
+func TestPoolAllocatorPingCheck(t *testing.T) {
+	s := newAllocatorTestServer(t)
+	ctx := context.Background()
+	var pinged []net.IP
+	s.PingCheck = func(ctx context.Context, ip net.IP) error {
+		pinged = append(pinged, ip.To4())
+		if ip.Equal(net.IPv4(10, 0, 0, 10)) || ip.Equal(net.IPv4(10, 0, 0, 12)) {
+			return &acd.ConflictError{IP: ip}
+		}
+		return nil
+	}
+
+	// the addresses in use are abandoned and skipped
+	ip, err := s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1, Hint: net.IPv4(10, 0, 0, 12)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 11}, ip)
+	require.Equal(t, []net.IP{{10, 0, 0, 12}, {10, 0, 0, 10}, {10, 0, 0, 11}}, pinged)
+	require.True(t, s.IsAbandoned(net.IPv4(10, 0, 0, 10), time.Now()))
+	require.True(t, s.IsAbandoned(net.IPv4(10, 0, 0, 12), time.Now()))
+
+	// the address of a client is not checked again
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: allocHwAddr1, IP: ip, Expires: time.Now().Add(time.Hour)}))
+	pinged = nil
+	ip, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1, Hint: net.IPv4(10, 0, 0, 11)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 11}, ip)
+	require.Empty(t, pinged)
+	_, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2})
+	require.Equal(t, ErrNoAddress, err)
+}
@@ -0,0 +1,52 @@
+package server4
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Pool is a range of IPv4 addresses that the server can hand out, from Start
+// to End included.
+type Pool struct {
+	Start net.IP
+	End   net.IP
+}
+
+// NewPool returns a new pool of addresses between start and end included. It
+// returns an error if the addresses are not IPv4 addresses or if end comes
+// before start.
+func NewPool(start, end net.IP) (*Pool, error) {
+	s, e := start.To4(), end.To4()
+	if s == nil || e == nil {
+		return nil, errors.New("pool boundaries must be IPv4 addresses")
+	}
+	if bytes.Compare(s, e) > 0 {
+		return nil, fmt.Errorf("invalid pool: %v comes after %v", start, end)
+	}
+	return &Pool{Start: s, End: e}, nil
+}
+
+// Contains returns true if ip belongs to the pool.
+func (p *Pool) Contains(ip net.IP) bool {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return false
+	}
+	return bytes.Compare(ip4, p.Start.To4()) >= 0 && bytes.Compare(ip4, p.End.To4()) <= 0
+}
+
+// Size returns the number of addresses in the pool.
+func (p *Pool) Size() int {
+	return int(ipKey(p.End)-ipKey(p.Start)) + 1
+}
+
+// Overlaps returns true if the two pools have at least one address in common.
+func (p *Pool) Overlaps(other *Pool) bool {
+	return p.Contains(other.Start) || p.Contains(other.End) || other.Contains(p.Start)
+}
+
+func (p *Pool) String() string {
+	return fmt.Sprintf("%v-%v", p.Start, p.End)
+}
@@ -0,0 +1,45 @@
+package server4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPool(t *testing.T) {
+	_, err := NewPool(net.ParseIP("2001:db8::1"), net.IPv4(10, 0, 0, 1))
+	require.Error(t, err, "IPv6 boundary")
+
+	_, err = NewPool(net.IPv4(10, 0, 0, 2), net.IPv4(10, 0, 0, 1))
+	require.Error(t, err, "reversed boundaries")
+
+	p, err := NewPool(net.IPv4(10, 0, 0, 1), net.IPv4(10, 0, 1, 0))
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 1}, p.Start)
+	require.Equal(t, net.IP{10, 0, 1, 0}, p.End)
+	require.Equal(t, 256, p.Size())
+	require.Equal(t, "10.0.0.1-10.0.1.0", p.String())
+}
+
+func TestPoolContains(t *testing.T) {
+	p, err := NewPool(net.IPv4(10, 0, 0, 10), net.IPv4(10, 0, 0, 20))
+	require.NoError(t, err)
+	require.True(t, p.Contains(net.IPv4(10, 0, 0, 10)))
+	require.True(t, p.Contains(net.IP{10, 0, 0, 15}))
+	require.True(t, p.Contains(net.IPv4(10, 0, 0, 20)))
+	require.False(t, p.Contains(net.IPv4(10, 0, 0, 9)))
+	require.False(t, p.Contains(net.IPv4(10, 0, 0, 21)))
+	require.False(t, p.Contains(net.ParseIP("2001:db8::1")))
+}
+
+func TestPoolOverlaps(t *testing.T) {
+	p1, _ := NewPool(net.IPv4(10, 0, 0, 10), net.IPv4(10, 0, 0, 20))
+	p2, _ := NewPool(net.IPv4(10, 0, 0, 20), net.IPv4(10, 0, 0, 30))
+	p3, _ := NewPool(net.IPv4(10, 0, 0, 21), net.IPv4(10, 0, 0, 30))
+	p4, _ := NewPool(net.IPv4(10, 0, 0, 0), net.IPv4(10, 0, 0, 255))
+	require.True(t, p1.Overlaps(p2))
+	require.False(t, p1.Overlaps(p3))
+	require.True(t, p1.Overlaps(p4))
+	require.True(t, p4.Overlaps(p1))
+}
@@ -0,0 +1,27 @@
+// +build linux
+
+package server4
+
+import (
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// NewServerDroppingPrivileges listens on addr while the process is still
+// privileged, then drops to the given user and group keeping only the
+// capabilities in keep, and returns a Server serving on the opened socket.
+// Since the socket is already bound to the DHCP server port, a server usually
+// needs no capability once it runs. See dhcpv4.DropPrivileges for the
+// details.
+func NewServerDroppingPrivileges(addr net.UDPAddr, handler Handler, uid, gid int, keep ...uintptr) (*Server, error) {
+	conn, err := net.ListenUDP("udp4", &addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := dhcpv4.DropPrivileges(uid, gid, keep...); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return NewServerWithConn(conn, handler), nil
+}
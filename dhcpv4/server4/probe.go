@@ -0,0 +1,33 @@
+package server4
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+)
+
+// DefaultAbandonTime is how long Probe abandons the addresses it finds in use.
+const DefaultAbandonTime = time.Hour
+
+// Probe checks that ip is not in use before the handler offers it, with an
+// ARP probe on the link of d, as an alternative to the ICMP echo request that
+// RFC 2131 section 4.4.1 suggests, which hosts commonly filter. The link must
+// be the one of the client, so this only works for the clients that are not
+// relayed; d usually has the acd.ServerTiming. If ip is in use, it is
+// abandoned for DefaultAbandonTime and an *acd.ConflictError is returned, so
+// that the handler offers another address.
+func (s *Server) Probe(ctx context.Context, d *acd.Detector, ip net.IP) error {
+	return s.abandonConflict(ip, d.Probe(ctx, ip))
+}
+
+// abandonConflict abandons ip for DefaultAbandonTime if err, the result of a
+// probe, tells that it is in use, and returns err.
+func (s *Server) abandonConflict(ip net.IP, err error) error {
+	if acd.IsConflict(err) {
+		s.logger().Printf("Abandoning address %v: %v", ip, err)
+		s.Abandon(ip, time.Now().Add(DefaultAbandonTime))
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package server4
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+	"github.com/stretchr/testify/require"
+)
+
+// inUseConn is an acd.Conn on a link where another host answers the probes
+// for ip.
+type inUseConn struct {
+	ip      net.IP
+	replies chan *acd.Packet
+}
+
+var (
+	probeHwAddr = net.HardwareAddr{0x52, 0x54, 0x00, 0x00, 0x00, 0x01}
+	otherHwAddr = net.HardwareAddr{0x52, 0x54, 0x00, 0x00, 0x00, 0x02}
+)
+
+func (c *inUseConn) WriteARP(p *acd.Packet) error {
+	if p.TargetIP.Equal(c.ip) {
+		c.replies <- &acd.Packet{
+			Operation:          acd.OperationReply,
+			SenderHardwareAddr: otherHwAddr,
+			SenderIP:           c.ip,
+			TargetHardwareAddr: p.SenderHardwareAddr,
+			TargetIP:           p.SenderIP,
+		}
+	}
+	return nil
+}
+
+func (c *inUseConn) ReadARP() (*acd.Packet, error) {
+	select {
+	case p := <-c.replies:
+		return p, nil
+	default:
+		return nil, &net.OpError{Op: "read", Err: timeoutError{}}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func (c *inUseConn) SetReadDeadline(t time.Time) error { return nil }
+func (c *inUseConn) HardwareAddr() net.HardwareAddr    { return probeHwAddr }
+func (c *inUseConn) Close() error                      { return nil }
+
+func TestServerProbe(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, nil)
+	used := net.IPv4(10, 0, 0, 10)
+	d := acd.NewDetector(&inUseConn{ip: used, replies: make(chan *acd.Packet, 1)})
+	d.Timing = acd.Timing{ProbeNum: 1, AnnounceWait: 10 * time.Millisecond}
+
+	err := s.Probe(context.Background(), d, used)
+	require.True(t, acd.IsConflict(err), "%v", err)
+	require.True(t, s.IsAbandoned(used, time.Now()))
+	require.False(t, s.IsAbandoned(used, time.Now().Add(DefaultAbandonTime)))
+
+	free := net.IPv4(10, 0, 0, 11)
+	require.NoError(t, s.Probe(context.Background(), d, free))
+	require.False(t, s.IsAbandoned(free, time.Now()))
+}
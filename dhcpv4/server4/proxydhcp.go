@@ -0,0 +1,185 @@
+package server4
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/pxe"
+)
+
+// ProxyDHCPPort is the port of the PXE boot servers. After the offer of a
+// proxyDHCP server, PXE clients request their boot file on this port.
+const ProxyDHCPPort = 4011
+
+// ProxyDHCP is a proxyDHCP service, as described in the PXE specification: it
+// answers PXE clients with boot parameters only, alongside the DHCP server of
+// the network, which assigns the addresses. It is made of two handlers, which
+// must be served on the same host: DiscoverHandler on the DHCP server port,
+// which offers the boot menu, or the boot file if there is no menu, and
+// BootHandler on ProxyDHCPPort, which sends the boot file to the clients that
+// request it. NewProxyDHCPServers builds the servers of both.
+type ProxyDHCP struct {
+	// ServerID is the address of the proxyDHCP server, sent as the server
+	// identifier of the replies.
+	ServerID net.IP
+	// Menu, if not nil, is offered to the clients. They request the boot
+	// file of the item the user chooses from the boot servers of the item,
+	// or from the proxyDHCP server if the item has none.
+	Menu *pxe.BootMenu
+	// BootFile returns the TFTP server and the name of the file to boot by
+	// the client that sent request, e.g. depending on pxe.RequestArch. item
+	// is the boot menu item that the client chose, or nil if it was not
+	// offered a menu. It returns false if the client must not be answered.
+	BootFile func(request *dhcpv4.DHCPv4, item *pxe.OptBootItem) (tftpServer net.IP, name string, ok bool)
+}
+
+// NewProxyDHCPServers returns the servers of the handlers of p, listening on
+// all the addresses, on the DHCP server port and on ProxyDHCPPort. The host
+// must not run a DHCP server of its own, which would listen on the same port.
+func NewProxyDHCPServers(p *ProxyDHCP) (dhcp *Server, boot *Server) {
+	dhcp = NewServer(net.UDPAddr{IP: net.IPv4zero, Port: dhcpv4.ServerPort}, p.DiscoverHandler())
+	boot = NewServer(net.UDPAddr{IP: net.IPv4zero, Port: ProxyDHCPPort}, p.BootHandler())
+	return dhcp, boot
+}
+
+// DiscoverHandler returns the handler answering the Discovers of PXE clients
+// with an Offer that has no address, but carries the boot menu, or the boot
+// file if there is no menu. The other messages are ignored, since they are
+// meant for the DHCP server.
+func (p *ProxyDHCP) DiscoverHandler() Handler {
+	return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		if !isPXERequest(m, dhcpv4.MessageTypeDiscover) {
+			return
+		}
+		reply, err := p.newReply(m, dhcpv4.MessageTypeOffer)
+		if err == nil {
+			if p.Menu != nil {
+				err = p.withMenu(reply)
+			} else if !p.withBootFile(m, nil, reply) {
+				return
+			}
+		}
+		if err != nil {
+			rc.logger().Printf("Cannot build the proxyDHCP offer to %v: %v", peer, err)
+			return
+		}
+		if _, err := conn.WriteTo(reply.ToBytes(), staticReplyAddr(m, peer, rc.clientPort())); err != nil {
+			rc.logger().Printf("Cannot send the proxyDHCP offer to %v: %v", peer, err)
+		}
+	}
+}
+
+// BootHandler returns the handler answering the Requests that PXE clients send
+// to ProxyDHCPPort with an Ack carrying their boot file. The Ack is sent back
+// to the address of the client, and echoes the boot menu item it chose.
+func (p *ProxyDHCP) BootHandler() Handler {
+	return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		if !isPXERequest(m, dhcpv4.MessageTypeRequest, dhcpv4.MessageTypeInform) {
+			return
+		}
+		item := requestedBootItem(m)
+		if item != nil && item.Type == pxe.BootServerTypeLocalBoot {
+			// the client boots from its local disk
+			return
+		}
+		reply, err := p.newReply(m, dhcpv4.MessageTypeAck)
+		if err != nil {
+			rc.logger().Printf("Cannot build the boot reply to %v: %v", peer, err)
+			return
+		}
+		if !p.withBootFile(m, item, reply) {
+			return
+		}
+		if item != nil {
+			reply.UpdateOption(&pxe.OptVendorSpecificInformation{Options: []dhcpv4.Option{item}})
+		}
+		if _, err := conn.WriteTo(reply.ToBytes(), peer); err != nil {
+			rc.logger().Printf("Cannot send the boot reply to %v: %v", peer, err)
+		}
+	}
+}
+
+// isPXERequest returns true if m is a request of one of the given types, from
+// a PXE client.
+func isPXERequest(m *dhcpv4.DHCPv4, types ...dhcpv4.MessageType) bool {
+	mt := m.MessageType()
+	if m.Opcode() != dhcpv4.OpcodeBootRequest || mt == nil {
+		return false
+	}
+	class, ok := m.GetOneOption(dhcpv4.OptionClassIdentifier).(*dhcpv4.OptClassIdentifier)
+	if !ok || !strings.HasPrefix(class.Identifier, pxe.PXEClientVendorClassIdentifier) {
+		return false
+	}
+	for _, t := range types {
+		if *mt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedBootItem returns the boot menu item chosen by the client, or nil if
+// the request does not tell it.
+func requestedBootItem(m *dhcpv4.DHCPv4) *pxe.OptBootItem {
+	opts, err := m.VendorOptions(pxe.PXEClientVendorClassIdentifier)
+	if err != nil {
+		return nil
+	}
+	for _, opt := range opts {
+		if item, ok := opt.(*pxe.OptBootItem); ok {
+			return item
+		}
+	}
+	return nil
+}
+
+// newReply returns the reply of the given type to request, without address
+// nor boot parameters. PXE clients require the class identifier, and the
+// client machine identifier they sent, if any.
+func (p *ProxyDHCP) newReply(request *dhcpv4.DHCPv4, replyType dhcpv4.MessageType) (*dhcpv4.DHCPv4, error) {
+	if p.ServerID.To4() == nil {
+		return nil, fmt.Errorf("invalid server identifier %v", p.ServerID)
+	}
+	reply, err := dhcpv4.NewReplyFromRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	reply.UpdateOption(&dhcpv4.OptMessageType{MessageType: replyType})
+	reply.UpdateOption(&dhcpv4.OptServerIdentifier{ServerID: p.ServerID.To4()})
+	reply.UpdateOption(&dhcpv4.OptClassIdentifier{Identifier: pxe.PXEClientVendorClassIdentifier})
+	if guid := request.GetOneOption(dhcpv4.OptionClientMachineIdentifier); guid != nil {
+		reply.UpdateOption(dhcpv4.CloneOption(guid))
+	}
+	return reply, nil
+}
+
+// withMenu adds the boot menu to reply. The PXE clients contact the server
+// address of the reply for the items without boot servers.
+func (p *ProxyDHCP) withMenu(reply *dhcpv4.DHCPv4) error {
+	opt, err := p.Menu.Option()
+	if err != nil {
+		return err
+	}
+	reply.SetServerIPAddr(p.ServerID.To4())
+	reply.UpdateOption(opt)
+	return nil
+}
+
+// withBootFile adds the boot file of the client that sent request to reply,
+// and returns false if there is none.
+func (p *ProxyDHCP) withBootFile(request *dhcpv4.DHCPv4, item *pxe.OptBootItem, reply *dhcpv4.DHCPv4) bool {
+	if p.BootFile == nil {
+		return false
+	}
+	tftpServer, name, ok := p.BootFile(request, item)
+	if !ok {
+		return false
+	}
+	if tftpServer == nil {
+		tftpServer = p.ServerID
+	}
+	pxe.WithBootFile(tftpServer.To4(), name)(reply)
+	return true
+}
@@ -0,0 +1,142 @@
+package server4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/pxe"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+var proxyTestGUID = &dhcpv4.OptClientMachineIdentifier{Identifier: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}}
+
+func proxyTestRequest(t *testing.T, mt dhcpv4.MessageType, class string, opts ...dhcpv4.Option) *dhcpv4.DHCPv4 {
+	m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	m.UpdateOption(&dhcpv4.OptMessageType{MessageType: mt})
+	m.UpdateOption(&dhcpv4.OptClassIdentifier{Identifier: class})
+	m.UpdateOption(proxyTestGUID)
+	for _, opt := range opts {
+		m.UpdateOption(opt)
+	}
+	// what the handlers get from the server
+	m, err = dhcpv4.FromBytes(m.ToBytes())
+	require.NoError(t, err)
+	return m
+}
+
+func proxyTestServer() *ProxyDHCP {
+	return &ProxyDHCP{
+		ServerID: net.IPv4(192, 168, 0, 2),
+		BootFile: func(request *dhcpv4.DHCPv4, item *pxe.OptBootItem) (net.IP, string, bool) {
+			arch, ok := pxe.RequestArch(request)
+			if !ok || arch != iana.EFI_X86_64 {
+				return nil, "", false
+			}
+			if item != nil && item.Type == pxe.BootServerTypeLinuxInstall {
+				return net.IPv4(192, 168, 0, 3), "install.efi", true
+			}
+			return nil, "boot.efi", true
+		},
+	}
+}
+
+func TestProxyDHCPDiscoverHandler(t *testing.T) {
+	p := proxyTestServer()
+	h := p.DiscoverHandler()
+	conn := &recordingConn{}
+	peer := &net.UDPAddr{IP: net.IPv4zero, Port: dhcpv4.ClientPort}
+
+	// only the Discovers of PXE clients are answered
+	h(conn, peer, proxyTestRequest(t, dhcpv4.MessageTypeDiscover, "MSFT 5.0"), nil)
+	h(conn, peer, proxyTestRequest(t, dhcpv4.MessageTypeRequest, "PXEClient:Arch:00009:UNDI:003016"), nil)
+	// no boot file for this architecture
+	h(conn, peer, proxyTestRequest(t, dhcpv4.MessageTypeDiscover, "PXEClient:Arch:00000:UNDI:002001"), nil)
+	require.Empty(t, conn.written)
+
+	discover := proxyTestRequest(t, dhcpv4.MessageTypeDiscover, "PXEClient:Arch:00009:UNDI:003016")
+	h(conn, peer, discover, nil)
+	require.Len(t, conn.written, 1)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ClientPort}, conn.written[0].addr)
+	offer, err := dhcpv4.FromBytes(conn.written[0].data)
+	require.NoError(t, err)
+	require.Equal(t, dhcpv4.MessageTypeOffer, *offer.MessageType())
+	require.Equal(t, discover.TransactionID(), offer.TransactionID())
+	require.True(t, offer.YourIPAddr().IsUnspecified())
+	require.Equal(t, net.IP{192, 168, 0, 2}, offer.ServerIdentifier())
+	require.Equal(t, &dhcpv4.OptClassIdentifier{Identifier: pxe.PXEClientVendorClassIdentifier}, offer.GetOneOption(dhcpv4.OptionClassIdentifier))
+	require.Equal(t, proxyTestGUID.ToBytes(), offer.GetOneOption(dhcpv4.OptionClientMachineIdentifier).ToBytes())
+	require.True(t, offer.ServerIPAddr().Equal(net.IPv4(192, 168, 0, 2)))
+	require.Equal(t, "boot.efi", offer.BootFileName())
+	require.Nil(t, offer.GetOneOption(dhcpv4.OptionIPAddressLeaseTime))
+}
+
+func TestProxyDHCPDiscoverHandlerMenu(t *testing.T) {
+	p := proxyTestServer()
+	p.Menu = pxe.NewBootMenu("Boot", 10).
+		AddItem(pxe.BootServerTypeLocalBoot, "Local disk").
+		AddItem(pxe.BootServerTypeLinuxInstall, "Install Linux")
+	conn := &recordingConn{}
+	p.DiscoverHandler()(conn, &net.UDPAddr{IP: net.IPv4zero, Port: dhcpv4.ClientPort},
+		proxyTestRequest(t, dhcpv4.MessageTypeDiscover, "PXEClient:Arch:00009:UNDI:003016"), nil)
+	require.Len(t, conn.written, 1)
+	offer, err := dhcpv4.FromBytes(conn.written[0].data)
+	require.NoError(t, err)
+	require.True(t, offer.ServerIPAddr().Equal(net.IPv4(192, 168, 0, 2)))
+	require.Equal(t, "", offer.BootFileName())
+
+	opts, err := offer.VendorOptions("")
+	require.NoError(t, err)
+	var menu *pxe.OptBootMenu
+	for _, opt := range opts {
+		if m, ok := opt.(*pxe.OptBootMenu); ok {
+			menu = m
+		}
+	}
+	require.NotNil(t, menu)
+	require.Equal(t, p.Menu.Items(), menu.Items)
+
+	// an invalid menu is not sent
+	conn = &recordingConn{}
+	p.Menu = pxe.NewBootMenu("Boot", 10)
+	p.DiscoverHandler()(conn, &net.UDPAddr{IP: net.IPv4zero, Port: dhcpv4.ClientPort},
+		proxyTestRequest(t, dhcpv4.MessageTypeDiscover, "PXEClient:Arch:00009:UNDI:003016"), nil)
+	require.Empty(t, conn.written)
+}
+
+func TestProxyDHCPBootHandler(t *testing.T) {
+	h := proxyTestServer().BootHandler()
+	conn := &recordingConn{}
+	peer := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: dhcpv4.ClientPort}
+	class := "PXEClient:Arch:00009:UNDI:003016"
+	bootItem := func(typ pxe.BootServerType) dhcpv4.Option {
+		return &pxe.OptVendorSpecificInformation{Options: []dhcpv4.Option{&pxe.OptBootItem{Type: typ}}}
+	}
+
+	// Discovers and local boot items are not answered
+	h(conn, peer, proxyTestRequest(t, dhcpv4.MessageTypeDiscover, class), nil)
+	h(conn, peer, proxyTestRequest(t, dhcpv4.MessageTypeRequest, class, bootItem(pxe.BootServerTypeLocalBoot)), nil)
+	require.Empty(t, conn.written)
+
+	h(conn, peer, proxyTestRequest(t, dhcpv4.MessageTypeRequest, class, bootItem(pxe.BootServerTypeLinuxInstall)), nil)
+	require.Len(t, conn.written, 1)
+	require.Equal(t, peer, conn.written[0].addr)
+	ack, err := dhcpv4.FromBytes(conn.written[0].data)
+	require.NoError(t, err)
+	require.Equal(t, dhcpv4.MessageTypeAck, *ack.MessageType())
+	require.True(t, ack.YourIPAddr().IsUnspecified())
+	require.True(t, ack.ServerIPAddr().Equal(net.IPv4(192, 168, 0, 3)))
+	require.Equal(t, "install.efi", ack.BootFileName())
+	opts, err := ack.VendorOptions("")
+	require.NoError(t, err)
+	require.Contains(t, opts, &pxe.OptBootItem{Type: pxe.BootServerTypeLinuxInstall})
+
+	// without boot item, the boot file is the one sent without menu
+	h(conn, peer, proxyTestRequest(t, dhcpv4.MessageTypeRequest, class), nil)
+	require.Len(t, conn.written, 2)
+	ack, err = dhcpv4.FromBytes(conn.written[1].data)
+	require.NoError(t, err)
+	require.Equal(t, "boot.efi", ack.BootFileName())
+}
@@ -0,0 +1,304 @@
+package server4
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+)
+
+// ErrNotAllocated is returned when renewing an address that is not allocated
+// to the client.
+var ErrNotAllocated = errors.New("address not allocated to the client")
+
+// DefaultConflictTime is how long a RangeAllocator skips the addresses found in
+// use by another host.
+const DefaultConflictTime = time.Hour
+
+// DefaultOfferHoldTime is how long a RangeAllocator holds the addresses it
+// allocates for the clients to renew them, as ISC dhcpd holds its offers.
+const DefaultOfferHoldTime = 2 * time.Minute
+
+// RangeAllocator is an Allocator keeping its own record of the addresses it
+// allocates from a range, independently of the leases of a server: an address
+// is held for a client for OfferHoldTime, so that the Discovers that are not
+// followed by a request do not exhaust the range, then, once the client
+// renews it, for LeaseTime or until it is released. Unlike PoolAllocator, it
+// can check that the addresses are not in use by hosts it does not know of
+// before allocating them, and it hands out the free addresses in turn rather
+// than the lowest one, so that the addresses released recently are reused
+// last. It can be built with NewRangeAllocator, or as a struct literal
+// setting at least Pool.
+type RangeAllocator struct {
+	Pool *Pool
+	// Probe, if not nil, checks that an address is not in use before it is
+	// allocated to a client for the first time, e.g. the Probe method of an
+	// acd.Detector. The addresses for which it returns an
+	// *acd.ConflictError are skipped for ConflictTime, other errors are
+	// returned by Allocate.
+	Probe func(ctx context.Context, ip net.IP) error
+	// ConflictTime is how long the addresses found in use are skipped. If
+	// zero, DefaultConflictTime is used.
+	ConflictTime time.Duration
+	// OfferHoldTime is how long an address allocated to a client stays
+	// allocated if the client does not renew it, typically after an offer
+	// that it does not take. If zero, DefaultOfferHoldTime is used.
+	OfferHoldTime time.Duration
+	// LeaseTime is how long a renewed address stays allocated until the
+	// next renewal, typically the lease time of the server. If zero, it
+	// stays allocated until it is released.
+	LeaseTime time.Duration
+
+	lock      sync.Mutex
+	byHWAddr  map[string]uint32
+	byIP      map[uint32]string
+	conflicts map[uint32]time.Time
+	// expires holds the end of the allocations that are not held until
+	// released
+	expires map[uint32]time.Time
+	next    uint32
+}
+
+// NewRangeAllocator returns a RangeAllocator allocating the addresses of p,
+// with the DefaultConflictTime, the DefaultOfferHoldTime and no probe. The
+// renewed addresses stay allocated until released.
+func NewRangeAllocator(p *Pool) *RangeAllocator {
+	return &RangeAllocator{
+		Pool:          p,
+		ConflictTime:  DefaultConflictTime,
+		OfferHoldTime: DefaultOfferHoldTime,
+		next:          ipKey(p.Start),
+	}
+}
+
+func (a *RangeAllocator) conflictTime() time.Duration {
+	if a.ConflictTime == 0 {
+		return DefaultConflictTime
+	}
+	return a.ConflictTime
+}
+
+func (a *RangeAllocator) offerHoldTime() time.Duration {
+	if a.OfferHoldTime == 0 {
+		return DefaultOfferHoldTime
+	}
+	return a.OfferHoldTime
+}
+
+// initMaps creates the maps of the allocator, if it was built as a struct
+// literal. It must be called with the lock held before adding to them.
+func (a *RangeAllocator) initMaps() {
+	if a.byHWAddr == nil {
+		a.byHWAddr = make(map[string]uint32)
+		a.byIP = make(map[uint32]string)
+		a.conflicts = make(map[uint32]time.Time)
+		a.expires = make(map[uint32]time.Time)
+	}
+}
+
+// Allocate implements Allocator.Allocate. The client keeps its address, or
+// gets its hint if it is free, or the next free address of the range. Unless
+// the address was renewed, it is held for OfferHoldTime from now.
+func (a *RangeAllocator) Allocate(ctx context.Context, req AllocationRequest) (net.IP, error) {
+	hk := string(req.HWAddr)
+	for {
+		a.lock.Lock()
+		a.initMaps()
+		now := time.Now()
+		if k, ok := a.allocated(hk, now); ok {
+			if _, held := a.expires[k]; held {
+				a.expires[k] = a.later(k, now.Add(a.offerHoldTime()))
+			}
+			a.lock.Unlock()
+			return keyToIP(k), nil
+		}
+		k, ok := a.pick(req.Hint, now)
+		if !ok {
+			a.lock.Unlock()
+			return nil, ErrNoAddress
+		}
+		// the address is reserved while it is probed, so that it is not
+		// picked for another client meanwhile
+		a.byHWAddr[hk], a.byIP[k] = k, hk
+		a.expires[k] = now.Add(a.offerHoldTime())
+		a.lock.Unlock()
+
+		err := a.probe(ctx, keyToIP(k))
+		if err == nil {
+			return keyToIP(k), nil
+		}
+		a.lock.Lock()
+		a.release(hk, k)
+		if acd.IsConflict(err) {
+			a.conflicts[k] = time.Now().Add(a.conflictTime())
+		}
+		a.lock.Unlock()
+		if !acd.IsConflict(err) {
+			return nil, err
+		}
+	}
+}
+
+// pick returns a free address, the hint if possible, or false if there is
+// none. It must be called with the lock held.
+func (a *RangeAllocator) pick(hint net.IP, now time.Time) (uint32, bool) {
+	if hint != nil && a.Pool.Contains(hint) && a.free(ipKey(hint), now) {
+		return ipKey(hint), true
+	}
+	start, end := ipKey(a.Pool.Start), ipKey(a.Pool.End)
+	k := a.next
+	if k < start || k > end {
+		k = start
+	}
+	for n := uint64(end-start) + 1; n > 0; n-- {
+		current := k
+		if k == end {
+			k = start
+		} else {
+			k++
+		}
+		if a.free(current, now) {
+			a.next = k
+			return current, true
+		}
+	}
+	return 0, false
+}
+
+// allocated returns the address allocated to hk at the given time, if any,
+// releasing it if its allocation expired. It must be called with the lock
+// held.
+func (a *RangeAllocator) allocated(hk string, now time.Time) (uint32, bool) {
+	k, ok := a.byHWAddr[hk]
+	if !ok {
+		return 0, false
+	}
+	if expires, held := a.expires[k]; held && !now.Before(expires) {
+		a.release(hk, k)
+		return 0, false
+	}
+	return k, true
+}
+
+// later returns t, or the end of the allocation of k if it is later. It must
+// be called with the lock held.
+func (a *RangeAllocator) later(k uint32, t time.Time) time.Time {
+	if expires := a.expires[k]; expires.After(t) {
+		return expires
+	}
+	return t
+}
+
+// free returns true if k is neither allocated nor in conflict at the given
+// time. It must be called with the lock held.
+func (a *RangeAllocator) free(k uint32, now time.Time) bool {
+	if hk, ok := a.byIP[k]; ok {
+		if _, ok := a.allocated(hk, now); ok {
+			return false
+		}
+	}
+	if until, ok := a.conflicts[k]; ok {
+		if now.Before(until) {
+			return false
+		}
+		delete(a.conflicts, k)
+	}
+	return true
+}
+
+func (a *RangeAllocator) probe(ctx context.Context, ip net.IP) error {
+	if a.Probe == nil {
+		return nil
+	}
+	return a.Probe(ctx, ip)
+}
+
+// release forgets the allocation of k to hk. It must be called with the lock
+// held.
+func (a *RangeAllocator) release(hk string, k uint32) {
+	delete(a.byHWAddr, hk)
+	delete(a.byIP, k)
+	delete(a.expires, k)
+}
+
+// Reserve allocates ip to a client until it is released, e.g. to restore the
+// allocations from the leases of a server after a restart. It fails with
+// ErrAddressInUse if ip is allocated to another client.
+func (a *RangeAllocator) Reserve(hwaddr net.HardwareAddr, ip net.IP) error {
+	if !a.Pool.Contains(ip) {
+		return fmt.Errorf("address %v is not in %v", ip, a.Pool)
+	}
+	hk, k := string(hwaddr), ipKey(ip)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.initMaps()
+	if owner, ok := a.byIP[k]; ok && owner != hk {
+		if _, ok := a.allocated(owner, time.Now()); ok {
+			return ErrAddressInUse
+		}
+	}
+	if old, ok := a.byHWAddr[hk]; ok {
+		a.release(hk, old)
+	}
+	a.byHWAddr[hk], a.byIP[k] = k, hk
+	delete(a.expires, k)
+	return nil
+}
+
+// Renew implements Renewer.Renew. The address stays allocated to the client
+// for LeaseTime from now, or until it is released if LeaseTime is zero.
+func (a *RangeAllocator) Renew(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.initMaps()
+	now := time.Now()
+	k, ok := a.allocated(string(hwaddr), now)
+	if !ok || k != ipKey(ip) {
+		return ErrNotAllocated
+	}
+	if a.LeaseTime > 0 {
+		a.expires[k] = now.Add(a.LeaseTime)
+	} else {
+		delete(a.expires, k)
+	}
+	return nil
+}
+
+// Release implements Allocator.Release.
+func (a *RangeAllocator) Release(ctx context.Context, hwaddr net.HardwareAddr, ip net.IP) error {
+	hk := string(hwaddr)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if k, ok := a.byHWAddr[hk]; ok && k == ipKey(ip) {
+		a.release(hk, k)
+	}
+	return nil
+}
+
+// Conflict marks ip as in use by another host for ConflictTime, e.g. because a
+// client declined it, and releases it if it was allocated.
+func (a *RangeAllocator) Conflict(ip net.IP) {
+	k := ipKey(ip)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.initMaps()
+	if hk, ok := a.byIP[k]; ok {
+		a.release(hk, k)
+	}
+	a.conflicts[k] = time.Now().Add(a.conflictTime())
+}
+
+// Len returns the number of allocated addresses.
+func (a *RangeAllocator) Len() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	now := time.Now()
+	for _, hk := range a.byIP {
+		a.allocated(hk, now)
+	}
+	return len(a.byIP)
+}
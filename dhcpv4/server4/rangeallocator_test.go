@@ -0,0 +1,158 @@
+package server4
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/acd"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRangeAllocator(t *testing.T) *RangeAllocator {
+	p, err := NewPool(net.IPv4(10, 0, 0, 10), net.IPv4(10, 0, 0, 12))
+	require.NoError(t, err)
+	return NewRangeAllocator(p)
+}
+
+func TestRangeAllocator(t *testing.T) {
+	a := newTestRangeAllocator(t)
+	ctx := context.Background()
+	hwaddr3 := net.HardwareAddr{0, 1, 2, 3, 4, 3}
+
+	ip, err := a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+	// the client keeps its address
+	ip, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1, Hint: net.IPv4(10, 0, 0, 12)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+
+	// the hint is honoured if it is free
+	ip, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2, Hint: net.IPv4(10, 0, 0, 10)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 11}, ip)
+	require.NoError(t, a.Renew(ctx, allocHwAddr2, ip))
+	require.Equal(t, ErrNotAllocated, a.Renew(ctx, allocHwAddr2, net.IPv4(10, 0, 0, 10)))
+
+	ip, err = a.Allocate(ctx, AllocationRequest{HWAddr: hwaddr3})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 12}, ip)
+	_, err = a.Allocate(ctx, AllocationRequest{HWAddr: net.HardwareAddr{0, 1, 2, 3, 4, 4}})
+	require.Equal(t, ErrNoAddress, err)
+	require.Equal(t, 3, a.Len())
+
+	// released addresses are reused in turn
+	require.NoError(t, a.Release(ctx, allocHwAddr2, net.IPv4(10, 0, 0, 11)))
+	require.NoError(t, a.Release(ctx, allocHwAddr1, net.IPv4(10, 0, 0, 10)))
+	ip, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+}
+
+func TestRangeAllocatorLiteral(t *testing.T) {
+	p, err := NewPool(net.IPv4(10, 0, 0, 10), net.IPv4(10, 0, 0, 12))
+	require.NoError(t, err)
+	a := &RangeAllocator{Pool: p}
+	ctx := context.Background()
+
+	ip, err := a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+	require.NoError(t, a.Renew(ctx, allocHwAddr1, ip))
+	require.Equal(t, 1, a.Len())
+
+	a = &RangeAllocator{Pool: p}
+	require.NoError(t, a.Reserve(allocHwAddr1, net.IPv4(10, 0, 0, 11)))
+	a = &RangeAllocator{Pool: p}
+	a.Conflict(net.IPv4(10, 0, 0, 10))
+	ip, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 11}, ip)
+}
+
+func TestRangeAllocatorConflicts(t *testing.T) {
+	a := newTestRangeAllocator(t)
+	ctx := context.Background()
+	probed := []net.IP{}
+	a.Probe = func(ctx context.Context, ip net.IP) error {
+		probed = append(probed, ip)
+		if ip.Equal(net.IPv4(10, 0, 0, 10)) {
+			return &acd.ConflictError{IP: ip}
+		}
+		return nil
+	}
+
+	ip, err := a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 11}, ip)
+	require.Equal(t, []net.IP{{10, 0, 0, 10}, {10, 0, 0, 11}}, probed)
+	// the address in conflict is skipped, even as hint
+	ip, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2, Hint: net.IPv4(10, 0, 0, 10)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 12}, ip)
+
+	// declined addresses are released
+	a.Conflict(ip)
+	require.Equal(t, 1, a.Len())
+	_, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2})
+	require.Equal(t, ErrNoAddress, err)
+
+	// the other errors of the probe are returned
+	a = newTestRangeAllocator(t)
+	probeErr := errors.New("no link")
+	a.Probe = func(ctx context.Context, ip net.IP) error {
+		return probeErr
+	}
+	_, err = a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.Equal(t, probeErr, err)
+	require.Equal(t, 0, a.Len())
+}
+
+func TestRangeAllocatorReserve(t *testing.T) {
+	a := newTestRangeAllocator(t)
+	require.NoError(t, a.Reserve(allocHwAddr1, net.IPv4(10, 0, 0, 11)))
+	require.Equal(t, ErrAddressInUse, a.Reserve(allocHwAddr2, net.IPv4(10, 0, 0, 11)))
+	require.Error(t, a.Reserve(allocHwAddr2, net.IPv4(10, 0, 0, 13)))
+	// moves the client to another address
+	require.NoError(t, a.Reserve(allocHwAddr1, net.IPv4(10, 0, 0, 12)))
+	require.Equal(t, 1, a.Len())
+
+	ip, err := a.Allocate(context.Background(), AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 12}, ip)
+}
+
+func TestRangeAllocatorOfferHoldTime(t *testing.T) {
+	a := newTestRangeAllocator(t)
+	a.OfferHoldTime = 50 * time.Millisecond
+	ctx := context.Background()
+	// clients that never renew
+	for i := byte(0); i < 3; i++ {
+		_, err := a.Allocate(ctx, AllocationRequest{HWAddr: net.HardwareAddr{0, 1, 2, 3, 5, i}})
+		require.NoError(t, err)
+	}
+	_, err := a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.Equal(t, ErrNoAddress, err)
+
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, 0, a.Len())
+	ip, err := a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	// renewed addresses are kept
+	require.NoError(t, a.Renew(ctx, allocHwAddr1, ip))
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, 1, a.Len())
+	got, err := a.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1})
+	require.NoError(t, err)
+	require.Equal(t, ip, got)
+
+	// for the lease time
+	a.LeaseTime = 50 * time.Millisecond
+	require.NoError(t, a.Renew(ctx, allocHwAddr1, ip))
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, ErrNotAllocated, a.Renew(ctx, allocHwAddr1, ip))
+	require.Equal(t, 0, a.Len())
+}
@@ -0,0 +1,331 @@
+package server4
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// The middlewares below protect the server against starvation attacks, where
+// a host sends Discovers from a flood of random hardware addresses to exhaust
+// the pools. Limiting the rate per hardware address only stops the clients
+// that misbehave, the rate per relay agent and the offers per relay circuit
+// bound what a single port, or link, can get.
+
+// bucket is the token bucket of a key of a RateLimiter.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// limited is true once a message was dropped, until one is allowed
+	limited bool
+}
+
+// RateLimiter limits the rate of the messages of each key, e.g. of each
+// client, with a token bucket: a key can send burst messages at once, then
+// rate messages per second. It is used through the RateLimitHwAddr and
+// RateLimitGatewayIPAddr middlewares.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	lock    sync.Mutex
+	buckets map[string]*bucket
+	pruned  time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing rate messages per second, and
+// bursts of burst messages, to each key. The rate must be positive, and the
+// bursts of at least one message.
+func NewRateLimiter(rate float64, burst int) (*RateLimiter, error) {
+	if !(rate > 0) {
+		return nil, errors.New("rate limit must be positive")
+	}
+	if burst < 1 {
+		return nil, errors.New("burst must be at least one message")
+	}
+	return &RateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket)}, nil
+}
+
+// Allow returns true if key can send a message at the given time, and takes
+// the message into account.
+func (l *RateLimiter) Allow(key string, now time.Time) bool {
+	ok, _ := l.allow(key, now)
+	return ok
+}
+
+// allow is Allow, also returning true if the message is the first one dropped
+// since the last one allowed, so that a flood is only logged once.
+func (l *RateLimiter) allow(key string, now time.Time) (bool, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	// the buckets that are full again are dropped, once per refill time
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.pruned) >= refill {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) >= refill {
+				delete(l.buckets, k)
+			}
+		}
+		l.pruned = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		first := !b.limited
+		b.limited = true
+		return false, first
+	}
+	b.tokens--
+	b.limited = false
+	return true, false
+}
+
+// Len returns the number of keys whose bucket is not full, give or take the
+// ones not pruned yet.
+func (l *RateLimiter) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.buckets)
+}
+
+// rateLimit returns a middleware dropping the messages of the keys exceeding
+// the rate of l.
+func rateLimit(l *RateLimiter, what string, key func(m *dhcpv4.DHCPv4) string) Middleware {
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			k := key(m)
+			ok, first := l.allow(k, time.Now())
+			if !ok {
+				if first {
+					rc.logger().Printf("Dropping messages from %s %s: rate limit exceeded", what, k)
+				}
+				return
+			}
+			next(conn, peer, m, rc)
+		}
+	}
+}
+
+// RateLimitHwAddr returns a middleware that drops the messages of the clients
+// sending them faster than l allows, by client hardware address.
+func RateLimitHwAddr(l *RateLimiter) Middleware {
+	return rateLimit(l, "client", func(m *dhcpv4.DHCPv4) string {
+		return m.ClientHwAddr().String()
+	})
+}
+
+// RateLimitGatewayIPAddr returns a middleware that drops the messages relayed
+// by a relay agent faster than l allows, by giaddr. The messages of the local
+// clients, without giaddr, share the limit of the 0.0.0.0 giaddr.
+func RateLimitGatewayIPAddr(l *RateLimiter) Middleware {
+	return rateLimit(l, "relay", func(m *dhcpv4.DHCPv4) string {
+		return m.GatewayIPAddr().String()
+	})
+}
+
+// OfferLimiter caps the number of outstanding offers, that is offers not
+// followed by a request of the client yet, on each relay circuit, so that a
+// single port cannot get addresses offered faster than its clients take
+// them. It is used through the LimitOffers middleware.
+type OfferLimiter struct {
+	max     int
+	timeout time.Duration
+
+	lock     sync.Mutex
+	circuits map[string]map[offerKey]time.Time
+	// full holds the circuits on which a Discover was dropped, until one
+	// is admitted
+	full   map[string]bool
+	pruned time.Time
+}
+
+// NewOfferLimiter returns an OfferLimiter allowing max outstanding offers per
+// circuit. The offers are outstanding until the client sends a request,
+// declines or releases an address, or until timeout, typically the
+// ExchangeTimeout of the server. The maximum must be at least one offer, and
+// the timeout positive.
+func NewOfferLimiter(max int, timeout time.Duration) (*OfferLimiter, error) {
+	if max < 1 {
+		return nil, errors.New("maximum of outstanding offers must be at least one")
+	}
+	if timeout <= 0 {
+		return nil, errors.New("offer timeout must be positive")
+	}
+	return &OfferLimiter{
+		max:      max,
+		timeout:  timeout,
+		circuits: make(map[string]map[offerKey]time.Time),
+		full:     make(map[string]bool),
+	}, nil
+}
+
+// circuitKey returns the circuit a message comes from: the giaddr and the
+// circuit ID given by the relay agent, if any. The local clients share the
+// circuit with no giaddr, whatever relay agent information they send, since
+// they could otherwise pick a new circuit for each Discover.
+func circuitKey(m *dhcpv4.DHCPv4) string {
+	giaddr := m.GatewayIPAddr()
+	if giaddr == nil || giaddr.IsUnspecified() {
+		return "0.0.0.0/"
+	}
+	var circuitID []byte
+	if opt, ok := m.GetOneOption(dhcpv4.OptionRelayAgentInformation).(*dhcpv4.OptRelayAgentInformation); ok {
+		circuitID = opt.CircuitID()
+	}
+	return fmt.Sprintf("%v/%x", giaddr, circuitID)
+}
+
+// admit returns true if an offer can be made in response to discover, either
+// because it is a retransmission of a Discover already answered, or because
+// the circuit has less than max outstanding offers. It also returns true if the
+// Discover is the first one dropped since the last one admitted. It drops the
+// offers of the circuit expired at the given time and, once per timeout, those
+// of all the circuits.
+func (l *OfferLimiter) admit(circuit string, discover *dhcpv4.DHCPv4, now time.Time) (bool, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if now.Sub(l.pruned) >= l.timeout {
+		for c := range l.circuits {
+			l.expire(c, now)
+		}
+		for c := range l.full {
+			if _, ok := l.circuits[c]; !ok {
+				delete(l.full, c)
+			}
+		}
+		l.pruned = now
+	} else {
+		l.expire(circuit, now)
+	}
+	offers := l.circuits[circuit]
+	_, retransmitted := offers[exchangeKey(discover)]
+	if retransmitted || len(offers) < l.max {
+		delete(l.full, circuit)
+		return true, false
+	}
+	first := !l.full[circuit]
+	l.full[circuit] = true
+	return false, first
+}
+
+// expire drops the offers of a circuit expired at the given time, and the
+// circuit if it has no offer left. It must be called with the lock held.
+func (l *OfferLimiter) expire(circuit string, now time.Time) {
+	offers, ok := l.circuits[circuit]
+	if !ok {
+		return
+	}
+	for k, expires := range offers {
+		if !now.Before(expires) {
+			delete(offers, k)
+		}
+	}
+	if len(offers) == 0 {
+		delete(l.circuits, circuit)
+	}
+}
+
+// offered records an outstanding offer on a circuit.
+func (l *OfferLimiter) offered(circuit string, offer *dhcpv4.DHCPv4, now time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	offers, ok := l.circuits[circuit]
+	if !ok {
+		offers = make(map[offerKey]time.Time)
+		l.circuits[circuit] = offers
+	}
+	offers[exchangeKey(offer)] = now.Add(l.timeout)
+}
+
+// answered forgets the outstanding offers made to a client on a circuit.
+func (l *OfferLimiter) answered(circuit string, hwaddr net.HardwareAddr) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	offers := l.circuits[circuit]
+	for k := range offers {
+		if k.hwaddr == string(hwaddr) {
+			delete(offers, k)
+		}
+	}
+	if len(offers) == 0 {
+		delete(l.circuits, circuit)
+	}
+}
+
+// Outstanding returns the number of outstanding offers on all the circuits,
+// expired ones included.
+func (l *OfferLimiter) Outstanding() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var n int
+	for _, offers := range l.circuits {
+		n += len(offers)
+	}
+	return n
+}
+
+// offerCounter records in an OfferLimiter the offers written to the
+// connection.
+type offerCounter struct {
+	net.PacketConn
+	limiter *OfferLimiter
+	circuit string
+}
+
+func (c offerCounter) WriteTo(p []byte, addr net.Addr) (int, error) {
+	n, err := c.PacketConn.WriteTo(p, addr)
+	if err != nil {
+		return n, err
+	}
+	if m, perr := dhcpv4.FromBytes(p); perr == nil {
+		if mt := m.MessageType(); mt != nil && *mt == dhcpv4.MessageTypeOffer {
+			c.limiter.offered(c.circuit, m, time.Now())
+		}
+	}
+	return n, err
+}
+
+// LimitOffers returns a middleware that drops the Discovers received on the
+// relay circuits with as many outstanding offers as l allows. The offers that
+// the handler sends are counted, and the requests, declines and releases of
+// the clients end their exchanges.
+func LimitOffers(l *OfferLimiter) Middleware {
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			mt := m.MessageType()
+			if mt == nil {
+				next(conn, peer, m, rc)
+				return
+			}
+			circuit := circuitKey(m)
+			switch *mt {
+			case dhcpv4.MessageTypeDiscover:
+				ok, first := l.admit(circuit, m, time.Now())
+				if !ok {
+					if first {
+						rc.logger().Printf("Dropping Discovers on circuit %s: too many outstanding offers", circuit)
+					}
+					return
+				}
+				conn = offerCounter{PacketConn: conn, limiter: l, circuit: circuit}
+			case dhcpv4.MessageTypeRequest, dhcpv4.MessageTypeDecline, dhcpv4.MessageTypeRelease:
+				l.answered(circuit, m.ClientHwAddr())
+			}
+			next(conn, peer, m, rc)
+		}
+	}
+}
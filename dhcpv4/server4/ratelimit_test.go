@@ -0,0 +1,157 @@
+package server4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l, err := NewRateLimiter(1, 2)
+	require.NoError(t, err)
+	now := time.Now()
+	require.True(t, l.Allow("a", now))
+	require.True(t, l.Allow("a", now))
+	require.False(t, l.Allow("a", now))
+	require.True(t, l.Allow("b", now))
+
+	// one message per second
+	require.False(t, l.Allow("a", now.Add(500*time.Millisecond)))
+	require.True(t, l.Allow("a", now.Add(time.Second)))
+	require.False(t, l.Allow("a", now.Add(time.Second)))
+	require.Equal(t, 2, l.Len())
+
+	// the full buckets are dropped
+	require.True(t, l.Allow("c", now.Add(time.Hour)))
+	require.Equal(t, 1, l.Len())
+}
+
+func TestNewRateLimiterInvalid(t *testing.T) {
+	_, err := NewRateLimiter(0, 1)
+	require.Error(t, err)
+	_, err = NewRateLimiter(1, 0)
+	require.Error(t, err)
+}
+
+func TestRateLimitHwAddr(t *testing.T) {
+	var count int
+	l, err := NewRateLimiter(0.001, 1)
+	require.NoError(t, err)
+	h := RateLimitHwAddr(l)(countingHandler(&count))
+	m1, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	m2, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 7})
+	require.NoError(t, err)
+	h(nil, nil, m1, nil)
+	h(nil, nil, m1, nil)
+	h(nil, nil, m2, nil)
+	require.Equal(t, 2, count)
+}
+
+func TestRateLimitGatewayIPAddr(t *testing.T) {
+	var count int
+	l, err := NewRateLimiter(0.001, 1)
+	require.NoError(t, err)
+	h := RateLimitGatewayIPAddr(l)(countingHandler(&count))
+	for i := byte(0); i < 3; i++ {
+		m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, i})
+		require.NoError(t, err)
+		m.SetGatewayIPAddr(net.IPv4(10, 0, 0, 1))
+		h(nil, nil, m, nil)
+	}
+	m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	h(nil, nil, m, nil)
+	require.Equal(t, 2, count)
+}
+
+func TestLimitOffers(t *testing.T) {
+	handler := func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		if *m.MessageType() != dhcpv4.MessageTypeDiscover {
+			return
+		}
+		offer, err := dhcpv4.NewReplyFromRequest(m, dhcpv4.WithMessageType(dhcpv4.MessageTypeOffer))
+		require.NoError(t, err)
+		conn.WriteTo(offer.ToBytes(), peer)
+	}
+	l, err := NewOfferLimiter(2, time.Minute)
+	require.NoError(t, err)
+	h := LimitOffers(l)(handler)
+	conn := &recordingConn{}
+	discover := func(last byte, circuitID string) *dhcpv4.DHCPv4 {
+		m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, last})
+		require.NoError(t, err)
+		m.SetGatewayIPAddr(net.IPv4(10, 0, 0, 1))
+		info := dhcpv4.OptRelayAgentInformation{}
+		info.AddSubOption(dhcpv4.RelayAgentCircuitID, []byte(circuitID))
+		m.AddOption(&info)
+		return m
+	}
+
+	first := discover(1, "eth1")
+	h(conn, nil, first, nil)
+	h(conn, nil, discover(2, "eth1"), nil)
+	h(conn, nil, discover(3, "eth1"), nil)
+	require.Len(t, conn.written, 2)
+	require.Equal(t, 2, l.Outstanding())
+
+	// retransmissions and other circuits are answered
+	h(conn, nil, first, nil)
+	h(conn, nil, discover(3, "eth2"), nil)
+	require.Len(t, conn.written, 4)
+
+	// the request of a client ends its exchange
+	request := first.Clone()
+	request.UpdateOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeRequest})
+	h(conn, nil, request, nil)
+	h(conn, nil, discover(3, "eth1"), nil)
+	require.Len(t, conn.written, 5)
+	require.Equal(t, 3, l.Outstanding())
+}
+
+func TestCircuitKey(t *testing.T) {
+	m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	info := dhcpv4.OptRelayAgentInformation{}
+	info.AddSubOption(dhcpv4.RelayAgentCircuitID, []byte("eth1"))
+	m.AddOption(&info)
+	// the local clients cannot pick their circuit
+	require.Equal(t, "0.0.0.0/", circuitKey(m))
+	m.SetGatewayIPAddr(net.IPv4(10, 0, 0, 1))
+	require.Equal(t, "10.0.0.1/65746831", circuitKey(m))
+}
+
+func TestNewOfferLimiterInvalid(t *testing.T) {
+	_, err := NewOfferLimiter(0, time.Minute)
+	require.Error(t, err)
+	_, err = NewOfferLimiter(1, 0)
+	require.Error(t, err)
+}
+
+func TestOfferLimiterPrunes(t *testing.T) {
+	l, err := NewOfferLimiter(1, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+	m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	other, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 7})
+	require.NoError(t, err)
+	for _, circuit := range []string{"a", "b"} {
+		ok, _ := l.admit(circuit, m, now)
+		require.True(t, ok)
+		l.offered(circuit, m, now)
+		ok, _ = l.admit(circuit, other, now)
+		require.False(t, ok)
+	}
+	require.Len(t, l.circuits, 2)
+	require.Len(t, l.full, 2)
+
+	// the expired offers of all the circuits are dropped, with the circuits
+	ok, _ := l.admit("c", m, now.Add(time.Minute))
+	require.True(t, ok)
+	require.Empty(t, l.circuits)
+	require.Empty(t, l.full)
+}
@@ -0,0 +1,111 @@
+package server4
+
+import (
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// RequestContext holds what is known about a received message besides its
+// content, and what can be derived from it, so that handlers and middlewares
+// do not have to find it out each on their own. Middlewares may fill in
+// Classes for the ones that follow.
+type RequestContext struct {
+	// IfIndex is the index of the interface on which the message was
+	// received, or 0 if unknown.
+	IfIndex int
+	// LocalAddr is the address the message was sent to, e.g. the broadcast
+	// address, or nil if unknown.
+	LocalAddr net.IP
+	// Peer is the address and port the message was sent from, that is the
+	// ones of the client or of the last relay agent.
+	Peer *net.UDPAddr
+	// VLAN is the VLAN ID on which the message was received, or 0 if
+	// unknown, as is the case with the UDP sockets of Server.
+	VLAN uint16
+	// Received is when the message was received.
+	Received time.Time
+
+	// GatewayIPAddr is the address of the relay agent closest to the
+	// client, or nil if the message was not relayed.
+	GatewayIPAddr net.IP
+	// RelayAgentInfo is the relay agent information option added by the
+	// relay agents, or nil if there is none.
+	RelayAgentInfo *dhcpv4.OptRelayAgentInformation
+
+	// MessageType is the DHCP message type, MessageTypeNone for BOOTP
+	// requests.
+	MessageType dhcpv4.MessageType
+	// ClassIdentifier is the vendor class identifier of the client, if any.
+	ClassIdentifier string
+	// Classes are the classes that the middlewares assigned the client to.
+	Classes []string
+
+	// ClientPort is the port to which the replies that are not relayed are
+	// sent to the client, or 0 for dhcpv4.ClientPort. Server sets it to its
+	// own ClientPort.
+	ClientPort int
+
+	// Logger is where the handler and the middlewares report what they do
+	// with the message. Server sets it to its own Logger.
+	Logger dhcpv4.Logger
+}
+
+// NewRequestContext returns the RequestContext of a message received from peer
+// at the given time, with the fields derived from the message filled in.
+func NewRequestContext(peer net.Addr, m *dhcpv4.DHCPv4, received time.Time) *RequestContext {
+	rc := RequestContext{Received: received}
+	if addr, ok := peer.(*net.UDPAddr); ok {
+		rc.Peer = addr
+	}
+	if giaddr := m.GatewayIPAddr(); giaddr != nil && !giaddr.IsUnspecified() {
+		rc.GatewayIPAddr = giaddr
+	}
+	if opt, ok := m.GetOneOption(dhcpv4.OptionRelayAgentInformation).(*dhcpv4.OptRelayAgentInformation); ok {
+		rc.RelayAgentInfo = opt
+	}
+	if mt := m.MessageType(); mt != nil {
+		rc.MessageType = *mt
+	}
+	if opt, ok := m.GetOneOption(dhcpv4.OptionClassIdentifier).(*dhcpv4.OptClassIdentifier); ok {
+		rc.ClassIdentifier = opt.Identifier
+	}
+	return &rc
+}
+
+// Relayed returns true if the message was relayed.
+func (rc *RequestContext) Relayed() bool {
+	return rc.GatewayIPAddr != nil
+}
+
+// Interface returns the interface on which the message was received.
+func (rc *RequestContext) Interface() (*net.Interface, error) {
+	return net.InterfaceByIndex(rc.IfIndex)
+}
+
+// HasClass returns true if the client was assigned to the given class.
+func (rc *RequestContext) HasClass(class string) bool {
+	for _, c := range rc.Classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// logger returns the Logger of the request context, or dhcpv4.DefaultLogger if
+// there is none.
+func (rc *RequestContext) clientPort() int {
+	if rc == nil || rc.ClientPort == 0 {
+		return dhcpv4.ClientPort
+	}
+	return rc.ClientPort
+}
+
+func (rc *RequestContext) logger() dhcpv4.Logger {
+	if rc == nil || rc.Logger == nil {
+		return dhcpv4.DefaultLogger
+	}
+	return rc.Logger
+}
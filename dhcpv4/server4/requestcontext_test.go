@@ -0,0 +1,85 @@
+package server4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequestContext(t *testing.T) {
+	m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	peer := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: dhcpv4.ClientPort}
+	now := time.Now()
+
+	rc := NewRequestContext(peer, m, now)
+	require.Equal(t, peer, rc.Peer)
+	require.Equal(t, now, rc.Received)
+	require.Equal(t, dhcpv4.MessageTypeDiscover, rc.MessageType)
+	require.False(t, rc.Relayed())
+	require.Nil(t, rc.RelayAgentInfo)
+	require.Empty(t, rc.ClassIdentifier)
+
+	m.SetGatewayIPAddr(net.IPv4(192, 168, 1, 1))
+	info := &dhcpv4.OptRelayAgentInformation{Options: []dhcpv4.RelayAgentSubOption{
+		{Code: dhcpv4.RelayAgentCircuitID, Data: []byte("eth0")},
+	}}
+	m.AddOption(info)
+	m.AddOption(&dhcpv4.OptClassIdentifier{Identifier: "PXEClient"})
+	rc = NewRequestContext(peer, m, now)
+	require.True(t, rc.Relayed())
+	require.True(t, rc.GatewayIPAddr.Equal(net.IPv4(192, 168, 1, 1)))
+	require.Equal(t, info, rc.RelayAgentInfo)
+	require.Equal(t, "PXEClient", rc.ClassIdentifier)
+
+	require.False(t, rc.HasClass("pxe"))
+	rc.Classes = append(rc.Classes, "pxe")
+	require.True(t, rc.HasClass("pxe"))
+}
+
+func TestServerRequestContext(t *testing.T) {
+	contexts := make(chan *RequestContext, 1)
+	s := NewServer(net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		contexts <- rc
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ActivateAndServe()
+	}()
+	defer func() {
+		s.Close()
+		<-done
+	}()
+	var addr net.Addr
+	for addr == nil {
+		time.Sleep(10 * time.Millisecond)
+		addr = s.LocalAddr()
+	}
+
+	conn, err := net.DialUDP("udp4", nil, addr.(*net.UDPAddr))
+	require.NoError(t, err)
+	defer conn.Close()
+	m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	_, err = conn.Write(m.ToBytes())
+	require.NoError(t, err)
+
+	select {
+	case rc := <-contexts:
+		require.NotNil(t, rc)
+		require.Equal(t, conn.LocalAddr(), rc.Peer)
+		require.Equal(t, dhcpv4.MessageTypeDiscover, rc.MessageType)
+		require.False(t, rc.Received.IsZero())
+		if rc.IfIndex != 0 {
+			iface, err := rc.Interface()
+			require.NoError(t, err)
+			require.NotZero(t, iface.Flags&net.FlagLoopback)
+			require.True(t, rc.LocalAddr.Equal(net.IPv4(127, 0, 0, 1)))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not handled")
+	}
+}
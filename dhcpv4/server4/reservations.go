@@ -0,0 +1,364 @@
+package server4
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/rfc1035label"
+)
+
+// Reservation pins the address of a host, identified by its client identifier
+// or by its hardware address, and the options it gets.
+type Reservation struct {
+	// Name is the name of the host, for the logs.
+	Name string
+	// ClientID is the data of the client identifier option of the host, or
+	// nil to identify it by HWAddr only.
+	ClientID []byte
+	HWAddr   net.HardwareAddr
+	// IP is the address of the host, or nil to only set its options.
+	IP net.IP
+	// Options are given to the host, replacing the ones of the same code
+	// that the other hosts get.
+	Options []dhcpv4.Option
+}
+
+// Apply adds the options of the reservation to reply, replacing the ones of
+// the same code.
+func (r *Reservation) Apply(reply *dhcpv4.DHCPv4) {
+	for _, opt := range r.Options {
+		reply.UpdateOption(dhcpv4.CloneOption(opt))
+	}
+}
+
+// Reservations is a table of reservations, matched against the clients by
+// client identifier first, then by hardware address, as RFC 2131, section
+// 4.2 suggests. It can be changed while the server is running. Its read
+// methods, Match, LookupIP, Reserved and Len, can be called on a nil table,
+// which has no reservation.
+type Reservations struct {
+	lock       sync.RWMutex
+	byClientID map[string]*Reservation
+	byHWAddr   map[string]*Reservation
+	byIP       map[uint32]*Reservation
+}
+
+// NewReservations returns an empty table of reservations.
+func NewReservations() *Reservations {
+	return &Reservations{
+		byClientID: make(map[string]*Reservation),
+		byHWAddr:   make(map[string]*Reservation),
+		byIP:       make(map[uint32]*Reservation),
+	}
+}
+
+// Add adds a reservation to the table. It fails if the reservation has
+// neither client identifier nor hardware address, or if another reservation
+// has the same client identifier, hardware address or address.
+func (t *Reservations) Add(r Reservation) error {
+	if r.ClientID == nil && r.HWAddr == nil {
+		return errors.New("reservation without client identifier nor hardware address")
+	}
+	if r.IP != nil {
+		if r.IP = r.IP.To4(); r.IP == nil {
+			return errors.New("not an IPv4 address")
+		}
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, ok := t.byClientID[string(r.ClientID)]; ok && r.ClientID != nil {
+		return fmt.Errorf("duplicate reservation for client identifier %x", r.ClientID)
+	}
+	if _, ok := t.byHWAddr[string(r.HWAddr)]; ok && r.HWAddr != nil {
+		return fmt.Errorf("duplicate reservation for %v", r.HWAddr)
+	}
+	if _, ok := t.byIP[ipKey(r.IP)]; ok && r.IP != nil {
+		return fmt.Errorf("duplicate reservation for %v", r.IP)
+	}
+	if r.ClientID != nil {
+		t.byClientID[string(r.ClientID)] = &r
+	}
+	if r.HWAddr != nil {
+		t.byHWAddr[string(r.HWAddr)] = &r
+	}
+	if r.IP != nil {
+		t.byIP[ipKey(r.IP)] = &r
+	}
+	return nil
+}
+
+// Remove removes the reservation that a client with the given client
+// identifier and hardware address matches, either of which may be nil.
+func (t *Reservations) Remove(clientID []byte, hwaddr net.HardwareAddr) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	r := t.match(clientID, hwaddr)
+	if r == nil {
+		return
+	}
+	if r.ClientID != nil {
+		delete(t.byClientID, string(r.ClientID))
+	}
+	if r.HWAddr != nil {
+		delete(t.byHWAddr, string(r.HWAddr))
+	}
+	if r.IP != nil {
+		delete(t.byIP, ipKey(r.IP))
+	}
+}
+
+// Match returns the reservation of the client of req, or nil if there is
+// none. The reservation must not be changed.
+func (t *Reservations) Match(req AllocationRequest) *Reservation {
+	if t == nil {
+		return nil
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.match(req.ClientID, req.HWAddr)
+}
+
+func (t *Reservations) match(clientID []byte, hwaddr net.HardwareAddr) *Reservation {
+	if r, ok := t.byClientID[string(clientID)]; ok && clientID != nil {
+		return r
+	}
+	if r, ok := t.byHWAddr[string(hwaddr)]; ok && hwaddr != nil {
+		return r
+	}
+	return nil
+}
+
+// LookupIP returns the reservation of an address, or nil if there is none.
+// The reservation must not be changed.
+func (t *Reservations) LookupIP(ip net.IP) *Reservation {
+	if t == nil {
+		return nil
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.byIP[ipKey(ip)]
+}
+
+// Reserved returns true if ip is reserved for another client than the one of
+// req.
+func (t *Reservations) Reserved(ip net.IP, req AllocationRequest) bool {
+	r := t.LookupIP(ip)
+	return r != nil && r != t.Match(req)
+}
+
+// Len returns the number of reservations.
+func (t *Reservations) Len() int {
+	if t == nil {
+		return 0
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	n := len(t.byHWAddr)
+	for _, r := range t.byClientID {
+		if r.HWAddr == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// ParseReservations reads a table of reservations in a simple text format,
+// with one host per line:
+//
+//   # lab machines
+//   host pxe1 hwaddr 00:11:22:33:44:55 ip 10.0.0.5 option 67=pxelinux.0
+//   host vm2 clientid 01:00:11:22:33:44:66 ip 10.0.0.6 option 6=10.0.0.1,10.0.0.2
+//
+// A host has a name, then a client identifier, a hardware address or both,
+// possibly an address, and options. The values of the options are written
+// according to their type, as told by dhcpv4.LookupOptionInfo: comma-separated
+// for lists, and hexadecimal with a 0x prefix for the other types and the
+// unknown options. Values cannot contain blanks, and everything after a # is
+// ignored.
+func ParseReservations(r io.Reader) (*Reservations, error) {
+	t := NewReservations()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		res, err := parseReservation(fields)
+		if err == nil {
+			err = t.Add(*res)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parseReservation parses the fields of a host line.
+func parseReservation(fields []string) (*Reservation, error) {
+	if fields[0] != "host" || len(fields) < 2 {
+		return nil, errors.New("expected host and its name")
+	}
+	r := Reservation{Name: fields[1]}
+	for i := 2; i < len(fields); i += 2 {
+		if i+1 == len(fields) {
+			return nil, fmt.Errorf("missing value of %s", fields[i])
+		}
+		key, value := fields[i], fields[i+1]
+		var err error
+		switch key {
+		case "hwaddr":
+			r.HWAddr, err = net.ParseMAC(value)
+		case "clientid":
+			r.ClientID, err = parseHex(value)
+		case "ip":
+			if r.IP = net.ParseIP(value).To4(); r.IP == nil {
+				err = fmt.Errorf("invalid IPv4 address %q", value)
+			}
+		case "option":
+			var opt dhcpv4.Option
+			opt, err = parseOptionSetting(value)
+			r.Options = append(r.Options, opt)
+		default:
+			err = fmt.Errorf("unknown setting %q", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// parseOptionSetting parses an option written as code=value.
+func parseOptionSetting(s string) (dhcpv4.Option, error) {
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		return nil, fmt.Errorf("expected code=value, got %q", s)
+	}
+	n, err := strconv.ParseUint(s[:i], 10, 8)
+	if err != nil || n == 0 || n == 255 {
+		return nil, fmt.Errorf("invalid option code %q", s[:i])
+	}
+	code := dhcpv4.OptionCode(n)
+	data, err := parseOptionValue(code, s[i+1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of option %v: %v", code, err)
+	}
+	if len(data) > 255 {
+		return nil, fmt.Errorf("value of option %v is too long", code)
+	}
+	opt, err := dhcpv4.ParseOption(append([]byte{byte(code), byte(len(data))}, data...))
+	if err != nil {
+		return nil, err
+	}
+	if err := dhcpv4.ValidateOption(opt); err != nil {
+		return nil, err
+	}
+	return opt, nil
+}
+
+// parseOptionValue returns the data of an option of the given code from its
+// textual value.
+func parseOptionValue(code dhcpv4.OptionCode, value string) ([]byte, error) {
+	if strings.HasPrefix(value, "0x") {
+		return hex.DecodeString(value[2:])
+	}
+	info, ok := dhcpv4.LookupOptionInfo(code)
+	if !ok {
+		return nil, errors.New("unknown option, expected hexadecimal data")
+	}
+	switch info.Type {
+	case dhcpv4.ValueString:
+		return []byte(value), nil
+	case dhcpv4.ValueEmpty:
+		if value != "" {
+			return nil, errors.New("the option has no value")
+		}
+		return []byte{}, nil
+	case dhcpv4.ValueBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+	case dhcpv4.ValueUint8, dhcpv4.ValueUint16, dhcpv4.ValueUint32:
+		return parseUints(value, info.Type)
+	case dhcpv4.ValueUint8List:
+		return parseUints(value, dhcpv4.ValueUint8)
+	case dhcpv4.ValueUint16List:
+		return parseUints(value, dhcpv4.ValueUint16)
+	case dhcpv4.ValueInt32:
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, 4)
+		binary.BigEndian.PutUint32(data, uint32(n))
+		return data, nil
+	case dhcpv4.ValueIPv4, dhcpv4.ValueIPv4List:
+		var data []byte
+		for _, s := range strings.Split(value, ",") {
+			ip := net.ParseIP(s).To4()
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IPv4 address %q", s)
+			}
+			data = append(data, ip...)
+		}
+		return data, nil
+	case dhcpv4.ValueDomainList:
+		return rfc1035label.LabelsToBytes(strings.Split(value, ",")), nil
+	}
+	return nil, fmt.Errorf("expected hexadecimal data for a value of type %v", info.Type)
+}
+
+// parseUints parses comma-separated unsigned integers of the given type.
+func parseUints(value string, typ dhcpv4.ValueType) ([]byte, error) {
+	var data []byte
+	for _, s := range strings.Split(value, ",") {
+		switch typ {
+		case dhcpv4.ValueUint8:
+			n, err := strconv.ParseUint(s, 10, 8)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, byte(n))
+		case dhcpv4.ValueUint16:
+			n, err := strconv.ParseUint(s, 10, 16)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, byte(n>>8), byte(n))
+		default:
+			n, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+		}
+	}
+	return data, nil
+}
+
+// parseHex parses hexadecimal data, possibly with colons between the bytes.
+func parseHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.Replace(s, ":", "", -1))
+}
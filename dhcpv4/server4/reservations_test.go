@@ -0,0 +1,112 @@
+package server4
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservations(t *testing.T) {
+	var nilTable *Reservations
+	require.Nil(t, nilTable.Match(AllocationRequest{HWAddr: allocHwAddr1}))
+	require.Equal(t, 0, nilTable.Len())
+
+	r := NewReservations()
+	require.NoError(t, r.Add(Reservation{Name: "host1", HWAddr: allocHwAddr1, IP: net.IPv4(10, 0, 0, 11)}))
+	require.NoError(t, r.Add(Reservation{Name: "host2", ClientID: []byte{1, 2}, HWAddr: allocHwAddr2}))
+	require.Error(t, r.Add(Reservation{Name: "nobody", IP: net.IPv4(10, 0, 0, 12)}))
+	require.Error(t, r.Add(Reservation{Name: "dup", HWAddr: allocHwAddr1}))
+	require.Error(t, r.Add(Reservation{Name: "dup", ClientID: []byte{1, 2}}))
+	require.Error(t, r.Add(Reservation{Name: "dup", ClientID: []byte{3}, IP: net.IPv4(10, 0, 0, 11)}))
+	require.Equal(t, 2, r.Len())
+
+	require.Equal(t, "host1", r.Match(AllocationRequest{HWAddr: allocHwAddr1}).Name)
+	require.Equal(t, "host2", r.Match(AllocationRequest{ClientID: []byte{1, 2}}).Name)
+	// the client identifier comes first
+	require.Equal(t, "host2", r.Match(AllocationRequest{HWAddr: allocHwAddr1, ClientID: []byte{1, 2}}).Name)
+	require.Equal(t, "host1", r.LookupIP(net.IPv4(10, 0, 0, 11)).Name)
+	require.True(t, r.Reserved(net.IPv4(10, 0, 0, 11), AllocationRequest{HWAddr: allocHwAddr2}))
+	require.False(t, r.Reserved(net.IPv4(10, 0, 0, 11), AllocationRequest{HWAddr: allocHwAddr1}))
+
+	r.Remove(nil, allocHwAddr1)
+	require.Nil(t, r.Match(AllocationRequest{HWAddr: allocHwAddr1}))
+	require.Nil(t, r.LookupIP(net.IPv4(10, 0, 0, 11)))
+	require.Equal(t, 1, r.Len())
+}
+
+func TestServerAllocateReservations(t *testing.T) {
+	s := newAllocatorTestServer(t)
+	s.Reservations = NewReservations()
+	require.NoError(t, s.Reservations.Add(Reservation{HWAddr: allocHwAddr2, IP: net.IPv4(10, 0, 0, 10)}))
+	require.NoError(t, s.Reservations.Add(Reservation{ClientID: []byte{1, 2}, IP: net.IPv4(192, 0, 2, 1)}))
+	ctx := context.Background()
+
+	// the reserved addresses are skipped for the other clients
+	ip, err := s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1, Hint: net.IPv4(10, 0, 0, 10)})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 11}, ip)
+	ip, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr2})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{10, 0, 0, 10}, ip)
+	// even outside of the pools
+	ip, err = s.Allocate(ctx, AllocationRequest{HWAddr: allocHwAddr1, ClientID: []byte{1, 2}})
+	require.NoError(t, err)
+	require.Equal(t, net.IP{192, 0, 2, 1}, ip)
+}
+
+func TestParseReservations(t *testing.T) {
+	r, err := ParseReservations(strings.NewReader(`
+# lab machines
+host pxe1 hwaddr 00:01:02:03:04:01 ip 10.0.0.5 option 67=pxelinux.0 option 6=10.0.0.1,10.0.0.2
+host vm2 clientid 01:00:01:02:03:04:02 option 26=9000   # jumbo frames
+host vm3 hwaddr 00:01:02:03:04:03 option 119=example.com,lab.example.com option 224=0x0102
+`))
+	require.NoError(t, err)
+	require.Equal(t, 3, r.Len())
+
+	pxe1 := r.Match(AllocationRequest{HWAddr: allocHwAddr1})
+	require.NotNil(t, pxe1)
+	require.Equal(t, "pxe1", pxe1.Name)
+	require.Equal(t, net.IP{10, 0, 0, 5}, pxe1.IP)
+	reply, err := dhcpv4.New()
+	require.NoError(t, err)
+	reply.UpdateOption(&dhcpv4.OptBootfileName{BootfileName: []byte("default.efi")})
+	pxe1.Apply(reply)
+	require.Equal(t, &dhcpv4.OptBootfileName{BootfileName: []byte("pxelinux.0")}, reply.GetOneOption(dhcpv4.OptionBootfileName))
+	require.Len(t, reply.GetOption(dhcpv4.OptionBootfileName), 1)
+	require.Equal(t, []net.IP{net.IPv4(10, 0, 0, 1), net.IPv4(10, 0, 0, 2)}, reply.DNS())
+
+	vm2 := r.Match(AllocationRequest{ClientID: []byte{1, 0, 1, 2, 3, 4, 2}})
+	require.NotNil(t, vm2)
+	require.Nil(t, vm2.IP)
+	require.Equal(t, []dhcpv4.Option{&dhcpv4.OptInterfaceMTU{MTU: 9000}}, vm2.Options)
+
+	vm3 := r.Match(AllocationRequest{HWAddr: net.HardwareAddr{0, 1, 2, 3, 4, 3}})
+	require.NotNil(t, vm3)
+	require.Len(t, vm3.Options, 2)
+	require.Equal(t, []byte{224, 2, 1, 2}, vm3.Options[1].ToBytes())
+}
+
+func TestParseReservationsInvalid(t *testing.T) {
+	for _, config := range []string{
+		"hosts pxe1 hwaddr 00:01:02:03:04:01",
+		"host pxe1 hwaddr",
+		"host pxe1 hwaddr 00:01:02:03:04",
+		"host pxe1 hwaddr 00:01:02:03:04:01 ip 10.0.0",
+		"host pxe1 hwaddr 00:01:02:03:04:01 mac 00:01:02:03:04:01",
+		"host pxe1 ip 10.0.0.1",
+		"host pxe1 hwaddr 00:01:02:03:04:01 option 67",
+		"host pxe1 hwaddr 00:01:02:03:04:01 option 256=1",
+		"host pxe1 hwaddr 00:01:02:03:04:01 option 26=90000",
+		"host pxe1 hwaddr 00:01:02:03:04:01 option 3=10.0.0.1,router",
+		"host pxe1 hwaddr 00:01:02:03:04:01 option 224=text",
+		"host pxe1 hwaddr 00:01:02:03:04:01\nhost pxe2 hwaddr 00:01:02:03:04:01",
+	} {
+		_, err := ParseReservations(strings.NewReader(config))
+		require.Error(t, err, config)
+	}
+}
@@ -0,0 +1,416 @@
+package server4
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"golang.org/x/net/ipv4"
+)
+
+/*
+  To use the DHCPv4 server code you have to call NewServer with two arguments:
+  - an address to listen on, and
+  - a handler function, that will be called every time a valid DHCPv4 packet is
+      received.
+
+  The handler is a function that takes as input a packet connection, that can be
+  used to reply to the client; a peer address, that identifies the client sending
+  the request; the DHCPv4 packet itself, and a RequestContext holding what is
+  known about the packet, such as the interface it was received on. Just
+  implement your custom logic in the handler.
+
+  Besides the server loop, a Server keeps the state needed to hand out
+  addresses: the pools of assignable addresses, the lease store, and the
+  addresses abandoned because they were found in use by another host. This
+  state can be saved and loaded with Snapshot and Restore, while a
+  FileLeaseStore keeps the leases across restarts on its own. Instead of the
+  pools, an Allocator such as a RangeAllocator can pick the addresses, except
+  for the clients with Reservations, which also get their own options. The
+  addresses can be checked with a PingCheck, such as a Pinger, before they are
+  offered.
+
+  The messages are handled one at a time, unless the server has Workers, in
+  which case the handler must be safe for concurrent use. A handler that
+  panics does not bring the server down: the panic is logged, and the message
+  dropped.
+
+  Handlers can be wrapped with middlewares using Chain, e.g. to drop the
+  messages relayed too many times with MaxHopCount, or the ones from unknown
+  relay agents with ValidateGatewayIPAddr, or the duplicates received through
+  several paths with DropDuplicates, or to answer the retransmitted Discovers
+  with the offer already sent with CacheOffers. Against starvation attacks,
+  RateLimitHwAddr and RateLimitGatewayIPAddr limit the rate of the messages of
+  each client and relay agent, and LimitOffers the offers made on each relay
+  circuit. AnswerLeaseQueries answers the leasequeries of the relay agents
+  from the leases of the server.
+
+  Replies to local clients that did not ask for broadcast replies, as told by
+  UnicastToHwAddr, cannot be sent through conn since these clients have no
+  address yet. On Linux, a UnicastReplier sends them to the client hardware
+  address instead: either a RawReplier, sending raw frames, or an ARPReplier,
+  installing an ARP entry for the client first.
+
+  Under systemd socket activation, the sockets passed by systemd can be
+  obtained with ActivationConns, and served with NewServerWithConn instead of
+  NewServer.
+
+  Example program:
+
+
+package main
+
+import (
+	"log"
+	"net"
+	"runtime/debug"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+)
+
+func handler(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *server4.RequestContext) {
+	// this function will just print the received DHCPv4 message, without replying
+	log.Print(m.Summary())
+}
+
+func main() {
+	laddr := net.UDPAddr{
+		IP:   net.ParseIP("0.0.0.0"),
+		Port: dhcpv4.ServerPort,
+	}
+	server := server4.NewServer(laddr, handler)
+
+	defer server.Close()
+	if err := server.ActivateAndServe(); err != nil {
+		log.Panic(err)
+	}
+}
+
+*/
+
+// Handler is a type that defines the handler function to be called every time a
+// valid DHCPv4 message is received. rc is never nil when called by Server.
+type Handler func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext)
+
+// Server represents a DHCPv4 server object
+type Server struct {
+	conn       net.PacketConn
+	connMutex  sync.Mutex
+	shouldStop chan bool
+	Handler    Handler
+	localAddr  net.UDPAddr
+
+	// Leases holds the bindings of the server. NewServer initializes it with
+	// a MemoryLeaseStore.
+	Leases LeaseStore
+
+	// Reservations, if not nil, pins the addresses of some clients for
+	// Allocate, and their options. The reserved addresses may be in the
+	// pools of the server, but must be kept out of the ranges of Allocator.
+	Reservations *Reservations
+
+	// Allocator, if not nil, picks the addresses of the clients for
+	// Allocate, instead of a PoolAllocator on the pools of the server.
+	Allocator Allocator
+
+	// PingCheck, if not nil, checks that the addresses picked from the
+	// pools of the server are not in use before they are given to a new
+	// client, e.g. the Probe method of a Pinger. It returns an
+	// *acd.ConflictError for the addresses in use, which are abandoned.
+	PingCheck func(ctx context.Context, ip net.IP) error
+
+	// ExchangeTimeout is how long Shutdown waits for a client that received
+	// an offer to send its request before considering the exchange
+	// abandoned. Only the offers that the handler writes to its connection
+	// are waited for, not the ones sent by a UnicastReplier.
+	ExchangeTimeout time.Duration
+
+	// ClientPort is the port to which the replies to the clients that are
+	// not relayed are sent, which the handlers find in their
+	// RequestContext. If zero, dhcpv4.ClientPort is used. Along with a
+	// non-standard port in the address of the server, it allows testing
+	// without root.
+	ClientPort int
+
+	// Workers is the number of goroutines parsing and handling the
+	// messages, so that a slow handler does not hold up the others. If
+	// zero, the messages are parsed and handled one at a time by the
+	// server loop, and the handler needs not be safe for concurrent use.
+	Workers int
+
+	// QueueSize is the number of messages waiting for a worker beyond
+	// which the server loop drops the messages it receives. If zero,
+	// Workers is used. It is ignored without workers.
+	QueueSize int
+
+	// MaxMessageSize is the size in bytes of the largest message the server
+	// receives, larger ones being truncated and dropped. If zero,
+	// dhcpv4.MaxUDPReceivedPacketSize is used. The handlers can tell how
+	// large a reply the client accepts with DHCPv4.MaximumMessageSize.
+	MaxMessageSize int
+
+	// ParseConfig tells how the received messages that are malformed are
+	// handled, e.g. dhcpv4.StrictParseConfig to drop all of them. The zero
+	// value drops those that dhcpv4.FromBytes cannot decode.
+	ParseConfig dhcpv4.ParseConfig
+
+	// Logger, if not nil, is where the server, and the handler through the
+	// RequestContext, report what they do. dhcpv4.DefaultLogger is used
+	// otherwise.
+	Logger dhcpv4.Logger
+
+	stateMutex sync.RWMutex
+	pools      []*Pool
+	abandoned  map[uint32]time.Time
+
+	draining      int32
+	queued        int32
+	inflightMutex sync.Mutex
+	handling      int
+	// exchanges holds the start of the exchanges waiting for a request,
+	// pruned at exchangesPruned
+	exchanges       map[uint32]time.Time
+	exchangesPruned time.Time
+}
+
+// LocalAddr returns the local address of the listening socket, or nil if not
+// listening
+func (s *Server) LocalAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.LocalAddr()
+}
+
+// ActivateAndServe starts the DHCPv4 server
+func (s *Server) ActivateAndServe() error {
+	s.connMutex.Lock()
+	if s.conn == nil {
+		conn, err := net.ListenUDP("udp4", &s.localAddr)
+		if err != nil {
+			s.connMutex.Unlock()
+			return err
+		}
+		s.conn = conn
+	}
+	pc, ok := s.conn.(*net.UDPConn)
+	s.connMutex.Unlock()
+	defer func() {
+		s.connMutex.Lock()
+		s.conn.Close()
+		s.conn = nil
+		s.connMutex.Unlock()
+	}()
+	if !ok {
+		return fmt.Errorf("Error: not an UDPConn")
+	}
+	if pc == nil {
+		return fmt.Errorf("ActivateAndServe: Invalid nil PacketConn")
+	}
+	logger := s.logger()
+	logger.Printf("Server listening on %s", pc.LocalAddr())
+	logger.Printf("Ready to handle requests")
+	// learn the interface and the destination address of the messages, where
+	// the platform allows it
+	p := ipv4.NewPacketConn(pc)
+	if err := p.SetControlMessage(ipv4.FlagInterface|ipv4.FlagDst, true); err != nil {
+		logger.Printf("Cannot get the interface of the messages: %v", err)
+	}
+	var queue chan packet
+	if s.Workers > 0 {
+		queue = s.startWorkers(pc)
+		defer close(queue)
+	}
+	rbuf := make([]byte, s.maxMessageSize())
+	for {
+		select {
+		case <-s.shouldStop:
+			return nil
+		default:
+		}
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, cm, peer, err := p.ReadFrom(rbuf)
+		received := time.Now()
+		if err != nil {
+			switch err.(type) {
+			case net.Error:
+				// silently skip and continue
+			default:
+				//complain and continue
+				logger.Printf("Error reading from packet conn: %v", err)
+			}
+			continue
+		}
+		logger.Printf("Handling request from %v", peer)
+		// the message may outlive the buffer, kept by the handler
+		p := packet{data: append([]byte(nil), rbuf[:n]...), peer: peer, received: received}
+		if cm != nil {
+			p.ifindex, p.dst = cm.IfIndex, cm.Dst
+		}
+		if queue == nil {
+			s.process(pc, p)
+			continue
+		}
+		atomic.AddInt32(&s.queued, 1)
+		select {
+		case queue <- p:
+		default:
+			atomic.AddInt32(&s.queued, -1)
+			logger.Printf("Dropping request from %v: too many requests waiting", peer)
+		}
+	}
+}
+
+// packet is a message received by the server loop, not parsed yet.
+type packet struct {
+	data     []byte
+	peer     net.Addr
+	received time.Time
+	ifindex  int
+	dst      net.IP
+}
+
+// startWorkers starts the workers of the server, handling the packets sent
+// on the returned channel until it is closed.
+func (s *Server) startWorkers(conn net.PacketConn) chan packet {
+	size := s.QueueSize
+	if size <= 0 {
+		size = s.Workers
+	}
+	queue := make(chan packet, size)
+	for i := 0; i < s.Workers; i++ {
+		go func() {
+			for p := range queue {
+				s.process(conn, p)
+				atomic.AddInt32(&s.queued, -1)
+			}
+		}()
+	}
+	return queue
+}
+
+// process parses a packet and passes the message to the handler.
+func (s *Server) process(conn net.PacketConn, p packet) {
+	logger := s.logger()
+	// a panic of the parsing or of the handling, e.g. with a custom option
+	// parser or handler, is logged and recovered from, so that a message
+	// crafted to trigger it only costs its own reply
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Printf("Panic on message from %v: %v\n%s", p.peer, r, debug.Stack())
+		}
+	}()
+	m, err := dhcpv4.FromBytesWithConfig(p.data, s.ParseConfig)
+	if err != nil {
+		logger.Printf("Error parsing DHCPv4 request: %v", err)
+		return
+	}
+	rc := NewRequestContext(p.peer, m, p.received)
+	rc.Logger = logger
+	rc.IfIndex, rc.LocalAddr = p.ifindex, p.dst
+	rc.ClientPort = s.ClientPort
+	s.handle(conn, p.peer, m, rc)
+}
+
+// maxMessageSize returns the size of the receive buffer of the server.
+func (s *Server) maxMessageSize() int {
+	if s.MaxMessageSize <= 0 {
+		return dhcpv4.MaxUDPReceivedPacketSize
+	}
+	return s.MaxMessageSize
+}
+
+// logger returns the Logger of the server, or dhcpv4.DefaultLogger if there is
+// none.
+func (s *Server) logger() dhcpv4.Logger {
+	if s.Logger == nil {
+		return dhcpv4.DefaultLogger
+	}
+	return s.Logger
+}
+
+// Close sends a termination request to the server, and closes the UDP listener
+func (s *Server) Close() error {
+	select {
+	case s.shouldStop <- true:
+	default:
+	}
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// AddPool adds a pool of assignable addresses to the server. It returns an
+// error if the pool overlaps with one of the existing pools.
+func (s *Server) AddPool(p *Pool) error {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	for _, other := range s.pools {
+		if p.Overlaps(other) {
+			return fmt.Errorf("pool %v overlaps with pool %v", p, other)
+		}
+	}
+	s.pools = append(s.pools, p)
+	return nil
+}
+
+// Pools returns the pools of assignable addresses of the server.
+func (s *Server) Pools() []*Pool {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+	pools := make([]*Pool, len(s.pools))
+	copy(pools, s.pools)
+	return pools
+}
+
+// Abandon marks an address as unusable until the given time, typically
+// because a client declined it or because it was found in use by a host
+// unknown to the server.
+func (s *Server) Abandon(ip net.IP, until time.Time) {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	s.abandoned[ipKey(ip)] = until
+}
+
+// IsAbandoned returns true if the address is abandoned at the given time.
+func (s *Server) IsAbandoned(ip net.IP, now time.Time) bool {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+	until, ok := s.abandoned[ipKey(ip)]
+	return ok && now.Before(until)
+}
+
+// NewServer initializes and returns a new Server object
+func NewServer(addr net.UDPAddr, handler Handler) *Server {
+	return &Server{
+		localAddr:  addr,
+		Handler:    handler,
+		shouldStop: make(chan bool, 1),
+		Leases:     NewMemoryLeaseStore(0),
+		abandoned:  make(map[uint32]time.Time),
+		exchanges:  make(map[uint32]time.Time),
+
+		ExchangeTimeout: DefaultExchangeTimeout,
+	}
+}
+
+// NewServerWithConn initializes and returns a new Server object serving on an
+// already opened connection, such as one obtained from ActivationConns. This
+// allows the server to run without the privileges needed to bind to the DHCP
+// server port. The connection must be a *net.UDPConn.
+func NewServerWithConn(conn net.PacketConn, handler Handler) *Server {
+	s := NewServer(net.UDPAddr{}, handler)
+	s.conn = conn
+	return s
+}
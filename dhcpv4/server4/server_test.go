@@ -0,0 +1,235 @@
+package server4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerAddPool(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, nil)
+	p1, _ := NewPool(net.IPv4(10, 0, 0, 10), net.IPv4(10, 0, 0, 20))
+	p2, _ := NewPool(net.IPv4(10, 0, 0, 15), net.IPv4(10, 0, 0, 30))
+	require.NoError(t, s.AddPool(p1))
+	require.Error(t, s.AddPool(p2))
+	require.Equal(t, []*Pool{p1}, s.Pools())
+}
+
+func TestServerAbandon(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, nil)
+	now := time.Now()
+	ip := net.IPv4(10, 0, 0, 10)
+	require.False(t, s.IsAbandoned(ip, now))
+	s.Abandon(ip, now.Add(time.Minute))
+	require.True(t, s.IsAbandoned(ip, now))
+	require.False(t, s.IsAbandoned(ip, now.Add(time.Minute)))
+}
+
+func TestServerActivateAndServeClose(t *testing.T) {
+	s := NewServer(net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, nil)
+	require.Nil(t, s.LocalAddr())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ActivateAndServe()
+	}()
+	require.NoError(t, s.Close())
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestServerMaxMessageSize(t *testing.T) {
+	for _, tc := range []struct {
+		maxSize int
+		handled bool
+	}{
+		{0, true},
+		{1000, false},
+	} {
+		received := make(chan *dhcpv4.DHCPv4, 1)
+		s := NewServer(net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			received <- m
+		})
+		s.MaxMessageSize = tc.maxSize
+		done := make(chan error, 1)
+		go func() {
+			done <- s.ActivateAndServe()
+		}()
+		var addr net.Addr
+		for addr == nil {
+			time.Sleep(10 * time.Millisecond)
+			addr = s.LocalAddr()
+		}
+
+		conn, err := net.DialUDP("udp4", nil, addr.(*net.UDPAddr))
+		require.NoError(t, err)
+		// a message larger than the Ethernet MTU
+		m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+		require.NoError(t, err)
+		m = dhcpv4.WithMaximumMessageSize(9000)(m)
+		m.AddOption(&dhcpv4.OptionGeneric{OptionCode: dhcpv4.OptionCode(224), Data: make([]byte, 2000)})
+		_, err = conn.Write(m.ToBytes())
+		require.NoError(t, err)
+
+		select {
+		case got := <-received:
+			require.True(t, tc.handled, "truncated message handled")
+			require.True(t, m.Equal(got))
+			require.Equal(t, 9000, got.MaximumMessageSize())
+		case <-time.After(500 * time.Millisecond):
+			require.False(t, tc.handled, "message not handled")
+		}
+		conn.Close()
+		s.Close()
+		<-done
+	}
+}
+
+func TestServerParseConfig(t *testing.T) {
+	for _, tc := range []struct {
+		config  dhcpv4.ParseConfig
+		handled bool
+	}{
+		{dhcpv4.ParseConfig{}, true},
+		{dhcpv4.StrictParseConfig, false},
+	} {
+		received := make(chan *dhcpv4.DHCPv4, 1)
+		s := NewServer(net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+			received <- m
+		})
+		s.ParseConfig = tc.config
+		done := make(chan error, 1)
+		go func() {
+			done <- s.ActivateAndServe()
+		}()
+		var addr net.Addr
+		for addr == nil {
+			time.Sleep(10 * time.Millisecond)
+			addr = s.LocalAddr()
+		}
+
+		conn, err := net.DialUDP("udp4", nil, addr.(*net.UDPAddr))
+		require.NoError(t, err)
+		// garbage after the End option
+		m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+		require.NoError(t, err)
+		_, err = conn.Write(append(m.ToBytes(), 0xde, 0xad))
+		require.NoError(t, err)
+
+		select {
+		case <-received:
+			require.True(t, tc.handled, "malformed message handled")
+		case <-time.After(500 * time.Millisecond):
+			require.False(t, tc.handled, "message not handled")
+		}
+		conn.Close()
+		s.Close()
+		<-done
+	}
+}
+
+// startTestServer runs s on the loopback interface, and returns a connection
+// to it and a function stopping it.
+func startTestServer(t *testing.T, s *Server) (*net.UDPConn, func()) {
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ActivateAndServe()
+	}()
+	var addr net.Addr
+	for addr == nil {
+		time.Sleep(10 * time.Millisecond)
+		addr = s.LocalAddr()
+	}
+	conn, err := net.DialUDP("udp4", nil, addr.(*net.UDPAddr))
+	require.NoError(t, err)
+	return conn, func() {
+		conn.Close()
+		s.Close()
+		<-done
+	}
+}
+
+func TestServerWorkers(t *testing.T) {
+	slow := net.HardwareAddr{1, 2, 3, 4, 5, 6}
+	release := make(chan struct{})
+	received := make(chan net.HardwareAddr, 2)
+	s := NewServer(net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		if m.ClientHwAddr().String() == slow.String() {
+			<-release
+		}
+		received <- m.ClientHwAddr()
+	})
+	s.Workers = 2
+	conn, stop := startTestServer(t, s)
+	defer stop()
+
+	for _, hwaddr := range []net.HardwareAddr{slow, {1, 2, 3, 4, 5, 7}} {
+		m, err := dhcpv4.NewDiscovery(hwaddr)
+		require.NoError(t, err)
+		_, err = conn.Write(m.ToBytes())
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	// the second message is handled while the first one is held up
+	select {
+	case hwaddr := <-received:
+		require.Equal(t, net.HardwareAddr{1, 2, 3, 4, 5, 7}, hwaddr)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not handled")
+	}
+	close(release)
+	require.Equal(t, slow, <-received)
+}
+
+func TestServerProcessPanic(t *testing.T) {
+	err := dhcpv4.RegisterOptionParser(224, func(data []byte) (dhcpv4.Option, error) {
+		panic("crafted option")
+	})
+	require.NoError(t, err)
+	defer dhcpv4.RegisterOptionParser(224, nil)
+	s := NewServer(net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		panic("crafted message")
+	})
+
+	m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	peer := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: dhcpv4.ClientPort}
+	require.NotPanics(t, func() { s.process(nil, packet{data: m.ToBytes(), peer: peer}) })
+	require.Equal(t, 0, s.handling)
+
+	m.UpdateOption(&dhcpv4.OptionGeneric{OptionCode: 224, Data: []byte{1}})
+	require.NotPanics(t, func() { s.process(nil, packet{data: m.ToBytes(), peer: peer}) })
+}
+
+func TestServerHandlerPanic(t *testing.T) {
+	received := make(chan *dhcpv4.DHCPv4, 1)
+	s := NewServer(net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		if m.HopCount() > 0 {
+			panic("crafted message")
+		}
+		received <- m
+	})
+	conn, stop := startTestServer(t, s)
+	defer stop()
+
+	m, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	m.SetHopCount(1)
+	_, err = conn.Write(m.ToBytes())
+	require.NoError(t, err)
+	m.SetHopCount(0)
+	_, err = conn.Write(m.ToBytes())
+	require.NoError(t, err)
+	select {
+	case got := <-received:
+		require.Equal(t, uint8(0), got.HopCount())
+	case <-time.After(5 * time.Second):
+		t.Fatal("server stopped handling messages")
+	}
+}
@@ -0,0 +1,137 @@
+package server4
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// DefaultExchangeTimeout is the default value of Server.ExchangeTimeout.
+const DefaultExchangeTimeout = 10 * time.Second
+
+// shutdownPollInterval is how often Shutdown checks whether the in-flight
+// exchanges are over.
+var shutdownPollInterval = 50 * time.Millisecond
+
+// Flusher is implemented by the lease stores that buffer their writes. Shutdown
+// calls Flush before returning.
+type Flusher interface {
+	Flush() error
+}
+
+// handle calls the handler of the server for a message, keeping track of the
+// exchanges in progress: the Discovers being handled, and the ones answered
+// with an offer written to the connection, until the client sends its
+// request. While the server is shutting down, Discovers are dropped so that
+// no new exchange starts.
+func (s *Server) handle(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+	mt := m.MessageType()
+	discover := mt != nil && *mt == dhcpv4.MessageTypeDiscover
+	if discover && atomic.LoadInt32(&s.draining) != 0 {
+		return
+	}
+	if discover {
+		conn = exchangeRecorder{PacketConn: conn, server: s}
+	}
+
+	s.inflightMutex.Lock()
+	s.handling++
+	if mt != nil && (*mt == dhcpv4.MessageTypeRequest || *mt == dhcpv4.MessageTypeDecline) {
+		delete(s.exchanges, m.TransactionID())
+	}
+	s.inflightMutex.Unlock()
+
+	defer func() {
+		s.inflightMutex.Lock()
+		s.handling--
+		s.inflightMutex.Unlock()
+	}()
+	s.Handler(conn, peer, m, rc)
+}
+
+// offered records the exchange of an offer made at the given time. The
+// exchanges that timed out are dropped at most once per ExchangeTimeout, so
+// that the offers that the clients never answer do not pile up.
+func (s *Server) offered(xid uint32, now time.Time) {
+	s.inflightMutex.Lock()
+	defer s.inflightMutex.Unlock()
+	if now.Sub(s.exchangesPruned) >= s.ExchangeTimeout {
+		s.pruneExchanges(now)
+	}
+	s.exchanges[xid] = now
+}
+
+// pruneExchanges drops the exchanges that timed out at the given time. It must
+// be called with inflightMutex held.
+func (s *Server) pruneExchanges(now time.Time) {
+	for xid, started := range s.exchanges {
+		if now.Sub(started) >= s.ExchangeTimeout {
+			delete(s.exchanges, xid)
+		}
+	}
+	s.exchangesPruned = now
+}
+
+// inflight returns the number of messages waiting for a worker or being
+// handled, and of exchanges waiting for a request, dropping the exchanges that
+// timed out.
+func (s *Server) inflight(now time.Time) int {
+	s.inflightMutex.Lock()
+	defer s.inflightMutex.Unlock()
+	s.pruneExchanges(now)
+	return s.handling + len(s.exchanges) + int(atomic.LoadInt32(&s.queued))
+}
+
+// exchangeRecorder records in the exchanges of a server the offers written to
+// the connection.
+type exchangeRecorder struct {
+	net.PacketConn
+	server *Server
+}
+
+func (r exchangeRecorder) WriteTo(p []byte, addr net.Addr) (int, error) {
+	n, err := r.PacketConn.WriteTo(p, addr)
+	if err != nil {
+		return n, err
+	}
+	if m, perr := dhcpv4.FromBytes(p); perr == nil {
+		if mt := m.MessageType(); mt != nil && *mt == dhcpv4.MessageTypeOffer {
+			r.server.offered(m.TransactionID(), time.Now())
+		}
+	}
+	return n, err
+}
+
+// Shutdown gracefully stops the server: new Discovers are ignored, while the
+// exchanges in progress are given a chance to complete. Once they are over,
+// or when ctx is done, the listener is closed and the lease store is flushed
+// if it implements Flusher. If ctx is done before the exchanges are over,
+// Shutdown returns the context error.
+func (s *Server) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&s.draining, 1)
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	var ctxErr error
+	for s.inflight(time.Now()) > 0 && ctxErr == nil {
+		select {
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	err := s.Close()
+	if f, ok := s.Leases.(Flusher); ok {
+		if ferr := f.Flush(); ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	if ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
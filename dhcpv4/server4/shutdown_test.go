@@ -0,0 +1,116 @@
+package server4
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+type flushingStore struct {
+	*MemoryLeaseStore
+	flushed bool
+}
+
+func (f *flushingStore) Flush() error {
+	f.flushed = true
+	return nil
+}
+
+func newTestMessage(t *testing.T, mt dhcpv4.MessageType, xid uint32) *dhcpv4.DHCPv4 {
+	m, err := dhcpv4.New()
+	require.NoError(t, err)
+	m.SetTransactionID(xid)
+	m.AddOption(&dhcpv4.OptMessageType{MessageType: mt})
+	return m
+}
+
+// offer answers the Discovers with an offer.
+func offer(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+	if *m.MessageType() != dhcpv4.MessageTypeDiscover {
+		return
+	}
+	reply, err := dhcpv4.NewReplyFromRequest(m, dhcpv4.WithMessageType(dhcpv4.MessageTypeOffer))
+	if err == nil {
+		conn.WriteTo(reply.ToBytes(), peer)
+	}
+}
+
+func TestShutdownDrainsExchanges(t *testing.T) {
+	var handled []dhcpv4.MessageType
+	s := NewServer(net.UDPAddr{}, func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		handled = append(handled, *m.MessageType())
+		offer(conn, peer, m, rc)
+	})
+	store := &flushingStore{MemoryLeaseStore: NewMemoryLeaseStore(1)}
+	s.Leases = store
+	conn := &recordingConn{}
+
+	s.handle(conn, nil, newTestMessage(t, dhcpv4.MessageTypeDiscover, 1), nil)
+	require.Equal(t, 1, s.inflight(time.Now()))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Shutdown(context.Background())
+	}()
+	// wait for the server to start draining
+	for atomic.LoadInt32(&s.draining) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.handle(conn, nil, newTestMessage(t, dhcpv4.MessageTypeDiscover, 2), nil)
+	s.handle(conn, nil, newTestMessage(t, dhcpv4.MessageTypeRequest, 1), nil)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not return")
+	}
+	require.Equal(t, []dhcpv4.MessageType{dhcpv4.MessageTypeDiscover, dhcpv4.MessageTypeRequest}, handled)
+	require.True(t, store.flushed)
+}
+
+func TestShutdownContextDone(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, offer)
+	store := &flushingStore{MemoryLeaseStore: NewMemoryLeaseStore(1)}
+	s.Leases = store
+	s.handle(&recordingConn{}, nil, newTestMessage(t, dhcpv4.MessageTypeDiscover, 1), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := s.Shutdown(ctx)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.True(t, store.flushed)
+}
+
+func TestShutdownExchangeTimeout(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, offer)
+	s.ExchangeTimeout = 50 * time.Millisecond
+	s.handle(&recordingConn{}, nil, newTestMessage(t, dhcpv4.MessageTypeDiscover, 1), nil)
+	require.NoError(t, s.Shutdown(context.Background()))
+	require.Equal(t, 0, s.inflight(time.Now()))
+}
+
+func TestShutdownUnansweredDiscover(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {})
+	s.handle(&recordingConn{}, nil, newTestMessage(t, dhcpv4.MessageTypeDiscover, 1), nil)
+	require.Equal(t, 0, s.inflight(time.Now()))
+}
+
+func TestServerPrunesExchanges(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, offer)
+	now := time.Now()
+	for xid := uint32(0); xid < 100; xid++ {
+		s.offered(xid, now)
+	}
+	require.Len(t, s.exchanges, 100)
+	// the exchanges are not pruned more than once per timeout
+	s.offered(100, now.Add(s.ExchangeTimeout/2))
+	require.Len(t, s.exchanges, 101)
+	s.offered(101, now.Add(s.ExchangeTimeout))
+	require.Len(t, s.exchanges, 2)
+}
@@ -0,0 +1,226 @@
+package server4
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshotVersion is the version of the snapshot format written by
+// Server.Snapshot. Restore rejects snapshots with a different version.
+const snapshotVersion = 1
+
+type snapshotPool struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type snapshotBinding struct {
+	HWAddr  string    `json:"hw_addr"`
+	IP      string    `json:"ip"`
+	Expires time.Time `json:"expires"`
+	Updated time.Time `json:"updated"`
+}
+
+type snapshotAbandoned struct {
+	IP    string    `json:"ip"`
+	Until time.Time `json:"until"`
+}
+
+type snapshot struct {
+	Version   int                 `json:"version"`
+	Pools     []snapshotPool      `json:"pools"`
+	Leases    []snapshotBinding   `json:"leases"`
+	Abandoned []snapshotAbandoned `json:"abandoned"`
+}
+
+func keyToIP(k uint32) net.IP {
+	return net.IPv4(byte(k>>24), byte(k>>16), byte(k>>8), byte(k)).To4()
+}
+
+// Snapshot writes the state of the server, that is its pools, its leases and
+// its abandoned addresses, to w as a JSON document that can be loaded with
+// Restore, possibly by another process. Abandoned addresses that are usable
+// again are not included.
+func (s *Server) Snapshot(w io.Writer) error {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+
+	snap := snapshot{
+		Version:   snapshotVersion,
+		Pools:     make([]snapshotPool, 0, len(s.pools)),
+		Leases:    snapshotBindings(s.Leases),
+		Abandoned: make([]snapshotAbandoned, 0, len(s.abandoned)),
+	}
+	for _, p := range s.pools {
+		snap.Pools = append(snap.Pools, snapshotPool{Start: p.Start.String(), End: p.End.String()})
+	}
+	now := time.Now()
+	for k, until := range s.abandoned {
+		if now.Before(until) {
+			snap.Abandoned = append(snap.Abandoned, snapshotAbandoned{IP: keyToIP(k).String(), Until: until})
+		}
+	}
+	return json.NewEncoder(w).Encode(&snap)
+}
+
+func parseIPv4(s string) (net.IP, error) {
+	ip := net.ParseIP(s).To4()
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IPv4 address %q", s)
+	}
+	return ip, nil
+}
+
+// parseHWAddr parses a hardware address as written by
+// net.HardwareAddr.String. Unlike net.ParseMAC, it accepts addresses of any
+// length, the empty one included, since the server binds the chaddr of the
+// clients whatever its length.
+func parseHWAddr(s string) (net.HardwareAddr, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ":")
+	hwaddr := make(net.HardwareAddr, len(parts))
+	for i, p := range parts {
+		if len(p) != 2 {
+			return nil, fmt.Errorf("invalid hardware address %q", s)
+		}
+		b, err := strconv.ParseUint(p, 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hardware address %q", s)
+		}
+		hwaddr[i] = byte(b)
+	}
+	return hwaddr, nil
+}
+
+// snapshotBindings returns the bindings of leases in the snapshot format.
+func snapshotBindings(leases LeaseStore) []snapshotBinding {
+	bindings := make([]snapshotBinding, 0, leases.Len())
+	leases.Range(func(b Binding) bool {
+		bindings = append(bindings, snapshotBinding{
+			HWAddr:  b.HWAddr.String(),
+			IP:      b.IP.String(),
+			Expires: b.Expires,
+			Updated: b.Updated,
+		})
+		return true
+	})
+	return bindings
+}
+
+// loadBindings returns a new MemoryLeaseStore holding the bindings of a
+// snapshot, or an error if they are invalid or conflicting.
+func loadBindings(bindings []snapshotBinding) (*MemoryLeaseStore, error) {
+	leases := NewMemoryLeaseStore(0)
+	for _, sb := range bindings {
+		hwaddr, err := parseHWAddr(sb.HWAddr)
+		if err != nil {
+			return nil, err
+		}
+		ip, err := parseIPv4(sb.IP)
+		if err != nil {
+			return nil, err
+		}
+		if leases.Lookup(hwaddr) != nil {
+			return nil, fmt.Errorf("duplicate lease for %v", hwaddr)
+		}
+		if leases.LookupIP(ip) != nil {
+			return nil, fmt.Errorf("duplicate lease for %v", ip)
+		}
+		if err := leases.Bind(Binding{HWAddr: hwaddr, IP: ip, Expires: sb.Expires, Updated: sb.Updated}); err != nil {
+			return nil, err
+		}
+	}
+	return leases, nil
+}
+
+// Restore replaces the state of the server with the one read from r, as
+// written by Snapshot. The whole snapshot is decoded and validated before the
+// state is replaced, so on an invalid snapshot the server is left untouched.
+// The bindings of Leases are replaced by the restored ones through the
+// LeaseStore, so that a persistent store keeps persisting them; if the store
+// fails to bind one, its error is returned, and the store is only partially
+// restored. Restore must not be called while the server is handling requests.
+func (s *Server) Restore(r io.Reader) error {
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return err
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	pools := make([]*Pool, 0, len(snap.Pools))
+	for _, sp := range snap.Pools {
+		start, err := parseIPv4(sp.Start)
+		if err != nil {
+			return err
+		}
+		end, err := parseIPv4(sp.End)
+		if err != nil {
+			return err
+		}
+		p, err := NewPool(start, end)
+		if err != nil {
+			return err
+		}
+		for _, other := range pools {
+			if p.Overlaps(other) {
+				return fmt.Errorf("pool %v overlaps with pool %v", p, other)
+			}
+		}
+		pools = append(pools, p)
+	}
+
+	leases, err := loadBindings(snap.Leases)
+	if err != nil {
+		return err
+	}
+
+	abandoned := make(map[uint32]time.Time, len(snap.Abandoned))
+	for _, sa := range snap.Abandoned {
+		ip, err := parseIPv4(sa.IP)
+		if err != nil {
+			return err
+		}
+		if sa.Until.IsZero() {
+			return errors.New("abandoned address without expiration")
+		}
+		abandoned[ipKey(ip)] = sa.Until
+	}
+
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	s.pools = pools
+	s.abandoned = abandoned
+	if s.Leases == nil {
+		s.Leases = leases
+		return nil
+	}
+	return replaceBindings(s.Leases, leases)
+}
+
+// replaceBindings replaces the bindings of store with the ones of leases.
+func replaceBindings(store LeaseStore, leases LeaseStore) error {
+	var stale []net.HardwareAddr
+	store.Range(func(b Binding) bool {
+		stale = append(stale, b.HWAddr)
+		return true
+	})
+	for _, hwaddr := range stale {
+		store.Release(hwaddr)
+	}
+	var err error
+	leases.Range(func(b Binding) bool {
+		err = store.Bind(b)
+		return err == nil
+	})
+	return err
+}
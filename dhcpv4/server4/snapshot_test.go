@@ -0,0 +1,128 @@
+package server4
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	s := NewServer(net.UDPAddr{}, nil)
+	p1, err := NewPool(net.IPv4(10, 0, 0, 10), net.IPv4(10, 0, 0, 100))
+	require.NoError(t, err)
+	p2, err := NewPool(net.IPv4(10, 0, 1, 10), net.IPv4(10, 0, 1, 100))
+	require.NoError(t, err)
+	require.NoError(t, s.AddPool(p1))
+	require.NoError(t, s.AddPool(p2))
+	hw1 := net.HardwareAddr{0, 1, 2, 3, 4, 5}
+	hw2 := net.HardwareAddr{0, 1, 2, 3, 4, 6}
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: hw1, IP: net.IPv4(10, 0, 0, 10), Expires: now.Add(time.Hour), Updated: now}))
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: hw2, IP: net.IPv4(10, 0, 1, 10), Expires: now.Add(time.Minute)}))
+	s.Abandon(net.IPv4(10, 0, 0, 11), now.Add(time.Hour))
+	// already usable again, not part of the snapshot
+	s.Abandon(net.IPv4(10, 0, 0, 12), now.Add(-time.Hour))
+
+	var buf bytes.Buffer
+	require.NoError(t, s.Snapshot(&buf))
+
+	r := NewServer(net.UDPAddr{}, nil)
+	require.NoError(t, r.Restore(&buf))
+
+	pools := r.Pools()
+	require.Equal(t, 2, len(pools))
+	require.Equal(t, p1.String(), pools[0].String())
+	require.Equal(t, p2.String(), pools[1].String())
+
+	require.Equal(t, 2, r.Leases.Len())
+	b := r.Leases.Lookup(hw1)
+	require.NotNil(t, b)
+	require.Equal(t, net.IP{10, 0, 0, 10}, b.IP)
+	require.True(t, now.Add(time.Hour).Equal(b.Expires))
+	require.True(t, now.Equal(b.Updated))
+	b = r.Leases.LookupIP(net.IPv4(10, 0, 1, 10))
+	require.NotNil(t, b)
+	require.Equal(t, hw2, b.HWAddr)
+
+	require.True(t, r.IsAbandoned(net.IPv4(10, 0, 0, 11), now))
+	require.False(t, r.IsAbandoned(net.IPv4(10, 0, 0, 12), now.Add(-2*time.Hour)))
+}
+
+func TestRestoreIntoLeaseStore(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, nil)
+	hw1 := net.HardwareAddr{0, 1, 2, 3, 4, 5}
+	require.NoError(t, s.Leases.Bind(Binding{HWAddr: hw1, IP: net.IPv4(10, 0, 0, 10), Expires: time.Now().Add(time.Hour)}))
+	var buf bytes.Buffer
+	require.NoError(t, s.Snapshot(&buf))
+
+	dir, err := ioutil.TempDir("", "leases")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "leases.json")
+	store, err := OpenFileLeaseStore(path)
+	require.NoError(t, err)
+	r := NewServer(net.UDPAddr{}, nil)
+	r.Leases = store
+	hw2 := net.HardwareAddr{0, 1, 2, 3, 4, 6}
+	require.NoError(t, store.Bind(Binding{HWAddr: hw2, IP: net.IPv4(10, 0, 0, 11), Expires: time.Now().Add(time.Hour)}))
+	require.NoError(t, r.Restore(&buf))
+
+	// the store is kept, and persists the restored bindings only
+	require.Equal(t, store, r.Leases)
+	reopened, err := OpenFileLeaseStore(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, reopened.Len())
+	require.NotNil(t, reopened.Lookup(hw1))
+	require.Nil(t, reopened.Lookup(hw2))
+}
+
+func TestSnapshotRestoreHwAddrLengths(t *testing.T) {
+	s := NewServer(net.UDPAddr{}, nil)
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	hwaddrs := []net.HardwareAddr{{}, {1, 2, 3}, {0, 1, 2, 3, 4, 5}}
+	for i, hwaddr := range hwaddrs {
+		require.NoError(t, s.Leases.Bind(Binding{HWAddr: hwaddr, IP: net.IPv4(10, 0, 0, byte(10+i)), Expires: expires}))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, s.Snapshot(&buf))
+	r := NewServer(net.UDPAddr{}, nil)
+	require.NoError(t, r.Restore(&buf))
+
+	require.Equal(t, len(hwaddrs), r.Leases.Len())
+	for i, hwaddr := range hwaddrs {
+		b := r.Leases.LookupIP(net.IPv4(10, 0, 0, byte(10+i)))
+		require.NotNil(t, b, hwaddr)
+		require.Equal(t, hwaddr.String(), b.HWAddr.String())
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	for _, in := range []string{
+		`not json`,
+		`{"version": 2}`,
+		`{"version": 1, "pools": [{"start": "10.0.0.1", "end": "2001:db8::1"}]}`,
+		`{"version": 1, "pools": [{"start": "10.0.0.1", "end": "10.0.0.10"}, {"start": "10.0.0.5", "end": "10.0.0.20"}]}`,
+		`{"version": 1, "leases": [{"hw_addr": "zz", "ip": "10.0.0.1"}]}`,
+		`{"version": 1, "leases": [{"hw_addr": "0:1", "ip": "10.0.0.1"}]}`,
+		`{"version": 1, "leases": [{"hw_addr": "00::01", "ip": "10.0.0.1"}]}`,
+		`{"version": 1, "leases": [{"hw_addr": "00:01:02:03:04:05", "ip": "10.0.0.1"}, {"hw_addr": "00:01:02:03:04:05", "ip": "10.0.0.2"}]}`,
+		`{"version": 1, "leases": [{"hw_addr": "00:01:02:03:04:05", "ip": "10.0.0.1"}, {"hw_addr": "00:01:02:03:04:06", "ip": "10.0.0.1"}]}`,
+		`{"version": 1, "abandoned": [{"ip": "10.0.0.1"}]}`,
+	} {
+		s := NewServer(net.UDPAddr{}, nil)
+		p, err := NewPool(net.IPv4(192, 168, 0, 1), net.IPv4(192, 168, 0, 10))
+		require.NoError(t, err)
+		require.NoError(t, s.AddPool(p))
+		require.Error(t, s.Restore(strings.NewReader(in)), in)
+		// state is left untouched on error
+		require.Equal(t, 1, len(s.Pools()), in)
+	}
+}
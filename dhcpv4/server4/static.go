@@ -0,0 +1,81 @@
+package server4
+
+import (
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// StaticHandler returns a handler answering every client with the same canned
+// reply, without any allocation: each Discover gets an Offer, and each Request
+// or Inform an Ack. It is meant for test harnesses, e.g. to check that a PXE
+// client or an installer image boots with a given configuration, and must not
+// be used on production networks, where every client would get the same
+// address.
+//
+// The yiaddr, siaddr, sname and file fields, and the options of canned, are
+// copied into the replies, except that the Ack of an Inform has no yiaddr.
+// The message type comes from the request, and so do the fields identifying
+// the exchange and the client. The replies are sent to the relay agent, if
+// any, to the ciaddr of the client if it has one, and broadcast otherwise.
+func StaticHandler(canned *dhcpv4.DHCPv4) Handler {
+	return func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4, rc *RequestContext) {
+		mt := m.MessageType()
+		if m.Opcode() != dhcpv4.OpcodeBootRequest || mt == nil {
+			return
+		}
+		var replyType dhcpv4.MessageType
+		switch *mt {
+		case dhcpv4.MessageTypeDiscover:
+			replyType = dhcpv4.MessageTypeOffer
+		case dhcpv4.MessageTypeRequest, dhcpv4.MessageTypeInform:
+			replyType = dhcpv4.MessageTypeAck
+		default:
+			return
+		}
+		reply, err := newStaticReply(canned, m, replyType)
+		if err != nil {
+			rc.logger().Printf("Cannot build the reply to %v: %v", peer, err)
+			return
+		}
+		if _, err := conn.WriteTo(reply.ToBytes(), staticReplyAddr(m, peer, rc.clientPort())); err != nil {
+			rc.logger().Printf("Cannot send the reply to %v: %v", peer, err)
+		}
+	}
+}
+
+// newStaticReply builds the reply of the given type to request, from canned.
+func newStaticReply(canned, request *dhcpv4.DHCPv4, replyType dhcpv4.MessageType) (*dhcpv4.DHCPv4, error) {
+	reply, err := dhcpv4.NewReplyFromRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	if *request.MessageType() != dhcpv4.MessageTypeInform {
+		reply.SetYourIPAddr(canned.YourIPAddr())
+	}
+	reply.SetServerIPAddr(canned.ServerIPAddr())
+	sname, file := canned.ServerHostNameRaw(), canned.BootFileNameRaw()
+	reply.SetServerHostNameRaw(sname[:])
+	reply.SetBootFileNameRaw(file[:])
+	reply.UpdateOption(&dhcpv4.OptMessageType{MessageType: replyType})
+	for _, opt := range canned.Options() {
+		switch opt.Code() {
+		case dhcpv4.OptionDHCPMessageType, dhcpv4.OptionPad, dhcpv4.OptionEnd:
+			continue
+		}
+		reply.AddOption(dhcpv4.CloneOption(opt))
+	}
+	return reply, nil
+}
+
+// staticReplyAddr returns the address to which the reply to request, received
+// from peer, is sent, port being the port of the clients.
+func staticReplyAddr(request *dhcpv4.DHCPv4, peer net.Addr, port int) net.Addr {
+	if giaddr := request.GatewayIPAddr(); giaddr != nil && !giaddr.IsUnspecified() {
+		return peer
+	}
+	if ciaddr := request.ClientIPAddr(); ciaddr != nil && !ciaddr.IsUnspecified() {
+		return &net.UDPAddr{IP: ciaddr, Port: port}
+	}
+	return &net.UDPAddr{IP: net.IPv4bcast, Port: port}
+}
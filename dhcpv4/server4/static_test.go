@@ -0,0 +1,98 @@
+package server4
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func staticTestReply(t *testing.T) *dhcpv4.DHCPv4 {
+	canned, err := dhcpv4.New()
+	require.NoError(t, err)
+	for _, mod := range []dhcpv4.Modifier{
+		dhcpv4.WithYourIP(net.IPv4(192, 168, 0, 10)),
+		dhcpv4.WithServerIP(net.IPv4(192, 168, 0, 1)),
+		dhcpv4.WithNetmask(net.IPv4Mask(255, 255, 255, 0)),
+		dhcpv4.WithRouter(net.IPv4(192, 168, 0, 1)),
+		dhcpv4.WithLeaseTime(time.Hour),
+		dhcpv4.WithOption(&dhcpv4.OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)}),
+	} {
+		canned = mod(canned)
+	}
+	canned.SetBootFileName("pxelinux.0")
+	return canned
+}
+
+func TestStaticHandler(t *testing.T) {
+	canned := staticTestReply(t)
+	h := StaticHandler(canned)
+	conn := &recordingConn{}
+	peer := &net.UDPAddr{IP: net.IPv4zero, Port: dhcpv4.ClientPort}
+
+	discover, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	h(conn, peer, discover, nil)
+	require.Len(t, conn.written, 1)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ClientPort}, conn.written[0].addr)
+	offer, err := dhcpv4.FromBytes(conn.written[0].data)
+	require.NoError(t, err)
+	require.Equal(t, dhcpv4.MessageTypeOffer, *offer.MessageType())
+	require.Equal(t, discover.TransactionID(), offer.TransactionID())
+	require.Equal(t, discover.ClientHwAddr(), offer.ClientHwAddr())
+	require.True(t, offer.YourIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+	require.True(t, offer.ServerIPAddr().Equal(net.IPv4(192, 168, 0, 1)))
+	require.Equal(t, "pxelinux.0", offer.BootFileNameToString())
+	require.Equal(t, []net.IP{net.IPv4(192, 168, 0, 1)}, offer.Router())
+	require.Equal(t, time.Hour, offer.IPAddressLeaseTime(0))
+
+	request, err := dhcpv4.NewRequestFromOffer(offer)
+	require.NoError(t, err)
+	h(conn, peer, request, nil)
+	require.Len(t, conn.written, 2)
+	ack, err := dhcpv4.FromBytes(conn.written[1].data)
+	require.NoError(t, err)
+	require.Equal(t, dhcpv4.MessageTypeAck, *ack.MessageType())
+	require.True(t, ack.YourIPAddr().Equal(net.IPv4(192, 168, 0, 10)))
+
+	// the canned reply is left untouched
+	require.Nil(t, canned.MessageType())
+
+	// to the client port of the server
+	h(conn, peer, discover, &RequestContext{ClientPort: 6868})
+	require.Len(t, conn.written, 3)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4bcast, Port: 6868}, conn.written[2].addr)
+}
+
+func TestStaticHandlerInformAndRelayed(t *testing.T) {
+	h := StaticHandler(staticTestReply(t))
+	conn := &recordingConn{}
+
+	// an Inform gets an Ack without address, sent to the client
+	inform, err := dhcpv4.NewInform(net.HardwareAddr{1, 2, 3, 4, 5, 6}, net.IPv4(192, 168, 0, 20))
+	require.NoError(t, err)
+	h(conn, &net.UDPAddr{IP: net.IPv4(192, 168, 0, 20), Port: dhcpv4.ClientPort}, inform, nil)
+	require.Len(t, conn.written, 1)
+	require.Equal(t, &net.UDPAddr{IP: net.IPv4(192, 168, 0, 20), Port: dhcpv4.ClientPort}, conn.written[0].addr)
+	ack, err := dhcpv4.FromBytes(conn.written[0].data)
+	require.NoError(t, err)
+	require.Equal(t, dhcpv4.MessageTypeAck, *ack.MessageType())
+	require.True(t, ack.YourIPAddr().IsUnspecified())
+
+	// the replies to relayed messages go to the relay agent
+	discover, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	discover.SetGatewayIPAddr(net.IPv4(10, 0, 0, 1))
+	relay := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: dhcpv4.ServerPort}
+	h(conn, relay, discover, nil)
+	require.Len(t, conn.written, 2)
+	require.Equal(t, relay, conn.written[1].addr)
+
+	// the other messages are ignored
+	release, err := dhcpv4.NewReleaseFromACK(ack)
+	require.NoError(t, err)
+	h(conn, relay, release, nil)
+	require.Len(t, conn.written, 2)
+}
@@ -0,0 +1,94 @@
+package server4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// UnicastReplier sends replies to the hardware address of clients that have
+// no address yet. The way it does so is up to the deployment: a RawReplier
+// sends raw frames, while an ARPReplier installs an ARP entry before sending
+// the reply through the server socket.
+type UnicastReplier interface {
+	// Send sends reply from src, the server address, to the offered
+	// address (yiaddr) at the client hardware address (chaddr).
+	Send(reply *dhcpv4.DHCPv4, src net.IP) error
+	Close() error
+}
+
+// UnicastToHwAddr returns true if the reply to request must be unicast to the
+// client hardware address and to the offered address, as RFC 2131, section
+// 4.1 mandates for the clients on the local network that did not ask for
+// broadcast replies. Since such clients have no address yet, nothing can
+// resolve the offered address to their hardware address, so the replies have
+// to be sent with a RawReplier or after installing an ARP entry.
+func UnicastToHwAddr(request *dhcpv4.DHCPv4) bool {
+	if giaddr := request.GatewayIPAddr(); giaddr != nil && !giaddr.IsUnspecified() {
+		return false
+	}
+	if ciaddr := request.ClientIPAddr(); ciaddr != nil && !ciaddr.IsUnspecified() {
+		return false
+	}
+	return !request.IsBroadcast()
+}
+
+// unicastDestination returns the addresses to which a UnicastReplier sends
+// reply, or an error if it cannot send it to the client hardware address.
+func unicastDestination(reply *dhcpv4.DHCPv4) (net.IP, net.HardwareAddr, error) {
+	if reply.HwType() != iana.HwTypeEthernet || reply.HwAddrLen() != 6 {
+		return nil, nil, fmt.Errorf("cannot send to hardware address %s of type %s", reply.ClientHwAddr(), reply.HwTypeToString())
+	}
+	yiaddr := reply.YourIPAddr().To4()
+	if yiaddr == nil || yiaddr.IsUnspecified() {
+		return nil, nil, fmt.Errorf("invalid destination address %v", reply.YourIPAddr())
+	}
+	return yiaddr, reply.ClientHwAddr(), nil
+}
+
+// makeUnicastPacket wraps payload, a serialized reply, in the IPv4 and UDP
+// headers of a packet sent from srcPort of src to dstPort of dst.
+func makeUnicastPacket(src, dst net.IP, srcPort, dstPort int, payload []byte) []byte {
+	packet := make([]byte, 28, 28+len(payload))
+	ip, udp := packet[:20], packet[20:28]
+	ip[0] = 4<<4 | 5 // version 4, 20 bytes header
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(packet)+len(payload)))
+	ip[8] = 64 // TTL
+	ip[9] = 17 // UDP
+	copy(ip[12:16], src.To4())
+	copy(ip[16:20], dst.To4())
+	binary.BigEndian.PutUint16(ip[10:12], ipChecksum(ip))
+
+	binary.BigEndian.PutUint16(udp[0:2], uint16(srcPort))
+	binary.BigEndian.PutUint16(udp[2:4], uint16(dstPort))
+	binary.BigEndian.PutUint16(udp[4:6], uint16(8+len(payload)))
+	// the UDP checksum is optional over IPv4 and left out
+	return append(packet, payload...)
+}
+
+// replyPorts returns the ports from which and to which a UnicastReplier sends
+// the replies, given its own, possibly zero, settings.
+func replyPorts(serverPort, clientPort int) (int, int) {
+	if serverPort == 0 {
+		serverPort = dhcpv4.ServerPort
+	}
+	if clientPort == 0 {
+		clientPort = dhcpv4.ClientPort
+	}
+	return serverPort, clientPort
+}
+
+// ipChecksum computes the checksum of an IPv4 header.
+func ipChecksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(header[i : i+2]))
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,76 @@
+// +build linux
+
+package server4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"unsafe"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"golang.org/x/sys/unix"
+)
+
+// RawReplier sends replies straight to the hardware address of the clients
+// through an AF_PACKET socket, without going through the ARP resolution of
+// the kernel, which cannot succeed for clients that have no address yet. See
+// UnicastToHwAddr for when to use it. Opening it requires CAP_NET_RAW.
+// RawReplier implements UnicastReplier.
+type RawReplier struct {
+	// ServerPort and ClientPort, if not zero, are the ports from which and
+	// to which the replies are sent, instead of dhcpv4.ServerPort and
+	// dhcpv4.ClientPort.
+	ServerPort, ClientPort int
+
+	fd      int
+	ifindex int
+}
+
+// NewRawReplier opens a RawReplier sending on the given interface, which must
+// be an Ethernet interface.
+func NewRawReplier(ifname string) (*RawReplier, error) {
+	iface, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return nil, err
+	}
+	// protocol 0 so that the socket receives nothing
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &RawReplier{fd: fd, ifindex: iface.Index}, nil
+}
+
+// Send sends reply from the server port of src, the server address, to the
+// client port of the offered address (yiaddr), in an Ethernet frame addressed
+// to the client hardware address (chaddr).
+func (r *RawReplier) Send(reply *dhcpv4.DHCPv4, src net.IP) error {
+	yiaddr, chaddr, err := unicastDestination(reply)
+	if err != nil {
+		return err
+	}
+	if src.To4() == nil {
+		return fmt.Errorf("invalid source address %v", src)
+	}
+	addr := unix.SockaddrLinklayer{
+		Protocol: htons(unix.ETH_P_IP),
+		Ifindex:  r.ifindex,
+		Halen:    6,
+	}
+	copy(addr.Addr[:], chaddr)
+	srcPort, dstPort := replyPorts(r.ServerPort, r.ClientPort)
+	return unix.Sendto(r.fd, makeUnicastPacket(src, yiaddr, srcPort, dstPort, reply.ToBytes()), 0, &addr)
+}
+
+// Close closes the socket of the RawReplier.
+func (r *RawReplier) Close() error {
+	return unix.Close(r.fd)
+}
+
+// htons converts a short from host to network byte order.
+func htons(v uint16) uint16 {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	return *(*uint16)(unsafe.Pointer(&b[0]))
+}
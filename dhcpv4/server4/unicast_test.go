@@ -0,0 +1,71 @@
+package server4
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnicastToHwAddr(t *testing.T) {
+	request, err := dhcpv4.NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	request.SetUnicast()
+	require.True(t, UnicastToHwAddr(request))
+
+	request.SetBroadcast()
+	require.False(t, UnicastToHwAddr(request))
+
+	request.SetUnicast()
+	request.SetGatewayIPAddr(net.IPv4(10, 0, 0, 1))
+	require.False(t, UnicastToHwAddr(request))
+
+	request.SetGatewayIPAddr(net.IPv4zero)
+	request.SetClientIPAddr(net.IPv4(192, 168, 0, 10))
+	require.False(t, UnicastToHwAddr(request))
+}
+
+func TestMakeUnicastPacket(t *testing.T) {
+	payload := []byte{1, 2, 3}
+	packet := makeUnicastPacket(net.IPv4(192, 168, 0, 1), net.IPv4(192, 168, 0, 10), dhcpv4.ServerPort, dhcpv4.ClientPort, payload)
+	require.Len(t, packet, 28+len(payload))
+
+	ip := packet[:20]
+	require.Equal(t, byte(0x45), ip[0])
+	require.Equal(t, uint16(len(packet)), binary.BigEndian.Uint16(ip[2:4]))
+	require.Equal(t, byte(17), ip[9])
+	require.Equal(t, net.IP{192, 168, 0, 1}, net.IP(ip[12:16]))
+	require.Equal(t, net.IP{192, 168, 0, 10}, net.IP(ip[16:20]))
+	// a header with a valid checksum sums to zero
+	require.Equal(t, uint16(0), ipChecksum(ip))
+
+	udp := packet[20:28]
+	require.Equal(t, uint16(dhcpv4.ServerPort), binary.BigEndian.Uint16(udp[0:2]))
+	require.Equal(t, uint16(dhcpv4.ClientPort), binary.BigEndian.Uint16(udp[2:4]))
+	require.Equal(t, uint16(8+len(payload)), binary.BigEndian.Uint16(udp[4:6]))
+	require.Equal(t, payload, packet[28:])
+}
+
+func TestUnicastDestination(t *testing.T) {
+	hwaddr := net.HardwareAddr{1, 2, 3, 4, 5, 6}
+	request, err := dhcpv4.NewDiscovery(hwaddr)
+	require.NoError(t, err)
+	reply, err := dhcpv4.NewReplyFromRequest(request)
+	require.NoError(t, err)
+
+	// no offered address
+	_, _, err = unicastDestination(reply)
+	require.Error(t, err)
+
+	reply.SetYourIPAddr(net.IPv4(192, 168, 0, 10))
+	yiaddr, chaddr, err := unicastDestination(reply)
+	require.NoError(t, err)
+	require.Equal(t, net.IP{192, 168, 0, 10}, yiaddr)
+	require.Equal(t, hwaddr, chaddr)
+
+	reply.SetHwAddrLen(8)
+	_, _, err = unicastDestination(reply)
+	require.Error(t, err)
+}
@@ -0,0 +1,403 @@
+// Code generated by ianagen from the IANA registries. DO NOT EDIT.
+
+package dhcpv4
+
+// DHCP message types
+const (
+	MessageTypeDiscover         MessageType = 1  // [RFC2132]
+	MessageTypeOffer            MessageType = 2  // [RFC2132]
+	MessageTypeRequest          MessageType = 3  // [RFC2132]
+	MessageTypeDecline          MessageType = 4  // [RFC2132]
+	MessageTypeAck              MessageType = 5  // [RFC2132]
+	MessageTypeNak              MessageType = 6  // [RFC2132]
+	MessageTypeRelease          MessageType = 7  // [RFC2132]
+	MessageTypeInform           MessageType = 8  // [RFC2132]
+	MessageTypeForceRenew       MessageType = 9  // [RFC3203]
+	MessageTypeLeaseQuery       MessageType = 10 // [RFC4388]
+	MessageTypeLeaseUnassigned  MessageType = 11 // [RFC4388]
+	MessageTypeLeaseUnknown     MessageType = 12 // [RFC4388]
+	MessageTypeLeaseActive      MessageType = 13 // [RFC4388]
+	MessageTypeBulkLeaseQuery   MessageType = 14 // [RFC6926]
+	MessageTypeLeaseQueryDone   MessageType = 15 // [RFC6926]
+	MessageTypeActiveLeaseQuery MessageType = 16 // [RFC7724]
+	MessageTypeLeaseQueryStatus MessageType = 17 // [RFC7724]
+	MessageTypeTLS              MessageType = 18 // [RFC7724]
+)
+
+// MessageTypeToString maps DHCP message types to human-readable strings.
+var MessageTypeToString = map[MessageType]string{
+	MessageTypeDiscover:         "DISCOVER",
+	MessageTypeOffer:            "OFFER",
+	MessageTypeRequest:          "REQUEST",
+	MessageTypeDecline:          "DECLINE",
+	MessageTypeAck:              "ACK",
+	MessageTypeNak:              "NAK",
+	MessageTypeRelease:          "RELEASE",
+	MessageTypeInform:           "INFORM",
+	MessageTypeForceRenew:       "FORCERENEW",
+	MessageTypeLeaseQuery:       "LEASEQUERY",
+	MessageTypeLeaseUnassigned:  "LEASEUNASSIGNED",
+	MessageTypeLeaseUnknown:     "LEASEUNKNOWN",
+	MessageTypeLeaseActive:      "LEASEACTIVE",
+	MessageTypeBulkLeaseQuery:   "BULKLEASEQUERY",
+	MessageTypeLeaseQueryDone:   "LEASEQUERYDONE",
+	MessageTypeActiveLeaseQuery: "ACTIVELEASEQUERY",
+	MessageTypeLeaseQueryStatus: "LEASEQUERYSTATUS",
+	MessageTypeTLS:              "TLS",
+}
+
+// DHCPv4 Options
+const (
+	OptionPad                                        OptionCode = 0   // [RFC2132]
+	OptionSubnetMask                                 OptionCode = 1   // [RFC2132]
+	OptionTimeOffset                                 OptionCode = 2   // [RFC2132]
+	OptionRouter                                     OptionCode = 3   // [RFC2132]
+	OptionTimeServer                                 OptionCode = 4   // [RFC2132]
+	OptionNameServer                                 OptionCode = 5   // [RFC2132]
+	OptionDomainNameServer                           OptionCode = 6   // [RFC2132]
+	OptionLogServer                                  OptionCode = 7   // [RFC2132]
+	OptionQuoteServer                                OptionCode = 8   // [RFC2132]
+	OptionLPRServer                                  OptionCode = 9   // [RFC2132]
+	OptionImpressServer                              OptionCode = 10  // [RFC2132]
+	OptionResourceLocationServer                     OptionCode = 11  // [RFC2132]
+	OptionHostName                                   OptionCode = 12  // [RFC2132]
+	OptionBootFileSize                               OptionCode = 13  // [RFC2132]
+	OptionMeritDumpFile                              OptionCode = 14  // [RFC2132]
+	OptionDomainName                                 OptionCode = 15  // [RFC2132]
+	OptionSwapServer                                 OptionCode = 16  // [RFC2132]
+	OptionRootPath                                   OptionCode = 17  // [RFC2132]
+	OptionExtensionsPath                             OptionCode = 18  // [RFC2132]
+	OptionIPForwarding                               OptionCode = 19  // [RFC2132]
+	OptionNonLocalSourceRouting                      OptionCode = 20  // [RFC2132]
+	OptionPolicyFilter                               OptionCode = 21  // [RFC2132]
+	OptionMaximumDatagramAssemblySize                OptionCode = 22  // [RFC2132]
+	OptionDefaultIPTTL                               OptionCode = 23  // [RFC2132]
+	OptionPathMTUAgingTimeout                        OptionCode = 24  // [RFC2132]
+	OptionPathMTUPlateauTable                        OptionCode = 25  // [RFC2132]
+	OptionInterfaceMTU                               OptionCode = 26  // [RFC2132]
+	OptionAllSubnetsAreLocal                         OptionCode = 27  // [RFC2132]
+	OptionBroadcastAddress                           OptionCode = 28  // [RFC2132]
+	OptionPerformMaskDiscovery                       OptionCode = 29  // [RFC2132]
+	OptionMaskSupplier                               OptionCode = 30  // [RFC2132]
+	OptionPerformRouterDiscovery                     OptionCode = 31  // [RFC2132]
+	OptionRouterSolicitationAddress                  OptionCode = 32  // [RFC2132]
+	OptionStaticRoutingTable                         OptionCode = 33  // [RFC2132]
+	OptionTrailerEncapsulation                       OptionCode = 34  // [RFC2132]
+	OptionArpCacheTimeout                            OptionCode = 35  // [RFC2132]
+	OptionEthernetEncapsulation                      OptionCode = 36  // [RFC2132]
+	OptionDefaulTCPTTL                               OptionCode = 37  // [RFC2132]
+	OptionTCPKeepaliveInterval                       OptionCode = 38  // [RFC2132]
+	OptionTCPKeepaliveGarbage                        OptionCode = 39  // [RFC2132]
+	OptionNetworkInformationServiceDomain            OptionCode = 40  // [RFC2132]
+	OptionNetworkInformationServers                  OptionCode = 41  // [RFC2132]
+	OptionNTPServers                                 OptionCode = 42  // [RFC2132]
+	OptionVendorSpecificInformation                  OptionCode = 43  // [RFC2132]
+	OptionNetBIOSOverTCPIPNameServer                 OptionCode = 44  // [RFC2132]
+	OptionNetBIOSOverTCPIPDatagramDistributionServer OptionCode = 45  // [RFC2132]
+	OptionNetBIOSOverTCPIPNodeType                   OptionCode = 46  // [RFC2132]
+	OptionNetBIOSOverTCPIPScope                      OptionCode = 47  // [RFC2132]
+	OptionXWindowSystemFontServer                    OptionCode = 48  // [RFC2132]
+	OptionXWindowSystemDisplayManger                 OptionCode = 49  // [RFC2132]
+	OptionRequestedIPAddress                         OptionCode = 50  // [RFC2132]
+	OptionIPAddressLeaseTime                         OptionCode = 51  // [RFC2132]
+	OptionOptionOverload                             OptionCode = 52  // [RFC2132]
+	OptionDHCPMessageType                            OptionCode = 53  // [RFC2132]
+	OptionServerIdentifier                           OptionCode = 54  // [RFC2132]
+	OptionParameterRequestList                       OptionCode = 55  // [RFC2132]
+	OptionMessage                                    OptionCode = 56  // [RFC2132]
+	OptionMaximumDHCPMessageSize                     OptionCode = 57  // [RFC2132]
+	OptionRenewTimeValue                             OptionCode = 58  // [RFC2132]
+	OptionRebindingTimeValue                         OptionCode = 59  // [RFC2132]
+	OptionClassIdentifier                            OptionCode = 60  // [RFC2132]
+	OptionClientIdentifier                           OptionCode = 61  // [RFC2132]
+	OptionNetWareIPDomainName                        OptionCode = 62  // [RFC2242]
+	OptionNetWareIPInformation                       OptionCode = 63  // [RFC2242]
+	OptionNetworkInformationServicePlusDomain        OptionCode = 64  // [RFC2132]
+	OptionNetworkInformationServicePlusServers       OptionCode = 65  // [RFC2132]
+	OptionTFTPServerName                             OptionCode = 66  // [RFC2132]
+	OptionBootfileName                               OptionCode = 67  // [RFC2132]
+	OptionMobileIPHomeAgent                          OptionCode = 68  // [RFC2132]
+	OptionSimpleMailTransportProtocolServer          OptionCode = 69  // [RFC2132]
+	OptionPostOfficeProtocolServer                   OptionCode = 70  // [RFC2132]
+	OptionNetworkNewsTransportProtocolServer         OptionCode = 71  // [RFC2132]
+	OptionDefaultWorldWideWebServer                  OptionCode = 72  // [RFC2132]
+	OptionDefaultFingerServer                        OptionCode = 73  // [RFC2132]
+	OptionDefaultInternetRelayChatServer             OptionCode = 74  // [RFC2132]
+	OptionStreetTalkServer                           OptionCode = 75  // [RFC2132]
+	OptionStreetTalkDirectoryAssistanceServer        OptionCode = 76  // [RFC2132]
+	OptionUserClassInformation                       OptionCode = 77  // [RFC3004]
+	OptionSLPDirectoryAgent                          OptionCode = 78  // [RFC2610]
+	OptionSLPServiceScope                            OptionCode = 79  // [RFC2610]
+	OptionRapidCommit                                OptionCode = 80  // [RFC4039]
+	OptionFQDN                                       OptionCode = 81  // [RFC4702]
+	OptionRelayAgentInformation                      OptionCode = 82  // [RFC3046]
+	OptionInternetStorageNameService                 OptionCode = 83  // [RFC4174]
+	OptionNDSServers                                 OptionCode = 85  // [RFC2241]
+	OptionNDSTreeName                                OptionCode = 86  // [RFC2241]
+	OptionNDSContext                                 OptionCode = 87  // [RFC2241]
+	OptionBCMCSControllerDomainNameList              OptionCode = 88  // [RFC4280]
+	OptionBCMCSControllerIPv4AddressList             OptionCode = 89  // [RFC4280]
+	OptionAuthentication                             OptionCode = 90  // [RFC3118]
+	OptionClientLastTransactionTime                  OptionCode = 91  // [RFC4388]
+	OptionAssociatedIP                               OptionCode = 92  // [RFC4388]
+	OptionClientSystemArchitectureType               OptionCode = 93  // [RFC4578]
+	OptionClientNetworkInterfaceIdentifier           OptionCode = 94  // [RFC4578]
+	OptionLDAP                                       OptionCode = 95  // [RFC3679]
+	OptionClientMachineIdentifier                    OptionCode = 97  // [RFC4578]
+	OptionOpenGroupUserAuthentication                OptionCode = 98  // [RFC2485]
+	OptionGeoConfCivic                               OptionCode = 99  // [RFC4776]
+	OptionIEEE10031TZString                          OptionCode = 100 // [RFC4833]
+	OptionReferenceToTZDatabase                      OptionCode = 101 // [RFC4833]
+	OptionIPv6OnlyPreferred                          OptionCode = 108 // [RFC8925]
+	OptionDHCP4o6S46SADDR                            OptionCode = 109 // [RFC8539]
+	OptionNetInfoParentServerAddress                 OptionCode = 112 // [RFC3679]
+	OptionNetInfoParentServerTag                     OptionCode = 113 // [RFC3679]
+	OptionURL                                        OptionCode = 114 // [RFC8910]
+	OptionAutoConfigure                              OptionCode = 116 // [RFC2563]
+	OptionNameServiceSearch                          OptionCode = 117 // [RFC2937]
+	OptionSubnetSelection                            OptionCode = 118 // [RFC3011]
+	OptionDNSDomainSearchList                        OptionCode = 119 // [RFC3397]
+	OptionSIPServersDHCPOption                       OptionCode = 120 // [RFC3361]
+	OptionClasslessStaticRouteOption                 OptionCode = 121 // [RFC3442]
+	OptionCCC                                        OptionCode = 122 // [RFC3495]
+	OptionGeoConf                                    OptionCode = 123 // [RFC6225]
+	OptionVendorIdentifyingVendorClass               OptionCode = 124 // [RFC3925]
+	OptionVendorIdentifyingVendorSpecific            OptionCode = 125 // [RFC3925]
+	OptionTFTPServerIPAddress                        OptionCode = 128
+	OptionCallServerIPAddress                        OptionCode = 129
+	OptionDiscriminationString                       OptionCode = 130
+	OptionRemoteStatisticsServerIPAddress            OptionCode = 131
+	Option8021PVLANID                                OptionCode = 132
+	Option8021QL2Priority                            OptionCode = 133
+	OptionDiffservCodePoint                          OptionCode = 134
+	OptionHTTPProxyForPhoneSpecificApplications      OptionCode = 135
+	OptionPANAAuthenticationAgent                    OptionCode = 136 // [RFC5192]
+	OptionLoSTServer                                 OptionCode = 137 // [RFC5223]
+	OptionCAPWAPAccessControllerAddresses            OptionCode = 138 // [RFC5417]
+	OptionOPTIONIPv4AddressMoS                       OptionCode = 139 // [RFC5678]
+	OptionOPTIONIPv4FQDNMoS                          OptionCode = 140 // [RFC5678]
+	OptionSIPUAConfigurationServiceDomains           OptionCode = 141 // [RFC6011]
+	OptionOPTIONIPv4AddressANDSF                     OptionCode = 142 // [RFC6153]
+	OptionOPTIONIPv6AddressANDSF                     OptionCode = 143 // [RFC6153], now assigned to OPTION_V4_SZTP_REDIRECT
+	OptionGeoLoc                                     OptionCode = 144 // [RFC6225]
+	OptionForceRenewNonceCapable                     OptionCode = 145 // [RFC6704]
+	OptionRDNSSSelection                             OptionCode = 146 // [RFC6731]
+	OptionV4DOTSRI                                   OptionCode = 147 // [RFC8973]
+	OptionV4DOTSAddress                              OptionCode = 148 // [RFC8973]
+	OptionTFTPServerAddress                          OptionCode = 150
+	OptionStatusCode                                 OptionCode = 151 // [RFC6926]
+	OptionBaseTime                                   OptionCode = 152 // [RFC6926]
+	OptionStartTimeOfState                           OptionCode = 153 // [RFC6926]
+	OptionQueryStartTime                             OptionCode = 154 // [RFC6926]
+	OptionQueryEndTime                               OptionCode = 155 // [RFC6926]
+	OptionDHCPState                                  OptionCode = 156 // [RFC6926]
+	OptionDataSource                                 OptionCode = 157 // [RFC6926]
+	OptionV4PCPServer                                OptionCode = 158 // [RFC7291]
+	OptionV4PortParams                               OptionCode = 159 // [RFC7618]
+	OptionMUDURLV4                                   OptionCode = 161 // [RFC8520]
+	OptionV4DNR                                      OptionCode = 162 // [RFC9463]
+	OptionEtherboot                                  OptionCode = 175
+	OptionIPTelephone                                OptionCode = 176
+	OptionEtherbootPacketCableAndCableHome           OptionCode = 177
+	OptionPXELinuxMagicString                        OptionCode = 208 // [RFC5071][Deprecated]
+	OptionPXELinuxConfigFile                         OptionCode = 209 // [RFC5071]
+	OptionPXELinuxPathPrefix                         OptionCode = 210 // [RFC5071]
+	OptionPXELinuxRebootTime                         OptionCode = 211 // [RFC5071]
+	OptionOPTION6RD                                  OptionCode = 212 // [RFC5969]
+	OptionOPTIONv4AccessDomain                       OptionCode = 213 // [RFC5986]
+	OptionSubnetAllocation                           OptionCode = 220 // [RFC6656]
+	OptionVirtualSubnetAllocation                    OptionCode = 221 // [RFC6607]
+	OptionMicrosoftClasslessStaticRoute              OptionCode = 249 // private use, Microsoft equivalent of option 121
+	OptionEnd                                        OptionCode = 255 // [RFC2132]
+)
+
+// OptionCodeToString maps an OptionCode to its mnemonic name
+var OptionCodeToString = map[OptionCode]string{
+	OptionPad:                                        "Pad",
+	OptionSubnetMask:                                 "Subnet Mask",
+	OptionTimeOffset:                                 "Time Offset",
+	OptionRouter:                                     "Router",
+	OptionTimeServer:                                 "Time Server",
+	OptionNameServer:                                 "Name Server",
+	OptionDomainNameServer:                           "Domain Name Server",
+	OptionLogServer:                                  "Log Server",
+	OptionQuoteServer:                                "Quote Server",
+	OptionLPRServer:                                  "LPR Server",
+	OptionImpressServer:                              "Impress Server",
+	OptionResourceLocationServer:                     "Resource Location Server",
+	OptionHostName:                                   "Host Name",
+	OptionBootFileSize:                               "Boot File Size",
+	OptionMeritDumpFile:                              "Merit Dump File",
+	OptionDomainName:                                 "Domain Name",
+	OptionSwapServer:                                 "Swap Server",
+	OptionRootPath:                                   "Root Path",
+	OptionExtensionsPath:                             "Extensions Path",
+	OptionIPForwarding:                               "IP Forwarding enable/disable",
+	OptionNonLocalSourceRouting:                      "Non-local Source Routing enable/disable",
+	OptionPolicyFilter:                               "Policy Filter",
+	OptionMaximumDatagramAssemblySize:                "Maximum Datagram Reassembly Size",
+	OptionDefaultIPTTL:                               "Default IP Time-to-live",
+	OptionPathMTUAgingTimeout:                        "Path MTU Aging Timeout",
+	OptionPathMTUPlateauTable:                        "Path MTU Plateau Table",
+	OptionInterfaceMTU:                               "Interface MTU",
+	OptionAllSubnetsAreLocal:                         "All Subnets Are Local",
+	OptionBroadcastAddress:                           "Broadcast Address",
+	OptionPerformMaskDiscovery:                       "Perform Mask Discovery",
+	OptionMaskSupplier:                               "Mask Supplier",
+	OptionPerformRouterDiscovery:                     "Perform Router Discovery",
+	OptionRouterSolicitationAddress:                  "Router Solicitation Address",
+	OptionStaticRoutingTable:                         "Static Routing Table",
+	OptionTrailerEncapsulation:                       "Trailer Encapsulation",
+	OptionArpCacheTimeout:                            "ARP Cache Timeout",
+	OptionEthernetEncapsulation:                      "Ethernet Encapsulation",
+	OptionDefaulTCPTTL:                               "Default TCP TTL",
+	OptionTCPKeepaliveInterval:                       "TCP Keepalive Interval",
+	OptionTCPKeepaliveGarbage:                        "TCP Keepalive Garbage",
+	OptionNetworkInformationServiceDomain:            "Network Information Service Domain",
+	OptionNetworkInformationServers:                  "Network Information Servers",
+	OptionNTPServers:                                 "NTP Servers",
+	OptionVendorSpecificInformation:                  "Vendor Specific Information",
+	OptionNetBIOSOverTCPIPNameServer:                 "NetBIOS over TCP/IP Name Server",
+	OptionNetBIOSOverTCPIPDatagramDistributionServer: "NetBIOS over TCP/IP Datagram Distribution Server",
+	OptionNetBIOSOverTCPIPNodeType:                   "NetBIOS over TCP/IP Node Type",
+	OptionNetBIOSOverTCPIPScope:                      "NetBIOS over TCP/IP Scope",
+	OptionXWindowSystemFontServer:                    "X Window System Font Server",
+	OptionXWindowSystemDisplayManger:                 "X Window System Display Manager",
+	OptionRequestedIPAddress:                         "Requested IP Address",
+	OptionIPAddressLeaseTime:                         "IP Addresses Lease Time",
+	OptionOptionOverload:                             "Option Overload",
+	OptionDHCPMessageType:                            "DHCP Message Type",
+	OptionServerIdentifier:                           "Server Identifier",
+	OptionParameterRequestList:                       "Parameter Request List",
+	OptionMessage:                                    "Message",
+	OptionMaximumDHCPMessageSize:                     "Maximum DHCP Message Size",
+	OptionRenewTimeValue:                             "Renew Time Value",
+	OptionRebindingTimeValue:                         "Rebinding Time Value",
+	OptionClassIdentifier:                            "Class Identifier",
+	OptionClientIdentifier:                           "Client identifier",
+	OptionNetWareIPDomainName:                        "NetWare/IP Domain Name",
+	OptionNetWareIPInformation:                       "NetWare/IP Information",
+	OptionNetworkInformationServicePlusDomain:        "Network Information Service+ Domain",
+	OptionNetworkInformationServicePlusServers:       "Network Information Service+ Servers",
+	OptionTFTPServerName:                             "TFTP Server Name",
+	OptionBootfileName:                               "Bootfile Name",
+	OptionMobileIPHomeAgent:                          "Mobile IP Home Agent",
+	OptionSimpleMailTransportProtocolServer:          "SMTP Server",
+	OptionPostOfficeProtocolServer:                   "POP Server",
+	OptionNetworkNewsTransportProtocolServer:         "NNTP Server",
+	OptionDefaultWorldWideWebServer:                  "Default WWW Server",
+	OptionDefaultFingerServer:                        "Default Finger Server",
+	OptionDefaultInternetRelayChatServer:             "Default IRC Server",
+	OptionStreetTalkServer:                           "StreetTalk Server",
+	OptionStreetTalkDirectoryAssistanceServer:        "StreetTalk Directory Assistance Server",
+	OptionUserClassInformation:                       "User Class Information",
+	OptionSLPDirectoryAgent:                          "SLP DIrectory Agent",
+	OptionSLPServiceScope:                            "SLP Service Scope",
+	OptionRapidCommit:                                "Rapid Commit",
+	OptionFQDN:                                       "FQDN",
+	OptionRelayAgentInformation:                      "Relay Agent Information",
+	OptionInternetStorageNameService:                 "Internet Storage Name Service",
+	OptionNDSServers:                                 "NDS Servers",
+	OptionNDSTreeName:                                "NDS Tree Name",
+	OptionNDSContext:                                 "NDS Context",
+	OptionBCMCSControllerDomainNameList:              "BCMCS Controller Domain Name List",
+	OptionBCMCSControllerIPv4AddressList:             "BCMCS Controller IPv4 Address List",
+	OptionAuthentication:                             "Authentication",
+	OptionClientLastTransactionTime:                  "Client Last Transaction Time",
+	OptionAssociatedIP:                               "Associated IP",
+	OptionClientSystemArchitectureType:               "Client System Architecture Type",
+	OptionClientNetworkInterfaceIdentifier:           "Client Network Interface Identifier",
+	OptionLDAP:                                       "LDAP",
+	OptionClientMachineIdentifier:                    "Client Machine Identifier",
+	OptionOpenGroupUserAuthentication:                "OpenGroup's User Authentication",
+	OptionGeoConfCivic:                               "GEOCONF_CIVIC",
+	OptionIEEE10031TZString:                          "IEEE 1003.1 TZ String",
+	OptionReferenceToTZDatabase:                      "Reference to the TZ Database",
+	OptionIPv6OnlyPreferred:                          "IPv6-Only Preferred",
+	OptionDHCP4o6S46SADDR:                            "OPTION_DHCP4O6_S46_SADDR",
+	OptionNetInfoParentServerAddress:                 "NetInfo Parent Server Address",
+	OptionNetInfoParentServerTag:                     "NetInfo Parent Server Tag",
+	OptionURL:                                        "URL",
+	OptionAutoConfigure:                              "Auto-Configure",
+	OptionNameServiceSearch:                          "Name Service Search",
+	OptionSubnetSelection:                            "Subnet Selection",
+	OptionDNSDomainSearchList:                        "DNS Domain Search List",
+	OptionSIPServersDHCPOption:                       "SIP Servers DHCP Option",
+	OptionClasslessStaticRouteOption:                 "Classless Static Route Option",
+	OptionCCC:                                        "CCC, CableLabs Client Configuration",
+	OptionGeoConf:                                    "GeoConf",
+	OptionVendorIdentifyingVendorClass:               "Vendor-Identifying Vendor Class",
+	OptionVendorIdentifyingVendorSpecific:            "Vendor-Identifying Vendor-Specific",
+	OptionTFTPServerIPAddress:                        "TFTP Server IP Address",
+	OptionCallServerIPAddress:                        "Call Server IP Address",
+	OptionDiscriminationString:                       "Discrimination String",
+	OptionRemoteStatisticsServerIPAddress:            "RemoteStatistics Server IP Address",
+	Option8021PVLANID:                                "802.1P VLAN ID",
+	Option8021QL2Priority:                            "802.1Q L2 Priority",
+	OptionDiffservCodePoint:                          "Diffserv Code Point",
+	OptionHTTPProxyForPhoneSpecificApplications:      "HTTP Proxy for phone-specific applications",
+	OptionPANAAuthenticationAgent:                    "PANA Authentication Agent",
+	OptionLoSTServer:                                 "LoST Server",
+	OptionCAPWAPAccessControllerAddresses:            "CAPWAP Access Controller Addresses",
+	OptionOPTIONIPv4AddressMoS:                       "OPTION-IPv4_Address-MoS",
+	OptionOPTIONIPv4FQDNMoS:                          "OPTION-IPv4_FQDN-MoS",
+	OptionSIPUAConfigurationServiceDomains:           "SIP UA Configuration Service Domains",
+	OptionOPTIONIPv4AddressANDSF:                     "OPTION-IPv4_Address-ANDSF",
+	OptionOPTIONIPv6AddressANDSF:                     "OPTION-IPv6_Address-ANDSF",
+	OptionGeoLoc:                                     "GeoLoc",
+	OptionForceRenewNonceCapable:                     "FORCERENEW_NONCE_CAPABLE",
+	OptionRDNSSSelection:                             "RDNSS Selection",
+	OptionV4DOTSRI:                                   "OPTION_V4_DOTS_RI",
+	OptionV4DOTSAddress:                              "OPTION_V4_DOTS_ADDRESS",
+	OptionTFTPServerAddress:                          "TFTP Server Address",
+	OptionStatusCode:                                 "Status Code",
+	OptionBaseTime:                                   "Base Time",
+	OptionStartTimeOfState:                           "Start Time of State",
+	OptionQueryStartTime:                             "Query Start Time",
+	OptionQueryEndTime:                               "Query End Time",
+	OptionDHCPState:                                  "DHCP Staet",
+	OptionDataSource:                                 "Data Source",
+	OptionV4PCPServer:                                "OPTION_V4_PCP_SERVER",
+	OptionV4PortParams:                               "OPTION_V4_PORTPARAMS",
+	OptionMUDURLV4:                                   "OPTION_MUD_URL_V4",
+	OptionV4DNR:                                      "OPTION_V4_DNR",
+	OptionEtherboot:                                  "Etherboot",
+	OptionIPTelephone:                                "IP Telephone",
+	OptionEtherbootPacketCableAndCableHome:           "Etherboot / PacketCable and CableHome",
+	OptionPXELinuxMagicString:                        "PXELinux Magic String",
+	OptionPXELinuxConfigFile:                         "PXELinux Config File",
+	OptionPXELinuxPathPrefix:                         "PXELinux Path Prefix",
+	OptionPXELinuxRebootTime:                         "PXELinux Reboot Time",
+	OptionOPTION6RD:                                  "OPTION_6RD",
+	OptionOPTIONv4AccessDomain:                       "OPTION_V4_ACCESS_DOMAIN",
+	OptionSubnetAllocation:                           "Subnet Allocation",
+	OptionVirtualSubnetAllocation:                    "Virtual Subnet Selection",
+	OptionMicrosoftClasslessStaticRoute:              "Microsoft Classless Static Route",
+	OptionEnd:                                        "End",
+}
+
+// Status codes as defined by RFC 6926
+const (
+	StatusSuccess              StatusCode = 0 // [RFC6926]
+	StatusUnspecFail           StatusCode = 1 // [RFC6926]
+	StatusQueryTerminated      StatusCode = 2 // [RFC6926]
+	StatusMalformedQuery       StatusCode = 3 // [RFC6926]
+	StatusNotAllowed           StatusCode = 4 // [RFC6926]
+	StatusDataMissing          StatusCode = 5 // [RFC7724]
+	StatusConnectionActive     StatusCode = 6 // [RFC7724]
+	StatusCatchUpComplete      StatusCode = 7 // [RFC7724]
+	StatusTLSConnectionRefused StatusCode = 8 // [RFC7724]
+)
+
+// StatusCodeToString maps status codes to their names.
+var StatusCodeToString = map[StatusCode]string{
+	StatusSuccess:              "Success",
+	StatusUnspecFail:           "UnspecFail",
+	StatusQueryTerminated:      "QueryTerminated",
+	StatusMalformedQuery:       "MalformedQuery",
+	StatusNotAllowed:           "NotAllowed",
+	StatusDataMissing:          "DataMissing",
+	StatusConnectionActive:     "ConnectionActive",
+	StatusCatchUpComplete:      "CatchUpComplete",
+	StatusTLSConnectionRefused: "TLSConnectionRefused",
+}
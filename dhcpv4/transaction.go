@@ -0,0 +1,49 @@
+package dhcpv4
+
+import (
+	"math"
+	"time"
+)
+
+// Transaction holds the state shared by all the messages of a DHCP exchange:
+// the transaction ID, which RFC 2131 requires to be the same for the Discover
+// and the following Request, and the time the exchange started, from which
+// the secs field of each message is computed.
+type Transaction struct {
+	ID    uint32
+	Start time.Time
+}
+
+// NewTransaction returns a Transaction with a random transaction ID, started
+// now.
+func NewTransaction() (*Transaction, error) {
+	tid, err := GenerateTransactionID()
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{ID: *tid, Start: time.Now()}, nil
+}
+
+// Seconds returns the number of seconds elapsed since the transaction
+// started, as expected in the secs field. It saturates at the maximum value
+// the field can hold.
+func (t *Transaction) Seconds() uint16 {
+	elapsed := time.Since(t.Start) / time.Second
+	if elapsed < 0 {
+		return 0
+	}
+	if elapsed > math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return uint16(elapsed)
+}
+
+// WithTransaction sets the transaction ID and the secs field of the packet
+// from the given transaction.
+func WithTransaction(t *Transaction) Modifier {
+	return func(d *DHCPv4) *DHCPv4 {
+		d.SetTransactionID(t.ID)
+		d.SetNumSeconds(t.Seconds())
+		return d
+	}
+}
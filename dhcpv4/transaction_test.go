@@ -0,0 +1,46 @@
+package dhcpv4
+
+import (
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransaction(t *testing.T) {
+	tr, err := NewTransaction()
+	require.NoError(t, err)
+	require.Equal(t, uint16(0), tr.Seconds())
+	require.WithinDuration(t, time.Now(), tr.Start, time.Second)
+}
+
+func TestTransactionSeconds(t *testing.T) {
+	tr := Transaction{Start: time.Now().Add(-90 * time.Second)}
+	require.Equal(t, uint16(90), tr.Seconds())
+
+	tr.Start = time.Now().Add(-24 * time.Hour)
+	require.Equal(t, uint16(math.MaxUint16), tr.Seconds())
+
+	tr.Start = time.Now().Add(time.Minute)
+	require.Equal(t, uint16(0), tr.Seconds())
+}
+
+func TestWithTransaction(t *testing.T) {
+	tr := Transaction{ID: 0xaabbccdd, Start: time.Now().Add(-5 * time.Second)}
+	d, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	d = WithTransaction(&tr)(d)
+	require.Equal(t, uint32(0xaabbccdd), d.TransactionID())
+	require.Equal(t, uint16(5), d.NumSeconds())
+
+	offer, err := NewReplyFromRequest(d)
+	require.NoError(t, err)
+	offer.AddOption(&OptMessageType{MessageType: MessageTypeOffer})
+	offer.AddOption(&OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)})
+	request, err := NewRequestFromOffer(offer, WithTransaction(&tr))
+	require.NoError(t, err)
+	require.Equal(t, d.TransactionID(), request.TransactionID())
+	require.Equal(t, uint16(5), request.NumSeconds())
+}
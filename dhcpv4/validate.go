@@ -0,0 +1,113 @@
+package dhcpv4
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// concatenableOptions are the options whose data is a list of items, so that
+// several instances of them can be concatenated into one, as described in RFC
+// 3396. Any other option must appear only once.
+var concatenableOptions = map[OptionCode]struct{}{
+	OptionRouter:                        {},
+	OptionTimeServer:                    {},
+	OptionNameServer:                    {},
+	OptionDomainNameServer:              {},
+	OptionLogServer:                     {},
+	OptionPolicyFilter:                  {},
+	OptionStaticRoutingTable:            {},
+	OptionNTPServers:                    {},
+	OptionVendorSpecificInformation:     {},
+	OptionNetBIOSOverTCPIPNameServer:    {},
+	OptionParameterRequestList:          {},
+	OptionUserClassInformation:          {},
+	OptionRelayAgentInformation:         {},
+	OptionDNSDomainSearchList:           {},
+	OptionClasslessStaticRouteOption:    {},
+	OptionMicrosoftClasslessStaticRoute: {},
+	OptionVendorIdentifyingVendorClass:  {},
+}
+
+// clientOnlyOptions are the options that a server must not send, see RFC 2131,
+// table 3.
+var clientOnlyOptions = map[OptionCode]struct{}{
+	OptionRequestedIPAddress:     {},
+	OptionParameterRequestList:   {},
+	OptionMaximumDHCPMessageSize: {},
+}
+
+// Site-specific options, RFC 2132 section 2, are not known to the package but
+// are valid.
+const (
+	minSiteSpecificOption OptionCode = 224
+	maxSiteSpecificOption OptionCode = 254
+)
+
+// Validate runs sanity checks on the DHCPv4 packet and returns the problems
+// found, or nil if there are none. It looks for unknown opcodes, hardware
+// types and options, options that appear more than once when they cannot be
+// concatenated, a missing or duplicate End option, options after the End
+// option, a missing message type, and options that the message type does not
+// allow. BOOTP messages have no message type and are reported as such.
+func (d *DHCPv4) Validate() []error {
+	var errs []error
+	if _, ok := OpcodeToString[d.opcode]; !ok {
+		errs = append(errs, fmt.Errorf("unknown opcode %v", d.opcode))
+	}
+	if _, ok := iana.HwTypeToString[d.hwType]; !ok {
+		errs = append(errs, fmt.Errorf("unknown hardware type %v", d.hwType))
+	}
+	mt := d.MessageType()
+	seen := make(map[OptionCode]bool, len(d.options))
+	foundOptionEnd := false
+	for _, opt := range d.options {
+		code := opt.Code()
+		if foundOptionEnd {
+			if code == OptionEnd {
+				errs = append(errs, errors.New("duplicate End option"))
+			} else if code != OptionPad {
+				errs = append(errs, fmt.Errorf("option %v after End option", code))
+			}
+			continue
+		}
+		switch code {
+		case OptionEnd:
+			foundOptionEnd = true
+			continue
+		case OptionPad:
+			continue
+		}
+		if _, ok := OptionCodeToString[code]; !ok && (code < minSiteSpecificOption || code > maxSiteSpecificOption) {
+			errs = append(errs, fmt.Errorf("unknown option %d", code))
+		}
+		if _, ok := concatenableOptions[code]; seen[code] && !ok {
+			errs = append(errs, fmt.Errorf("duplicate option %v", code))
+		}
+		if mt != nil && !seen[code] && !optionAllowed(*mt, code) {
+			errs = append(errs, fmt.Errorf("option %v not allowed in %v message", code, *mt))
+		}
+		seen[code] = true
+	}
+	if !foundOptionEnd {
+		errs = append(errs, errors.New("no End option found"))
+	}
+	if mt == nil {
+		errs = append(errs, ErrNoMessageType)
+	}
+	return errs
+}
+
+// optionAllowed returns false if RFC 2131, tables 3 and 5, forbid an option
+// in messages of the given type.
+func optionAllowed(mt MessageType, code OptionCode) bool {
+	switch mt {
+	case MessageTypeOffer, MessageTypeAck, MessageTypeNak:
+		_, ok := clientOnlyOptions[code]
+		return !ok
+	case MessageTypeDiscover, MessageTypeInform:
+		return code != OptionServerIdentifier
+	}
+	return true
+}
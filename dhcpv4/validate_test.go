@@ -0,0 +1,62 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	discover, err := NewDiscovery(net.HardwareAddr{1, 2, 3, 4, 5, 6})
+	require.NoError(t, err)
+	require.Empty(t, discover.Validate())
+
+	// lists can be repeated, the other options cannot
+	discover.AddOption(&OptRouter{Routers: []net.IP{net.IPv4(192, 168, 0, 1)}})
+	discover.AddOption(&OptRouter{Routers: []net.IP{net.IPv4(192, 168, 0, 2)}})
+	require.Empty(t, discover.Validate())
+	discover.AddOption(&OptHostName{HostName: "a"})
+	discover.AddOption(&OptHostName{HostName: "b"})
+	require.Len(t, discover.Validate(), 1)
+}
+
+func TestValidateErrors(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	d.SetOpcode(OpcodeType(7))
+	d.AddOption(&OptionGeneric{OptionCode: OptionCode(200), Data: []byte{1}})
+	// site-specific options are fine
+	d.AddOption(&OptionGeneric{OptionCode: OptionCode(230), Data: []byte{1}})
+	d.AddOption(&OptionGeneric{OptionCode: OptionEnd})
+	d.AddOption(&OptionGeneric{OptionCode: OptionPad})
+	d.SetOptions(append(d.Options(), &OptHostName{HostName: "a"}))
+	errs := d.Validate()
+	require.Len(t, errs, 5, "%v", errs)
+	require.EqualError(t, errs[0], "unknown opcode Unknown")
+	require.EqualError(t, errs[1], "unknown option 200")
+	require.EqualError(t, errs[2], "duplicate End option")
+	require.EqualError(t, errs[3], "option Host Name after End option")
+	require.Equal(t, ErrNoMessageType, errs[4])
+
+	d.SetOptions(nil)
+	d.SetOpcode(OpcodeBootRequest)
+	require.Equal(t, 2, len(d.Validate()))
+}
+
+func TestValidateOptionsAllowed(t *testing.T) {
+	offer, err := New()
+	require.NoError(t, err)
+	offer = WithMessageType(MessageTypeOffer)(offer)
+	offer.SetOpcode(OpcodeBootReply)
+	offer.AddOption(&OptParameterRequestList{RequestedOpts: []OptionCode{OptionRouter}})
+	errs := offer.Validate()
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "option Parameter Request List not allowed in OFFER message")
+
+	inform, err := New()
+	require.NoError(t, err)
+	inform = WithMessageType(MessageTypeInform)(inform)
+	inform.AddOption(&OptServerIdentifier{ServerID: net.IPv4(192, 168, 0, 1)})
+	require.Len(t, inform.Validate(), 1)
+}
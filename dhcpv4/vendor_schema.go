@@ -0,0 +1,181 @@
+package dhcpv4
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// VendorSubOptionFormat is the format of the data of a vendor sub-option.
+type VendorSubOptionFormat int
+
+// Vendor sub-option formats
+const (
+	// VendorFormatBytes is opaque data, decoded as []byte.
+	VendorFormatBytes VendorSubOptionFormat = iota
+	// VendorFormatString is text, decoded as string.
+	VendorFormatString
+	// VendorFormatIPs is a list of IPv4 addresses, decoded as []net.IP.
+	VendorFormatIPs
+	// VendorFormatUint32 is a 32-bit integer, decoded as uint32.
+	VendorFormatUint32
+	// VendorFormatCodes is a list of 1-byte codes, e.g. of sub-options to
+	// send, decoded as []uint8.
+	VendorFormatCodes
+)
+
+// VendorSubOptionSpec describes a vendor sub-option.
+type VendorSubOptionSpec struct {
+	Name   string
+	Format VendorSubOptionFormat
+}
+
+// VendorSchema describes the sub-options of a vendor, by code. Its Parser can
+// be registered with RegisterVendorOptionParser or
+// RegisterEnterpriseOptionParser, so that vendors that need no specific types
+// can be dissected.
+type VendorSchema map[uint8]VendorSubOptionSpec
+
+// OptVendorSubOption is a vendor sub-option decoded according to a
+// VendorSchema. Value holds the data decoded according to the format of the
+// sub-option, or the raw data if the sub-option is not in the schema or does
+// not match its format. Only Data is serialized.
+type OptVendorSubOption struct {
+	SubCode uint8
+	Name    string
+	Value   interface{}
+	Data    []byte
+}
+
+// Code returns the code of the sub-option.
+func (o *OptVendorSubOption) Code() OptionCode {
+	return OptionCode(o.SubCode)
+}
+
+// ToBytes returns a serialized stream of bytes for this sub-option.
+func (o *OptVendorSubOption) ToBytes() []byte {
+	return append([]byte{o.SubCode, byte(len(o.Data))}, o.Data...)
+}
+
+// String returns a human-readable string.
+func (o *OptVendorSubOption) String() string {
+	if s, ok := o.Value.(string); ok {
+		return fmt.Sprintf("%s -> %q", o.Name, s)
+	}
+	return fmt.Sprintf("%s -> %v", o.Name, o.Value)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *OptVendorSubOption) MarshalJSON() ([]byte, error) {
+	return marshalOptionJSON(o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Without the schema, Value is
+// the raw data of the sub-option.
+func (o *OptVendorSubOption) UnmarshalJSON(data []byte) error {
+	var oj optionJSON
+	if err := json.Unmarshal(data, &oj); err != nil {
+		return err
+	}
+	raw, err := oj.raw()
+	if err != nil {
+		return err
+	}
+	*o = OptVendorSubOption{SubCode: uint8(raw.code), Name: oj.Name, Value: raw.data, Data: raw.data}
+	return nil
+}
+
+// Length returns the length of the data portion (excluding sub-option code
+// and byte length).
+func (o *OptVendorSubOption) Length() int {
+	return len(o.Data)
+}
+
+// Parser returns a VendorOptionParser decoding the sub-options according to
+// the schema into OptVendorSubOption.
+func (s VendorSchema) Parser() VendorOptionParser {
+	return func(data []byte) (Option, error) {
+		g, err := ParseOptionGeneric(data)
+		if err != nil {
+			return nil, err
+		}
+		code := uint8(g.OptionCode)
+		opt := &OptVendorSubOption{SubCode: code, Value: g.Data, Data: g.Data}
+		spec, ok := s[code]
+		if !ok {
+			opt.Name = fmt.Sprintf("Unknown (%d)", code)
+			return opt, nil
+		}
+		opt.Name = spec.Name
+		if v, ok := decodeVendorValue(spec.Format, g.Data); ok {
+			opt.Value = v
+		}
+		return opt, nil
+	}
+}
+
+// decodeVendorValue decodes data according to format, and returns false if
+// it does not match the format.
+func decodeVendorValue(format VendorSubOptionFormat, data []byte) (interface{}, bool) {
+	switch format {
+	case VendorFormatString:
+		return string(data), true
+	case VendorFormatIPs:
+		if len(data)%net.IPv4len != 0 {
+			return nil, false
+		}
+		ips := make([]net.IP, 0, len(data)/net.IPv4len)
+		for i := 0; i < len(data); i += net.IPv4len {
+			ips = append(ips, net.IP(data[i:i+net.IPv4len]))
+		}
+		return ips, true
+	case VendorFormatUint32:
+		if len(data) != 4 {
+			return nil, false
+		}
+		return binary.BigEndian.Uint32(data), true
+	case VendorFormatCodes:
+		return []uint8(data), true
+	}
+	return data, true
+}
+
+// CableLabsSchema describes the CableLabs sub-options of the
+// Vendor-Identifying Vendor-Specific Information option, sent by cable modems
+// and eRouters, see CL-SP-CANN-DHCP-Reg.
+var CableLabsSchema = VendorSchema{
+	1: {"Option Request", VendorFormatCodes},
+	2: {"TFTP Servers", VendorFormatIPs},
+	3: {"eRouter Container", VendorFormatBytes},
+	4: {"MIB Environment Indicator", VendorFormatBytes},
+	5: {"Modem Capabilities", VendorFormatBytes},
+}
+
+// BroadbandForumSchema describes the Broadband Forum sub-options of the
+// Vendor-Identifying Vendor-Specific Information option, sent by the devices
+// managed with TR-069, see TR-111.
+var BroadbandForumSchema = VendorSchema{
+	1: {"Device Manufacturer OUI", VendorFormatString},
+	2: {"Device Serial Number", VendorFormatString},
+	3: {"Device Product Class", VendorFormatString},
+	4: {"Gateway Manufacturer OUI", VendorFormatString},
+	5: {"Gateway Serial Number", VendorFormatString},
+	6: {"Gateway Product Class", VendorFormatString},
+}
+
+// MicrosoftSchema describes the sub-options of the Vendor Specific Information
+// option for the Windows clients, whose class identifier starts with "MSFT".
+var MicrosoftSchema = VendorSchema{
+	1: {"Disable NetBIOS", VendorFormatUint32},
+	2: {"Release DHCP Lease on Shutdown", VendorFormatUint32},
+	3: {"Default Router Metric Base", VendorFormatUint32},
+}
+
+func init() {
+	RegisterEnterpriseOptionParser(iana.EnterpriseIDCableLabs, CableLabsSchema.Parser())
+	RegisterEnterpriseOptionParser(iana.EnterpriseIDBroadbandForum, BroadbandForumSchema.Parser())
+	RegisterVendorOptionParser("MSFT", MicrosoftSchema.Parser())
+}
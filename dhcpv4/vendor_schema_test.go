@@ -0,0 +1,65 @@
+package dhcpv4
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVendorSchemaParser(t *testing.T) {
+	schema := VendorSchema{
+		1: {"Bytes", VendorFormatBytes},
+		2: {"String", VendorFormatString},
+		3: {"IPs", VendorFormatIPs},
+		4: {"Uint32", VendorFormatUint32},
+		5: {"Codes", VendorFormatCodes},
+	}
+	parser := schema.Parser()
+	for _, tt := range []struct {
+		data   []byte
+		name   string
+		value  interface{}
+		output string
+	}{
+		{[]byte{1, 1, 7}, "Bytes", []byte{7}, "Bytes -> [7]"},
+		{[]byte{2, 2, 'h', 'i'}, "String", "hi", `String -> "hi"`},
+		{[]byte{3, 8, 10, 0, 0, 1, 10, 0, 0, 2}, "IPs", []net.IP{{10, 0, 0, 1}, {10, 0, 0, 2}}, "IPs -> [10.0.0.1 10.0.0.2]"},
+		{[]byte{4, 4, 0, 0, 1, 0}, "Uint32", uint32(256), "Uint32 -> 256"},
+		{[]byte{5, 2, 1, 2}, "Codes", []uint8{1, 2}, "Codes -> [1 2]"},
+		// data not matching the format is kept raw
+		{[]byte{3, 3, 10, 0, 0}, "IPs", []byte{10, 0, 0}, "IPs -> [10 0 0]"},
+		{[]byte{4, 1, 1}, "Uint32", []byte{1}, "Uint32 -> [1]"},
+		{[]byte{9, 1, 1}, "Unknown (9)", []byte{1}, "Unknown (9) -> [1]"},
+	} {
+		opt, err := parser(tt.data)
+		require.NoError(t, err)
+		sub := opt.(*OptVendorSubOption)
+		require.Equal(t, tt.name, sub.Name)
+		require.Equal(t, tt.value, sub.Value)
+		require.Equal(t, tt.output, sub.String())
+		require.Equal(t, OptionCode(tt.data[0]), sub.Code())
+		require.Equal(t, len(tt.data)-2, sub.Length())
+		require.Equal(t, tt.data, sub.ToBytes())
+	}
+	_, err := parser([]byte{1, 3, 1})
+	require.Error(t, err)
+}
+
+func TestBuiltinVendorSchemas(t *testing.T) {
+	require.NotNil(t, LookupEnterpriseOptionParser(iana.EnterpriseIDCableLabs))
+	require.NotNil(t, LookupEnterpriseOptionParser(iana.EnterpriseIDBroadbandForum))
+
+	d, err := New()
+	require.NoError(t, err)
+	d.AddOption(&OptClassIdentifier{Identifier: "MSFT 5.0"})
+	vsi := &OptVendorSpecificInformation{}
+	vsi.AddSubOption(1, []byte{0, 0, 0, 2})
+	d.AddOption(vsi)
+	opts, err := d.VendorOptions("")
+	require.NoError(t, err)
+	require.Equal(t, []Option{
+		&OptVendorSubOption{SubCode: 1, Name: "Disable NetBIOS", Value: uint32(2), Data: []byte{0, 0, 0, 2}},
+	}, opts)
+}
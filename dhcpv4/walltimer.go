@@ -0,0 +1,78 @@
+package dhcpv4
+
+import (
+	"context"
+	"time"
+)
+
+// Go timers run on the monotonic clock, which on most platforms stops while
+// the system is suspended. A timer armed for T1 before a suspend would thus
+// fire late by the time spent sleeping, possibly well after T2 or even after
+// the lease expired. The lease timers of the Manager instead check the wall
+// clock at regular intervals, which also tells them when the system resumed.
+
+// wallClockCheckInterval is how often the lease timers check the wall clock.
+var wallClockCheckInterval = 30 * time.Second
+
+// suspendThreshold is how much the wall clock has to advance beyond the
+// monotonic clock between two checks for the system to be considered resumed
+// from a suspend.
+const suspendThreshold = 5 * time.Second
+
+// clockDrift returns how much more the wall clock advanced than the monotonic
+// clock between last and now, both returned by Clock.Now. Times without a
+// monotonic clock reading, like those of a fake Clock, never drift. It is a
+// variable so that tests can simulate a suspend.
+var clockDrift = func(last, now time.Time) time.Duration {
+	return now.Round(0).Sub(last.Round(0)) - now.Sub(last)
+}
+
+// wakeReason tells why waitUntil returned.
+type wakeReason int
+
+const (
+	// wakeDeadline means that the wall clock reached the deadline.
+	wakeDeadline wakeReason = iota
+	// wakeResumed means that the system resumed from a suspend before the
+	// deadline, so the lease has to be checked again.
+	wakeResumed
+	// wakeCancelled means that the context was cancelled, or that the
+	// channel passed to waitUntil was closed.
+	wakeCancelled
+)
+
+// waitUntil waits until the wall clock of clock reaches deadline, the system
+// resumes from a suspend, ctx is cancelled, or stop is closed, whichever
+// happens first. A zero deadline is never reached. A nil stop channel is never
+// closed.
+func waitUntil(ctx context.Context, clock Clock, deadline time.Time, stop <-chan struct{}) wakeReason {
+	deadline = deadline.Round(0)
+	last := clock.Now()
+	for {
+		wait := wallClockCheckInterval
+		if !deadline.IsZero() {
+			remaining := deadline.Sub(last.Round(0))
+			if remaining <= 0 {
+				return wakeDeadline
+			}
+			if remaining < wait {
+				wait = remaining
+			}
+		}
+		t := clock.NewTimer(wait)
+		select {
+		case <-t.C():
+		case <-stop:
+			t.Stop()
+			return wakeCancelled
+		case <-ctx.Done():
+			t.Stop()
+			return wakeCancelled
+		}
+		now := clock.Now()
+		if clockDrift(last, now) > suspendThreshold {
+			return wakeResumed
+		}
+		last = now
+	}
+}
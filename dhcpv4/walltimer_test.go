@@ -0,0 +1,48 @@
+package dhcpv4
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// simulateSuspend makes the lease timers check the clock often, and believe
+// that the system resumed from a suspend at every check, until the returned
+// function is called.
+func simulateSuspend() func() {
+	interval, drift := wallClockCheckInterval, clockDrift
+	wallClockCheckInterval = time.Millisecond
+	clockDrift = func(last, now time.Time) time.Duration { return time.Hour }
+	return func() {
+		wallClockCheckInterval, clockDrift = interval, drift
+	}
+}
+
+func TestWaitUntilDeadline(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, wakeDeadline, waitUntil(ctx, SystemClock{}, time.Now().Add(-time.Second), nil))
+	require.Equal(t, wakeDeadline, waitUntil(ctx, SystemClock{}, time.Now().Add(10*time.Millisecond), nil))
+}
+
+func TestWaitUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, wakeCancelled, waitUntil(ctx, SystemClock{}, time.Time{}, nil))
+
+	stop := make(chan struct{})
+	close(stop)
+	require.Equal(t, wakeCancelled, waitUntil(context.Background(), SystemClock{}, time.Now().Add(time.Hour), stop))
+}
+
+func TestWaitUntilResumed(t *testing.T) {
+	defer simulateSuspend()()
+	require.Equal(t, wakeResumed, waitUntil(context.Background(), SystemClock{}, time.Now().Add(time.Hour), nil))
+	require.Equal(t, wakeResumed, waitUntil(context.Background(), SystemClock{}, time.Time{}, nil))
+}
+
+func TestClockDrift(t *testing.T) {
+	last := time.Now()
+	require.Equal(t, time.Duration(0), clockDrift(last, last.Add(time.Minute)))
+}
@@ -0,0 +1,363 @@
+package dhcpv6
+
+// This module implements the server side of DHCPv6 Bulk Leasequery.
+// https://www.ietf.org/rfc/rfc5460.txt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+const (
+	// DefaultBulkLeaseQueryTimeout is the default Timeout of a
+	// BulkLeaseQueryServer, BULK_LQ_DATA_TIMEOUT in RFC 5460.
+	DefaultBulkLeaseQueryTimeout = 300 * time.Second
+	// DefaultBulkLeaseQueryMaxConns is the default MaxConns of a
+	// BulkLeaseQueryServer, BULK_LQ_MAX_CONNS in RFC 5460.
+	DefaultBulkLeaseQueryMaxConns = 10
+)
+
+// BulkLeaseQueryServer answers bulk leasequeries over TCP, streaming the
+// matching bindings of a LeaseStore. Each connection can carry several
+// queries, answered in sequence.
+type BulkLeaseQueryServer struct {
+	// Leases holds the bindings returned to the queries.
+	Leases LeaseStore
+	// ServerID is sent in the replies, as OPTION_SERVERID.
+	ServerID Duid
+	// Timeout is how long a connection can wait for a query, and a write
+	// to it can block, before it is closed, so that an idle requestor, or
+	// one that stops reading, does not hold it forever. If zero,
+	// DefaultBulkLeaseQueryTimeout is used.
+	Timeout time.Duration
+	// MaxConns is the maximum number of connections served at once. The
+	// connections accepted beyond it are closed right away. If zero,
+	// DefaultBulkLeaseQueryMaxConns is used.
+	MaxConns int
+	// Logger, if not nil, is where the server reports the connections that
+	// it failed to serve. DefaultLogger is used otherwise.
+	Logger Logger
+
+	localAddr     net.TCPAddr
+	listener      net.Listener
+	listenerMutex sync.Mutex
+}
+
+// NewBulkLeaseQueryServer initializes and returns a new BulkLeaseQueryServer
+// object. Bulk leasequeries are usually sent to the DHCP server port.
+func NewBulkLeaseQueryServer(addr net.TCPAddr, leases LeaseStore, serverID Duid) *BulkLeaseQueryServer {
+	return &BulkLeaseQueryServer{
+		Leases:    leases,
+		ServerID:  serverID,
+		localAddr: addr,
+	}
+}
+
+// LocalAddr returns the local address of the listening socket, or nil if not
+// listening
+func (s *BulkLeaseQueryServer) LocalAddr() net.Addr {
+	s.listenerMutex.Lock()
+	defer s.listenerMutex.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// ActivateAndServe starts listening, and answers the queries of each accepted
+// connection until Close is called.
+func (s *BulkLeaseQueryServer) ActivateAndServe() error {
+	s.listenerMutex.Lock()
+	if s.listener == nil {
+		l, err := net.ListenTCP("tcp6", &s.localAddr)
+		if err != nil {
+			s.listenerMutex.Unlock()
+			return err
+		}
+		s.listener = l
+	}
+	l := s.listener
+	s.listenerMutex.Unlock()
+	logger := loggerOrDefault(s.Logger)
+	logger.Printf("Bulk leasequery server listening on %s", l.Addr())
+	maxConns := s.MaxConns
+	if maxConns <= 0 {
+		maxConns = DefaultBulkLeaseQueryMaxConns
+	}
+	conns := make(chan struct{}, maxConns)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.listenerMutex.Lock()
+			closed := s.listener == nil
+			s.listenerMutex.Unlock()
+			if closed {
+				return nil
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		select {
+		case conns <- struct{}{}:
+		default:
+			logger.Printf("Too many bulk leasequery connections, closing the one from %v", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		go func() {
+			defer func() {
+				conn.Close()
+				<-conns
+			}()
+			if err := s.ServeConn(conn); err != nil {
+				logger.Printf("Error serving bulk leasequery from %v: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// Close stops the server and closes the TCP listener. The connections being
+// served are not interrupted.
+func (s *BulkLeaseQueryServer) Close() error {
+	s.listenerMutex.Lock()
+	defer s.listenerMutex.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.listener = nil
+	return err
+}
+
+// ServeConn answers the queries read from conn until it is closed by the
+// requestor. Messages on the connection are preceded by their length, as a
+// 16-bit integer. A message that is not a leasequery terminates the
+// connection, since the stream cannot be trusted anymore. If conn is a
+// net.Conn, the Timeout of the server applies to its reads and writes.
+func (s *BulkLeaseQueryServer) ServeConn(conn io.ReadWriter) error {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultBulkLeaseQueryTimeout
+	}
+	nc, _ := conn.(net.Conn)
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	if nc != nil {
+		w = bufio.NewWriter(timeoutWriter{conn: nc, timeout: timeout})
+	}
+	for {
+		if nc != nil {
+			nc.SetReadDeadline(time.Now().Add(timeout))
+		}
+		query, err := ReadTCPMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		msg, ok := query.(*DHCPv6Message)
+		if !ok || msg.Type() != MessageTypeLeaseQuery {
+			return fmt.Errorf("unexpected %s message", query.Type())
+		}
+		if err := s.answer(w, msg); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// timeoutWriter bounds each write to a connection by a deadline.
+type timeoutWriter struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (w timeoutWriter) Write(p []byte) (int, error) {
+	w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+	return w.conn.Write(p)
+}
+
+// ReadTCPMessage reads a DHCPv6 message preceded by its length, as sent over
+// TCP.
+func ReadTCPMessage(r io.Reader) (DHCPv6, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	data := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+	return FromBytes(data)
+}
+
+// WriteTCPMessage writes a DHCPv6 message preceded by its length, as sent
+// over TCP.
+func WriteTCPMessage(w io.Writer, d DHCPv6) error {
+	data := d.ToBytes()
+	if len(data) > 0xffff {
+		return fmt.Errorf("message too long: %d bytes", len(data))
+	}
+	var length [2]byte
+	binary.BigEndian.PutUint16(length[:], uint16(len(data)))
+	if _, err := w.Write(length[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// answer sends the replies to a query: the first matching binding in a
+// LEASEQUERY-REPLY, the following ones in LEASEQUERY-DATA messages and, if
+// there was at least one, a final LEASEQUERY-DONE. Queries that cannot be
+// answered get a LEASEQUERY-REPLY with the appropriate status code.
+func (s *BulkLeaseQueryServer) answer(w io.Writer, query *DHCPv6Message) error {
+	newMessage := func(messageType MessageType) *DHCPv6Message {
+		m := DHCPv6Message{messageType: messageType, transactionID: query.TransactionID()}
+		return &m
+	}
+	reply := newMessage(MessageTypeLeaseQueryReply)
+	reply.AddOption(&OptServerId{Sid: s.ServerID})
+	if cid := query.GetOneOption(OptionClientID); cid != nil {
+		reply.AddOption(cid)
+	}
+
+	match, status := queryMatcher(query)
+	if status != iana.StatusSuccess {
+		reply.AddOption(&OptStatusCode{StatusCode: status})
+		return WriteTCPMessage(w, reply)
+	}
+
+	// the bindings are collected before being written, so that a slow
+	// requestor does not hold the lock of the store
+	var bindings []Binding
+	s.Leases.Range(func(b Binding) bool {
+		if match(&b) {
+			bindings = append(bindings, b)
+		}
+		return true
+	})
+	if len(bindings) == 0 {
+		return WriteTCPMessage(w, reply)
+	}
+	now := time.Now()
+	for i := range bindings {
+		m := reply
+		if i > 0 {
+			m = newMessage(MessageTypeLeaseQueryData)
+		}
+		m.AddOption(clientData(&bindings[i], now))
+		if err := WriteTCPMessage(w, m); err != nil {
+			return err
+		}
+	}
+	return WriteTCPMessage(w, newMessage(MessageTypeLeaseQueryDone))
+}
+
+// queryMatcher returns a function selecting the bindings requested by a
+// query, or the status code to reply with if the query is not valid.
+func queryMatcher(query *DHCPv6Message) (func(b *Binding) bool, iana.StatusCode) {
+	lq, ok := query.GetOneOption(OptionLQQuery).(*OptLQQuery)
+	if !ok {
+		return nil, iana.StatusMalformedQuery
+	}
+	onLink := func(b *Binding) bool {
+		return lq.LinkAddress == nil || lq.LinkAddress.IsUnspecified() || lq.LinkAddress.Equal(b.LinkAddress)
+	}
+	switch lq.QueryType {
+	case QueryByAddress:
+		iaaddr, ok := lq.GetOneOption(OptionIAAddr).(*OptIAAddress)
+		if !ok {
+			return nil, iana.StatusMalformedQuery
+		}
+		return func(b *Binding) bool {
+			return onLink(b) && b.Contains(iaaddr.IPv6Addr)
+		}, iana.StatusSuccess
+	case QueryByClientID:
+		cid, ok := lq.GetOneOption(OptionClientID).(*OptClientId)
+		if !ok {
+			return nil, iana.StatusMalformedQuery
+		}
+		duid := cid.Cid.ToBytes()
+		return func(b *Binding) bool {
+			return onLink(b) && bytes.Equal(b.ClientID.ToBytes(), duid)
+		}, iana.StatusSuccess
+	case QueryByRelayID:
+		rid, ok := lq.GetOneOption(OptionRelayID).(*OptionGeneric)
+		if !ok {
+			return nil, iana.StatusMalformedQuery
+		}
+		return func(b *Binding) bool {
+			return onLink(b) && len(b.RelayID) > 0 && bytes.Equal(b.RelayID, rid.OptionData)
+		}, iana.StatusSuccess
+	case QueryByLinkAddress:
+		if lq.LinkAddress == nil || lq.LinkAddress.IsUnspecified() {
+			return nil, iana.StatusMalformedQuery
+		}
+		return onLink, iana.StatusSuccess
+	case QueryByRemoteID:
+		remote, ok := lq.GetOneOption(OptionRemoteID).(*OptRemoteId)
+		if !ok {
+			return nil, iana.StatusMalformedQuery
+		}
+		return func(b *Binding) bool {
+			return onLink(b) && b.RemoteID != nil &&
+				b.RemoteID.EnterpriseNumber() == remote.EnterpriseNumber() &&
+				bytes.Equal(b.RemoteID.RemoteID(), remote.RemoteID())
+		}, iana.StatusSuccess
+	default:
+		return nil, iana.StatusUnknownQueryType
+	}
+}
+
+// clientData builds the OPTION_CLIENT_DATA describing a binding.
+func clientData(b *Binding, now time.Time) *OptClientData {
+	var lease Option
+	if b.PrefixLength == 0 {
+		lease = &OptIAAddress{
+			IPv6Addr:          b.Address.To16(),
+			PreferredLifetime: b.PreferredLifetime,
+			ValidLifetime:     b.ValidLifetime,
+		}
+	} else {
+		var prefix [16]byte
+		copy(prefix[:], b.Address.To16())
+		p := OptIAPrefix{}
+		p.SetIPv6Prefix(prefix)
+		p.SetPrefixLength(b.PrefixLength)
+		p.SetPreferredLifetime(b.PreferredLifetime)
+		p.SetValidLifetime(b.ValidLifetime)
+		lease = &p
+	}
+	var clt uint32
+	if elapsed := now.Sub(b.LastTransaction); elapsed > 0 {
+		clt = uint32(elapsed / time.Second)
+	}
+	return &OptClientData{
+		Options: []Option{
+			&OptClientId{Cid: b.ClientID},
+			lease,
+			&OptCLTTime{Time: clt},
+		},
+	}
+}
@@ -0,0 +1,239 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLeaseStore() *MemoryLeaseStore {
+	s := NewMemoryLeaseStore()
+	link := net.ParseIP("2001:db8::")
+	for i := byte(1); i <= 3; i++ {
+		s.Bind(Binding{
+			ClientID: Duid{
+				Type:          DUID_LL,
+				HwType:        iana.HwTypeEthernet,
+				LinkLayerAddr: net.HardwareAddr{0, 1, 2, 3, 4, i},
+			},
+			Address:           net.IP{0x20, 1, 0xd, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, i},
+			PreferredLifetime: 300,
+			ValidLifetime:     600,
+			LastTransaction:   time.Now().Add(-time.Minute),
+			LinkAddress:       link,
+			RelayID:           []byte{0, 3, 0, 1, 0xa, 0xb, 0xc, 0xd, 0xe, 0xf},
+		})
+	}
+	return s
+}
+
+func newTestQuery(t *testing.T, lq *OptLQQuery) *DHCPv6Message {
+	d, err := NewMessage()
+	require.NoError(t, err)
+	m := d.(*DHCPv6Message)
+	m.SetMessage(MessageTypeLeaseQuery)
+	m.AddOption(lq)
+	return m
+}
+
+// runQueries sends the queries to a server serving store, and returns all
+// the messages received in response.
+func runQueries(t *testing.T, store LeaseStore, queries ...*DHCPv6Message) []DHCPv6 {
+	var in, out bytes.Buffer
+	for _, q := range queries {
+		require.NoError(t, WriteTCPMessage(&in, q))
+	}
+	s := NewBulkLeaseQueryServer(net.TCPAddr{}, store, Duid{Type: DUID_LL, LinkLayerAddr: net.HardwareAddr{1, 1, 1, 1, 1, 1}})
+	require.NoError(t, s.ServeConn(struct {
+		io.Reader
+		io.Writer
+	}{&in, &out}))
+
+	var replies []DHCPv6
+	for out.Len() > 0 {
+		m, err := ReadTCPMessage(&out)
+		require.NoError(t, err)
+		replies = append(replies, m)
+	}
+	return replies
+}
+
+func TestBulkLeaseQueryByLinkAddress(t *testing.T) {
+	q := newTestQuery(t, &OptLQQuery{QueryType: QueryByLinkAddress, LinkAddress: net.ParseIP("2001:db8::")})
+	replies := runQueries(t, newTestLeaseStore(), q)
+	require.Len(t, replies, 4)
+	require.Equal(t, MessageTypeLeaseQueryReply, replies[0].Type())
+	require.NotNil(t, replies[0].GetOneOption(OptionServerID))
+	require.Equal(t, MessageTypeLeaseQueryData, replies[1].Type())
+	require.Equal(t, MessageTypeLeaseQueryData, replies[2].Type())
+	require.Equal(t, MessageTypeLeaseQueryDone, replies[3].Type())
+	for _, r := range replies {
+		require.Equal(t, q.TransactionID(), r.(*DHCPv6Message).TransactionID())
+	}
+	for _, r := range replies[:3] {
+		data := r.GetOneOption(OptionClientData).(*OptClientData)
+		require.NotNil(t, data.GetOneOption(OptionClientID))
+		require.Equal(t, uint32(600), data.GetOneOption(OptionIAAddr).(*OptIAAddress).ValidLifetime)
+		require.InDelta(t, 60, data.GetOneOption(OptionCLTTime).(*OptCLTTime).Time, 1)
+	}
+}
+
+func TestBulkLeaseQueryByAddress(t *testing.T) {
+	q := newTestQuery(t, &OptLQQuery{
+		QueryType:   QueryByAddress,
+		LinkAddress: net.IPv6unspecified,
+		Options:     []Option{&OptIAAddress{IPv6Addr: net.ParseIP("2001:db8::2")}},
+	})
+	replies := runQueries(t, newTestLeaseStore(), q)
+	require.Len(t, replies, 2)
+	data := replies[0].GetOneOption(OptionClientData).(*OptClientData)
+	require.True(t, data.GetOneOption(OptionIAAddr).(*OptIAAddress).IPv6Addr.Equal(net.ParseIP("2001:db8::2")))
+	require.Equal(t, MessageTypeLeaseQueryDone, replies[1].Type())
+}
+
+func TestBulkLeaseQueryByClientID(t *testing.T) {
+	q := newTestQuery(t, &OptLQQuery{
+		QueryType:   QueryByClientID,
+		LinkAddress: net.IPv6unspecified,
+		Options: []Option{&OptClientId{Cid: Duid{
+			Type:          DUID_LL,
+			HwType:        iana.HwTypeEthernet,
+			LinkLayerAddr: net.HardwareAddr{0, 1, 2, 3, 4, 3},
+		}}},
+	})
+	replies := runQueries(t, newTestLeaseStore(), q)
+	require.Len(t, replies, 2)
+	data := replies[0].GetOneOption(OptionClientData).(*OptClientData)
+	require.True(t, data.GetOneOption(OptionIAAddr).(*OptIAAddress).IPv6Addr.Equal(net.ParseIP("2001:db8::3")))
+}
+
+func TestBulkLeaseQueryByRelayID(t *testing.T) {
+	q := newTestQuery(t, &OptLQQuery{
+		QueryType:   QueryByRelayID,
+		LinkAddress: net.IPv6unspecified,
+		Options: []Option{&OptionGeneric{
+			OptionCode: OptionRelayID,
+			OptionData: []byte{0, 3, 0, 1, 0xa, 0xb, 0xc, 0xd, 0xe, 0xf},
+		}},
+	})
+	require.Len(t, runQueries(t, newTestLeaseStore(), q), 4)
+}
+
+func TestBulkLeaseQueryNoBinding(t *testing.T) {
+	q := newTestQuery(t, &OptLQQuery{QueryType: QueryByLinkAddress, LinkAddress: net.ParseIP("2001:db8:1::")})
+	replies := runQueries(t, newTestLeaseStore(), q)
+	require.Len(t, replies, 1)
+	require.Equal(t, MessageTypeLeaseQueryReply, replies[0].Type())
+	require.Nil(t, replies[0].GetOneOption(OptionClientData))
+	require.Nil(t, replies[0].GetOneOption(OptionStatusCode))
+}
+
+func TestBulkLeaseQueryInvalid(t *testing.T) {
+	unknown := newTestQuery(t, &OptLQQuery{QueryType: 42, LinkAddress: net.IPv6unspecified})
+	malformed := newTestQuery(t, &OptLQQuery{QueryType: QueryByAddress, LinkAddress: net.IPv6unspecified})
+	replies := runQueries(t, newTestLeaseStore(), unknown, malformed)
+	require.Len(t, replies, 2)
+	require.Equal(t, iana.StatusUnknownQueryType, replies[0].GetOneOption(OptionStatusCode).(*OptStatusCode).StatusCode)
+	require.Equal(t, iana.StatusMalformedQuery, replies[1].GetOneOption(OptionStatusCode).(*OptStatusCode).StatusCode)
+}
+
+func TestBulkLeaseQueryNotALeaseQuery(t *testing.T) {
+	var in bytes.Buffer
+	solicit, err := NewMessage()
+	require.NoError(t, err)
+	require.NoError(t, WriteTCPMessage(&in, solicit))
+	s := NewBulkLeaseQueryServer(net.TCPAddr{}, newTestLeaseStore(), Duid{})
+	require.Error(t, s.ServeConn(struct {
+		io.Reader
+		io.Writer
+	}{&in, &bytes.Buffer{}}))
+}
+
+func TestReadTCPMessageTruncated(t *testing.T) {
+	_, err := ReadTCPMessage(bytes.NewReader([]byte{0, 10, 1, 2, 3}))
+	require.Error(t, err)
+}
+
+func TestBulkLeaseQueryIdleTimeout(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	s := NewBulkLeaseQueryServer(net.TCPAddr{}, newTestLeaseStore(), Duid{})
+	s.Timeout = 50 * time.Millisecond
+	err := s.ServeConn(server)
+	require.Error(t, err)
+	netErr, ok := err.(net.Error)
+	require.True(t, ok)
+	require.True(t, netErr.Timeout())
+}
+
+func TestBulkLeaseQueryStalledRequestor(t *testing.T) {
+	store := newTestLeaseStore()
+	server, client := net.Pipe()
+	defer client.Close()
+	s := NewBulkLeaseQueryServer(net.TCPAddr{}, store, Duid{})
+	s.Timeout = 200 * time.Millisecond
+	served := make(chan error, 1)
+	go func() {
+		served <- s.ServeConn(server)
+	}()
+	// the requestor never reads the replies
+	require.NoError(t, WriteTCPMessage(client, newTestQuery(t, &OptLQQuery{
+		QueryType:   QueryByLinkAddress,
+		LinkAddress: net.ParseIP("2001:db8::"),
+	})))
+
+	bound := make(chan struct{})
+	go func() {
+		store.Bind(Binding{ClientID: Duid{Type: DUID_LL, LinkLayerAddr: net.HardwareAddr{0, 1, 2, 3, 4, 9}}, Address: net.ParseIP("2001:db8::9")})
+		close(bound)
+	}()
+	select {
+	case <-bound:
+	case <-time.After(time.Second):
+		t.Fatal("store blocked by the stalled requestor")
+	}
+	select {
+	case err := <-served:
+		require.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not closed")
+	}
+}
+
+func TestBulkLeaseQueryMaxConns(t *testing.T) {
+	s := NewBulkLeaseQueryServer(net.TCPAddr{IP: net.IPv6loopback}, newTestLeaseStore(), Duid{})
+	s.MaxConns = 1
+	s.Logger = NopLogger{}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ActivateAndServe()
+	}()
+	var addr net.Addr
+	for i := 0; addr == nil && i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+		addr = s.LocalAddr()
+	}
+	if addr == nil {
+		t.Skip(<-done)
+	}
+	defer s.Close()
+
+	first, err := net.Dial("tcp6", addr.String())
+	require.NoError(t, err)
+	defer first.Close()
+	// the first connection is served, the second one is closed
+	require.NoError(t, WriteTCPMessage(first, newTestQuery(t, &OptLQQuery{QueryType: QueryByLinkAddress})))
+	_, err = ReadTCPMessage(first)
+	require.NoError(t, err)
+	second, err := net.Dial("tcp6", addr.String())
+	require.NoError(t, err)
+	defer second.Close()
+	second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = second.Read(make([]byte, 1))
+	require.Equal(t, io.EOF, err)
+}
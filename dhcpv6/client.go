@@ -1,6 +1,7 @@
 package dhcpv6
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -42,6 +43,12 @@ func NewClient() *Client {
 // be applied to the Request packet. A common use is to make sure that the
 // Request packet has the right options, see modifiers.go
 func (c *Client) Exchange(ifname string, solicit DHCPv6, modifiers ...Modifier) ([]DHCPv6, error) {
+	return c.ExchangeContext(context.Background(), ifname, solicit, modifiers...)
+}
+
+// ExchangeContext works like Exchange, but the exchange is aborted as soon as
+// the context is cancelled, in which case the context's error is returned.
+func (c *Client) ExchangeContext(ctx context.Context, ifname string, solicit DHCPv6, modifiers ...Modifier) ([]DHCPv6, error) {
 	conversation := make([]DHCPv6, 0)
 	var err error
 
@@ -52,7 +59,7 @@ func (c *Client) Exchange(ifname string, solicit DHCPv6, modifiers ...Modifier)
 			return conversation, err
 		}
 	}
-	solicit, advertise, err := c.Solicit(ifname, solicit, modifiers...)
+	solicit, advertise, err := c.solicit(ctx, ifname, solicit, modifiers...)
 	conversation = append(conversation, solicit)
 	if err != nil {
 		return conversation, err
@@ -67,7 +74,7 @@ func (c *Client) Exchange(ifname string, solicit DHCPv6, modifiers ...Modifier)
 			return conversation, err
 		}
 	}
-	request, reply, err := c.Request(ifname, advertise, nil, modifiers...)
+	request, reply, err := c.request(ctx, ifname, advertise, nil, modifiers...)
 	if request != nil {
 		conversation = append(conversation, request)
 	}
@@ -78,7 +85,7 @@ func (c *Client) Exchange(ifname string, solicit DHCPv6, modifiers ...Modifier)
 	return conversation, nil
 }
 
-func (c *Client) sendReceive(ifname string, packet DHCPv6, expectedType MessageType) (DHCPv6, error) {
+func (c *Client) sendReceive(ctx context.Context, ifname string, packet DHCPv6, expectedType MessageType) (DHCPv6, error) {
 	if packet == nil {
 		return nil, fmt.Errorf("Packet to send cannot be nil")
 	}
@@ -86,7 +93,7 @@ func (c *Client) sendReceive(ifname string, packet DHCPv6, expectedType MessageT
 		// infer the expected type from the packet being sent
 		if packet.Type() == MessageTypeSolicit {
 			expectedType = MessageTypeAdvertise
-		} else if packet.Type() == MessageTypeRequest {
+		} else if packet.Type() == MessageTypeRequest || packet.Type() == MessageTypeInformationRequest {
 			expectedType = MessageTypeReply
 		} else if packet.Type() == MessageTypeRelayForward {
 			expectedType = MessageTypeRelayReply
@@ -150,11 +157,20 @@ func (c *Client) sendReceive(ifname string, packet DHCPv6, expectedType MessageT
 	// wait for a reply
 	oobdata := []byte{} // ignoring oob data
 	conn.SetReadDeadline(time.Now().Add(c.ReadTimeout))
+	// unblock the pending read if the context is cancelled
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
 	var (
 		adv       DHCPv6
 		isMessage bool
 	)
-	defer conn.Close()
 	msg, ok := packet.(*DHCPv6Message)
 	if ok {
 		isMessage = true
@@ -163,6 +179,9 @@ func (c *Client) sendReceive(ifname string, packet DHCPv6, expectedType MessageT
 		buf := make([]byte, MaxUDPReceivedPacketSize)
 		n, _, _, _, err := conn.ReadMsgUDP(buf, oobdata)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			return nil, err
 		}
 		adv, err = FromBytes(buf[:n])
@@ -192,6 +211,10 @@ func (c *Client) sendReceive(ifname string, packet DHCPv6, expectedType MessageT
 // Solicit sends a SOLICIT, return the solicit, an ADVERTISE (if not nil), and
 // an error if any
 func (c *Client) Solicit(ifname string, solicit DHCPv6, modifiers ...Modifier) (DHCPv6, DHCPv6, error) {
+	return c.solicit(context.Background(), ifname, solicit, modifiers...)
+}
+
+func (c *Client) solicit(ctx context.Context, ifname string, solicit DHCPv6, modifiers ...Modifier) (DHCPv6, DHCPv6, error) {
 	var err error
 	if solicit == nil {
 		solicit, err = NewSolicitForInterface(ifname)
@@ -202,13 +225,17 @@ func (c *Client) Solicit(ifname string, solicit DHCPv6, modifiers ...Modifier) (
 	for _, mod := range modifiers {
 		solicit = mod(solicit)
 	}
-	advertise, err := c.sendReceive(ifname, solicit, MessageTypeNone)
+	advertise, err := c.sendReceive(ctx, ifname, solicit, MessageTypeNone)
 	return solicit, advertise, err
 }
 
 // Request sends a REQUEST built from an ADVERTISE if no REQUEST is specified.
 // It returns the request, a reply if not nil, and an error if any
 func (c *Client) Request(ifname string, advertise, request DHCPv6, modifiers ...Modifier) (DHCPv6, DHCPv6, error) {
+	return c.request(context.Background(), ifname, advertise, request, modifiers...)
+}
+
+func (c *Client) request(ctx context.Context, ifname string, advertise, request DHCPv6, modifiers ...Modifier) (DHCPv6, DHCPv6, error) {
 	if request == nil {
 		var err error
 		request, err = NewRequestFromAdvertise(advertise)
@@ -219,6 +246,22 @@ func (c *Client) Request(ifname string, advertise, request DHCPv6, modifiers ...
 	for _, mod := range modifiers {
 		request = mod(request)
 	}
-	reply, err := c.sendReceive(ifname, request, MessageTypeNone)
+	reply, err := c.sendReceive(ctx, ifname, request, MessageTypeNone)
 	return request, reply, err
 }
+
+// InformationRequest sends an INFORMATION-REQUEST, built with
+// NewInformationRequest for example, and returns the REPLY of the server.
+func (c *Client) InformationRequest(ifname string, request DHCPv6, modifiers ...Modifier) (DHCPv6, error) {
+	return c.InformationRequestContext(context.Background(), ifname, request, modifiers...)
+}
+
+// InformationRequestContext works like InformationRequest, but stops waiting
+// for the reply as soon as the context is cancelled, in which case the
+// context's error is returned.
+func (c *Client) InformationRequestContext(ctx context.Context, ifname string, request DHCPv6, modifiers ...Modifier) (DHCPv6, error) {
+	for _, mod := range modifiers {
+		request = mod(request)
+	}
+	return c.sendReceive(ctx, ifname, request, MessageTypeNone)
+}
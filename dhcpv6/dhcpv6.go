@@ -29,6 +29,9 @@ type DHCPv6 interface {
 type Modifier func(d DHCPv6) DHCPv6
 
 func FromBytes(data []byte) (DHCPv6, error) {
+	if len(data) == 0 {
+		return nil, ErrShortPacket
+	}
 	var (
 		isRelay     = false
 		headerSize  int
@@ -43,7 +46,7 @@ func FromBytes(data []byte) (DHCPv6, error) {
 		headerSize = MessageHeaderSize
 	}
 	if len(data) < headerSize {
-		return nil, fmt.Errorf("Invalid header size: shorter than %v bytes", headerSize)
+		return nil, ErrShortPacket
 	}
 	if isRelay {
 		var (
@@ -140,7 +143,7 @@ func DecapsulateRelay(l DHCPv6) (DHCPv6, error) {
 	}
 	opt := l.GetOneOption(OptionRelayMsg)
 	if opt == nil {
-		return nil, fmt.Errorf("No OptRelayMsg found")
+		return nil, ErrNoRelayMessage
 	}
 	relayOpt := opt.(*OptRelayMsg)
 	if relayOpt.RelayMessage() == nil {
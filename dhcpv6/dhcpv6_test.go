@@ -221,6 +221,71 @@ func TestNewMessageTypeSolicitWithCID(t *testing.T) {
 	require.Equal(t, len(opts), 2)
 }
 
+func TestNewInformationRequest(t *testing.T) {
+	duid := Duid{Type: DUID_LL, HwType: iana.HwTypeEthernet, LinkLayerAddr: net.HardwareAddr{1, 2, 3, 4, 5, 6}}
+	d, err := NewInformationRequest(duid)
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeInformationRequest, d.Type())
+	require.Equal(t, &OptClientId{Cid: duid}, d.GetOneOption(OptionClientID))
+	require.NotNil(t, d.GetOneOption(OptionORO))
+	require.NotNil(t, d.GetOneOption(OptionElapsedTime))
+	require.Nil(t, d.GetOneOption(OptionIANA))
+}
+
+func TestNewRequestFromAdvertise(t *testing.T) {
+	duid := Duid{Type: DUID_LL, HwType: iana.HwTypeEthernet, LinkLayerAddr: net.HardwareAddr{1, 2, 3, 4, 5, 6}}
+	adv := DHCPv6Message{}
+	adv.SetMessage(MessageTypeAdvertise)
+	adv.SetTransactionID(0xabcdef)
+	adv.AddOption(&OptClientId{Cid: duid})
+	adv.AddOption(&OptServerId{Sid: duid})
+	_, err := NewRequestFromAdvertise(&adv)
+	require.Error(t, err, "should get error without IA")
+
+	iaPd := &OptIAForPrefixDelegation{}
+	adv.AddOption(iaPd)
+	req, err := NewRequestFromAdvertise(&adv)
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeRequest, req.Type())
+	require.Equal(t, adv.TransactionID(), req.(*DHCPv6Message).TransactionID())
+	require.Equal(t, &OptClientId{Cid: duid}, req.GetOneOption(OptionClientID))
+	require.Equal(t, &OptServerId{Sid: duid}, req.GetOneOption(OptionServerID))
+	require.Equal(t, []Option{iaPd}, req.GetOption(OptionIAPD))
+
+	adv.SetOptions([]Option{&OptClientId{Cid: duid}, iaPd})
+	_, err = NewRequestFromAdvertise(&adv)
+	require.Equal(t, ErrNoServerID, err)
+}
+
+func TestNewReplyFromMessage(t *testing.T) {
+	msg := DHCPv6Message{}
+	msg.SetTransactionID(0xabcdef)
+
+	// INFORMATION-REQUEST may come without Client ID
+	msg.SetMessage(MessageTypeInformationRequest)
+	rep, err := NewReplyFromMessage(&msg)
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeReply, rep.Type())
+	require.Nil(t, rep.GetOneOption(OptionClientID))
+
+	msg.SetMessage(MessageTypeDecline)
+	_, err = NewReplyFromMessage(&msg)
+	require.Equal(t, ErrNoClientID, err)
+	msg.AddOption(&OptClientId{})
+	rep, err = NewReplyFromMessage(&msg)
+	require.NoError(t, err)
+	require.NotNil(t, rep.GetOneOption(OptionClientID))
+
+	// SOLICIT only with Rapid Commit
+	msg.SetMessage(MessageTypeSolicit)
+	_, err = NewReplyFromMessage(&msg)
+	require.Error(t, err)
+	msg.AddOption(&OptionGeneric{OptionCode: OptionRapidCommit})
+	rep, err = NewReplyFromMessage(&msg)
+	require.NoError(t, err)
+	require.NotNil(t, rep.GetOneOption(OptionRapidCommit))
+}
+
 
 func TestIsUsingUEFIArchTypeTrue(t *testing.T) {
 	msg := DHCPv6Message{}
@@ -5,7 +5,6 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"log"
 	"net"
 	"time"
 
@@ -109,6 +108,31 @@ func NewSolicitForInterface(ifname string, modifiers ...Modifier) (DHCPv6, error
 	return NewSolicitWithCID(duid, modifiers...)
 }
 
+// NewInformationRequest creates a new INFORMATION-REQUEST message with CID,
+// asking for the DNS servers and the domain search list. It carries no IA,
+// since the client only wants configuration options, see RFC 8415 section
+// 18.2.6.
+func NewInformationRequest(duid Duid, modifiers ...Modifier) (DHCPv6, error) {
+	d, err := NewMessage()
+	if err != nil {
+		return nil, err
+	}
+	d.(*DHCPv6Message).SetMessage(MessageTypeInformationRequest)
+	d.AddOption(&OptClientId{Cid: duid})
+	oro := new(OptRequestedOption)
+	oro.SetRequestedOptions([]OptionCode{
+		OptionDNSRecursiveNameServer,
+		OptionDomainSearchList,
+	})
+	d.AddOption(oro)
+	d.AddOption(&OptElapsedTime{})
+	// Apply modifiers
+	for _, mod := range modifiers {
+		d = mod(d)
+	}
+	return d, nil
+}
+
 // NewAdvertiseFromSolicit creates a new ADVERTISE packet based on an SOLICIT packet.
 func NewAdvertiseFromSolicit(solicit DHCPv6, modifiers ...Modifier) (DHCPv6, error) {
 	if solicit == nil {
@@ -128,7 +152,7 @@ func NewAdvertiseFromSolicit(solicit DHCPv6, modifiers ...Modifier) (DHCPv6, err
 	// add Client ID
 	cid := sol.GetOneOption(OptionClientID)
 	if cid == nil {
-		return nil, errors.New("Client ID cannot be nil in SOLICIT when building ADVERTISE")
+		return nil, ErrNoClientID
 	}
 	adv.AddOption(cid)
 
@@ -141,7 +165,8 @@ func NewAdvertiseFromSolicit(solicit DHCPv6, modifiers ...Modifier) (DHCPv6, err
 }
 
 // NewRequestFromAdvertise creates a new REQUEST packet based on an ADVERTISE
-// packet options.
+// packet options. The Client ID, the Server ID and the IAs of the ADVERTISE
+// are copied, and there must be at least one IA_NA or IA_PD.
 func NewRequestFromAdvertise(advertise DHCPv6, modifiers ...Modifier) (DHCPv6, error) {
 	if advertise == nil {
 		return nil, fmt.Errorf("ADVERTISE cannot be nil")
@@ -160,23 +185,25 @@ func NewRequestFromAdvertise(advertise DHCPv6, modifiers ...Modifier) (DHCPv6, e
 	// add Client ID
 	cid := adv.GetOneOption(OptionClientID)
 	if cid == nil {
-		return nil, fmt.Errorf("Client ID cannot be nil in ADVERTISE when building REQUEST")
+		return nil, ErrNoClientID
 	}
 	req.AddOption(cid)
 	// add Server ID
 	sid := adv.GetOneOption(OptionServerID)
 	if sid == nil {
-		return nil, fmt.Errorf("Server ID cannot be nil in ADVERTISE when building REQUEST")
+		return nil, ErrNoServerID
 	}
 	req.AddOption(sid)
 	// add Elapsed Time
 	req.AddOption(&OptElapsedTime{})
-	// add IA_NA
-	iaNa := adv.GetOneOption(OptionIANA)
-	if iaNa == nil {
-		return nil, fmt.Errorf("IA_NA cannot be nil in ADVERTISE when building REQUEST")
+	// add IA_NA and IA_PD
+	ias := append(adv.GetOption(OptionIANA), adv.GetOption(OptionIAPD)...)
+	if len(ias) == 0 {
+		return nil, fmt.Errorf("IA_NA or IA_PD required in ADVERTISE when building REQUEST")
+	}
+	for _, ia := range ias {
+		req.AddOption(ia)
 	}
-	req.AddOption(iaNa)
 	// add OptRequestedOption
 	oro := OptRequestedOption{}
 	oro.SetRequestedOptions([]OptionCode{
@@ -202,13 +229,30 @@ func NewRequestFromAdvertise(advertise DHCPv6, modifiers ...Modifier) (DHCPv6, e
 // NewReplyFromDHCPv6Message creates a new REPLY packet based on a
 // DHCPv6Message. The function is to be used when generating a reply to
 // REQUEST, CONFIRM, RENEW, REBIND, RELEASE and INFORMATION-REQUEST packets.
+//
+// Deprecated: use NewReplyFromMessage, which also answers DECLINE messages
+// and SOLICIT messages with Rapid Commit.
 func NewReplyFromDHCPv6Message(message DHCPv6, modifiers ...Modifier) (DHCPv6, error) {
+	return NewReplyFromMessage(message, modifiers...)
+}
+
+// NewReplyFromMessage creates a new REPLY packet based on a DHCPv6Message, to
+// be used when generating a reply to REQUEST, CONFIRM, RENEW, REBIND,
+// RELEASE, DECLINE and INFORMATION-REQUEST packets, and to SOLICIT packets
+// with a Rapid Commit option, in which case the REPLY gets one too. The Client
+// ID is copied; only an INFORMATION-REQUEST may come without one.
+func NewReplyFromMessage(message DHCPv6, modifiers ...Modifier) (DHCPv6, error) {
 	if message == nil {
 		return nil, errors.New("DHCPv6Message cannot be nil")
 	}
 	switch message.Type() {
 	case MessageTypeRequest, MessageTypeConfirm, MessageTypeRenew,
-		MessageTypeRebind, MessageTypeRelease, MessageTypeInformationRequest:
+		MessageTypeRebind, MessageTypeRelease, MessageTypeDecline,
+		MessageTypeInformationRequest:
+	case MessageTypeSolicit:
+		if message.GetOneOption(OptionRapidCommit) == nil {
+			return nil, errors.New("Cannot create REPLY from a SOLICIT without Rapid Commit")
+		}
 	default:
 		return nil, errors.New("Cannot create REPLY from the passed message type set")
 	}
@@ -222,10 +266,14 @@ func NewReplyFromDHCPv6Message(message DHCPv6, modifiers ...Modifier) (DHCPv6, e
 	rep.SetTransactionID(msg.TransactionID())
 	// add Client ID
 	cid := message.GetOneOption(OptionClientID)
-	if cid == nil {
-		return nil, errors.New("Client ID cannot be nil when building REPLY")
+	if cid != nil {
+		rep.AddOption(cid)
+	} else if message.Type() != MessageTypeInformationRequest {
+		return nil, ErrNoClientID
+	}
+	if message.Type() == MessageTypeSolicit {
+		rep.AddOption(&OptionGeneric{OptionCode: OptionRapidCommit})
 	}
-	rep.AddOption(cid)
 
 	// apply modifiers
 	d := DHCPv6(&rep)
@@ -239,15 +287,10 @@ func (d *DHCPv6Message) Type() MessageType {
 	return d.messageType
 }
 
+// SetMessage sets the message type. Unknown types are accepted, and so are the
+// relay message types, although a DHCPv6Message cannot be serialized as a
+// relay message; use EncapsulateRelay for that.
 func (d *DHCPv6Message) SetMessage(messageType MessageType) {
-	msgString := messageType.String()
-	if msgString == "" {
-		log.Printf("Warning: unknown DHCPv6 message type: %v", messageType)
-	}
-	if messageType == MessageTypeRelayForward || messageType == MessageTypeRelayReply {
-		log.Printf("Warning: using a RELAY message type with a non-relay message: %v (%v)",
-			msgString, messageType)
-	}
 	d.messageType = messageType
 }
 
@@ -259,12 +302,9 @@ func (d *DHCPv6Message) TransactionID() uint32 {
 	return d.transactionID
 }
 
+// SetTransactionID sets the transaction ID, truncating it to 24 bits.
 func (d *DHCPv6Message) SetTransactionID(tid uint32) {
-	ttid := tid & 0x00ffffff
-	if ttid != tid {
-		log.Printf("Warning: truncating transaction ID that is longer than 24 bits: %v", tid)
-	}
-	d.transactionID = ttid
+	d.transactionID = tid & 0x00ffffff
 }
 
 func (d *DHCPv6Message) SetOptions(options []Option) {
@@ -3,7 +3,6 @@ package dhcpv6
 import (
 	"errors"
 	"fmt"
-	"log"
 	"net"
 )
 
@@ -63,8 +62,10 @@ func (r *DHCPv6Relay) ToBytes() []byte {
 	return ret
 }
 
+// MessageType returns the message type.
+//
+// Deprecated: use Type instead.
 func (r *DHCPv6Relay) MessageType() MessageType {
-	log.Printf("Warning: DHCPv6Relay.MessageType() is deprecated and will be removed, use DHCPv6Relay.Type() instead")
 	return r.messageType
 }
 
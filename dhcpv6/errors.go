@@ -0,0 +1,39 @@
+package dhcpv6
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrShortPacket is returned when parsing a packet shorter than the DHCPv6
+// message or relay message header.
+var ErrShortPacket = errors.New("packet shorter than the DHCPv6 header")
+
+// ErrNoRelayMessage is returned when a relay message has no Relay Message
+// option to decapsulate.
+var ErrNoRelayMessage = errors.New("no Relay Message option")
+
+// ErrNoClientID is returned when a message has no Client ID option, but one
+// is required, e.g. to build a reply to it.
+var ErrNoClientID = errors.New("no Client ID option")
+
+// ErrNoServerID is returned when a message has no Server ID option, but one
+// is required, e.g. to build a Request from an Advertise.
+var ErrNoServerID = errors.New("no Server ID option")
+
+// OptionParseError is returned when an option of a packet cannot be parsed.
+type OptionParseError struct {
+	// Code is the code of the option.
+	Code OptionCode
+	// Err is the reason the option cannot be parsed.
+	Err error
+}
+
+func (e *OptionParseError) Error() string {
+	return fmt.Sprintf("cannot parse option %v: %v", e.Code, e.Err)
+}
+
+// Unwrap returns the reason the option cannot be parsed.
+func (e *OptionParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,42 @@
+package dhcpv6
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromBytesShortPacket(t *testing.T) {
+	for _, data := range [][]byte{nil, {1, 2}, {12, 0, 1, 2}} {
+		_, err := FromBytes(data)
+		require.Equal(t, ErrShortPacket, err)
+	}
+}
+
+func TestOptionParseError(t *testing.T) {
+	// an elapsed time option holds 2 bytes, not 1
+	_, err := ParseOption([]byte{0, 8, 0, 1, 0})
+	require.Error(t, err)
+	perr, ok := err.(*OptionParseError)
+	require.True(t, ok, "should get an OptionParseError")
+	require.Equal(t, OptionElapsedTime, perr.Code)
+
+	// truncated option
+	_, err = ParseOption([]byte{0, 8, 0, 2, 0})
+	perr, ok = err.(*OptionParseError)
+	require.True(t, ok, "should get an OptionParseError")
+	require.Equal(t, OptionElapsedTime, perr.Code)
+}
+
+func TestMissingIDErrors(t *testing.T) {
+	solicit, err := NewMessage()
+	require.NoError(t, err)
+	_, err = NewAdvertiseFromSolicit(solicit)
+	require.Equal(t, ErrNoClientID, err)
+
+	relay, err := EncapsulateRelay(solicit, MessageTypeRelayForward, nil, nil)
+	require.NoError(t, err)
+	relay.SetOptions(nil)
+	_, err = DecapsulateRelay(relay)
+	require.Equal(t, ErrNoRelayMessage, err)
+}
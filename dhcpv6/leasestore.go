@@ -0,0 +1,84 @@
+package dhcpv6
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// Binding associates an address, or a delegated prefix, with the client it
+// was handed out to.
+type Binding struct {
+	ClientID Duid
+	// Address is the leased address, or the delegated prefix if
+	// PrefixLength is not zero.
+	Address           net.IP
+	PrefixLength      uint8
+	PreferredLifetime uint32
+	ValidLifetime     uint32
+	// LastTransaction is the time the server last heard from the client.
+	// The lifetimes count from it.
+	LastTransaction time.Time
+	// LinkAddress identifies the link of the client. RelayID and RemoteID
+	// identify the relay agent through which the client was served, if
+	// any: RelayID is the DUID of the relay, as found in its OPTION_RELAY_ID.
+	LinkAddress net.IP
+	RelayID     []byte
+	RemoteID    *OptRemoteId
+}
+
+// Contains returns true if the binding is for the given address, or for a
+// prefix including it.
+func (b *Binding) Contains(ip net.IP) bool {
+	if b.PrefixLength == 0 {
+		return b.Address.Equal(ip)
+	}
+	prefix := net.IPNet{IP: b.Address, Mask: net.CIDRMask(int(b.PrefixLength), 128)}
+	return prefix.Contains(ip)
+}
+
+// LeaseStore keeps track of the bindings of a server. Implementations must be
+// safe for concurrent use.
+type LeaseStore interface {
+	// Range calls f for each binding, in no particular order, until f
+	// returns false.
+	Range(f func(b Binding) bool)
+}
+
+// MemoryLeaseStore is an in-memory LeaseStore, indexed by address.
+type MemoryLeaseStore struct {
+	mutex    sync.RWMutex
+	bindings map[string]Binding
+}
+
+// NewMemoryLeaseStore returns an empty MemoryLeaseStore.
+func NewMemoryLeaseStore() *MemoryLeaseStore {
+	return &MemoryLeaseStore{bindings: make(map[string]Binding)}
+}
+
+// Bind stores a binding, replacing the previous binding of the same address
+// or prefix if any.
+func (s *MemoryLeaseStore) Bind(b Binding) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.bindings[string(b.Address.To16())] = b
+}
+
+// Release removes the binding of an address or prefix, if any.
+func (s *MemoryLeaseStore) Release(ip net.IP) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.bindings, string(ip.To16()))
+}
+
+// Range implements LeaseStore.Range. The store cannot be modified until f
+// returns false or all the bindings are visited.
+func (s *MemoryLeaseStore) Range(f func(b Binding) bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, b := range s.bindings {
+		if !f(b) {
+			return
+		}
+	}
+}
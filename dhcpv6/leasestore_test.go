@@ -0,0 +1,41 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindingContains(t *testing.T) {
+	addr := Binding{Address: net.ParseIP("2001:db8::1")}
+	require.True(t, addr.Contains(net.ParseIP("2001:db8::1")))
+	require.False(t, addr.Contains(net.ParseIP("2001:db8::2")))
+
+	prefix := Binding{Address: net.ParseIP("2001:db8:1::"), PrefixLength: 48}
+	require.True(t, prefix.Contains(net.ParseIP("2001:db8:1::42")))
+	require.False(t, prefix.Contains(net.ParseIP("2001:db8:2::42")))
+}
+
+func TestMemoryLeaseStore(t *testing.T) {
+	s := NewMemoryLeaseStore()
+	s.Bind(Binding{Address: net.ParseIP("2001:db8::1"), ValidLifetime: 10})
+	s.Bind(Binding{Address: net.ParseIP("2001:db8::2")})
+	s.Bind(Binding{Address: net.ParseIP("2001:db8::1"), ValidLifetime: 20})
+
+	var bindings []Binding
+	s.Range(func(b Binding) bool {
+		bindings = append(bindings, b)
+		return true
+	})
+	require.Len(t, bindings, 2)
+
+	s.Release(net.ParseIP("2001:db8::2"))
+	bindings = nil
+	s.Range(func(b Binding) bool {
+		bindings = append(bindings, b)
+		return false
+	})
+	require.Len(t, bindings, 1)
+	require.Equal(t, uint32(20), bindings[0].ValidLifetime)
+}
@@ -0,0 +1,19 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerOrDefault(t *testing.T) {
+	require.Equal(t, DefaultLogger, loggerOrDefault(nil))
+	var buf bytes.Buffer
+	l := log.New(&buf, "", 0)
+	loggerOrDefault(l).Printf("hello %d", 1)
+	require.Equal(t, "hello 1\n", buf.String())
+	// the default discards everything
+	NopLogger{}.Printf("hello %d", 2)
+}
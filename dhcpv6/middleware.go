@@ -0,0 +1,111 @@
+package dhcpv6
+
+import (
+	"net"
+)
+
+// Middleware wraps a Handler, to act on the messages before they reach it or
+// on the replies it sends.
+type Middleware func(Handler) Handler
+
+// Chain wraps handler with the given middlewares, the first one being the
+// first to see the messages.
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// protocolOptions are the options that drive the protocol rather than carry
+// configuration. They are sent whether or not the client requested them.
+var protocolOptions = map[OptionCode]struct{}{
+	OptionClientID:     {},
+	OptionServerID:     {},
+	OptionIANA:         {},
+	OptionIATA:         {},
+	OptionIAPD:         {},
+	OptionPreference:   {},
+	OptionRelayMsg:     {},
+	OptionAuth:         {},
+	OptionUnicast:      {},
+	OptionStatusCode:   {},
+	OptionRapidCommit:  {},
+	OptionInterfaceID:  {},
+	OptionReconfAccept: {},
+}
+
+// TrimUnrequestedOptions returns a middleware removing from the Advertise and
+// Reply messages that the handler sends the options that the client did not
+// request in its option request option. The options driving the protocol, such
+// as the identifiers and the IAs, are kept, as well as the ones in keep. The
+// replies encapsulated in Relay-Reply messages are trimmed too.
+func TrimUnrequestedOptions(keep ...OptionCode) Middleware {
+	return func(next Handler) Handler {
+		return func(conn net.PacketConn, peer net.Addr, m DHCPv6) {
+			inner, err := innerMessage(m)
+			if err != nil {
+				next(conn, peer, m)
+				return
+			}
+			allowed := make(map[OptionCode]struct{}, len(protocolOptions)+len(keep))
+			for code := range protocolOptions {
+				allowed[code] = struct{}{}
+			}
+			for _, code := range keep {
+				allowed[code] = struct{}{}
+			}
+			if oro, ok := inner.GetOneOption(OptionORO).(*OptRequestedOption); ok {
+				for _, code := range oro.RequestedOptions() {
+					allowed[code] = struct{}{}
+				}
+			}
+			next(replyTrimmer{PacketConn: conn, allowed: allowed}, peer, m)
+		}
+	}
+}
+
+// innerMessage returns the client message encapsulated in the relay messages,
+// if any.
+func innerMessage(m DHCPv6) (DHCPv6, error) {
+	for m.IsRelay() {
+		var err error
+		if m, err = DecapsulateRelay(m); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// replyTrimmer removes the options that are not allowed from the Advertise and
+// Reply messages written to the connection.
+type replyTrimmer struct {
+	net.PacketConn
+	allowed map[OptionCode]struct{}
+}
+
+func (r replyTrimmer) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if len(p) == 0 {
+		return r.PacketConn.WriteTo(p, addr)
+	}
+	m, err := FromBytes(p)
+	if err != nil {
+		return r.PacketConn.WriteTo(p, addr)
+	}
+	reply, err := innerMessage(m)
+	if err != nil || (reply.Type() != MessageTypeAdvertise && reply.Type() != MessageTypeReply) {
+		return r.PacketConn.WriteTo(p, addr)
+	}
+	options := make([]Option, 0, len(reply.Options()))
+	for _, opt := range reply.Options() {
+		if _, ok := r.allowed[opt.Code()]; ok {
+			options = append(options, opt)
+		}
+	}
+	reply.SetOptions(options)
+	if _, err := r.PacketConn.WriteTo(m.ToBytes(), addr); err != nil {
+		return 0, err
+	}
+	// the caller wrote p, not the trimmed message
+	return len(p), nil
+}
@@ -0,0 +1,106 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writesConn is a net.PacketConn recording the packets written to it.
+type writesConn struct {
+	net.PacketConn
+	writes [][]byte
+}
+
+func (c *writesConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	c.writes = append(c.writes, append([]byte{}, p...))
+	return len(p), nil
+}
+
+func TestChain(t *testing.T) {
+	var order []int
+	mw := func(i int) Middleware {
+		return func(next Handler) Handler {
+			return func(conn net.PacketConn, peer net.Addr, m DHCPv6) {
+				order = append(order, i)
+				next(conn, peer, m)
+			}
+		}
+	}
+	h := Chain(func(conn net.PacketConn, peer net.Addr, m DHCPv6) {
+		order = append(order, 0)
+	}, mw(1), mw(2))
+	h(nil, nil, nil)
+	require.Equal(t, []int{1, 2, 0}, order)
+}
+
+// replyWithEverything answers with a Reply holding the DNS, domain search
+// list and boot file URL options, encapsulated in a Relay-Reply message if
+// the request was relayed.
+func replyWithEverything(conn net.PacketConn, peer net.Addr, m DHCPv6) {
+	inner, err := innerMessage(m)
+	if err != nil {
+		return
+	}
+	reply, err := NewReplyFromMessage(inner,
+		WithServerID(Duid{Type: DUID_LL, LinkLayerAddr: net.HardwareAddr{1, 2, 3, 4, 5, 6}}),
+	)
+	if err != nil {
+		return
+	}
+	reply.AddOption(&OptDNSRecursiveNameServer{NameServers: []net.IP{net.ParseIP("2001:db8::53")}})
+	reply.AddOption(&OptDomainSearchList{DomainSearchList: []string{"example.com"}})
+	reply.AddOption(&OptBootFileURL{BootFileURL: []byte("http://[2001:db8::1]/boot")})
+	if m.IsRelay() {
+		relay := m.(*DHCPv6Relay)
+		reply, err = EncapsulateRelay(reply, MessageTypeRelayReply, relay.LinkAddr(), relay.PeerAddr())
+		if err != nil {
+			return
+		}
+	}
+	conn.WriteTo(reply.ToBytes(), peer)
+}
+
+func TestTrimUnrequestedOptions(t *testing.T) {
+	request, err := NewMessage(
+		WithClientID(Duid{Type: DUID_LL, LinkLayerAddr: net.HardwareAddr{6, 5, 4, 3, 2, 1}}),
+		WithRequestedOptions(OptionDNSRecursiveNameServer),
+	)
+	require.NoError(t, err)
+	request.(*DHCPv6Message).SetMessage(MessageTypeRequest)
+	relayed, err := EncapsulateRelay(request, MessageTypeRelayForward, net.ParseIP("2001:db8::1"), net.ParseIP("fe80::1"))
+	require.NoError(t, err)
+
+	for _, m := range []DHCPv6{request, relayed} {
+		conn := &writesConn{}
+		h := Chain(replyWithEverything, TrimUnrequestedOptions(OptionBootfileURL))
+		h(conn, &net.UDPAddr{}, m)
+		require.Len(t, conn.writes, 1)
+		written, err := FromBytes(conn.writes[0])
+		require.NoError(t, err)
+		require.Equal(t, m.IsRelay(), written.IsRelay())
+		reply, err := innerMessage(written)
+		require.NoError(t, err)
+		require.Equal(t, MessageTypeReply, reply.Type())
+		require.NotNil(t, reply.GetOneOption(OptionClientID))
+		require.NotNil(t, reply.GetOneOption(OptionServerID))
+		require.NotNil(t, reply.GetOneOption(OptionDNSRecursiveNameServer))
+		require.NotNil(t, reply.GetOneOption(OptionBootfileURL))
+		require.Nil(t, reply.GetOneOption(OptionDomainSearchList))
+	}
+}
+
+func TestTrimUnrequestedOptionsOtherMessages(t *testing.T) {
+	conn := &writesConn{}
+	h := TrimUnrequestedOptions()(func(conn net.PacketConn, peer net.Addr, m DHCPv6) {
+		conn.WriteTo([]byte{1, 2, 3}, peer)
+		conn.WriteTo(m.ToBytes(), peer)
+	})
+	solicit, err := NewMessage(WithRequestedOptions(OptionDNSRecursiveNameServer))
+	require.NoError(t, err)
+	solicit.AddOption(&OptDomainSearchList{DomainSearchList: []string{"example.com"}})
+	h(conn, &net.UDPAddr{}, solicit)
+	// what is not an Advertise nor a Reply is written as is
+	require.Equal(t, [][]byte{{1, 2, 3}, solicit.ToBytes()}, conn.writes)
+}
@@ -1,8 +1,6 @@
 package dhcpv6
 
 import (
-	"log"
-
 	"github.com/insomniacslk/dhcp/iana"
 )
 
@@ -28,7 +26,6 @@ func WithServerID(duid Duid) Modifier {
 func WithNetboot(d DHCPv6) DHCPv6 {
 	msg, ok := d.(*DHCPv6Message)
 	if !ok {
-		log.Printf("WithNetboot: not a DHCPv6Message")
 		return d
 	}
 	// add OptionBootfileURL and OptionBootfileParam
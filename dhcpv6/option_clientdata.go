@@ -0,0 +1,100 @@
+package dhcpv6
+
+// This module defines the OptClientData and OptCLTTime structures.
+// https://www.ietf.org/rfc/rfc5007.txt
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// OptClientData represents an OptionClientData, holding the data of a client
+// in a leasequery reply.
+type OptClientData struct {
+	Options []Option
+}
+
+// Code returns the option's code
+func (op *OptClientData) Code() OptionCode {
+	return OptionClientData
+}
+
+// ToBytes serializes the option and returns it as a sequence of bytes
+func (op *OptClientData) ToBytes() []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint16(buf[0:2], uint16(OptionClientData))
+	binary.BigEndian.PutUint16(buf[2:4], uint16(op.Length()))
+	for _, opt := range op.Options {
+		buf = append(buf, opt.ToBytes()...)
+	}
+	return buf
+}
+
+// Length returns the option length
+func (op *OptClientData) Length() int {
+	var opLen int
+	for _, opt := range op.Options {
+		opLen += 4 + opt.Length()
+	}
+	return opLen
+}
+
+func (op *OptClientData) String() string {
+	return fmt.Sprintf("OptClientData{options=%v}", op.Options)
+}
+
+// GetOneOption returns the first client option with the given code, or nil
+// if there is none.
+func (op *OptClientData) GetOneOption(code OptionCode) Option {
+	return getOption(op.Options, code)
+}
+
+// ParseOptClientData builds an OptClientData structure from a sequence of
+// bytes. The input data does not include option code and length bytes.
+func ParseOptClientData(data []byte) (*OptClientData, error) {
+	var err error
+	opt := OptClientData{}
+	opt.Options, err = OptionsFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return &opt, nil
+}
+
+// OptCLTTime represents an OptionCLTTime, the number of seconds since the
+// server last communicated with a client.
+type OptCLTTime struct {
+	Time uint32
+}
+
+// Code returns the option's code
+func (op *OptCLTTime) Code() OptionCode {
+	return OptionCLTTime
+}
+
+// ToBytes serializes the option and returns it as a sequence of bytes
+func (op *OptCLTTime) ToBytes() []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint16(buf[0:2], uint16(OptionCLTTime))
+	binary.BigEndian.PutUint16(buf[2:4], uint16(op.Length()))
+	binary.BigEndian.PutUint32(buf[4:8], op.Time)
+	return buf
+}
+
+// Length returns the option length
+func (op *OptCLTTime) Length() int {
+	return 4
+}
+
+func (op *OptCLTTime) String() string {
+	return fmt.Sprintf("OptCLTTime{time=%v}", op.Time)
+}
+
+// ParseOptCLTTime builds an OptCLTTime structure from a sequence of bytes.
+// The input data does not include option code and length bytes.
+func ParseOptCLTTime(data []byte) (*OptCLTTime, error) {
+	if len(data) != 4 {
+		return nil, fmt.Errorf("Invalid CLT Time data length. Expected 4 bytes, got %v", len(data))
+	}
+	return &OptCLTTime{Time: binary.BigEndian.Uint32(data)}, nil
+}
@@ -0,0 +1,35 @@
+package dhcpv6
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptClientDataParse(t *testing.T) {
+	data := []byte{
+		0, 46, 0, 4, 0, 0, 0, 60, // CLT time
+		0, 8, 0, 2, 0xaa, 0xbb, // elapsed time
+	}
+	opt, err := ParseOptClientData(data)
+	require.NoError(t, err)
+	require.Equal(t, 14, opt.Length())
+	require.Equal(t, 2, len(opt.Options))
+	require.Equal(t, &OptCLTTime{Time: 60}, opt.GetOneOption(OptionCLTTime))
+	require.Equal(t, append([]byte{0, 45, 0, 14}, data...), opt.ToBytes())
+}
+
+func TestOptClientDataParseInvalidBrokenOptions(t *testing.T) {
+	_, err := ParseOptClientData([]byte{0, 46, 0, 4, 0, 0})
+	require.Error(t, err)
+}
+
+func TestOptCLTTime(t *testing.T) {
+	opt, err := ParseOptCLTTime([]byte{0, 0, 1, 0})
+	require.NoError(t, err)
+	require.Equal(t, uint32(256), opt.Time)
+	require.Equal(t, []byte{0, 46, 0, 4, 0, 0, 1, 0}, opt.ToBytes())
+
+	_, err = ParseOptCLTTime([]byte{0, 0, 1})
+	require.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package dhcpv6
+
+// This module defines the OptClientLinkLayerAddr structure.
+// https://www.ietf.org/rfc/rfc6939.txt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// OptClientLinkLayerAddr represents a Client Link-Layer Address option, by
+// which a first-hop relay agent tells the server the link-layer address of
+// the client.
+type OptClientLinkLayerAddr struct {
+	LinkLayerType iana.HwTypeType
+	LinkLayerAddr net.HardwareAddr
+}
+
+// Code returns the option's code
+func (op *OptClientLinkLayerAddr) Code() OptionCode {
+	return OptionClientLinkLayerAddr
+}
+
+// ToBytes serializes the option and returns it as a sequence of bytes
+func (op *OptClientLinkLayerAddr) ToBytes() []byte {
+	buf := make([]byte, 6)
+	binary.BigEndian.PutUint16(buf[0:2], uint16(OptionClientLinkLayerAddr))
+	binary.BigEndian.PutUint16(buf[2:4], uint16(op.Length()))
+	binary.BigEndian.PutUint16(buf[4:6], uint16(op.LinkLayerType))
+	buf = append(buf, op.LinkLayerAddr...)
+	return buf
+}
+
+// Length returns the option length
+func (op *OptClientLinkLayerAddr) Length() int {
+	return 2 + len(op.LinkLayerAddr)
+}
+
+func (op *OptClientLinkLayerAddr) String() string {
+	return fmt.Sprintf("OptClientLinkLayerAddr{linklayertype=%v, linklayeraddr=%v}",
+		op.LinkLayerType, op.LinkLayerAddr)
+}
+
+// ParseOptClientLinkLayerAddr builds an OptClientLinkLayerAddr structure from
+// a sequence of bytes. The input data does not include option code and length
+// bytes.
+func ParseOptClientLinkLayerAddr(data []byte) (*OptClientLinkLayerAddr, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("Invalid client link-layer address data length. Expected at least 2 bytes, got %v", len(data))
+	}
+	return &OptClientLinkLayerAddr{
+		LinkLayerType: iana.HwTypeType(binary.BigEndian.Uint16(data[0:2])),
+		LinkLayerAddr: append(net.HardwareAddr(nil), data[2:]...),
+	}, nil
+}
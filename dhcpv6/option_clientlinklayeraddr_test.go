@@ -0,0 +1,33 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptClientLinkLayerAddrParse(t *testing.T) {
+	opt, err := ParseOptClientLinkLayerAddr([]byte{0, 1, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff})
+	require.NoError(t, err)
+	require.Equal(t, iana.HwTypeEthernet, opt.LinkLayerType)
+	require.Equal(t, net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, opt.LinkLayerAddr)
+	require.Equal(t, 8, opt.Length())
+}
+
+func TestOptClientLinkLayerAddrParseInvalidTooShort(t *testing.T) {
+	_, err := ParseOptClientLinkLayerAddr([]byte{0})
+	require.Error(t, err)
+}
+
+func TestOptClientLinkLayerAddrToBytes(t *testing.T) {
+	opt := OptClientLinkLayerAddr{
+		LinkLayerType: iana.HwTypeEthernet,
+		LinkLayerAddr: net.HardwareAddr{1, 2, 3, 4, 5, 6},
+	}
+	require.Equal(t, []byte{0, 79, 0, 8, 0, 1, 1, 2, 3, 4, 5, 6}, opt.ToBytes())
+	parsed, err := ParseOption(opt.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, &opt, parsed)
+}
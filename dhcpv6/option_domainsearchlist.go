@@ -10,7 +10,9 @@ import (
 	"github.com/insomniacslk/dhcp/rfc1035label"
 )
 
-// OptDomainSearchList list implements a OptionDomainSearchList option
+// OptDomainSearchList list implements a OptionDomainSearchList option. The
+// domains are serialized without compression, as RFC 8415 requires, but the
+// parser accepts compressed ones.
 type OptDomainSearchList struct {
 	DomainSearchList []string
 }
@@ -28,11 +30,7 @@ func (op *OptDomainSearchList) ToBytes() []byte {
 }
 
 func (op *OptDomainSearchList) Length() int {
-	var length int
-	for _, label := range op.DomainSearchList {
-		length += len(label) + 2 // add the first and the last length bytes
-	}
-	return length
+	return len(rfc1035label.LabelsToBytes(op.DomainSearchList))
 }
 
 func (op *OptDomainSearchList) String() string {
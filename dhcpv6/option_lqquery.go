@@ -0,0 +1,102 @@
+package dhcpv6
+
+// This module defines the OptLQQuery structure.
+// https://www.ietf.org/rfc/rfc5007.txt
+// https://www.ietf.org/rfc/rfc5460.txt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// LQQueryType is the type of a leasequery, telling how the bindings are
+// selected.
+type LQQueryType uint8
+
+// Leasequery types, as defined by RFC 5007 and RFC 5460
+const (
+	QueryByAddress     LQQueryType = 1
+	QueryByClientID    LQQueryType = 2
+	QueryByRelayID     LQQueryType = 3
+	QueryByLinkAddress LQQueryType = 4
+	QueryByRemoteID    LQQueryType = 5
+)
+
+// LQQueryTypeToString maps leasequery types to their names.
+var LQQueryTypeToString = map[LQQueryType]string{
+	QueryByAddress:     "QUERY_BY_ADDRESS",
+	QueryByClientID:    "QUERY_BY_CLIENTID",
+	QueryByRelayID:     "QUERY_BY_RELAY_ID",
+	QueryByLinkAddress: "QUERY_BY_LINK_ADDRESS",
+	QueryByRemoteID:    "QUERY_BY_REMOTE_ID",
+}
+
+func (q LQQueryType) String() string {
+	if s, ok := LQQueryTypeToString[q]; ok {
+		return s
+	}
+	return "Unknown"
+}
+
+// OptLQQuery represents an OptionLQQuery, describing a leasequery.
+type OptLQQuery struct {
+	QueryType   LQQueryType
+	LinkAddress net.IP
+	Options     []Option
+}
+
+// Code returns the option's code
+func (op *OptLQQuery) Code() OptionCode {
+	return OptionLQQuery
+}
+
+// ToBytes serializes the option and returns it as a sequence of bytes
+func (op *OptLQQuery) ToBytes() []byte {
+	buf := make([]byte, 21)
+	binary.BigEndian.PutUint16(buf[0:2], uint16(OptionLQQuery))
+	binary.BigEndian.PutUint16(buf[2:4], uint16(op.Length()))
+	buf[4] = uint8(op.QueryType)
+	copy(buf[5:21], op.LinkAddress.To16())
+	for _, opt := range op.Options {
+		buf = append(buf, opt.ToBytes()...)
+	}
+	return buf
+}
+
+// Length returns the option length
+func (op *OptLQQuery) Length() int {
+	opLen := 17
+	for _, opt := range op.Options {
+		opLen += 4 + opt.Length()
+	}
+	return opLen
+}
+
+func (op *OptLQQuery) String() string {
+	return fmt.Sprintf("OptLQQuery{querytype=%v, linkaddress=%v, options=%v}",
+		op.QueryType, op.LinkAddress, op.Options)
+}
+
+// GetOneOption returns the first query option with the given code, or nil if
+// there is none.
+func (op *OptLQQuery) GetOneOption(code OptionCode) Option {
+	return getOption(op.Options, code)
+}
+
+// ParseOptLQQuery builds an OptLQQuery structure from a sequence of bytes.
+// The input data does not include option code and length bytes.
+func ParseOptLQQuery(data []byte) (*OptLQQuery, error) {
+	var err error
+	if len(data) < 17 {
+		return nil, fmt.Errorf("Invalid LQ Query data length. Expected at least 17 bytes, got %v", len(data))
+	}
+	opt := OptLQQuery{}
+	opt.QueryType = LQQueryType(data[0])
+	opt.LinkAddress = net.IP(append([]byte(nil), data[1:17]...))
+	opt.Options, err = OptionsFromBytes(data[17:])
+	if err != nil {
+		return nil, err
+	}
+	return &opt, nil
+}
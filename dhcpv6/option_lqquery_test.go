@@ -0,0 +1,49 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptLQQueryParse(t *testing.T) {
+	linkaddr := []byte{0x20, 1, 0xd, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	data := append([]byte{byte(QueryByLinkAddress)}, linkaddr...)
+	data = append(data, []byte{0, 8, 0, 2, 0xaa, 0xbb}...) // options
+	opt, err := ParseOptLQQuery(data)
+	require.NoError(t, err)
+	require.Equal(t, 23, opt.Length())
+	require.Equal(t, QueryByLinkAddress, opt.QueryType)
+	require.Equal(t, net.IP(linkaddr), opt.LinkAddress)
+	require.Equal(t, 1, len(opt.Options))
+	require.NotNil(t, opt.GetOneOption(OptionElapsedTime))
+}
+
+func TestOptLQQueryParseInvalidTooShort(t *testing.T) {
+	_, err := ParseOptLQQuery([]byte{byte(QueryByAddress), 0x20, 1, 0xd, 0xb8})
+	require.Error(t, err)
+}
+
+func TestOptLQQueryToBytes(t *testing.T) {
+	opt := OptLQQuery{
+		QueryType:   QueryByAddress,
+		LinkAddress: net.IPv6unspecified,
+		Options: []Option{
+			&OptIAAddress{IPv6Addr: net.ParseIP("2001:db8::1")},
+		},
+	}
+	data := opt.ToBytes()
+	require.Equal(t, []byte{0, 44, 0, 45, 1}, data[:5])
+	parsed, err := ParseOption(data)
+	require.NoError(t, err)
+	lq := parsed.(*OptLQQuery)
+	require.Equal(t, QueryByAddress, lq.QueryType)
+	require.True(t, lq.LinkAddress.Equal(net.IPv6unspecified))
+	require.True(t, lq.GetOneOption(OptionIAAddr).(*OptIAAddress).IPv6Addr.Equal(net.ParseIP("2001:db8::1")))
+}
+
+func TestLQQueryTypeString(t *testing.T) {
+	require.Equal(t, "QUERY_BY_RELAY_ID", QueryByRelayID.String())
+	require.Equal(t, "Unknown", LQQueryType(42).String())
+}
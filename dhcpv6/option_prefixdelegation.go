@@ -6,7 +6,6 @@ package dhcpv6
 import (
 	"encoding/binary"
 	"fmt"
-	"log"
 )
 
 type OptIAForPrefixDelegation struct {
@@ -66,8 +65,9 @@ func (op *OptIAForPrefixDelegation) SetT2(t2 uint32) {
 }
 
 // Options serializes the options and returns them as a sequence of bytes
+//
+// Deprecated: it will be changed to a public field.
 func (op *OptIAForPrefixDelegation) Options() []byte {
-	log.Printf("Warning: OptIAForPrefixDelegation.Options() is deprecated and will be changed to a public field")
 	buf := op.ToBytes()
 	return buf[16:]
 }
@@ -0,0 +1,47 @@
+package dhcpv6
+
+// This module defines the OptSubscriberId structure.
+// https://www.ietf.org/rfc/rfc4580.txt
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// OptSubscriberId represents a Relay-Agent Subscriber-ID option, by which a
+// relay agent identifies the subscriber of the client, e.g. by circuit.
+type OptSubscriberId struct {
+	SubscriberID []byte
+}
+
+// Code returns the option's code
+func (op *OptSubscriberId) Code() OptionCode {
+	return OptionRelayAgentSubscriberID
+}
+
+// ToBytes serializes the option and returns it as a sequence of bytes
+func (op *OptSubscriberId) ToBytes() []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint16(buf[0:2], uint16(OptionRelayAgentSubscriberID))
+	binary.BigEndian.PutUint16(buf[2:4], uint16(op.Length()))
+	buf = append(buf, op.SubscriberID...)
+	return buf
+}
+
+// Length returns the option length
+func (op *OptSubscriberId) Length() int {
+	return len(op.SubscriberID)
+}
+
+func (op *OptSubscriberId) String() string {
+	return fmt.Sprintf("OptSubscriberId{subscriberid=%v}", op.SubscriberID)
+}
+
+// ParseOptSubscriberId builds an OptSubscriberId structure from a sequence of
+// bytes. The input data does not include option code and length bytes.
+func ParseOptSubscriberId(data []byte) (*OptSubscriberId, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("Invalid subscriber id data: empty")
+	}
+	return &OptSubscriberId{SubscriberID: append([]byte(nil), data...)}, nil
+}
@@ -0,0 +1,27 @@
+package dhcpv6
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptSubscriberIdParse(t *testing.T) {
+	opt, err := ParseOptSubscriberId([]byte("subscriber-1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("subscriber-1"), opt.SubscriberID)
+	require.Equal(t, 12, opt.Length())
+}
+
+func TestOptSubscriberIdParseInvalidEmpty(t *testing.T) {
+	_, err := ParseOptSubscriberId([]byte{})
+	require.Error(t, err)
+}
+
+func TestOptSubscriberIdToBytes(t *testing.T) {
+	opt := OptSubscriberId{SubscriberID: []byte("sub")}
+	require.Equal(t, []byte{0, 38, 0, 3, 's', 'u', 'b'}, opt.ToBytes())
+	parsed, err := ParseOption(opt.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, &opt, parsed)
+}
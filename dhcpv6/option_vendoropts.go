@@ -0,0 +1,151 @@
+package dhcpv6
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// This module defines the OptVendorOpts structure.
+// https://tools.ietf.org/html/rfc8415#section-21.17
+
+// VendorSubOption is a sub-option of the Vendor-specific Information option.
+// Its format is the one of the DHCPv6 options, and its meaning is defined by
+// the vendor.
+type VendorSubOption struct {
+	Code uint16
+	Data []byte
+}
+
+// OptVendorOpts represents a DHCPv6 Vendor-specific Information option
+type OptVendorOpts struct {
+	EnterpriseNumber uint32
+	Options          []VendorSubOption
+}
+
+// Code returns the option code
+func (op *OptVendorOpts) Code() OptionCode {
+	return OptionVendorOpts
+}
+
+// ToBytes serializes the option and returns it as a sequence of bytes
+func (op *OptVendorOpts) ToBytes() []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint16(buf[0:2], uint16(OptionVendorOpts))
+	binary.BigEndian.PutUint16(buf[2:4], uint16(op.Length()))
+	binary.BigEndian.PutUint32(buf[4:8], op.EnterpriseNumber)
+	u16 := make([]byte, 2)
+	for _, opt := range op.Options {
+		binary.BigEndian.PutUint16(u16, opt.Code)
+		buf = append(buf, u16...)
+		binary.BigEndian.PutUint16(u16, uint16(len(opt.Data)))
+		buf = append(buf, u16...)
+		buf = append(buf, opt.Data...)
+	}
+	return buf
+}
+
+// Length returns the option length
+func (op *OptVendorOpts) Length() int {
+	ret := 4
+	for _, opt := range op.Options {
+		ret += 4 + len(opt.Data)
+	}
+	return ret
+}
+
+// String returns a string representation of the VendorOpts data
+func (op *OptVendorOpts) String() string {
+	subs := make([]string, 0, len(op.Options))
+	for _, opt := range op.Options {
+		subs = append(subs, fmt.Sprintf("%d: %v", opt.Code, opt.Data))
+	}
+	return fmt.Sprintf("OptVendorOpts{enterprisenum=%v, options=[%s]}", iana.EnterpriseID(op.EnterpriseNumber), strings.Join(subs, ", "))
+}
+
+// GetSubOption returns the data of the first sub-option with the given code,
+// or nil if there is none.
+func (op *OptVendorOpts) GetSubOption(code uint16) []byte {
+	for _, opt := range op.Options {
+		if opt.Code == code {
+			return opt.Data
+		}
+	}
+	return nil
+}
+
+// Decode returns the sub-options, decoded with the parser registered for the
+// vendor, or as OptionGeneric if there is none.
+func (op *OptVendorOpts) Decode() ([]Option, error) {
+	parser := LookupVendorOptsParser(iana.EnterpriseID(op.EnterpriseNumber))
+	opts := make([]Option, 0, len(op.Options))
+	for _, sub := range op.Options {
+		if parser == nil {
+			opts = append(opts, &OptionGeneric{OptionCode: OptionCode(sub.Code), OptionData: sub.Data})
+			continue
+		}
+		data := make([]byte, 4, 4+len(sub.Data))
+		binary.BigEndian.PutUint16(data[0:2], sub.Code)
+		binary.BigEndian.PutUint16(data[2:4], uint16(len(sub.Data)))
+		opt, err := parser(append(data, sub.Data...))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
+
+// ParseOptVendorOpts builds an OptVendorOpts structure from a sequence of
+// bytes. The input data does not include option code and length bytes.
+func ParseOptVendorOpts(data []byte) (*OptVendorOpts, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("Invalid vendor opts data length. Expected at least 4 bytes, got %v", len(data))
+	}
+	opt := OptVendorOpts{EnterpriseNumber: binary.BigEndian.Uint32(data[:4])}
+	data = data[4:]
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("ParseOptVendorOpts: short data: missing sub-option header")
+		}
+		subLen := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < subLen+4 {
+			return nil, fmt.Errorf("ParseOptVendorOpts: short data: less than %d bytes", subLen+4)
+		}
+		opt.Options = append(opt.Options, VendorSubOption{
+			Code: binary.BigEndian.Uint16(data[0:2]),
+			Data: data[4 : 4+subLen],
+		})
+		data = data[4+subLen:]
+	}
+	return &opt, nil
+}
+
+// VendorOptionParser parses a vendor sub-option, given as code, length and
+// data like ParseOption expects, into the vendor's specific type.
+type VendorOptionParser func(data []byte) (Option, error)
+
+var (
+	vendorOptsParsersMutex sync.RWMutex
+	vendorOptsParsers      = make(map[iana.EnterpriseID]VendorOptionParser)
+)
+
+// RegisterVendorOptsParser registers the parser of the sub-options that the
+// given vendor sends in the Vendor-specific Information option. A later
+// registration for the same vendor replaces the earlier one.
+func RegisterVendorOptsParser(entID iana.EnterpriseID, parser VendorOptionParser) {
+	vendorOptsParsersMutex.Lock()
+	defer vendorOptsParsersMutex.Unlock()
+	vendorOptsParsers[entID] = parser
+}
+
+// LookupVendorOptsParser returns the parser registered for the given vendor,
+// or nil if there is none.
+func LookupVendorOptsParser(entID iana.EnterpriseID) VendorOptionParser {
+	vendorOptsParsersMutex.RLock()
+	defer vendorOptsParsersMutex.RUnlock()
+	return vendorOptsParsers[entID]
+}
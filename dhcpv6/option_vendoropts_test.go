@@ -0,0 +1,86 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptVendorOpts(t *testing.T) {
+	data := []byte{
+		0, 0, 0x11, 0x8b, // EnterpriseNumber (CableLabs)
+		0, 1, 0, 4, 0, 32, 0, 33, // Option Request
+		0, 2, 0, 4, 'E', 'C', 'M', ':', // Device Type
+	}
+	opt, err := ParseOptVendorOpts(data)
+	require.NoError(t, err)
+	require.Equal(t, OptionVendorOpts, opt.Code())
+	require.Equal(t, uint32(4491), opt.EnterpriseNumber)
+	require.Equal(t, []VendorSubOption{
+		{Code: 1, Data: []byte{0, 32, 0, 33}},
+		{Code: 2, Data: []byte("ECM:")},
+	}, opt.Options)
+	require.Equal(t, []byte("ECM:"), opt.GetSubOption(2))
+	require.Nil(t, opt.GetSubOption(3))
+	require.Equal(t, len(data), opt.Length())
+	require.Equal(t, append([]byte{0, 17, 0, byte(len(data))}, data...), opt.ToBytes())
+	require.Equal(t, "OptVendorOpts{enterprisenum=CableLabs, options=[1: [0 32 0 33], 2: [69 67 77 58]]}", opt.String())
+
+	// through ParseOption
+	parsed, err := ParseOption(opt.ToBytes())
+	require.NoError(t, err)
+	require.Equal(t, opt, parsed)
+}
+
+func TestParseOptVendorOptsMalformed(t *testing.T) {
+	_, err := ParseOptVendorOpts([]byte{0, 0, 0x11})
+	require.Error(t, err, "truncated EnterpriseNumber")
+	_, err = ParseOptVendorOpts([]byte{0, 0, 0x11, 0x8b, 0, 1, 0})
+	require.Error(t, err, "truncated sub-option header")
+	_, err = ParseOptVendorOpts([]byte{0, 0, 0x11, 0x8b, 0, 1, 0, 2, 0})
+	require.Error(t, err, "truncated sub-option data")
+}
+
+func TestOptVendorOptsDecode(t *testing.T) {
+	tftp := net.ParseIP("2001:db8::1")
+	opt := &OptVendorOpts{
+		EnterpriseNumber: uint32(iana.EnterpriseIDCableLabs),
+		Options: []VendorSubOption{
+			{Code: 1, Data: []byte{0, 32, 0, 33}},
+			{Code: 32, Data: tftp},
+			{Code: 33, Data: []byte("modem.cfg")},
+			{Code: 99, Data: []byte{1}},
+		},
+	}
+	opts, err := opt.Decode()
+	require.NoError(t, err)
+	require.Equal(t, []Option{
+		&OptVendorSubOption{SubCode: 1, Name: "Option Request", Value: []uint16{32, 33}, Data: []byte{0, 32, 0, 33}},
+		&OptVendorSubOption{SubCode: 32, Name: "TFTP Servers", Value: []net.IP{tftp}, Data: []byte(tftp)},
+		&OptVendorSubOption{SubCode: 33, Name: "Configuration File Name", Value: "modem.cfg", Data: []byte("modem.cfg")},
+		&OptVendorSubOption{SubCode: 99, Name: "Unknown (99)", Value: []byte{1}, Data: []byte{1}},
+	}, opts)
+	require.Equal(t, `Configuration File Name -> "modem.cfg"`, opts[2].String())
+	require.Equal(t, []byte{0, 33, 0, 9, 'm', 'o', 'd', 'e', 'm', '.', 'c', 'f', 'g'}, opts[2].ToBytes())
+	require.Equal(t, 9, opts[2].Length())
+
+	// without a registered parser, the sub-options are generic
+	opt.EnterpriseNumber = 0xcafe
+	opts, err = opt.Decode()
+	require.NoError(t, err)
+	require.Equal(t, &OptionGeneric{OptionCode: 1, OptionData: []byte{0, 32, 0, 33}}, opts[0])
+}
+
+func TestVendorSchemaMismatch(t *testing.T) {
+	parser := VendorSchema{1: {"Codes", VendorFormatCodes}, 2: {"Number", VendorFormatUint32}}.Parser()
+	opt, err := parser([]byte{0, 1, 0, 1, 7})
+	require.NoError(t, err)
+	require.Equal(t, []byte{7}, opt.(*OptVendorSubOption).Value)
+	opt, err = parser([]byte{0, 2, 0, 4, 0, 0, 1, 0})
+	require.NoError(t, err)
+	require.Equal(t, uint32(256), opt.(*OptVendorSubOption).Value)
+	_, err = parser([]byte{0, 2, 0, 4, 0})
+	require.Error(t, err)
+}
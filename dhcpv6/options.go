@@ -58,9 +58,9 @@ func ParseOption(dataStart []byte) (Option, error) {
 	code := OptionCode(binary.BigEndian.Uint16(dataStart[:2]))
 	length := int(binary.BigEndian.Uint16(dataStart[2:4]))
 	if len(dataStart) < length+4 {
-		return nil, fmt.Errorf("Invalid option length for option %v. Declared %v, actual %v",
-			code, length, len(dataStart)-4,
-		)
+		return nil, &OptionParseError{Code: code, Err: fmt.Errorf("invalid option length: declared %v, actual %v",
+			length, len(dataStart)-4,
+		)}
 	}
 	var (
 		err error
@@ -88,6 +88,8 @@ func ParseOption(dataStart []byte) (Option, error) {
 		opt, err = ParseOptUserClass(optData)
 	case OptionVendorClass:
 		opt, err = ParseOptVendorClass(optData)
+	case OptionVendorOpts:
+		opt, err = ParseOptVendorOpts(optData)
 	case OptionInterfaceID:
 		opt, err = ParseOptInterfaceId(optData)
 	case OptionDNSRecursiveNameServer:
@@ -100,21 +102,31 @@ func ParseOption(dataStart []byte) (Option, error) {
 		opt, err = ParseOptIAPrefix(optData)
 	case OptionRemoteID:
 		opt, err = ParseOptRemoteId(optData)
+	case OptionRelayAgentSubscriberID:
+		opt, err = ParseOptSubscriberId(optData)
+	case OptionLQQuery:
+		opt, err = ParseOptLQQuery(optData)
+	case OptionClientData:
+		opt, err = ParseOptClientData(optData)
+	case OptionCLTTime:
+		opt, err = ParseOptCLTTime(optData)
 	case OptionBootfileURL:
 		opt, err = ParseOptBootFileURL(optData)
 	case OptionClientArchType:
 		opt, err = ParseOptClientArchType(optData)
 	case OptionNII:
 		opt, err = ParseOptNetworkInterfaceId(optData)
+	case OptionClientLinkLayerAddr:
+		opt, err = ParseOptClientLinkLayerAddr(optData)
 	default:
 		opt = &OptionGeneric{OptionCode: code, OptionData: optData}
 	}
 	if err != nil {
-		return nil, err
+		return nil, &OptionParseError{Code: code, Err: err}
 	}
 	if length != opt.Length() {
-		return nil, fmt.Errorf("Error: declared length is different from actual length for option %d: %d != %d",
-			code, opt.Length(), length)
+		return nil, &OptionParseError{Code: code, Err: fmt.Errorf("declared length is different from actual length: %d != %d",
+			opt.Length(), length)}
 	}
 	return opt, nil
 }
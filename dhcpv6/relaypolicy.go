@@ -0,0 +1,118 @@
+package dhcpv6
+
+import (
+	"net"
+	"sync"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// RelayPolicy tells which options a relay agent inserts into the RELAY-FORW
+// messages it sends on behalf of the clients of an interface, and which ones
+// it strips from the RELAY-FORW messages of downstream relay agents, e.g.
+// lightweight relay agents in access nodes, before relaying them.
+type RelayPolicy struct {
+	// InterfaceID, if not nil, is sent in an Interface-ID option, with
+	// which the server replies so that the reply is sent back on the
+	// right interface.
+	InterfaceID []byte
+	// RemoteID, if not nil, is sent as is.
+	RemoteID *OptRemoteId
+	// SubscriberID, if not nil, is sent in a Subscriber-ID option.
+	SubscriberID []byte
+	// ClientLinkLayerAddr tells whether to send the link-layer address of
+	// the client in a Client Link-Layer Address option. Only first-hop
+	// relay agents can send it, so it is not sent when relaying the
+	// messages of other relay agents.
+	ClientLinkLayerAddr bool
+	// Strip lists the options removed from the messages of downstream
+	// relay agents.
+	Strip []OptionCode
+}
+
+// Apply inserts the options of the policy into relay, a RELAY-FORW message,
+// and strips the unwanted ones from the message it relays, if that is itself
+// a RELAY-FORW message. The options of the policy replace the ones of the
+// same type that relay already holds. hwaddr is the link-layer source address
+// of the relayed message, used for the Client Link-Layer Address option.
+func (p *RelayPolicy) Apply(relay *DHCPv6Relay, hwaddr net.HardwareAddr) {
+	inner, err := DecapsulateRelay(relay)
+	if err != nil {
+		return
+	}
+	if inner.IsRelay() {
+		options := inner.Options()
+		for _, code := range p.Strip {
+			options = delOption(options, code)
+		}
+		inner.SetOptions(options)
+	}
+	if p.InterfaceID != nil {
+		opt := OptInterfaceId{}
+		opt.SetInterfaceID(p.InterfaceID)
+		relay.UpdateOption(&opt)
+	}
+	if p.RemoteID != nil {
+		relay.UpdateOption(p.RemoteID)
+	}
+	if p.SubscriberID != nil {
+		relay.UpdateOption(&OptSubscriberId{SubscriberID: p.SubscriberID})
+	}
+	if p.ClientLinkLayerAddr && hwaddr != nil && !inner.IsRelay() {
+		relay.UpdateOption(&OptClientLinkLayerAddr{
+			LinkLayerType: iana.HwTypeEthernet,
+			LinkLayerAddr: hwaddr,
+		})
+	}
+}
+
+// RelayPolicies holds the relay policy of each interface. Policies can be
+// changed at runtime, while other goroutines apply them.
+type RelayPolicies struct {
+	mu       sync.RWMutex
+	policies map[string]*RelayPolicy
+}
+
+// NewRelayPolicies returns a RelayPolicies with no policies.
+func NewRelayPolicies() *RelayPolicies {
+	return &RelayPolicies{policies: make(map[string]*RelayPolicy)}
+}
+
+// Set sets the policy of an interface, replacing the previous one.
+func (r *RelayPolicies) Set(ifname string, policy RelayPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policies[ifname] = &policy
+}
+
+// Delete removes the policy of an interface.
+func (r *RelayPolicies) Delete(ifname string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.policies, ifname)
+}
+
+// Get returns the policy of an interface, if any.
+func (r *RelayPolicies) Get(ifname string) (RelayPolicy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	policy, ok := r.policies[ifname]
+	if !ok {
+		return RelayPolicy{}, false
+	}
+	return *policy, true
+}
+
+// Apply applies the policy of the interface on which the relayed message was
+// received to relay, as RelayPolicy.Apply does. It returns false if the
+// interface has no policy, in which case relay is left as is.
+func (r *RelayPolicies) Apply(ifname string, relay *DHCPv6Relay, hwaddr net.HardwareAddr) bool {
+	r.mu.RLock()
+	policy, ok := r.policies[ifname]
+	r.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	policy.Apply(relay, hwaddr)
+	return true
+}
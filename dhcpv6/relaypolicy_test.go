@@ -0,0 +1,97 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRelayForw(t *testing.T, d DHCPv6) *DHCPv6Relay {
+	relay, err := EncapsulateRelay(d, MessageTypeRelayForward, net.ParseIP("2001:db8::1"), net.ParseIP("fe80::1"))
+	require.NoError(t, err)
+	return relay.(*DHCPv6Relay)
+}
+
+func TestRelayPolicyApply(t *testing.T) {
+	solicit, err := NewMessage()
+	require.NoError(t, err)
+	relay := newTestRelayForw(t, solicit)
+	remoteID := OptRemoteId{}
+	remoteID.SetEnterpriseNumber(4491)
+	remoteID.SetRemoteID([]byte("olt1 1/1/1"))
+	policy := RelayPolicy{
+		InterfaceID:         []byte("eth0.100"),
+		RemoteID:            &remoteID,
+		SubscriberID:        []byte("subscriber-1"),
+		ClientLinkLayerAddr: true,
+	}
+	hwaddr := net.HardwareAddr{1, 2, 3, 4, 5, 6}
+	policy.Apply(relay, hwaddr)
+
+	data := relay.ToBytes()
+	parsed, err := FromBytes(data)
+	require.NoError(t, err)
+	iid := parsed.GetOneOption(OptionInterfaceID).(*OptInterfaceId)
+	require.Equal(t, []byte("eth0.100"), iid.InterfaceID())
+	rid := parsed.GetOneOption(OptionRemoteID).(*OptRemoteId)
+	require.Equal(t, uint32(4491), rid.EnterpriseNumber())
+	sid := parsed.GetOneOption(OptionRelayAgentSubscriberID).(*OptSubscriberId)
+	require.Equal(t, []byte("subscriber-1"), sid.SubscriberID)
+	lla := parsed.GetOneOption(OptionClientLinkLayerAddr).(*OptClientLinkLayerAddr)
+	require.Equal(t, hwaddr, lla.LinkLayerAddr)
+
+	// applying it again does not duplicate the options
+	policy.Apply(relay, hwaddr)
+	require.Len(t, relay.GetOption(OptionInterfaceID), 1)
+	require.Len(t, relay.Options(), 5)
+}
+
+func TestRelayPolicyApplyDownstreamRelay(t *testing.T) {
+	solicit, err := NewMessage()
+	require.NoError(t, err)
+	// a lightweight relay agent inserted its own options
+	ldra := newTestRelayForw(t, solicit)
+	ldraIID := OptInterfaceId{}
+	ldraIID.SetInterfaceID([]byte("port1"))
+	ldra.AddOption(&ldraIID)
+	ldra.AddOption(&OptRemoteId{})
+	relay := newTestRelayForw(t, ldra)
+
+	policy := RelayPolicy{
+		InterfaceID:         []byte("eth0"),
+		ClientLinkLayerAddr: true,
+		Strip:               []OptionCode{OptionRemoteID},
+	}
+	policy.Apply(relay, net.HardwareAddr{1, 2, 3, 4, 5, 6})
+
+	// no client link-layer address, since it would be the one of the
+	// downstream relay agent
+	require.Nil(t, relay.GetOneOption(OptionClientLinkLayerAddr))
+	require.NotNil(t, relay.GetOneOption(OptionInterfaceID))
+	inner, err := DecapsulateRelay(relay)
+	require.NoError(t, err)
+	require.Nil(t, inner.GetOneOption(OptionRemoteID))
+	require.NotNil(t, inner.GetOneOption(OptionInterfaceID))
+	require.NotNil(t, inner.GetOneOption(OptionRelayMsg))
+}
+
+func TestRelayPolicies(t *testing.T) {
+	policies := NewRelayPolicies()
+	solicit, err := NewMessage()
+	require.NoError(t, err)
+	relay := newTestRelayForw(t, solicit)
+	require.False(t, policies.Apply("eth0", relay, nil))
+	require.Len(t, relay.Options(), 1)
+
+	policies.Set("eth0", RelayPolicy{SubscriberID: []byte("sub")})
+	policy, ok := policies.Get("eth0")
+	require.True(t, ok)
+	require.Equal(t, []byte("sub"), policy.SubscriberID)
+	require.True(t, policies.Apply("eth0", relay, nil))
+	require.NotNil(t, relay.GetOneOption(OptionRelayAgentSubscriberID))
+
+	policies.Delete("eth0")
+	_, ok = policies.Get("eth0")
+	require.False(t, ok)
+}
@@ -2,7 +2,6 @@ package dhcpv6
 
 import (
 	"fmt"
-	"log"
 	"net"
 	"sync"
 	"time"
@@ -17,7 +16,8 @@ import (
   The handler is a function that takes as input a packet connection, that can be
   used to reply to the client; a peer address, that identifies the client sending
   the request, and the DHCPv6 packet itself. Just implement your custom logic in
-  the handler.
+  the handler. The handler can be wrapped with middlewares using Chain, e.g.
+  TrimUnrequestedOptions to keep the replies to what the clients requested.
 
   The address to listen on is used to know IP address, port and optionally the
   scope to create and UDP6 socket to listen on for DHCPv6 traffic.
@@ -65,6 +65,10 @@ type Server struct {
 	shouldStop chan bool
 	Handler    Handler
 	localAddr  net.UDPAddr
+
+	// Logger, if not nil, is where the server reports what it does.
+	// DefaultLogger is used otherwise.
+	Logger Logger
 }
 
 // LocalAddr returns the local address of the listening socket, or nil if not
@@ -103,12 +107,13 @@ func (s *Server) ActivateAndServe() error {
 	if pc == nil {
 		return fmt.Errorf("ActivateAndServe: Invalid nil PacketConn")
 	}
-	log.Printf("Server listening on %s", pc.LocalAddr())
-	log.Print("Ready to handle requests")
+	logger := loggerOrDefault(s.Logger)
+	logger.Printf("Server listening on %s", pc.LocalAddr())
+	logger.Printf("Ready to handle requests")
 	for {
 		select {
 		case <-s.shouldStop:
-			break
+			return nil
 		case <-time.After(time.Millisecond):
 		}
 		pc.SetReadDeadline(time.Now().Add(time.Second))
@@ -120,19 +125,18 @@ func (s *Server) ActivateAndServe() error {
 				// silently skip and continue
 			default:
 				//complain and continue
-				log.Printf("Error reading from packet conn: %v", err)
+				logger.Printf("Error reading from packet conn: %v", err)
 			}
 			continue
 		}
-		log.Printf("Handling request from %v", peer)
+		logger.Printf("Handling request from %v", peer)
 		m, err := FromBytes(rbuf[:n])
 		if err != nil {
-			log.Printf("Error parsing DHCPv6 request: %v", err)
+			logger.Printf("Error parsing DHCPv6 request: %v", err)
 			continue
 		}
 		s.Handler(pc, peer, m)
 	}
-	return nil
 }
 
 // Close sends a termination request to the server, and closes the UDP listener
@@ -1,6 +1,7 @@
 package dhcpv6
 
 import (
+	"context"
 	"log"
 	"net"
 	"testing"
@@ -91,3 +92,21 @@ func TestServerActivateAndServe(t *testing.T) {
 	_, _, err = c.Solicit(iface, nil)
 	require.NoError(t, err)
 }
+
+func TestClientExchangeContextCancel(t *testing.T) {
+	// a server that never replies
+	handler := func(conn net.PacketConn, peer net.Addr, m DHCPv6) {}
+	c, s := setUpClientAndServer(handler)
+	defer s.Close()
+	c.ReadTimeout = 10 * time.Second
+
+	iface, err := getLoopbackInterface()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.ExchangeContext(ctx, iface, nil)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.True(t, time.Since(start) < c.ReadTimeout)
+}
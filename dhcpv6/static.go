@@ -0,0 +1,88 @@
+package dhcpv6
+
+import "net"
+
+// StaticHandler returns a handler answering every client with the same canned
+// options, without any allocation: each Solicit gets an Advertise, or a Reply
+// if it has a Rapid Commit option, and each Request, Renew, Rebind or
+// Information-request a Reply. It is meant for test harnesses, e.g. to check
+// that a PXE client or an installer image boots with a given configuration,
+// and must not be used on production networks, where every client would get
+// the same address.
+//
+// The replies hold the client identifier, serverID and the canned options. The
+// IA_NA and IA_PD options are given the IAID of the first IA of the same type
+// of the client, and left out if it has none, or for an Information-request.
+// The replies to relayed messages are encapsulated in Relay-reply messages.
+func StaticHandler(serverID Duid, options ...Option) Handler {
+	return func(conn net.PacketConn, peer net.Addr, m DHCPv6) {
+		inner, err := innerMessage(m)
+		if err != nil {
+			return
+		}
+		var reply DHCPv6
+		switch inner.Type() {
+		case MessageTypeSolicit:
+			if inner.GetOneOption(OptionRapidCommit) != nil {
+				reply, err = NewReplyFromMessage(inner, WithServerID(serverID))
+			} else {
+				reply, err = NewAdvertiseFromSolicit(inner, WithServerID(serverID))
+			}
+		case MessageTypeRequest, MessageTypeRenew, MessageTypeRebind, MessageTypeInformationRequest:
+			reply, err = NewReplyFromMessage(inner, WithServerID(serverID))
+		default:
+			return
+		}
+		if err != nil {
+			DefaultLogger.Printf("Cannot build the reply to %v: %v", peer, err)
+			return
+		}
+		for _, opt := range options {
+			switch opt.Code() {
+			case OptionClientID, OptionServerID:
+				continue
+			case OptionIANA, OptionIAPD:
+				if opt = staticIA(inner, opt); opt == nil {
+					continue
+				}
+			}
+			reply.AddOption(opt)
+		}
+		if m.IsRelay() {
+			if reply, err = NewRelayReplFromRelayForw(m, reply); err != nil {
+				DefaultLogger.Printf("Cannot build the reply to %v: %v", peer, err)
+				return
+			}
+		}
+		if _, err := conn.WriteTo(reply.ToBytes(), peer); err != nil {
+			DefaultLogger.Printf("Cannot send the reply to %v: %v", peer, err)
+		}
+	}
+}
+
+// staticIA returns a copy of the canned IA opt with the IAID of the first IA
+// of the same type of request, or nil if there is none.
+func staticIA(request DHCPv6, opt Option) Option {
+	if request.Type() == MessageTypeInformationRequest {
+		return nil
+	}
+	switch ia := opt.(type) {
+	case *OptIANA:
+		client, ok := request.GetOneOption(OptionIANA).(*OptIANA)
+		if !ok {
+			return nil
+		}
+		c := *ia
+		c.IaId = client.IaId
+		return &c
+	case *OptIAForPrefixDelegation:
+		client, ok := request.GetOneOption(OptionIAPD).(*OptIAForPrefixDelegation)
+		if !ok {
+			return nil
+		}
+		c := *ia
+		c.iaId = client.iaId
+		return &c
+	}
+	return opt
+}
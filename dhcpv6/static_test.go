@@ -0,0 +1,69 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticHandler(t *testing.T) {
+	serverID := Duid{Type: DUID_LL, HwType: 1, LinkLayerAddr: net.HardwareAddr{1, 2, 3, 4, 5, 6}}
+	clientID := Duid{Type: DUID_LL, HwType: 1, LinkLayerAddr: net.HardwareAddr{6, 5, 4, 3, 2, 1}}
+	h := StaticHandler(serverID,
+		&OptIANA{
+			IaId:    [4]byte{0, 0, 0, 1},
+			T1:      1800,
+			T2:      2880,
+			Options: []Option{&OptIAAddress{IPv6Addr: net.ParseIP("2001:db8::10"), PreferredLifetime: 3600, ValidLifetime: 7200}},
+		},
+		&OptDNSRecursiveNameServer{NameServers: []net.IP{net.ParseIP("2001:db8::53")}},
+		&OptBootFileURL{BootFileURL: []byte("http://[2001:db8::1]/boot")},
+	)
+	ia := &OptIANA{IaId: [4]byte{0xaa, 0xbb, 0xcc, 0xdd}}
+
+	solicit, err := NewMessage(WithClientID(clientID))
+	require.NoError(t, err)
+	solicit.AddOption(ia)
+	relayed, err := EncapsulateRelay(solicit, MessageTypeRelayForward, net.ParseIP("2001:db8::1"), net.ParseIP("fe80::1"))
+	require.NoError(t, err)
+
+	for _, m := range []DHCPv6{solicit, relayed} {
+		conn := &writesConn{}
+		h(conn, &net.UDPAddr{}, m)
+		require.Len(t, conn.writes, 1)
+		written, err := FromBytes(conn.writes[0])
+		require.NoError(t, err)
+		require.Equal(t, m.IsRelay(), written.IsRelay())
+		advertise, err := innerMessage(written)
+		require.NoError(t, err)
+		require.Equal(t, MessageTypeAdvertise, advertise.Type())
+		require.Equal(t, &OptServerId{Sid: serverID}, advertise.GetOneOption(OptionServerID))
+		require.Equal(t, &OptClientId{Cid: clientID}, advertise.GetOneOption(OptionClientID))
+		got, ok := advertise.GetOneOption(OptionIANA).(*OptIANA)
+		require.True(t, ok)
+		// the IA of the client
+		require.Equal(t, ia.IaId, got.IaId)
+		require.True(t, got.GetOneOption(OptionIAAddr).(*OptIAAddress).IPv6Addr.Equal(net.ParseIP("2001:db8::10")))
+		require.NotNil(t, advertise.GetOneOption(OptionBootfileURL))
+	}
+
+	// an Information-request gets no IA
+	info, err := NewMessage(WithClientID(clientID))
+	require.NoError(t, err)
+	info.(*DHCPv6Message).SetMessage(MessageTypeInformationRequest)
+	info.AddOption(ia)
+	conn := &writesConn{}
+	h(conn, &net.UDPAddr{}, info)
+	require.Len(t, conn.writes, 1)
+	reply, err := FromBytes(conn.writes[0])
+	require.NoError(t, err)
+	require.Equal(t, MessageTypeReply, reply.Type())
+	require.Nil(t, reply.GetOneOption(OptionIANA))
+	require.NotNil(t, reply.GetOneOption(OptionDNSRecursiveNameServer))
+
+	// the other messages are ignored
+	info.(*DHCPv6Message).SetMessage(MessageTypeRelease)
+	h(conn, &net.UDPAddr{}, info)
+	require.Len(t, conn.writes, 1)
+}
@@ -0,0 +1,380 @@
+// Code generated by ianagen from the IANA registries. DO NOT EDIT.
+
+package dhcpv6
+
+// The different kinds of DHCPv6 message types.
+const (
+	MessageTypeSolicit            MessageType = 1  // [RFC8415]
+	MessageTypeAdvertise          MessageType = 2  // [RFC8415]
+	MessageTypeRequest            MessageType = 3  // [RFC8415]
+	MessageTypeConfirm            MessageType = 4  // [RFC8415]
+	MessageTypeRenew              MessageType = 5  // [RFC8415]
+	MessageTypeRebind             MessageType = 6  // [RFC8415]
+	MessageTypeReply              MessageType = 7  // [RFC8415]
+	MessageTypeRelease            MessageType = 8  // [RFC8415]
+	MessageTypeDecline            MessageType = 9  // [RFC8415]
+	MessageTypeReconfigure        MessageType = 10 // [RFC8415]
+	MessageTypeInformationRequest MessageType = 11 // [RFC8415]
+	MessageTypeRelayForward       MessageType = 12 // [RFC8415]
+	MessageTypeRelayReply         MessageType = 13 // [RFC8415]
+	MessageTypeLeaseQuery         MessageType = 14 // [RFC5007]
+	MessageTypeLeaseQueryReply    MessageType = 15 // [RFC5007]
+	MessageTypeLeaseQueryDone     MessageType = 16 // [RFC5460]
+	MessageTypeLeaseQueryData     MessageType = 17 // [RFC5460]
+	MessageTypeReconfigureRequest MessageType = 18 // [RFC6977]
+	MessageTypeReconfigureReply   MessageType = 19 // [RFC6977]
+	MessageTypeDHCPv4Query        MessageType = 20 // [RFC7341]
+	MessageTypeDHCPv4Response     MessageType = 21 // [RFC7341]
+	MessageTypeActiveLeaseQuery   MessageType = 22 // [RFC7653]
+	MessageTypeStartTLS           MessageType = 23 // [RFC7653]
+	MessageTypeBindingUpdate      MessageType = 24 // [RFC8156]
+	MessageTypeBindingReply       MessageType = 25 // [RFC8156]
+	MessageTypePoolRequest        MessageType = 26 // [RFC8156]
+	MessageTypePoolResponse       MessageType = 27 // [RFC8156]
+	MessageTypeUpdateRequest      MessageType = 28 // [RFC8156]
+	MessageTypeUpdateRequestAll   MessageType = 29 // [RFC8156]
+	MessageTypeUpdateDone         MessageType = 30 // [RFC8156]
+	MessageTypeConnect            MessageType = 31 // [RFC8156]
+	MessageTypeConnectReply       MessageType = 32 // [RFC8156]
+	MessageTypeDisconnect         MessageType = 33 // [RFC8156]
+	MessageTypeState              MessageType = 34 // [RFC8156]
+	MessageTypeContact            MessageType = 35 // [RFC8156]
+)
+
+// MessageTypeToStringMap contains the mapping of MessageTypes to human-readable
+// strings.
+var MessageTypeToStringMap = map[MessageType]string{
+	MessageTypeSolicit:            "SOLICIT",
+	MessageTypeAdvertise:          "ADVERTISE",
+	MessageTypeRequest:            "REQUEST",
+	MessageTypeConfirm:            "CONFIRM",
+	MessageTypeRenew:              "RENEW",
+	MessageTypeRebind:             "REBIND",
+	MessageTypeReply:              "REPLY",
+	MessageTypeRelease:            "RELEASE",
+	MessageTypeDecline:            "DECLINE",
+	MessageTypeReconfigure:        "RECONFIGURE",
+	MessageTypeInformationRequest: "INFORMATION-REQUEST",
+	MessageTypeRelayForward:       "RELAY-FORW",
+	MessageTypeRelayReply:         "RELAY-REPL",
+	MessageTypeLeaseQuery:         "LEASEQUERY",
+	MessageTypeLeaseQueryReply:    "LEASEQUERY-REPLY",
+	MessageTypeLeaseQueryDone:     "LEASEQUERY-DONE",
+	MessageTypeLeaseQueryData:     "LEASEQUERY-DATA",
+	MessageTypeReconfigureRequest: "RECONFIGURE-REQUEST",
+	MessageTypeReconfigureReply:   "RECONFIGURE-REPLY",
+	MessageTypeDHCPv4Query:        "DHCPV4-QUERY",
+	MessageTypeDHCPv4Response:     "DHCPV4-RESPONSE",
+	MessageTypeActiveLeaseQuery:   "ACTIVELEASEQUERY",
+	MessageTypeStartTLS:           "STARTTLS",
+	MessageTypeBindingUpdate:      "BNDUPD",
+	MessageTypeBindingReply:       "BNDREPLY",
+	MessageTypePoolRequest:        "POOLREQ",
+	MessageTypePoolResponse:       "POOLRESP",
+	MessageTypeUpdateRequest:      "UPDREQ",
+	MessageTypeUpdateRequestAll:   "UPDREQALL",
+	MessageTypeUpdateDone:         "UPDDONE",
+	MessageTypeConnect:            "CONNECT",
+	MessageTypeConnectReply:       "CONNECTREPLY",
+	MessageTypeDisconnect:         "DISCONNECT",
+	MessageTypeState:              "STATE",
+	MessageTypeContact:            "CONTACT",
+}
+
+// All DHCPv6 options.
+const (
+	OptionClientID                                OptionCode = 1   // [RFC8415]
+	OptionServerID                                OptionCode = 2   // [RFC8415]
+	OptionIANA                                    OptionCode = 3   // [RFC8415]
+	OptionIATA                                    OptionCode = 4   // [RFC8415]
+	OptionIAAddr                                  OptionCode = 5   // [RFC8415]
+	OptionORO                                     OptionCode = 6   // [RFC8415]
+	OptionPreference                              OptionCode = 7   // [RFC8415]
+	OptionElapsedTime                             OptionCode = 8   // [RFC8415]
+	OptionRelayMsg                                OptionCode = 9   // [RFC8415]
+	OptionAuth                                    OptionCode = 11  // [RFC8415]
+	OptionUnicast                                 OptionCode = 12  // [RFC8415]
+	OptionStatusCode                              OptionCode = 13  // [RFC8415]
+	OptionRapidCommit                             OptionCode = 14  // [RFC8415]
+	OptionUserClass                               OptionCode = 15  // [RFC8415]
+	OptionVendorClass                             OptionCode = 16  // [RFC8415]
+	OptionVendorOpts                              OptionCode = 17  // [RFC8415]
+	OptionInterfaceID                             OptionCode = 18  // [RFC8415]
+	OptionReconfMessage                           OptionCode = 19  // [RFC8415]
+	OptionReconfAccept                            OptionCode = 20  // [RFC8415]
+	OptionSIPServersDomainNameList                OptionCode = 21  // [RFC3319]
+	OptionSIPServersIPv6AddressList               OptionCode = 22  // [RFC3319]
+	OptionDNSRecursiveNameServer                  OptionCode = 23  // [RFC3646]
+	OptionDomainSearchList                        OptionCode = 24  // [RFC3646]
+	OptionIAPD                                    OptionCode = 25  // [RFC8415]
+	OptionIAPrefix                                OptionCode = 26  // [RFC8415]
+	OptionNISServers                              OptionCode = 27  // [RFC3898]
+	OptionNISPServers                             OptionCode = 28  // [RFC3898]
+	OptionNISDomainName                           OptionCode = 29  // [RFC3898]
+	OptionNISPDomainName                          OptionCode = 30  // [RFC3898]
+	OptionSNTPServerList                          OptionCode = 31  // [RFC4075]
+	OptionInformationRefreshTime                  OptionCode = 32  // [RFC8415]
+	OptionBCMCSControllerDomainNameList           OptionCode = 33  // [RFC4280]
+	OptionBCMCSControllerIPv6AddressList          OptionCode = 34  // [RFC4280]
+	OptionGeoConfCivic                            OptionCode = 36  // [RFC4776]
+	OptionRemoteID                                OptionCode = 37  // [RFC4649]
+	OptionRelayAgentSubscriberID                  OptionCode = 38  // [RFC4580]
+	OptionFQDN                                    OptionCode = 39  // [RFC4704]
+	OptionPANAAuthenticationAgent                 OptionCode = 40  // [RFC5192]
+	OptionNewPOSIXTimezone                        OptionCode = 41  // [RFC4833]
+	OptionNewTZDBTimezone                         OptionCode = 42  // [RFC4833]
+	OptionEchoRequest                             OptionCode = 43  // [RFC4994]
+	OptionLQQuery                                 OptionCode = 44  // [RFC5007]
+	OptionClientData                              OptionCode = 45  // [RFC5007]
+	OptionCLTTime                                 OptionCode = 46  // [RFC5007]
+	OptionLQRelayData                             OptionCode = 47  // [RFC5007]
+	OptionLQClientLink                            OptionCode = 48  // [RFC5007]
+	OptionMIPv6HomeNetworkIDFQDN                  OptionCode = 49  // [RFC6610]
+	OptionMIPv6VisitedHomeNetworkInformation      OptionCode = 50  // [RFC6610]
+	OptionLoSTServer                              OptionCode = 51  // [RFC5223]
+	OptionCAPWAPAccessControllerAddresses         OptionCode = 52  // [RFC5417]
+	OptionRelayID                                 OptionCode = 53  // [RFC5460]
+	OptionIPv6AddressMOS                          OptionCode = 54  // [RFC5678]
+	OptionIPv6FQDNMOS                             OptionCode = 55  // [RFC5678]
+	OptionNTPServer                               OptionCode = 56  // [RFC5908]
+	OptionV6AccessDomain                          OptionCode = 57  // [RFC5986]
+	OptionSIPUACSList                             OptionCode = 58  // [RFC6011]
+	OptionBootfileURL                             OptionCode = 59  // [RFC5970]
+	OptionBootfileParam                           OptionCode = 60  // [RFC5970]
+	OptionClientArchType                          OptionCode = 61  // [RFC5970]
+	OptionNII                                     OptionCode = 62  // [RFC5970]
+	OptionGeolocation                             OptionCode = 63  // [RFC6225]
+	OptionAFTRName                                OptionCode = 64  // [RFC6334]
+	OptionERPLocalDomainName                      OptionCode = 65  // [RFC6440]
+	OptionRSOO                                    OptionCode = 66  // [RFC6422]
+	OptionPDExclude                               OptionCode = 67  // [RFC6603]
+	OptionVirtualSubnetSelection                  OptionCode = 68  // [RFC6607]
+	OptionMIPv6IdentifiedHomeNetworkInformation   OptionCode = 69  // [RFC6610]
+	OptionMIPv6UnrestrictedHomeNetworkInformation OptionCode = 70  // [RFC6610]
+	OptionMIPv6HomeNetworkPrefix                  OptionCode = 71  // [RFC6610]
+	OptionMIPv6HomeAgentAddress                   OptionCode = 72  // [RFC6610]
+	OptionMIPv6HomeAgentFQDN                      OptionCode = 73  // [RFC6610]
+	OptionRDNSSSelection                          OptionCode = 74  // [RFC6731]
+	OptionKRBPrincipalName                        OptionCode = 75  // [RFC6784]
+	OptionKRBRealmName                            OptionCode = 76  // [RFC6784]
+	OptionKRBDefaultRealmName                     OptionCode = 77  // [RFC6784]
+	OptionKRBKDC                                  OptionCode = 78  // [RFC6784]
+	OptionClientLinkLayerAddr                     OptionCode = 79  // [RFC6939]
+	OptionLinkAddress                             OptionCode = 80  // [RFC6977]
+	OptionRADIUS                                  OptionCode = 81  // [RFC7037]
+	OptionSolMaxRT                                OptionCode = 82  // [RFC8415]
+	OptionInfMaxRT                                OptionCode = 83  // [RFC8415]
+	OptionAddrsel                                 OptionCode = 84  // [RFC7078]
+	OptionAddrselTable                            OptionCode = 85  // [RFC7078]
+	OptionV6PCPServer                             OptionCode = 86  // [RFC7291]
+	OptionDHCPv4Msg                               OptionCode = 87  // [RFC7341]
+	OptionDHCP4ODHCP6Server                       OptionCode = 88  // [RFC7341]
+	OptionS46Rule                                 OptionCode = 89  // [RFC7598]
+	OptionS46BR                                   OptionCode = 90  // [RFC7598][RFC8539]
+	OptionS46DMR                                  OptionCode = 91  // [RFC7598]
+	OptionS46V4V6Bind                             OptionCode = 92  // [RFC7598]
+	OptionS46PortParams                           OptionCode = 93  // [RFC7598]
+	OptionS46ContMAPE                             OptionCode = 94  // [RFC7598]
+	OptionS46ContMAPT                             OptionCode = 95  // [RFC7598]
+	OptionS46ContLW                               OptionCode = 96  // [RFC7598]
+	Option4RD                                     OptionCode = 97  // [RFC7600]
+	Option4RDMapRule                              OptionCode = 98  // [RFC7600]
+	Option4RDNonMapRule                           OptionCode = 99  // [RFC7600]
+	OptionLQBaseTime                              OptionCode = 100 // [RFC7653]
+	OptionLQStartTime                             OptionCode = 101 // [RFC7653]
+	OptionLQEndTime                               OptionCode = 102 // [RFC7653]
+	OptionDHCPCaptivePortal                       OptionCode = 103 // [RFC8910]
+	OptionMPLParameters                           OptionCode = 104 // [RFC7774]
+	OptionANIAtt                                  OptionCode = 105 // [RFC7839]
+	OptionANINetworkName                          OptionCode = 106 // [RFC7839]
+	OptionANIAPName                               OptionCode = 107 // [RFC7839]
+	OptionANIAPBSSID                              OptionCode = 108 // [RFC7839]
+	OptionANIOperatorID                           OptionCode = 109 // [RFC7839]
+	OptionANIOperatorRealm                        OptionCode = 110 // [RFC7839]
+	OptionS46Priority                             OptionCode = 111 // [RFC8026]
+	OptionMUDURLV6                                OptionCode = 112 // [RFC8520]
+	OptionV6Prefix64                              OptionCode = 113 // [RFC8115]
+	OptionFBindingStatus                          OptionCode = 114 // [RFC8156]
+	OptionFConnectFlags                           OptionCode = 115 // [RFC8156]
+	OptionFDNSRemovalInfo                         OptionCode = 116 // [RFC8156]
+	OptionFDNSHostName                            OptionCode = 117 // [RFC8156]
+	OptionFDNSZoneName                            OptionCode = 118 // [RFC8156]
+	OptionFDNSFlags                               OptionCode = 119 // [RFC8156]
+	OptionFExpirationTime                         OptionCode = 120 // [RFC8156]
+	OptionFMaxUnackedBndupd                       OptionCode = 121 // [RFC8156]
+	OptionFMCLT                                   OptionCode = 122 // [RFC8156]
+	OptionFPartnerLifetime                        OptionCode = 123 // [RFC8156]
+	OptionFPartnerLifetimeSent                    OptionCode = 124 // [RFC8156]
+	OptionFPartnerDownTime                        OptionCode = 125 // [RFC8156]
+	OptionFPartnerRawCLTTime                      OptionCode = 126 // [RFC8156]
+	OptionFProtocolVersion                        OptionCode = 127 // [RFC8156]
+	OptionFKeepaliveTime                          OptionCode = 128 // [RFC8156]
+	OptionFReconfigureData                        OptionCode = 129 // [RFC8156]
+	OptionFRelationshipName                       OptionCode = 130 // [RFC8156]
+	OptionFServerFlags                            OptionCode = 131 // [RFC8156]
+	OptionFServerState                            OptionCode = 132 // [RFC8156]
+	OptionFStartTimeOfState                       OptionCode = 133 // [RFC8156]
+	OptionFStateExpirationTime                    OptionCode = 134 // [RFC8156]
+	OptionRelayPort                               OptionCode = 135 // [RFC8357]
+	OptionV6SZTPRedirect                          OptionCode = 136 // [RFC8572]
+	OptionS46BindIPv6Prefix                       OptionCode = 137 // [RFC8539]
+	OptionIALL                                    OptionCode = 138 // [RFC8947]
+	OptionLLAddr                                  OptionCode = 139 // [RFC8947]
+	OptionSLAPQuad                                OptionCode = 140 // [RFC8948]
+	OptionV6DOTSRI                                OptionCode = 141 // [RFC8973]
+	OptionV6DOTSAddress                           OptionCode = 142 // [RFC8973]
+	OptionIPv6AddressANDSF                        OptionCode = 143 // [RFC6153]
+	OptionV6DNR                                   OptionCode = 144 // [RFC9463]
+	OptionRegisteredDomain                        OptionCode = 145 // [RFC9527]
+	OptionForwardDistManager                      OptionCode = 146 // [RFC9527]
+	OptionReverseDistManager                      OptionCode = 147 // [RFC9527]
+)
+
+// OptionCodeToString maps DHCPv6 OptionCodes to human-readable strings.
+var OptionCodeToString = map[OptionCode]string{
+	OptionClientID:                              "OPTION_CLIENTID",
+	OptionServerID:                              "OPTION_SERVERID",
+	OptionIANA:                                  "OPTION_IA_NA",
+	OptionIATA:                                  "OPTION_IA_TA",
+	OptionIAAddr:                                "OPTION_IAADDR",
+	OptionORO:                                   "OPTION_ORO",
+	OptionPreference:                            "OPTION_PREFERENCE",
+	OptionElapsedTime:                           "OPTION_ELAPSED_TIME",
+	OptionRelayMsg:                              "OPTION_RELAY_MSG",
+	OptionAuth:                                  "OPTION_AUTH",
+	OptionUnicast:                               "OPTION_UNICAST",
+	OptionStatusCode:                            "OPTION_STATUS_CODE",
+	OptionRapidCommit:                           "OPTION_RAPID_COMMIT",
+	OptionUserClass:                             "OPTION_USER_CLASS",
+	OptionVendorClass:                           "OPTION_VENDOR_CLASS",
+	OptionVendorOpts:                            "OPTION_VENDOR_OPTS",
+	OptionInterfaceID:                           "OPTION_INTERFACE_ID",
+	OptionReconfMessage:                         "OPTION_RECONF_MSG",
+	OptionReconfAccept:                          "OPTION_RECONF_ACCEPT",
+	OptionSIPServersDomainNameList:              "SIP Servers Domain Name List",
+	OptionSIPServersIPv6AddressList:             "SIP Servers IPv6 Address List",
+	OptionDNSRecursiveNameServer:                "DNS Recursive Name Server",
+	OptionDomainSearchList:                      "Domain Search List",
+	OptionIAPD:                                  "OPTION_IA_PD",
+	OptionIAPrefix:                              "OPTION_IAPREFIX",
+	OptionNISServers:                            "OPTION_NIS_SERVERS",
+	OptionNISPServers:                           "OPTION_NISP_SERVERS",
+	OptionNISDomainName:                         "OPTION_NIS_DOMAIN_NAME",
+	OptionNISPDomainName:                        "OPTION_NISP_DOMAIN_NAME",
+	OptionSNTPServerList:                        "SNTP Server List",
+	OptionInformationRefreshTime:                "Information Refresh Time",
+	OptionBCMCSControllerDomainNameList:         "BCMCS Controller Domain Name List",
+	OptionBCMCSControllerIPv6AddressList:        "BCMCS Controller IPv6 Address List",
+	OptionGeoConfCivic:                          "OPTION_GEOCONF",
+	OptionRemoteID:                              "OPTION_REMOTE_ID",
+	OptionRelayAgentSubscriberID:                "Relay-Agent Subscriber ID",
+	OptionFQDN:                                  "FQDN",
+	OptionPANAAuthenticationAgent:               "PANA Authentication Agent",
+	OptionNewPOSIXTimezone:                      "OPTION_NEW_POSIX_TIME_ZONE",
+	OptionNewTZDBTimezone:                       "OPTION_NEW_TZDB_TIMEZONE",
+	OptionEchoRequest:                           "Echo Request",
+	OptionLQQuery:                               "OPTION_LQ_QUERY",
+	OptionClientData:                            "OPTION_CLIENT_DATA",
+	OptionCLTTime:                               "OPTION_CLT_TIME",
+	OptionLQRelayData:                           "OPTION_LQ_RELAY_DATA",
+	OptionLQClientLink:                          "OPTION_LQ_CLIENT_LINK",
+	OptionMIPv6HomeNetworkIDFQDN:                "MIPv6 Home Network ID FQDN",
+	OptionMIPv6VisitedHomeNetworkInformation:    "MIPv6 Visited Home Network Information",
+	OptionLoSTServer:                            "LoST Server",
+	OptionCAPWAPAccessControllerAddresses:       "CAPWAP Access Controller Addresses",
+	OptionRelayID:                               "RELAY_ID",
+	OptionIPv6AddressMOS:                        "OPTION-IPv6_Address-MoS",
+	OptionIPv6FQDNMOS:                           "OPTION-IPv6-FQDN-MoS",
+	OptionNTPServer:                             "OPTION_NTP_SERVER",
+	OptionV6AccessDomain:                        "OPTION_V6_ACCESS_DOMAIN",
+	OptionSIPUACSList:                           "OPTION_SIP_UA_CS_LIST",
+	OptionBootfileURL:                           "OPT_BOOTFILE_URL",
+	OptionBootfileParam:                         "OPT_BOOTFILE_PARAM",
+	OptionClientArchType:                        "OPTION_CLIENT_ARCH_TYPE",
+	OptionNII:                                   "OPTION_NII",
+	OptionGeolocation:                           "OPTION_GEOLOCATION",
+	OptionAFTRName:                              "OPTION_AFTR_NAME",
+	OptionERPLocalDomainName:                    "OPTION_ERP_LOCAL_DOMAIN_NAME",
+	OptionRSOO:                                  "OPTION_RSOO",
+	OptionPDExclude:                             "OPTION_PD_EXCLUDE",
+	OptionVirtualSubnetSelection:                "Virtual Subnet Selection",
+	OptionMIPv6IdentifiedHomeNetworkInformation: "MIPv6 Identified Home Network Information",
+	OptionMIPv6UnrestrictedHomeNetworkInformation: "MIPv6 Unrestricted Home Network Information",
+	OptionMIPv6HomeNetworkPrefix:                  "MIPv6 Home Network Prefix",
+	OptionMIPv6HomeAgentAddress:                   "MIPv6 Home Agent Address",
+	OptionMIPv6HomeAgentFQDN:                      "MIPv6 Home Agent FQDN",
+	OptionRDNSSSelection:                          "OPTION_RDNSS_SELECTION",
+	OptionKRBPrincipalName:                        "OPTION_KRB_PRINCIPAL_NAME",
+	OptionKRBRealmName:                            "OPTION_KRB_REALM_NAME",
+	OptionKRBDefaultRealmName:                     "OPTION_KRB_DEFAULT_REALM_NAME",
+	OptionKRBKDC:                                  "OPTION_KRB_KDC",
+	OptionClientLinkLayerAddr:                     "OPTION_CLIENT_LINKLAYER_ADDR",
+	OptionLinkAddress:                             "OPTION_LINK_ADDRESS",
+	OptionRADIUS:                                  "OPTION_RADIUS",
+	OptionSolMaxRT:                                "OPTION_SOL_MAX_RT",
+	OptionInfMaxRT:                                "OPTION_INF_MAX_RT",
+	OptionAddrsel:                                 "OPTION_ADDRSEL",
+	OptionAddrselTable:                            "OPTION_ADDRSEL_TABLE",
+	OptionV6PCPServer:                             "OPTION_V6_PCP_SERVER",
+	OptionDHCPv4Msg:                               "OPTION_DHCPV4_MSG",
+	OptionDHCP4ODHCP6Server:                       "OPTION_DHCP4_O_DHCP6_SERVER",
+	OptionS46Rule:                                 "OPTION_S46_RULE",
+	OptionS46BR:                                   "OPTION_S46_BR",
+	OptionS46DMR:                                  "OPTION_S46_DMR",
+	OptionS46V4V6Bind:                             "OPTION_S46_V4V6BIND",
+	OptionS46PortParams:                           "OPTION_S46_PORTPARAMS",
+	OptionS46ContMAPE:                             "OPTION_S46_CONT_MAPE",
+	OptionS46ContMAPT:                             "OPTION_S46_CONT_MAPT",
+	OptionS46ContLW:                               "OPTION_S46_CONT_LW",
+	Option4RD:                                     "OPTION_4RD",
+	Option4RDMapRule:                              "OPTION_4RD_MAP_RULE",
+	Option4RDNonMapRule:                           "OPTION_4RD_NON_MAP_RULE",
+	OptionLQBaseTime:                              "OPTION_LQ_BASE_TIME",
+	OptionLQStartTime:                             "OPTION_LQ_START_TIME",
+	OptionLQEndTime:                               "OPTION_LQ_END_TIME",
+	OptionDHCPCaptivePortal:                       "DHCP Captive-Portal",
+	OptionMPLParameters:                           "OPTION_MPL_PARAMETERS",
+	OptionANIAtt:                                  "OPTION_ANI_ATT",
+	OptionANINetworkName:                          "OPTION_ANI_NETWORK_NAME",
+	OptionANIAPName:                               "OPTION_ANI_AP_NAME",
+	OptionANIAPBSSID:                              "OPTION_ANI_AP_BSSID",
+	OptionANIOperatorID:                           "OPTION_ANI_OPERATOR_ID",
+	OptionANIOperatorRealm:                        "OPTION_ANI_OPERATOR_REALM",
+	OptionS46Priority:                             "OPTION_S46_PRIORITY",
+	OptionMUDURLV6:                                "OPTION_MUD_URL_V6",
+	OptionV6Prefix64:                              "OPTION_V6_PREFIX64",
+	OptionFBindingStatus:                          "OPTION_F_BINDING_STATUS",
+	OptionFConnectFlags:                           "OPTION_F_CONNECT_FLAGS",
+	OptionFDNSRemovalInfo:                         "OPTION_F_DNS_REMOVAL_INFO",
+	OptionFDNSHostName:                            "OPTION_F_DNS_HOST_NAME",
+	OptionFDNSZoneName:                            "OPTION_F_DNS_ZONE_NAME",
+	OptionFDNSFlags:                               "OPTION_F_DNS_FLAGS",
+	OptionFExpirationTime:                         "OPTION_F_EXPIRATION_TIME",
+	OptionFMaxUnackedBndupd:                       "OPTION_F_MAX_UNACKED_BNDUPD",
+	OptionFMCLT:                                   "OPTION_F_MCLT",
+	OptionFPartnerLifetime:                        "OPTION_F_PARTNER_LIFETIME",
+	OptionFPartnerLifetimeSent:                    "OPTION_F_PARTNER_LIFETIME_SENT",
+	OptionFPartnerDownTime:                        "OPTION_F_PARTNER_DOWN_TIME",
+	OptionFPartnerRawCLTTime:                      "OPTION_F_PARTNER_RAW_CLT_TIME",
+	OptionFProtocolVersion:                        "OPTION_F_PROTOCOL_VERSION",
+	OptionFKeepaliveTime:                          "OPTION_F_KEEPALIVE_TIME",
+	OptionFReconfigureData:                        "OPTION_F_RECONFIGURE_DATA",
+	OptionFRelationshipName:                       "OPTION_F_RELATIONSHIP_NAME",
+	OptionFServerFlags:                            "OPTION_F_SERVER_FLAGS",
+	OptionFServerState:                            "OPTION_F_SERVER_STATE",
+	OptionFStartTimeOfState:                       "OPTION_F_START_TIME_OF_STATE",
+	OptionFStateExpirationTime:                    "OPTION_F_STATE_EXPIRATION_TIME",
+	OptionRelayPort:                               "OPTION_RELAY_PORT",
+	OptionV6SZTPRedirect:                          "OPTION_V6_SZTP_REDIRECT",
+	OptionS46BindIPv6Prefix:                       "OPTION_S46_BIND_IPV6_PREFIX",
+	OptionIALL:                                    "OPTION_IA_LL",
+	OptionLLAddr:                                  "OPTION_LLADDR",
+	OptionSLAPQuad:                                "OPTION_SLAP_QUAD",
+	OptionV6DOTSRI:                                "OPTION_V6_DOTS_RI",
+	OptionV6DOTSAddress:                           "OPTION_V6_DOTS_ADDRESS",
+	OptionIPv6AddressANDSF:                        "OPTION-IPv6_Address-ANDSF",
+	OptionV6DNR:                                   "OPTION_V6_DNR",
+	OptionRegisteredDomain:                        "OPTION_REGISTERED_DOMAIN",
+	OptionForwardDistManager:                      "OPTION_FORWARD_DIST_MANAGER",
+	OptionReverseDistManager:                      "OPTION_REVERSE_DIST_MANAGER",
+}
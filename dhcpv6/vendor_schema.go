@@ -0,0 +1,161 @@
+package dhcpv6
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// VendorSubOptionFormat is the format of the data of a vendor sub-option.
+type VendorSubOptionFormat int
+
+// Vendor sub-option formats
+const (
+	// VendorFormatBytes is opaque data, decoded as []byte.
+	VendorFormatBytes VendorSubOptionFormat = iota
+	// VendorFormatString is text, decoded as string.
+	VendorFormatString
+	// VendorFormatIPs is a list of IPv6 addresses, decoded as []net.IP.
+	VendorFormatIPs
+	// VendorFormatUint32 is a 32-bit integer, decoded as uint32.
+	VendorFormatUint32
+	// VendorFormatCodes is a list of 2-byte codes, e.g. of sub-options to
+	// send, decoded as []uint16.
+	VendorFormatCodes
+)
+
+// VendorSubOptionSpec describes a vendor sub-option.
+type VendorSubOptionSpec struct {
+	Name   string
+	Format VendorSubOptionFormat
+}
+
+// VendorSchema describes the sub-options of a vendor, by code. Its Parser can
+// be registered with RegisterVendorOptsParser, so that vendors that need no
+// specific types can be dissected.
+type VendorSchema map[uint16]VendorSubOptionSpec
+
+// OptVendorSubOption is a vendor sub-option decoded according to a
+// VendorSchema. Value holds the data decoded according to the format of the
+// sub-option, or the raw data if the sub-option is not in the schema or does
+// not match its format. Only Data is serialized.
+type OptVendorSubOption struct {
+	SubCode uint16
+	Name    string
+	Value   interface{}
+	Data    []byte
+}
+
+// Code returns the code of the sub-option
+func (op *OptVendorSubOption) Code() OptionCode {
+	return OptionCode(op.SubCode)
+}
+
+// ToBytes serializes the sub-option and returns it as a sequence of bytes
+func (op *OptVendorSubOption) ToBytes() []byte {
+	buf := make([]byte, 4, 4+len(op.Data))
+	binary.BigEndian.PutUint16(buf[0:2], op.SubCode)
+	binary.BigEndian.PutUint16(buf[2:4], uint16(len(op.Data)))
+	return append(buf, op.Data...)
+}
+
+// Length returns the sub-option length
+func (op *OptVendorSubOption) Length() int {
+	return len(op.Data)
+}
+
+// String returns a string representation of the sub-option
+func (op *OptVendorSubOption) String() string {
+	if s, ok := op.Value.(string); ok {
+		return fmt.Sprintf("%s -> %q", op.Name, s)
+	}
+	return fmt.Sprintf("%s -> %v", op.Name, op.Value)
+}
+
+// Parser returns a VendorOptionParser decoding the sub-options according to
+// the schema into OptVendorSubOption.
+func (s VendorSchema) Parser() VendorOptionParser {
+	return func(data []byte) (Option, error) {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("invalid vendor sub-option: less than 4 bytes")
+		}
+		code := binary.BigEndian.Uint16(data[0:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+length {
+			return nil, fmt.Errorf("invalid vendor sub-option length: declared %v, actual %v", length, len(data)-4)
+		}
+		data = data[4 : 4+length]
+		opt := &OptVendorSubOption{SubCode: code, Value: data, Data: data}
+		spec, ok := s[code]
+		if !ok {
+			opt.Name = fmt.Sprintf("Unknown (%d)", code)
+			return opt, nil
+		}
+		opt.Name = spec.Name
+		if v, ok := decodeVendorValue(spec.Format, data); ok {
+			opt.Value = v
+		}
+		return opt, nil
+	}
+}
+
+// decodeVendorValue decodes data according to format, and returns false if
+// it does not match the format.
+func decodeVendorValue(format VendorSubOptionFormat, data []byte) (interface{}, bool) {
+	switch format {
+	case VendorFormatString:
+		return string(data), true
+	case VendorFormatIPs:
+		if len(data)%net.IPv6len != 0 {
+			return nil, false
+		}
+		ips := make([]net.IP, 0, len(data)/net.IPv6len)
+		for i := 0; i < len(data); i += net.IPv6len {
+			ips = append(ips, net.IP(data[i:i+net.IPv6len]))
+		}
+		return ips, true
+	case VendorFormatUint32:
+		if len(data) != 4 {
+			return nil, false
+		}
+		return binary.BigEndian.Uint32(data), true
+	case VendorFormatCodes:
+		if len(data)%2 != 0 {
+			return nil, false
+		}
+		codes := make([]uint16, 0, len(data)/2)
+		for i := 0; i < len(data); i += 2 {
+			codes = append(codes, binary.BigEndian.Uint16(data[i:i+2]))
+		}
+		return codes, true
+	}
+	return data, true
+}
+
+// CableLabsSchema describes the CableLabs sub-options of the Vendor-specific
+// Information option, sent by cable modems and eRouters, see
+// CL-SP-CANN-DHCP-Reg.
+var CableLabsSchema = VendorSchema{
+	1:  {"Option Request", VendorFormatCodes},
+	2:  {"Device Type", VendorFormatString},
+	3:  {"Embedded Components List", VendorFormatString},
+	4:  {"Device Serial Number", VendorFormatString},
+	5:  {"Hardware Version Number", VendorFormatString},
+	6:  {"Software Version Number", VendorFormatString},
+	7:  {"Boot ROM Version", VendorFormatString},
+	8:  {"Vendor OUI", VendorFormatString},
+	9:  {"Model Number", VendorFormatString},
+	10: {"Vendor Name", VendorFormatString},
+	32: {"TFTP Servers", VendorFormatIPs},
+	33: {"Configuration File Name", VendorFormatString},
+	34: {"Syslog Servers", VendorFormatIPs},
+	36: {"Device Identifier", VendorFormatBytes},
+	37: {"Time Protocol Servers", VendorFormatIPs},
+	38: {"Time Offset", VendorFormatBytes},
+}
+
+func init() {
+	RegisterVendorOptsParser(iana.EnterpriseIDCableLabs, CableLabsSchema.Parser())
+}
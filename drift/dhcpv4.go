@@ -0,0 +1,104 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// Prober4 sends a DHCPINFORM and returns the reply of the server.
+type Prober4 func(ctx context.Context, inform *dhcpv4.DHCPv4) (*dhcpv4.DHCPv4, error)
+
+// UnicastProber4 returns a Prober4 sending the probes to the server at the
+// given address through client, see dhcpv4.Client.SendReceiveUnicast.
+func UnicastProber4(client *dhcpv4.Client, server net.IP) Prober4 {
+	return func(ctx context.Context, inform *dhcpv4.DHCPv4) (*dhcpv4.DHCPv4, error) {
+		return client.SendReceiveUnicastContext(ctx, server, inform)
+	}
+}
+
+// Profile4 is the intended DHCPv4 configuration of a subnet or client class.
+type Profile4 struct {
+	Name string
+	// Modifiers make the DHCPINFORM look like it comes from the subnet or
+	// class, e.g. dhcpv4.WithRelay for the subnet of a relay agent, or
+	// dhcpv4.WithVendorClass for a vendor class.
+	Modifiers []dhcpv4.Modifier
+	// Options are the options the server is meant to return.
+	Options []dhcpv4.Option
+}
+
+// Check4 probes the server with a copy of inform for each profile, and
+// reports how the replies drift from the profiles. The probes request the
+// options of the profile, and each get a new transaction ID.
+func Check4(ctx context.Context, probe Prober4, inform *dhcpv4.DHCPv4, profiles ...Profile4) []Report {
+	reports := make([]Report, 0, len(profiles))
+	for _, p := range profiles {
+		report := Report{Profile: p.Name}
+		report.Drifts, report.Err = check4(ctx, probe, inform, &p)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func check4(ctx context.Context, probe Prober4, inform *dhcpv4.DHCPv4, p *Profile4) ([]Drift, error) {
+	d := inform.Clone()
+	xid, err := dhcpv4.GenerateTransactionID()
+	if err != nil {
+		return nil, err
+	}
+	d.SetTransactionID(*xid)
+	for _, mod := range p.Modifiers {
+		d = mod(d)
+	}
+	requested := make(map[dhcpv4.OptionCode]bool)
+	if prl, ok := d.GetOneOption(dhcpv4.OptionParameterRequestList).(*dhcpv4.OptParameterRequestList); ok {
+		for _, code := range prl.RequestedOpts {
+			requested[code] = true
+		}
+	}
+	var codes []dhcpv4.OptionCode
+	for _, opt := range p.Options {
+		if !requested[opt.Code()] {
+			requested[opt.Code()] = true
+			codes = append(codes, opt.Code())
+		}
+	}
+	if len(codes) > 0 {
+		d = dhcpv4.WithRequestedOptions(codes...)(d)
+	}
+
+	reply, err := probe(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if reply.MessageType() == nil || *reply.MessageType() != dhcpv4.MessageTypeAck {
+		return nil, fmt.Errorf("unexpected reply %v", reply.MessageType())
+	}
+	var drifts []Drift
+	for _, want := range p.Options {
+		got, err := reply.GetMergedOption(want.Code())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v option in reply: %v", want.Code(), err)
+		}
+		switch {
+		case got == nil:
+			drifts = append(drifts, Drift{
+				Kind:   Missing,
+				Option: want.Code().String(),
+				Want:   want.String(),
+			})
+		case !bytes.Equal(got.ToBytes(), want.ToBytes()):
+			drifts = append(drifts, Drift{
+				Kind:   Changed,
+				Option: want.Code().String(),
+				Want:   want.String(),
+				Got:    got.String(),
+			})
+		}
+	}
+	return drifts, nil
+}
@@ -0,0 +1,98 @@
+package drift
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer4 answers the probes with the given options, plus the domain name
+// example.org for the clients of the relay 192.0.2.1.
+func fakeServer4(t *testing.T, opts ...dhcpv4.Option) Prober4 {
+	return func(ctx context.Context, inform *dhcpv4.DHCPv4) (*dhcpv4.DHCPv4, error) {
+		require.Equal(t, dhcpv4.MessageTypeInform, *inform.MessageType())
+		modifiers := []dhcpv4.Modifier{dhcpv4.WithMessageType(dhcpv4.MessageTypeAck)}
+		for _, opt := range opts {
+			modifiers = append(modifiers, dhcpv4.WithOption(opt))
+		}
+		if inform.GatewayIPAddr().Equal(net.IPv4(192, 0, 2, 1)) {
+			modifiers = append(modifiers, dhcpv4.WithOption(&dhcpv4.OptDomainName{DomainName: "example.org"}))
+		}
+		return dhcpv4.NewReplyFromRequest(inform, modifiers...)
+	}
+}
+
+func TestCheck4(t *testing.T) {
+	inform, err := dhcpv4.NewInform(net.HardwareAddr{0, 1, 2, 3, 4, 5}, net.IPv4(198, 51, 100, 10))
+	require.NoError(t, err)
+	probe := fakeServer4(t,
+		&dhcpv4.OptRouter{Routers: []net.IP{net.IPv4(198, 51, 100, 1)}},
+		&dhcpv4.OptDomainNameServer{NameServers: []net.IP{net.IPv4(198, 51, 100, 53)}},
+	)
+	reports := Check4(context.Background(), probe, inform,
+		Profile4{
+			Name: "local",
+			Options: []dhcpv4.Option{
+				&dhcpv4.OptRouter{Routers: []net.IP{net.IPv4(198, 51, 100, 1)}},
+				&dhcpv4.OptDomainNameServer{NameServers: []net.IP{net.IPv4(198, 51, 100, 53)}},
+			},
+		},
+		Profile4{
+			Name:      "relayed",
+			Modifiers: []dhcpv4.Modifier{dhcpv4.WithRelay(net.IPv4(192, 0, 2, 1))},
+			Options: []dhcpv4.Option{
+				&dhcpv4.OptDomainNameServer{NameServers: []net.IP{net.IPv4(192, 0, 2, 53)}},
+				&dhcpv4.OptDomainName{DomainName: "example.com"},
+				&dhcpv4.OptNTPServers{NTPServers: []net.IP{net.IPv4(192, 0, 2, 123)}},
+			},
+		},
+	)
+	require.Len(t, reports, 2)
+	require.True(t, reports[0].OK(), reports[0].String())
+
+	require.Equal(t, "relayed", reports[1].Profile)
+	require.NoError(t, reports[1].Err)
+	require.Equal(t, []Drift{
+		{
+			Kind:   Changed,
+			Option: "Domain Name Server",
+			Want:   "Domain Name Servers -> 192.0.2.53",
+			Got:    "Domain Name Servers -> 198.51.100.53",
+		},
+		{
+			Kind:   Changed,
+			Option: "Domain Name",
+			Want:   "Domain Name -> example.com",
+			Got:    "Domain Name -> example.org",
+		},
+		{
+			Kind:   Missing,
+			Option: "NTP Servers",
+			Want:   "NTP Servers -> 192.0.2.123",
+		},
+	}, reports[1].Drifts)
+
+	// the base INFORM is left untouched
+	require.Nil(t, inform.GetOneOption(dhcpv4.OptionParameterRequestList))
+	require.True(t, inform.GatewayIPAddr().IsUnspecified())
+}
+
+func TestCheck4ProbeError(t *testing.T) {
+	inform, err := dhcpv4.NewInform(net.HardwareAddr{0, 1, 2, 3, 4, 5}, net.IPv4(198, 51, 100, 10))
+	require.NoError(t, err)
+	probe := func(ctx context.Context, inform *dhcpv4.DHCPv4) (*dhcpv4.DHCPv4, error) {
+		return nil, dhcpv4.ErrTimeout
+	}
+	reports := Check4(context.Background(), probe, inform, Profile4{Name: "local"})
+	require.Equal(t, dhcpv4.ErrTimeout, reports[0].Err)
+
+	probe = func(ctx context.Context, inform *dhcpv4.DHCPv4) (*dhcpv4.DHCPv4, error) {
+		return dhcpv4.NewReplyFromRequest(inform, dhcpv4.WithMessageType(dhcpv4.MessageTypeNak))
+	}
+	reports = Check4(context.Background(), probe, inform, Profile4{Name: "local"})
+	require.Error(t, reports[0].Err)
+	require.False(t, reports[0].OK())
+}
@@ -0,0 +1,101 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+// Prober6 sends an INFORMATION-REQUEST and returns the reply of the server.
+type Prober6 func(ctx context.Context, request dhcpv6.DHCPv6) (dhcpv6.DHCPv6, error)
+
+// ClientProber6 returns a Prober6 sending the probes through client on the
+// given interface, see dhcpv6.Client.InformationRequest.
+func ClientProber6(client *dhcpv6.Client, ifname string) Prober6 {
+	return func(ctx context.Context, request dhcpv6.DHCPv6) (dhcpv6.DHCPv6, error) {
+		return client.InformationRequestContext(ctx, ifname, request)
+	}
+}
+
+// Profile6 is the intended DHCPv6 configuration of a link or client class.
+type Profile6 struct {
+	Name string
+	// Modifiers make the INFORMATION-REQUEST look like it comes from the
+	// link or class, e.g. dhcpv6.WithUserClass for a user class.
+	Modifiers []dhcpv6.Modifier
+	// Options are the options the server is meant to return.
+	Options []dhcpv6.Option
+}
+
+// Check6 probes the server with a copy of request for each profile, and
+// reports how the replies drift from the profiles. The probes request the
+// options of the profile, and each get a new transaction ID.
+func Check6(ctx context.Context, probe Prober6, request dhcpv6.DHCPv6, profiles ...Profile6) []Report {
+	reports := make([]Report, 0, len(profiles))
+	for _, p := range profiles {
+		report := Report{Profile: p.Name}
+		report.Drifts, report.Err = check6(ctx, probe, request, &p)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func check6(ctx context.Context, probe Prober6, request dhcpv6.DHCPv6, p *Profile6) ([]Drift, error) {
+	d, err := dhcpv6.FromBytes(request.ToBytes())
+	if err != nil {
+		return nil, err
+	}
+	msg, ok := d.(*dhcpv6.DHCPv6Message)
+	if !ok {
+		return nil, fmt.Errorf("not an INFORMATION-REQUEST: %v", d.Type())
+	}
+	xid, err := dhcpv6.GenerateTransactionID()
+	if err != nil {
+		return nil, err
+	}
+	msg.SetTransactionID(*xid)
+	for _, mod := range p.Modifiers {
+		d = mod(d)
+	}
+	requested := make(map[dhcpv6.OptionCode]bool)
+	if oro, ok := d.GetOneOption(dhcpv6.OptionORO).(*dhcpv6.OptRequestedOption); ok {
+		for _, code := range oro.RequestedOptions() {
+			requested[code] = true
+		}
+	}
+	var codes []dhcpv6.OptionCode
+	for _, opt := range p.Options {
+		if !requested[opt.Code()] {
+			requested[opt.Code()] = true
+			codes = append(codes, opt.Code())
+		}
+	}
+	if len(codes) > 0 {
+		d = dhcpv6.WithRequestedOptions(codes...)(d)
+	}
+
+	reply, err := probe(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Type() != dhcpv6.MessageTypeReply {
+		return nil, fmt.Errorf("unexpected reply %v", reply.Type())
+	}
+	var drifts []Drift
+	for _, want := range p.Options {
+		name := dhcpv6.OptionCodeToString[want.Code()]
+		if name == "" {
+			name = fmt.Sprintf("option %d", want.Code())
+		}
+		got := reply.GetOneOption(want.Code())
+		switch {
+		case got == nil:
+			drifts = append(drifts, Drift{Kind: Missing, Option: name, Want: want.String()})
+		case !bytes.Equal(got.ToBytes(), want.ToBytes()):
+			drifts = append(drifts, Drift{Kind: Changed, Option: name, Want: want.String(), Got: got.String()})
+		}
+	}
+	return drifts, nil
+}
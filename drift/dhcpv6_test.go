@@ -0,0 +1,60 @@
+package drift
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck6(t *testing.T) {
+	duid := dhcpv6.Duid{
+		Type:          dhcpv6.DUID_LL,
+		HwType:        iana.HwTypeEthernet,
+		LinkLayerAddr: net.HardwareAddr{0, 1, 2, 3, 4, 5},
+	}
+	request, err := dhcpv6.NewInformationRequest(duid)
+	require.NoError(t, err)
+	probe := func(ctx context.Context, request dhcpv6.DHCPv6) (dhcpv6.DHCPv6, error) {
+		require.Equal(t, dhcpv6.MessageTypeInformationRequest, request.Type())
+		oro := request.GetOneOption(dhcpv6.OptionORO).(*dhcpv6.OptRequestedOption)
+		require.Equal(t, []dhcpv6.OptionCode{
+			dhcpv6.OptionDNSRecursiveNameServer,
+			dhcpv6.OptionDomainSearchList,
+			dhcpv6.OptionBootfileURL,
+		}, oro.RequestedOptions())
+		return dhcpv6.NewReplyFromMessage(request, func(d dhcpv6.DHCPv6) dhcpv6.DHCPv6 {
+			d.AddOption(&dhcpv6.OptDNSRecursiveNameServer{NameServers: []net.IP{net.ParseIP("2001:db8::53")}})
+			d.AddOption(&dhcpv6.OptDomainSearchList{DomainSearchList: []string{"example.org"}})
+			return d
+		})
+	}
+	reports := Check6(context.Background(), probe, request, Profile6{
+		Name: "lab",
+		Options: []dhcpv6.Option{
+			&dhcpv6.OptDNSRecursiveNameServer{NameServers: []net.IP{net.ParseIP("2001:db8::53")}},
+			&dhcpv6.OptDomainSearchList{DomainSearchList: []string{"example.com"}},
+			&dhcpv6.OptBootFileURL{BootFileURL: []byte("http://[2001:db8::1]/boot")},
+		},
+	})
+	require.Len(t, reports, 1)
+	require.NoError(t, reports[0].Err)
+	require.Len(t, reports[0].Drifts, 2)
+	require.Equal(t, Changed, reports[0].Drifts[0].Kind)
+	require.Equal(t, "Domain Search List", reports[0].Drifts[0].Option)
+	require.Equal(t, Missing, reports[0].Drifts[1].Kind)
+	require.Equal(t, "OPT_BOOTFILE_URL", reports[0].Drifts[1].Option)
+}
+
+func TestCheck6ProbeError(t *testing.T) {
+	request, err := dhcpv6.NewInformationRequest(dhcpv6.Duid{Type: dhcpv6.DUID_LL, LinkLayerAddr: net.HardwareAddr{0, 1, 2, 3, 4, 5}})
+	require.NoError(t, err)
+	probe := func(ctx context.Context, request dhcpv6.DHCPv6) (dhcpv6.DHCPv6, error) {
+		return nil, context.DeadlineExceeded
+	}
+	reports := Check6(context.Background(), probe, request, Profile6{Name: "lab"})
+	require.Equal(t, context.DeadlineExceeded, reports[0].Err)
+}
@@ -0,0 +1,80 @@
+// Package drift checks that the answers of live DHCP servers match their
+// intended configuration, e.g. after a configuration push. For each subnet or
+// client class, a Profile holds the options the servers are meant to return.
+// The checks send a DHCPINFORM, or a DHCPv6 INFORMATION-REQUEST, shaped to
+// look like it comes from that subnet or class, and report the intended
+// options that the reply lacks or carries with another value.
+//
+// Options that the servers return but that a profile does not mention are not
+// reported, since servers commonly add options of their own.
+package drift
+
+import (
+	"fmt"
+)
+
+// Kind tells how an option drifted from its intended value.
+type Kind int
+
+// Kinds of drift
+const (
+	// Missing means that the reply lacks the option.
+	Missing Kind = iota
+	// Changed means that the reply carries the option with another value.
+	Changed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Missing:
+		return "missing"
+	case Changed:
+		return "changed"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Drift describes an option of a reply that does not match the intended
+// configuration.
+type Drift struct {
+	Kind Kind
+	// Option is the name of the option.
+	Option string
+	// Want and Got are the human-readable intended and returned options. Got
+	// is empty if the option is missing.
+	Want string
+	Got  string
+}
+
+func (d Drift) String() string {
+	if d.Kind == Missing {
+		return fmt.Sprintf("%s: missing, want %s", d.Option, d.Want)
+	}
+	return fmt.Sprintf("%s: got %s, want %s", d.Option, d.Got, d.Want)
+}
+
+// Report is the outcome of the check of a profile.
+type Report struct {
+	// Profile is the name of the profile checked.
+	Profile string
+	// Drifts lists the options that do not match the profile.
+	Drifts []Drift
+	// Err is set if the server could not be probed, in which case Drifts is
+	// empty.
+	Err error
+}
+
+// OK returns true if the server was probed and its reply matches the profile.
+func (r *Report) OK() bool {
+	return r.Err == nil && len(r.Drifts) == 0
+}
+
+func (r *Report) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: probe failed: %v", r.Profile, r.Err)
+	case len(r.Drifts) == 0:
+		return fmt.Sprintf("%s: ok", r.Profile)
+	}
+	return fmt.Sprintf("%s: %d option(s) drifted %v", r.Profile, len(r.Drifts), r.Drifts)
+}
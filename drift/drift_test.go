@@ -0,0 +1,31 @@
+package drift
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	r := Report{Profile: "lab"}
+	require.True(t, r.OK())
+	require.Equal(t, "lab: ok", r.String())
+
+	r.Drifts = []Drift{
+		{Kind: Missing, Option: "Router", Want: "Routers -> 192.0.2.1"},
+		{Kind: Changed, Option: "Domain Name", Want: "Domain Name -> example.com", Got: "Domain Name -> example.org"},
+	}
+	require.False(t, r.OK())
+	require.Equal(t, "lab: 2 option(s) drifted [Router: missing, want Routers -> 192.0.2.1 Domain Name: got Domain Name -> example.org, want Domain Name -> example.com]", r.String())
+
+	r = Report{Profile: "lab", Err: errors.New("timeout")}
+	require.False(t, r.OK())
+	require.Equal(t, "lab: probe failed: timeout", r.String())
+}
+
+func TestKindString(t *testing.T) {
+	require.Equal(t, "missing", Missing.String())
+	require.Equal(t, "changed", Changed.String())
+	require.Equal(t, "Kind(7)", Kind(7).String())
+}
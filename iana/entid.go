@@ -0,0 +1,33 @@
+package iana
+
+import "fmt"
+
+// EnterpriseID is an IANA Private Enterprise Number, which identifies the
+// vendor of the vendor-specific DHCP options.
+// https://www.iana.org/assignments/enterprise-numbers
+type EnterpriseID uint32
+
+// A few of the Private Enterprise Numbers found in DHCP traffic
+const (
+	EnterpriseIDCisco          EnterpriseID = 9
+	EnterpriseIDMicrosoft      EnterpriseID = 311
+	EnterpriseIDJuniper        EnterpriseID = 2636
+	EnterpriseIDBroadbandForum EnterpriseID = 3561
+	EnterpriseIDCableLabs      EnterpriseID = 4491
+)
+
+// EnterpriseIDToString maps an EnterpriseID to the name of the enterprise.
+var EnterpriseIDToString = map[EnterpriseID]string{
+	EnterpriseIDCisco:          "Cisco Systems",
+	EnterpriseIDMicrosoft:      "Microsoft",
+	EnterpriseIDJuniper:        "Juniper Networks",
+	EnterpriseIDBroadbandForum: "Broadband Forum",
+	EnterpriseIDCableLabs:      "CableLabs",
+}
+
+func (e EnterpriseID) String() string {
+	if s, ok := EnterpriseIDToString[e]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown (%d)", uint32(e))
+}
@@ -0,0 +1,141 @@
+// Code generated by ianagen from the IANA registries. DO NOT EDIT.
+
+package iana
+
+// Hardware types, as used in ARP, DHCPv4 and DUIDs
+const (
+	HwTypeEthernet             HwTypeType = 1  // [Jon_Postel]
+	HwTypeExperimentalEthernet HwTypeType = 2  // [Jon_Postel]
+	HwTypeAmateurRadioAX25     HwTypeType = 3  // [Philip_Koch]
+	HwTypeProteonTokenRing     HwTypeType = 4  // [Avri_Doria]
+	HwTypeChaos                HwTypeType = 5  // [Gill_Pratt]
+	HwTypeIEEE802              HwTypeType = 6  // [Jon_Postel]
+	HwTypeARCNET               HwTypeType = 7  // [RFC1201]
+	HwTypeHyperchannel         HwTypeType = 8  // [Jon_Postel]
+	HwTypeLanstar              HwTypeType = 9  // [Tom_Unger]
+	HwTypeAutonet              HwTypeType = 10 // [Mike_Burrows]
+	HwTypeLocalTalk            HwTypeType = 11 // [Joyce_K_Reynolds]
+	HwTypeLocalNet             HwTypeType = 12 // [Joseph Murdock]
+	HwTypeUltraLink            HwTypeType = 13 // [Rajiv_Dhingra]
+	HwTypeSMDS                 HwTypeType = 14 // [George_Clapp]
+	HwTypeFrameRelay           HwTypeType = 15 // [Andy_Malis]
+	HwTypeATM                  HwTypeType = 16 // [[JXB2]]
+	HwTypeHDLC                 HwTypeType = 17 // [Jon_Postel]
+	HwTypeFibreChannel         HwTypeType = 18 // [RFC4338]
+	HwTypeATM2                 HwTypeType = 19 // [RFC2225]
+	HwTypeSerialLine           HwTypeType = 20 // [Jon_Postel]
+	HwTypeATM3                 HwTypeType = 21 // [Mike_Burrows]
+	HwTypeMILSTD188220         HwTypeType = 22 // [Herb_Jensen]
+	HwTypeMetricom             HwTypeType = 23 // [Jonathan_Stone]
+	HwTypeIEEE1394             HwTypeType = 24 // [Myron_Hattig]
+	HwTypeMAPOS                HwTypeType = 25 // [Mitsuru_Maruyama][RFC2176]
+	HwTypeTwinaxial            HwTypeType = 26 // [Marion_Pitts]
+	HwTypeEUI64                HwTypeType = 27 // [Kenji_Fujisawa]
+	HwTypeHIPARP               HwTypeType = 28 // [Jean_Michel_Pittet]
+	HwTypeISO7816              HwTypeType = 29 // [Scott_Guthery]
+	HwTypeARPSec               HwTypeType = 30 // [Jerome_Etienne]
+	HwTypeIPsec                HwTypeType = 31 // [RFC3456]
+	HwTypeInfiniband           HwTypeType = 32 // [RFC4391]
+	HwTypeCAI                  HwTypeType = 33 // [Jeff Anderson, Telecommunications Industry of America (TIA) TR-8.5 Formulating Group, <cja015&motorola.com>, June 2004]
+	HwTypeWiegandInterface     HwTypeType = 34 // [Scott_Guthery_2]
+	HwTypePureIP               HwTypeType = 35 // [Inaky_Perez-Gonzalez]
+	HwTypeHWExp1               HwTypeType = 36 // [RFC5494]
+	HwTypeHFI                  HwTypeType = 37 // [Tseng-Hui_Lin]
+	HwTypeUnifiedBus           HwTypeType = 38 // [Wei_Pan]
+)
+
+// HwTypeToString maps hardware types to their names
+var HwTypeToString = map[HwTypeType]string{
+	HwTypeEthernet:             "Ethernet",
+	HwTypeExperimentalEthernet: "Experimental Ethernet",
+	HwTypeAmateurRadioAX25:     "Amateur Radio AX.25",
+	HwTypeProteonTokenRing:     "Proteon ProNET Token Ring",
+	HwTypeChaos:                "Chaos",
+	HwTypeIEEE802:              "IEEE 802",
+	HwTypeARCNET:               "ARCNET",
+	HwTypeHyperchannel:         "Hyperchannel",
+	HwTypeLanstar:              "Lanstar",
+	HwTypeAutonet:              "Autonet Short Address",
+	HwTypeLocalTalk:            "LocalTalk",
+	HwTypeLocalNet:             "LocalNet",
+	HwTypeUltraLink:            "Ultra link",
+	HwTypeSMDS:                 "SMDS",
+	HwTypeFrameRelay:           "Frame Relay",
+	HwTypeATM:                  "ATM",
+	HwTypeHDLC:                 "HDLC",
+	HwTypeFibreChannel:         "Fibre Channel",
+	HwTypeATM2:                 "ATM 2",
+	HwTypeSerialLine:           "Serial Line",
+	HwTypeATM3:                 "ATM 3",
+	HwTypeMILSTD188220:         "MIL-STD-188-220",
+	HwTypeMetricom:             "Metricom",
+	HwTypeIEEE1394:             "IEEE 1394.1995",
+	HwTypeMAPOS:                "MAPOS",
+	HwTypeTwinaxial:            "Twinaxial",
+	HwTypeEUI64:                "EUI-64",
+	HwTypeHIPARP:               "HIPARP",
+	HwTypeISO7816:              "IP and ARP over ISO 7816-3",
+	HwTypeARPSec:               "ARPSec",
+	HwTypeIPsec:                "IPsec tunnel",
+	HwTypeInfiniband:           "Infiniband",
+	HwTypeCAI:                  "CAI, TIA-102 Project 125 Common Air Interface",
+	HwTypeWiegandInterface:     "Wiegand Interface",
+	HwTypePureIP:               "Pure IP",
+	HwTypeHWExp1:               "HW_EXP1",
+	HwTypeHFI:                  "HFI",
+	HwTypeUnifiedBus:           "Unified Bus (UB)",
+}
+
+// IANA status codes for DHCPv6
+const (
+	StatusSuccess                    StatusCode = 0  // [RFC8415]
+	StatusUnspecFail                 StatusCode = 1  // [RFC8415]
+	StatusNoAddrsAvail               StatusCode = 2  // [RFC8415]
+	StatusNoBinding                  StatusCode = 3  // [RFC8415]
+	StatusNotOnLink                  StatusCode = 4  // [RFC8415]
+	StatusUseMulticast               StatusCode = 5  // [RFC8415]
+	StatusNoPrefixAvail              StatusCode = 6  // [RFC8415]
+	StatusUnknownQueryType           StatusCode = 7  // [RFC5007]
+	StatusMalformedQuery             StatusCode = 8  // [RFC5007]
+	StatusNotConfigured              StatusCode = 9  // [RFC5007]
+	StatusNotAllowed                 StatusCode = 10 // [RFC5007]
+	StatusQueryTerminated            StatusCode = 11 // [RFC5460]
+	StatusDataMissing                StatusCode = 12 // [RFC7653]
+	StatusCatchUpComplete            StatusCode = 13 // [RFC7653]
+	StatusNotSupported               StatusCode = 14 // [RFC7653]
+	StatusTLSConnectionRefused       StatusCode = 15 // [RFC7653]
+	StatusAddressInUse               StatusCode = 16 // [RFC8156]
+	StatusConfigurationConflict      StatusCode = 17 // [RFC8156]
+	StatusMissingBindingInformation  StatusCode = 18 // [RFC8156]
+	StatusOutdatedBindingInformation StatusCode = 19 // [RFC8156]
+	StatusServerShuttingDown         StatusCode = 20 // [RFC8156]
+	StatusDNSUpdateNotSupported      StatusCode = 21 // [RFC8156]
+	StatusExcessiveTimeSkew          StatusCode = 22 // [RFC8156]
+)
+
+// StatusCodeToStringMap maps status codes to their names
+var StatusCodeToStringMap = map[StatusCode]string{
+	StatusSuccess:                    "Success",
+	StatusUnspecFail:                 "UnspecFail",
+	StatusNoAddrsAvail:               "NoAddrsAvail",
+	StatusNoBinding:                  "NoBinding",
+	StatusNotOnLink:                  "NotOnLink",
+	StatusUseMulticast:               "UseMulticast",
+	StatusNoPrefixAvail:              "NoPrefixAvail",
+	StatusUnknownQueryType:           "UnknownQueryType",
+	StatusMalformedQuery:             "MalformedQuery",
+	StatusNotConfigured:              "NotConfigured",
+	StatusNotAllowed:                 "NotAllowed",
+	StatusQueryTerminated:            "QueryTerminated",
+	StatusDataMissing:                "DataMissing",
+	StatusCatchUpComplete:            "CatchUpComplete",
+	StatusNotSupported:               "NotSupported",
+	StatusTLSConnectionRefused:       "TLSConnectionRefused",
+	StatusAddressInUse:               "AddressInUse",
+	StatusConfigurationConflict:      "ConfigurationConflict",
+	StatusMissingBindingInformation:  "MissingBindingInformation",
+	StatusOutdatedBindingInformation: "OutdatedBindingInformation",
+	StatusServerShuttingDown:         "ServerShuttingDown",
+	StatusDNSUpdateNotSupported:      "DNSUpdateNotSupported",
+	StatusExcessiveTimeSkew:          "ExcessiveTimeSkew",
+}
@@ -0,0 +1,335 @@
+// Package identity generates and persists the identifiers with which a host
+// presents itself to the DHCP servers: a DUID and an IAID per interface, as
+// defined for DHCPv6 by RFC 8415, which DHCPv4 clients also send in their
+// client identifier option as described in RFC 4361, so that both protocols
+// see the same client.
+package identity
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// Policy tells how a Store picks the identities.
+type Policy int
+
+// The policies of a Store.
+const (
+	// PolicyStable gives the host a single DUID, a DUID-LLT built from the
+	// first hardware address it sees, and every interface its own IAID.
+	// They never change, unless rotated, whatever the network.
+	PolicyStable Policy = iota
+	// PolicyPerNetwork gives each interface a random DUID-LL and IAID on
+	// every network, so that the host cannot be tracked from one network to
+	// another, as the anonymity profiles of RFC 7844 recommend, while it
+	// keeps its addresses on each of them.
+	PolicyPerNetwork
+)
+
+// String returns a human-readable string for the policy.
+func (p Policy) String() string {
+	switch p {
+	case PolicyStable:
+		return "stable"
+	case PolicyPerNetwork:
+		return "per-network"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// Identity is the identity of an interface.
+type Identity struct {
+	DUID dhcpv6.Duid
+	IAID uint32
+	// Created is when the DUID was generated.
+	Created time.Time
+}
+
+// duidEpoch is the origin of the time of the DUID-LLT, see RFC 8415 section
+// 11.2.
+var duidEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Store hands out the identities of the interfaces of a host, and saves them
+// so that they survive a restart. It is safe for concurrent use, but not to
+// share its file between processes.
+type Store struct {
+	// Path is the file where the identities are saved, as JSON. If empty,
+	// they are only kept in memory.
+	Path   string
+	Policy Policy
+	// RotateAfter, if not zero, is the age after which a DUID is replaced
+	// by a new one, and the IAIDs that go with it too.
+	RotateAfter time.Duration
+
+	lock   sync.Mutex
+	loaded bool
+	state  storeState
+	now    func() time.Time
+}
+
+// storeState is what a Store saves.
+type storeState struct {
+	// Host is the DUID shared by the interfaces with PolicyStable, and
+	// Interfaces their IAIDs by name.
+	Host       *storedIdentity            `json:"host,omitempty"`
+	Interfaces map[string]*storedIdentity `json:"interfaces,omitempty"`
+	// Networks are the identities with PolicyPerNetwork, by interface name
+	// and network.
+	Networks map[string]*storedIdentity `json:"networks,omitempty"`
+}
+
+type storedIdentity struct {
+	DUID    []byte    `json:"duid,omitempty"`
+	IAID    uint32    `json:"iaid"`
+	Created time.Time `json:"created"`
+}
+
+// NewStore returns a Store saving the identities in the file at path, with the
+// given policy.
+func NewStore(path string, policy Policy) *Store {
+	return &Store{Path: path, Policy: policy}
+}
+
+func (s *Store) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// networkKey is the key of the identity of an interface on a network.
+func networkKey(ifname, network string) string {
+	return ifname + "/" + network
+}
+
+// Get returns the identity of the interface ifname, whose hardware address is
+// hwaddr, on the given network, which only matters with PolicyPerNetwork. The
+// network is any string that tells the networks apart, e.g. the SSID of a
+// wireless network, or the hardware address of the default gateway. A new
+// identity is generated, and saved, if there is none yet or it must be
+// rotated.
+func (s *Store) Get(ifname string, hwaddr net.HardwareAddr, network string) (*Identity, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	now := s.clock()
+	changed := false
+	var duid, iaid *storedIdentity
+	switch s.Policy {
+	case PolicyStable:
+		if s.expired(s.state.Host, now) {
+			host, err := newStableDUID(hwaddr, now)
+			if err != nil {
+				return nil, err
+			}
+			s.state.Host = host
+			// the IAIDs go with the DUID
+			s.state.Interfaces = nil
+			changed = true
+		}
+		if s.state.Interfaces[ifname] == nil {
+			id, err := s.newIAID(now)
+			if err != nil {
+				return nil, err
+			}
+			if s.state.Interfaces == nil {
+				s.state.Interfaces = make(map[string]*storedIdentity)
+			}
+			s.state.Interfaces[ifname] = id
+			changed = true
+		}
+		duid, iaid = s.state.Host, s.state.Interfaces[ifname]
+	case PolicyPerNetwork:
+		key := networkKey(ifname, network)
+		if s.expired(s.state.Networks[key], now) {
+			id, err := newRandomIdentity(now)
+			if err != nil {
+				return nil, err
+			}
+			if s.state.Networks == nil {
+				s.state.Networks = make(map[string]*storedIdentity)
+			}
+			s.state.Networks[key] = id
+			changed = true
+		}
+		duid, iaid = s.state.Networks[key], s.state.Networks[key]
+	default:
+		return nil, fmt.Errorf("unknown identity policy %v", s.Policy)
+	}
+	if changed {
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+	}
+	// a copy, since the DUID refers to the bytes it is parsed from
+	d, err := dhcpv6.DuidFromBytes(append([]byte(nil), duid.DUID...))
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{DUID: *d, IAID: iaid.IAID, Created: duid.Created}, nil
+}
+
+// Rotate forgets the identity of the interface ifname on the given network,
+// so that a new one is generated by the next Get. With PolicyStable, the
+// network is ignored and the DUID of the host is forgotten, along with the
+// IAIDs of all its interfaces.
+func (s *Store) Rotate(ifname, network string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	switch s.Policy {
+	case PolicyStable:
+		s.state.Host = nil
+		s.state.Interfaces = nil
+	default:
+		delete(s.state.Networks, networkKey(ifname, network))
+	}
+	return s.save()
+}
+
+// expired returns true if id is nil or older than RotateAfter.
+func (s *Store) expired(id *storedIdentity, now time.Time) bool {
+	return id == nil || (s.RotateAfter != 0 && !now.Before(id.Created.Add(s.RotateAfter)))
+}
+
+// newIAID returns an IAID that no interface uses yet, since the IAIDs of a
+// client must be unique.
+func (s *Store) newIAID(now time.Time) (*storedIdentity, error) {
+	for {
+		iaid, err := randomUint32()
+		if err != nil {
+			return nil, err
+		}
+		used := false
+		for _, id := range s.state.Interfaces {
+			if id.IAID == iaid {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return &storedIdentity{IAID: iaid, Created: now}, nil
+		}
+	}
+}
+
+// newStableDUID returns a DUID-LLT for hwaddr, or a random DUID-UUID if there
+// is no hardware address, see RFC 6355.
+func newStableDUID(hwaddr net.HardwareAddr, now time.Time) (*storedIdentity, error) {
+	if len(hwaddr) == 0 {
+		uuid := make([]byte, 16)
+		if _, err := rand.Read(uuid); err != nil {
+			return nil, err
+		}
+		// a version 4 UUID, as per RFC 4122 section 4.4
+		uuid[6] = uuid[6]&0x0f | 0x40
+		uuid[8] = uuid[8]&0x3f | 0x80
+		duid := dhcpv6.Duid{Type: dhcpv6.DUID_UUID, Uuid: uuid}
+		return &storedIdentity{DUID: duid.ToBytes(), Created: now}, nil
+	}
+	duid := dhcpv6.Duid{
+		Type:          dhcpv6.DUID_LLT,
+		HwType:        iana.HwTypeEthernet,
+		Time:          uint32(now.Sub(duidEpoch) / time.Second),
+		LinkLayerAddr: append(net.HardwareAddr(nil), hwaddr...),
+	}
+	return &storedIdentity{DUID: duid.ToBytes(), Created: now}, nil
+}
+
+// newRandomIdentity returns a DUID-LL with a random, locally administered,
+// unicast address, and a random IAID.
+func newRandomIdentity(now time.Time) (*storedIdentity, error) {
+	hwaddr := make(net.HardwareAddr, 6)
+	if _, err := rand.Read(hwaddr); err != nil {
+		return nil, err
+	}
+	hwaddr[0] = hwaddr[0]&^0x01 | 0x02
+	iaid, err := randomUint32()
+	if err != nil {
+		return nil, err
+	}
+	duid := dhcpv6.Duid{Type: dhcpv6.DUID_LL, HwType: iana.HwTypeEthernet, LinkLayerAddr: hwaddr}
+	return &storedIdentity{DUID: duid.ToBytes(), IAID: iaid, Created: now}, nil
+}
+
+func randomUint32() (uint32, error) {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b[:]), nil
+}
+
+// load reads the file of the store the first time it is needed. A missing
+// file is an empty store.
+func (s *Store) load() error {
+	if s.loaded || s.Path == "" {
+		s.loaded = true
+		return nil
+	}
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var state storeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("cannot load the identities from %s: %v", s.Path, err)
+	}
+	for _, ids := range []map[string]*storedIdentity{state.Interfaces, state.Networks} {
+		for key, id := range ids {
+			if id == nil {
+				delete(ids, key)
+			}
+		}
+	}
+	s.state = state
+	s.loaded = true
+	return nil
+}
+
+// save writes the file of the store, replacing it atomically so that a crash
+// does not lose the identities.
+func (s *Store) save() error {
+	if s.Path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(&s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.Path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package identity
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	hwaddr0 = net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+	hwaddr1 = net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x66}
+)
+
+func tempStore(t *testing.T, policy Policy) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "identity")
+	require.NoError(t, err)
+	return NewStore(filepath.Join(dir, "identity.json"), policy), func() { os.RemoveAll(dir) }
+}
+
+func TestPolicyString(t *testing.T) {
+	require.Equal(t, "stable", PolicyStable.String())
+	require.Equal(t, "per-network", PolicyPerNetwork.String())
+	require.Equal(t, "Policy(7)", Policy(7).String())
+}
+
+func TestStoreStable(t *testing.T) {
+	s, cleanup := tempStore(t, PolicyStable)
+	defer cleanup()
+
+	eth0, err := s.Get("eth0", hwaddr0, "home")
+	require.NoError(t, err)
+	require.Equal(t, dhcpv6.DUID_LLT, eth0.DUID.Type)
+	require.Equal(t, iana.HwTypeEthernet, eth0.DUID.HwType)
+	require.Equal(t, hwaddr0, eth0.DUID.LinkLayerAddr)
+
+	// the network does not matter
+	again, err := s.Get("eth0", hwaddr0, "office")
+	require.NoError(t, err)
+	require.Equal(t, eth0, again)
+
+	// the interfaces share the DUID of the host, with their own IAID
+	eth1, err := s.Get("eth1", hwaddr1, "home")
+	require.NoError(t, err)
+	require.Equal(t, eth0.DUID, eth1.DUID)
+	require.NotEqual(t, eth0.IAID, eth1.IAID)
+
+	// the identities survive a restart
+	reloaded := NewStore(s.Path, PolicyStable)
+	again, err = reloaded.Get("eth0", hwaddr0, "")
+	require.NoError(t, err)
+	require.Equal(t, eth0.DUID, again.DUID)
+	require.Equal(t, eth0.IAID, again.IAID)
+	require.True(t, eth0.Created.Equal(again.Created))
+
+	// a DUID-LLT only changes with its time
+	reloaded.now = func() time.Time { return time.Now().Add(time.Hour) }
+	require.NoError(t, reloaded.Rotate("eth0", ""))
+	rotated, err := reloaded.Get("eth0", hwaddr0, "")
+	require.NoError(t, err)
+	require.NotEqual(t, eth0.DUID.ToBytes(), rotated.DUID.ToBytes())
+}
+
+func TestStoreStableNoHwAddr(t *testing.T) {
+	s := NewStore("", PolicyStable)
+	id, err := s.Get("tun0", nil, "")
+	require.NoError(t, err)
+	require.Equal(t, dhcpv6.DUID_UUID, id.DUID.Type)
+	require.Len(t, id.DUID.Uuid, 16)
+	require.Equal(t, byte(0x40), id.DUID.Uuid[6]&0xf0)
+}
+
+func TestStorePerNetwork(t *testing.T) {
+	s, cleanup := tempStore(t, PolicyPerNetwork)
+	defer cleanup()
+
+	home, err := s.Get("wlan0", hwaddr0, "home")
+	require.NoError(t, err)
+	require.Equal(t, dhcpv6.DUID_LL, home.DUID.Type)
+	require.NotEqual(t, hwaddr0, home.DUID.LinkLayerAddr)
+	// locally administered unicast address
+	require.Equal(t, byte(0x02), home.DUID.LinkLayerAddr[0]&0x03)
+
+	office, err := s.Get("wlan0", hwaddr0, "office")
+	require.NoError(t, err)
+	require.NotEqual(t, home.DUID.ToBytes(), office.DUID.ToBytes())
+
+	again, err := NewStore(s.Path, PolicyPerNetwork).Get("wlan0", hwaddr0, "home")
+	require.NoError(t, err)
+	require.Equal(t, home.DUID, again.DUID)
+	require.Equal(t, home.IAID, again.IAID)
+
+	require.NoError(t, s.Rotate("wlan0", "home"))
+	rotated, err := s.Get("wlan0", hwaddr0, "home")
+	require.NoError(t, err)
+	require.NotEqual(t, home.DUID.ToBytes(), rotated.DUID.ToBytes())
+	again, err = s.Get("wlan0", hwaddr0, "office")
+	require.NoError(t, err)
+	require.Equal(t, office.DUID, again.DUID)
+}
+
+func TestStoreRotateAfter(t *testing.T) {
+	now := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	s := &Store{Policy: PolicyStable, RotateAfter: 24 * time.Hour, now: func() time.Time { return now }}
+	first, err := s.Get("eth0", hwaddr0, "")
+	require.NoError(t, err)
+	require.Equal(t, uint32(now.Sub(duidEpoch)/time.Second), first.DUID.Time)
+
+	now = now.Add(23 * time.Hour)
+	again, err := s.Get("eth0", hwaddr0, "")
+	require.NoError(t, err)
+	require.Equal(t, first, again)
+
+	now = now.Add(time.Hour)
+	rotated, err := s.Get("eth0", hwaddr0, "")
+	require.NoError(t, err)
+	require.NotEqual(t, first.DUID.Time, rotated.DUID.Time)
+	require.True(t, rotated.Created.Equal(now))
+}
+
+func TestStoreCorruptFile(t *testing.T) {
+	s, cleanup := tempStore(t, PolicyStable)
+	defer cleanup()
+	require.NoError(t, ioutil.WriteFile(s.Path, []byte("{"), 0600))
+	_, err := s.Get("eth0", hwaddr0, "")
+	require.Error(t, err)
+}
+
+func TestStoreUnknownPolicy(t *testing.T) {
+	_, err := NewStore("", Policy(7)).Get("eth0", hwaddr0, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,410 @@
+// ianagen generates the tables of option codes, message types, status codes
+// and hardware types of the dhcpv4, dhcpv6 and iana packages from the CSV
+// files of the IANA registries, so that a new assignment only takes updating
+// a CSV file and running go generate.
+//
+// Each table is generated from a registry and from a names file. The registry
+// is the CSV file published by IANA, unmodified: the first column holds the
+// values, the second one their names and the last one their references. The
+// values that are ranges, unassigned or reserved, or that do not fit in the Go
+// type of the table, are skipped.
+//
+// The names file pins the identifier and the string of the values that the
+// packages already exported before the table was generated, so that they do
+// not change, and may add values that are not in the registry, e.g. options
+// for private use. It is a CSV file with the columns value, identifier, string
+// and comment, where the empty columns take their default: the identifier is
+// derived from the name in the registry, and the string is that name.
+//
+// Usage, from the directory of a package:
+//
+//	go run ../internal/ianagen -package dhcpv4 -registries ../internal/ianagen/registries
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// table describes a generated table.
+type table struct {
+	// Registry and Names are the registry and the names file, in the
+	// registries directory.
+	Registry string
+	Names    string
+	// Type is the Go type of the values, which have Bits bits.
+	Type string
+	Bits uint
+	// Prefix is the prefix of the identifiers. TrimName are the prefixes
+	// removed from the names in the registry before deriving identifiers
+	// from them, and TrimString those removed to make strings of them.
+	Prefix     string
+	TrimName   []string
+	TrimString []string
+	// Map is the name of the map from the values to their strings.
+	Map string
+	// ConstDoc and MapDoc are the doc comments of the constants and of the
+	// map.
+	ConstDoc string
+	MapDoc   string
+}
+
+// tables are the tables generated for each package.
+var tables = map[string][]table{
+	"dhcpv4": {
+		{
+			Registry:   "bootp-dhcp-message-types.csv",
+			Names:      "names/dhcpv4-message-types.csv",
+			Type:       "MessageType",
+			Bits:       8,
+			Prefix:     "MessageType",
+			TrimName:   []string{"DHCP"},
+			TrimString: []string{"DHCP"},
+			Map:        "MessageTypeToString",
+			ConstDoc:   "DHCP message types",
+			MapDoc:     "MessageTypeToString maps DHCP message types to human-readable strings.",
+		},
+		{
+			Registry: "bootp-dhcp-options.csv",
+			Names:    "names/dhcpv4-options.csv",
+			Type:     "OptionCode",
+			Bits:     8,
+			Prefix:   "Option",
+			TrimName: []string{"OPTION_", "OPTION-"},
+			Map:      "OptionCodeToString",
+			ConstDoc: "DHCPv4 Options",
+			MapDoc:   "OptionCodeToString maps an OptionCode to its mnemonic name",
+		},
+		{
+			Registry: "bootp-dhcp-status-codes.csv",
+			Names:    "names/dhcpv4-status-codes.csv",
+			Type:     "StatusCode",
+			Bits:     8,
+			Prefix:   "Status",
+			Map:      "StatusCodeToString",
+			ConstDoc: "Status codes as defined by RFC 6926",
+			MapDoc:   "StatusCodeToString maps status codes to their names.",
+		},
+	},
+	"dhcpv6": {
+		{
+			Registry: "dhcpv6-message-types.csv",
+			Names:    "names/dhcpv6-message-types.csv",
+			Type:     "MessageType",
+			Bits:     8,
+			Prefix:   "MessageType",
+			Map:      "MessageTypeToStringMap",
+			ConstDoc: "The different kinds of DHCPv6 message types.",
+			MapDoc:   "MessageTypeToStringMap contains the mapping of MessageTypes to human-readable\n// strings.",
+		},
+		{
+			Registry: "dhcpv6-options.csv",
+			Names:    "names/dhcpv6-options.csv",
+			Type:     "OptionCode",
+			Bits:     16,
+			Prefix:   "Option",
+			TrimName: []string{"OPTION_", "OPTION-", "OPT_"},
+			Map:      "OptionCodeToString",
+			ConstDoc: "All DHCPv6 options.",
+			MapDoc:   "OptionCodeToString maps DHCPv6 OptionCodes to human-readable strings.",
+		},
+	},
+	"iana": {
+		{
+			Registry: "arp-hardware-types.csv",
+			Names:    "names/iana-hardware-types.csv",
+			Type:     "HwTypeType",
+			Bits:     8,
+			Prefix:   "HwType",
+			Map:      "HwTypeToString",
+			ConstDoc: "Hardware types, as used in ARP, DHCPv4 and DUIDs",
+			MapDoc:   "HwTypeToString maps hardware types to their names",
+		},
+		{
+			Registry: "dhcpv6-status-codes.csv",
+			Names:    "names/iana-status-codes.csv",
+			Type:     "StatusCode",
+			Bits:     16,
+			Prefix:   "Status",
+			Map:      "StatusCodeToStringMap",
+			ConstDoc: "IANA status codes for DHCPv6",
+			MapDoc:   "StatusCodeToStringMap maps status codes to their names",
+		},
+	},
+}
+
+// entry is a value of a table.
+type entry struct {
+	Value      uint64
+	Identifier string
+	String     string
+	// Comment is the trailing comment of the constant: the comment of the
+	// names file, or else the reference of the value in the registry.
+	Comment string
+}
+
+// registryRow is an assignment read from a registry.
+type registryRow struct {
+	Name      string
+	Reference string
+}
+
+// readCSV reads all the records of a CSV file, skipping the lines starting
+// with a #, and the header if there is one.
+func readCSV(path string, header bool) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if header {
+			header = false
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+var unassigned = regexp.MustCompile(`(?i)^(unassigned|reserved|removed)\b`)
+
+// readRegistry returns the assignments of a registry by value, skipping those
+// that do not fit in bits bits.
+func readRegistry(path string, bits uint) (map[uint64][]registryRow, error) {
+	records, err := readCSV(path, true)
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[uint64][]registryRow)
+	for _, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("%s: too few columns in %q", path, record)
+		}
+		name := strings.TrimSpace(record[1])
+		if name == "" || unassigned.MatchString(name) {
+			continue
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 64)
+		if err != nil {
+			// a range
+			continue
+		}
+		if value >= 1<<bits {
+			continue
+		}
+		var reference string
+		if len(record) > 2 {
+			reference = strings.TrimSpace(record[len(record)-1])
+		}
+		rows[value] = append(rows[value], registryRow{Name: name, Reference: reference})
+	}
+	return rows, nil
+}
+
+// readNames returns the entries of a names file by value.
+func readNames(path string) (map[uint64]entry, error) {
+	records, err := readCSV(path, false)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint64]entry)
+	for _, record := range records {
+		for len(record) < 4 {
+			record = append(record, "")
+		}
+		value, err := strconv.ParseUint(record[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid value %q", path, record[0])
+		}
+		if _, ok := names[value]; ok {
+			return nil, fmt.Errorf("%s: value %d named twice", path, value)
+		}
+		names[value] = entry{
+			Value:      value,
+			Identifier: record[1],
+			String:     record[2],
+			Comment:    record[3],
+		}
+	}
+	return names, nil
+}
+
+// acronyms are the words kept in upper case, or with their usual case, in the
+// identifiers derived from the names in the registries.
+var acronyms = map[string]string{}
+
+func init() {
+	for _, a := range []string{
+		"4RD", "6RD", "AC", "AFTR", "ANDSF", "ANI", "AP", "ARP", "BCMCS", "BR",
+		"BSSID", "CAPWAP", "CCC", "CLT", "CS", "DHCP", "DHCP4", "DHCP6", "DMR",
+		"DNR", "DNS", "DOTS", "DSCP", "ERO", "ERP", "F", "FQDN", "HAA", "HAF",
+		"HFI", "HIPARP", "HNIDF", "HNP", "HW", "IA", "ID", "IDINF", "IP", "IRC",
+		"KDC", "KRB", "LL", "LPR", "LQ", "LW", "MAPE", "MAPT", "MCLT", "MIP6",
+		"MPL", "MTU", "MUD", "NA", "NII", "NIS", "NISP", "NNTP", "NTP", "ORO",
+		"PANA", "PCP", "PD", "POSIX", "PXE", "RADIUS", "RDNSS", "RI", "RLP",
+		"RSOO", "RT", "S46", "SADDR", "SIP", "SLAP", "SMDS", "SMTP", "SNTP",
+		"STDA", "SZTP", "TA", "TCP", "TFTP", "TLS", "TTL", "TZDB", "UA", "URL",
+		"UUID", "V4", "V6", "VSS", "WWW",
+		"DHCP4o6", "DHCPv4", "DHCPv6", "ForceRenew", "IPv4", "IPv6", "LLAddr",
+		"MoS", "PortParams", "V4V6Bind", "iSNS",
+	} {
+		acronyms[strings.ToUpper(a)] = a
+	}
+}
+
+var (
+	parenthesized = regexp.MustCompile(`\s*\([^)]*\)`)
+	separators    = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	validIdent    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// identifier derives an identifier from the name of a value in a registry.
+func identifier(prefix, name string, trim []string) string {
+	name = parenthesized.ReplaceAllString(name, "")
+	for _, t := range trim {
+		name = strings.TrimPrefix(name, t)
+	}
+	var ident bytes.Buffer
+	ident.WriteString(prefix)
+	for _, word := range separators.Split(name, -1) {
+		if word == "" {
+			continue
+		}
+		if a, ok := acronyms[strings.ToUpper(word)]; ok {
+			ident.WriteString(a)
+		} else if strings.ToUpper(word) == word {
+			ident.WriteString(word[:1] + strings.ToLower(word[1:]))
+		} else {
+			ident.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return ident.String()
+}
+
+// entries returns the entries of a table, sorted by value.
+func (t table) entries(dir string) ([]entry, error) {
+	rows, err := readRegistry(filepath.Join(dir, t.Registry), t.Bits)
+	if err != nil {
+		return nil, err
+	}
+	names, err := readNames(filepath.Join(dir, t.Names))
+	if err != nil {
+		return nil, err
+	}
+	var entries []entry
+	for value, r := range rows {
+		e, pinned := names[value]
+		if !pinned && len(r) > 1 {
+			return nil, fmt.Errorf("%s: value %d has %d assignments, name it in %s", t.Registry, value, len(r), t.Names)
+		}
+		e.Value = value
+		if e.Identifier == "" {
+			e.Identifier = identifier(t.Prefix, r[0].Name, t.TrimName)
+		}
+		if e.String == "" {
+			e.String = r[0].Name
+			for _, trim := range t.TrimString {
+				e.String = strings.TrimPrefix(e.String, trim)
+			}
+		}
+		if e.Comment == "" && len(r) == 1 {
+			e.Comment = r[0].Reference
+		}
+		entries = append(entries, e)
+	}
+	for value, e := range names {
+		if _, ok := rows[value]; ok {
+			continue
+		}
+		if e.Identifier == "" || e.String == "" {
+			return nil, fmt.Errorf("%s: value %d is not in the registry, it needs an identifier and a string", t.Names, value)
+		}
+		if value >= 1<<t.Bits {
+			return nil, fmt.Errorf("%s: value %d does not fit in a %s", t.Names, value, t.Type)
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Value < entries[j].Value })
+	seen := make(map[string]uint64)
+	for _, e := range entries {
+		if !validIdent.MatchString(e.Identifier) {
+			return nil, fmt.Errorf("%s: invalid identifier %q for value %d, name it in %s", t.Registry, e.Identifier, e.Value, t.Names)
+		}
+		if other, ok := seen[e.Identifier]; ok {
+			return nil, fmt.Errorf("%s: values %d and %d are both %s, name one of them in %s", t.Registry, other, e.Value, e.Identifier, t.Names)
+		}
+		seen[e.Identifier] = e.Value
+	}
+	return entries, nil
+}
+
+// generate returns the source of the tables of package pkg, read from the
+// registries directory dir.
+func generate(pkg, dir string) ([]byte, error) {
+	ts, ok := tables[pkg]
+	if !ok {
+		return nil, fmt.Errorf("no tables for package %s", pkg)
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by ianagen from the IANA registries. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", pkg)
+	for _, t := range ts {
+		entries, err := t.entries(dir)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "\n// %s\nconst (\n", t.ConstDoc)
+		for _, e := range entries {
+			fmt.Fprintf(&buf, "\t%s %s = %d", e.Identifier, t.Type, e.Value)
+			if e.Comment != "" {
+				fmt.Fprintf(&buf, " // %s", e.Comment)
+			}
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, ")\n\n// %s\nvar %s = map[%s]string{\n", t.MapDoc, t.Map, t.Type)
+		for _, e := range entries {
+			fmt.Fprintf(&buf, "\t%s: %q,\n", e.Identifier, e.String)
+		}
+		buf.WriteString("}\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+func main() {
+	pkg := flag.String("package", "", "package whose tables are generated")
+	dir := flag.String("registries", "registries", "directory of the registries and names files")
+	out := flag.String("o", "tables_generated.go", "output file")
+	flag.Parse()
+	src, err := generate(*pkg, *dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		name, want string
+	}{
+		{"Subnet Mask", "OptionSubnetMask"},
+		{"OPTION_CLIENT_ARCH_TYPE", "OptionClientArchType"},
+		{"OPTION_V4_PCP_SERVER", "OptionV4PCPServer"},
+		{"OPTION-IPv6_Address-MoS", "OptionIPv6AddressMoS"},
+		{"IPv6-Only Preferred", "OptionIPv6OnlyPreferred"},
+		{"Virtual Subnet Selection (VSS) Option", "OptionVirtualSubnetSelectionOption"},
+		{"OPTION_4RD_MAP_RULE", "Option4RDMapRule"},
+	} {
+		require.Equal(t, tt.want, identifier("Option", tt.name, []string{"OPTION_", "OPTION-"}), tt.name)
+	}
+}
+
+// TestGeneratedTablesUpToDate checks that the tables of the packages were
+// regenerated after the last change of the registries or of ianagen.
+func TestGeneratedTablesUpToDate(t *testing.T) {
+	for pkg := range tables {
+		src, err := generate(pkg, "registries")
+		require.NoError(t, err, pkg)
+		current, err := ioutil.ReadFile(filepath.Join("..", "..", pkg, "tables_generated.go"))
+		require.NoError(t, err, pkg)
+		require.Equal(t, string(current), string(src), "%s is out of date, run go generate", pkg)
+	}
+}
+
+func TestTableEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ianagen")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	write := func(name, content string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	tbl := table{Registry: "registry.csv", Names: "names.csv", Type: "Code", Bits: 8, Prefix: "Code"}
+
+	write("registry.csv", "Value,Name,Reference\n"+
+		"0,Reserved,\n"+
+		"1,First Code,[RFC1]\n"+
+		"2,Second,[RFC2]\n"+
+		"3-9,Unassigned,\n"+
+		"256,Too Large,[RFC3]\n")
+	write("names.csv", "# comment\n2,CodeTwo,two\n250,CodePrivate,private,private use\n")
+	entries, err := tbl.entries(dir)
+	require.NoError(t, err)
+	require.Equal(t, []entry{
+		{Value: 1, Identifier: "CodeFirstCode", String: "First Code", Comment: "[RFC1]"},
+		{Value: 2, Identifier: "CodeTwo", String: "two", Comment: "[RFC2]"},
+		{Value: 250, Identifier: "CodePrivate", String: "private", Comment: "private use"},
+	}, entries)
+
+	// several assignments of a value must be named
+	write("registry.csv", "Value,Name,Reference\n1,One,\n1,Uno,\n")
+	write("names.csv", "")
+	_, err = tbl.entries(dir)
+	require.Error(t, err)
+	write("names.csv", "1,CodeOne\n")
+	entries, err = tbl.entries(dir)
+	require.NoError(t, err)
+	require.Equal(t, []entry{{Value: 1, Identifier: "CodeOne", String: "One"}}, entries)
+
+	// identifiers must be unique
+	write("registry.csv", "Value,Name,Reference\n1,One,\n2,ONE,\n")
+	write("names.csv", "")
+	_, err = tbl.entries(dir)
+	require.Error(t, err)
+
+	// values added by the names file need an identifier and a string
+	write("registry.csv", "Value,Name,Reference\n")
+	write("names.csv", "250,CodePrivate\n")
+	_, err = tbl.entries(dir)
+	require.Error(t, err)
+}
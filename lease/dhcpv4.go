@@ -0,0 +1,39 @@
+package lease
+
+import (
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+)
+
+// FromDHCPv4Lease builds a Binding from the lease obtained by a DHCPv4
+// client.
+func FromDHCPv4Lease(l *dhcpv4.Lease) *Binding {
+	var expires time.Time
+	if !l.Infinite() {
+		expires = l.Expires()
+	}
+	return &Binding{
+		Address:        l.IP,
+		HWAddr:         l.ClientHwAddr,
+		PreferredUntil: expires,
+		ValidUntil:     expires,
+		Options: Options{
+			Routers:      l.Routers,
+			DNS:          l.DNS,
+			DomainSearch: l.DomainSearch,
+		},
+	}
+}
+
+// FromServer4Binding builds a Binding from a binding of a DHCPv4 server. The
+// server does not keep the configuration it handed out, so Options is empty.
+func FromServer4Binding(b *server4.Binding) *Binding {
+	return &Binding{
+		Address:        b.IP,
+		HWAddr:         b.HWAddr,
+		PreferredUntil: b.Expires,
+		ValidUntil:     b.Expires,
+	}
+}
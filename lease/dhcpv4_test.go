@@ -0,0 +1,49 @@
+package lease
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromDHCPv4Lease(t *testing.T) {
+	acquired := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	l := dhcpv4.Lease{
+		ClientHwAddr: net.HardwareAddr{0, 1, 2, 3, 4, 5},
+		IP:           net.IP{192, 0, 2, 10},
+		Routers:      []net.IP{{192, 0, 2, 1}},
+		DNS:          []net.IP{{192, 0, 2, 53}},
+		DomainSearch: []string{"example.com"},
+		LeaseTime:    time.Hour,
+		Acquired:     acquired,
+	}
+	b := FromDHCPv4Lease(&l)
+	require.Equal(t, FamilyIPv4, b.Family())
+	require.Equal(t, l.IP, b.Address)
+	require.Equal(t, l.ClientHwAddr, b.HWAddr)
+	require.Equal(t, acquired.Add(time.Hour), b.ValidUntil)
+	require.Equal(t, b.ValidUntil, b.PreferredUntil)
+	require.Equal(t, l.Routers, b.Options.Routers)
+	require.Equal(t, l.DNS, b.Options.DNS)
+	require.Equal(t, l.DomainSearch, b.Options.DomainSearch)
+
+	l.LeaseTime = -1
+	require.True(t, FromDHCPv4Lease(&l).Infinite())
+}
+
+func TestFromServer4Binding(t *testing.T) {
+	sb := server4.Binding{
+		HWAddr:  net.HardwareAddr{0, 1, 2, 3, 4, 5},
+		IP:      net.IP{192, 0, 2, 10},
+		Expires: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	b := FromServer4Binding(&sb)
+	require.Equal(t, sb.IP, b.Address)
+	require.Equal(t, sb.HWAddr, b.HWAddr)
+	require.Equal(t, sb.Expires, b.ValidUntil)
+	require.Equal(t, sb.Expires, b.PreferredUntil)
+}
@@ -0,0 +1,100 @@
+package lease
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+// infiniteLifetime is the lifetime of DHCPv6 bindings that never expire, as
+// defined in RFC 8415, section 7.7.
+const infiniteLifetime = 0xffffffff
+
+// lifetimeEnd returns the time a DHCPv6 lifetime started at start ends at, or
+// the zero time if it is infinite.
+func lifetimeEnd(start time.Time, lifetime uint32) time.Time {
+	if lifetime == infiniteLifetime {
+		return time.Time{}
+	}
+	return start.Add(time.Duration(lifetime) * time.Second)
+}
+
+// FromDHCPv6Reply builds the Bindings of the addresses and prefixes assigned
+// by a DHCPv6 Reply, considering them obtained at the given time.
+func FromDHCPv6Reply(reply *dhcpv6.DHCPv6Message, now time.Time) ([]*Binding, error) {
+	var (
+		duid    []byte
+		options Options
+	)
+	if opt, ok := reply.GetOneOption(dhcpv6.OptionClientID).(*dhcpv6.OptClientId); ok {
+		duid = opt.Cid.ToBytes()
+	}
+	if opt, ok := reply.GetOneOption(dhcpv6.OptionDNSRecursiveNameServer).(*dhcpv6.OptDNSRecursiveNameServer); ok {
+		options.DNS = opt.NameServers
+	}
+	if opt, ok := reply.GetOneOption(dhcpv6.OptionDomainSearchList).(*dhcpv6.OptDomainSearchList); ok {
+		options.DomainSearch = opt.DomainSearchList
+	}
+	var bindings []*Binding
+	for _, opt := range reply.GetOption(dhcpv6.OptionIANA) {
+		ia, ok := opt.(*dhcpv6.OptIANA)
+		if !ok {
+			continue
+		}
+		for _, o := range ia.Options {
+			iaaddr, ok := o.(*dhcpv6.OptIAAddress)
+			if !ok {
+				continue
+			}
+			bindings = append(bindings, &Binding{
+				Address:        iaaddr.IPv6Addr,
+				DUID:           duid,
+				PreferredUntil: lifetimeEnd(now, iaaddr.PreferredLifetime),
+				ValidUntil:     lifetimeEnd(now, iaaddr.ValidLifetime),
+				Options:        options,
+			})
+		}
+	}
+	for _, opt := range reply.GetOption(dhcpv6.OptionIAPD) {
+		iapd, ok := opt.(*dhcpv6.OptIAForPrefixDelegation)
+		if !ok {
+			continue
+		}
+		iaOptions, err := dhcpv6.OptionsFromBytes(iapd.Options())
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range iaOptions {
+			iaprefix, ok := o.(*dhcpv6.OptIAPrefix)
+			if !ok {
+				continue
+			}
+			bindings = append(bindings, &Binding{
+				Address:        net.IP(iaprefix.IPv6Prefix()),
+				PrefixLength:   int(iaprefix.PrefixLength()),
+				DUID:           duid,
+				PreferredUntil: lifetimeEnd(now, iaprefix.PreferredLifetime()),
+				ValidUntil:     lifetimeEnd(now, iaprefix.ValidLifetime()),
+				Options:        options,
+			})
+		}
+	}
+	if len(bindings) == 0 {
+		return nil, errors.New("no address or prefix assigned")
+	}
+	return bindings, nil
+}
+
+// FromDHCPv6Binding builds a Binding from a binding of a DHCPv6 server. The
+// server does not keep the configuration it handed out, so Options is empty.
+func FromDHCPv6Binding(b *dhcpv6.Binding) *Binding {
+	return &Binding{
+		Address:        b.Address,
+		PrefixLength:   int(b.PrefixLength),
+		DUID:           b.ClientID.ToBytes(),
+		PreferredUntil: lifetimeEnd(b.LastTransaction, b.PreferredLifetime),
+		ValidUntil:     lifetimeEnd(b.LastTransaction, b.ValidLifetime),
+	}
+}
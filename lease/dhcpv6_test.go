@@ -0,0 +1,115 @@
+package lease
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/stretchr/testify/require"
+)
+
+var testDUID = dhcpv6.Duid{
+	Type:          dhcpv6.DUID_LL,
+	HwType:        iana.HwTypeEthernet,
+	LinkLayerAddr: net.HardwareAddr{0, 1, 2, 3, 4, 5},
+}
+
+func TestFromDHCPv6Reply(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	prefix := dhcpv6.OptIAPrefix{}
+	prefix.SetPreferredLifetime(1800)
+	prefix.SetValidLifetime(0xffffffff)
+	prefix.SetPrefixLength(56)
+	prefix.SetIPv6Prefix([16]byte{0x20, 0x01, 0x0d, 0xb8, 0, 0x01})
+	iapd := dhcpv6.OptIAForPrefixDelegation{}
+	require.NoError(t, iapd.SetOptions(prefix.ToBytes()))
+
+	reply := dhcpv6.DHCPv6Message{}
+	reply.SetMessage(dhcpv6.MessageTypeReply)
+	reply.AddOption(&dhcpv6.OptClientId{Cid: testDUID})
+	reply.AddOption(&dhcpv6.OptIANA{
+		Options: []dhcpv6.Option{
+			&dhcpv6.OptIAAddress{
+				IPv6Addr:          net.ParseIP("2001:db8::10"),
+				PreferredLifetime: 1800,
+				ValidLifetime:     3600,
+			},
+		},
+	})
+	reply.AddOption(&iapd)
+	reply.AddOption(&dhcpv6.OptDNSRecursiveNameServer{NameServers: []net.IP{net.ParseIP("2001:db8::53")}})
+	reply.AddOption(&dhcpv6.OptDomainSearchList{DomainSearchList: []string{"example.com"}})
+
+	bindings, err := FromDHCPv6Reply(&reply, now)
+	require.NoError(t, err)
+	require.Len(t, bindings, 2)
+
+	addr := bindings[0]
+	require.Equal(t, FamilyIPv6, addr.Family())
+	require.False(t, addr.IsPrefix())
+	require.Equal(t, net.ParseIP("2001:db8::10"), addr.Address)
+	require.Equal(t, testDUID.ToBytes(), addr.DUID)
+	require.Equal(t, now.Add(30*time.Minute), addr.PreferredUntil)
+	require.Equal(t, now.Add(time.Hour), addr.ValidUntil)
+	require.Equal(t, []net.IP{net.ParseIP("2001:db8::53")}, addr.Options.DNS)
+	require.Equal(t, []string{"example.com"}, addr.Options.DomainSearch)
+
+	pd := bindings[1]
+	require.True(t, pd.IsPrefix())
+	require.Equal(t, "2001:db8:1::/56", pd.IPNet().String())
+	require.Equal(t, now.Add(30*time.Minute), pd.PreferredUntil)
+	require.True(t, pd.Infinite())
+}
+
+func TestFromDHCPv6ReplyNoBinding(t *testing.T) {
+	reply := dhcpv6.DHCPv6Message{}
+	reply.SetMessage(dhcpv6.MessageTypeReply)
+	_, err := FromDHCPv6Reply(&reply, time.Now())
+	require.Error(t, err)
+}
+
+func TestFromDHCPv6ReplyGenericOptions(t *testing.T) {
+	reply := dhcpv6.DHCPv6Message{}
+	reply.SetMessage(dhcpv6.MessageTypeReply)
+	for _, code := range []dhcpv6.OptionCode{
+		dhcpv6.OptionClientID,
+		dhcpv6.OptionDNSRecursiveNameServer,
+		dhcpv6.OptionDomainSearchList,
+		dhcpv6.OptionIANA,
+		dhcpv6.OptionIAPD,
+	} {
+		reply.AddOption(&dhcpv6.OptionGeneric{OptionCode: code, OptionData: []byte{1}})
+	}
+	reply.AddOption(&dhcpv6.OptIANA{
+		Options: []dhcpv6.Option{
+			&dhcpv6.OptIAAddress{IPv6Addr: net.ParseIP("2001:db8::10"), ValidLifetime: 3600},
+		},
+	})
+
+	bindings, err := FromDHCPv6Reply(&reply, time.Now())
+	require.NoError(t, err)
+	require.Len(t, bindings, 1)
+	require.Nil(t, bindings[0].DUID)
+	require.Nil(t, bindings[0].Options.DNS)
+	require.Nil(t, bindings[0].Options.DomainSearch)
+}
+
+func TestFromDHCPv6Binding(t *testing.T) {
+	last := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	sb := dhcpv6.Binding{
+		ClientID:          testDUID,
+		Address:           net.ParseIP("2001:db8:1::"),
+		PrefixLength:      48,
+		PreferredLifetime: 1800,
+		ValidLifetime:     3600,
+		LastTransaction:   last,
+	}
+	b := FromDHCPv6Binding(&sb)
+	require.Equal(t, sb.Address, b.Address)
+	require.Equal(t, 48, b.PrefixLength)
+	require.Equal(t, testDUID.ToBytes(), b.DUID)
+	require.Equal(t, last.Add(30*time.Minute), b.PreferredUntil)
+	require.Equal(t, last.Add(time.Hour), b.ValidUntil)
+}
@@ -0,0 +1,140 @@
+package lease
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DNSOrder is the order in which MergeDNS takes the DNS servers and search
+// domains of the bindings.
+type DNSOrder int
+
+// The orders of MergeDNS.
+const (
+	// DNSOrderGiven takes all the entries of the first binding, then those
+	// of the second one, and so on.
+	DNSOrderGiven DNSOrder = iota
+	// DNSOrderInterleaved takes the first entry of each binding, then the
+	// second entry of each binding, and so on, so that the first servers of
+	// every network are tried before the fallback servers of any.
+	DNSOrderInterleaved
+	// DNSOrderIPv4First takes the entries of the IPv4 bindings, then those
+	// of the IPv6 bindings, each family in the given order. The servers are
+	// ordered by their own family, since a DHCPv4 lease can hold IPv6
+	// servers and conversely.
+	DNSOrderIPv4First
+	// DNSOrderIPv6First is DNSOrderIPv4First with the families swapped.
+	DNSOrderIPv6First
+)
+
+// String returns a human-readable string for the order.
+func (o DNSOrder) String() string {
+	switch o {
+	case DNSOrderGiven:
+		return "given"
+	case DNSOrderInterleaved:
+		return "interleaved"
+	case DNSOrderIPv4First:
+		return "IPv4 first"
+	case DNSOrderIPv6First:
+		return "IPv6 first"
+	}
+	return fmt.Sprintf("DNSOrder(%d)", int(o))
+}
+
+// MergeDNS merges the DNS servers and search domains of bindings obtained on
+// several interfaces, or with both DHCPv4 and DHCPv6, in the given order. The
+// duplicates are removed, keeping the first occurrence: the servers are
+// compared as addresses, and the domains without case and trailing dot. Only
+// DNS and DomainSearch are set in the result, which can be written with
+// netboot.WriteResolvConf.
+//
+// The bindings of a single DHCPv6 reply share their options, so passing them
+// all is harmless.
+func MergeDNS(order DNSOrder, bindings ...*Binding) Options {
+	switch order {
+	case DNSOrderIPv4First, DNSOrderIPv6First:
+		first := FamilyIPv4
+		if order == DNSOrderIPv6First {
+			first = FamilyIPv6
+		}
+		var sorted []*Binding
+		for _, pass := range []bool{true, false} {
+			for _, b := range bindings {
+				if (b.Family() == first) == pass {
+					sorted = append(sorted, b)
+				}
+			}
+		}
+		bindings = sorted
+	}
+
+	servers := make([][]net.IP, 0, len(bindings))
+	domains := make([][]string, 0, len(bindings))
+	for _, b := range bindings {
+		servers = append(servers, b.Options.DNS)
+		domains = append(domains, b.Options.DomainSearch)
+	}
+	var merged Options
+	seenServers := make(map[string]bool)
+	walk(order == DNSOrderInterleaved, len(servers), func(i int) int { return len(servers[i]) }, func(i, j int) {
+		ip := servers[i][j]
+		if ip == nil || ip.IsUnspecified() || seenServers[string(ip.To16())] {
+			return
+		}
+		seenServers[string(ip.To16())] = true
+		merged.DNS = append(merged.DNS, ip)
+	})
+	seenDomains := make(map[string]bool)
+	walk(order == DNSOrderInterleaved, len(domains), func(i int) int { return len(domains[i]) }, func(i, j int) {
+		domain := strings.TrimSuffix(domains[i][j], ".")
+		key := strings.ToLower(domain)
+		if domain == "" || seenDomains[key] {
+			return
+		}
+		seenDomains[key] = true
+		merged.DomainSearch = append(merged.DomainSearch, domain)
+	})
+
+	switch order {
+	case DNSOrderIPv4First, DNSOrderIPv6First:
+		// a stable partition of the servers by their family
+		v4First := order == DNSOrderIPv4First
+		var sorted []net.IP
+		for _, pass := range []bool{true, false} {
+			for _, ip := range merged.DNS {
+				if (ip.To4() != nil) == (v4First == pass) {
+					sorted = append(sorted, ip)
+				}
+			}
+		}
+		merged.DNS = sorted
+	}
+	return merged
+}
+
+// walk calls visit(i, j) for the j-th entry of each of the n lists, the i-th
+// one having length(i) entries, list by list or, if interleaved, rank by rank.
+func walk(interleaved bool, n int, length func(i int) int, visit func(i, j int)) {
+	if !interleaved {
+		for i := 0; i < n; i++ {
+			for j := 0; j < length(i); j++ {
+				visit(i, j)
+			}
+		}
+		return
+	}
+	for j := 0; ; j++ {
+		done := true
+		for i := 0; i < n; i++ {
+			if j < length(i) {
+				visit(i, j)
+				done = false
+			}
+		}
+		if done {
+			return
+		}
+	}
+}
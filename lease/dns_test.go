@@ -0,0 +1,87 @@
+package lease
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func dnsTestBindings() []*Binding {
+	return []*Binding{
+		{
+			// a DHCPv4 lease on the wired network
+			Address: net.IP{192, 0, 2, 10},
+			Options: Options{
+				DNS:          []net.IP{{192, 0, 2, 53}, {9, 9, 9, 9}},
+				DomainSearch: []string{"lan.example.com", "example.com"},
+			},
+		},
+		{
+			// a DHCPv6 lease on the same network
+			Address: net.ParseIP("2001:db8::10"),
+			Options: Options{
+				DNS:          []net.IP{net.ParseIP("2001:db8::53"), net.IPv4(9, 9, 9, 9)},
+				DomainSearch: []string{"Example.COM.", "v6.example.com"},
+			},
+		},
+		{
+			// a DHCPv4 lease on the wireless network
+			Address: net.IP{198, 51, 100, 10},
+			Options: Options{
+				DNS:          []net.IP{{198, 51, 100, 53}, net.IPv4zero},
+				DomainSearch: []string{"wifi.example.net", ""},
+			},
+		},
+	}
+}
+
+func TestMergeDNS(t *testing.T) {
+	for _, tc := range []struct {
+		order   DNSOrder
+		servers []string
+		domains []string
+	}{
+		{
+			DNSOrderGiven,
+			[]string{"192.0.2.53", "9.9.9.9", "2001:db8::53", "198.51.100.53"},
+			[]string{"lan.example.com", "example.com", "v6.example.com", "wifi.example.net"},
+		},
+		{
+			DNSOrderInterleaved,
+			[]string{"192.0.2.53", "2001:db8::53", "198.51.100.53", "9.9.9.9"},
+			[]string{"lan.example.com", "Example.COM", "wifi.example.net", "v6.example.com"},
+		},
+		{
+			DNSOrderIPv4First,
+			[]string{"192.0.2.53", "9.9.9.9", "198.51.100.53", "2001:db8::53"},
+			[]string{"lan.example.com", "example.com", "wifi.example.net", "v6.example.com"},
+		},
+		{
+			DNSOrderIPv6First,
+			[]string{"2001:db8::53", "9.9.9.9", "192.0.2.53", "198.51.100.53"},
+			[]string{"Example.COM", "v6.example.com", "lan.example.com", "wifi.example.net"},
+		},
+	} {
+		t.Run(tc.order.String(), func(t *testing.T) {
+			merged := MergeDNS(tc.order, dnsTestBindings()...)
+			var servers []string
+			for _, ip := range merged.DNS {
+				servers = append(servers, ip.String())
+			}
+			require.Equal(t, tc.servers, servers)
+			require.Equal(t, tc.domains, merged.DomainSearch)
+			require.Nil(t, merged.Routers)
+		})
+	}
+}
+
+func TestMergeDNSEmpty(t *testing.T) {
+	require.Equal(t, Options{}, MergeDNS(DNSOrderInterleaved))
+	require.Equal(t, Options{}, MergeDNS(DNSOrderGiven, &Binding{Address: net.IP{192, 0, 2, 10}}))
+}
+
+func TestDNSOrderString(t *testing.T) {
+	require.Equal(t, "IPv6 first", DNSOrderIPv6First.String())
+	require.Equal(t, "DNSOrder(42)", DNSOrder(42).String())
+}
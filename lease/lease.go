@@ -0,0 +1,117 @@
+// Package lease defines a Binding common to DHCPv4 and DHCPv6, so that the
+// address management and user interface layers can handle the leases of both
+// protocols with a single type. The From functions build it from the leases
+// obtained by the clients and from the bindings of the servers.
+package lease
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// Family is the address family of a Binding.
+type Family int
+
+// The address families, numbered after the IP version.
+const (
+	FamilyIPv4 Family = 4
+	FamilyIPv6 Family = 6
+)
+
+// String returns a human-readable string for the family.
+func (f Family) String() string {
+	switch f {
+	case FamilyIPv4:
+		return "IPv4"
+	case FamilyIPv6:
+		return "IPv6"
+	}
+	return fmt.Sprintf("Family(%d)", int(f))
+}
+
+// Binding associates an address, or a delegated prefix, with the client it
+// was handed out to, whatever the protocol.
+type Binding struct {
+	// Address is the leased address, or the delegated prefix if
+	// PrefixLength is not zero.
+	Address      net.IP
+	PrefixLength int
+	// HWAddr identifies a DHCPv4 client, DUID a DHCPv6 client. DUID holds
+	// the serialized DUID.
+	HWAddr net.HardwareAddr
+	DUID   []byte
+	// PreferredUntil and ValidUntil are the times the binding stops being
+	// preferred and expires at. They are zero if it never does. DHCPv4 has
+	// no preferred lifetime, so both are the expiry of its leases.
+	PreferredUntil time.Time
+	ValidUntil     time.Time
+	// Options holds the configuration given along with the binding, if
+	// known.
+	Options Options
+}
+
+// Options is the configuration given to a client along with its binding.
+// Each field is nil if the configuration did not include it.
+type Options struct {
+	Routers      []net.IP
+	DNS          []net.IP
+	DomainSearch []string
+}
+
+// Family returns the address family of the binding.
+func (b *Binding) Family() Family {
+	if b.Address.To4() != nil {
+		return FamilyIPv4
+	}
+	return FamilyIPv6
+}
+
+// IsPrefix returns true if the binding is for a delegated prefix rather than
+// for an address.
+func (b *Binding) IsPrefix() bool {
+	return b.PrefixLength != 0
+}
+
+// IPNet returns the delegated prefix, or the address as a host prefix.
+func (b *Binding) IPNet() *net.IPNet {
+	ip, bits := b.Address.To4(), 8*net.IPv4len
+	if ip == nil {
+		ip, bits = b.Address.To16(), 8*net.IPv6len
+	}
+	ones := bits
+	if b.IsPrefix() {
+		ones = b.PrefixLength
+	}
+	mask := net.CIDRMask(ones, bits)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+// Infinite returns true if the binding never expires.
+func (b *Binding) Infinite() bool {
+	return b.ValidUntil.IsZero()
+}
+
+// Expired returns true if the binding is expired at the given time.
+func (b *Binding) Expired(now time.Time) bool {
+	return !b.Infinite() && !now.Before(b.ValidUntil)
+}
+
+// Preferred returns true if the binding is preferred at the given time, that
+// is if new communications can use it.
+func (b *Binding) Preferred(now time.Time) bool {
+	return b.PreferredUntil.IsZero() || now.Before(b.PreferredUntil)
+}
+
+// String returns a human-readable string for the binding.
+func (b *Binding) String() string {
+	client := b.HWAddr.String()
+	if b.Family() == FamilyIPv6 {
+		client = fmt.Sprintf("%x", b.DUID)
+	}
+	expiry := "never"
+	if !b.Infinite() {
+		expiry = b.ValidUntil.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v bound to %v, expires %v", b.IPNet(), client, expiry)
+}
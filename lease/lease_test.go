@@ -0,0 +1,66 @@
+package lease
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindingAddress(t *testing.T) {
+	b := Binding{Address: net.IPv4(192, 0, 2, 10)}
+	require.Equal(t, FamilyIPv4, b.Family())
+	require.False(t, b.IsPrefix())
+	require.Equal(t, "192.0.2.10/32", b.IPNet().String())
+
+	b = Binding{Address: net.ParseIP("2001:db8::1")}
+	require.Equal(t, FamilyIPv6, b.Family())
+	require.Equal(t, "2001:db8::1/128", b.IPNet().String())
+}
+
+func TestBindingPrefix(t *testing.T) {
+	b := Binding{Address: net.ParseIP("2001:db8:1:2::"), PrefixLength: 56}
+	require.True(t, b.IsPrefix())
+	require.Equal(t, "2001:db8:1::/56", b.IPNet().String())
+}
+
+func TestBindingLifetimes(t *testing.T) {
+	now := time.Now()
+	b := Binding{
+		Address:        net.ParseIP("2001:db8::1"),
+		PreferredUntil: now.Add(time.Hour),
+		ValidUntil:     now.Add(2 * time.Hour),
+	}
+	require.False(t, b.Infinite())
+	require.True(t, b.Preferred(now))
+	require.False(t, b.Expired(now))
+	require.False(t, b.Preferred(now.Add(time.Hour)))
+	require.False(t, b.Expired(now.Add(time.Hour)))
+	require.True(t, b.Expired(now.Add(2*time.Hour)))
+
+	b = Binding{Address: net.ParseIP("2001:db8::1")}
+	require.True(t, b.Infinite())
+	require.True(t, b.Preferred(now))
+	require.False(t, b.Expired(now.Add(1000*time.Hour)))
+}
+
+func TestBindingString(t *testing.T) {
+	b := Binding{
+		Address: net.IPv4(192, 0, 2, 10),
+		HWAddr:  net.HardwareAddr{0, 1, 2, 3, 4, 5},
+	}
+	require.Equal(t, "192.0.2.10/32 bound to 00:01:02:03:04:05, expires never", b.String())
+	b = Binding{
+		Address:    net.ParseIP("2001:db8::1"),
+		DUID:       []byte{0, 3, 0, 1, 0, 1, 2, 3, 4, 5},
+		ValidUntil: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	require.Equal(t, "2001:db8::1/128 bound to 00030001000102030405, expires 2019-01-02T03:04:05Z", b.String())
+}
+
+func TestFamilyString(t *testing.T) {
+	require.Equal(t, "IPv4", FamilyIPv4.String())
+	require.Equal(t, "IPv6", FamilyIPv6.String())
+	require.Equal(t, "Family(5)", Family(5).String())
+}
@@ -0,0 +1,138 @@
+package netboot
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// The exporters below hand the configuration of a DHCPv4 lease over to the
+// network manager of the host, as a static configuration, e.g. when a
+// provisioning agent stops running its own client.
+
+// leaseIPNet returns the address of a lease with its subnet mask, or with the
+// default mask of its class if the server did not send one.
+func leaseIPNet(lease *dhcpv4.Lease) *net.IPNet {
+	ip := lease.IP.To4()
+	mask := lease.SubnetMask
+	if mask == nil {
+		mask = ip.DefaultMask()
+	}
+	return &net.IPNet{IP: ip, Mask: mask}
+}
+
+// NetworkdNetwork returns the content of a systemd-networkd .network file
+// configuring the interface ifname with the address, routers, DNS servers and
+// search domains of the lease. If ifname is empty, there is no [Match]
+// section, and the content can be used as a drop-in of an existing .network
+// file.
+func NetworkdNetwork(ifname string, lease *dhcpv4.Lease) []byte {
+	var buf bytes.Buffer
+	if ifname != "" {
+		fmt.Fprintf(&buf, "[Match]\nName=%s\n\n", ifname)
+	}
+	buf.WriteString("[Network]\n")
+	fmt.Fprintf(&buf, "Address=%s\n", leaseIPNet(lease))
+	for _, router := range lease.Routers {
+		fmt.Fprintf(&buf, "Gateway=%s\n", router)
+	}
+	for _, server := range lease.DNS {
+		fmt.Fprintf(&buf, "DNS=%s\n", server)
+	}
+	if len(lease.DomainSearch) > 0 {
+		fmt.Fprintf(&buf, "Domains=%s\n", strings.Join(lease.DomainSearch, " "))
+	}
+	return buf.Bytes()
+}
+
+// NMSettings are the settings of a NetworkManager connection, by setting name,
+// e.g. "ipv4", and key, with the values formatted as in keyfiles.
+type NMSettings map[string]map[string]string
+
+// Set sets the value of a key of a setting.
+func (s NMSettings) Set(setting, key, value string) {
+	if s[setting] == nil {
+		s[setting] = make(map[string]string)
+	}
+	s[setting][key] = value
+}
+
+// nmSettingOrder is the order in which NetworkManager writes the settings it
+// knows of in keyfiles, the others come after them in alphabetical order.
+var nmSettingOrder = map[string]int{
+	"connection": 0,
+	"ethernet":   1,
+	"ipv4":       2,
+	"ipv6":       3,
+}
+
+// Keyfile returns the content of a keyfile holding the settings, to be saved
+// in /etc/NetworkManager/system-connections with a .nmconnection extension
+// and mode 0600, otherwise NetworkManager ignores it.
+func (s NMSettings) Keyfile() []byte {
+	settings := make([]string, 0, len(s))
+	for setting := range s {
+		settings = append(settings, setting)
+	}
+	rank := func(setting string) int {
+		if r, ok := nmSettingOrder[setting]; ok {
+			return r
+		}
+		return len(nmSettingOrder)
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		if ri, rj := rank(settings[i]), rank(settings[j]); ri != rj {
+			return ri < rj
+		}
+		return settings[i] < settings[j]
+	})
+	var buf bytes.Buffer
+	for i, setting := range settings {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "[%s]\n", setting)
+		keys := make([]string, 0, len(s[setting]))
+		for key := range s[setting] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&buf, "%s=%s\n", key, s[setting][key])
+		}
+	}
+	return buf.Bytes()
+}
+
+// NetworkManagerSettings returns the settings of an Ethernet connection named
+// after the interface ifname, and bound to it, configuring the address, first
+// router, DNS servers and search domains of the lease. IPv6 is left to its
+// default configuration.
+func NetworkManagerSettings(ifname string, lease *dhcpv4.Lease) NMSettings {
+	s := make(NMSettings)
+	s.Set("connection", "id", ifname)
+	s.Set("connection", "type", "ethernet")
+	s.Set("connection", "interface-name", ifname)
+	s.Set("ipv4", "method", "manual")
+	address := leaseIPNet(lease).String()
+	if len(lease.Routers) > 0 {
+		address += "," + lease.Routers[0].String()
+	}
+	s.Set("ipv4", "address1", address)
+	if len(lease.DNS) > 0 {
+		servers := make([]string, 0, len(lease.DNS))
+		for _, server := range lease.DNS {
+			servers = append(servers, server.String())
+		}
+		// lists end with a separator in keyfiles
+		s.Set("ipv4", "dns", strings.Join(servers, ";")+";")
+	}
+	if len(lease.DomainSearch) > 0 {
+		s.Set("ipv4", "dns-search", strings.Join(lease.DomainSearch, ";")+";")
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package netboot
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/stretchr/testify/require"
+)
+
+func hostConfTestLease() *dhcpv4.Lease {
+	return &dhcpv4.Lease{
+		IP:           net.IPv4(192, 168, 0, 10),
+		SubnetMask:   net.IPv4Mask(255, 255, 255, 0),
+		Routers:      []net.IP{net.IPv4(192, 168, 0, 1), net.IPv4(192, 168, 0, 2)},
+		DNS:          []net.IP{net.IPv4(192, 168, 0, 53), net.IPv4(8, 8, 8, 8)},
+		DomainSearch: []string{"lan.example.com", "example.com"},
+	}
+}
+
+func TestNetworkdNetwork(t *testing.T) {
+	require.Equal(t, `[Match]
+Name=eth0
+
+[Network]
+Address=192.168.0.10/24
+Gateway=192.168.0.1
+Gateway=192.168.0.2
+DNS=192.168.0.53
+DNS=8.8.8.8
+Domains=lan.example.com example.com
+`, string(NetworkdNetwork("eth0", hostConfTestLease())))
+
+	// a drop-in, for a lease without mask nor options
+	lease := &dhcpv4.Lease{IP: net.IPv4(10, 1, 2, 3)}
+	require.Equal(t, "[Network]\nAddress=10.1.2.3/8\n", string(NetworkdNetwork("", lease)))
+}
+
+func TestNetworkManagerSettings(t *testing.T) {
+	s := NetworkManagerSettings("eth0", hostConfTestLease())
+	require.Equal(t, NMSettings{
+		"connection": {"id": "eth0", "type": "ethernet", "interface-name": "eth0"},
+		"ipv4": {
+			"method":     "manual",
+			"address1":   "192.168.0.10/24,192.168.0.1",
+			"dns":        "192.168.0.53;8.8.8.8;",
+			"dns-search": "lan.example.com;example.com;",
+		},
+	}, s)
+
+	s.Set("ipv6", "method", "ignore")
+	s.Set("user", "org.example.provisioned", "yes")
+	require.Equal(t, `[connection]
+id=eth0
+interface-name=eth0
+type=ethernet
+
+[ipv4]
+address1=192.168.0.10/24,192.168.0.1
+dns=192.168.0.53;8.8.8.8;
+dns-search=lan.example.com;example.com;
+method=manual
+
+[ipv6]
+method=ignore
+
+[user]
+org.example.provisioned=yes
+`, string(s.Keyfile()))
+}
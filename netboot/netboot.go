@@ -3,21 +3,21 @@ package netboot
 import (
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/insomniacslk/dhcp/dhcpv6"
 )
 
 // RequestNetbootv6 sends a netboot request via DHCPv6 and returns the exchanged packets. Additional modifiers
-// can be passed to manipulate both solicit and advertise packets.
+// can be passed to manipulate both solicit and advertise packets. The attempts
+// are reported to dhcpv6.DefaultLogger.
 func RequestNetbootv6(ifname string, timeout time.Duration, retries int, modifiers ...dhcpv6.Modifier) ([]dhcpv6.DHCPv6, error) {
 	var (
 		conversation []dhcpv6.DHCPv6
 	)
 	delay := 2 * time.Second
 	for i := 0; i <= retries; i++ {
-		log.Printf("sending request, attempt #%d", i+1)
+		dhcpv6.DefaultLogger.Printf("sending request, attempt #%d", i+1)
 		solicit, err := dhcpv6.NewSolicitForInterface(ifname, modifiers...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create SOLICIT for interface %s: %v", ifname, err)
@@ -30,8 +30,8 @@ func RequestNetbootv6(ifname string, timeout time.Duration, retries int, modifie
 		modifiers = append(modifiers, dhcpv6.WithNetboot)
 		conversation, err = client.Exchange(ifname, solicit, modifiers...)
 		if err != nil {
-			log.Printf("Client.Exchange failed: %v", err)
-			log.Printf("sleeping %v before retrying", delay)
+			dhcpv6.DefaultLogger.Printf("Client.Exchange failed: %v", err)
+			dhcpv6.DefaultLogger.Printf("sleeping %v before retrying", delay)
 			if i >= retries {
 				// don't wait at the end of the last attempt
 				break
@@ -71,7 +71,7 @@ func ConversationToNetconf(conversation []dhcpv6.DHCPv6) (*NetConf, string, erro
 	)
 	opt = reply.GetOneOption(dhcpv6.OptionBootfileURL)
 	if opt == nil {
-		log.Printf("no bootfile URL option found in REPLY, looking for it in ADVERTISE")
+		dhcpv6.DefaultLogger.Printf("no bootfile URL option found in REPLY, looking for it in ADVERTISE")
 		// as a fallback, look for bootfile URL in the advertise
 		var advertise dhcpv6.DHCPv6
 		for _, m := range conversation {
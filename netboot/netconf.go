@@ -122,10 +122,23 @@ func ConfigureInterface(ifname string, netconf *NetConf) error {
 		}
 	}
 	// configure /etc/resolv.conf
+	return WriteResolvConf("/etc/resolv.conf", netconf.DNSServers, netconf.DNSSearchList)
+}
+
+// ResolvConf returns the content of a resolv.conf file with the given DNS
+// servers and search list. The resolvers usually use the first three servers
+// only. To combine the configurations of several leases, see lease.MergeDNS.
+func ResolvConf(servers []net.IP, searchList []string) []byte {
 	resolvconf := ""
-	for _, ns := range netconf.DNSServers {
+	for _, ns := range servers {
 		resolvconf += fmt.Sprintf("nameserver %s\n", ns)
 	}
-	resolvconf += fmt.Sprintf("search %s\n", strings.Join(netconf.DNSSearchList, " "))
-	return ioutil.WriteFile("/etc/resolv.conf", []byte(resolvconf), 0644)
+	resolvconf += fmt.Sprintf("search %s\n", strings.Join(searchList, " "))
+	return []byte(resolvconf)
+}
+
+// WriteResolvConf writes a resolv.conf file at path with the given DNS servers
+// and search list.
+func WriteResolvConf(path string, servers []net.IP, searchList []string) error {
+	return ioutil.WriteFile(path, ResolvConf(servers, searchList), 0644)
 }
@@ -0,0 +1,24 @@
+package netboot
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteResolvConf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netboot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "resolv.conf")
+	servers := []net.IP{net.IPv4(192, 0, 2, 53), net.ParseIP("2001:db8::53")}
+	require.NoError(t, WriteResolvConf(path, servers, []string{"lan.example.com", "example.com"}))
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "nameserver 192.0.2.53\nnameserver 2001:db8::53\nsearch lan.example.com example.com\n", string(data))
+}
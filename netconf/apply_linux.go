@@ -0,0 +1,84 @@
+// +build linux
+
+package netconf
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+// Apply configures the interface ifname with c through netlink: it brings the
+// interface up, sets its MTU, replaces its addresses of the same prefix, and
+// replaces the routes to the destinations of c. The addresses and routes that
+// c does not mention are left untouched, and so is the DNS configuration of
+// the host, see WriteResolvConf.
+func Apply(ifname string, c *Config) error {
+	link, err := netlink.LinkByName(ifname)
+	if err != nil {
+		return fmt.Errorf("cannot get interface %s: %v", ifname, err)
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("cannot bring %s up: %v", ifname, err)
+	}
+	if c.MTU != 0 && c.MTU != link.Attrs().MTU {
+		if err := netlink.LinkSetMTU(link, c.MTU); err != nil {
+			return fmt.Errorf("cannot set the MTU of %s to %d: %v", ifname, c.MTU, err)
+		}
+	}
+	for _, a := range c.Addresses {
+		ipnet := a.IPNet
+		addr := netlink.Addr{
+			IPNet:       &ipnet,
+			PreferedLft: seconds(a.PreferredLifetime),
+			ValidLft:    seconds(a.ValidLifetime),
+		}
+		if err := netlink.AddrReplace(link, &addr); err != nil {
+			return fmt.Errorf("cannot configure %v on %s: %v", &ipnet, ifname, err)
+		}
+	}
+	for _, r := range c.Routes {
+		route := netlink.Route{LinkIndex: link.Attrs().Index}
+		if ones, _ := r.Dest.Mask.Size(); ones != 0 {
+			route.Dst = r.Dest
+		}
+		if r.Router.IsUnspecified() {
+			route.Scope = netlink.SCOPE_LINK
+		} else {
+			route.Gw = r.Router
+			if !onLink(c.Addresses, r.Router) {
+				// the router may be outside of the subnet of the
+				// address, as RFC 3442 allows
+				route.Flags = int(netlink.FLAG_ONLINK)
+			}
+		}
+		if err := netlink.RouteReplace(&route); err != nil {
+			return fmt.Errorf("cannot add the route %v on %s: %v", r, ifname, err)
+		}
+	}
+	return nil
+}
+
+// onLink returns true if ip is in the prefix of one of the addresses.
+func onLink(addrs []Address, ip net.IP) bool {
+	for _, a := range addrs {
+		if a.IPNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// seconds converts a lifetime to the lifetime of a netlink address, in
+// seconds, where 0 means forever.
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	if d < time.Second {
+		return 1
+	}
+	return int(d / time.Second)
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package netconf
+
+import (
+	"errors"
+)
+
+// Apply configures the interface ifname with c. It is only implemented on
+// Linux.
+func Apply(ifname string, c *Config) error {
+	return errors.New("interface configuration is not supported on this platform")
+}
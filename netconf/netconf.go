@@ -0,0 +1,137 @@
+// Package netconf configures a network interface with a DHCP lease: its
+// address, its MTU and its routes, and optionally the DNS configuration of
+// the host. A Config is built from the acknowledge of a DHCPv4 server with
+// FromDHCPv4, or from the Reply of a DHCPv6 server with FromDHCPv6, and
+// applied with Apply, which is only implemented on Linux, e.g.:
+//
+//   conversation, err := client.Exchange("eth0")
+//   ...
+//   c, err := netconf.FromDHCPv4(conversation[len(conversation)-1])
+//   ...
+//   err = netconf.Apply("eth0", c)
+package netconf
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/netboot"
+)
+
+// infiniteLifetime is the lifetime of the DHCPv6 addresses that never expire,
+// as defined in RFC 8415, section 7.7.
+const infiniteLifetime = 0xffffffff
+
+// Address is an address to configure on the interface, with its prefix.
+type Address struct {
+	IPNet net.IPNet
+	// PreferredLifetime and ValidLifetime are how long the address is
+	// preferred and valid, after which the kernel stops using it and
+	// removes it. They are zero if the address never expires.
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+}
+
+// Config is the configuration of an interface obtained with DHCP.
+type Config struct {
+	Addresses []Address
+	// Routes are added through the interface. A route whose router is
+	// 0.0.0.0 is a route to a network attached to the interface, as in RFC
+	// 3442.
+	Routes []dhcpv4.Route
+	// MTU is the MTU of the interface, or 0 to keep the current one.
+	MTU int
+
+	DNSServers    []net.IP
+	DNSSearchList []string
+}
+
+// FromDHCPv4 returns the configuration given by the acknowledge of a DHCPv4
+// server. The routes are the ones of DHCPv4.Routes, and the address expires
+// with the lease.
+func FromDHCPv4(ack *dhcpv4.DHCPv4) (*Config, error) {
+	lease, err := dhcpv4.NewLeaseFromACK(ack)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := ack.Routes()
+	if err != nil {
+		return nil, err
+	}
+	mask := lease.SubnetMask
+	if mask == nil {
+		mask = lease.IP.DefaultMask()
+	}
+	var lifetime time.Duration
+	if !lease.Infinite() {
+		lifetime = lease.LeaseTime
+	}
+	return &Config{
+		Addresses: []Address{{
+			IPNet:             net.IPNet{IP: lease.IP, Mask: mask},
+			PreferredLifetime: lifetime,
+			ValidLifetime:     lifetime,
+		}},
+		Routes:        routes,
+		MTU:           int(ack.InterfaceMTU()),
+		DNSServers:    lease.DNS,
+		DNSSearchList: lease.DomainSearch,
+	}, nil
+}
+
+// FromDHCPv6 returns the configuration given by the Reply of a DHCPv6 server:
+// the addresses of its IA_NA options, and its DNS options. The addresses are
+// configured as /128, since the prefix of the link is advertised by the
+// routers, along with the routes.
+func FromDHCPv6(reply *dhcpv6.DHCPv6Message) (*Config, error) {
+	var c Config
+	for _, opt := range reply.GetOption(dhcpv6.OptionIANA) {
+		ia, ok := opt.(*dhcpv6.OptIANA)
+		if !ok {
+			continue
+		}
+		for _, o := range ia.Options {
+			iaaddr, ok := o.(*dhcpv6.OptIAAddress)
+			if !ok {
+				continue
+			}
+			c.Addresses = append(c.Addresses, Address{
+				IPNet:             net.IPNet{IP: iaaddr.IPv6Addr, Mask: net.CIDRMask(128, 128)},
+				PreferredLifetime: lifetime(iaaddr.PreferredLifetime),
+				ValidLifetime:     lifetime(iaaddr.ValidLifetime),
+			})
+		}
+	}
+	if len(c.Addresses) == 0 {
+		return nil, errors.New("no address assigned")
+	}
+	if opt, ok := reply.GetOneOption(dhcpv6.OptionDNSRecursiveNameServer).(*dhcpv6.OptDNSRecursiveNameServer); ok {
+		c.DNSServers = opt.NameServers
+	}
+	if opt, ok := reply.GetOneOption(dhcpv6.OptionDomainSearchList).(*dhcpv6.OptDomainSearchList); ok {
+		c.DNSSearchList = opt.DomainSearchList
+	}
+	return &c, nil
+}
+
+// lifetime converts a DHCPv6 lifetime, in seconds, to the lifetime of an
+// Address.
+func lifetime(seconds uint32) time.Duration {
+	if seconds == infiniteLifetime {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// WriteResolvConf writes a resolv.conf file at path, usually
+// /etc/resolv.conf, with the DNS configuration of c. It does nothing if c has
+// no DNS server.
+func (c *Config) WriteResolvConf(path string) error {
+	if len(c.DNSServers) == 0 {
+		return nil
+	}
+	return netboot.WriteResolvConf(path, c.DNSServers, c.DNSSearchList)
+}
@@ -0,0 +1,100 @@
+package netconf
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromDHCPv4(t *testing.T) {
+	ack, err := dhcpv4.New()
+	require.NoError(t, err)
+	ack.SetOpcode(dhcpv4.OpcodeBootReply)
+	ack.SetYourIPAddr(net.IPv4(192, 0, 2, 10))
+	ack.AddOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeAck})
+	ack.AddOption(&dhcpv4.OptServerIdentifier{ServerID: net.IP{192, 0, 2, 1}})
+	ack.AddOption(&dhcpv4.OptIPAddressLeaseTime{LeaseTime: 3600})
+	ack.AddOption(&dhcpv4.OptSubnetMask{SubnetMask: net.IPMask{255, 255, 255, 0}})
+	ack.AddOption(&dhcpv4.OptRouter{Routers: []net.IP{net.IPv4(192, 0, 2, 1)}})
+	ack.AddOption(&dhcpv4.OptDomainNameServer{NameServers: []net.IP{net.IPv4(192, 0, 2, 53)}})
+	ack.AddOption(&dhcpv4.OptDomainName{DomainName: "example.com"})
+	ack.AddOption(&dhcpv4.OptInterfaceMTU{MTU: 1400})
+	c, err := FromDHCPv4(ack)
+	require.NoError(t, err)
+	require.Equal(t, []Address{{
+		IPNet:             net.IPNet{IP: net.IP{192, 0, 2, 10}, Mask: net.CIDRMask(24, 32)},
+		PreferredLifetime: time.Hour,
+		ValidLifetime:     time.Hour,
+	}}, c.Addresses)
+	require.Len(t, c.Routes, 1)
+	require.Equal(t, "0.0.0.0/0 via 192.0.2.1", c.Routes[0].String())
+	require.Equal(t, 1400, c.MTU)
+	require.Equal(t, []net.IP{{192, 0, 2, 53}}, c.DNSServers)
+	require.Equal(t, []string{"example.com"}, c.DNSSearchList)
+
+	ack.UpdateOption(&dhcpv4.OptMessageType{MessageType: dhcpv4.MessageTypeNak})
+	_, err = FromDHCPv4(ack)
+	require.Error(t, err)
+}
+
+func TestFromDHCPv6(t *testing.T) {
+	reply := &dhcpv6.DHCPv6Message{}
+	reply.SetMessage(dhcpv6.MessageTypeReply)
+	_, err := FromDHCPv6(reply)
+	require.Error(t, err)
+
+	ip := net.ParseIP("2001:db8::10")
+	reply.AddOption(&dhcpv6.OptIANA{Options: []dhcpv6.Option{
+		&dhcpv6.OptIAAddress{IPv6Addr: ip, PreferredLifetime: 1800, ValidLifetime: 0xffffffff},
+	}})
+	reply.AddOption(&dhcpv6.OptDNSRecursiveNameServer{NameServers: []net.IP{net.ParseIP("2001:db8::53")}})
+	c, err := FromDHCPv6(reply)
+	require.NoError(t, err)
+	require.Equal(t, []Address{{
+		IPNet:             net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)},
+		PreferredLifetime: 30 * time.Minute,
+	}}, c.Addresses)
+	require.Equal(t, []net.IP{net.ParseIP("2001:db8::53")}, c.DNSServers)
+	require.Nil(t, c.DNSSearchList)
+}
+
+func TestFromDHCPv6GenericOptions(t *testing.T) {
+	reply := &dhcpv6.DHCPv6Message{}
+	reply.SetMessage(dhcpv6.MessageTypeReply)
+	reply.AddOption(&dhcpv6.OptionGeneric{OptionCode: dhcpv6.OptionIANA, OptionData: []byte{1}})
+	reply.AddOption(&dhcpv6.OptIANA{Options: []dhcpv6.Option{
+		&dhcpv6.OptIAAddress{IPv6Addr: net.ParseIP("2001:db8::10"), PreferredLifetime: 1800, ValidLifetime: 3600},
+	}})
+	reply.AddOption(&dhcpv6.OptionGeneric{OptionCode: dhcpv6.OptionDNSRecursiveNameServer, OptionData: []byte{1}})
+	reply.AddOption(&dhcpv6.OptionGeneric{OptionCode: dhcpv6.OptionDomainSearchList, OptionData: []byte{1}})
+	c, err := FromDHCPv6(reply)
+	require.NoError(t, err)
+	require.Len(t, c.Addresses, 1)
+	require.Nil(t, c.DNSServers)
+	require.Nil(t, c.DNSSearchList)
+}
+
+func TestWriteResolvConf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netconf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "resolv.conf")
+
+	c := Config{}
+	require.NoError(t, c.WriteResolvConf(path))
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	c.DNSServers, c.DNSSearchList = []net.IP{net.IPv4(192, 0, 2, 53)}, []string{"example.com"}
+	require.NoError(t, c.WriteResolvConf(path))
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "nameserver 192.0.2.53\nsearch example.com\n", string(data))
+}
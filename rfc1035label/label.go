@@ -1,38 +1,89 @@
 package rfc1035label
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"strings"
 )
 
 // This implements the compression from RFC 1035, section 4.1.4
 // https://tools.ietf.org/html/rfc1035
+//
+// The decoder follows the compression pointers, so that it can be used for
+// both the compressed and the uncompressed lists. Whether the encoder should
+// compress depends on the protocol: the DHCPv4 domain search option of RFC
+// 3397 uses compression, while RFC 8415 forbids it in DHCPv6.
 
-// LabelsFromBytes decodes a serialized stream and returns a list of labels
+// pointerMask marks a length byte as the beginning of a compression pointer.
+const pointerMask = 0xc0
+
+// maxPointerOffset is the largest offset a compression pointer can hold.
+const maxPointerOffset = 0x3fff
+
+// LabelsFromBytes decodes a serialized stream and returns a list of labels.
+// The labels may be compressed with pointers to previous labels, the offsets
+// being relative to the beginning of buf.
 func LabelsFromBytes(buf []byte) ([]string, error) {
 	var (
 		pos     = 0
 		domains = make([]string, 0)
-		label   = ""
+	)
+	for pos < len(buf) {
+		label, next, err := labelFromBytes(buf, pos)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, label)
+		pos = next
+	}
+	return domains, nil
+}
+
+// labelFromBytes decodes the label starting at pos in buf, and returns it with
+// the position of what follows it in buf.
+func labelFromBytes(buf []byte, pos int) (string, int, error) {
+	var (
+		parts []string
+		// next is the position after the label, known once the terminating
+		// zero length or the first pointer is found
+		next = -1
+		// start is the position the current run of parts begins at. Pointers
+		// must point before it, so that decoding cannot loop.
+		start = pos
 	)
 	for {
 		if pos >= len(buf) {
-			return domains, nil
+			return "", 0, errors.New("LabelsFromBytes: label not terminated")
 		}
 		length := int(buf[pos])
-		pos++
-		if length == 0 {
-			domains = append(domains, label)
-			label = ""
-		}
-		if len(buf)-pos < length {
-			return nil, fmt.Errorf("DomainNamesFromBytes: invalid short label length")
-		}
-		if label != "" {
-			label += "."
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = pos + 1
+			}
+			return strings.Join(parts, "."), next, nil
+		case length&pointerMask == pointerMask:
+			if pos+2 > len(buf) {
+				return "", 0, errors.New("LabelsFromBytes: invalid short pointer")
+			}
+			ptr := int(binary.BigEndian.Uint16(buf[pos:pos+2]) & maxPointerOffset)
+			if ptr >= start {
+				return "", 0, fmt.Errorf("LabelsFromBytes: invalid pointer %d, not to a previous label", ptr)
+			}
+			if next < 0 {
+				next = pos + 2
+			}
+			pos, start = ptr, ptr
+		case length&pointerMask != 0:
+			return "", 0, fmt.Errorf("LabelsFromBytes: invalid label type 0x%02x", length&pointerMask)
+		default:
+			if len(buf)-pos-1 < length {
+				return "", 0, errors.New("LabelsFromBytes: invalid short label length")
+			}
+			parts = append(parts, string(buf[pos+1:pos+1+length]))
+			pos += 1 + length
 		}
-		label += string(buf[pos : pos+length])
-		pos += length
 	}
 }
 
@@ -58,3 +109,39 @@ func LabelsToBytes(labels []string) []byte {
 	}
 	return encodedLabels
 }
+
+// CompressedLabelsToBytes encodes a list of labels like LabelsToBytes, but
+// replaces the longest suffix of each label that was already encoded with a
+// pointer to it. The offsets are relative to the beginning of the returned
+// stream.
+func CompressedLabelsToBytes(labels []string) []byte {
+	var (
+		encodedLabels []byte
+		suffixes      = make(map[string]int)
+	)
+	for _, label := range labels {
+		if len(label) == 0 {
+			encodedLabels = append(encodedLabels, 0)
+			continue
+		}
+		parts := strings.Split(label, ".")
+		compressed := false
+		for i := range parts {
+			suffix := strings.Join(parts[i:], ".")
+			if offset, ok := suffixes[suffix]; ok {
+				encodedLabels = append(encodedLabels, byte(pointerMask|offset>>8), byte(offset))
+				compressed = true
+				break
+			}
+			if len(encodedLabels) <= maxPointerOffset {
+				suffixes[suffix] = len(encodedLabels)
+			}
+			encodedLabels = append(encodedLabels, byte(len(parts[i])))
+			encodedLabels = append(encodedLabels, parts[i]...)
+		}
+		if !compressed {
+			encodedLabels = append(encodedLabels, 0)
+		}
+	}
+	return encodedLabels
+}
@@ -2,6 +2,7 @@ package rfc1035label
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 )
 
@@ -64,3 +65,59 @@ func TestLabelToBytesZeroLength(t *testing.T) {
 		t.Fatalf("Invalid label. Expected: %v, got: %v", expected, encodedLabel)
 	}
 }
+
+func TestLabelsFromBytesCompressed(t *testing.T) {
+	labels, err := LabelsFromBytes([]byte{
+		0x9, 's', 'l', 'a', 'c', 'k', 'w', 'a', 'r', 'e',
+		0x2, 'i', 't',
+		0x0,
+		0x3, 'w', 'w', 'w', 0xc0, 0x0,
+		0xc0, 0xe,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"slackware.it", "www.slackware.it", "www.slackware.it"}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("Invalid labels. Expected: %v, got: %v", expected, labels)
+	}
+}
+
+func TestLabelsFromBytesInvalidPointer(t *testing.T) {
+	for _, buf := range [][]byte{
+		{0xc0},                     // short pointer
+		{0xc0, 0x0},                // pointer to itself
+		{0x1, 'a', 0xc0, 0x4, 0x0}, // forward pointer
+		{0x1, 'a', 0xc0, 0x0},      // loop
+		{0x80, 0x0},                // reserved label type
+		{0x1, 'a'},                 // not terminated
+	} {
+		if _, err := LabelsFromBytes(buf); err == nil {
+			t.Fatalf("Expected error for %v, got nil", buf)
+		}
+	}
+}
+
+func TestCompressedLabelsToBytes(t *testing.T) {
+	labels := []string{"slackware.it", "www.slackware.it", "www.slackware.it", "", "it"}
+	encoded := CompressedLabelsToBytes(labels)
+	expected := []byte{
+		0x9, 's', 'l', 'a', 'c', 'k', 'w', 'a', 'r', 'e',
+		0x2, 'i', 't',
+		0x0,
+		0x3, 'w', 'w', 'w', 0xc0, 0x0,
+		0xc0, 0xe,
+		0x0,
+		0xc0, 0xa,
+	}
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("Invalid labels. Expected: %v, got: %v", expected, encoded)
+	}
+	decoded, err := LabelsFromBytes(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, labels) {
+		t.Fatalf("Invalid labels. Expected: %v, got: %v", labels, decoded)
+	}
+}
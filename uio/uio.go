@@ -0,0 +1,158 @@
+// Package uio provides a bounds-checked reader for the binary formats of the
+// DHCP messages and options.
+//
+// A Lexer reads fixed-size integers and byte slices from a buffer. Instead of
+// panicking on a short buffer, or having the caller check the length before
+// each slice expression, the first read past the end records an error and
+// returns zero values, as do all the reads that follow. The parser thus reads
+// the whole structure, then checks Error, or FinError if the structure must
+// take the whole buffer:
+//
+//	buf := uio.NewBigEndianBuffer(data)
+//	code := buf.Read8()
+//	value := buf.Read32()
+//	if err := buf.FinError(); err != nil {
+//		return nil, err
+//	}
+package uio
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+)
+
+// ErrBufferTooShort is recorded by a Lexer when a read goes past the end of
+// its buffer.
+var ErrBufferTooShort = errors.New("buffer too short")
+
+// ErrUnreadBytes is returned by FinError when the buffer was not read until
+// its end.
+var ErrUnreadBytes = errors.New("buffer contains unread bytes")
+
+// Lexer is a bounds-checked reader over a byte slice. The slices it returns
+// reference the buffer, unless they come from CopyN.
+type Lexer struct {
+	data  []byte
+	pos   int
+	err   error
+	order binary.ByteOrder
+}
+
+// NewBigEndianBuffer returns a Lexer reading integers in network byte order
+// from b.
+func NewBigEndianBuffer(b []byte) *Lexer {
+	return &Lexer{data: b, order: binary.BigEndian}
+}
+
+// Len returns the number of unread bytes.
+func (l *Lexer) Len() int {
+	return len(l.data) - l.pos
+}
+
+// Has returns true if at least n bytes are left to read, and no error was
+// recorded. Loops reading until the end of the buffer can thus test it to
+// stop at the first error.
+func (l *Lexer) Has(n int) bool {
+	return l.err == nil && n >= 0 && l.Len() >= n
+}
+
+// Error returns the error recorded by the first read that went past the end
+// of the buffer, or nil.
+func (l *Lexer) Error() error {
+	return l.err
+}
+
+// FinError works like Error, but also returns ErrUnreadBytes if some bytes
+// were not read.
+func (l *Lexer) FinError() error {
+	if l.err != nil {
+		return l.err
+	}
+	if l.Len() > 0 {
+		return ErrUnreadBytes
+	}
+	return nil
+}
+
+// Consume returns the next n bytes, or nil if there are not as many left, in
+// which case ErrBufferTooShort is recorded. Once an error is recorded, it
+// always returns nil.
+func (l *Lexer) Consume(n int) []byte {
+	if l.err != nil {
+		return nil
+	}
+	if !l.Has(n) {
+		l.err = ErrBufferTooShort
+		return nil
+	}
+	b := l.data[l.pos : l.pos+n : l.pos+n]
+	l.pos += n
+	return b
+}
+
+// CopyN works like Consume, but returns a copy of the bytes, which does not
+// reference the buffer.
+func (l *Lexer) CopyN(n int) []byte {
+	b := l.Consume(n)
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// ReadAll returns the unread bytes, possibly none.
+func (l *Lexer) ReadAll() []byte {
+	return l.Consume(l.Len())
+}
+
+// Read8 reads a byte, or returns 0 if there is none left.
+func (l *Lexer) Read8() uint8 {
+	b := l.Consume(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+// Read16 reads a 16-bit integer, or returns 0 if the buffer is too short.
+func (l *Lexer) Read16() uint16 {
+	b := l.Consume(2)
+	if b == nil {
+		return 0
+	}
+	return l.order.Uint16(b)
+}
+
+// Read32 reads a 32-bit integer, or returns 0 if the buffer is too short.
+func (l *Lexer) Read32() uint32 {
+	b := l.Consume(4)
+	if b == nil {
+		return 0
+	}
+	return l.order.Uint32(b)
+}
+
+// Read64 reads a 64-bit integer, or returns 0 if the buffer is too short.
+func (l *Lexer) Read64() uint64 {
+	b := l.Consume(8)
+	if b == nil {
+		return 0
+	}
+	return l.order.Uint64(b)
+}
+
+// ReadIPv4 reads an IPv4 address, in its 16-byte representation as returned
+// by net.IPv4, or returns nil if the buffer is too short.
+func (l *Lexer) ReadIPv4() net.IP {
+	b := l.Consume(net.IPv4len)
+	if b == nil {
+		return nil
+	}
+	return net.IPv4(b[0], b[1], b[2], b[3])
+}
+
+// ReadIPv6 reads an IPv6 address, or returns nil if the buffer is too short.
+func (l *Lexer) ReadIPv6() net.IP {
+	return net.IP(l.CopyN(net.IPv6len))
+}
@@ -0,0 +1,56 @@
+package uio
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexer(t *testing.T) {
+	buf := NewBigEndianBuffer([]byte{
+		1,
+		0, 2,
+		0, 0, 0, 3,
+		0, 0, 0, 0, 0, 0, 0, 4,
+		192, 168, 0, 1,
+		0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+		'a', 'b', 'c',
+	})
+	require.True(t, buf.Has(3))
+	require.False(t, buf.Has(-1))
+	require.Equal(t, uint8(1), buf.Read8())
+	require.Equal(t, uint16(2), buf.Read16())
+	require.Equal(t, uint32(3), buf.Read32())
+	require.Equal(t, uint64(4), buf.Read64())
+	require.Equal(t, net.IPv4(192, 168, 0, 1), buf.ReadIPv4())
+	require.Equal(t, net.ParseIP("2001:db8::1"), buf.ReadIPv6())
+	require.Equal(t, 3, buf.Len())
+	require.Equal(t, []byte("a"), buf.CopyN(1))
+	require.Equal(t, ErrUnreadBytes, buf.FinError())
+	require.Equal(t, []byte("bc"), buf.ReadAll())
+	require.NoError(t, buf.FinError())
+	require.Equal(t, []byte{}, buf.ReadAll())
+}
+
+func TestLexerShort(t *testing.T) {
+	buf := NewBigEndianBuffer([]byte{1, 2, 3})
+	require.Equal(t, uint32(0), buf.Read32())
+	require.Equal(t, ErrBufferTooShort, buf.Error())
+	// the error sticks, even for reads that would fit
+	require.Equal(t, uint8(0), buf.Read8())
+	require.Nil(t, buf.Consume(0))
+	require.Nil(t, buf.ReadIPv4())
+	require.Nil(t, buf.ReadIPv6())
+	require.Nil(t, buf.CopyN(1))
+	require.Equal(t, uint16(0), buf.Read16())
+	require.Equal(t, uint64(0), buf.Read64())
+	require.Equal(t, ErrBufferTooShort, buf.FinError())
+	require.False(t, buf.Has(0))
+
+	// the slices returned cannot be extended into the unread bytes
+	buf = NewBigEndianBuffer([]byte{1, 2, 3})
+	b := buf.Consume(1)
+	_ = append(b, 9)
+	require.Equal(t, uint8(2), buf.Read8())
+}
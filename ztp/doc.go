@@ -0,0 +1,13 @@
+/*
+The ztp package assembles the DHCP options that network devices, mostly
+switches and routers, expect when they provision themselves at their first
+boot (zero-touch provisioning): where to fetch their configuration, or the
+script that installs it, and possibly a new software image.
+
+The vendors agree on little besides the Bootfile Name option (67) and the TFTP
+server options (66 and 150), so a Profile selects the vendor-specific options
+to add, e.g. the sub-options of the Vendor Specific Information option (43)
+that Juniper devices read.
+*/
+
+package ztp
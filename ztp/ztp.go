@@ -0,0 +1,168 @@
+package ztp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+// Vendor selects the vendor-specific options of a Profile.
+type Vendor int
+
+// Vendors with specific ZTP options
+const (
+	// VendorGeneric only sends the options that most vendors understand,
+	// e.g. Cisco, Arista or Cumulus devices: the Bootfile Name option holds
+	// the URL of the configuration or of the provisioning script.
+	VendorGeneric Vendor = iota
+	// VendorJuniper also sends the configuration and image file names,
+	// and the transfer mode, in the Vendor Specific Information option.
+	VendorJuniper
+)
+
+func (v Vendor) String() string {
+	if s, ok := VendorToString[v]; ok {
+		return s
+	}
+	return "Unknown"
+}
+
+// VendorToString maps a Vendor to its name.
+var VendorToString = map[Vendor]string{
+	VendorGeneric: "Generic",
+	VendorJuniper: "Juniper",
+}
+
+// Juniper sub-options of the Vendor Specific Information option
+const (
+	juniperConfigFileName   = 1
+	juniperTransferMode     = 3
+	juniperAltImageFileName = 4
+)
+
+// Profile describes the zero-touch provisioning of a class of devices.
+type Profile struct {
+	Vendor Vendor
+	// ConfigURL is the URL of the configuration file, or of the script that
+	// provisions the device.
+	ConfigURL string
+	// ImageURL is the URL of the software image to install, if any. It is
+	// only sent to the vendors that have an option for it.
+	ImageURL string
+	// TFTPServers are the file servers, sent in the TFTP Server Address
+	// option (150), and the first one in the TFTP Server Name option (66).
+	// Juniper devices also use them for HTTP and FTP transfers; if there
+	// are none, the host of the URLs is used, which must then be an IPv4
+	// address.
+	TFTPServers []net.IP
+}
+
+// Options returns the DHCPv4 options of the profile, or an error if the
+// profile is invalid.
+func (p *Profile) Options() ([]dhcpv4.Option, error) {
+	if p.ConfigURL == "" {
+		return nil, errors.New("ztp: no configuration URL")
+	}
+	config, err := url.Parse(p.ConfigURL)
+	if err != nil {
+		return nil, fmt.Errorf("ztp: invalid configuration URL: %v", err)
+	}
+	var image *url.URL
+	if p.ImageURL != "" {
+		if image, err = url.Parse(p.ImageURL); err != nil {
+			return nil, fmt.Errorf("ztp: invalid image URL: %v", err)
+		}
+	}
+	servers := p.TFTPServers
+	var opts []dhcpv4.Option
+	switch p.Vendor {
+	case VendorGeneric:
+		opts = append(opts, &dhcpv4.OptBootfileName{BootfileName: []byte(p.ConfigURL)})
+	case VendorJuniper:
+		// Juniper devices get the paths of the files, and the server from
+		// the TFTP server options, whatever the transfer mode
+		if image != nil && image.Scheme != config.Scheme {
+			return nil, errors.New("ztp: the configuration and image URLs must have the same scheme")
+		}
+		if len(servers) == 0 {
+			ip := net.ParseIP(config.Hostname()).To4()
+			if ip == nil {
+				return nil, fmt.Errorf("ztp: the host of %s is not an IPv4 address, and there are no TFTP servers", p.ConfigURL)
+			}
+			servers = []net.IP{ip}
+		}
+		vsi := &dhcpv4.OptVendorSpecificInformation{}
+		vsi.AddSubOption(juniperConfigFileName, []byte(config.Path))
+		if image != nil {
+			vsi.AddSubOption(juniperAltImageFileName, []byte(image.Path))
+		}
+		if config.Scheme != "" {
+			vsi.AddSubOption(juniperTransferMode, []byte(config.Scheme))
+		}
+		opts = append(opts, vsi)
+	default:
+		return nil, fmt.Errorf("ztp: unknown vendor %v", p.Vendor)
+	}
+	if len(servers) > 0 {
+		var addrs []byte
+		for _, ip := range servers {
+			ip4 := ip.To4()
+			if ip4 == nil {
+				return nil, fmt.Errorf("ztp: TFTP server %v is not an IPv4 address", ip)
+			}
+			addrs = append(addrs, ip4...)
+		}
+		opts = append(opts,
+			&dhcpv4.OptTFTPServerName{TFTPServerName: []byte(servers[0].String())},
+			&dhcpv4.OptionGeneric{OptionCode: dhcpv4.OptionTFTPServerAddress, Data: addrs},
+		)
+	}
+	return opts, nil
+}
+
+// Modifier returns a modifier setting the DHCPv4 options of the profile in a
+// reply, or an error if the profile is invalid.
+func (p *Profile) Modifier() (dhcpv4.Modifier, error) {
+	opts, err := p.Options()
+	if err != nil {
+		return nil, err
+	}
+	return func(d *dhcpv4.DHCPv4) *dhcpv4.DHCPv4 {
+		for _, opt := range opts {
+			d.UpdateOption(opt)
+		}
+		return d
+	}, nil
+}
+
+// OptionsV6 returns the DHCPv6 options of the profile, or an error if the
+// profile is invalid. DHCPv6 only has a Boot File URL option, which holds the
+// configuration URL for every vendor.
+func (p *Profile) OptionsV6() ([]dhcpv6.Option, error) {
+	if p.ConfigURL == "" {
+		return nil, errors.New("ztp: no configuration URL")
+	}
+	if _, err := url.Parse(p.ConfigURL); err != nil {
+		return nil, fmt.Errorf("ztp: invalid configuration URL: %v", err)
+	}
+	return []dhcpv6.Option{&dhcpv6.OptBootFileURL{BootFileURL: []byte(p.ConfigURL)}}, nil
+}
+
+// ModifierV6 returns a modifier setting the DHCPv6 options of the profile in
+// a reply, or an error if the profile is invalid.
+func (p *Profile) ModifierV6() (dhcpv6.Modifier, error) {
+	opts, err := p.OptionsV6()
+	if err != nil {
+		return nil, err
+	}
+	return func(d dhcpv6.DHCPv6) dhcpv6.DHCPv6 {
+		for _, opt := range opts {
+			d.UpdateOption(opt)
+		}
+		return d
+	}, nil
+}
@@ -0,0 +1,97 @@
+package ztp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileGeneric(t *testing.T) {
+	p := Profile{
+		ConfigURL:   "http://10.0.0.1/ztp.py",
+		TFTPServers: []net.IP{net.IPv4(10, 0, 0, 2), net.IPv4(10, 0, 0, 3)},
+	}
+	opts, err := p.Options()
+	require.NoError(t, err)
+	require.Equal(t, []dhcpv4.Option{
+		&dhcpv4.OptBootfileName{BootfileName: []byte("http://10.0.0.1/ztp.py")},
+		&dhcpv4.OptTFTPServerName{TFTPServerName: []byte("10.0.0.2")},
+		&dhcpv4.OptionGeneric{OptionCode: dhcpv4.OptionTFTPServerAddress, Data: []byte{10, 0, 0, 2, 10, 0, 0, 3}},
+	}, opts)
+
+	mod, err := p.Modifier()
+	require.NoError(t, err)
+	d, err := dhcpv4.New()
+	require.NoError(t, err)
+	d = mod(d)
+	for _, opt := range opts {
+		require.Equal(t, opt, d.GetOneOption(opt.Code()))
+	}
+}
+
+func TestProfileJuniper(t *testing.T) {
+	p := Profile{
+		Vendor:    VendorJuniper,
+		ConfigURL: "http://10.0.0.1/configs/ex4300.conf",
+		ImageURL:  "http://10.0.0.1/images/junos.tgz",
+	}
+	opts, err := p.Options()
+	require.NoError(t, err)
+	require.Len(t, opts, 3)
+	vsi := opts[0].(*dhcpv4.OptVendorSpecificInformation)
+	require.Equal(t, []byte("/configs/ex4300.conf"), vsi.GetSubOption(1))
+	require.Equal(t, []byte("/images/junos.tgz"), vsi.GetSubOption(4))
+	require.Equal(t, []byte("http"), vsi.GetSubOption(3))
+	// the file server is the host of the URLs
+	require.Equal(t, []byte{10, 0, 0, 1}, opts[2].(*dhcpv4.OptionGeneric).Data)
+
+	// the host must be an address if there are no TFTP servers
+	p.ConfigURL = "http://ztp.example.com/configs/ex4300.conf"
+	_, err = p.Options()
+	require.Error(t, err)
+	p.TFTPServers = []net.IP{net.IPv4(10, 0, 0, 2)}
+	p.ImageURL = "ftp://10.0.0.1/images/junos.tgz"
+	_, err = p.Options()
+	require.Error(t, err, "schemes differ")
+	p.ImageURL = ""
+	_, err = p.Options()
+	require.NoError(t, err)
+}
+
+func TestProfileInvalid(t *testing.T) {
+	for _, p := range []Profile{
+		{},
+		{ConfigURL: "http://[::1"},
+		{ConfigURL: "http://10.0.0.1/ztp.py", ImageURL: "http://[::1"},
+		{ConfigURL: "http://10.0.0.1/ztp.py", TFTPServers: []net.IP{net.ParseIP("2001:db8::1")}},
+		{ConfigURL: "http://10.0.0.1/ztp.py", Vendor: Vendor(42)},
+	} {
+		_, err := p.Options()
+		require.Error(t, err, "%+v", p)
+		_, err = p.Modifier()
+		require.Error(t, err, "%+v", p)
+	}
+	_, err := (&Profile{}).OptionsV6()
+	require.Error(t, err)
+	_, err = (&Profile{}).ModifierV6()
+	require.Error(t, err)
+}
+
+func TestProfileV6(t *testing.T) {
+	p := Profile{ConfigURL: "http://[2001:db8::1]/ztp.py"}
+	mod, err := p.ModifierV6()
+	require.NoError(t, err)
+	d, err := dhcpv6.NewMessage()
+	require.NoError(t, err)
+	d = mod(d)
+	opt := d.GetOneOption(dhcpv6.OptionBootfileURL)
+	require.Equal(t, &dhcpv6.OptBootFileURL{BootFileURL: []byte("http://[2001:db8::1]/ztp.py")}, opt)
+}
+
+func TestVendorString(t *testing.T) {
+	require.Equal(t, "Juniper", VendorJuniper.String())
+	require.Equal(t, "Unknown", Vendor(42).String())
+}